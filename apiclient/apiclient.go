@@ -0,0 +1,318 @@
+// Package apiclient is a client for the product API served by
+// product.Handler.
+//
+// Responses decode into product.Product, and error responses become an
+// *Error whose Is method maps the status back onto apperr's sentinels, so
+// code on either side of the wire can test errors.Is(err,
+// apperr.ErrNotFound). Transport failures, 429 and 5xx responses are
+// retried with the retry package; other 4xx responses are not.
+//
+//	c, err := apiclient.New("http://localhost:8080",
+//		apiclient.WithTimeout(2*time.Second),
+//		apiclient.WithRetry(retry.WithMaxAttempts(5)),
+//		apiclient.WithBearerToken(token),
+//	)
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/apperr"
+	"github.com/stawuah/pounce-on-go/eventbus/bridge"
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/product"
+	"github.com/stawuah/pounce-on-go/retry"
+)
+
+// Error is a non-2xx response from the API.
+type Error struct {
+	StatusCode int
+	Message    string            // the server's "error" field, or the body text
+	Fields     map[string]string // invalid field to failed rule, for 422
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("apiclient: %d %s", e.StatusCode, e.Message)
+}
+
+// Is matches apperr.ErrNotFound for a 404, apperr.ErrGone for a 410 and
+// apperr.ErrInvalid for a 422.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case apperr.ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case apperr.ErrGone:
+		return e.StatusCode == http.StatusGone
+	case apperr.ErrInvalid:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	}
+	return false
+}
+
+// Temporary reports whether the same request might succeed later.
+func (e *Error) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// maxErrorBody caps how much of an error response is read.
+const maxErrorBody = 64 << 10
+
+// Client calls the product API. It is safe for concurrent use.
+type Client struct {
+	base    *url.URL
+	http    *http.Client
+	timeout time.Duration
+	retry   []retry.Option
+	auth    func(*http.Request)
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends requests through hc instead of
+// http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithTimeout bounds each attempt, not the call as a whole; bound the
+// call with the context passed to it. The default is 10s.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) { c.timeout = d }
+}
+
+// WithRetry sets the retry policy. The default is retry.Do's: three
+// attempts with jittered exponential backoff. Pass
+// retry.WithMaxAttempts(1) to disable retries.
+func WithRetry(opts ...retry.Option) Option {
+	return func(c *Client) { c.retry = opts }
+}
+
+// WithBearerToken sends token in the Authorization header.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.auth = func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }
+	}
+}
+
+// WithBasicAuth sends HTTP basic credentials.
+func WithBasicAuth(user, password string) Option {
+	return func(c *Client) {
+		c.auth = func(r *http.Request) { r.SetBasicAuth(user, password) }
+	}
+}
+
+// New returns a client for the API rooted at baseURL.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("apiclient: base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("apiclient: base URL %q: scheme must be http or https", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	c := &Client{base: u, http: http.DefaultClient, timeout: 10 * time.Second}
+	for _, o := range opts {
+		o(c)
+	}
+	return c, nil
+}
+
+// List fetches the whole catalog, ordered by SKU.
+func (c *Client) List(ctx context.Context) ([]product.Product, error) {
+	var ps []product.Product
+	err := c.do(ctx, http.MethodGet, "/products", nil, &ps)
+	return ps, err
+}
+
+// Get fetches the product with the given SKU.
+func (c *Client) Get(ctx context.Context, sku string) (product.Product, error) {
+	var p product.Product
+	err := c.do(ctx, http.MethodGet, "/products/"+url.PathEscape(sku), nil, &p)
+	return p, err
+}
+
+// Create stores p, replacing any product with the same SKU. Since that
+// makes it idempotent, it is retried like a GET.
+func (c *Client) Create(ctx context.Context, p product.Product) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("apiclient: encode product: %w", err)
+	}
+	return c.do(ctx, http.MethodPost, "/products", body, nil)
+}
+
+// Suggest returns products whose name starts with prefix.
+func (c *Client) Suggest(ctx context.Context, prefix string) ([]product.Product, error) {
+	var ps []product.Product
+	err := c.do(ctx, http.MethodGet, "/products/suggest?q="+url.QueryEscape(prefix), nil, &ps)
+	return ps, err
+}
+
+// Related returns the products shown alongside sku.
+func (c *Client) Related(ctx context.Context, sku string) ([]product.Product, error) {
+	var ps []product.Product
+	err := c.do(ctx, http.MethodGet, "/products/"+url.PathEscape(sku)+"/related", nil, &ps)
+	return ps, err
+}
+
+// Diff fetches what changed since snapshot since, or the whole catalog if
+// since is empty (see product.Snapshotter). A snapshot the server no longer
+// keeps is apperr.ErrGone.
+func (c *Client) Diff(ctx context.Context, since string) (product.Diff, error) {
+	var d product.Diff
+	err := c.do(ctx, http.MethodGet, "/admin/diff?since="+url.QueryEscape(since), nil, &d)
+	return d, err
+}
+
+// Events follows the server's event stream, GET /events, calling fn with
+// each event until ctx ends, the server closes the stream or fn fails.
+// It returns nil only for a stream the server closed. The stream is not
+// retried and has no timeout; reconnecting is up to the caller, which
+// may have missed events in between.
+func (c *Client) Events(ctx context.Context, fn func(jsonx.Event) error) error {
+	s, err := c.OpenEvents(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return s.Follow(fn)
+}
+
+// EventStream is an open GET /events stream.
+type EventStream struct {
+	ctx  context.Context
+	body io.ReadCloser
+}
+
+// OpenEvents opens the server's event stream and returns once the server
+// has subscribed it: every event published from then on is on the
+// stream, so a caller can subscribe first and then read current state
+// without a gap in between. The stream lives until ctx ends or Close.
+func (c *Client) OpenEvents(ctx context.Context) (*EventStream, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base.String()+"/events", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if c.auth != nil {
+		c.auth(req)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("apiclient: GET /events: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("apiclient: GET /events: %w", readError(resp))
+	}
+	return &EventStream{ctx: ctx, body: resp.Body}, nil
+}
+
+// Follow calls fn with each event on s, as Events does.
+func (s *EventStream) Follow(fn func(jsonx.Event) error) error {
+	err := bridge.ReadFrames(s.body, func(f bridge.Frame) error {
+		var e jsonx.Event
+		if err := json.Unmarshal(f.Data, &e); err != nil {
+			return fmt.Errorf("decode event: %w", err)
+		}
+		return fn(e)
+	})
+	if err == nil && s.ctx.Err() != nil {
+		err = s.ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("apiclient: GET /events: %w", err)
+	}
+	return nil
+}
+
+// Close ends the stream.
+func (s *EventStream) Close() error { return s.body.Close() }
+
+// do sends the request, retrying as configured, and decodes a 2xx body
+// into out unless out is nil.
+func (c *Client) do(ctx context.Context, method, path string, body []byte, out any) error {
+	target := c.base.String() + path
+	err := retry.Do(ctx, func(ctx context.Context) error {
+		return c.attempt(ctx, method, target, body, out)
+	}, c.retry...)
+	if err != nil {
+		return fmt.Errorf("apiclient: %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+func (c *Client) attempt(parent context.Context, method, target string, body []byte, out any) error {
+	ctx := parent
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, c.timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return retry.Permanent(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.auth != nil {
+		c.auth(req)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if parent.Err() != nil {
+			// The caller gave up; only a per-attempt timeout is retried.
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		apiErr := readError(resp)
+		if apiErr.Temporary() {
+			return apiErr
+		}
+		return retry.Permanent(apiErr)
+	}
+	if out == nil {
+		io.Copy(io.Discard, resp.Body) // lets the connection be reused
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return retry.Permanent(fmt.Errorf("decode response: %w", err))
+	}
+	return nil
+}
+
+// readError builds an *Error from a failed response. The API answers in
+// JSON, but a proxy in front of it, or the server's own malformed-JSON
+// reply, may not.
+func readError(resp *http.Response) *Error {
+	e := &Error{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	var body struct {
+		Error  string            `json:"error"`
+		Fields map[string]string `json:"fields"`
+	}
+	switch {
+	case json.Unmarshal(data, &body) == nil && body.Error != "":
+		e.Message, e.Fields = body.Error, body.Fields
+	case !json.Valid(data) && len(bytes.TrimSpace(data)) > 0:
+		e.Message = string(bytes.TrimSpace(data))
+	}
+	return e
+}
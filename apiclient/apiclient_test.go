@@ -0,0 +1,221 @@
+package apiclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/apperr"
+	"github.com/stawuah/pounce-on-go/eventbus"
+	"github.com/stawuah/pounce-on-go/eventbus/bridge"
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/product"
+	"github.com/stawuah/pounce-on-go/retry"
+)
+
+func newClient(t *testing.T, h http.Handler, opts ...Option) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	c, err := New(srv.URL+"/", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestAgainstProductAPI(t *testing.T) {
+	ctx := context.Background()
+	repo := product.NewRepository()
+	c := newClient(t, product.Handler(&product.Service{Repo: repo}))
+
+	for _, p := range []product.Product{{SKU: "A1", Name: "Anvil", Price: 9}, {SKU: "A2", Name: "Anchor", Price: 4}} {
+		if err := c.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo.Relate(ctx, "A1", "A2")
+
+	if p, err := c.Get(ctx, "A1"); err != nil || p.Name != "Anvil" {
+		t.Fatalf("Get = %+v, %v", p, err)
+	}
+	if ps, err := c.List(ctx); err != nil || len(ps) != 2 || ps[0].SKU != "A1" {
+		t.Fatalf("List = %+v, %v", ps, err)
+	}
+	if ps, err := c.Suggest(ctx, "an"); err != nil || len(ps) != 2 {
+		t.Fatalf("Suggest = %+v, %v", ps, err)
+	}
+	if ps, err := c.Related(ctx, "A1"); err != nil || len(ps) != 1 || ps[0].SKU != "A2" {
+		t.Fatalf("Related = %+v, %v", ps, err)
+	}
+
+	_, err := c.Get(ctx, "Z9")
+	var apiErr *Error
+	if !errors.Is(err, apperr.ErrNotFound) || !errors.As(err, &apiErr) || apiErr.Message != `product "Z9" not found` {
+		t.Fatalf("Get missing = %v", err)
+	}
+
+	err = c.Create(ctx, product.Product{SKU: "B1", Price: -1})
+	if !errors.Is(err, apperr.ErrInvalid) || !errors.As(err, &apiErr) {
+		t.Fatalf("Create invalid = %v", err)
+	}
+	if apiErr.Fields["name"] != "required" || apiErr.Fields["price"] != "min=0" {
+		t.Fatalf("fields = %v", apiErr.Fields)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantCalls int32
+	}{
+		{"unavailable", http.StatusServiceUnavailable, "", 3},
+		{"rate limited", http.StatusTooManyRequests, "", 3},
+		{"bad request", http.StatusBadRequest, "malformed JSON\n", 1},
+		{"not found", http.StatusNotFound, `{"error":"gone"}`, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			c := newClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}), WithRetry(retry.WithMaxAttempts(3), retry.WithBackoff(retry.Constant(0))))
+
+			_, err := c.Get(context.Background(), "A1")
+			var apiErr *Error
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Fatalf("err = %v, want status %d", err, tt.status)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Fatalf("calls = %d, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRecoversAfterTransientFailure(t *testing.T) {
+	var calls atomic.Int32
+	c := newClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "busy", http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"sku":"A1","name":"Anvil","price":9}`))
+	}), WithRetry(retry.WithBackoff(retry.Constant(0))))
+
+	if p, err := c.Get(context.Background(), "A1"); err != nil || p.Name != "Anvil" {
+		t.Fatalf("Get = %+v, %v", p, err)
+	}
+}
+
+func TestTimeoutPerAttempt(t *testing.T) {
+	var calls atomic.Int32
+	c := newClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-r.Context().Done() // the first attempt hangs
+			return
+		}
+		w.Write([]byte(`{"sku":"A1"}`))
+	}), WithTimeout(20*time.Millisecond), WithRetry(retry.WithBackoff(retry.Constant(0))))
+
+	if _, err := c.Get(context.Background(), "A1"); err != nil {
+		t.Fatalf("Get = %v, want success on the second attempt", err)
+	}
+}
+
+func TestCancelledCallIsNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	c := newClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		cancel()
+		<-r.Context().Done()
+	}), WithRetry(retry.WithBackoff(retry.Constant(0))))
+
+	if _, err := c.Get(ctx, "A1"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Get = %v, want context.Canceled", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestAuth(t *testing.T) {
+	tests := []struct {
+		opt  Option
+		want string
+	}{
+		{WithBearerToken("s3cret"), "Bearer s3cret"},
+		{WithBasicAuth("ada", "pw"), "Basic YWRhOnB3"},
+	}
+	for _, tt := range tests {
+		var got string
+		c := newClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get("Authorization")
+			w.Write([]byte(`[]`))
+		}), tt.opt)
+		if _, err := c.Suggest(context.Background(), "a"); err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Authorization = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestNewRejectsBadBaseURL(t *testing.T) {
+	for _, base := range []string{"localhost:8080", "ftp://example.com", "http://[::1"} {
+		if _, err := New(base); err == nil {
+			t.Errorf("New(%q) succeeded", base)
+		}
+	}
+}
+
+func TestDiffAndEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	topic := eventbus.NewTopic[jsonx.Event]("products")
+	defer topic.Close()
+	svc := &product.Service{Repo: product.NewRepository(), Events: topic}
+	mux := http.NewServeMux()
+	mux.Handle("/products", product.Handler(svc))
+	mux.Handle("/admin/", product.DiffHandler(product.NewSnapshotter(svc, 1)))
+	mux.Handle("GET /events", &bridge.SSE[jsonx.Event]{Topic: topic})
+	c := newClient(t, mux)
+
+	svc.Create(ctx, product.Product{SKU: "A1", Name: "Anvil", Price: 9})
+	d, err := c.Diff(ctx, "")
+	if err != nil || len(d.Added) != 1 || d.Snapshot == "" {
+		t.Fatalf("Diff = %+v, %v", d, err)
+	}
+	svc.Create(ctx, product.Product{SKU: "A2", Name: "Anchor", Price: 4})
+	c.Diff(ctx, d.Snapshot) // the Snapshotter keeps one, so d's goes
+	if _, err := c.Diff(ctx, d.Snapshot); !errors.Is(err, apperr.ErrGone) {
+		t.Fatalf("Diff since an evicted snapshot = %v, want ErrGone", err)
+	}
+
+	got := make(chan jsonx.Event)
+	done := make(chan error)
+	go func() {
+		done <- c.Events(ctx, func(e jsonx.Event) error { got <- e; return nil })
+	}()
+	for topic.Len() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	svc.Create(ctx, product.Product{SKU: "A1", Name: "Anvil", Price: 12})
+	if e := <-got; e.Type() != "product.price_changed" || e.Payload.(jsonx.PriceChanged).New != 12 {
+		t.Fatalf("event = %+v", e)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Events after cancel = %v", err)
+	}
+}
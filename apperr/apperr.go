@@ -0,0 +1,84 @@
+// Package apperr is how errors travel through a layered service, such as
+// the one in package product.
+//
+// The repository reports what went wrong in typed errors that carry data
+// (which key was missing, which field was invalid). Each layer above adds
+// context with fmt.Errorf and %w, never discarding the cause, and the
+// HTTP edge inspects the chain with errors.As and errors.Is to pick a
+// status code (StatusOf) and a body (WriteError). Callers that only care
+// about the category match the sentinels ErrNotFound and ErrInvalid;
+// callers that need details use errors.As to get at the typed value.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinels naming broad categories. Typed errors match them via Is.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrInvalid  = errors.New("invalid input")
+	// ErrGone means something existed once but is no longer kept.
+	ErrGone = errors.New("gone")
+)
+
+// NotFoundError reports a missing entity.
+type NotFoundError struct {
+	Kind string // e.g. "product"
+	Key  string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %q not found", e.Kind, e.Key)
+}
+
+// Is makes errors.Is(err, ErrNotFound) true for any *NotFoundError.
+func (e *NotFoundError) Is(target error) bool { return target == ErrNotFound }
+
+// ValidationError reports one invalid field.
+type ValidationError struct {
+	Field string
+	Value any
+	Rule  string // e.g. "required", "min=0"
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: failed %s (got %v)", e.Field, e.Rule, e.Value)
+}
+
+// Is makes errors.Is(err, ErrInvalid) true for any *ValidationError.
+func (e *ValidationError) Is(target error) bool { return target == ErrInvalid }
+
+// Fields returns every ValidationError in err's tree, including those
+// combined with errors.Join, in order.
+func Fields(err error) []*ValidationError {
+	var out []*ValidationError
+	var walk func(error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+		case *ValidationError:
+			out = append(out, e)
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+	walk(err)
+	return out
+}
+
+// FieldNames returns the invalid field names in err, comma-separated in
+// the order they were reported.
+func FieldNames(err error) string {
+	var names []string
+	for _, v := range Fields(err) {
+		names = append(names, v.Field)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,188 @@
+package apperr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stawuah/pounce-on-go/logging"
+)
+
+// The layers of a tiny service, each adding context to the error below.
+func findProduct(sku string) error { return &NotFoundError{Kind: "product", Key: sku} }
+
+func getProduct(sku string) error {
+	if err := findProduct(sku); err != nil {
+		return fmt.Errorf("service: get product: %w", err)
+	}
+	return nil
+}
+
+func TestNotFoundThroughLayers(t *testing.T) {
+	err := getProduct("Z9")
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("errors.Is(err, ErrNotFound) = false for %v", err)
+	}
+	var nf *NotFoundError
+	if !errors.As(err, &nf) || nf.Key != "Z9" || nf.Kind != "product" {
+		t.Fatalf("errors.As gave %+v", nf)
+	}
+	if got := err.Error(); got != `service: get product: product "Z9" not found` {
+		t.Fatalf("message = %q", got)
+	}
+}
+
+func TestValidationJoinsAllFields(t *testing.T) {
+	err := fmt.Errorf("service: create product: %w", errors.Join(
+		&ValidationError{Field: "sku", Rule: "required"},
+		&ValidationError{Field: "name", Rule: "required"},
+		&ValidationError{Field: "price", Value: -1, Rule: "min=0"},
+	))
+
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("errors.Is(err, ErrInvalid) = false for %v", err)
+	}
+	if got := FieldNames(err); got != "sku, name, price" {
+		t.Fatalf("FieldNames = %q", got)
+	}
+	// errors.As finds only the first match in a joined tree.
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Field != "sku" {
+		t.Fatalf("errors.As gave %+v", ve)
+	}
+}
+
+func TestSentinelsDoNotCrossMatch(t *testing.T) {
+	nf := &NotFoundError{Kind: "product", Key: "x"}
+	if errors.Is(nf, ErrInvalid) {
+		t.Fatal("NotFoundError matched ErrInvalid")
+	}
+	if errors.Is(&ValidationError{}, ErrNotFound) {
+		t.Fatal("ValidationError matched ErrNotFound")
+	}
+}
+
+func TestStatusOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, http.StatusOK},
+		{fmt.Errorf("a: %w", fmt.Errorf("b: %w", &NotFoundError{})), http.StatusNotFound},
+		{fmt.Errorf("diff: %w", ErrGone), http.StatusGone},
+		{errors.Join(&ValidationError{Field: "x"}), http.StatusUnprocessableEntity},
+		{fmt.Errorf("repo: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{errors.New("disk on fire"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := StatusOf(tt.err); got != tt.want {
+			t.Errorf("StatusOf(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name, lang   string
+		err          error
+		wantStatus   int
+		wantLang     string
+		wantError    string
+		wantFields   map[string]string
+		wantMessages map[string]string
+	}{
+		{"not found", "", getProduct("Z9"), 404, "en", `product "Z9" not found`, nil, nil},
+		{"french", "fr-FR, en;q=0.5", getProduct("Z9"), 404, "fr", "produit « Z9 » introuvable", nil, nil},
+		{"fields", "fr", errors.Join(&ValidationError{Field: "name", Rule: "required"}, &ValidationError{Field: "price", Value: -1, Rule: "min=0"}),
+			422, "fr", "Entité non traitable",
+			map[string]string{"name": "required", "price": "min=0"},
+			map[string]string{"name": "name est obligatoire", "price": "price doit valoir au moins 0"}},
+		{"internal", "", errors.New("dial tcp 10.0.0.7: refused"), 500, "en", "Internal Server Error", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			log, _ := logging.New(&logs, logging.Config{})
+			h := logging.Middleware(log, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				WriteError(w, r, tt.err)
+			}))
+			req := httptest.NewRequest("GET", "/products/Z9", nil)
+			if tt.lang != "" {
+				req.Header.Set("Accept-Language", tt.lang)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus || rec.Header().Get("Content-Language") != tt.wantLang {
+				t.Fatalf("status %d, Content-Language %q; want %d, %q", rec.Code, rec.Header().Get("Content-Language"), tt.wantStatus, tt.wantLang)
+			}
+			var body ErrorBody
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error != tt.wantError || !maps.Equal(body.Fields, tt.wantFields) || !maps.Equal(body.Messages, tt.wantMessages) {
+				t.Fatalf("body = %+v", body)
+			}
+			// Internal errors are logged, not shown.
+			if logged := strings.Contains(logs.String(), "10.0.0.7"); logged != (tt.wantStatus == 500) {
+				t.Fatalf("cause logged = %v:\n%s", logged, logs.String())
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	es := Messages.Localizer("es")
+	v := &ValidationError{Field: "status", Value: 7, Rule: "oneof=draft active discontinued"}
+	if got := v.Message(es); got != "status debe ser uno de: draft, active, discontinued" {
+		t.Fatalf("oneof in Spanish = %q", got)
+	}
+	v.Rule = "max=10"
+	if got := v.Message(es); got != v.Error() {
+		t.Fatalf("unknown rule = %q, want Error()", got)
+	}
+}
+
+// Every catalog must have exactly the English one's keys. A key only a
+// translation has is a typo or a message English lost; a key it lacks
+// falls back to English text in a response labelled with its
+// Content-Language.
+func TestCatalogKeys(t *testing.T) {
+	en := Messages.Localizer("en")
+	data, err := locales.ReadFile("locales/en.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var enKeys map[string]string
+	if err := json.Unmarshal(data, &enKeys); err != nil {
+		t.Fatal(err)
+	}
+	for _, locale := range Messages.Locales() {
+		data, err := locales.ReadFile("locales/" + locale + ".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c map[string]string
+		if err := json.Unmarshal(data, &c); err != nil {
+			t.Fatal(err)
+		}
+		for key := range c {
+			if msg, _ := en.Lookup(key); msg == "" {
+				t.Errorf("%s has %q, which English lacks", locale, key)
+			}
+		}
+		for key := range enKeys {
+			if _, ok := c[key]; !ok {
+				t.Errorf("%s lacks %q", locale, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package apperr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/logging"
+	"github.com/stawuah/pounce-on-go/query"
+)
+
+// StatusOf maps an error chain to an HTTP status.
+func StatusOf(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrGone):
+		return http.StatusGone
+	case errors.Is(err, query.ErrInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrInvalid), errors.Is(err, jsonx.ErrInvalid):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// ErrorBody is the JSON WriteError sends.
+type ErrorBody struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty"`
+	// Messages describes each invalid field for people, in the language
+	// negotiated from Accept-Language; Fields holds the rules that failed,
+	// for programs.
+	Messages map[string]string `json:"messages,omitempty"`
+}
+
+// WriteError renders err as JSON, with messages in the language the
+// request's Accept-Language header asks for (see Messages). Internal
+// errors are not echoed to the client since their text may expose
+// implementation details; they go to the request's log instead.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	status := StatusOf(err)
+	l := Messages.Negotiate(r.Header.Get("Accept-Language"))
+	body := ErrorBody{Error: localize(l, status, err)}
+	if status >= 500 {
+		logging.FromContext(r.Context()).Error("request failed", "status", status, "err", err)
+	}
+
+	// A value the client sent that failed to decode, or query parameters
+	// that do not fit the list; the message names them.
+	if errors.Is(err, jsonx.ErrInvalid) || errors.Is(err, query.ErrInvalid) {
+		body.Error = err.Error()
+	}
+	if fields := Fields(err); len(fields) > 0 {
+		body.Fields = make(map[string]string, len(fields))
+		body.Messages = make(map[string]string, len(fields))
+		for _, f := range fields {
+			body.Fields[f.Field] = f.Rule
+			body.Messages[f.Field] = f.Message(l)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", l.Locale())
+	w.Header().Add("Vary", "Accept-Language")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,56 @@
+package apperr
+
+import (
+	"embed"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/stawuah/pounce-on-go/i18n"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Messages holds the API's error messages, one catalog per file in
+// locales. English is the fallback and has every message; the other
+// catalogs may lag behind it.
+var Messages = func() *i18n.Bundle {
+	b := i18n.NewBundle("en")
+	if err := b.LoadFS(locales, "locales/*.json"); err != nil {
+		panic(err)
+	}
+	return b
+}()
+
+// Message describes e in l's language: "price must be at least 0". A rule
+// without a message falls back to Error.
+func (e *ValidationError) Message(l i18n.Localizer) string {
+	name, param, _ := strings.Cut(e.Rule, "=")
+	key := "validation." + name
+	if !l.Has(key) {
+		return e.Error()
+	}
+	if name == "oneof" {
+		param = strings.Join(strings.Fields(param), ", ")
+	}
+	return l.T(key, "field", e.Field, name, param, "value", e.Value)
+}
+
+// localize returns the client-facing message for err, which has the given
+// status: what is missing for a not-found error, otherwise the status
+// text.
+func localize(l i18n.Localizer, status int, err error) string {
+	var nf *NotFoundError
+	if errors.As(err, &nf) {
+		if key := "not_found." + nf.Kind; l.Has(key) {
+			return l.T(key, "key", nf.Key)
+		}
+		return nf.Error()
+	}
+	if key := "status." + strconv.Itoa(status); l.Has(key) {
+		return l.T(key)
+	}
+	return http.StatusText(status)
+}
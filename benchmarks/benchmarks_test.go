@@ -0,0 +1,263 @@
+package benchmarks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stawuah/pounce-on-go/bloom"
+	"github.com/stawuah/pounce-on-go/concurrency/ownership"
+	"github.com/stawuah/pounce-on-go/pagination"
+	"github.com/stawuah/pounce-on-go/product"
+	"github.com/stawuah/pounce-on-go/skiplist"
+	"github.com/stawuah/pounce-on-go/slicepool"
+	"github.com/stawuah/pounce-on-go/tree"
+)
+
+func catalog(n int) []product.Product {
+	out := make([]product.Product, n)
+	for i := range out {
+		out[i] = product.Product{
+			SKU:   fmt.Sprintf("SKU-%05d", i),
+			Name:  "Product " + strconv.Itoa(i),
+			Price: float64(i%500) + 0.99,
+		}
+	}
+	return out
+}
+
+func BenchmarkJSONEncode(b *testing.B) {
+	products := catalog(100)
+
+	b.Run("Marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := json.Marshal(products); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Encoder", func(b *testing.B) {
+		b.ReportAllocs()
+		enc := json.NewEncoder(io.Discard)
+		for b.Loop() {
+			if err := enc.Encode(products); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("PooledBuffer", func(b *testing.B) {
+		b.ReportAllocs()
+		pool := slicepool.NewBufferPool(1 << 20)
+		for b.Loop() {
+			buf := pool.Get()
+			if err := json.NewEncoder(buf).Encode(products); err != nil {
+				b.Fatal(err)
+			}
+			pool.Put(buf)
+		}
+	})
+}
+
+func BenchmarkStoreGet(b *testing.B) {
+	stores := map[string]func() ownership.Store{
+		"Mutex": func() ownership.Store { return ownership.NewMutexStore() },
+		"Owned": func() ownership.Store { return ownership.NewOwnedStore() },
+	}
+	keys := make([]string, 10_000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("SKU-%05d", i)
+	}
+	for name, mk := range stores {
+		b.Run(name, func(b *testing.B) {
+			s := mk()
+			if c, ok := s.(interface{ Close() }); ok {
+				defer c.Close()
+			}
+			for _, k := range keys {
+				s.Set(k, k)
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					s.Get(keys[i%len(keys)])
+					i++
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkPaginate(b *testing.B) {
+	products := catalog(100_000)
+	for _, page := range []int{1, 2500, 5000} {
+		b.Run(fmt.Sprintf("page=%d", page), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				items, info := pagination.Paginate(products, page, 20)
+				if len(items) == 0 || info.Page != page {
+					b.Fatal("unexpected page")
+				}
+			}
+		})
+	}
+}
+
+// orderedIndex is the surface shared by the ordered structures compared
+// below: build from random keys, then scan a range.
+type orderedIndex interface {
+	Insert(int, struct{}) bool
+	Scan(from, n int) int
+}
+
+type bstIndex struct{ *tree.BST[int, struct{}] }
+
+func (t bstIndex) Scan(from, n int) int { return scan(t.Ascend(from), n) }
+
+type avlIndex struct{ *tree.AVL[int, struct{}] }
+
+func (t avlIndex) Scan(from, n int) int { return scan(t.Ascend(from), n) }
+
+type skipIndex struct{ *skiplist.List[int, struct{}] }
+
+func (s skipIndex) Scan(from, n int) int { return scan(s.Seek(from), n) }
+
+// sliceIndex keeps keys sorted in a slice: binary search to find, copy
+// to insert.
+type sliceIndex struct{ keys []int }
+
+func (s *sliceIndex) Insert(k int, _ struct{}) bool {
+	i, found := slices.BinarySearch(s.keys, k)
+	if !found {
+		s.keys = slices.Insert(s.keys, i, k)
+	}
+	return !found
+}
+
+func (s *sliceIndex) Scan(from, n int) int {
+	i, _ := slices.BinarySearch(s.keys, from)
+	return len(s.keys[i:min(i+n, len(s.keys))])
+}
+
+func scan(seq iter.Seq2[int, struct{}], n int) int {
+	got := 0
+	for range seq {
+		if got++; got == n {
+			break
+		}
+	}
+	return got
+}
+
+func BenchmarkOrderedIndex(b *testing.B) {
+	impls := []struct {
+		name string
+		new  func() orderedIndex
+	}{
+		{"BST", func() orderedIndex { return bstIndex{tree.New[int, struct{}]()} }},
+		{"AVL", func() orderedIndex { return avlIndex{tree.NewAVL[int, struct{}]()} }},
+		{"SkipList", func() orderedIndex { return skipIndex{skiplist.New[int, struct{}]()} }},
+		{"SortedSlice", func() orderedIndex { return new(sliceIndex) }},
+	}
+	const n = 10_000
+	keys := rand.New(rand.NewPCG(1, 1)).Perm(n)
+
+	for _, impl := range impls {
+		b.Run("Build/"+impl.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				idx := impl.new()
+				for _, k := range keys {
+					idx.Insert(k, struct{}{})
+				}
+			}
+		})
+	}
+	for _, impl := range impls {
+		b.Run("Scan100/"+impl.name, func(b *testing.B) {
+			idx := impl.new()
+			for _, k := range keys {
+				idx.Insert(k, struct{}{})
+			}
+			i := 0
+			for b.Loop() {
+				if idx.Scan(keys[i%n], 100) == 0 {
+					b.Fatal("empty scan")
+				}
+				i++
+			}
+		})
+	}
+}
+
+func BenchmarkRepositoryByPrice(b *testing.B) {
+	for name, opts := range map[string][]product.RepositoryOption{
+		"AVL":      nil,
+		"SkipList": {product.WithSkipListIndex()},
+	} {
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			repo := product.NewRepository(opts...)
+			for _, p := range catalog(10_000) {
+				repo.Put(ctx, p)
+			}
+			for b.Loop() {
+				if ps, _ := repo.ByPrice(ctx, 100, 101); len(ps) == 0 {
+					b.Fatal("empty range")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkBloomBeforeStore looks up keys that are all absent, with and
+// without a Bloom filter consulted first. The filter costs a SHA-256 per
+// lookup: more than a mutex-guarded map read, less than a round trip to
+// the goroutine that owns the store.
+func BenchmarkBloomBeforeStore(b *testing.B) {
+	stores := map[string]func() ownership.Store{
+		"Mutex": func() ownership.Store { return ownership.NewMutexStore() },
+		"Owned": func() ownership.Store { return ownership.NewOwnedStore() },
+	}
+	const n = 10_000
+	filter := bloom.New(n, 0.01)
+	misses := make([]string, n)
+	for i := range misses {
+		filter.AddString(fmt.Sprintf("SKU-%05d", i))
+		misses[i] = fmt.Sprintf("missing-%05d", i)
+	}
+
+	for name, mk := range stores {
+		s := mk()
+		for i := range n {
+			k := fmt.Sprintf("SKU-%05d", i)
+			s.Set(k, k)
+		}
+		b.Run(name+"/Direct", func(b *testing.B) {
+			i := 0
+			for b.Loop() {
+				s.Get(misses[i%n])
+				i++
+			}
+		})
+		b.Run(name+"/Filtered", func(b *testing.B) {
+			i := 0
+			for b.Loop() {
+				if k := misses[i%n]; filter.TestString(k) {
+					s.Get(k)
+				}
+				i++
+			}
+		})
+		if c, ok := s.(interface{ Close() }); ok {
+			c.Close()
+		}
+	}
+}
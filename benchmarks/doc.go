@@ -0,0 +1,11 @@
+// Package benchmarks measures the hot paths that span packages: encoding
+// products to JSON, store lookups under both concurrency models,
+// paginating a large list, a Bloom filter in front of store lookups, and
+// the ordered indexes (BST, AVL, skip list and sorted slice) that can
+// back range scans. It has no code of its own.
+//
+// Run with profiles to see where the time goes:
+//
+//	go test ./benchmarks -bench . -benchmem -cpuprofile cpu.out -memprofile mem.out
+//	go tool pprof -http :8080 cpu.out
+package benchmarks
@@ -0,0 +1,166 @@
+// Package bitset provides compact sets of small non-negative integers.
+//
+// Bitset grows on demand and is backed by a []uint64. Set256 holds exactly
+// 256 bits in a [4]uint64 array: it never allocates, is copied by value,
+// and is comparable with ==, which makes it a good fit for a fixed table of
+// feature flags embedded in another struct.
+package bitset
+
+import (
+	"iter"
+	"math/bits"
+)
+
+const wordBits = 64
+
+// Bitset is a growable set of non-negative integers. The zero value is an
+// empty set. Negative indexes panic.
+type Bitset struct {
+	words []uint64
+}
+
+// New returns a Bitset with room for n bits before it needs to grow.
+func New(n int) *Bitset {
+	return &Bitset{words: make([]uint64, (n+wordBits-1)/wordBits)}
+}
+
+// Set adds i.
+func (b *Bitset) Set(i int) {
+	w := i / wordBits
+	if w >= len(b.words) {
+		b.words = append(b.words, make([]uint64, w-len(b.words)+1)...)
+	}
+	b.words[w] |= 1 << (uint(i) % wordBits)
+}
+
+// Clear removes i.
+func (b *Bitset) Clear(i int) {
+	if w := i / wordBits; w < len(b.words) {
+		b.words[w] &^= 1 << (uint(i) % wordBits)
+	}
+}
+
+// Test reports whether i is present.
+func (b *Bitset) Test(i int) bool {
+	w := i / wordBits
+	return w < len(b.words) && b.words[w]&(1<<(uint(i)%wordBits)) != 0
+}
+
+// Count returns the number of set bits.
+func (b *Bitset) Count() int {
+	n := 0
+	for _, w := range b.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// And returns the intersection of b and o.
+func (b *Bitset) And(o *Bitset) *Bitset {
+	out := &Bitset{words: make([]uint64, min(len(b.words), len(o.words)))}
+	for i := range out.words {
+		out.words[i] = b.words[i] & o.words[i]
+	}
+	return out
+}
+
+// Or returns the union of b and o.
+func (b *Bitset) Or(o *Bitset) *Bitset {
+	return b.combine(o, func(x, y uint64) uint64 { return x | y })
+}
+
+// Xor returns the elements in exactly one of b and o.
+func (b *Bitset) Xor(o *Bitset) *Bitset {
+	return b.combine(o, func(x, y uint64) uint64 { return x ^ y })
+}
+
+// combine applies op word by word, treating missing words as zero.
+func (b *Bitset) combine(o *Bitset, op func(x, y uint64) uint64) *Bitset {
+	out := &Bitset{words: make([]uint64, max(len(b.words), len(o.words)))}
+	for i := range out.words {
+		var x, y uint64
+		if i < len(b.words) {
+			x = b.words[i]
+		}
+		if i < len(o.words) {
+			y = o.words[i]
+		}
+		out.words[i] = op(x, y)
+	}
+	return out
+}
+
+// All iterates the set bits in increasing order.
+func (b *Bitset) All() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for wi, w := range b.words {
+			for w != 0 {
+				tz := bits.TrailingZeros64(w)
+				if !yield(wi*wordBits + tz) {
+					return
+				}
+				w &= w - 1 // drop the lowest set bit
+			}
+		}
+	}
+}
+
+// Set256 is a fixed set of the integers 0–255. Indexes outside that range
+// panic.
+type Set256 [4]uint64
+
+// Set adds i.
+func (s *Set256) Set(i uint8) { s[i/wordBits] |= 1 << (i % wordBits) }
+
+// Clear removes i.
+func (s *Set256) Clear(i uint8) { s[i/wordBits] &^= 1 << (i % wordBits) }
+
+// Test reports whether i is present.
+func (s Set256) Test(i uint8) bool { return s[i/wordBits]&(1<<(i%wordBits)) != 0 }
+
+// Count returns the number of set bits.
+func (s Set256) Count() int {
+	n := 0
+	for _, w := range s {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// And returns the intersection of s and o.
+func (s Set256) And(o Set256) Set256 {
+	for i := range s {
+		s[i] &= o[i]
+	}
+	return s
+}
+
+// Or returns the union of s and o.
+func (s Set256) Or(o Set256) Set256 {
+	for i := range s {
+		s[i] |= o[i]
+	}
+	return s
+}
+
+// Xor returns the elements in exactly one of s and o.
+func (s Set256) Xor(o Set256) Set256 {
+	for i := range s {
+		s[i] ^= o[i]
+	}
+	return s
+}
+
+// All iterates the set bits in increasing order.
+func (s Set256) All() iter.Seq[uint8] {
+	return func(yield func(uint8) bool) {
+		for wi, w := range s {
+			for w != 0 {
+				if !yield(uint8(wi*wordBits + bits.TrailingZeros64(w))) {
+					return
+				}
+				w &= w - 1
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package bitset
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBitset(t *testing.T) {
+	var b Bitset
+	for _, i := range []int{0, 3, 64, 130} {
+		b.Set(i)
+	}
+	b.Set(3) // idempotent
+	b.Clear(64)
+	b.Clear(10_000) // clearing beyond the end is a no-op
+
+	if b.Count() != 3 {
+		t.Fatalf("Count() = %d, want 3", b.Count())
+	}
+	for i, want := range map[int]bool{0: true, 3: true, 64: false, 130: true, 131: false, 1 << 20: false} {
+		if got := b.Test(i); got != want {
+			t.Errorf("Test(%d) = %v, want %v", i, got, want)
+		}
+	}
+	if got := slices.Collect(b.All()); !slices.Equal(got, []int{0, 3, 130}) {
+		t.Fatalf("All() = %v", got)
+	}
+}
+
+func TestBitsetAlgebra(t *testing.T) {
+	a, b := New(0), New(256)
+	for _, i := range []int{1, 2, 200} {
+		a.Set(i)
+	}
+	for _, i := range []int{2, 3} {
+		b.Set(i)
+	}
+
+	tests := []struct {
+		name string
+		got  *Bitset
+		want []int
+	}{
+		{"and", a.And(b), []int{2}},
+		{"or", a.Or(b), []int{1, 2, 3, 200}},
+		{"xor", a.Xor(b), []int{1, 3, 200}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slices.Collect(tt.got.All()); !slices.Equal(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllStopsEarly(t *testing.T) {
+	var b Bitset
+	b.Set(1)
+	b.Set(2)
+	b.Set(3)
+	var got []int
+	for i := range b.All() {
+		got = append(got, i)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if !slices.Equal(got, []int{1, 2}) {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestSet256(t *testing.T) {
+	var flags Set256
+	flags.Set(0)
+	flags.Set(63)
+	flags.Set(64)
+	flags.Set(255)
+	flags.Clear(63)
+
+	if flags.Count() != 3 || !flags.Test(255) || flags.Test(63) {
+		t.Fatalf("unexpected flags %v", slices.Collect(flags.All()))
+	}
+
+	copied := flags // value copy: the array is copied, not shared
+	copied.Set(7)
+	if flags.Test(7) {
+		t.Fatal("modifying a copy changed the original")
+	}
+	if copied == flags {
+		t.Fatal("== did not see the difference")
+	}
+
+	var other Set256
+	other.Set(64)
+	other.Set(7)
+	if got := slices.Collect(flags.And(other).All()); !slices.Equal(got, []uint8{64}) {
+		t.Fatalf("And = %v", got)
+	}
+	if got := slices.Collect(flags.Or(other).All()); !slices.Equal(got, []uint8{0, 7, 64, 255}) {
+		t.Fatalf("Or = %v", got)
+	}
+	if got := slices.Collect(flags.Xor(other).All()); !slices.Equal(got, []uint8{0, 7, 255}) {
+		t.Fatalf("Xor = %v", got)
+	}
+}
@@ -0,0 +1,151 @@
+// Package bloom implements a Bloom filter: a compact set that can say
+// "definitely not present" or "probably present".
+//
+// Adding an item sets k bits chosen by hashing it; testing checks that
+// all k are set. An item never added can find its bits set by others,
+// which is the false-positive rate, but an added item is never missed.
+// The filter is sized from the expected number of items and the wanted
+// false-positive rate, and the k positions come from one SHA-256 digest
+// by double hashing (Kirsch and Mitzenmacher): position i is h1 + i·h2.
+//
+// A filter pays off in front of a lookup that is expensive when the
+// answer is no, such as a disk read or a network call; in front of a Go
+// map it is pure overhead. The benchmarks package measures both.
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/stawuah/pounce-on-go/digest"
+)
+
+// Filter is a Bloom filter. It is not safe for concurrent use.
+type Filter struct {
+	words []uint64
+	m     uint64 // bits
+	k     uint32 // hash functions
+	n     uint64 // items added
+}
+
+// New returns a filter sized for n items at false-positive rate p, for
+// example 0.01. It panics unless n > 0 and 0 < p < 1.
+func New(n int, p float64) *Filter {
+	if n <= 0 || p <= 0 || p >= 1 {
+		panic(fmt.Sprintf("bloom: invalid size n=%d p=%v", n, p))
+	}
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := max(1, math.Round(m/float64(n)*math.Ln2))
+	return newFilter(uint64(m), uint32(k))
+}
+
+func newFilter(m uint64, k uint32) *Filter {
+	return &Filter{words: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+// Bits returns the size of the filter in bits.
+func (f *Filter) Bits() uint64 { return f.m }
+
+// Hashes returns the number of bit positions per item.
+func (f *Filter) Hashes() int { return int(f.k) }
+
+// Len returns the number of items added, counting repeats.
+func (f *Filter) Len() int { return int(f.n) }
+
+// Add inserts data.
+func (f *Filter) Add(data []byte) { f.AddDigest(digest.Sum(data)) }
+
+// AddString inserts s.
+func (f *Filter) AddString(s string) { f.Add([]byte(s)) }
+
+// AddDigest inserts an item by its digest, for callers that already have
+// one, such as content-addressed blobs.
+func (f *Filter) AddDigest(d digest.Digest) {
+	h1, h2 := split(d)
+	for i := range uint64(f.k) {
+		pos := (h1 + i*h2) % f.m
+		f.words[pos/64] |= 1 << (pos % 64)
+	}
+	f.n++
+}
+
+// Test reports whether data may have been added. False means it
+// certainly was not.
+func (f *Filter) Test(data []byte) bool { return f.TestDigest(digest.Sum(data)) }
+
+// TestString reports whether s may have been added.
+func (f *Filter) TestString(s string) bool { return f.Test([]byte(s)) }
+
+// TestDigest reports whether the item with digest d may have been added.
+func (f *Filter) TestDigest(d digest.Digest) bool {
+	h1, h2 := split(d)
+	for i := range uint64(f.k) {
+		pos := (h1 + i*h2) % f.m
+		if f.words[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// split takes two independent 64-bit hashes from a digest. h2 is forced
+// odd so that successive positions do not collapse onto a short cycle.
+func split(d digest.Digest) (h1, h2 uint64) {
+	return binary.LittleEndian.Uint64(d[0:8]), binary.LittleEndian.Uint64(d[8:16]) | 1
+}
+
+// FalsePositiveRate estimates the current false-positive rate from the
+// fraction of bits set.
+func (f *Filter) FalsePositiveRate() float64 {
+	set := 0
+	for _, w := range f.words {
+		set += bits.OnesCount64(w)
+	}
+	return math.Pow(float64(set)/float64(f.m), float64(f.k))
+}
+
+// ErrFormat is returned by UnmarshalBinary for data not produced by
+// MarshalBinary.
+var ErrFormat = errors.New("bloom: invalid encoding")
+
+var magic = [4]byte{'B', 'L', 'M', '1'}
+
+const headerSize = len(magic) + 4 + 8 + 8
+
+// MarshalBinary encodes the filter so it can be stored with a snapshot of
+// the data it describes and reloaded without re-adding every item.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, headerSize+8*len(f.words))
+	b = append(b, magic[:]...)
+	b = binary.LittleEndian.AppendUint32(b, f.k)
+	b = binary.LittleEndian.AppendUint64(b, f.m)
+	b = binary.LittleEndian.AppendUint64(b, f.n)
+	for _, w := range f.words {
+		b = binary.LittleEndian.AppendUint64(b, w)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary replaces f with a filter encoded by MarshalBinary.
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize || [4]byte(data[:4]) != magic {
+		return ErrFormat
+	}
+	k := binary.LittleEndian.Uint32(data[4:])
+	m := binary.LittleEndian.Uint64(data[8:])
+	n := binary.LittleEndian.Uint64(data[16:])
+	body := data[headerSize:]
+	if k == 0 || m == 0 || uint64(len(body)) != (m+63)/64*8 {
+		return fmt.Errorf("%w: header says %d bits, body has %d bytes", ErrFormat, m, len(body))
+	}
+	g := newFilter(m, k)
+	g.n = n
+	for i := range g.words {
+		g.words[i] = binary.LittleEndian.Uint64(body[8*i:])
+	}
+	*f = *g
+	return nil
+}
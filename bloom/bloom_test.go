@@ -0,0 +1,120 @@
+package bloom
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stawuah/pounce-on-go/digest"
+)
+
+func TestSizing(t *testing.T) {
+	tests := []struct {
+		n    int
+		p    float64
+		bits uint64
+		k    int
+	}{
+		{1000, 0.01, 9586, 7},
+		{1000, 0.001, 14378, 10},
+		{1, 0.5, 2, 1},
+	}
+	for _, tt := range tests {
+		f := New(tt.n, tt.p)
+		if f.Bits() != tt.bits || f.Hashes() != tt.k {
+			t.Errorf("New(%d, %v): %d bits, k=%d; want %d, %d", tt.n, tt.p, f.Bits(), f.Hashes(), tt.bits, tt.k)
+		}
+	}
+}
+
+func TestNewPanicsOnBadParameters(t *testing.T) {
+	for _, args := range []struct {
+		n int
+		p float64
+	}{{0, 0.1}, {10, 0}, {10, 1}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("New(%d, %v) did not panic", args.n, args.p)
+				}
+			}()
+			New(args.n, args.p)
+		}()
+	}
+}
+
+func TestNoFalseNegativesAndRate(t *testing.T) {
+	const n, p = 10_000, 0.01
+	f := New(n, p)
+	for i := range n {
+		f.AddString(fmt.Sprintf("SKU-%05d", i))
+	}
+	for i := range n {
+		if !f.TestString(fmt.Sprintf("SKU-%05d", i)) {
+			t.Fatalf("added item %d reported absent", i)
+		}
+	}
+
+	fp := 0
+	const probes = 20_000
+	for i := range probes {
+		if f.TestString(fmt.Sprintf("missing-%d", i)) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / probes; rate > 2*p {
+		t.Fatalf("false-positive rate %.4f, want about %v", rate, p)
+	}
+	if est := f.FalsePositiveRate(); est < p/2 || est > 2*p {
+		t.Fatalf("estimated rate %.4f, want about %v", est, p)
+	}
+}
+
+func TestDigestAndBytesAgree(t *testing.T) {
+	f := New(10, 0.01)
+	f.AddDigest(digest.Sum([]byte("blob")))
+	if !f.Test([]byte("blob")) || f.Len() != 1 {
+		t.Fatal("item added by digest not found by bytes")
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	f := New(100, 0.05)
+	for i := range 100 {
+		f.AddString(fmt.Sprint(i))
+	}
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var g Filter
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if g.Bits() != f.Bits() || g.Hashes() != f.Hashes() || g.Len() != 100 {
+		t.Fatalf("decoded filter %d bits, k=%d, len %d", g.Bits(), g.Hashes(), g.Len())
+	}
+	for i := range 1000 {
+		s := fmt.Sprint(i)
+		if f.TestString(s) != g.TestString(s) {
+			t.Fatalf("decoded filter disagrees on %q", s)
+		}
+	}
+}
+
+func TestUnmarshalRejectsBadData(t *testing.T) {
+	good, _ := New(10, 0.1).MarshalBinary()
+	tests := map[string][]byte{
+		"empty":     nil,
+		"bad magic": append([]byte("XXXX"), good[4:]...),
+		"truncated": good[:len(good)-1],
+		"extended":  append(good, 0, 0, 0, 0, 0, 0, 0, 0),
+	}
+	for name, data := range tests {
+		var f Filter
+		if err := f.UnmarshalBinary(data); !errors.Is(err, ErrFormat) {
+			t.Errorf("%s: err = %v, want ErrFormat", name, err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Package catalog serves server-rendered HTML pages for the product
+// catalog: GET /catalog lists or searches products and GET
+// /catalog/{sku} shows one with its related products.
+//
+// The templates are embedded in the binary and composed from a shared
+// layout: each page file defines "content", and is parsed into its own
+// clone of the layout so pages cannot overwrite each other's blocks. They
+// are parsed once at startup; an executed *template.Template is safe for
+// concurrent use. Every request builds its own page value and passes a
+// pointer to it, so nothing request-specific is shared between requests.
+package catalog
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/stawuah/pounce-on-go/apperr"
+	"github.com/stawuah/pounce-on-go/logging"
+	"github.com/stawuah/pounce-on-go/patterns"
+	"github.com/stawuah/pounce-on-go/product"
+)
+
+//go:embed templates/*.html
+var files embed.FS
+
+var funcs = template.FuncMap{
+	"currency": Currency,
+	"plural": func(n int, one, many string) string {
+		if n == 1 {
+			return one
+		}
+		return many
+	},
+}
+
+// pages holds one template set per page, each a clone of the layout.
+var pages = func() map[string]*template.Template {
+	layout := template.Must(template.New("").Funcs(funcs).ParseFS(files, "templates/layout.html"))
+	out := make(map[string]*template.Template)
+	for _, name := range []string{"list", "product", "error"} {
+		t := template.Must(layout.Clone())
+		out[name] = template.Must(t.ParseFS(files, "templates/"+name+".html"))
+	}
+	return out
+}()
+
+// Currency formats v as dollars and cents with thousands separators, e.g.
+// $1,234.50. Rounding is to the nearest cent.
+func Currency(v float64) string {
+	cents := int64(math.Round(math.Abs(v) * 100))
+	digits := strconv.FormatInt(cents/100, 10)
+	var b strings.Builder
+	if v < 0 && cents > 0 {
+		b.WriteByte('-')
+	}
+	b.WriteByte('$')
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	fmt.Fprintf(&b, ".%02d", cents%100)
+	return b.String()
+}
+
+type listPage struct {
+	Title    string
+	Query    string
+	Products []product.Product
+}
+
+type productPage struct {
+	Title   string
+	Product product.Product
+	Related []product.Product
+}
+
+type errorPage struct {
+	Title   string
+	Message string
+}
+
+// searchLimit caps the results of a search on /catalog.
+const searchLimit = 50
+
+// Handler serves the catalog pages from svc.
+func Handler(svc *product.Service) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /catalog", func(w http.ResponseWriter, r *http.Request) {
+		page := &listPage{Title: "Catalog", Query: patterns.SanitizeSearch(r.URL.Query().Get("q"))}
+		var err error
+		if page.Query != "" {
+			page.Title = "Search"
+			page.Products, err = svc.Suggest(r.Context(), page.Query, searchLimit)
+		} else {
+			page.Products, err = svc.List(r.Context())
+		}
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+		render(w, r, http.StatusOK, "list", page)
+	})
+	mux.HandleFunc("GET /catalog/{sku}", func(w http.ResponseWriter, r *http.Request) {
+		p, err := svc.Get(r.Context(), r.PathValue("sku"))
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+		related, err := svc.Related(r.Context(), p.SKU)
+		if err != nil {
+			renderError(w, r, err)
+			return
+		}
+		render(w, r, http.StatusOK, "product", &productPage{Title: p.Name, Product: p, Related: related})
+	})
+	return mux
+}
+
+// render executes the named page into a buffer first, so a template error
+// becomes a clean 500 rather than half a page.
+func render(w http.ResponseWriter, r *http.Request, status int, name string, data any) {
+	var buf bytes.Buffer
+	if err := pages[name].ExecuteTemplate(&buf, "layout", data); err != nil {
+		logging.FromContext(r.Context()).Error("catalog: render failed", "page", name, "err", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
+
+// renderError shows err as a page. As in the JSON API, only not-found
+// errors are described; anything else gets the bare status text.
+func renderError(w http.ResponseWriter, r *http.Request, err error) {
+	status := apperr.StatusOf(err)
+	page := &errorPage{Title: http.StatusText(status), Message: "Something went wrong. Please try again later."}
+	var nf *apperr.NotFoundError
+	if errors.As(err, &nf) {
+		page.Message = "There is no product with SKU " + nf.Key + "."
+	}
+	if status >= 500 {
+		logging.FromContext(r.Context()).Error("request failed", "status", status, "err", err)
+	}
+	render(w, r, status, "error", page)
+}
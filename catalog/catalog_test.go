@@ -0,0 +1,107 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stawuah/pounce-on-go/golden"
+	"github.com/stawuah/pounce-on-go/product"
+)
+
+func TestCurrency(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, "$0.00"},
+		{9, "$9.00"},
+		{0.995, "$1.00"},
+		{999.99, "$999.99"},
+		{1234.5, "$1,234.50"},
+		{1234567.891, "$1,234,567.89"},
+		{-42.1, "-$42.10"},
+		{-0.001, "$0.00"},
+	}
+	for _, tt := range tests {
+		if got := Currency(tt.v); got != tt.want {
+			t.Errorf("Currency(%v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func fixture(t *testing.T) (*product.Repository, *product.Service) {
+	t.Helper()
+	ctx := context.Background()
+	repo := product.NewRepository()
+	svc := &product.Service{Repo: repo}
+	for _, p := range []product.Product{
+		{SKU: "A1", Name: "Anvil", Price: 1249.5},
+		{SKU: "H1", Name: "Hammer", Price: 12},
+		{SKU: "N1", Name: "Nails <100>", Price: 3.25},
+	} {
+		if err := svc.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo.Relate(ctx, "H1", "N1")
+	return repo, svc
+}
+
+func TestPages(t *testing.T) {
+	repo, svc := fixture(t)
+	h := Handler(svc)
+	tests := []struct {
+		name   string
+		path   string
+		fail   error
+		status int
+	}{
+		{"list", "/catalog", nil, 200},
+		{"search", "/catalog?q=ha", nil, 200},
+		{"search-empty", "/catalog?q=%3Cb%3E", nil, 200},
+		{"product", "/catalog/H1", nil, 200},
+		{"not-found", "/catalog/Z9", nil, 404},
+		{"outage", "/catalog", errors.New("dial tcp 10.0.0.7: refused"), 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Fail = tt.fail
+			defer func() { repo.Fail = nil }()
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+				t.Fatalf("Content-Type = %q", ct)
+			}
+			golden.Assert(t, tt.name+".html", rec.Body.Bytes())
+		})
+	}
+}
+
+// TestConcurrentRequests checks, under -race, that requests share only
+// the parsed templates and never each other's page data.
+func TestConcurrentRequests(t *testing.T) {
+	_, svc := fixture(t)
+	h := Handler(svc)
+	var wg sync.WaitGroup
+	for _, sku := range []string{"A1", "H1", "N1"} {
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, httptest.NewRequest("GET", "/catalog/"+sku, nil))
+				if !strings.Contains(rec.Body.String(), "<dd>"+sku+"</dd>") {
+					t.Errorf("page for %s shows another product", sku)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
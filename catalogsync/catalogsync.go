@@ -0,0 +1,388 @@
+// Package catalogsync keeps a local copy of the product catalog for a
+// client that is not always connected, such as the pounce CLI.
+//
+// A Replica holds the catalog in files in a directory of its own and
+// changes them in three ways:
+//
+//   - Pull fetches what changed since the last pull from GET /admin/diff
+//     (see product.Snapshotter), or the whole catalog the first time and
+//     whenever the server no longer has the snapshot the replica last saw.
+//   - Follow keeps pulling as the server's event stream, GET /events,
+//     says products change, and pulls again after every reconnect, since
+//     events sent while disconnected are lost.
+//   - Put edits a product locally, even offline. The edit shows at once
+//     and waits in the replica until Push sends it to the server.
+//
+// A server change to a product with an edit still waiting is a Conflict,
+// which the replica's Resolver settles: ServerWins, the default, drops the
+// edit, ClientWins keeps it to push, and a Resolver of one's own can
+// merge the two.
+//
+// Each change to the local files is one repository.Unit, so a crash
+// leaves the products and the waiting edits consistent with each other.
+// The last snapshot seen is saved after the changes it led to; a crash in
+// between only means the next Pull fetches the same changes again, which
+// changes nothing.
+package catalogsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/apiclient"
+	"github.com/stawuah/pounce-on-go/apperr"
+	"github.com/stawuah/pounce-on-go/fileio"
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/product"
+	"github.com/stawuah/pounce-on-go/repository"
+)
+
+// Conflict is a change from the server to a product with a local edit
+// not yet pushed.
+type Conflict struct {
+	Local   product.Product // the waiting edit
+	Remote  product.Product // the server's version, unless Removed
+	Removed bool            // the server no longer has the product
+}
+
+// A Resolver settles a Conflict. It returns the product to keep as the
+// local edit, which will be pushed, or ok false to drop the edit and take
+// the server's version.
+type Resolver func(c Conflict) (keep product.Product, ok bool)
+
+// ServerWins drops the local edit.
+func ServerWins(Conflict) (product.Product, bool) { return product.Product{}, false }
+
+// ClientWins keeps the local edit, so it overwrites the server's change
+// when pushed.
+func ClientWins(c Conflict) (product.Product, bool) { return c.Local, true }
+
+// Stats counts what a Pull changed locally.
+type Stats struct {
+	Added, Changed, Removed int
+	Conflicts               int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%d added, %d changed, %d removed, %d conflicts", s.Added, s.Changed, s.Removed, s.Conflicts)
+}
+
+// Option configures a Replica.
+type Option func(*Replica)
+
+// WithResolver sets how conflicts are settled; the default is ServerWins.
+func WithResolver(r Resolver) Option { return func(rp *Replica) { rp.resolve = r } }
+
+// WithLogger sets where Follow reports lost connections; the default is
+// slog.Default().
+func WithLogger(l *slog.Logger) Option { return func(rp *Replica) { rp.log = l } }
+
+// WithReconnectDelay sets how long Follow waits before reconnecting; the
+// default is 2s.
+func WithReconnectDelay(d time.Duration) Option { return func(rp *Replica) { rp.reconnect = d } }
+
+// Replica is a local copy of the catalog served at an API. It is safe for
+// concurrent use; changes are applied one at a time.
+type Replica struct {
+	api       *apiclient.Client
+	dir       string
+	resolve   Resolver
+	log       *slog.Logger
+	reconnect time.Duration
+
+	mu sync.Mutex // held while changing anything below
+	// products is what the client sees, local edits included; pending
+	// holds the edits not yet pushed.
+	products *repository.Repository[string, product.Product]
+	pending  *repository.Repository[string, product.Product]
+	snapshot string // the last snapshot pulled; saved in stateFile
+}
+
+const (
+	productsFile = "products.ndjson"
+	pendingFile  = "pending.ndjson"
+	stateFile    = "state.json"
+)
+
+type state struct {
+	Snapshot string `json:"snapshot"`
+}
+
+func productSKU(p product.Product) string { return p.SKU }
+
+var bySKU = repository.WithOrder[string](func(a, b product.Product) int { return strings.Compare(a.SKU, b.SKU) })
+
+// Open opens the replica kept in dir, creating dir if need be, of the
+// catalog api serves. A new replica is empty until its first Pull.
+func Open(dir string, api *apiclient.Client, opts ...Option) (*Replica, error) {
+	r := &Replica{api: api, dir: dir, resolve: ServerWins, log: slog.Default(), reconnect: 2 * time.Second}
+	for _, o := range opts {
+		o(r)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("catalogsync: %w", err)
+	}
+	var err error
+	if r.products, err = repository.Open(filepath.Join(dir, productsFile), productSKU, bySKU); err != nil {
+		return nil, fmt.Errorf("catalogsync: %w", err)
+	}
+	if r.pending, err = repository.Open(filepath.Join(dir, pendingFile), productSKU, bySKU); err != nil {
+		return nil, fmt.Errorf("catalogsync: %w", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("catalogsync: %w", err)
+	}
+	if len(data) > 0 {
+		var s state
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("catalogsync: %s: %w", stateFile, err)
+		}
+		r.snapshot = s.Snapshot
+	}
+	return r, nil
+}
+
+// Get returns the product with sku as the replica has it.
+func (r *Replica) Get(sku string) (product.Product, bool) { return r.products.Get(sku) }
+
+// List returns every product, ordered by SKU.
+func (r *Replica) List() []product.Product { return r.products.All() }
+
+// Pending returns the local edits waiting for Push, ordered by SKU.
+func (r *Replica) Pending() []product.Product { return r.pending.All() }
+
+// Snapshot returns the ID of the last snapshot pulled, or "" before the
+// first Pull.
+func (r *Replica) Snapshot() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.snapshot
+}
+
+// Put edits p locally, replacing any product with its SKU, and keeps the
+// edit for Push.
+func (r *Replica) Put(p product.Product) error {
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("catalogsync: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return repository.Do(func(u *repository.Unit) error {
+		repository.Stage(u, r.products).Put(p)
+		repository.Stage(u, r.pending).Put(p)
+		return nil
+	})
+}
+
+// Pull brings the replica up to date with the server. Starting over from
+// the full catalog, it cannot tell a product the server removed from one
+// created here, so it keeps every waiting edit for Push.
+func (r *Replica) Pull(ctx context.Context) (Stats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	full := r.snapshot == ""
+	d, err := r.api.Diff(ctx, r.snapshot)
+	if errors.Is(err, apperr.ErrGone) {
+		full = true
+		d, err = r.api.Diff(ctx, "")
+	}
+	if err != nil {
+		return Stats{}, fmt.Errorf("catalogsync: pull: %w", err)
+	}
+	removed := d.Removed
+	if full {
+		// A diff from nothing lists only what the server has; anything
+		// else here is gone, except edits that have not reached it.
+		have := make(map[string]bool, len(d.Added))
+		for _, p := range d.Added {
+			have[p.SKU] = true
+		}
+		for _, p := range r.products.All() {
+			if _, waiting := r.pending.Get(p.SKU); !have[p.SKU] && !waiting {
+				removed = append(removed, p.SKU)
+			}
+		}
+	}
+	stats, err := r.apply(slices.Concat(d.Added, d.Changed), removed)
+	if err != nil {
+		return stats, err
+	}
+	return stats, r.saveSnapshot(d.Snapshot)
+}
+
+// apply stores the server's versions of puts and removes the SKUs in
+// removes, settling conflicts with local edits. r.mu must be held.
+func (r *Replica) apply(puts []product.Product, removes []string) (Stats, error) {
+	var s Stats
+	err := repository.Do(func(u *repository.Unit) error {
+		s = Stats{}
+		products := repository.Stage(u, r.products)
+		pending := repository.Stage(u, r.pending)
+		// settle handles a change from the server to a SKU with a
+		// waiting edit.
+		settle := func(c Conflict) {
+			if !c.Removed && c.Remote == c.Local {
+				pending.Delete(c.Local.SKU) // the edit has reached the server
+				return
+			}
+			s.Conflicts++
+			if keep, ok := r.resolve(c); ok {
+				products.Put(keep)
+				pending.Put(keep)
+				return
+			}
+			pending.Delete(c.Local.SKU)
+			if c.Removed {
+				products.Delete(c.Local.SKU)
+			} else {
+				products.Put(c.Remote)
+			}
+		}
+		for _, p := range puts {
+			if local, ok := pending.Get(p.SKU); ok {
+				settle(Conflict{Local: local, Remote: p})
+				continue
+			}
+			switch old, ok := products.Get(p.SKU); {
+			case !ok:
+				s.Added++
+			case old != p:
+				s.Changed++
+			default:
+				continue
+			}
+			products.Put(p)
+		}
+		for _, sku := range removes {
+			if local, ok := pending.Get(sku); ok {
+				settle(Conflict{Local: local, Removed: true})
+				continue
+			}
+			if _, ok := products.Get(sku); ok {
+				products.Delete(sku)
+				s.Removed++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Stats{}, fmt.Errorf("catalogsync: %w", err)
+	}
+	return s, nil
+}
+
+func (r *Replica) saveSnapshot(id string) error {
+	if id == r.snapshot {
+		return nil
+	}
+	err := fileio.WriteAtomic(filepath.Join(r.dir, stateFile), 0o644, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(state{Snapshot: id})
+	})
+	if err != nil {
+		return fmt.Errorf("catalogsync: %w", err)
+	}
+	r.snapshot = id
+	return nil
+}
+
+// Push sends the waiting local edits to the server, in SKU order, and
+// returns how many it sent. It stops at the first that fails, which stays
+// waiting; one the server rejects as invalid, apperr.ErrInvalid, will
+// keep failing until it is edited again.
+func (r *Replica) Push(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, p := range r.pending.All() {
+		if err := r.api.Create(ctx, p); err != nil {
+			return n, fmt.Errorf("catalogsync: push %s: %w", p.SKU, err)
+		}
+		if err := r.pending.Delete(p.SKU); err != nil {
+			return n, fmt.Errorf("catalogsync: %w", err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Follow keeps the replica up to date until ctx ends, and then returns
+// its error. It subscribes to the server's events, pulls, then applies
+// each product the events name as it changes, fetching the product
+// itself since events carry only part of it. Subscribing first means a
+// change made during the pull is not missed. When the stream breaks it
+// waits, reconnects and pulls again to catch up on what it missed.
+func (r *Replica) Follow(ctx context.Context) error {
+	for {
+		err := r.follow(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		r.log.Warn("catalogsync: lost the event stream", "err", err, "retry_in", r.reconnect)
+		select {
+		case <-time.After(r.reconnect):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (r *Replica) follow(ctx context.Context) error {
+	stream, err := r.api.OpenEvents(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	if _, err := r.Pull(ctx); err != nil {
+		return err
+	}
+	err = stream.Follow(func(e jsonx.Event) error {
+		var sku string
+		switch p := e.Payload.(type) {
+		case jsonx.ProductCreated:
+			sku = p.SKU
+		case jsonx.PriceChanged:
+			sku = p.SKU
+		case jsonx.StatusChanged:
+			sku = p.SKU
+		case jsonx.ProductUpdated:
+			sku = p.SKU
+		default:
+			return nil
+		}
+		return r.refresh(ctx, sku)
+	})
+	if err == nil {
+		err = errors.New("server closed the stream")
+	}
+	return err
+}
+
+// refresh applies the server's current version of sku.
+func (r *Replica) refresh(ctx context.Context, sku string) error {
+	p, err := r.api.Get(ctx, sku)
+	var puts []product.Product
+	var removes []string
+	switch {
+	case err == nil:
+		puts = []product.Product{p}
+	case errors.Is(err, apperr.ErrNotFound):
+		removes = []string{sku}
+	default:
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.apply(puts, removes)
+	return err
+}
@@ -0,0 +1,275 @@
+package catalogsync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/apiclient"
+	"github.com/stawuah/pounce-on-go/apperr"
+	"github.com/stawuah/pounce-on-go/eventbus"
+	"github.com/stawuah/pounce-on-go/eventbus/bridge"
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/product"
+)
+
+// server is a catalog API the way pounce serve mounts it.
+type server struct {
+	svc    *product.Service
+	topic  *eventbus.Topic[jsonx.Event]
+	client *apiclient.Client
+	pulled func() // if set, runs after each GET /admin/diff is answered
+}
+
+func newServer(t *testing.T, products ...product.Product) *server {
+	t.Helper()
+	topic := eventbus.NewTopic[jsonx.Event]("products")
+	t.Cleanup(topic.Close)
+	s := &server{svc: &product.Service{Repo: product.NewRepository(), Events: topic}, topic: topic}
+	for _, p := range products {
+		if err := s.svc.Create(context.Background(), p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	api := product.Handler(s.svc)
+	mux := http.NewServeMux()
+	mux.Handle("/products", api)
+	mux.Handle("/products/", api)
+	diff := product.DiffHandler(product.NewSnapshotter(s.svc, 0))
+	mux.HandleFunc("GET /admin/diff", func(w http.ResponseWriter, r *http.Request) {
+		diff.ServeHTTP(w, r)
+		if s.pulled != nil {
+			s.pulled()
+		}
+	})
+	mux.Handle("GET /events", &bridge.SSE[jsonx.Event]{Topic: topic})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	var err error
+	if s.client, err = apiclient.New(srv.URL + "/"); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func (s *server) get(t *testing.T, sku string) product.Product {
+	t.Helper()
+	p, err := s.svc.Repo.Get(context.Background(), sku)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func open(t *testing.T, dir string, s *server, opts ...Option) *Replica {
+	t.Helper()
+	r, err := Open(dir, s.client, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func skus(ps []product.Product) []string {
+	out := make([]string, len(ps))
+	for i, p := range ps {
+		out[i] = p.SKU
+	}
+	return out
+}
+
+var (
+	anvil  = product.Product{SKU: "A1", Name: "Anvil", Price: 40}
+	bolt   = product.Product{SKU: "B1", Name: "Bolt", Price: 1}
+	chisel = product.Product{SKU: "C1", Name: "Chisel", Price: 18}
+)
+
+func TestPull(t *testing.T) {
+	ctx := context.Background()
+	s := newServer(t, anvil, bolt)
+	dir := t.TempDir()
+	r := open(t, dir, s)
+
+	if st, err := r.Pull(ctx); err != nil || st != (Stats{Added: 2}) {
+		t.Fatalf("first Pull = %v, %v", st, err)
+	}
+	s.svc.Create(ctx, product.Product{SKU: "B1", Name: "Bolt", Price: 2})
+	s.svc.Create(ctx, chisel)
+	if st, err := r.Pull(ctx); err != nil || st != (Stats{Added: 1, Changed: 1}) {
+		t.Fatalf("second Pull = %v, %v", st, err)
+	}
+
+	// Everything survives a restart, so the next pull has nothing to do.
+	again := open(t, dir, s)
+	if again.Snapshot() != r.Snapshot() || !slices.Equal(skus(again.List()), []string{"A1", "B1", "C1"}) {
+		t.Fatalf("reopened: snapshot %q, products %v", again.Snapshot(), skus(again.List()))
+	}
+	if p, _ := again.Get("B1"); p.Price != 2 {
+		t.Fatalf("reopened B1 = %+v", p)
+	}
+	if st, err := again.Pull(ctx); err != nil || st != (Stats{}) {
+		t.Fatalf("Pull after reopening = %v, %v", st, err)
+	}
+}
+
+func TestPutAndPush(t *testing.T) {
+	ctx := context.Background()
+	s := newServer(t, anvil)
+	r := open(t, t.TempDir(), s)
+	r.Pull(ctx)
+
+	if err := r.Put(product.Product{SKU: "A1", Name: "Anvil", Price: -1}); !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("Put with a negative price = %v, want ErrInvalid", err)
+	}
+	edit := product.Product{SKU: "A1", Name: "Anvil", Price: 35}
+	r.Put(edit)
+	r.Put(chisel)
+	if p, _ := r.Get("A1"); p != edit {
+		t.Fatalf("Get after Put = %+v", p)
+	}
+	if got := skus(r.Pending()); !slices.Equal(got, []string{"A1", "C1"}) {
+		t.Fatalf("Pending = %v", got)
+	}
+	if s.get(t, "A1") != anvil {
+		t.Fatal("Put reached the server before Push")
+	}
+
+	if n, err := r.Push(ctx); n != 2 || err != nil {
+		t.Fatalf("Push = %d, %v", n, err)
+	}
+	if len(r.Pending()) != 0 || s.get(t, "A1") != edit || s.get(t, "C1") != chisel {
+		t.Fatalf("after Push: pending %v, server A1 %+v", skus(r.Pending()), s.get(t, "A1"))
+	}
+	// The server now has what the replica has.
+	if st, err := r.Pull(ctx); err != nil || st != (Stats{}) {
+		t.Fatalf("Pull after Push = %v, %v", st, err)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	local := product.Product{SKU: "A1", Name: "Anvil", Price: 35}
+	remote := product.Product{SKU: "A1", Name: "Anvil, forged", Price: 45}
+	tests := []struct {
+		name    string
+		resolve Resolver
+		want    product.Product // kept locally after Pull
+		pending bool
+	}{
+		{"server wins", ServerWins, remote, false},
+		{"client wins", ClientWins, local, true},
+		{"merge", func(c Conflict) (product.Product, bool) {
+			p := c.Remote
+			p.Price = c.Local.Price // our price, their name
+			return p, true
+		}, product.Product{SKU: "A1", Name: "Anvil, forged", Price: 35}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newServer(t, anvil)
+			r := open(t, t.TempDir(), s, WithResolver(tt.resolve))
+			r.Pull(ctx)
+			r.Put(local)
+			s.svc.Create(ctx, remote)
+
+			if st, err := r.Pull(ctx); err != nil || st != (Stats{Conflicts: 1}) {
+				t.Fatalf("Pull = %v, %v", st, err)
+			}
+			if p, _ := r.Get("A1"); p != tt.want {
+				t.Fatalf("Get = %+v, want %+v", p, tt.want)
+			}
+			if got := len(r.Pending()) == 1; got != tt.pending {
+				t.Fatalf("pending = %v, want %v", got, tt.pending)
+			}
+			r.Push(ctx)
+			if tt.pending && s.get(t, "A1") != tt.want {
+				t.Fatalf("server after Push = %+v", s.get(t, "A1"))
+			}
+		})
+	}
+}
+
+func TestResyncAfterGone(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	r := open(t, dir, newServer(t, anvil, bolt, chisel))
+	r.Pull(ctx)
+	r.Put(product.Product{SKU: "B1", Name: "Bolt", Price: 3})
+	r.Put(product.Product{SKU: "D1", Name: "Drill", Price: 120})
+
+	// Against another server, or this one restarted, the snapshot the
+	// replica knows is gone, so it starts over from the full catalog.
+	// That drops C1, which the server no longer has, but keeps the edits.
+	s := newServer(t, anvil)
+	r = open(t, dir, s)
+	st, err := r.Pull(ctx)
+	if err != nil || st != (Stats{Removed: 1}) {
+		t.Fatalf("Pull = %v, %v", st, err)
+	}
+	if got := skus(r.List()); !slices.Equal(got, []string{"A1", "B1", "D1"}) {
+		t.Fatalf("List = %v, want the server's and the local edits", got)
+	}
+	r.Push(ctx)
+	if st, err := r.Pull(ctx); err != nil || st != (Stats{}) {
+		t.Fatalf("Pull after Push = %v, %v", st, err)
+	}
+}
+
+// waitFor polls ok until it holds or five seconds have passed.
+func waitFor(t *testing.T, what string, ok func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !ok(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+// follow runs r.Follow until the test ends.
+func follow(t *testing.T, r *Replica) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- r.Follow(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("Follow = %v, want context.Canceled", err)
+		}
+	})
+}
+
+func TestFollow(t *testing.T) {
+	s := newServer(t, anvil)
+	r := open(t, t.TempDir(), s, WithReconnectDelay(time.Millisecond))
+	follow(t, r)
+
+	// The first pull comes after the subscription, so no waiting for a
+	// subscriber here.
+	waitFor(t, "the first pull", func() bool { _, ok := r.Get("A1"); return ok })
+	s.svc.Create(context.Background(), chisel)
+	waitFor(t, "the new product", func() bool { p, _ := r.Get("C1"); return p == chisel })
+	s.svc.Create(context.Background(), product.Product{SKU: "A1", Name: "Anvil", Price: 50})
+	waitFor(t, "the new price", func() bool { p, _ := r.Get("A1"); return p.Price == 50 })
+	renamed := product.Product{SKU: "A1", Name: "Big anvil", Category: "tools", Price: 50}
+	s.svc.Create(context.Background(), renamed)
+	waitFor(t, "the new name and category", func() bool { p, _ := r.Get("A1"); return p == renamed })
+}
+
+// A change the server makes just after answering the first pull reaches
+// the replica through the stream, which was already open.
+func TestFollowChangeDuringPull(t *testing.T) {
+	s := newServer(t, anvil)
+	var once sync.Once
+	s.pulled = func() {
+		once.Do(func() { s.svc.Create(context.Background(), chisel) })
+	}
+	r := open(t, t.TempDir(), s, WithReconnectDelay(time.Hour))
+	follow(t, r)
+
+	waitFor(t, "the product created after the pull", func() bool { p, _ := r.Get("C1"); return p == chisel })
+}
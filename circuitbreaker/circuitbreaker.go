@@ -0,0 +1,210 @@
+// Package circuitbreaker stops calling a dependency that keeps failing.
+//
+// A Breaker starts Closed and passes calls through. After a run of
+// consecutive failures it trips Open and rejects calls with ErrOpen
+// without running them. Once the reset timeout has passed it lets a
+// single trial call through (HalfOpen): success closes the breaker,
+// failure opens it for another timeout.
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+// ErrOpen is returned by Do while the breaker is rejecting calls.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is a breaker state.
+type State int
+
+const (
+	Closed   State = iota // calls pass through
+	Open                  // calls are rejected with ErrOpen
+	HalfOpen              // one trial call decides the next state
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Option configures a Breaker.
+type Option func(*Breaker)
+
+// WithFailureThreshold sets how many consecutive failures trip the
+// breaker. The default is 5.
+func WithFailureThreshold(n int) Option {
+	return func(b *Breaker) { b.threshold = max(n, 1) }
+}
+
+// WithResetTimeout sets how long the breaker stays open before a trial
+// call. The default is 30s.
+func WithResetTimeout(d time.Duration) Option {
+	return func(b *Breaker) { b.timeout = d }
+}
+
+// WithIsFailure decides which errors count as failures. By default every
+// non-nil error does except context cancellation, which says more about
+// the caller than about the dependency.
+func WithIsFailure(f func(error) bool) Option {
+	return func(b *Breaker) { b.isFailure = f }
+}
+
+// OnStateChange registers f to be called after every transition. It runs
+// synchronously on the goroutine whose call caused the change, after the
+// breaker's lock is released.
+func OnStateChange(f func(from, to State)) Option {
+	return func(b *Breaker) { b.onChange = f }
+}
+
+// WithClock makes the breaker read time from c when deciding whether an
+// open breaker may try again.
+func WithClock(c clock.Clock) Option {
+	return func(b *Breaker) { b.clock = c }
+}
+
+// Breaker guards calls to one dependency. It is safe for concurrent use.
+type Breaker struct {
+	threshold int
+	timeout   time.Duration
+	isFailure func(error) bool
+	onChange  func(from, to State)
+	clock     clock.Clock
+
+	mu       sync.Mutex
+	state    State
+	failures int       // consecutive failures while closed
+	openedAt time.Time // when the breaker last opened
+	trial    bool      // a half-open trial call is in flight
+}
+
+// New returns a closed breaker.
+func New(opts ...Option) *Breaker {
+	b := &Breaker{
+		threshold: 5,
+		timeout:   30 * time.Second,
+		isFailure: func(err error) bool {
+			return err != nil && !errors.Is(err, context.Canceled)
+		},
+		clock: clock.Real{},
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// State returns the current state, moving an open breaker whose timeout
+// has passed to HalfOpen.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	from, to := b.state, b.advance()
+	b.mu.Unlock()
+	b.notify(from, to)
+	return to
+}
+
+// OpenUntil returns when an open breaker will half-open and let a trial
+// call through, or the zero time if it is not open.
+func (b *Breaker) OpenUntil() time.Time {
+	b.mu.Lock()
+	from, to := b.state, b.advance()
+	until := b.openedAt.Add(b.timeout)
+	b.mu.Unlock()
+	b.notify(from, to)
+	if to != Open {
+		return time.Time{}
+	}
+	return until
+}
+
+// Do runs fn if the breaker allows it and records the outcome. While the
+// breaker is open, or a half-open trial is already running, it returns
+// ErrOpen without calling fn.
+func (b *Breaker) Do(ctx context.Context, fn func(context.Context) error) error {
+	if err := b.before(); err != nil {
+		return err
+	}
+	err := fn(ctx)
+	b.after(err)
+	return err
+}
+
+func (b *Breaker) before() error {
+	b.mu.Lock()
+	from := b.state
+	to := b.advance()
+	var err error
+	switch to {
+	case Open:
+		err = ErrOpen
+	case HalfOpen:
+		if b.trial {
+			err = ErrOpen
+		} else {
+			b.trial = true
+		}
+	}
+	b.mu.Unlock()
+	b.notify(from, to)
+	return err
+}
+
+func (b *Breaker) after(err error) {
+	b.mu.Lock()
+	from := b.state
+	failed := b.isFailure(err)
+	switch b.state {
+	case Closed:
+		if !failed {
+			b.failures = 0
+		} else if b.failures++; b.failures >= b.threshold {
+			b.trip()
+		}
+	case HalfOpen:
+		b.trial = false
+		if failed {
+			b.trip()
+		} else {
+			b.state = Closed
+			b.failures = 0
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+// advance moves Open to HalfOpen once the timeout has passed and returns
+// the resulting state. b.mu must be held.
+func (b *Breaker) advance() State {
+	if b.state == Open && !b.clock.Now().Before(b.openedAt.Add(b.timeout)) {
+		b.state = HalfOpen
+	}
+	return b.state
+}
+
+// trip opens the breaker. b.mu must be held.
+func (b *Breaker) trip() {
+	b.state = Open
+	b.openedAt = b.clock.Now()
+	b.failures = 0
+}
+
+func (b *Breaker) notify(from, to State) {
+	if from != to && b.onChange != nil {
+		b.onChange(from, to)
+	}
+}
@@ -0,0 +1,171 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+var errDown = errors.New("webhook endpoint down")
+
+func fail(context.Context) error    { return errDown }
+func succeed(context.Context) error { return nil }
+
+// newBreaker returns a breaker on a fake clock that records transitions.
+func newBreaker(opts ...Option) (*Breaker, *clock.Fake, *[]string) {
+	clk := clock.NewFake(time.Unix(1_000, 0))
+	var transitions []string
+	opts = append([]Option{
+		WithFailureThreshold(3),
+		WithResetTimeout(10 * time.Second),
+		WithClock(clk),
+		OnStateChange(func(from, to State) {
+			transitions = append(transitions, fmt.Sprintf("%s->%s", from, to))
+		}),
+	}, opts...)
+	return New(opts...), clk, &transitions
+}
+
+func TestTripsAfterThreshold(t *testing.T) {
+	b, _, _ := newBreaker()
+	ctx := context.Background()
+
+	for i := range 3 {
+		if err := b.Do(ctx, fail); !errors.Is(err, errDown) {
+			t.Fatalf("call %d: err = %v", i, err)
+		}
+	}
+	if b.State() != Open {
+		t.Fatalf("state = %s, want open", b.State())
+	}
+	called := false
+	err := b.Do(ctx, func(context.Context) error { called = true; return nil })
+	if !errors.Is(err, ErrOpen) || called {
+		t.Fatalf("open breaker: err = %v, called = %t", err, called)
+	}
+}
+
+func TestOpenUntil(t *testing.T) {
+	b, clk, _ := newBreaker()
+	if !b.OpenUntil().IsZero() {
+		t.Fatalf("closed breaker: OpenUntil = %v", b.OpenUntil())
+	}
+	for range 3 {
+		b.Do(context.Background(), fail)
+	}
+	if want := clk.Now().Add(10 * time.Second); !b.OpenUntil().Equal(want) {
+		t.Fatalf("open breaker: OpenUntil = %v, want %v", b.OpenUntil(), want)
+	}
+	clk.Advance(10 * time.Second)
+	if !b.OpenUntil().IsZero() || b.State() != HalfOpen {
+		t.Fatalf("after timeout: OpenUntil = %v, state = %s", b.OpenUntil(), b.State())
+	}
+}
+
+func TestSuccessResetsFailureCount(t *testing.T) {
+	b, _, _ := newBreaker()
+	ctx := context.Background()
+	for range 5 {
+		b.Do(ctx, fail)
+		b.Do(ctx, fail)
+		b.Do(ctx, succeed)
+	}
+	if b.State() != Closed {
+		t.Fatalf("state = %s, want closed", b.State())
+	}
+}
+
+func TestFullCycle(t *testing.T) {
+	b, clk, transitions := newBreaker()
+	ctx := context.Background()
+
+	for range 3 {
+		b.Do(ctx, fail)
+	}
+	clk.Advance(9 * time.Second)
+	if err := b.Do(ctx, succeed); !errors.Is(err, ErrOpen) {
+		t.Fatalf("before timeout: err = %v, want ErrOpen", err)
+	}
+
+	clk.Advance(time.Second)
+	if b.Do(ctx, fail); b.State() != Open {
+		t.Fatalf("failed trial: state = %s, want open", b.State())
+	}
+
+	clk.Advance(10 * time.Second)
+	if err := b.Do(ctx, succeed); err != nil {
+		t.Fatalf("trial: %v", err)
+	}
+	if b.State() != Closed {
+		t.Fatalf("state = %s, want closed", b.State())
+	}
+
+	want := []string{
+		"closed->open",
+		"open->half-open", "half-open->open",
+		"open->half-open", "half-open->closed",
+	}
+	if !slices.Equal(*transitions, want) {
+		t.Fatalf("transitions = %v, want %v", *transitions, want)
+	}
+}
+
+func TestHalfOpenAllowsOneTrial(t *testing.T) {
+	b, clk, _ := newBreaker()
+	ctx := context.Background()
+	for range 3 {
+		b.Do(ctx, fail)
+	}
+	clk.Advance(10 * time.Second)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		b.Do(ctx, func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+	if err := b.Do(ctx, succeed); !errors.Is(err, ErrOpen) {
+		t.Fatalf("second trial: err = %v, want ErrOpen", err)
+	}
+	close(release)
+	wg.Wait()
+	if b.State() != Closed {
+		t.Fatalf("state = %s, want closed", b.State())
+	}
+}
+
+func TestCancellationIsNotAFailure(t *testing.T) {
+	b, _, _ := newBreaker()
+	for range 10 {
+		b.Do(context.Background(), func(context.Context) error { return context.Canceled })
+	}
+	if b.State() != Closed {
+		t.Fatalf("state = %s, want closed", b.State())
+	}
+}
+
+func TestWithIsFailure(t *testing.T) {
+	errNotFound := errors.New("not found")
+	b, _, _ := newBreaker(WithIsFailure(func(err error) bool {
+		return err != nil && !errors.Is(err, errNotFound)
+	}))
+	for range 10 {
+		b.Do(context.Background(), func(context.Context) error { return errNotFound })
+	}
+	if b.State() != Closed {
+		t.Fatalf("state = %s, want closed", b.State())
+	}
+}
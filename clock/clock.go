@@ -0,0 +1,131 @@
+// Package clock abstracts the time source so that code which reads the
+// time or sleeps can be tested without waiting.
+//
+// Production code takes a Clock and defaults to Real. Tests pass a *Fake,
+// whose time moves only when the test says so:
+//
+//	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	s := scheduler.New(scheduler.WithClock(clk))
+//	...
+//	clk.BlockUntil(1)          // the job's timer goroutine is waiting
+//	clk.Advance(time.Minute)   // and now it fires
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time and signals when a duration has passed.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is the system clock.
+type Real struct{}
+
+func (Real) Now() time.Time                         { return time.Now() }
+func (Real) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Fake is a Clock under a test's control. It is safe for concurrent use.
+//
+// A Fake made by NewFake moves only on Advance, which fires every After
+// channel that falls due. One made by NewAutoFake instead jumps forward
+// by d on each After(d) and fires at once, for code that only sleeps and
+// whose test only cares how long it would have slept.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	auto    bool
+	slept   time.Duration
+	waiters []waiter
+}
+
+type waiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewFake returns a Fake reading start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start}
+}
+
+// NewAutoFake returns a Fake reading start that advances itself whenever
+// something waits on it.
+func NewAutoFake(start time.Time) *Fake {
+	return &Fake{now: start, auto: true}
+}
+
+// Now returns the fake's current time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel that receives the fake time once it has moved
+// on by d. A d of zero or less fires at once.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	switch {
+	case f.auto:
+		d = max(d, 0)
+		f.now = f.now.Add(d)
+		f.slept += d
+		ch <- f.now
+	case d <= 0:
+		ch <- f.now
+	default:
+		f.waiters = append(f.waiters, waiter{f.now.Add(d), ch})
+	}
+	return ch
+}
+
+// Advance moves the time forward by d and fires the After channels that
+// are now due.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	kept := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.at.After(f.now) {
+			kept = append(kept, w)
+			continue
+		}
+		w.ch <- f.now
+	}
+	clear(f.waiters[len(kept):])
+	f.waiters = kept
+}
+
+// Waiters reports how many After channels have yet to fire.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil waits, in real time, until n After channels are pending, so
+// a test can Advance knowing the goroutines under test are already
+// asleep. It panics after ten seconds rather than hang the test binary.
+func (f *Fake) BlockUntil(n int) {
+	deadline := time.Now().Add(10 * time.Second)
+	for f.Waiters() != n {
+		if time.Now().After(deadline) {
+			panic("clock: BlockUntil timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Slept reports how far an auto-advancing Fake has moved itself.
+func (f *Fake) Slept() time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.slept
+}
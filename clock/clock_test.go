@@ -0,0 +1,86 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func fired(ch <-chan time.Time) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestFakeAdvanceFiresDueWaiters(t *testing.T) {
+	f := NewFake(epoch)
+	a := f.After(time.Second)
+	b := f.After(time.Minute)
+	if !fired(f.After(0)) {
+		t.Fatal("After(0) did not fire at once")
+	}
+	if f.Waiters() != 2 {
+		t.Fatalf("Waiters = %d, want 2", f.Waiters())
+	}
+
+	f.Advance(999 * time.Millisecond)
+	if fired(a) || fired(b) {
+		t.Fatal("fired early")
+	}
+	f.Advance(time.Millisecond)
+	select {
+	case at := <-a:
+		if !at.Equal(epoch.Add(time.Second)) {
+			t.Fatalf("fired with %v", at)
+		}
+	default:
+		t.Fatal("due waiter did not fire")
+	}
+	if fired(b) || f.Waiters() != 1 {
+		t.Fatal("later waiter fired or was dropped")
+	}
+	f.Advance(time.Hour)
+	if !fired(b) || f.Waiters() != 0 {
+		t.Fatal("Advance past every waiter left one pending")
+	}
+	if got := f.Now(); !got.Equal(epoch.Add(time.Hour + time.Second)) {
+		t.Fatalf("Now = %v", got)
+	}
+}
+
+func TestAutoFake(t *testing.T) {
+	f := NewAutoFake(epoch)
+	for _, d := range []time.Duration{time.Second, -time.Second, 2 * time.Second} {
+		if !fired(f.After(d)) {
+			t.Fatalf("After(%v) did not fire at once", d)
+		}
+	}
+	if f.Slept() != 3*time.Second || !f.Now().Equal(epoch.Add(3*time.Second)) {
+		t.Fatalf("Slept = %v, Now = %v", f.Slept(), f.Now())
+	}
+}
+
+func TestBlockUntil(t *testing.T) {
+	f := NewFake(epoch)
+	done := make(chan struct{})
+	go func() {
+		<-f.After(time.Minute)
+		close(done)
+	}()
+	f.BlockUntil(1)
+	f.Advance(time.Minute)
+	<-done
+}
+
+func TestRealSatisfiesClock(t *testing.T) {
+	var c Clock = Real{}
+	before := time.Now()
+	<-c.After(time.Millisecond)
+	if c.Now().Sub(before) < time.Millisecond {
+		t.Fatal("Real.After returned early")
+	}
+}
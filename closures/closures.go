@@ -0,0 +1,97 @@
+// Package closures shows what a Go closure captures: variables, not
+// values. Everything else follows from that, including the loop-variable
+// change in Go 1.22.
+package closures
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// CapturePerIteration collects one closure per loop iteration. Since Go
+// 1.22 each iteration has its own i, so the closures return 0, 1, 2.
+// CaptureShared in legacy.go is the same code under Go 1.21 rules.
+func CapturePerIteration() []func() int {
+	var fs []func() int
+	for i := 0; i < 3; i++ {
+		fs = append(fs, func() int { return i })
+	}
+	return fs
+}
+
+// CaptureHoisted declares the variable outside the loop. There is one i
+// for the whole loop on any Go version, so every closure sees its final
+// value.
+func CaptureHoisted() []func() int {
+	var fs []func() int
+	var i int
+	for i = 0; i < 3; i++ {
+		fs = append(fs, func() int { return i })
+	}
+	return fs
+}
+
+// Counter returns a function that yields 1, 2, 3, ... on successive
+// calls. The count lives in the closure, not in a global.
+func Counter() func() int {
+	n := 0
+	return func() int {
+		n++
+		return n
+	}
+}
+
+// Price is a small struct used to contrast capturing a value with
+// capturing a pointer.
+type Price struct{ Cents int }
+
+// SnapshotAndLive returns two readers: one captures a copy of p at call
+// time, the other captures the pointer and sees later changes.
+func SnapshotAndLive(p *Price) (snapshot, live func() int) {
+	copied := *p
+	return func() int { return copied.Cents }, func() int { return p.Cents }
+}
+
+// Product is the payload for the handler-factory example.
+type Product struct {
+	SKU  string `json:"sku"`
+	Name string `json:"name"`
+}
+
+// Catalog is a minimal concurrent product map.
+type Catalog struct {
+	mu    sync.Mutex
+	items map[string]Product
+}
+
+// NewCatalog returns an empty catalog.
+func NewCatalog() *Catalog { return &Catalog{items: make(map[string]Product)} }
+
+// Len returns the number of products.
+func (c *Catalog) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// CreateProductHandler is a handler factory: it closes over its
+// dependencies instead of reaching for globals, so each call produces an
+// independent handler and tests can pass their own catalog.
+func CreateProductHandler(c *Catalog, maxBody int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var p Product
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody)).Decode(&p); err != nil || p.SKU == "" {
+			http.Error(w, "invalid product", http.StatusBadRequest)
+			return
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if _, exists := c.items[p.SKU]; exists {
+			http.Error(w, "duplicate sku", http.StatusConflict)
+			return
+		}
+		c.items[p.SKU] = p
+		w.WriteHeader(http.StatusCreated)
+	}
+}
@@ -0,0 +1,85 @@
+package closures
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func call(fs []func() int) []int {
+	var out []int
+	for _, f := range fs {
+		out = append(out, f())
+	}
+	return out
+}
+
+func TestLoopCapture(t *testing.T) {
+	tests := []struct {
+		name string
+		fs   []func() int
+		want []int
+	}{
+		{"per iteration (Go 1.22+)", CapturePerIteration(), []int{0, 1, 2}},
+		{"shared (Go 1.21 file)", CaptureShared(), []int{3, 3, 3}},
+		{"hoisted variable", CaptureHoisted(), []int{3, 3, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := call(tt.fs); !slices.Equal(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCounterState(t *testing.T) {
+	a, b := Counter(), Counter()
+	a()
+	a()
+	if got := a(); got != 3 {
+		t.Fatalf("a() = %d, want 3", got)
+	}
+	if got := b(); got != 1 {
+		t.Fatalf("b() = %d; counters must not share state", got)
+	}
+}
+
+func TestSnapshotAndLive(t *testing.T) {
+	p := &Price{Cents: 100}
+	snapshot, live := SnapshotAndLive(p)
+	p.Cents = 250
+	if snapshot() != 100 || live() != 250 {
+		t.Fatalf("snapshot = %d, live = %d; want 100 and 250", snapshot(), live())
+	}
+}
+
+func TestCreateProductHandler(t *testing.T) {
+	// Two handlers from the same factory hold separate dependencies.
+	catA, catB := NewCatalog(), NewCatalog()
+	hA := CreateProductHandler(catA, 1<<10)
+	hB := CreateProductHandler(catB, 1<<10)
+
+	post := func(h http.Handler, body string) int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("POST", "/products", strings.NewReader(body)))
+		return rec.Code
+	}
+	if code := post(hA, `{"sku":"A1","name":"Anvil"}`); code != http.StatusCreated {
+		t.Fatalf("create: %d", code)
+	}
+	if code := post(hA, `{"sku":"A1","name":"Anvil"}`); code != http.StatusConflict {
+		t.Fatalf("duplicate: %d", code)
+	}
+	if code := post(hB, `{"sku":"A1","name":"Anvil"}`); code != http.StatusCreated {
+		t.Fatalf("second catalog: %d", code)
+	}
+	if code := post(hA, `{"name":"no sku"}`); code != http.StatusBadRequest {
+		t.Fatalf("invalid: %d", code)
+	}
+	if catA.Len() != 1 || catB.Len() != 1 {
+		t.Fatalf("catalog sizes = %d, %d", catA.Len(), catB.Len())
+	}
+}
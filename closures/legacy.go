@@ -0,0 +1,16 @@
+//go:build go1.21
+
+// The build constraint above sets this file's language version to Go 1.21,
+// before per-iteration loop variables, so the loop below shares one i.
+
+package closures
+
+// CaptureShared is CapturePerIteration compiled with Go 1.21 semantics:
+// every closure captures the same i and sees its final value, 3.
+func CaptureShared() []func() int {
+	var fs []func() int
+	for i := 0; i < 3; i++ {
+		fs = append(fs, func() int { return i })
+	}
+	return fs
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// accessor describes one generated Get method: the chain of fields from the
+// root struct to a leaf, and which hops along the way are pointers.
+type accessor struct {
+	path     []string
+	pointers []bool // pointers[i] reports whether path[i] is a pointer hop
+	leafType string
+}
+
+func (a accessor) name() string {
+	return "Get" + strings.Join(a.path, "")
+}
+
+// generator walks struct declarations from a set of parsed files.
+type generator struct {
+	fset    *token.FileSet
+	pkg     string
+	structs map[string]*ast.StructType
+}
+
+func newGenerator(fset *token.FileSet, files []*ast.File) (*generator, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("accessorgen: no input files")
+	}
+	g := &generator{
+		fset:    fset,
+		pkg:     files[0].Name.Name,
+		structs: make(map[string]*ast.StructType),
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					g.structs[ts.Name.Name] = st
+				}
+			}
+		}
+	}
+	return g, nil
+}
+
+// parseSource parses a single Go source file held in memory.
+func parseSource(filename string, src []byte) (*generator, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	return newGenerator(fset, []*ast.File{f})
+}
+
+// accessors returns every leaf reachable from the named root struct.
+func (g *generator) accessors(root string) ([]accessor, error) {
+	st, ok := g.structs[root]
+	if !ok {
+		return nil, fmt.Errorf("accessorgen: struct %q not found", root)
+	}
+	var out []accessor
+	g.walk(st, nil, nil, map[string]bool{root: true}, &out)
+	return out, nil
+}
+
+func (g *generator) walk(st *ast.StructType, path []string, ptrs []bool, seen map[string]bool, out *[]accessor) {
+	for _, field := range st.Fields.List {
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			p := append(append([]string(nil), path...), name.Name)
+
+			typ, isPtr := field.Type, false
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ, isPtr = star.X, true
+			}
+			q := append(append([]bool(nil), ptrs...), isPtr)
+
+			if ident, ok := typ.(*ast.Ident); ok {
+				if nested, ok := g.structs[ident.Name]; ok && !seen[ident.Name] {
+					seen[ident.Name] = true
+					g.walk(nested, p, q, seen, out)
+					delete(seen, ident.Name)
+					continue
+				}
+			}
+
+			// Leaves keep their declared type, pointer included; only the
+			// hops leading to them are dereferenced.
+			*out = append(*out, accessor{
+				path:     p,
+				pointers: ptrs,
+				leafType: g.exprString(field.Type),
+			})
+		}
+	}
+}
+
+func (g *generator) exprString(e ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, g.fset, e)
+	return buf.String()
+}
+
+// generate emits a gofmt'ed file containing accessors for each root type.
+func (g *generator) generate(roots []string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by accessorgen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n", g.pkg)
+
+	sort.Strings(roots)
+	for _, root := range roots {
+		accs, err := g.accessors(root)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range accs {
+			writeAccessor(&buf, root, a)
+		}
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("accessorgen: formatting output: %w", err)
+	}
+	return src, nil
+}
+
+func writeAccessor(buf *bytes.Buffer, root string, a accessor) {
+	recv := strings.ToLower(root[:1])
+	fmt.Fprintf(buf, "\n// %s returns %s.%s and whether every pointer on the way was non-nil.\n",
+		a.name(), root, strings.Join(a.path, "."))
+	fmt.Fprintf(buf, "func (%s *%s) %s() (v %s, ok bool) {\n", recv, root, a.name(), a.leafType)
+	fmt.Fprintf(buf, "\tif %s == nil {\n\t\treturn v, false\n\t}\n", recv)
+
+	expr := recv
+	for i, field := range a.path[:len(a.path)-1] {
+		expr += "." + field
+		if a.pointers[i] {
+			fmt.Fprintf(buf, "\tif %s == nil {\n\t\treturn v, false\n\t}\n", expr)
+		}
+	}
+	fmt.Fprintf(buf, "\treturn %s.%s, true\n}\n", expr, a.path[len(a.path)-1])
+}
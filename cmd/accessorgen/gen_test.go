@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerateNestedPointers(t *testing.T) {
+	src := []byte(`package p
+
+type Root struct {
+	ID   int
+	Next *Node
+	hidden *Node
+}
+
+type Node struct {
+	Label string
+	Tags  []string
+	Child *Node
+}
+`)
+	g, err := parseSource("p.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := g.generate([]string{"Root"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"func (r *Root) GetID() (v int, ok bool)",
+		"func (r *Root) GetNextLabel() (v string, ok bool)",
+		"func (r *Root) GetNextTags() (v []string, ok bool)",
+		"if r.Next == nil {",
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	// Node.Child is recursive; the walk must stop instead of looping and
+	// expose the pointer itself as a leaf.
+	if !bytes.Contains(out, []byte("GetNextChild() (v *Node, ok bool)")) {
+		t.Errorf("recursive field not emitted as leaf:\n%s", out)
+	}
+	if bytes.Contains(out, []byte("Hidden")) || bytes.Contains(out, []byte("hidden")) {
+		t.Errorf("unexported field leaked into output:\n%s", out)
+	}
+}
+
+func TestGenerateUnknownType(t *testing.T) {
+	g, err := parseSource("p.go", []byte("package p\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.generate([]string{"Missing"}); err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Fatalf("err = %v, want not-found error", err)
+	}
+}
+
+func TestNilsafeIsUpToDate(t *testing.T) {
+	src, err := os.ReadFile("../../nilsafe/server.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := parseSource("server.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := g.generate([]string{"Server"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../../nilsafe/server_accessors.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("nilsafe/server_accessors.go is stale; run go generate ./nilsafe")
+	}
+}
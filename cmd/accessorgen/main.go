@@ -0,0 +1,74 @@
+// Command accessorgen generates nil-safe Get methods for pointer-heavy
+// structs. For a root type with a field Config *Config whose Host is a
+// string, it emits
+//
+//	func (r *Root) GetConfigHost() (string, bool)
+//
+// which walks every pointer on the path and reports false instead of
+// panicking when one of them is nil.
+//
+// It is meant to be driven by go:generate:
+//
+//	//go:generate go run github.com/stawuah/pounce-on-go/cmd/accessorgen -type Server
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	types := flag.String("type", "", "comma-separated list of root struct names")
+	output := flag.String("output", "", "output file (default <first type>_accessors.go)")
+	flag.Parse()
+
+	if *types == "" {
+		fmt.Fprintln(os.Stderr, "accessorgen: -type is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(".", strings.Split(*types, ","), *output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, roots []string, output string) error {
+	if output == "" {
+		output = strings.ToLower(roots[0]) + "_accessors.go"
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == output {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+
+	g, err := newGenerator(fset, files)
+	if err != nil {
+		return err
+	}
+	src, err := g.generate(roots)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
@@ -0,0 +1,79 @@
+// Command gobconsumer receives LargeData streams from gobproducer and
+// aggregates them (see package gobstream).
+//
+//	go run ./cmd/gobconsumer -addr :7171
+//	go run ./cmd/gobproducer -addr localhost:7171 -n 10000
+//
+// Each stream is logged with its own metrics as it ends. Interrupt or
+// terminate the process to stop; it prints the totals of every stream
+// first.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/stawuah/pounce-on-go/gobstream"
+)
+
+func main() {
+	addr := flag.String("addr", ":7171", "listen address")
+	flag.Parse()
+	if err := run(*addr); err != nil {
+		slog.Error("gobconsumer failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run(addr string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	context.AfterFunc(ctx, func() { l.Close() })
+	slog.Info("gobconsumer: listening", "addr", l.Addr().String())
+
+	var (
+		mu     sync.Mutex
+		totals gobstream.Metrics
+		wg     sync.WaitGroup
+	)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+			producer, m, err := gobstream.Receive(conn, nil)
+			log := slog.With("remote", conn.RemoteAddr().String(), "producer", producer, "items", m.Items)
+			if err != nil {
+				log.Warn("gobconsumer: stream failed", "err", err)
+			} else {
+				log.Info("gobconsumer: stream done", "mean", m.Mean(), "min", m.Min, "max", m.Max, "nodes", m.Nodes, "bytes", m.Bytes)
+			}
+			mu.Lock()
+			totals.Merge(m)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(totals)
+}
@@ -0,0 +1,48 @@
+// Command gobproducer streams generated LargeData items to gobconsumer
+// over one gob stream (see package gobstream) and prints the metrics the
+// consumer sends back, with how long the stream took.
+//
+//	go run ./cmd/gobproducer -addr localhost:7171 -n 10000 -samples 64 -payload 1024
+//
+// The items depend only on -seed, so two runs with the same flags send
+// the same data.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/gobstream"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:7171", "gobconsumer address")
+	n := flag.Int("n", 1000, "number of items")
+	samples := flag.Int("samples", 64, "samples per item")
+	payload := flag.Int("payload", 1024, "payload bytes per item")
+	seed := flag.Uint64("seed", 1, "seed for the generated items")
+	name := flag.String("name", "", "producer name sent to the consumer (default: host name)")
+	flag.Parse()
+	if *name == "" {
+		*name, _ = os.Hostname()
+	}
+
+	conn, err := net.Dial("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+	start := time.Now()
+	m, err := gobstream.Send(conn, *name, gobstream.Generate(*seed, *n, *samples, *payload))
+	if err != nil {
+		log.Fatal(err)
+	}
+	took := time.Since(start)
+	fmt.Printf("sent %d items in %s (%.0f items/s)\n", m.Items, took.Round(time.Millisecond), float64(m.Items)/took.Seconds())
+	fmt.Printf("consumer saw %d samples, mean %.3f, min %.3f, max %.3f; %d nodes, depth %d; %d payload bytes\n",
+		m.Samples, m.Mean(), m.Min, m.Max, m.Nodes, m.MaxDepth, m.Bytes)
+}
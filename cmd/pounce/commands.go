@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/apiclient"
+	"github.com/stawuah/pounce-on-go/catalog"
+	"github.com/stawuah/pounce-on-go/catalogsync"
+	"github.com/stawuah/pounce-on-go/concurrency/errgroup"
+	"github.com/stawuah/pounce-on-go/config"
+	"github.com/stawuah/pounce-on-go/cryptox"
+	"github.com/stawuah/pounce-on-go/export"
+	"github.com/stawuah/pounce-on-go/fileio"
+	"github.com/stawuah/pounce-on-go/flags"
+	"github.com/stawuah/pounce-on-go/logging"
+	"github.com/stawuah/pounce-on-go/patterns"
+	"github.com/stawuah/pounce-on-go/product"
+	"github.com/stawuah/pounce-on-go/retry"
+)
+
+const defaultURL = "http://localhost:8080"
+
+// serveConfig is serve's settings. Besides flags and their $POUNCE_*
+// variables, they can come from a JSON file named by -config.
+type serveConfig struct {
+	logging.Config
+	Addr          string        `config:"addr" usage:"HTTP listen address"`
+	Statsd        string        `config:"statsd" usage:"UDP address for statsd metrics; empty disables"`
+	Seed          int           `config:"seed" usage:"start with this many sample products"`
+	Snapshot      string        `config:"snapshot" usage:"load products from this file at startup and save them to it at shutdown"`
+	SnapshotKey   config.Secret `config:"snapshot-key,noflag"`
+	SnapshotEvery time.Duration `config:"snapshot-every" usage:"with -snapshot, also save it this often as a background job, so a crash loses less"`
+	StopTimeout   time.Duration `config:"stop-timeout" usage:"how long each component may take to stop"`
+	Traces        int           `config:"traces" usage:"how many recent request traces /debug/traces keeps; 0 turns tracing off"`
+	OTelStdout    bool          `config:"otel-stdout" usage:"write every span to stdout as OpenTelemetry JSON"`
+	Flags         string        `config:"flags" usage:"feature flags at startup, such as search,beta=25%; /admin/flags changes them"`
+	Jobs          string        `config:"jobs" usage:"file the background job queue is kept in; empty keeps it in memory"`
+	Webhooks      string        `config:"webhooks" usage:"comma-separated URLs to POST every product event to, through the job queue"`
+	WebhookRate   float64       `config:"webhook-rate" usage:"deliveries per second to each webhook URL; 0 means no limit"`
+	WebhookSecret config.Secret `config:"webhook-secret,noflag"`
+	Pprof         string        `config:"pprof" usage:"serve pprof on this address (e.g. localhost:6060); empty disables"`
+	RateLimit     float64       `config:"rate-limit" usage:"requests per second the server accepts, answering 429 beyond; 0 disables"`
+	RateBurst     int           `config:"rate-burst" usage:"with -rate-limit, how many requests may come at once; 0 means one second's worth"`
+}
+
+// Validate checks the settings no flag parser would.
+func (c serveConfig) Validate() error {
+	if c.SnapshotKey != "" {
+		if _, err := cryptox.ParseKey(c.SnapshotKey.Value()); err != nil {
+			return fmt.Errorf("snapshot-key: %w", err)
+		}
+	}
+	if _, err := flags.Parse(c.Flags); err != nil {
+		return fmt.Errorf("flags: %w", err)
+	}
+	if c.SnapshotEvery < 0 || c.SnapshotEvery > 0 && c.Snapshot == "" {
+		return fmt.Errorf("snapshot-every needs -snapshot and a positive interval, got %s", c.SnapshotEvery)
+	}
+	if c.RateLimit < 0 || c.RateBurst < 0 || c.WebhookRate < 0 {
+		return fmt.Errorf("rate-limit, rate-burst and webhook-rate must not be negative, got %g, %d and %g", c.RateLimit, c.RateBurst, c.WebhookRate)
+	}
+	if c.StopTimeout <= 0 {
+		return fmt.Errorf("stop-timeout must be positive, got %s", c.StopTimeout)
+	}
+	return nil
+}
+
+var serveCmd = command{
+	name:    "serve",
+	summary: "serve the product API, catalog pages and /metrics",
+	flags: func(fs *flag.FlagSet) func(context.Context, *env) error {
+		cfg := serveConfig{Addr: ":8080", StopTimeout: 5 * time.Second, Traces: 100, Flags: product.FlagSearch, WebhookRate: 10}
+		config.RegisterFlags(fs, &cfg)
+		file := fs.String("config", "", "JSON file of settings, overridden by the environment and flags; $POUNCE_SNAPSHOT_KEY encrypts the snapshot and $POUNCE_WEBHOOK_SECRET signs webhooks")
+		return func(ctx context.Context, e *env) error {
+			if err := config.Load(&cfg, config.File(*file), config.Env("POUNCE", e.getenv), config.Flags(fs)); err != nil {
+				return err
+			}
+			log, logFile, err := logging.Open(cfg.Config, e.stderr)
+			if err != nil {
+				return err
+			}
+			defer logFile.Close()
+			ctx = logging.WithContext(ctx, log)
+			s, err := newServer(ctx, cfg, log, e.stdout)
+			if err != nil {
+				return err
+			}
+			return s.Run(ctx)
+		}
+	},
+}
+
+var seedCmd = command{
+	name:    "seed",
+	summary: "create sample products, or those in an NDJSON file, through the API",
+	flags: func(fs *flag.FlagSet) func(context.Context, *env) error {
+		url := fs.String("url", defaultURL, "API base URL")
+		n := fs.Int("n", 100, "number of sample products")
+		file := fs.String("file", "", "NDJSON product dump to load instead of sample products")
+		c := fs.Int("c", 4, "concurrent requests")
+		return func(ctx context.Context, e *env) error {
+			client, err := apiclient.New(*url)
+			if err != nil {
+				return err
+			}
+			g, ctx := errgroup.WithContext(ctx)
+			g.SetLimit(max(*c, 1))
+			// Go blocks at the limit, so a dump is read no faster than
+			// it is sent and never held in memory.
+			count := 0
+			create := func(p product.Product) error {
+				count++
+				g.Go(func() error { return client.Create(ctx, p) })
+				return ctx.Err()
+			}
+			var readErr error
+			if *file != "" {
+				f, err := os.Open(*file)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				if err := fileio.DecodeLines(f, create); err != nil {
+					readErr = fmt.Errorf("%s: %w", *file, err)
+				}
+			} else {
+				for _, p := range sampleProducts(*n) {
+					create(p)
+				}
+			}
+			if err := g.Wait(); err != nil {
+				return err
+			}
+			if readErr != nil {
+				return readErr
+			}
+			fmt.Fprintf(e.stdout, "seeded %d products\n", count)
+			return nil
+		}
+	},
+}
+
+var addCmd = command{
+	name:    "add",
+	summary: `create products from quick-add strings such as "Steel Anvil: 12.50"`,
+	args:    "NAME:PRICE...",
+	flags: func(fs *flag.FlagSet) func(context.Context, *env) error {
+		url := fs.String("url", defaultURL, "API base URL")
+		sku := fs.String("sku", "", "SKU when adding one product; by default it is derived from the name")
+		return func(ctx context.Context, e *env) error {
+			if len(e.args) == 0 {
+				return errors.New(`add: nothing to add; pass one or more "name:price" arguments`)
+			}
+			if *sku != "" && len(e.args) > 1 {
+				return errors.New("add: -sku needs exactly one product")
+			}
+			if *sku != "" && !patterns.ValidSKU(*sku) {
+				return fmt.Errorf("add: invalid SKU %q", *sku)
+			}
+			// Parse everything before sending anything, so a typo in the
+			// last argument does not leave the first ones half added.
+			var ps []product.Product
+			for _, arg := range e.args {
+				name, price, err := patterns.ParseQuickAdd(arg)
+				if err != nil {
+					return fmt.Errorf("add: %w", err)
+				}
+				p := product.Product{SKU: *sku, Name: name, Price: price}
+				if p.SKU == "" {
+					if p.SKU = patterns.DeriveSKU(name); p.SKU == "" {
+						return fmt.Errorf("add: cannot derive a SKU from %q; use -sku", name)
+					}
+				}
+				ps = append(ps, p)
+			}
+			client, err := apiclient.New(*url)
+			if err != nil {
+				return err
+			}
+			for _, p := range ps {
+				if err := client.Create(ctx, p); err != nil {
+					return fmt.Errorf("add %s: %w", p.SKU, err)
+				}
+				fmt.Fprintf(e.stdout, "added %s %q at %s\n", p.SKU, p.Name, catalog.Currency(p.Price))
+			}
+			return nil
+		}
+	},
+}
+
+var exportCmd = command{
+	name:    "export",
+	summary: "write the catalog as CSV, JSON or NDJSON",
+	flags: func(fs *flag.FlagSet) func(context.Context, *env) error {
+		url := fs.String("url", defaultURL, "API base URL")
+		format := fs.String("format", "json", "output format: json, csv or ndjson")
+		out := fs.String("o", "", "output file; empty means stdout")
+		return func(ctx context.Context, e *env) error {
+			if !slices.Contains([]string{"json", "csv", "ndjson"}, *format) {
+				return fmt.Errorf("export: unknown format %q", *format)
+			}
+			client, err := apiclient.New(*url)
+			if err != nil {
+				return err
+			}
+			ps, err := client.List(ctx)
+			if err != nil {
+				return err
+			}
+
+			write := func(w io.Writer) error {
+				switch *format {
+				case "csv":
+					return export.CSV(w, ps, productColumns)
+				case "ndjson":
+					return fileio.EncodeLines(w, slices.Values(ps))
+				}
+				return export.JSON(w, ps)
+			}
+			if *out == "" {
+				return write(e.stdout)
+			}
+			return fileio.WriteAtomic(*out, 0o644, write)
+		}
+	},
+}
+
+var productColumns = []export.Column[product.Product]{
+	{Name: "sku", Value: func(p product.Product) string { return p.SKU }},
+	{Name: "name", Value: func(p product.Product) string { return p.Name }},
+	export.Float("price", 2, func(p product.Product) float64 { return p.Price }),
+}
+
+var syncCmd = command{
+	name:    "sync",
+	summary: "bring a local copy of the catalog up to date and push local edits",
+	flags: func(fs *flag.FlagSet) func(context.Context, *env) error {
+		url := fs.String("url", defaultURL, "API base URL")
+		dir := fs.String("dir", ".pounce", "directory holding the local copy")
+		prefer := fs.String("prefer", "server", "which side wins when both changed a product: server or client")
+		follow := fs.Bool("follow", false, "keep the copy up to date from the event stream until interrupted")
+		return func(ctx context.Context, e *env) error {
+			resolvers := map[string]catalogsync.Resolver{"server": catalogsync.ServerWins, "client": catalogsync.ClientWins}
+			resolve, ok := resolvers[*prefer]
+			if !ok {
+				return fmt.Errorf("sync: -prefer must be server or client, got %q", *prefer)
+			}
+			client, err := apiclient.New(*url)
+			if err != nil {
+				return err
+			}
+			r, err := catalogsync.Open(*dir, client, catalogsync.WithResolver(resolve),
+				catalogsync.WithLogger(slog.New(slog.NewTextHandler(e.stderr, nil))))
+			if err != nil {
+				return err
+			}
+			stats, err := r.Pull(ctx)
+			if err != nil {
+				return err
+			}
+			pushed, err := r.Push(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(e.stdout, "pulled %v; pushed %d; %d products\n", stats, pushed, len(r.List()))
+			if !*follow {
+				return nil
+			}
+			if err := r.Follow(ctx); !errors.Is(err, context.Canceled) {
+				return err
+			}
+			return nil
+		}
+	},
+}
+
+var benchCmd = command{
+	name:    "bench",
+	summary: "load-test GET /products/{sku} and report latency",
+	flags: func(fs *flag.FlagSet) func(context.Context, *env) error {
+		url := fs.String("url", defaultURL, "API base URL")
+		c := fs.Int("c", 8, "concurrent clients")
+		d := fs.Duration("d", 5*time.Second, "how long to run")
+		return func(ctx context.Context, e *env) error {
+			// A benchmark should see failures, not retry past them.
+			client, err := apiclient.New(*url, apiclient.WithRetry(retry.WithMaxAttempts(1)))
+			if err != nil {
+				return err
+			}
+			ps, err := client.List(ctx)
+			if err != nil {
+				return err
+			}
+			if len(ps) == 0 {
+				return errors.New("bench: the catalog is empty; run pounce seed first")
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, *d)
+			defer cancel()
+			workers := max(*c, 1)
+			latencies := make([][]time.Duration, workers)
+			failures := make([]int, workers)
+			start := time.Now()
+			var wg sync.WaitGroup
+			wg.Add(workers)
+			for i := range workers {
+				go func() {
+					defer wg.Done()
+					for ctx.Err() == nil {
+						t := time.Now()
+						_, err := client.Get(ctx, ps[rand.N(len(ps))].SKU)
+						switch {
+						case ctx.Err() != nil: // cut off by the deadline; not a result
+						case err != nil:
+							failures[i]++
+						default:
+							latencies[i] = append(latencies[i], time.Since(t))
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			elapsed := time.Since(start)
+
+			all := slices.Concat(latencies...)
+			slices.Sort(all)
+			var failed int
+			for _, f := range failures {
+				failed += f
+			}
+			fmt.Fprintf(e.stdout, "requests   %d\n", len(all)+failed)
+			fmt.Fprintf(e.stdout, "errors     %d\n", failed)
+			fmt.Fprintf(e.stdout, "throughput %.0f req/s\n", float64(len(all))/elapsed.Seconds())
+			if len(all) > 0 {
+				fmt.Fprintf(e.stdout, "p50        %v\n", percentile(all, 50))
+				fmt.Fprintf(e.stdout, "p99        %v\n", percentile(all, 99))
+			}
+			return nil
+		}
+	},
+}
+
+// percentile returns the p-th percentile of sorted, by nearest rank.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p+99)/100 - 1
+	return sorted[max(i, 0)]
+}
+
+// sampleProducts returns n products with deterministic SKUs, names and
+// prices, so seeding twice updates rather than duplicates.
+func sampleProducts(n int) []product.Product {
+	adjectives := []string{"Steel", "Brass", "Heavy", "Compact", "Folding", "Cordless"}
+	nouns := []string{"Anvil", "Hammer", "Wrench", "Saw", "Drill", "Clamp", "Level"}
+	out := make([]product.Product, n)
+	for i := range out {
+		out[i] = product.Product{
+			SKU:   fmt.Sprintf("SKU-%05d", i+1),
+			Name:  adjectives[i%len(adjectives)] + " " + nouns[i%len(nouns)],
+			Price: float64(i%200) + 0.99,
+		}
+	}
+	return out
+}
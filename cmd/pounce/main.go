@@ -0,0 +1,147 @@
+// Command pounce is the single entry point for running the product API
+// and talking to it.
+//
+//	pounce serve -addr :8080 -statsd :8125 -pprof localhost:6060 -rate-limit 200
+//	pounce seed -n 500
+//	pounce add "Steel Anvil: 12.50" "Brass Hammer: 8"
+//	pounce export -format csv > products.csv
+//	pounce sync -dir ~/.pounce -follow
+//	pounce bench -c 16 -d 10s
+//
+// Run "pounce help <command>" for a command's flags. Any flag not given on
+// the command line falls back to the environment variable POUNCE_<FLAG>,
+// upper-cased with dashes as underscores, so POUNCE_URL sets -url. serve
+// also reads settings from the JSON file named by -config, below both.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/stawuah/pounce-on-go/config"
+)
+
+// command is one subcommand. flags registers its flags on fs and returns
+// the function that runs it once they are parsed.
+type command struct {
+	name    string
+	summary string
+	// args describes the positional arguments for the usage line; a
+	// command without it takes none.
+	args  string
+	flags func(fs *flag.FlagSet) func(ctx context.Context, env *env) error
+}
+
+// env is what a running command may touch, so tests can supply their own.
+type env struct {
+	stdout, stderr io.Writer
+	getenv         func(string) string
+	args           []string // positional arguments left after the flags
+}
+
+var commands = []command{serveCmd, seedCmd, addCmd, exportCmd, syncCmd, benchCmd}
+
+// errUsage reports bad command-line usage; the usage text has already
+// been printed.
+var errUsage = errors.New("usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err := run(ctx, os.Args[1:], &env{stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv})
+	switch {
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "pounce:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, e *env) error {
+	if len(args) == 0 {
+		usage(e.stderr)
+		return errUsage
+	}
+	name, args := args[0], args[1:]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		if len(args) == 0 {
+			usage(e.stdout)
+			return nil
+		}
+		name, args = args[0], []string{"-h"}
+	}
+	for _, c := range commands {
+		if c.name == name {
+			return c.run(ctx, args, e)
+		}
+	}
+	fmt.Fprintf(e.stderr, "pounce: unknown command %q\n\n", name)
+	usage(e.stderr)
+	return errUsage
+}
+
+func (c command) run(ctx context.Context, args []string, e *env) error {
+	fs := flag.NewFlagSet("pounce "+c.name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	exec := c.flags(fs)
+	fs.VisitAll(func(f *flag.Flag) {
+		f.Usage += fmt.Sprintf(" [$%s]", envName(f.Name))
+	})
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: pounce %s [flags]%s\n\n%s\n\nflags:\n", c.name, strings.TrimRight(" "+c.args, " "), c.summary)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return errUsage
+	}
+	if fs.NArg() > 0 && c.args == "" {
+		fmt.Fprintf(e.stderr, "pounce %s: unexpected argument %q\n", c.name, fs.Arg(0))
+		return errUsage
+	}
+	if err := applyEnv(fs, e.getenv); err != nil {
+		return err
+	}
+	e.args = fs.Args()
+	return exec(ctx, e)
+}
+
+// usage lists the commands.
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "usage: pounce <command> [flags]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(w, "\nRun \"pounce help <command>\" for its flags.\n")
+}
+
+func envName(flagName string) string {
+	return config.EnvName("POUNCE", flagName)
+}
+
+// applyEnv sets every flag not given on the command line from its
+// environment variable, if that is set. Command-line flags win.
+func applyEnv(fs *flag.FlagSet, getenv func(string) string) error {
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		v := getenv(envName(f.Name))
+		if err != nil || given[f.Name] || v == "" {
+			return
+		}
+		if setErr := fs.Set(f.Name, v); setErr != nil {
+			err = fmt.Errorf("$%s: %w", envName(f.Name), setErr)
+		}
+	})
+	return err
+}
@@ -0,0 +1,593 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/apiclient"
+	"github.com/stawuah/pounce-on-go/circuitbreaker"
+	"github.com/stawuah/pounce-on-go/clock"
+	"github.com/stawuah/pounce-on-go/counters"
+	"github.com/stawuah/pounce-on-go/cryptox"
+	"github.com/stawuah/pounce-on-go/eventbus/bridge"
+	"github.com/stawuah/pounce-on-go/jobs"
+	"github.com/stawuah/pounce-on-go/logging"
+	"github.com/stawuah/pounce-on-go/product"
+	"github.com/stawuah/pounce-on-go/retry"
+)
+
+// testEnv returns an env writing to buffers, with vars as the environment.
+func testEnv(vars map[string]string) (*env, *bytes.Buffer, *bytes.Buffer) {
+	var stdout, stderr bytes.Buffer
+	return &env{stdout: &stdout, stderr: &stderr, getenv: func(k string) string { return vars[k] }}, &stdout, &stderr
+}
+
+func TestUsage(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr error
+		want    []string
+	}{
+		{nil, errUsage, []string{"serve", "seed", "add", "export", "sync", "bench"}},
+		{[]string{"frobnicate"}, errUsage, []string{`unknown command "frobnicate"`, "commands:"}},
+		{[]string{"help"}, nil, []string{"commands:"}},
+		{[]string{"help", "seed"}, nil, []string{"usage: pounce seed [flags]", "-n int", "[$POUNCE_N]"}},
+		{[]string{"seed", "-bogus"}, errUsage, []string{"flag provided but not defined: -bogus"}},
+		{[]string{"seed", "extra"}, errUsage, []string{`unexpected argument "extra"`}},
+		{[]string{"help", "add"}, nil, []string{"usage: pounce add [flags] NAME:PRICE...", "-sku string"}},
+	}
+	for _, tt := range tests {
+		e, stdout, stderr := testEnv(nil)
+		err := run(context.Background(), tt.args, e)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("run(%q) = %v, want %v", tt.args, err, tt.wantErr)
+		}
+		out := stdout.String() + stderr.String()
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("run(%q) output lacks %q:\n%s", tt.args, want, out)
+			}
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	url := fs.String("url", "default", "")
+	n := fs.Int("n", 1, "")
+	idle := fs.String("max-idle", "", "")
+	fs.Parse([]string{"-url", "from-flag"})
+
+	env := map[string]string{"POUNCE_URL": "from-env", "POUNCE_N": "7", "POUNCE_MAX_IDLE": "5s"}
+	if err := applyEnv(fs, func(k string) string { return env[k] }); err != nil {
+		t.Fatal(err)
+	}
+	if *url != "from-flag" || *n != 7 || *idle != "5s" {
+		t.Fatalf("url=%q n=%d max-idle=%q", *url, *n, *idle)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("n", 1, "")
+	env["POUNCE_N"] = "many"
+	if err := applyEnv(fs, func(k string) string { return env[k] }); err == nil || !strings.Contains(err.Error(), "$POUNCE_N") {
+		t.Fatalf("bad env value: err = %v", err)
+	}
+}
+
+func TestSeedThenExport(t *testing.T) {
+	reg := counters.NewRegistry()
+	srv := httptest.NewServer(routes(&product.Service{Repo: product.NewRepository()}, reg))
+	defer srv.Close()
+	ctx := context.Background()
+
+	// The URL comes from the environment, as it would in a deployment.
+	e, stdout, _ := testEnv(map[string]string{"POUNCE_URL": srv.URL})
+	if err := run(ctx, []string{"seed", "-n", "3"}, e); err != nil {
+		t.Fatal(err)
+	}
+	if got := stdout.String(); got != "seeded 3 products\n" {
+		t.Fatalf("seed output = %q", got)
+	}
+
+	e, stdout, _ = testEnv(map[string]string{"POUNCE_URL": srv.URL})
+	if err := run(ctx, []string{"export", "-format", "csv"}, e); err != nil {
+		t.Fatal(err)
+	}
+	want := "sku,name,price\nSKU-00001,Steel Anvil,0.99\nSKU-00002,Brass Hammer,1.99\nSKU-00003,Heavy Wrench,2.99\n"
+	if got := stdout.String(); got != want {
+		t.Fatalf("export csv =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := reg.Dump()["/products"]; got != 4 {
+		t.Fatalf(`"/products" counter = %d, want 3 creates and a list`, got)
+	}
+
+	e, _, _ = testEnv(nil)
+	if err := run(ctx, []string{"export", "-url", srv.URL, "-format", "xml"}, e); err == nil {
+		t.Fatal("export -format xml succeeded")
+	}
+}
+
+// An NDJSON dump written with export -o seeds a second server.
+func TestExportFileThenSeedFile(t *testing.T) {
+	ctx := context.Background()
+	src := &product.Service{Repo: product.NewRepository()}
+	for _, p := range sampleProducts(5) {
+		if err := src.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srcSrv := httptest.NewServer(routes(src, counters.NewRegistry()))
+	defer srcSrv.Close()
+	dst := &product.Service{Repo: product.NewRepository()}
+	dstSrv := httptest.NewServer(routes(dst, counters.NewRegistry()))
+	defer dstSrv.Close()
+
+	dump := filepath.Join(t.TempDir(), "products.ndjson")
+	e, _, _ := testEnv(nil)
+	if err := run(ctx, []string{"export", "-url", srcSrv.URL, "-format", "ndjson", "-o", dump}, e); err != nil {
+		t.Fatal(err)
+	}
+	e, stdout, _ := testEnv(nil)
+	if err := run(ctx, []string{"seed", "-url", dstSrv.URL, "-file", dump}, e); err != nil {
+		t.Fatal(err)
+	}
+	if got := stdout.String(); got != "seeded 5 products\n" {
+		t.Fatalf("seed output = %q", got)
+	}
+	want, _ := src.List(ctx)
+	got, _ := dst.List(ctx)
+	if !slices.Equal(got, want) {
+		t.Fatalf("seeded %v, want %v", got, want)
+	}
+
+	if err := os.WriteFile(dump, []byte("{\"sku\":\"X-1\",\"name\":\"X\",\"price\":1}\nnot json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e, _, _ = testEnv(nil)
+	err := run(ctx, []string{"seed", "-url", dstSrv.URL, "-file", dump}, e)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("seed from a bad dump: err = %v, want a line 2 error", err)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	ctx := context.Background()
+	svc := &product.Service{Repo: product.NewRepository()}
+	srv := httptest.NewServer(routes(svc, counters.NewRegistry()))
+	defer srv.Close()
+
+	e, stdout, _ := testEnv(map[string]string{"POUNCE_URL": srv.URL})
+	if err := run(ctx, []string{"add", "Steel Anvil: $1,249.50", "brass hammer:8"}, e); err != nil {
+		t.Fatal(err)
+	}
+	want := "added STEEL-ANVIL \"Steel Anvil\" at $1,249.50\nadded BRASS-HAMMER \"brass hammer\" at $8.00\n"
+	if got := stdout.String(); got != want {
+		t.Fatalf("output =\n%s\nwant\n%s", got, want)
+	}
+	e, _, _ = testEnv(map[string]string{"POUNCE_URL": srv.URL})
+	if err := run(ctx, []string{"add", "-sku", "ANV-2", "Anvil: 9"}, e); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := svc.Get(ctx, "ANV-2"); err != nil || p.Price != 9 {
+		t.Fatalf("Get(ANV-2) = %+v, %v", p, err)
+	}
+
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"add"}, "nothing to add"},
+		{[]string{"add", "Anvil: 9", "Anvil 10"}, `want "name:price", got "Anvil 10"`},
+		{[]string{"add", "-sku", "X-1", "A: 1", "B: 2"}, "exactly one"},
+		{[]string{"add", "-sku", "x 1", "A: 1"}, `invalid SKU "x 1"`},
+		{[]string{"add", "***: 1"}, "cannot derive a SKU"},
+	} {
+		e, _, _ := testEnv(map[string]string{"POUNCE_URL": srv.URL})
+		if err := run(ctx, tt.args, e); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: err = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+	// The bad argument came last, but nothing from that call was added.
+	if _, err := svc.Get(ctx, "ANVIL"); err == nil {
+		t.Fatal("a partly invalid add stored products")
+	}
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	svc := &product.Service{Repo: product.NewRepository()}
+	for _, p := range sampleProducts(3) {
+		svc.Create(ctx, p)
+	}
+	srv := httptest.NewServer(routes(svc, counters.NewRegistry()))
+	defer srv.Close()
+	dir := t.TempDir()
+
+	e, stdout, _ := testEnv(map[string]string{"POUNCE_URL": srv.URL})
+	if err := run(ctx, []string{"sync", "-dir", dir}, e); err != nil {
+		t.Fatal(err)
+	}
+	if want := "pulled 3 added, 0 changed, 0 removed, 0 conflicts; pushed 0; 3 products\n"; stdout.String() != want {
+		t.Fatalf("first sync = %q, want %q", stdout, want)
+	}
+	svc.Create(ctx, product.Product{SKU: "NEW-1", Name: "New", Price: 1})
+	e, stdout, _ = testEnv(map[string]string{"POUNCE_URL": srv.URL})
+	if err := run(ctx, []string{"sync", "-dir", dir}, e); err != nil {
+		t.Fatal(err)
+	}
+	if want := "pulled 1 added, 0 changed, 0 removed, 0 conflicts; pushed 0; 4 products\n"; stdout.String() != want {
+		t.Fatalf("second sync = %q, want %q", stdout, want)
+	}
+
+	e, _, _ = testEnv(nil)
+	if err := run(ctx, []string{"sync", "-dir", dir, "-prefer", "mine"}, e); err == nil || !strings.Contains(err.Error(), "-prefer") {
+		t.Fatalf("sync -prefer mine = %v", err)
+	}
+}
+
+func TestBench(t *testing.T) {
+	svc := &product.Service{Repo: product.NewRepository()}
+	srv := httptest.NewServer(routes(svc, counters.NewRegistry()))
+	defer srv.Close()
+
+	e, _, _ := testEnv(nil)
+	if err := run(context.Background(), []string{"bench", "-url", srv.URL}, e); err == nil {
+		t.Fatal("bench against an empty catalog succeeded")
+	}
+
+	for _, p := range sampleProducts(10) {
+		svc.Create(context.Background(), p)
+	}
+	e, stdout, _ := testEnv(nil)
+	if err := run(context.Background(), []string{"bench", "-url", srv.URL, "-c", "2", "-d", "50ms"}, e); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"errors     0\n", "p50 ", "p99 "} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("bench output lacks %q:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	src := &product.Service{Repo: product.NewRepository()}
+	for _, p := range sampleProducts(5) {
+		if err := src.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, _ := src.List(ctx)
+	key, _ := cryptox.ParseKey(cryptox.NewKey())
+
+	for _, tt := range []struct {
+		name string
+		key  []byte
+	}{{"plain", nil}, {"encrypted", key}} {
+		path := filepath.Join(t.TempDir(), "products.snapshot")
+		dst := &product.Service{Repo: product.NewRepository()}
+		if n, err := loadSnapshot(ctx, dst, path, tt.key); n != 0 || err != nil {
+			t.Fatalf("%s: load before the first save = %d, %v", tt.name, n, err)
+		}
+		if err := saveSnapshot(ctx, src, path, tt.key); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		raw, _ := os.ReadFile(path)
+		if encrypted := !bytes.Contains(raw, []byte(want[0].SKU)); encrypted != (tt.key != nil) {
+			t.Fatalf("%s: file encrypted = %v", tt.name, encrypted)
+		}
+		if n, err := loadSnapshot(ctx, dst, path, tt.key); n != 5 || err != nil {
+			t.Fatalf("%s: load = %d, %v", tt.name, n, err)
+		}
+		if got, _ := dst.List(ctx); !slices.Equal(got, want) {
+			t.Fatalf("%s: loaded %v, want %v", tt.name, got, want)
+		}
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	cfg := serveConfig{Addr: "127.0.0.1:0", Seed: 3, StopTimeout: time.Second, Flags: product.FlagSearch}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s, err := newServer(context.Background(), cfg, log, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	client, _ := apiclient.New("http://" + s.listener.Addr().String())
+	ps, err := client.List(ctx)
+	if err != nil || len(ps) != 3 {
+		t.Fatalf("List = %d products, %v; want the 3 seeded", len(ps), err)
+	}
+	if ps, err := client.Suggest(ctx, ps[0].Name[:2]); err != nil || len(ps) == 0 {
+		t.Fatalf("Suggest with search on = %v, %v", ps, err)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run = %v", err)
+	}
+}
+
+// A server that fails to start lets go of the addresses it had already
+// taken, so a retry can have them.
+func TestNewServerReleasesOnError(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+	free, _ := net.Listen("tcp", "127.0.0.1:0")
+	pprof := free.Addr().String()
+	free.Close()
+	freeUDP, _ := net.ListenPacket("udp", "127.0.0.1:0")
+	statsdAddr := freeUDP.LocalAddr().String()
+	freeUDP.Close()
+
+	cfg := serveConfig{Addr: taken.Addr().String(), Pprof: pprof, Statsd: statsdAddr, StopTimeout: time.Second}
+	if _, err := newServer(context.Background(), cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), io.Discard); err == nil {
+		t.Fatal("newServer on a taken address succeeded")
+	}
+	l, err := net.Listen("tcp", pprof)
+	if err != nil {
+		t.Fatalf("pprof address still held: %v", err)
+	}
+	l.Close()
+	conn, err := net.ListenPacket("udp", statsdAddr)
+	if err != nil {
+		t.Fatalf("statsd address still held: %v", err)
+	}
+	conn.Close()
+}
+
+func TestProductRates(t *testing.T) {
+	reg := counters.NewRegistry()
+	svc := &product.Service{Repo: product.NewRepository()}
+	for _, p := range sampleProducts(2) {
+		svc.Create(context.Background(), p)
+	}
+	srv := httptest.NewServer(routes(svc, reg))
+	defer srv.Close()
+	clk := clock.NewFake(time.Unix(1_000, 0))
+	sampler := counters.NewSampler(reg, time.Second, counters.WithClock(clk))
+	defer sampler.Stop()
+	trackProductRates(sampler, reg)
+
+	sku := sampleProducts(1)[0].SKU
+	for _, path := range []string{"/products", "/products/" + sku, "/products/" + sku + "/related", "/products/export.ndjson"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s = %v, %v", path, resp, err)
+		}
+		resp.Body.Close()
+	}
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	clk.BlockUntil(1)
+
+	gauges := reg.DumpGauges()
+	if gauges["products.rps.1m"] != 4 || gauges["products.rps.ewma"] != 4 {
+		t.Fatalf("rates = %v, want 4 requests per second", gauges)
+	}
+}
+
+func TestSender(t *testing.T) {
+	ctx := context.Background()
+	receiver := func(status int) (*httptest.Server, *atomic.Int32) {
+		var hits atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			hits.Add(1)
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(srv.Close)
+		return srv, &hits
+	}
+	down, downHits := receiver(http.StatusServiceUnavailable)
+	picky, pickyHits := receiver(http.StatusBadRequest)
+	up, upHits := receiver(http.StatusNoContent)
+	send := newSender(http.DefaultClient, 0, nil)
+	job := func(url string) bridge.Job { return bridge.Job{URL: url, Event: "product.created", Body: []byte(`{}`)} }
+
+	// Five failures open the breaker; then deliveries stop reaching the
+	// receiver but stay retryable.
+	for range 5 {
+		send.deliver(ctx, job(down.URL))
+	}
+	err := send.deliver(ctx, job(down.URL))
+	if !errors.Is(err, circuitbreaker.ErrOpen) || retry.IsPermanent(err) || downHits.Load() != 5 {
+		t.Fatalf("delivery to an open breaker = %v after %d hits", err, downHits.Load())
+	}
+	// Refusals are answers, not outages, and other URLs are unaffected.
+	for range 6 {
+		if err := send.deliver(ctx, job(picky.URL)); !retry.IsPermanent(err) {
+			t.Fatalf("refused delivery = %v, want a permanent error", err)
+		}
+	}
+	if err := send.deliver(ctx, job(up.URL)); err != nil || pickyHits.Load() != 6 || upHits.Load() != 1 {
+		t.Fatalf("delivery = %v; hits %d refused, %d up", err, pickyHits.Load(), upHits.Load())
+	}
+
+	// At one a second, a second delivery waits its turn.
+	slow := newSender(http.DefaultClient, 1, nil)
+	slow.deliver(ctx, job(up.URL))
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := slow.deliver(short, job(up.URL)); !errors.Is(err, context.DeadlineExceeded) || upHits.Load() != 2 {
+		t.Fatalf("delivery over the rate = %v with %d hits", err, upHits.Load())
+	}
+}
+
+// TestSenderOpenBreakerDefers runs a delivery through a real queue while
+// its URL's breaker is open: nothing is sent, so the job must stay
+// pending for the breaker to half-open rather than die.
+func TestSenderOpenBreakerDefers(t *testing.T) {
+	var hits atomic.Int32
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	send := newSender(http.DefaultClient, 0, nil)
+	job := bridge.Job{URL: down.URL, Event: "product.created", Body: []byte(`{}`)}
+	for range 5 {
+		send.deliver(context.Background(), job)
+	}
+
+	q, err := jobs.New(jobs.NewMemoryStore(), jobs.WithMaxAttempts(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliveries := jobs.Register(q, "webhook", send.deliver)
+	if _, err := deliveries.Enqueue(job); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(logging.WithContext(context.Background(), logging.Discard))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.Run(ctx, 1)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		js := q.Jobs("")
+		if len(js) == 1 && js[0].State == jobs.Pending && js[0].LastError != "" {
+			if js[0].Attempts != 0 || !js[0].RunAt.After(time.Now().Add(20*time.Second)) || hits.Load() != 5 {
+				t.Fatalf("deferred job = %+v after %d hits", js[0], hits.Load())
+			}
+			return
+		}
+		if len(q.Jobs(jobs.Dead)) > 0 || time.Now().After(deadline) {
+			t.Fatalf("jobs = %+v, want one pending", js)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestServeLimits(t *testing.T) {
+	cfg := serveConfig{Addr: "127.0.0.1:0", Pprof: "127.0.0.1:0", RateLimit: 0.01, RateBurst: 2, StopTimeout: time.Second}
+	s, err := newServer(context.Background(), cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	get := func(addr, path string) *http.Response {
+		t.Helper()
+		resp, err := http.Get("http://" + addr + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	api := s.listener.Addr().String()
+	for i := range 2 {
+		if resp := get(api, "/products"); resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d = %d, want 200 within the burst", i+1, resp.StatusCode)
+		}
+	}
+	if resp := get(api, "/products"); resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		t.Fatalf("request past the burst = %d, Retry-After %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+	// pprof has an address of its own, which the API does not share.
+	if resp := get(s.pprof.Addr().String(), "/debug/pprof/"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("pprof index = %d", resp.StatusCode)
+	}
+}
+
+// pounce serve signs the webhooks it sends with -webhook-secret.
+func TestServeSignsWebhooks(t *testing.T) {
+	verifier := &cryptox.Verifier{Secrets: [][]byte{[]byte("s3cret")}}
+	verified := make(chan error, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		select {
+		case verified <- verifier.Verify(r.Header.Get(cryptox.SignatureHeader), body):
+		default:
+		}
+	}))
+	defer receiver.Close()
+
+	cfg := serveConfig{Addr: "127.0.0.1:0", StopTimeout: time.Second, Webhooks: receiver.URL, WebhookSecret: "s3cret"}
+	s, err := newServer(context.Background(), cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	resp, err := http.Post("http://"+s.listener.Addr().String()+"/products", "application/json",
+		strings.NewReader(`{"sku":"A1","name":"Anvil","price":9.99}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("POST /products = %d", resp.StatusCode)
+	}
+	select {
+	case err := <-verified:
+		if err != nil {
+			t.Fatalf("webhook signature: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook delivered")
+	}
+}
+
+// Bad settings stop serve before it listens.
+func TestServeConfigErrors(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "serve.json")
+	if err := os.WriteFile(file, []byte(`{"adr": ":9090"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		args []string
+		vars map[string]string
+		want string
+	}{
+		{[]string{"serve", "-config", file}, nil, `unknown key "adr"`},
+		{[]string{"serve"}, map[string]string{"POUNCE_CONFIG": file}, `unknown key "adr"`},
+		{[]string{"serve"}, map[string]string{"POUNCE_SNAPSHOT_KEY": "short"}, "snapshot-key"},
+		{[]string{"serve", "-stop-timeout", "0s"}, nil, "stop-timeout must be positive"},
+		{[]string{"serve", "-rate-limit", "-1"}, nil, "must not be negative"},
+		{[]string{"serve", "-log-format", "xml"}, nil, "text or json"},
+		{[]string{"serve", "-flags", "beta=half"}, nil, "want on, off or a percentage"},
+		{[]string{"serve", "-snapshot-every", "1m"}, nil, "snapshot-every needs -snapshot"},
+		{[]string{"serve"}, map[string]string{"POUNCE_LOG_LEVEL": "loud"}, "$POUNCE_LOG_LEVEL"},
+	}
+	for _, tt := range tests {
+		e, _, _ := testEnv(tt.vars)
+		err := run(context.Background(), tt.args, e)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v %v: err = %v, want %q", tt.args, tt.vars, err, tt.want)
+		}
+		if err != nil && strings.Contains(err.Error(), "short") {
+			t.Errorf("error echoes the key: %v", err)
+		}
+	}
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/catalog"
+	"github.com/stawuah/pounce-on-go/circuitbreaker"
+	"github.com/stawuah/pounce-on-go/counters"
+	"github.com/stawuah/pounce-on-go/cryptox"
+	"github.com/stawuah/pounce-on-go/eventbus"
+	"github.com/stawuah/pounce-on-go/eventbus/bridge"
+	"github.com/stawuah/pounce-on-go/fileio"
+	"github.com/stawuah/pounce-on-go/flags"
+	"github.com/stawuah/pounce-on-go/jobs"
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/lifecycle"
+	"github.com/stawuah/pounce-on-go/logging"
+	"github.com/stawuah/pounce-on-go/product"
+	"github.com/stawuah/pounce-on-go/profiling"
+	"github.com/stawuah/pounce-on-go/ratelimit"
+	"github.com/stawuah/pounce-on-go/retry"
+	"github.com/stawuah/pounce-on-go/statsd"
+	"github.com/stawuah/pounce-on-go/tracing"
+)
+
+// server is everything pounce serve runs. newServer builds the whole
+// graph in one place, each piece from those before it, and registers
+// every piece that starts and stops with the lifecycle.Manager; serve
+// only loads the settings and runs it.
+type server struct {
+	cfg      serveConfig
+	log      *slog.Logger
+	key      []byte // seals the snapshot file, if set
+	features *flags.Set
+	events   *eventbus.Topic[jsonx.Event]
+	svc      *product.Service
+	reg      *counters.Registry
+	queue    *jobs.Queue
+	listener net.Listener
+	pprof    net.Listener // if cfg.Pprof is set
+	handler  http.Handler
+	m        *lifecycle.Manager
+
+	// undo releases what newServer has opened so far, newest last. If a
+	// later step fails, newServer runs it backwards; otherwise the
+	// components registered with m release everything when they stop.
+	undo []func()
+}
+
+// newServer builds the server cfg describes, logging to log and writing
+// OpenTelemetry spans, if asked to, to stdout. It listens on cfg.Addr
+// but serves nothing until Run.
+func newServer(ctx context.Context, cfg serveConfig, log *slog.Logger, stdout io.Writer) (_ *server, err error) {
+	s := &server{cfg: cfg, log: log, reg: counters.NewRegistry()}
+	defer func() {
+		if err != nil {
+			for _, f := range slices.Backward(s.undo) {
+				f()
+			}
+		}
+	}()
+	if cfg.SnapshotKey != "" {
+		s.key, _ = cryptox.ParseKey(cfg.SnapshotKey.Value()) // checked by Validate
+	}
+	defs, _ := flags.Parse(cfg.Flags) // checked by Validate
+	s.features = flags.New(defs...)
+	s.events = eventbus.NewTopic[jsonx.Event]("products")
+	s.svc = &product.Service{Repo: product.NewRepository(), Events: s.events, Flags: s.features}
+	for _, p := range sampleProducts(cfg.Seed) {
+		if err := s.svc.Create(ctx, p); err != nil {
+			return nil, err
+		}
+	}
+	s.m = lifecycle.New(lifecycle.WithStopTimeout(cfg.StopTimeout), lifecycle.WithLogger(log))
+
+	// The order of registration is the order of starting; stopping runs
+	// backwards.
+	s.registerSnapshot()
+	if err := s.registerJobs(); err != nil {
+		return nil, err
+	}
+	if err := s.registerFlags(); err != nil {
+		return nil, err
+	}
+	s.registerSampler()
+	if err := s.registerStatsd(); err != nil {
+		return nil, err
+	}
+	if err := s.registerPprof(); err != nil {
+		return nil, err
+	}
+	return s, s.registerHTTP(stdout)
+}
+
+// Run runs the server until ctx ends or it fails.
+func (s *server) Run(ctx context.Context) error { return s.m.Run(ctx) }
+
+// registerSnapshot loads the snapshot file at start and saves it at stop.
+// It is registered before http, so it is stopped after it: the snapshot
+// holds every write the server accepted.
+func (s *server) registerSnapshot() {
+	if s.cfg.Snapshot == "" {
+		return
+	}
+	s.m.Register("snapshot", func(ctx context.Context) error {
+		n, err := loadSnapshot(ctx, s.svc, s.cfg.Snapshot, s.key)
+		if err == nil && n > 0 {
+			s.log.Info("loaded snapshot", "file", s.cfg.Snapshot, "products", n)
+		}
+		return err
+	}, func(ctx context.Context) error {
+		return saveSnapshot(ctx, s.svc, s.cfg.Snapshot, s.key)
+	})
+}
+
+// registerJobs opens the job queue and runs it, with the webhook
+// deliveries and periodic snapshots that go through it.
+func (s *server) registerJobs() error {
+	var err error
+	if s.queue, err = openQueue(s.cfg.Jobs); err != nil {
+		return err
+	}
+	if hooks := webhooks(s.cfg.Webhooks); len(hooks) > 0 {
+		var secret []byte
+		if s.cfg.WebhookSecret != "" {
+			secret = []byte(s.cfg.WebhookSecret.Value())
+		}
+		send := newSender(&http.Client{Timeout: 10 * time.Second}, s.cfg.WebhookRate, secret)
+		deliveries := jobs.Register(s.queue, "webhook", send.deliver)
+		s.m.Go("webhooks", func(ctx context.Context) error {
+			return bridge.Forward(ctx, s.events, 64, hooks, func(_ context.Context, j bridge.Job) error {
+				_, err := deliveries.Enqueue(j)
+				return err
+			})
+		})
+	}
+	if s.cfg.SnapshotEvery > 0 {
+		snapshots := jobs.Register(s.queue, "snapshot", func(ctx context.Context, _ struct{}) error {
+			return saveSnapshot(ctx, s.svc, s.cfg.Snapshot, s.key)
+		})
+		s.m.Go("snapshot-timer", func(ctx context.Context) error {
+			t := time.NewTicker(s.cfg.SnapshotEvery)
+			defer t.Stop()
+			for {
+				select {
+				case <-t.C:
+					if _, err := snapshots.Enqueue(struct{}{}); err != nil {
+						s.log.Error("queueing snapshot", "err", err)
+					}
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		})
+	}
+	// Jobs still running at shutdown are cancelled and stay queued; with
+	// -jobs they run again on the next start.
+	s.m.Go("jobs", func(ctx context.Context) error { return s.queue.Run(ctx, 4) })
+	return nil
+}
+
+// sender delivers webhook jobs, each URL behind a circuit breaker and a
+// rate limit of its own, so a receiver that is down is left alone for a
+// while instead of taking every retry, and one that is slow to drain
+// holds up no other.
+type sender struct {
+	client *http.Client
+	rate   float64 // per URL per second; 0 is no limit
+	secret []byte  // signs every delivery; nil sends them unsigned
+
+	mu       sync.Mutex
+	breakers map[string]*circuitbreaker.Breaker
+	limiters map[string]ratelimit.Limiter
+}
+
+func newSender(client *http.Client, rate float64, secret []byte) *sender {
+	return &sender{
+		client:   client,
+		rate:     rate,
+		secret:   secret,
+		breakers: make(map[string]*circuitbreaker.Breaker),
+		limiters: make(map[string]ratelimit.Limiter),
+	}
+}
+
+// deliver sends j. While j.URL's breaker is open it sends nothing, and
+// the job waits for the breaker to half-open without using up an
+// attempt; so does one whose turn under the rate limit never came.
+func (s *sender) deliver(ctx context.Context, j bridge.Job) error {
+	// A stored job carries no secret; see bridge.Job.
+	j.Secret = s.secret
+	b, l := s.guards(j.URL)
+	if l != nil {
+		if err := l.Wait(ctx); err != nil {
+			return jobs.RetryAt(time.Now(), err)
+		}
+	}
+	err := b.Do(ctx, func(ctx context.Context) error {
+		return j.Deliver(ctx, s.client, time.Now())
+	})
+	if errors.Is(err, circuitbreaker.ErrOpen) {
+		// A zero time means a half-open trial is under way: look again
+		// shortly.
+		at := b.OpenUntil()
+		if at.IsZero() {
+			at = time.Now().Add(time.Second)
+		}
+		return jobs.RetryAt(at, err)
+	}
+	return err
+}
+
+func (s *sender) guards(url string) (*circuitbreaker.Breaker, ratelimit.Limiter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.breakers[url]
+	if !ok {
+		// A permanent failure is the receiver answering, if unhappily:
+		// it says nothing about whether it is up.
+		b = circuitbreaker.New(circuitbreaker.WithIsFailure(func(err error) bool {
+			return err != nil && !retry.IsPermanent(err) && !errors.Is(err, context.Canceled)
+		}))
+		s.breakers[url] = b
+		if s.rate > 0 {
+			s.limiters[url] = ratelimit.NewTokenBucket(s.rate, max(int(math.Ceil(s.rate)), 1))
+		}
+	}
+	return b, s.limiters[url]
+}
+
+// registerFlags logs every feature flag change.
+func (s *server) registerFlags() error {
+	changes, err := s.features.Watch(8)
+	if err != nil {
+		return err
+	}
+	s.undo = append(s.undo, changes.Unsubscribe)
+	s.m.Go("flags", func(ctx context.Context) error {
+		defer changes.Unsubscribe()
+		for {
+			select {
+			case f := <-changes.C:
+				s.log.Info("flag changed", "flag", f.String())
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	})
+	return nil
+}
+
+// registerSampler publishes smoothed request rates on /metrics.
+func (s *server) registerSampler() {
+	var sampler *counters.Sampler
+	s.m.Register("sampler", func(context.Context) error {
+		sampler = counters.NewSampler(s.reg, time.Second)
+		trackProductRates(sampler, s.reg)
+		return nil
+	}, func(context.Context) error {
+		sampler.Stop()
+		return nil
+	})
+}
+
+// trackProductRates publishes the product API's request rate. routes
+// counts it under both its mount points: "/products" for the list and
+// POST, and "/products/" for everything below.
+func trackProductRates(sampler *counters.Sampler, reg *counters.Registry) {
+	list, below := reg.GetOrCreate("/products"), reg.GetOrCreate("/products/")
+	requests := func() int64 { return list.Value() + below.Value() }
+	sampler.TrackRateFunc("products.rps.1m", requests, counters.NewMovingAverage(60))
+	sampler.TrackRateFunc("products.rps.ewma", requests, counters.NewEWMA(0.2))
+}
+
+// registerStatsd receives statsd metrics into the registry.
+func (s *server) registerStatsd() error {
+	if s.cfg.Statsd == "" {
+		return nil
+	}
+	conn, err := net.ListenPacket("udp", s.cfg.Statsd)
+	if err != nil {
+		return err
+	}
+	s.undo = append(s.undo, func() { conn.Close() })
+	s.log.Info("statsd listening", "addr", conn.LocalAddr().String())
+	s.m.Go("statsd", func(ctx context.Context) error {
+		defer conn.Close()
+		return statsd.Listen(ctx, conn, s.reg)
+	})
+	return nil
+}
+
+// registerPprof serves pprof on its own address, apart from the API, so
+// it is never exposed by accident.
+func (s *server) registerPprof() error {
+	if s.cfg.Pprof == "" {
+		return nil
+	}
+	var err error
+	if s.pprof, err = net.Listen("tcp", s.cfg.Pprof); err != nil {
+		return err
+	}
+	s.undo = append(s.undo, func() { s.pprof.Close() })
+	mux := http.NewServeMux()
+	profiling.Register(mux)
+	s.serve("pprof", s.pprof, mux)
+	return nil
+}
+
+// serve registers a component serving h on l.
+func (s *server) serve(name string, l net.Listener, h http.Handler) *http.Server {
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 10 * time.Second}
+	s.m.Register(name, func(context.Context) error {
+		s.log.Info("listening", "component", name, "addr", l.Addr().String())
+		go func() {
+			if err := srv.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+				s.m.Shutdown(fmt.Errorf("%s: %w", name, err))
+			}
+		}()
+		return nil
+	}, srv.Shutdown)
+	return srv
+}
+
+// registerHTTP listens on cfg.Addr and serves the routes, the job admin
+// API and, with tracing on, the trace viewer. With cfg.RateLimit set, a
+// token bucket caps the requests the routes and job API accept.
+func (s *server) registerHTTP(stdout io.Writer) error {
+	l, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	s.listener = l
+	s.undo = append(s.undo, func() { l.Close() })
+	app := http.NewServeMux()
+	app.Handle("/", routes(s.svc, s.reg))
+	admin := jobs.Handler(s.queue)
+	app.Handle("/admin/jobs", admin)
+	app.Handle("/admin/jobs/", admin)
+	var h http.Handler = app
+	if s.cfg.RateLimit > 0 {
+		burst := s.cfg.RateBurst
+		if burst == 0 {
+			burst = max(int(math.Ceil(s.cfg.RateLimit)), 1)
+		}
+		h = ratelimit.Middleware(ratelimit.NewTokenBucket(s.cfg.RateLimit, burst), h)
+	}
+	s.handler = logging.Middleware(s.log, h)
+	if s.cfg.Traces > 0 || s.cfg.OTelStdout {
+		var opts []tracing.Option
+		if s.cfg.OTelStdout {
+			opts = append(opts, tracing.WithExporter(tracing.NewJSONExporter(stdout, "pounce")))
+		}
+		tracer := tracing.NewTracer(s.cfg.Traces, opts...)
+		mux := http.NewServeMux()
+		if s.cfg.Traces > 0 {
+			// The viewer's own requests are logged but not traced, so
+			// they do not push real traces out.
+			viewer := logging.Middleware(s.log, tracer.Handler())
+			mux.Handle("GET /debug/traces", viewer)
+			mux.Handle("GET /debug/traces/", viewer)
+		}
+		mux.Handle("/", tracer.Middleware(s.handler))
+		s.handler = mux
+	}
+	srv := s.serve("http", l, s.handler)
+	// Closing the topic ends the /events streams, which Shutdown would
+	// otherwise wait on until it timed out.
+	srv.RegisterOnShutdown(s.events.Close)
+	return nil
+}
+
+// openQueue returns a job queue kept in path, or in memory if path is
+// empty.
+func openQueue(path string) (*jobs.Queue, error) {
+	if path == "" {
+		return jobs.New(jobs.NewMemoryStore())
+	}
+	store, err := jobs.OpenFileStore(path)
+	if err != nil {
+		return nil, err
+	}
+	return jobs.New(store)
+}
+
+// webhooks turns a comma-separated list of URLs into webhooks receiving
+// every event.
+func webhooks(urls string) []bridge.Webhook[jsonx.Event] {
+	var hooks []bridge.Webhook[jsonx.Event]
+	for u := range strings.SplitSeq(urls, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			hooks = append(hooks, bridge.Webhook[jsonx.Event]{URL: u})
+		}
+	}
+	return hooks
+}
+
+// loadSnapshot creates the products in a snapshot file of any version
+// (see product.Snapshots), opening it with key if that is non-nil. A
+// missing file is an empty snapshot.
+func loadSnapshot(ctx context.Context, svc *product.Service, path string, key []byte) (int, error) {
+	var r io.Reader
+	if key != nil {
+		b, err := cryptox.ReadEncryptedFile(path, key)
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		} else if err != nil {
+			return 0, err
+		}
+		r = bytes.NewReader(b)
+	} else {
+		f, err := os.Open(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		} else if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		r = f
+	}
+	n := 0
+	err := product.ReadSnapshot(r, func(p product.Product) error {
+		n++
+		return svc.Create(ctx, p)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	return n, nil
+}
+
+// saveSnapshot replaces path with every product, in the current snapshot
+// version, sealed under key if that is non-nil.
+func saveSnapshot(ctx context.Context, svc *product.Service, path string, key []byte) error {
+	ps, err := svc.List(ctx)
+	if err != nil {
+		return err
+	}
+	write := func(w io.Writer) error {
+		return product.WriteSnapshot(w, slices.Values(ps))
+	}
+	if key != nil {
+		return cryptox.WriteEncryptedFile(path, key, 0o600, write)
+	}
+	return fileio.WriteAtomic(path, 0o600, write)
+}
+
+// routes mounts the JSON API, the HTML catalog, the metrics page and the
+// snapshot diffs of /admin/diff for incremental sync. If svc publishes
+// events, it streams them as SSE on /events, and if svc has
+// feature flags, it serves their admin API on /admin/flags.
+// Requests are counted per mount point ("/products/", "/catalog", ...);
+// the finer routes live in the mounted handlers' own muxes.
+func routes(svc *product.Service, reg *counters.Registry) http.Handler {
+	api := reg.CountRequests(product.Handler(svc))
+	pages := reg.CountRequests(catalog.Handler(svc))
+	mux := http.NewServeMux()
+	mux.Handle("/products", api)
+	mux.Handle("/products/", api)
+	mux.Handle("/catalog", pages)
+	mux.Handle("/catalog/", pages)
+	mux.Handle("GET /metrics", reg.Handler())
+	diffs := reg.CountRequests(product.DiffHandler(product.NewSnapshotter(svc, 0)))
+	mux.Handle("POST /admin/snapshots", diffs)
+	mux.Handle("GET /admin/diff", diffs)
+	if svc.Flags != nil {
+		admin := flags.Handler(svc.Flags)
+		mux.Handle("/admin/flags", admin)
+		mux.Handle("/admin/flags/", admin)
+	}
+	if svc.Events != nil {
+		mux.Handle("GET /events", &bridge.SSE[jsonx.Event]{Topic: svc.Events})
+	}
+	return mux
+}
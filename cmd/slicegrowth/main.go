@@ -0,0 +1,27 @@
+// Command slicegrowth prints how a slice's capacity grows under append.
+//
+//	go run ./cmd/slicegrowth -n 2000
+//	go run ./cmd/slicegrowth -n 20 -all
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/stawuah/pounce-on-go/slicegrowth"
+)
+
+func main() {
+	n := flag.Int("n", 1000, "number of elements to append")
+	all := flag.Bool("all", false, "print every append, not just reallocations")
+	flag.Parse()
+
+	steps := slicegrowth.Record[int](*n)
+	if !*all {
+		steps = slicegrowth.Growths(steps)
+	}
+	if err := slicegrowth.WriteTable(os.Stdout, steps); err != nil {
+		log.Fatal(err)
+	}
+}
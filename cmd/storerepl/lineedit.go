@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Keys the editor handles in raw mode.
+const (
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyBackspace = 8
+	keyTab       = '\t'
+	keyCtrlU     = 21
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// editor reads lines from a terminal with history (up and down arrows)
+// and Tab completion. Without raw mode it just reads lines; the terminal
+// does the editing.
+//
+// Background goroutines print through Println, which clears the line
+// being typed, prints above it and redraws it, so the two never garble
+// each other.
+type editor struct {
+	in       *bufio.Reader
+	raw      bool
+	complete func(line string) []string // candidates for the last word
+
+	mu      sync.Mutex // guards everything below
+	out     io.Writer
+	prompt  string
+	buf     []rune
+	history []string
+	hpos    int // index into history while browsing; len(history) when not
+}
+
+func newEditor(in io.Reader, out io.Writer, prompt string, raw bool) *editor {
+	return &editor{in: bufio.NewReader(in), out: out, prompt: prompt, raw: raw}
+}
+
+// Println writes s on its own line above the one being edited.
+func (e *editor) Println(s string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.raw {
+		fmt.Fprintln(e.out, s)
+		return
+	}
+	fmt.Fprintf(e.out, "\r\x1b[K%s\n", s)
+	e.redraw()
+}
+
+// History returns the lines entered so far, oldest first.
+func (e *editor) History() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.history...)
+}
+
+// ReadLine returns the next line without its newline, or io.EOF.
+func (e *editor) ReadLine() (string, error) {
+	if !e.raw {
+		return e.readCooked()
+	}
+	e.mu.Lock()
+	e.buf, e.hpos = e.buf[:0], len(e.history)
+	e.redraw()
+	e.mu.Unlock()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		e.mu.Lock()
+		line, done, err := e.key(r)
+		e.mu.Unlock()
+		if done || err != nil {
+			return line, err
+		}
+	}
+}
+
+func (e *editor) readCooked() (string, error) {
+	e.mu.Lock()
+	fmt.Fprint(e.out, e.prompt)
+	e.mu.Unlock()
+	line, err := e.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	e.mu.Lock()
+	e.remember(line)
+	e.mu.Unlock()
+	return line, nil
+}
+
+// key applies one keystroke. e.mu is held.
+func (e *editor) key(r rune) (line string, done bool, err error) {
+	switch r {
+	case '\r', '\n':
+		line = string(e.buf)
+		fmt.Fprint(e.out, "\n")
+		e.remember(line)
+		return line, true, nil
+	case keyCtrlD:
+		if len(e.buf) == 0 {
+			fmt.Fprint(e.out, "\n")
+			return "", true, io.EOF
+		}
+	case keyCtrlC:
+		fmt.Fprint(e.out, "^C\n")
+		e.buf = e.buf[:0]
+	case keyCtrlU:
+		e.buf = e.buf[:0]
+	case keyBackspace, keyDelete:
+		if len(e.buf) > 0 {
+			e.buf = e.buf[:len(e.buf)-1]
+		}
+	case keyTab:
+		e.completeWord()
+	case keyEscape:
+		e.escape()
+	default:
+		if r >= ' ' {
+			e.buf = append(e.buf, r)
+		}
+	}
+	e.redraw()
+	return "", false, nil
+}
+
+// escape handles the arrow keys, which arrive as ESC [ A and ESC [ B.
+// Other sequences are swallowed. The mutex is released while waiting for
+// the rest of the sequence so watchers are not held up.
+func (e *editor) escape() {
+	e.mu.Unlock()
+	b1, err1 := e.in.ReadByte()
+	b2, err2 := e.in.ReadByte()
+	e.mu.Lock()
+	if err1 != nil || err2 != nil || b1 != '[' {
+		return
+	}
+	switch b2 {
+	case 'A':
+		if e.hpos > 0 {
+			e.hpos--
+			e.buf = []rune(e.history[e.hpos])
+		}
+	case 'B':
+		if e.hpos < len(e.history) {
+			e.hpos++
+			e.buf = e.buf[:0]
+			if e.hpos < len(e.history) {
+				e.buf = []rune(e.history[e.hpos])
+			}
+		}
+	}
+}
+
+// completeWord extends the last word as far as every candidate agrees,
+// adding a space once it is unique. If that adds nothing and there is a
+// choice, the candidates are listed.
+func (e *editor) completeWord() {
+	if e.complete == nil {
+		return
+	}
+	line := string(e.buf)
+	word := line[strings.LastIndexByte(line, ' ')+1:]
+	cands := e.complete(line)
+	switch len(cands) {
+	case 0:
+		return
+	case 1:
+		e.buf = append(e.buf, []rune(strings.TrimPrefix(cands[0], word)+" ")...)
+		return
+	}
+	if common := commonPrefix(cands); len(common) > len(word) {
+		e.buf = append(e.buf, []rune(common[len(word):])...)
+		return
+	}
+	fmt.Fprintf(e.out, "\n%s\n", strings.Join(cands, "  "))
+}
+
+func (e *editor) remember(line string) {
+	if strings.TrimSpace(line) != "" && (len(e.history) == 0 || e.history[len(e.history)-1] != line) {
+		e.history = append(e.history, line)
+	}
+	e.hpos = len(e.history)
+}
+
+// redraw rewrites the current line. e.mu is held.
+func (e *editor) redraw() {
+	fmt.Fprintf(e.out, "\r\x1b[K%s%s", e.prompt, string(e.buf))
+}
+
+func commonPrefix(ss []string) string {
+	p := ss[0]
+	for _, s := range ss[1:] {
+		for !strings.HasPrefix(s, p) {
+			p = p[:len(p)-1]
+		}
+	}
+	return p
+}
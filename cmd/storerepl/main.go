@@ -0,0 +1,74 @@
+// Command storerepl is an interactive shell for a key-value store.
+//
+//	go run ./cmd/storerepl                  # in-process store
+//	go run ./cmd/storerepl -addr :7070      # a running storetcpd
+//
+// Commands are get, set, del, list, watch, unwatch, history, help and
+// quit. On a Linux terminal, Tab completes commands and keys and the
+// arrow keys walk the history; elsewhere, or with input piped in, lines
+// are read as they come.
+//
+// A watch polls its key on a background goroutine for as long as the
+// shell runs, printing changes above the line being typed. Against an
+// in-process store nothing else writes, so watches matter most with
+// -addr, where other clients share the store.
+//
+// Flags can also be set by $STOREREPL_<FLAG>, or in the JSON file named
+// by -config.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/concurrency/ownership"
+	"github.com/stawuah/pounce-on-go/config"
+	"github.com/stawuah/pounce-on-go/kvtcp"
+)
+
+type settings struct {
+	Addr  string        `config:"addr" usage:"kvtcp server address; empty uses an in-process store"`
+	Owned bool          `config:"owned" usage:"with no -addr, use the goroutine-owned store"`
+	Poll  time.Duration `config:"poll" usage:"how often watches check their key"`
+}
+
+func main() {
+	cfg := settings{Poll: 250 * time.Millisecond}
+	config.RegisterFlags(flag.CommandLine, &cfg)
+	file := flag.String("config", os.Getenv("STOREREPL_CONFIG"), "JSON settings file")
+	flag.Parse()
+	if err := config.Load(&cfg, config.File(*file), config.Env("STOREREPL", os.Getenv), config.Flags(flag.CommandLine)); err != nil {
+		log.Fatal(err)
+	}
+
+	var s store
+	switch {
+	case cfg.Addr != "":
+		c, err := kvtcp.Dial(cfg.Addr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer c.Close()
+		c.Timeout = 5 * time.Second
+		s = c
+	case cfg.Owned:
+		o := ownership.NewOwnedStore()
+		defer o.Close()
+		s = localStore{o}
+	default:
+		s = localStore{ownership.NewMutexStore()}
+	}
+
+	// Without a terminal there is nothing to prompt or edit. In raw mode
+	// Ctrl-C reaches the editor, which clears the line; otherwise it
+	// interrupts the process as usual.
+	ed := newEditor(os.Stdin, os.Stdout, "", false)
+	if restore, err := makeRaw(int(os.Stdin.Fd())); err == nil {
+		defer restore()
+		ed = newEditor(os.Stdin, os.Stdout, "store> ", true)
+	}
+	newREPL(s, ed, cfg.Poll).run(context.Background())
+}
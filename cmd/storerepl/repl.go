@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/concurrency/ownership"
+)
+
+// store is what the REPL needs from a key-value store. *kvtcp.Client has
+// this method set; localStore adapts an in-process ownership.Store.
+type store interface {
+	Get(key string) (string, bool, error)
+	Set(key, value string) error
+	Delete(key string) error
+	Keys() ([]string, error)
+}
+
+type localStore struct{ s ownership.Store }
+
+func (l localStore) Get(key string) (string, bool, error) {
+	v, ok := l.s.Get(key)
+	return v, ok, nil
+}
+
+func (l localStore) Set(key, value string) error { l.s.Set(key, value); return nil }
+func (l localStore) Delete(key string) error     { l.s.Delete(key); return nil }
+func (l localStore) Keys() ([]string, error)     { return l.s.Keys(), nil }
+
+var help = []struct{ usage, summary string }{
+	{"get KEY", "print the value of KEY"},
+	{"set KEY VALUE", "store VALUE, which may contain spaces, under KEY"},
+	{"del KEY", "delete KEY"},
+	{"list [PREFIX]", "list keys, optionally only those starting with PREFIX"},
+	{"watch KEY", "print every change to KEY until unwatched"},
+	{"unwatch KEY", "stop watching KEY"},
+	{"history", "show the commands entered so far"},
+	{"help", "show this help"},
+	{"quit", "leave (so does Ctrl-D)"},
+}
+
+// repl runs commands against a store. Watches run on their own
+// goroutines, polling the store and printing through the editor.
+type repl struct {
+	store store
+	ed    *editor
+	poll  time.Duration
+
+	mu      sync.Mutex
+	watches map[string]context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func newREPL(s store, ed *editor, poll time.Duration) *repl {
+	r := &repl{store: s, ed: ed, poll: poll, watches: make(map[string]context.CancelFunc)}
+	ed.complete = r.complete
+	return r
+}
+
+// run reads and executes commands until quit or end of input, then stops
+// every watch.
+func (r *repl) run(ctx context.Context) {
+	defer r.unwatchAll()
+	for ctx.Err() == nil {
+		line, err := r.ed.ReadLine()
+		if err != nil {
+			return
+		}
+		if quit := r.exec(ctx, line); quit {
+			return
+		}
+	}
+}
+
+func (r *repl) exec(ctx context.Context, line string) (quit bool) {
+	cmd, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+	rest = strings.TrimSpace(rest)
+	key, value, _ := strings.Cut(rest, " ")
+	value = strings.TrimSpace(value)
+	needKey := func() bool {
+		if key == "" {
+			r.ed.Println("usage: " + cmd + " KEY")
+			return false
+		}
+		return true
+	}
+
+	switch cmd {
+	case "":
+	case "get":
+		if !needKey() {
+			break
+		}
+		v, ok, err := r.store.Get(key)
+		switch {
+		case err != nil:
+			r.ed.Println("error: " + err.Error())
+		case !ok:
+			r.ed.Println("(nil)")
+		default:
+			r.ed.Println(v)
+		}
+	case "set":
+		if !needKey() {
+			break
+		}
+		r.result(r.store.Set(key, value))
+	case "del":
+		if !needKey() {
+			break
+		}
+		r.result(r.store.Delete(key))
+	case "list":
+		keys, err := r.keys(key)
+		if err != nil {
+			r.ed.Println("error: " + err.Error())
+			break
+		}
+		if len(keys) == 0 {
+			r.ed.Println("(empty)")
+		}
+		for _, k := range keys {
+			r.ed.Println(k)
+		}
+	case "watch":
+		if needKey() {
+			r.watch(ctx, key)
+		}
+	case "unwatch":
+		if needKey() {
+			r.unwatch(key)
+		}
+	case "history":
+		for i, h := range r.ed.History() {
+			r.ed.Println(fmt.Sprintf("%4d  %s", i+1, h))
+		}
+	case "help":
+		for _, h := range help {
+			r.ed.Println(fmt.Sprintf("  %-14s %s", h.usage, h.summary))
+		}
+	case "quit", "exit":
+		return true
+	default:
+		r.ed.Println(fmt.Sprintf("unknown command %q; try help", cmd))
+	}
+	return false
+}
+
+func (r *repl) result(err error) {
+	if err != nil {
+		r.ed.Println("error: " + err.Error())
+		return
+	}
+	r.ed.Println("OK")
+}
+
+// keys returns the store's keys starting with prefix, sorted.
+func (r *repl) keys(prefix string) ([]string, error) {
+	all, err := r.store.Keys()
+	if err != nil {
+		return nil, err
+	}
+	out := slices.DeleteFunc(all, func(k string) bool { return !strings.HasPrefix(k, prefix) })
+	slices.Sort(out)
+	return out, nil
+}
+
+// watch starts a goroutine that polls key and reports each change. A
+// store error ends the watch.
+func (r *repl) watch(ctx context.Context, key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.watches[key]; ok {
+		r.ed.Println("already watching " + key)
+		return
+	}
+	// The baseline is read here rather than on the goroutine, so a change
+	// made right after the command returns is still reported.
+	last, lastOK, err := r.store.Get(key)
+	if err != nil {
+		r.ed.Println("error: " + err.Error())
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	r.watches[key] = cancel
+	r.ed.Println("watching " + key)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		t := time.NewTicker(r.poll)
+		defer t.Stop()
+		var err error
+		for err == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+			var v string
+			var ok bool
+			if v, ok, err = r.store.Get(key); err == nil && (v != last || ok != lastOK) {
+				r.ed.Println(fmt.Sprintf("[watch] %s: %s -> %s", key, show(last, lastOK), show(v, ok)))
+				last, lastOK = v, ok
+			}
+		}
+		r.ed.Println(fmt.Sprintf("[watch] %s stopped: %v", key, err))
+		r.mu.Lock()
+		delete(r.watches, key)
+		r.mu.Unlock()
+		cancel()
+	}()
+}
+
+func show(v string, ok bool) string {
+	if !ok {
+		return "(nil)"
+	}
+	return fmt.Sprintf("%q", v)
+}
+
+func (r *repl) unwatch(key string) {
+	r.mu.Lock()
+	cancel, ok := r.watches[key]
+	delete(r.watches, key)
+	r.mu.Unlock()
+	if !ok {
+		r.ed.Println("not watching " + key)
+		return
+	}
+	cancel()
+	r.ed.Println("stopped watching " + key)
+}
+
+func (r *repl) unwatchAll() {
+	r.mu.Lock()
+	for key, cancel := range r.watches {
+		cancel()
+		delete(r.watches, key)
+	}
+	r.mu.Unlock()
+	r.wg.Wait()
+}
+
+// complete returns candidates for the last word of line: a command name
+// for the first word, a key for the second.
+func (r *repl) complete(line string) []string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.HasSuffix(line, " ") {
+		fields = append(fields, "")
+	}
+	word := fields[len(fields)-1]
+	var pool []string
+	switch {
+	case len(fields) == 1:
+		for _, h := range help {
+			pool = append(pool, strings.Fields(h.usage)[0])
+		}
+	case len(fields) == 2 && fields[0] == "unwatch":
+		r.mu.Lock()
+		for k := range r.watches {
+			pool = append(pool, k)
+		}
+		r.mu.Unlock()
+	case len(fields) == 2 && slices.Contains([]string{"get", "set", "del", "list", "watch"}, fields[0]):
+		pool, _ = r.store.Keys()
+	}
+	var out []string
+	for _, c := range pool {
+		if strings.HasPrefix(c, word) {
+			out = append(out, c)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/concurrency/leaktest"
+	"github.com/stawuah/pounce-on-go/concurrency/ownership"
+)
+
+// syncBuffer lets a test read output that watch goroutines write.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestScript(t *testing.T) {
+	in := strings.Join([]string{
+		"set sku:1 Steel  Anvil",
+		"set sku:2 Hammer",
+		"set config:tz UTC",
+		"get sku:1",
+		"get sku:9",
+		"list sku:",
+		"del sku:2",
+		"list nope",
+		"get",
+		"frob",
+		"",
+		"history",
+		"quit",
+		"get sku:1", // never reached
+	}, "\n")
+	var out bytes.Buffer
+	r := newREPL(localStore{ownership.NewMutexStore()}, newEditor(strings.NewReader(in), &out, "", false), time.Hour)
+	r.run(context.Background())
+
+	want := `OK
+OK
+OK
+Steel  Anvil
+(nil)
+sku:1
+sku:2
+OK
+(empty)
+usage: get KEY
+unknown command "frob"; try help
+   1  set sku:1 Steel  Anvil
+   2  set sku:2 Hammer
+   3  set config:tz UTC
+   4  get sku:1
+   5  get sku:9
+   6  list sku:
+   7  del sku:2
+   8  list nope
+   9  get
+  10  frob
+  11  history
+`
+	if got := out.String(); got != want {
+		t.Fatalf("output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	s := localStore{ownership.NewMutexStore()}
+	for _, k := range []string{"apple", "apricot", "banana"} {
+		s.Set(k, "x")
+	}
+	r := newREPL(s, newEditor(strings.NewReader(""), io.Discard, "", false), time.Hour)
+	r.watches["banana"] = func() {}
+
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"", "del,get,help,history,list,quit,set,unwatch,watch"},
+		{"h", "help,history"},
+		{"get ", "apple,apricot,banana"},
+		{"get ap", "apple,apricot"},
+		{"del b", "banana"},
+		{"unwatch ", "banana"},
+		{"set apple ", ""},
+		{"help ", ""},
+		{"zz", ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(r.complete(tt.line), ","); got != tt.want {
+			t.Errorf("complete(%q) = %s, want %s", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestRawEditor(t *testing.T) {
+	s := localStore{ownership.NewMutexStore()}
+	for _, k := range []string{"apple", "apricot"} {
+		s.Set(k, "x")
+	}
+	keys := strings.Join([]string{
+		"g\ta\tp\t\r",     // get apple, completed
+		"\x1b[A\x1b[A\r",  // up twice: still the only history entry
+		"lisx\x7ft\r",     // backspace
+		"set a b\x03",     // Ctrl-C drops the line
+		"\x15del\x1b[D\r", // Ctrl-U, then an unhandled arrow
+		"\x1b[A\x1b[B\r",  // up then down: back to an empty line
+		"\x04",            // Ctrl-D at an empty line
+	}, "")
+	var out bytes.Buffer
+	ed := newEditor(strings.NewReader(keys), &out, "> ", true)
+	newREPL(s, ed, time.Hour)
+
+	var lines []string
+	for {
+		line, err := ed.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line)
+	}
+	want := []string{"get apple ", "get apple ", "list", "del", ""}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Fatalf("lines = %q, want %q", lines, want)
+	}
+	if !strings.Contains(out.String(), "^C\n") {
+		t.Fatalf("Ctrl-C was not echoed: %q", out.String())
+	}
+}
+
+func TestRawEditorListsCandidates(t *testing.T) {
+	s := localStore{ownership.NewMutexStore()}
+	for _, k := range []string{"apple", "apricot"} {
+		s.Set(k, "x")
+	}
+	var out bytes.Buffer
+	ed := newEditor(strings.NewReader("get ap\t\r"), &out, "> ", true)
+	newREPL(s, ed, time.Hour)
+	if line, _ := ed.ReadLine(); line != "get ap" {
+		t.Fatalf("line = %q", line)
+	}
+	if !strings.Contains(out.String(), "\napple  apricot\n") {
+		t.Fatalf("candidates not listed: %q", out.String())
+	}
+}
+
+func TestWatch(t *testing.T) {
+	leaktest.Check(t)
+	s := localStore{ownership.NewMutexStore()}
+	var out syncBuffer
+	r := newREPL(s, newEditor(strings.NewReader(""), &out, "", false), time.Millisecond)
+	ctx := context.Background()
+
+	r.exec(ctx, "watch stock")
+	r.exec(ctx, "watch stock")
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !strings.Contains(out.String(), want) {
+			if time.Now().After(deadline) {
+				t.Fatalf("output lacks %q:\n%s", want, out.String())
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// Another writer sharing the store, as a second client would.
+	s.Set("stock", "5")
+	waitFor(`[watch] stock: (nil) -> "5"`)
+	s.Delete("stock")
+	waitFor(`[watch] stock: "5" -> (nil)`)
+
+	r.exec(ctx, "unwatch stock")
+	r.exec(ctx, "unwatch stock")
+	r.exec(ctx, "watch other")
+	r.unwatchAll()
+
+	for _, want := range []string{"watching stock\nalready watching stock\n", "stopped watching stock\nnot watching stock\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output lacks %q:\n%s", want, out.String())
+		}
+	}
+}
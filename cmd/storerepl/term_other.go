@@ -0,0 +1,11 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// makeRaw is only implemented for Linux; elsewhere the REPL reads whole
+// lines and has no completion or history keys.
+func makeRaw(int) (func(), error) {
+	return nil, errors.New("raw terminal mode not supported on this platform")
+}
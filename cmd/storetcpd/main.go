@@ -0,0 +1,93 @@
+// Command storetcpd serves an in-memory key-value store over TCP using
+// the kvtcp line protocol.
+//
+//	go run ./cmd/storetcpd -addr :7070
+//	printf 'SET sku:1 Anvil\nGET sku:1\nQUIT\n' | nc localhost 7070
+//
+// -owned serves the goroutine-owned store instead of the mutex-guarded
+// one. -otel-stdout prints a span for every store operation, in
+// OpenTelemetry's JSON shape. Interrupt or terminate the process to shut
+// down; open connections are closed.
+//
+// Every flag can also be set by $STORETCPD_<FLAG>, or in the JSON file
+// named by -config; flags win over the environment, which wins over the
+// file.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net"
+	"os"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/concurrency/ownership"
+	"github.com/stawuah/pounce-on-go/config"
+	"github.com/stawuah/pounce-on-go/kvtcp"
+	"github.com/stawuah/pounce-on-go/lifecycle"
+	"github.com/stawuah/pounce-on-go/logging"
+	"github.com/stawuah/pounce-on-go/tracing"
+)
+
+type settings struct {
+	logging.Config
+	Addr        string        `config:"addr" usage:"listen address"`
+	Idle        time.Duration `config:"idle" usage:"close connections idle for this long"`
+	Owned       bool          `config:"owned" usage:"use the goroutine-owned store"`
+	StopTimeout time.Duration `config:"stop-timeout" usage:"how long shutdown waits for open connections"`
+	OTelStdout  bool          `config:"otel-stdout" usage:"write a span for every store operation to stdout as OpenTelemetry JSON"`
+}
+
+func main() {
+	if err := run(); err != nil {
+		slog.Error("storetcpd failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	cfg := settings{Addr: ":7070", Idle: kvtcp.DefaultIdleTimeout, StopTimeout: 5 * time.Second}
+	config.RegisterFlags(flag.CommandLine, &cfg)
+	file := flag.String("config", os.Getenv("STORETCPD_CONFIG"), "JSON settings file")
+	flag.Parse()
+	if err := config.Load(&cfg, config.File(*file), config.Env("STORETCPD", os.Getenv), config.Flags(flag.CommandLine)); err != nil {
+		return err
+	}
+	log, logFile, err := logging.Open(cfg.Config, os.Stderr)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+	slog.SetDefault(log)
+	log.Info("storetcpd: starting", "settings", config.String(&cfg))
+
+	var store ownership.Store = ownership.NewMutexStore()
+	m := lifecycle.New(lifecycle.WithStopTimeout(cfg.StopTimeout), lifecycle.WithLogger(log))
+	if cfg.Owned {
+		s := ownership.NewOwnedStore()
+		store = s
+		m.Register("store", nil, func(context.Context) error { s.Close(); return nil })
+	}
+	if cfg.OTelStdout {
+		store = tracing.Store(tracing.NewTracer(0, tracing.WithExporter(tracing.NewJSONExporter(os.Stdout, "storetcpd"))), store)
+	}
+
+	l, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return err
+	}
+	srv := &kvtcp.Server{Store: store, IdleTimeout: cfg.Idle, Logger: log}
+	m.Register("server", func(context.Context) error {
+		log.Info("storetcpd: listening", "addr", l.Addr().String())
+		go func() {
+			if err := srv.Serve(l); !errors.Is(err, kvtcp.ErrServerClosed) {
+				m.Shutdown(err)
+			}
+		}()
+		return nil
+	}, srv.Shutdown)
+
+	return m.Run(context.Background())
+}
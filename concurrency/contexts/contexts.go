@@ -0,0 +1,105 @@
+// Package contexts collects the three jobs a context.Context does in this
+// repo: cancelling work nobody is waiting for, carrying a deadline down a
+// call chain, and carrying request-scoped values such as a request ID.
+//
+// The types here are a deliberately small store/service pair; what matters
+// is how the context flows through them, not the storage.
+package contexts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when a key has no value.
+var ErrNotFound = errors.New("not found")
+
+// Store is a map with an artificial per-lookup latency, standing in for a
+// remote database.
+type Store struct {
+	Latency time.Duration
+
+	mu   sync.RWMutex
+	data map[string]string
+}
+
+// NewStore returns an empty store whose lookups take latency.
+func NewStore(latency time.Duration) *Store {
+	return &Store{Latency: latency, data: make(map[string]string)}
+}
+
+// Put stores value under key.
+func (s *Store) Put(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+}
+
+// Lookup waits out the store's latency and returns the value for key. It
+// gives up as soon as ctx is done, returning ctx.Err(), so a caller that
+// has stopped waiting does not keep the lookup alive.
+func (s *Store) Lookup(ctx context.Context, key string) (string, error) {
+	t := time.NewTimer(s.Latency)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.data[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrNotFound, key)
+	}
+	return v, nil
+}
+
+// Service sits between a handler and the Store. It never creates a fresh
+// context: every call receives the caller's ctx, so a deadline set at the
+// edge bounds the whole chain.
+type Service struct {
+	Store *Store
+
+	// Budget, if positive, caps each Describe call. A context can only
+	// shorten a deadline, never extend it, so a caller's tighter deadline
+	// still wins.
+	Budget time.Duration
+}
+
+// Describe looks up the name and category for sku in sequence.
+func (s *Service) Describe(ctx context.Context, sku string) (string, error) {
+	if s.Budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Budget)
+		defer cancel()
+	}
+	name, err := s.Store.Lookup(ctx, "name/"+sku)
+	if err != nil {
+		return "", fmt.Errorf("describe %s [req %s]: %w", sku, RequestID(ctx), err)
+	}
+	cat, err := s.Store.Lookup(ctx, "category/"+sku)
+	if err != nil {
+		return "", fmt.Errorf("describe %s [req %s]: %w", sku, RequestID(ctx), err)
+	}
+	return name + " (" + cat + ")", nil
+}
+
+// requestIDKey is unexported so no other package can read or overwrite
+// the value by constructing the same key.
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID stored by WithRequestID, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,104 @@
+package contexts
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newService(latency time.Duration) *Service {
+	st := NewStore(latency)
+	st.Put("name/A1", "Anvil")
+	st.Put("category/A1", "tools")
+	return &Service{Store: st}
+}
+
+func TestLookupStopsOnCancel(t *testing.T) {
+	st := NewStore(time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := st.Lookup(ctx, "anything")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Lookup returned after %v, want prompt return", d)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	svc := newService(0)
+	got, err := svc.Describe(context.Background(), "A1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "Anvil (tools)" {
+		t.Fatalf("Describe() = %q", got)
+	}
+	if _, err := svc.Describe(context.Background(), "Z9"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDeadlinePropagates(t *testing.T) {
+	// Each lookup takes 20ms and Describe makes two; a 30ms deadline set by
+	// the caller must cut the second lookup short.
+	svc := newService(20 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	_, err := svc.Describe(ctx, "A1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestBudgetCannotExtendCallerDeadline(t *testing.T) {
+	svc := newService(20 * time.Millisecond)
+	svc.Budget = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := svc.Describe(ctx, "A1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestBudgetShortensDeadline(t *testing.T) {
+	svc := newService(20 * time.Millisecond)
+	svc.Budget = 10 * time.Millisecond
+
+	if _, err := svc.Describe(context.Background(), "A1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	if id := RequestID(context.Background()); id != "" {
+		t.Fatalf("RequestID(empty) = %q", id)
+	}
+
+	ctx := WithRequestID(context.Background(), "req-42")
+	if id := RequestID(ctx); id != "req-42" {
+		t.Fatalf("RequestID() = %q", id)
+	}
+
+	// A plain string key with the same spelling must not collide.
+	type otherKey string
+	ctx = context.WithValue(ctx, otherKey("requestIDKey"), "spoofed")
+	if id := RequestID(ctx); id != "req-42" {
+		t.Fatalf("RequestID() = %q after unrelated WithValue", id)
+	}
+
+	_, err := newService(0).Describe(ctx, "missing")
+	if err == nil || !strings.Contains(err.Error(), "req-42") {
+		t.Fatalf("err = %v, want request ID in message", err)
+	}
+}
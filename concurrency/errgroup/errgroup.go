@@ -0,0 +1,116 @@
+// Package errgroup is a small, dependency-free take on
+// golang.org/x/sync/errgroup: run goroutines as a group, wait for all of
+// them, and keep the first error. With WithContext the first error also
+// cancels the group's context so the remaining goroutines can stop early.
+package errgroup
+
+import (
+	"context"
+	"sync"
+)
+
+// A Group is a collection of goroutines working on subtasks of one task.
+// The zero Group is valid, has no limit and does not cancel on error.
+type Group struct {
+	cancel func(error)
+	wg     sync.WaitGroup
+	sem    chan struct{}
+
+	errOnce sync.Once
+	err     error
+}
+
+// WithContext returns a Group and a context derived from ctx that is
+// cancelled when any goroutine in the group fails or Wait returns.
+func WithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// SetLimit caps the number of goroutines running at once; Go blocks until
+// a slot is free. An n of zero or less removes the limit, since a limit of
+// zero would block every Go forever. SetLimit must not be called while
+// goroutines are running.
+func (g *Group) SetLimit(n int) {
+	if n <= 0 {
+		g.sem = nil
+		return
+	}
+	if len(g.sem) != 0 {
+		panic("errgroup: SetLimit called while goroutines are running")
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go runs f in a new goroutine, blocking first if the group is at its
+// limit. The first non-nil error returned by any f is kept for Wait.
+func (g *Group) Go(f func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+		if err := f(); err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				if g.cancel != nil {
+					g.cancel(err)
+				}
+			})
+		}
+	}()
+}
+
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+// Wait blocks until every goroutine started with Go has returned, then
+// returns the first error, if any.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(g.err)
+	}
+	return g.err
+}
+
+// FetchAll calls fetch for every id with at most limit calls in flight, or
+// all at once if limit is zero or less, and returns the results in id
+// order. The first failure cancels the context passed to the other calls
+// and is the error returned. If ctx ends before every fetch has started,
+// FetchAll returns its cause.
+func FetchAll[K comparable, V any](ctx context.Context, ids []K, limit int, fetch func(context.Context, K) (V, error)) ([]V, error) {
+	parent := ctx
+	g, ctx := WithContext(ctx)
+	g.SetLimit(limit)
+
+	out := make([]V, len(ids))
+	stopped := false
+	for i, id := range ids {
+		if ctx.Err() != nil {
+			stopped = true
+			break
+		}
+		g.Go(func() error {
+			v, err := fetch(ctx, id)
+			if err != nil {
+				return err
+			}
+			out[i] = v
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	if stopped {
+		// No fetch failed, so it was ctx that ended.
+		return nil, context.Cause(parent)
+	}
+	return out, nil
+}
@@ -0,0 +1,162 @@
+package errgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestZeroGroup(t *testing.T) {
+	var g Group
+	var n atomic.Int32
+	for range 10 {
+		g.Go(func() error { n.Add(1); return nil })
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if n.Load() != 10 {
+		t.Fatalf("ran %d goroutines, want 10", n.Load())
+	}
+}
+
+func TestFirstErrorWins(t *testing.T) {
+	errFirst := errors.New("first")
+	g, ctx := WithContext(context.Background())
+	g.Go(func() error { return errFirst })
+	g.Go(func() error {
+		<-ctx.Done()
+		return errors.New("second")
+	})
+	if err := g.Wait(); !errors.Is(err, errFirst) {
+		t.Fatalf("Wait() = %v, want %v", err, errFirst)
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, errFirst) {
+		t.Fatalf("context cause = %v", cause)
+	}
+}
+
+func TestWaitCancelsContext(t *testing.T) {
+	g, ctx := WithContext(context.Background())
+	g.Go(func() error { return nil })
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Err() == nil {
+		t.Fatal("context still live after Wait")
+	}
+}
+
+func TestSetLimit(t *testing.T) {
+	var g Group
+	g.SetLimit(3)
+	var inFlight, peak atomic.Int32
+	for range 30 {
+		g.Go(func() error {
+			cur := inFlight.Add(1)
+			for old := peak.Load(); cur > old && !peak.CompareAndSwap(old, cur); old = peak.Load() {
+			}
+			time.Sleep(time.Millisecond)
+			inFlight.Add(-1)
+			return nil
+		})
+	}
+	g.Wait()
+	if p := peak.Load(); p > 3 {
+		t.Fatalf("peak = %d, want <= 3", p)
+	}
+}
+
+type product struct {
+	ID   int
+	Name string
+}
+
+var errMissing = errors.New("product missing")
+
+func fetchProduct(ctx context.Context, id int) (product, error) {
+	select {
+	case <-time.After(time.Millisecond):
+	case <-ctx.Done():
+		return product{}, ctx.Err()
+	}
+	if id < 0 {
+		return product{}, fmt.Errorf("id %d: %w", id, errMissing)
+	}
+	return product{ID: id, Name: fmt.Sprintf("p%d", id)}, nil
+}
+
+func TestFetchAll(t *testing.T) {
+	ids := []int{5, 3, 9, 1, 7}
+	got, err := FetchAll(context.Background(), ids, 2, fetchProduct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range got {
+		if p.ID != ids[i] {
+			t.Fatalf("got[%d].ID = %d, want %d", i, p.ID, ids[i])
+		}
+	}
+}
+
+func TestFetchAllStopsOnError(t *testing.T) {
+	var calls atomic.Int32
+	fetch := func(ctx context.Context, id int) (product, error) {
+		calls.Add(1)
+		return fetchProduct(ctx, id)
+	}
+	ids := make([]int, 100)
+	ids[2] = -1
+
+	_, err := FetchAll(context.Background(), ids, 2, fetch)
+	if !errors.Is(err, errMissing) {
+		t.Fatalf("err = %v, want errMissing", err)
+	}
+	if n := calls.Load(); n == int32(len(ids)) {
+		t.Fatal("every fetch ran despite an early failure")
+	}
+}
+
+func TestFetchAllCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	got, err := FetchAll(ctx, []int{1, 2, 3}, 2, fetchProduct)
+	if !errors.Is(err, context.Canceled) || got != nil {
+		t.Fatalf("FetchAll with a cancelled context = %v, %v; want nil, context.Canceled", got, err)
+	}
+
+	// Cancelled part-way, by a fetch that itself succeeds.
+	ctx, stopAll := context.WithCancelCause(context.Background())
+	stop := errors.New("shutting down")
+	fetch := func(ctx context.Context, id int) (product, error) {
+		if id == 2 {
+			stopAll(stop)
+		}
+		return product{ID: id}, nil
+	}
+	if _, err := FetchAll(ctx, []int{1, 2, 3, 4}, 1, fetch); !errors.Is(err, stop) {
+		t.Fatalf("FetchAll cancelled part-way = %v, want %v", err, stop)
+	}
+}
+
+func TestFetchAllNoLimit(t *testing.T) {
+	ids := []int{1, 2, 3}
+	for _, limit := range []int{0, -1} {
+		done := make(chan error)
+		go func() {
+			_, err := FetchAll(context.Background(), ids, limit, fetchProduct)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("FetchAll with limit %d = %v", limit, err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("FetchAll with limit %d blocked", limit)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Package fanout spreads independent jobs over several goroutines (fan-out)
+// and merges what they produce back into one channel (fan-in).
+//
+// Each worker writes to its own output channel and Merge combines them, so
+// results arrive in completion order, not input order. Every Result
+// carries the index of the input it came from; Ordered uses that index to
+// put results back in input order without any extra synchronisation.
+package fanout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Result is the outcome for the input at Index.
+type Result[T any] struct {
+	Index int
+	Value T
+	Err   error
+}
+
+// Merge forwards every value from chans onto one channel, which is closed
+// once all of chans are closed. If ctx ends first, Merge stops forwarding
+// and closes its output; producers must then stop on ctx themselves.
+func Merge[T any](ctx context.Context, chans ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(len(chans))
+	for _, c := range chans {
+		go func() {
+			defer wg.Done()
+			for v := range c {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Run applies f to every input using workers goroutines and returns their
+// merged results in completion order. The channel is closed when all
+// inputs are done or ctx is cancelled.
+func Run[In, Out any](ctx context.Context, inputs []In, workers int, f func(context.Context, In) (Out, error)) <-chan Result[Out] {
+	workers = max(1, min(workers, len(inputs)))
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range inputs {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	outs := make([]<-chan Result[Out], workers)
+	for w := range outs {
+		out := make(chan Result[Out])
+		outs[w] = out
+		go func() {
+			defer close(out)
+			for i := range jobs {
+				v, err := f(ctx, inputs[i])
+				select {
+				case out <- Result[Out]{Index: i, Value: v, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	return Merge(ctx, outs...)
+}
+
+// Collect drains Run into a slice in completion order. Failures do not
+// stop the other jobs; they are returned together via errors.Join, each
+// labelled with its input index.
+func Collect[In, Out any](ctx context.Context, inputs []In, workers int, f func(context.Context, In) (Out, error)) ([]Out, error) {
+	var (
+		values []Out
+		errs   []error
+	)
+	for r := range Run(ctx, inputs, workers, f) {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("input %d: %w", r.Index, r.Err))
+			continue
+		}
+		values = append(values, r.Value)
+	}
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return values, errors.Join(errs...)
+}
+
+// Ordered is Collect with results in input order: out[i] is f(inputs[i]).
+// Entries whose job failed hold the zero value.
+func Ordered[In, Out any](ctx context.Context, inputs []In, workers int, f func(context.Context, In) (Out, error)) ([]Out, error) {
+	out := make([]Out, len(inputs))
+	var errs []error
+	for r := range Run(ctx, inputs, workers, f) {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("input %d: %w", r.Index, r.Err))
+			continue
+		}
+		out[r.Index] = r.Value
+	}
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return out, errors.Join(errs...)
+}
@@ -0,0 +1,121 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/concurrency/leaktest"
+)
+
+type product struct {
+	SKU      string
+	Category string
+}
+
+var errUnknownSKU = errors.New("unknown sku")
+
+// enrich simulates a slow lookup with random latency so results complete
+// out of order.
+func enrich(_ context.Context, sku string) (product, error) {
+	time.Sleep(time.Duration(rand.N(3)) * time.Millisecond)
+	if strings.HasPrefix(sku, "X") {
+		return product{}, errUnknownSKU
+	}
+	return product{SKU: sku, Category: "cat-" + sku[:1]}, nil
+}
+
+func TestMerge(t *testing.T) {
+	a, b := make(chan int), make(chan int)
+	go func() { a <- 1; a <- 2; close(a) }()
+	go func() { b <- 3; close(b) }()
+
+	var got []int
+	for v := range Merge(context.Background(), a, b) {
+		got = append(got, v)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("Merge() = %v", got)
+	}
+}
+
+func TestCollectAggregatesErrors(t *testing.T) {
+	leaktest.Check(t)
+	skus := []string{"A1", "X1", "B2", "X2", "C3"}
+	got, err := Collect(context.Background(), skus, 3, enrich)
+
+	if len(got) != 3 {
+		t.Fatalf("got %d products, want 3", len(got))
+	}
+	if !errors.Is(err, errUnknownSKU) {
+		t.Fatalf("err = %v, want errUnknownSKU", err)
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "input 1") || !strings.Contains(msg, "input 3") {
+		t.Fatalf("aggregated error missing an input: %q", msg)
+	}
+}
+
+func TestOrderedPreservesInputOrder(t *testing.T) {
+	skus := make([]string, 40)
+	for i := range skus {
+		skus[i] = string(rune('A'+i%20)) + "-sku"
+	}
+	got, err := Ordered(context.Background(), skus, 8, enrich)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range got {
+		if p.SKU != skus[i] {
+			t.Fatalf("out[%d] = %q, want %q", i, p.SKU, skus[i])
+		}
+	}
+}
+
+func TestWorkersBoundConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	work := func(_ context.Context, n int) (int, error) {
+		cur := inFlight.Add(1)
+		for {
+			old := peak.Load()
+			if cur <= old || peak.CompareAndSwap(old, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		inFlight.Add(-1)
+		return n, nil
+	}
+	if _, err := Collect(context.Background(), make([]int, 50), 4, work); err != nil {
+		t.Fatal(err)
+	}
+	if p := peak.Load(); p > 4 {
+		t.Fatalf("peak concurrency = %d, want <= 4", p)
+	}
+}
+
+func TestCancellation(t *testing.T) {
+	leaktest.Check(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	var started atomic.Int32
+	work := func(ctx context.Context, n int) (int, error) {
+		if started.Add(1) == 3 {
+			cancel()
+		}
+		<-ctx.Done()
+		return n, ctx.Err()
+	}
+	_, err := Ordered(ctx, make([]int, 100), 3, work)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if n := started.Load(); n > 10 {
+		t.Fatalf("%d jobs started after cancellation", n)
+	}
+}
@@ -0,0 +1,108 @@
+// Package leaktest fails a test that leaves goroutines running.
+//
+//	func TestPool(t *testing.T) {
+//		leaktest.Check(t)
+//		...
+//	}
+//
+// Check records every goroutine alive when it is called. When the test
+// finishes it waits a short while for goroutines started since then to
+// exit, and reports the stacks of any that are still running. Goroutines
+// belonging to other tests are ignored, but a test that calls t.Parallel
+// can still see goroutines from its siblings, so use Check only in
+// sequential tests.
+package leaktest
+
+import (
+	"bytes"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// DefaultTimeout is how long Check waits for goroutines to exit.
+const DefaultTimeout = 2 * time.Second
+
+// Check registers a cleanup on t that reports goroutines leaked by the
+// test. Call it first, before the test starts any goroutines.
+func Check(t testing.TB) {
+	CheckTimeout(t, DefaultTimeout)
+}
+
+// CheckTimeout is Check with a custom grace period.
+func CheckTimeout(t testing.TB, timeout time.Duration) {
+	t.Helper()
+	before := goroutines()
+	t.Cleanup(func() {
+		var leaked []string
+		deadline := time.Now().Add(timeout)
+		for {
+			leaked = leaked[:0]
+			for id, stack := range goroutines() {
+				if _, ok := before[id]; !ok {
+					leaked = append(leaked, stack)
+				}
+			}
+			if len(leaked) == 0 || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		slices.Sort(leaked)
+		for _, stack := range leaked {
+			t.Errorf("leaked goroutine:\n%s", stack)
+		}
+	})
+}
+
+// goroutines returns the stacks of interesting live goroutines keyed by
+// goroutine ID.
+func goroutines() map[int]string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	out := make(map[int]string)
+	for _, g := range bytes.Split(buf, []byte("\n\n")) {
+		stack := string(g)
+		id, ok := parseID(stack)
+		if !ok || ignored(stack) {
+			continue
+		}
+		out[id] = stack
+	}
+	return out
+}
+
+// parseID extracts N from a header of the form "goroutine N [state]:".
+func parseID(stack string) (int, bool) {
+	rest, ok := strings.CutPrefix(stack, "goroutine ")
+	if !ok {
+		return 0, false
+	}
+	idStr, _, ok := strings.Cut(rest, " ")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.Atoi(idStr)
+	return id, err == nil
+}
+
+// ignored reports whether a goroutine belongs to the test framework or the
+// runtime rather than to the code under test.
+func ignored(stack string) bool {
+	return strings.Contains(stack, "testing.tRunner(") ||
+		strings.Contains(stack, "testing.(*T).Run(") ||
+		strings.Contains(stack, "testing.runTests(") ||
+		strings.Contains(stack, "runtime.goexit0") ||
+		strings.Contains(stack, "os/signal.signal_recv")
+}
@@ -0,0 +1,91 @@
+package leaktest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recorder captures what Check reports instead of failing the real test.
+type recorder struct {
+	testing.TB
+	cleanups []func()
+	errors   []string
+}
+
+func (r *recorder) Helper()          {}
+func (r *recorder) Cleanup(f func()) { r.cleanups = append(r.cleanups, f) }
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestNoLeak(t *testing.T) {
+	r := &recorder{TB: t}
+	CheckTimeout(r, time.Second)
+
+	done := make(chan struct{})
+	go func() { close(done) }()
+	<-done
+
+	r.finish()
+	if len(r.errors) != 0 {
+		t.Fatalf("unexpected leak report: %v", r.errors)
+	}
+}
+
+func TestSlowExitIsNotALeak(t *testing.T) {
+	r := &recorder{TB: t}
+	CheckTimeout(r, time.Second)
+
+	go time.Sleep(20 * time.Millisecond)
+
+	r.finish()
+	if len(r.errors) != 0 {
+		t.Fatalf("unexpected leak report: %v", r.errors)
+	}
+}
+
+func blockForever(stop <-chan struct{}) { <-stop }
+
+func TestLeakReported(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	r := &recorder{TB: t}
+	CheckTimeout(r, 20*time.Millisecond)
+	go blockForever(stop)
+
+	r.finish()
+	if len(r.errors) != 1 {
+		t.Fatalf("got %d reports, want 1", len(r.errors))
+	}
+	if !strings.Contains(r.errors[0], "blockForever") {
+		t.Fatalf("report does not name the leaking function:\n%s", r.errors[0])
+	}
+}
+
+func TestParseID(t *testing.T) {
+	tests := []struct {
+		in   string
+		id   int
+		want bool
+	}{
+		{"goroutine 17 [running]:\nmain.main()", 17, true},
+		{"goroutine x [running]:", 0, false},
+		{"not a header", 0, false},
+	}
+	for _, tt := range tests {
+		id, ok := parseID(tt.in)
+		if id != tt.id || ok != tt.want {
+			t.Errorf("parseID(%q) = %d, %t", tt.in, id, ok)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package locking
+
+import (
+	"maps"
+	"sync/atomic"
+)
+
+// CASStock keeps the stock levels in an immutable snapshot behind an
+// atomic pointer. A reservation copies the snapshot, changes the copy and
+// swaps it in only if the snapshot is still the current one; otherwise it
+// retries against the newer one.
+//
+// Copying every level on every reservation suits a few hundred SKUs. A
+// real store would version each SKU separately, which is what
+// repository.Unit does with its revisions.
+type CASStock struct {
+	state   atomic.Pointer[snapshot]
+	retries atomic.Uint64
+}
+
+// snapshot is one version of the stock levels. It is never changed once
+// published.
+type snapshot struct {
+	version uint64
+	levels  map[string]int
+}
+
+// NewCASStock returns stock starting at levels, which it copies.
+func NewCASStock(levels map[string]int) *CASStock {
+	s := new(CASStock)
+	s.state.Store(&snapshot{levels: maps.Clone(levels)})
+	return s
+}
+
+// Reserve takes lines from stock, all or none. An order that cannot be
+// met fails without a retry: the snapshot it failed against was current
+// when read, so the answer was true then.
+func (s *CASStock) Reserve(lines ...Line) error {
+	for {
+		cur := s.state.Load()
+		if err := check(cur.levels, lines); err != nil {
+			return err
+		}
+		next := &snapshot{version: cur.version + 1, levels: maps.Clone(cur.levels)}
+		reserve(next.levels, lines)
+		if s.state.CompareAndSwap(cur, next) {
+			return nil
+		}
+		s.retries.Add(1)
+	}
+}
+
+// Available returns how much of sku is left. It never waits.
+func (s *CASStock) Available(sku string) int { return s.state.Load().levels[sku] }
+
+// Version returns how many reservations have succeeded.
+func (s *CASStock) Version() uint64 { return s.state.Load().version }
+
+// Retries returns how many times a reservation lost a race and started
+// again.
+func (s *CASStock) Retries() uint64 { return s.retries.Load() }
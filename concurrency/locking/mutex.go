@@ -0,0 +1,32 @@
+package locking
+
+import (
+	"maps"
+	"sync"
+)
+
+// MutexStock guards the stock levels with a mutex held for the whole of
+// each reservation.
+type MutexStock struct {
+	mu     sync.Mutex
+	levels map[string]int
+}
+
+// NewMutexStock returns stock starting at levels, which it copies.
+func NewMutexStock(levels map[string]int) *MutexStock {
+	return &MutexStock{levels: maps.Clone(levels)}
+}
+
+// Reserve takes lines from stock, all or none.
+func (s *MutexStock) Reserve(lines ...Line) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return reserve(s.levels, lines)
+}
+
+// Available returns how much of sku is left.
+func (s *MutexStock) Available(sku string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.levels[sku]
+}
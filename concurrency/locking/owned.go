@@ -0,0 +1,62 @@
+package locking
+
+import "maps"
+
+// OwnedStock keeps the stock levels in a goroutine started by
+// NewOwnedStock, which applies one request at a time. Call Close to stop
+// it; using the stock after Close panics.
+type OwnedStock struct {
+	reserve chan reservation
+	read    chan query
+}
+
+type reservation struct {
+	lines []Line
+	reply chan error
+}
+
+type query struct {
+	sku   string
+	reply chan int
+}
+
+// NewOwnedStock starts the owner goroutine with levels, which it copies.
+func NewOwnedStock(levels map[string]int) *OwnedStock {
+	s := &OwnedStock{
+		reserve: make(chan reservation),
+		read:    make(chan query),
+	}
+	go s.loop(maps.Clone(levels))
+	return s
+}
+
+func (s *OwnedStock) loop(levels map[string]int) {
+	for {
+		select {
+		case r, ok := <-s.reserve:
+			if !ok {
+				return
+			}
+			r.reply <- reserve(levels, r.lines)
+		case q := <-s.read:
+			q.reply <- levels[q.sku]
+		}
+	}
+}
+
+// Reserve takes lines from stock, all or none.
+func (s *OwnedStock) Reserve(lines ...Line) error {
+	reply := make(chan error, 1)
+	s.reserve <- reservation{lines: lines, reply: reply}
+	return <-reply
+}
+
+// Available returns how much of sku is left.
+func (s *OwnedStock) Available(sku string) int {
+	reply := make(chan int, 1)
+	s.read <- query{sku: sku, reply: reply}
+	return <-reply
+}
+
+// Close stops the owner goroutine.
+func (s *OwnedStock) Close() { close(s.reserve) }
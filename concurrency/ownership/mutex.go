@@ -0,0 +1,75 @@
+package ownership
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// MutexCounter is a counter guarded by a mutex. The zero value is ready
+// to use.
+type MutexCounter struct {
+	mu sync.Mutex
+	n  int64
+}
+
+// Add adds delta to the counter.
+func (c *MutexCounter) Add(delta int64) {
+	c.mu.Lock()
+	c.n += delta
+	c.mu.Unlock()
+}
+
+// Value returns the current count.
+func (c *MutexCounter) Value() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+// MutexStore is a map guarded by a read-write mutex.
+type MutexStore struct {
+	mu sync.RWMutex
+	m  map[string]string
+}
+
+// NewMutexStore returns an empty store.
+func NewMutexStore() *MutexStore {
+	return &MutexStore{m: make(map[string]string)}
+}
+
+// Get returns the value for key.
+func (s *MutexStore) Get(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.m[key]
+	return v, ok
+}
+
+// Set stores value under key.
+func (s *MutexStore) Set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = value
+}
+
+// Delete removes key.
+func (s *MutexStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m, key)
+}
+
+// Len returns the number of keys.
+func (s *MutexStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.m)
+}
+
+// Keys returns every key in sorted order.
+func (s *MutexStore) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Sorted(maps.Keys(s.m))
+}
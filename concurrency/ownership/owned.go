@@ -0,0 +1,138 @@
+package ownership
+
+import (
+	"maps"
+	"slices"
+)
+
+// OwnedCounter is a counter whose value lives in a goroutine started by
+// NewOwnedCounter. Call Close to stop it; using the counter after Close
+// panics.
+type OwnedCounter struct {
+	add  chan int64
+	read chan chan int64
+}
+
+// NewOwnedCounter starts the owner goroutine and returns its handle.
+func NewOwnedCounter() *OwnedCounter {
+	c := &OwnedCounter{
+		add:  make(chan int64),
+		read: make(chan chan int64),
+	}
+	go c.loop()
+	return c
+}
+
+func (c *OwnedCounter) loop() {
+	var n int64
+	for {
+		select {
+		case d, ok := <-c.add:
+			if !ok {
+				return
+			}
+			n += d
+		case reply := <-c.read:
+			reply <- n
+		}
+	}
+}
+
+// Add adds delta to the counter.
+func (c *OwnedCounter) Add(delta int64) { c.add <- delta }
+
+// Value returns the current count.
+func (c *OwnedCounter) Value() int64 {
+	reply := make(chan int64)
+	c.read <- reply
+	return <-reply
+}
+
+// Close stops the owner goroutine.
+func (c *OwnedCounter) Close() { close(c.add) }
+
+// storeOp is one command for the store owner. Each command carries its
+// own reply channel so callers never share one.
+type storeOp struct {
+	kind  opKind
+	key   string
+	value string
+	reply chan storeReply
+}
+
+type opKind int
+
+const (
+	opGet opKind = iota
+	opSet
+	opDelete
+	opLen
+	opKeys
+)
+
+type storeReply struct {
+	value string
+	ok    bool
+	n     int
+	keys  []string
+}
+
+// OwnedStore is a map owned by a single goroutine. Every method sends a
+// command and waits for the reply. Call Close to stop the owner.
+type OwnedStore struct {
+	ops chan storeOp
+}
+
+// NewOwnedStore starts the owner goroutine and returns its handle.
+func NewOwnedStore() *OwnedStore {
+	s := &OwnedStore{ops: make(chan storeOp)}
+	go s.loop()
+	return s
+}
+
+func (s *OwnedStore) loop() {
+	m := make(map[string]string)
+	for op := range s.ops {
+		var r storeReply
+		switch op.kind {
+		case opGet:
+			r.value, r.ok = m[op.key]
+		case opSet:
+			m[op.key] = op.value
+		case opDelete:
+			delete(m, op.key)
+		case opLen:
+			r.n = len(m)
+		case opKeys:
+			r.keys = slices.Sorted(maps.Keys(m))
+		}
+		op.reply <- r
+	}
+}
+
+func (s *OwnedStore) do(op storeOp) storeReply {
+	op.reply = make(chan storeReply, 1)
+	s.ops <- op
+	return <-op.reply
+}
+
+// Get returns the value for key.
+func (s *OwnedStore) Get(key string) (string, bool) {
+	r := s.do(storeOp{kind: opGet, key: key})
+	return r.value, r.ok
+}
+
+// Set stores value under key.
+func (s *OwnedStore) Set(key, value string) { s.do(storeOp{kind: opSet, key: key, value: value}) }
+
+// Delete removes key.
+func (s *OwnedStore) Delete(key string) { s.do(storeOp{kind: opDelete, key: key}) }
+
+// Len returns the number of keys.
+func (s *OwnedStore) Len() int { return s.do(storeOp{kind: opLen}).n }
+
+// Keys returns every key in sorted order.
+func (s *OwnedStore) Keys() []string { return s.do(storeOp{kind: opKeys}).keys }
+
+// Close stops the owner goroutine.
+func (s *OwnedStore) Close() { close(s.ops) }
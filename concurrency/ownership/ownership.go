@@ -0,0 +1,27 @@
+// Package ownership implements a counter and a key-value store twice:
+// once as shared memory guarded by a mutex, and once owned by a single
+// goroutine that receives commands over a channel ("share memory by
+// communicating").
+//
+// Both forms are correct under the race detector. The mutex forms are
+// simpler and around twenty times faster for operations this small; the owner
+// forms pay a channel round trip per call but make it impossible to touch
+// the state without going through the owner, and they compose naturally
+// with select, timeouts and batching. The benchmarks in this package put
+// numbers on that trade.
+package ownership
+
+// Counter is implemented by MutexCounter and OwnedCounter.
+type Counter interface {
+	Add(delta int64)
+	Value() int64
+}
+
+// Store is implemented by MutexStore and OwnedStore.
+type Store interface {
+	Get(key string) (string, bool)
+	Set(key, value string)
+	Delete(key string)
+	Len() int
+	Keys() []string // sorted
+}
@@ -0,0 +1,136 @@
+package ownership
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stawuah/pounce-on-go/concurrency/leaktest"
+)
+
+func counters() map[string]func() (Counter, func()) {
+	return map[string]func() (Counter, func()){
+		"Mutex": func() (Counter, func()) { return &MutexCounter{}, func() {} },
+		"Owned": func() (Counter, func()) {
+			c := NewOwnedCounter()
+			return c, c.Close
+		},
+	}
+}
+
+func stores() map[string]func() (Store, func()) {
+	return map[string]func() (Store, func()){
+		"Mutex": func() (Store, func()) { return NewMutexStore(), func() {} },
+		"Owned": func() (Store, func()) {
+			s := NewOwnedStore()
+			return s, s.Close
+		},
+	}
+}
+
+func TestCounterConcurrent(t *testing.T) {
+	for name, mk := range counters() {
+		t.Run(name, func(t *testing.T) {
+			leaktest.Check(t)
+			c, done := mk()
+			defer done()
+
+			var wg sync.WaitGroup
+			for range 8 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for range 500 {
+						c.Add(1)
+						c.Value()
+					}
+				}()
+			}
+			wg.Wait()
+			if got := c.Value(); got != 4000 {
+				t.Fatalf("Value() = %d, want 4000", got)
+			}
+		})
+	}
+}
+
+func TestStoreConcurrent(t *testing.T) {
+	for name, mk := range stores() {
+		t.Run(name, func(t *testing.T) {
+			leaktest.Check(t)
+			s, done := mk()
+			defer done()
+
+			var wg sync.WaitGroup
+			for w := range 8 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range 100 {
+						key := strconv.Itoa(w*100 + i)
+						s.Set(key, key)
+						if v, ok := s.Get(key); !ok || v != key {
+							t.Errorf("Get(%q) = %q, %t", key, v, ok)
+						}
+						if i%2 == 1 {
+							s.Delete(key)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			if n := s.Len(); n != 400 {
+				t.Fatalf("Len() = %d, want 400", n)
+			}
+			if _, ok := s.Get("1"); ok {
+				t.Fatal("deleted key still present")
+			}
+			if keys := s.Keys(); len(keys) != 400 || !slices.IsSorted(keys) || keys[0] != "0" {
+				t.Fatalf("Keys() = %d keys starting %q, want 400 sorted from \"0\"", len(keys), keys[:1])
+			}
+		})
+	}
+}
+
+func BenchmarkCounter(b *testing.B) {
+	for name, mk := range counters() {
+		b.Run(name, func(b *testing.B) {
+			c, done := mk()
+			defer done()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					c.Add(1)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkStore(b *testing.B) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	for name, mk := range stores() {
+		b.Run(name+"/ReadHeavy", func(b *testing.B) {
+			s, done := mk()
+			defer done()
+			for _, k := range keys {
+				s.Set(k, k)
+			}
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					k := keys[i%len(keys)]
+					if i%10 == 0 {
+						s.Set(k, k)
+					} else {
+						s.Get(k)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
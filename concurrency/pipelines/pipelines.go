@@ -0,0 +1,138 @@
+// Package pipelines connects processing stages with channels.
+//
+// A Pipeline owns the context shared by its stages. Each stage runs in its
+// own goroutine, reads from the previous stage's channel and closes its
+// own output when done, so shutdown ripples downstream naturally. The
+// first stage to fail cancels the context; every stage selects on it
+// around each send, so upstream stages stop instead of blocking forever
+// on a channel nobody reads any more. Wait returns that first error.
+package pipelines
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// Pipeline tracks the stages started on it.
+type Pipeline struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+}
+
+// New returns a Pipeline whose stages stop when ctx is cancelled.
+func New(ctx context.Context) *Pipeline {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Pipeline{ctx: ctx, cancel: cancel}
+}
+
+// Context returns the context shared by the stages.
+func (p *Pipeline) Context() context.Context { return p.ctx }
+
+// Wait blocks until every stage has returned and reports the first error,
+// or the parent context's error if it was cancelled from outside.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	err := context.Cause(p.ctx) // nil unless a stage failed or the parent ended
+	p.cancel(nil)
+	if p.err != nil {
+		return p.err
+	}
+	return err
+}
+
+func (p *Pipeline) fail(err error) {
+	p.once.Do(func() {
+		p.err = err
+		p.cancel(err)
+	})
+}
+
+func (p *Pipeline) goStage(f func() error) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if err := f(); err != nil {
+			p.fail(err)
+		}
+	}()
+}
+
+// send delivers v unless the pipeline is cancelled first.
+func send[T any](ctx context.Context, out chan<- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Source emits the values of seq. A non-nil error from seq stops the
+// pipeline.
+func Source[T any](p *Pipeline, seq iter.Seq2[T, error]) <-chan T {
+	out := make(chan T)
+	p.goStage(func() error {
+		defer close(out)
+		for v, err := range seq {
+			if err != nil {
+				return err
+			}
+			if !send(p.ctx, out, v) {
+				return nil
+			}
+		}
+		return nil
+	})
+	return out
+}
+
+// Map applies f to every value. An error from f stops the pipeline.
+func Map[In, Out any](p *Pipeline, in <-chan In, f func(context.Context, In) (Out, error)) <-chan Out {
+	out := make(chan Out)
+	p.goStage(func() error {
+		defer close(out)
+		for v := range in {
+			r, err := f(p.ctx, v)
+			if err != nil {
+				return err
+			}
+			if !send(p.ctx, out, r) {
+				return nil
+			}
+		}
+		return nil
+	})
+	return out
+}
+
+// Filter passes on the values for which keep returns true.
+func Filter[T any](p *Pipeline, in <-chan T, keep func(T) bool) <-chan T {
+	out := make(chan T)
+	p.goStage(func() error {
+		defer close(out)
+		for v := range in {
+			if keep(v) && !send(p.ctx, out, v) {
+				return nil
+			}
+		}
+		return nil
+	})
+	return out
+}
+
+// Sink consumes every value with f. An error from f stops the pipeline.
+// Call Wait to block until the sink has finished.
+func Sink[T any](p *Pipeline, in <-chan T, f func(context.Context, T) error) {
+	p.goStage(func() error {
+		for v := range in {
+			if err := f(p.ctx, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
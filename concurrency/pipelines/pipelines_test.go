@@ -0,0 +1,135 @@
+package pipelines
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stawuah/pounce-on-go/concurrency/leaktest"
+)
+
+func counting(n int) func(yield func(int, error) bool) {
+	return func(yield func(int, error) bool) {
+		for i := range n {
+			if !yield(i, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestStagesInOrder(t *testing.T) {
+	p := New(context.Background())
+	nums := Source(p, counting(10))
+	even := Filter(p, nums, func(n int) bool { return n%2 == 0 })
+	squared := Map(p, even, func(_ context.Context, n int) (int, error) { return n * n, nil })
+
+	var got []int
+	Sink(p, squared, func(_ context.Context, n int) error {
+		got = append(got, n)
+		return nil
+	})
+	if err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{0, 4, 16, 36, 64}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestErrorStopsUpstream(t *testing.T) {
+	leaktest.Check(t)
+	boom := errors.New("boom")
+
+	p := New(context.Background())
+	// An endless source: only cancellation can stop it.
+	nums := Source(p, func(yield func(int, error) bool) {
+		for i := 0; ; i++ {
+			if !yield(i, nil) {
+				return
+			}
+		}
+	})
+	mapped := Map(p, nums, func(_ context.Context, n int) (int, error) {
+		if n == 5 {
+			return 0, boom
+		}
+		return n, nil
+	})
+	Sink(p, mapped, func(context.Context, int) error { return nil })
+
+	if err := p.Wait(); !errors.Is(err, boom) {
+		t.Fatalf("Wait() = %v, want boom", err)
+	}
+}
+
+func TestParentCancellation(t *testing.T) {
+	leaktest.Check(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	p := New(ctx)
+	nums := Source(p, counting(1_000_000))
+	var once sync.Once
+	Sink(p, nums, func(context.Context, int) error {
+		once.Do(cancel)
+		return nil
+	})
+	if err := p.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait() = %v, want context.Canceled", err)
+	}
+}
+
+func TestImportProducts(t *testing.T) {
+	input := `sku,name,cost
+A-1,Apple,1.00
+B-2,,2.00
+C-3,Cherry,abc
+D-4,Date,-1
+E-5,Elderberry,3.99
+F-6,Fig
+`
+	var stored []Product
+	report, err := ImportProducts(context.Background(), strings.NewReader(input), 0.25,
+		func(_ context.Context, p Product) error {
+			stored = append(stored, p)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Product{
+		{SKU: "A-1", Name: "Apple", Cost: 1, Price: 1.25},
+		{SKU: "E-5", Name: "Elderberry", Cost: 3.99, Price: 4.99},
+	}
+	if !slices.Equal(stored, want) || report.Stored != 2 {
+		t.Fatalf("stored %+v (report %d)", stored, report.Stored)
+	}
+
+	var lines []int
+	for _, r := range report.Rejected {
+		lines = append(lines, r.Line)
+	}
+	if !slices.Equal(lines, []int{3, 4, 5, 7}) {
+		t.Fatalf("rejected lines = %v (%+v)", lines, report.Rejected)
+	}
+}
+
+func TestImportProductsStoreError(t *testing.T) {
+	full := errors.New("store full")
+	_, err := ImportProducts(context.Background(), strings.NewReader("sku,name,cost\nA,a,1\nB,b,2\n"), 0,
+		func(context.Context, Product) error { return full })
+	if !errors.Is(err, full) {
+		t.Fatalf("err = %v, want store error", err)
+	}
+}
+
+func TestImportProductsMalformedCSV(t *testing.T) {
+	_, err := ImportProducts(context.Background(), strings.NewReader("sku,name,cost\n\"unterminated,x,1\n"), 0,
+		func(context.Context, Product) error { return nil })
+	if err == nil {
+		t.Fatal("malformed CSV did not fail the import")
+	}
+}
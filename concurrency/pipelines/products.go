@@ -0,0 +1,136 @@
+package pipelines
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Product is one catalog row flowing through ImportProducts.
+type Product struct {
+	SKU   string
+	Name  string
+	Cost  float64
+	Price float64
+}
+
+// Rejection records a row that failed validation.
+type Rejection struct {
+	Line   int
+	Reason string
+}
+
+// ImportReport summarises an ImportProducts run.
+type ImportReport struct {
+	Stored   int
+	Rejected []Rejection
+}
+
+type csvRow struct {
+	line   int
+	fields []string
+}
+
+// ImportProducts reads "sku,name,cost" CSV rows from r (with a header
+// line), validates them, prices them at cost plus markup (0.25 = 25%),
+// and passes the result to store. Invalid rows are reported, not fatal;
+// a malformed CSV stream or a store error aborts the import.
+//
+// The stages are: read → parse → validate → price → store.
+func ImportProducts(ctx context.Context, r io.Reader, markup float64, store func(context.Context, Product) error) (ImportReport, error) {
+	var report ImportReport
+	p := New(ctx)
+
+	rows := Source(p, readCSV(r))
+	parsed := Map(p, rows, func(_ context.Context, row csvRow) (parsedRow, error) {
+		return parse(row), nil
+	})
+	valid := Filter(p, parsed, func(pr parsedRow) bool {
+		if pr.err != nil {
+			report.Rejected = append(report.Rejected, Rejection{Line: pr.line, Reason: pr.err.Error()})
+			return false
+		}
+		return true
+	})
+	priced := Map(p, valid, func(_ context.Context, pr parsedRow) (Product, error) {
+		prod := pr.product
+		prod.Price = math.Round(prod.Cost*(1+markup)*100) / 100
+		return prod, nil
+	})
+	Sink(p, priced, func(ctx context.Context, prod Product) error {
+		if err := store(ctx, prod); err != nil {
+			return fmt.Errorf("store %s: %w", prod.SKU, err)
+		}
+		report.Stored++
+		return nil
+	})
+
+	// report is written by single stages and read only after Wait, which
+	// orders those writes before this return.
+	err := p.Wait()
+	return report, err
+}
+
+type parsedRow struct {
+	line    int
+	product Product
+	err     error
+}
+
+var errValidation = errors.New("invalid row")
+
+func parse(row csvRow) parsedRow {
+	out := parsedRow{line: row.line}
+	if len(row.fields) != 3 {
+		out.err = fmt.Errorf("%w: want 3 fields, got %d", errValidation, len(row.fields))
+		return out
+	}
+	sku, name := strings.TrimSpace(row.fields[0]), strings.TrimSpace(row.fields[1])
+	cost, err := strconv.ParseFloat(strings.TrimSpace(row.fields[2]), 64)
+	switch {
+	case sku == "":
+		out.err = fmt.Errorf("%w: empty sku", errValidation)
+	case name == "":
+		out.err = fmt.Errorf("%w: empty name", errValidation)
+	case err != nil:
+		out.err = fmt.Errorf("%w: cost %q is not a number", errValidation, row.fields[2])
+	case cost < 0:
+		out.err = fmt.Errorf("%w: negative cost", errValidation)
+	default:
+		out.product = Product{SKU: sku, Name: name, Cost: cost}
+	}
+	return out
+}
+
+func readCSV(r io.Reader) iter.Seq2[csvRow, error] {
+	return func(yield func(csvRow, error) bool) {
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1 // field count is checked by validation
+		if _, err := cr.Read(); err != nil {
+			if err != io.EOF {
+				yield(csvRow{}, fmt.Errorf("read header: %w", err))
+			}
+			return
+		}
+		for {
+			fields, err := cr.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(csvRow{}, err)
+				return
+			}
+			line, _ := cr.FieldPos(0)
+			if !yield(csvRow{line: line, fields: fields}, nil) {
+				return
+			}
+		}
+	}
+}
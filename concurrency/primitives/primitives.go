@@ -0,0 +1,79 @@
+// Package primitives shows each of the sync and sync/atomic building
+// blocks doing the one job it is best at.
+//
+// Choosing between them:
+//
+//   - sync.WaitGroup: wait for a known set of goroutines to finish. It
+//     carries no results or errors; pair it with a slice indexed per
+//     goroutine, or use errgroup when failures matter.
+//   - sync.Once: run initialisation exactly once, lazily, however many
+//     goroutines race to trigger it. sync.OnceValue is the shorthand when
+//     the result is a single value.
+//   - sync.Cond: wait for a condition on state already guarded by a
+//     mutex, e.g. "queue not full". Reach for a channel first; Cond earns
+//     its place when several conditions share one lock or when waiters
+//     must be woken by Broadcast.
+//   - sync/atomic: single-word state updated without a lock, such as
+//     counters and high-water marks. Anything spanning two fields needs a
+//     mutex.
+package primitives
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ForEach calls f for every item on its own goroutine and returns when all
+// calls have returned. f must be safe to call concurrently.
+func ForEach[T any](items []T, f func(int, T)) {
+	var wg sync.WaitGroup
+	wg.Add(len(items))
+	for i, it := range items {
+		go func() {
+			defer wg.Done()
+			f(i, it)
+		}()
+	}
+	wg.Wait()
+}
+
+// Lazy holds a value built on first use. Concurrent first calls to Get
+// block until the single call to New has returned.
+type Lazy[T any] struct {
+	New func() T
+
+	once sync.Once
+	v    T
+}
+
+// Get returns the value, building it on the first call.
+func (l *Lazy[T]) Get() T {
+	l.once.Do(func() { l.v = l.New() })
+	return l.v
+}
+
+// Gauge tracks a current level and the highest level it has reached,
+// such as requests in flight. All methods are lock-free.
+type Gauge struct {
+	cur  atomic.Int64
+	peak atomic.Int64
+}
+
+// Add moves the current level by delta and returns the new level.
+func (g *Gauge) Add(delta int64) int64 {
+	n := g.cur.Add(delta)
+	// Raise peak with compare-and-swap: retry only while another goroutine
+	// raced us and the stored peak is still below n.
+	for {
+		p := g.peak.Load()
+		if n <= p || g.peak.CompareAndSwap(p, n) {
+			return n
+		}
+	}
+}
+
+// Current returns the current level.
+func (g *Gauge) Current() int64 { return g.cur.Load() }
+
+// Peak returns the highest level seen so far.
+func (g *Gauge) Peak() int64 { return g.peak.Load() }
@@ -0,0 +1,160 @@
+package primitives
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEach(t *testing.T) {
+	in := []int{1, 2, 3, 4, 5}
+	out := make([]int, len(in))
+	ForEach(in, func(i, v int) { out[i] = v * v })
+	if !slices.Equal(out, []int{1, 4, 9, 16, 25}) {
+		t.Fatalf("out = %v", out)
+	}
+}
+
+type service struct{ name string }
+
+func TestLazyBuildsOnce(t *testing.T) {
+	var built atomic.Int32
+	l := &Lazy[*service]{New: func() *service {
+		built.Add(1)
+		time.Sleep(time.Millisecond) // widen the race window
+		return &service{name: "products"}
+	}}
+
+	got := make([]*service, 50)
+	ForEach(got, func(i int, _ *service) { got[i] = l.Get() })
+
+	if n := built.Load(); n != 1 {
+		t.Fatalf("New called %d times, want 1", n)
+	}
+	for _, s := range got {
+		if s != got[0] {
+			t.Fatal("Get returned different instances")
+		}
+	}
+}
+
+func TestGauge(t *testing.T) {
+	var g Gauge
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.Add(1)
+			time.Sleep(time.Millisecond)
+			g.Add(-1)
+		}()
+	}
+	wg.Wait()
+	if g.Current() != 0 {
+		t.Fatalf("Current() = %d, want 0", g.Current())
+	}
+	if p := g.Peak(); p < 1 || p > 20 {
+		t.Fatalf("Peak() = %d, want 1..20", p)
+	}
+}
+
+func TestQueueFIFO(t *testing.T) {
+	q := NewQueue[int](3)
+	for i := range 3 {
+		q.Put(i)
+	}
+	for want := range 3 {
+		if v, ok := q.Take(); !ok || v != want {
+			t.Fatalf("Take() = %d, %t, want %d", v, ok, want)
+		}
+	}
+}
+
+func TestQueuePutBlocksWhenFull(t *testing.T) {
+	q := NewQueue[int](1)
+	q.Put(1)
+
+	done := make(chan struct{})
+	go func() {
+		q.Put(2)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Put on full queue did not block")
+	case <-time.After(10 * time.Millisecond):
+	}
+	q.Take()
+	<-done
+	if v, _ := q.Take(); v != 2 {
+		t.Fatalf("Take() = %d, want 2", v)
+	}
+}
+
+func TestQueueBoundUnderLoad(t *testing.T) {
+	const capacity = 4
+	q := NewQueue[int](capacity)
+	var wg sync.WaitGroup
+	for p := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				q.Put(p*100 + i)
+				if n := q.Len(); n > capacity {
+					t.Errorf("Len() = %d, exceeds capacity", n)
+				}
+			}
+		}()
+	}
+	var got []int
+	var consumer sync.WaitGroup
+	consumer.Add(1)
+	go func() {
+		defer consumer.Done()
+		for {
+			v, ok := q.Take()
+			if !ok {
+				return
+			}
+			got = append(got, v)
+		}
+	}()
+	wg.Wait()
+	q.Close()
+	consumer.Wait()
+	if len(got) != 400 {
+		t.Fatalf("consumed %d items, want 400", len(got))
+	}
+}
+
+func TestQueueClose(t *testing.T) {
+	q := NewQueue[string](2)
+	q.Put("a")
+
+	blocked := make(chan bool)
+	empty := NewQueue[string](1)
+	go func() {
+		_, ok := empty.Take()
+		blocked <- ok
+	}()
+	time.Sleep(5 * time.Millisecond)
+	empty.Close()
+	if <-blocked {
+		t.Fatal("Take on closed empty queue reported ok")
+	}
+
+	q.Close()
+	if q.Put("b") {
+		t.Fatal("Put after Close succeeded")
+	}
+	if v, ok := q.Take(); !ok || v != "a" {
+		t.Fatalf("Take() = %q, %t; queued items must survive Close", v, ok)
+	}
+	if _, ok := q.Take(); ok {
+		t.Fatal("Take on drained closed queue reported ok")
+	}
+}
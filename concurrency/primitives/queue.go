@@ -0,0 +1,79 @@
+package primitives
+
+import "sync"
+
+// Queue is a FIFO with a fixed capacity built on a mutex and two
+// condition variables: Put waits on notFull and Take waits on notEmpty.
+type Queue[T any] struct {
+	mu       sync.Mutex
+	notFull  sync.Cond
+	notEmpty sync.Cond
+	items    []T
+	cap      int
+	closed   bool
+}
+
+// NewQueue returns an empty queue holding at most capacity items.
+func NewQueue[T any](capacity int) *Queue[T] {
+	if capacity < 1 {
+		panic("primitives: queue capacity must be positive")
+	}
+	q := &Queue[T]{cap: capacity}
+	q.notFull.L = &q.mu
+	q.notEmpty.L = &q.mu
+	return q
+}
+
+// Put appends v, blocking while the queue is full. It reports false if the
+// queue is closed.
+func (q *Queue[T]) Put(v T) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	// Wait can wake spuriously or after another Put took the slot, so the
+	// condition is re-checked in a loop.
+	for len(q.items) == q.cap && !q.closed {
+		q.notFull.Wait()
+	}
+	if q.closed {
+		return false
+	}
+	q.items = append(q.items, v)
+	q.notEmpty.Signal()
+	return true
+}
+
+// Take removes and returns the oldest item, blocking while the queue is
+// empty. Once the queue is closed and drained it reports false.
+func (q *Queue[T]) Take() (v T, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 && !q.closed {
+		q.notEmpty.Wait()
+	}
+	if len(q.items) == 0 {
+		return v, false
+	}
+	v = q.items[0]
+	var zero T
+	q.items[0] = zero
+	q.items = q.items[1:]
+	q.notFull.Signal()
+	return v, true
+}
+
+// Len returns the number of queued items.
+func (q *Queue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Close wakes every blocked caller. Put fails from then on; Take keeps
+// returning queued items until the queue is empty.
+func (q *Queue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.notFull.Broadcast()
+	q.notEmpty.Broadcast()
+}
@@ -0,0 +1,110 @@
+// Package selects holds small, reusable select patterns: receiving with a
+// timeout, detecting a stalled worker through heartbeats, and sending or
+// receiving without blocking.
+package selects
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTimeout is returned when nothing arrives in time.
+var ErrTimeout = errors.New("timed out")
+
+// ErrStalled is returned by Watch when a worker misses its heartbeat.
+var ErrStalled = errors.New("worker stalled")
+
+// RecvTimeout receives one value from ch, giving up after d. It uses
+// time.After, which is the simplest form and fine for one-off waits: the
+// timer is garbage collected once it fires or becomes unreachable.
+//
+// ok is false if ch was closed.
+func RecvTimeout[T any](ch <-chan T, d time.Duration) (v T, ok bool, err error) {
+	select {
+	case v, ok = <-ch:
+		return v, ok, nil
+	case <-time.After(d):
+		return v, false, ErrTimeout
+	}
+}
+
+// Drain receives from ch until it is closed or idle elapses without a new
+// value. Calling time.After in the loop would allocate a timer per value;
+// Drain reuses one Timer and resets it after every receive. Since Go 1.23
+// Reset discards any stale tick, so no manual drain of t.C is needed.
+func Drain[T any](ch <-chan T, idle time.Duration) []T {
+	var out []T
+	t := time.NewTimer(idle)
+	defer t.Stop()
+	for {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return out
+			}
+			out = append(out, v)
+			t.Reset(idle)
+		case <-t.C:
+			return out
+		}
+	}
+}
+
+// TrySend sends v on ch only if that can happen immediately.
+func TrySend[T any](ch chan<- T, v T) bool {
+	select {
+	case ch <- v:
+		return true
+	default:
+		return false
+	}
+}
+
+// TryRecv receives from ch only if a value is ready. ok is false if
+// nothing was ready or ch is closed.
+func TryRecv[T any](ch <-chan T) (v T, ok bool) {
+	select {
+	case v, ok = <-ch:
+		return v, ok
+	default:
+		return v, false
+	}
+}
+
+// Heartbeat runs step repeatedly until ctx is done and signals on the
+// returned channel after each completed step. The channel has room for
+// one beat and beats are sent with TrySend, so a slow watcher never
+// holds up the worker. The channel is closed when the worker exits.
+func Heartbeat(ctx context.Context, step func(context.Context)) <-chan struct{} {
+	beats := make(chan struct{}, 1)
+	go func() {
+		defer close(beats)
+		for ctx.Err() == nil {
+			step(ctx)
+			TrySend(beats, struct{}{})
+		}
+	}()
+	return beats
+}
+
+// Watch consumes beats and returns ErrStalled if no beat arrives within
+// timeout. It returns nil when beats is closed and ctx.Err() when ctx is
+// done first.
+func Watch(ctx context.Context, beats <-chan struct{}, timeout time.Duration) error {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	for {
+		select {
+		case _, ok := <-beats:
+			if !ok {
+				return nil
+			}
+			t.Reset(timeout)
+		case <-t.C:
+			return ErrStalled
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
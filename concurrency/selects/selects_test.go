@@ -0,0 +1,121 @@
+package selects
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecvTimeout(t *testing.T) {
+	ch := make(chan int, 1)
+	ch <- 7
+	if v, ok, err := RecvTimeout(ch, time.Second); v != 7 || !ok || err != nil {
+		t.Fatalf("RecvTimeout() = %d, %t, %v", v, ok, err)
+	}
+	if _, _, err := RecvTimeout(ch, time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+	close(ch)
+	if _, ok, err := RecvTimeout(ch, time.Second); ok || err != nil {
+		t.Fatalf("closed channel: ok = %t, err = %v", ok, err)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	ch := make(chan int)
+	go func() {
+		for i := range 5 {
+			ch <- i
+		}
+		close(ch)
+	}()
+	if got := Drain(ch, time.Second); !slices.Equal(got, []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("Drain() = %v", got)
+	}
+}
+
+func TestDrainStopsWhenIdle(t *testing.T) {
+	ch := make(chan int)
+	go func() {
+		ch <- 1
+		ch <- 2
+		// Never closed: Drain must return on the idle timeout.
+	}()
+	if got := Drain(ch, 20*time.Millisecond); !slices.Equal(got, []int{1, 2}) {
+		t.Fatalf("Drain() = %v", got)
+	}
+}
+
+func TestTrySendTryRecv(t *testing.T) {
+	ch := make(chan int, 1)
+	if _, ok := TryRecv(ch); ok {
+		t.Fatal("TryRecv on empty channel succeeded")
+	}
+	if !TrySend(ch, 1) {
+		t.Fatal("TrySend into empty buffer failed")
+	}
+	if TrySend(ch, 2) {
+		t.Fatal("TrySend into full buffer succeeded")
+	}
+	if v, ok := TryRecv(ch); !ok || v != 1 {
+		t.Fatalf("TryRecv() = %d, %t", v, ok)
+	}
+}
+
+func TestWatchHealthyWorker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	beats := Heartbeat(ctx, func(context.Context) { time.Sleep(time.Millisecond) })
+
+	time.AfterFunc(30*time.Millisecond, cancel)
+	if err := Watch(context.Background(), beats, 50*time.Millisecond); err != nil {
+		t.Fatalf("Watch() = %v, want nil after worker exits", err)
+	}
+}
+
+func TestWatchDetectsStall(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var n int
+	beats := Heartbeat(ctx, func(ctx context.Context) {
+		n++
+		if n == 3 {
+			<-ctx.Done() // hang until the test gives up
+		}
+	})
+	if err := Watch(ctx, beats, 20*time.Millisecond); !errors.Is(err, ErrStalled) {
+		t.Fatalf("Watch() = %v, want ErrStalled", err)
+	}
+}
+
+func TestWatchContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Watch(ctx, make(chan struct{}), time.Hour); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Watch() = %v, want context.Canceled", err)
+	}
+}
+
+func BenchmarkRecvLoop(b *testing.B) {
+	ch := make(chan int, 1)
+	b.Run("After", func(b *testing.B) {
+		for b.Loop() {
+			ch <- 1
+			RecvTimeout(ch, time.Minute)
+		}
+	})
+	b.Run("Timer", func(b *testing.B) {
+		t := time.NewTimer(time.Minute)
+		defer t.Stop()
+		for b.Loop() {
+			ch <- 1
+			select {
+			case <-ch:
+				t.Reset(time.Minute)
+			case <-t.C:
+			}
+		}
+	})
+}
@@ -0,0 +1,164 @@
+// Package semaphore bounds how many operations run at once.
+//
+// Semaphore is the classic buffered-channel form: every holder owns one
+// slot. Weighted lets holders take several units at a time, e.g. a file
+// snapshot counted by megabytes instead of by file; waiters are served in
+// FIFO order so a large request is not starved by a stream of small ones.
+package semaphore
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// Semaphore allows up to n concurrent holders.
+type Semaphore struct {
+	slots chan struct{}
+}
+
+// New returns a semaphore with n slots.
+func New(n int) *Semaphore {
+	if n < 1 {
+		panic("semaphore: n must be positive")
+	}
+	return &Semaphore{slots: make(chan struct{}, n)}
+}
+
+// Acquire takes a slot, blocking until one is free or ctx is done.
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes a slot only if one is free right now.
+func (s *Semaphore) TryAcquire() bool {
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release returns a slot. Releasing more than was acquired panics.
+func (s *Semaphore) Release() {
+	select {
+	case <-s.slots:
+	default:
+		panic("semaphore: release without acquire")
+	}
+}
+
+// Do runs f while holding a slot.
+func (s *Semaphore) Do(ctx context.Context, f func(context.Context) error) error {
+	if err := s.Acquire(ctx); err != nil {
+		return err
+	}
+	defer s.Release()
+	return f(ctx)
+}
+
+// Weighted is a semaphore over size units where each Acquire takes n.
+type Weighted struct {
+	mu      sync.Mutex
+	size    int64
+	cur     int64
+	waiters list.List // of waiter, oldest first
+}
+
+type waiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// NewWeighted returns a weighted semaphore with size units.
+func NewWeighted(size int64) *Weighted {
+	return &Weighted{size: size}
+}
+
+// Acquire takes n units, blocking until they are free or ctx is done. A
+// request larger than the semaphore can never succeed and blocks until
+// ctx is done.
+func (w *Weighted) Acquire(ctx context.Context, n int64) error {
+	w.mu.Lock()
+	if w.size-w.cur >= n && w.waiters.Len() == 0 {
+		w.cur += n
+		w.mu.Unlock()
+		return nil
+	}
+	if n > w.size {
+		w.mu.Unlock()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ready := make(chan struct{})
+	elem := w.waiters.PushBack(waiter{n: n, ready: ready})
+	w.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		select {
+		case <-ready:
+			// Granted while we were giving up; hand the units back.
+			w.cur -= n
+			w.notify()
+		default:
+			front := w.waiters.Front() == elem
+			w.waiters.Remove(elem)
+			// If we were blocking the queue head, those behind may now fit.
+			if front {
+				w.notify()
+			}
+		}
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes n units only if they are free and nobody is queued.
+func (w *Weighted) TryAcquire(n int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size-w.cur >= n && w.waiters.Len() == 0 {
+		w.cur += n
+		return true
+	}
+	return false
+}
+
+// Release returns n units. Releasing more than is held panics.
+func (w *Weighted) Release(n int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cur -= n
+	if w.cur < 0 {
+		panic("semaphore: released more than held")
+	}
+	w.notify()
+}
+
+// notify wakes queued waiters in order for as long as the head fits.
+func (w *Weighted) notify() {
+	for {
+		front := w.waiters.Front()
+		if front == nil {
+			return
+		}
+		wt := front.Value.(waiter)
+		if w.size-w.cur < wt.n {
+			return
+		}
+		w.cur += wt.n
+		w.waiters.Remove(front)
+		close(wt.ready)
+	}
+}
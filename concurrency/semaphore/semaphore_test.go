@@ -0,0 +1,167 @@
+package semaphore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/concurrency/primitives"
+)
+
+func TestSemaphoreBoundsDeliveries(t *testing.T) {
+	const limit = 3
+	sem := New(limit)
+	var inFlight primitives.Gauge
+
+	deliver := func(context.Context) error {
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+		time.Sleep(time.Millisecond)
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for range 40 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sem.Do(context.Background(), deliver); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if p := inFlight.Peak(); p > limit {
+		t.Fatalf("peak deliveries = %d, want <= %d", p, limit)
+	}
+}
+
+func TestSemaphoreTryAndCancel(t *testing.T) {
+	sem := New(1)
+	if !sem.TryAcquire() {
+		t.Fatal("TryAcquire on free semaphore failed")
+	}
+	if sem.TryAcquire() {
+		t.Fatal("TryAcquire on full semaphore succeeded")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := sem.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire() = %v, want DeadlineExceeded", err)
+	}
+
+	sem.Release()
+	if !sem.TryAcquire() {
+		t.Fatal("slot not returned by Release")
+	}
+}
+
+func TestSemaphoreReleaseWithoutAcquirePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Release on empty semaphore did not panic")
+		}
+	}()
+	New(1).Release()
+}
+
+func TestWeightedBoundsSnapshotBytes(t *testing.T) {
+	// Snapshots are weighted by size in MB; no more than 10 MB may be
+	// copied at once.
+	const budget = 10
+	sem := NewWeighted(budget)
+	var inFlight primitives.Gauge
+
+	sizes := []int64{4, 1, 7, 2, 10, 3, 3, 5, 1, 8, 6, 2}
+	var wg sync.WaitGroup
+	for _, mb := range sizes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sem.Acquire(context.Background(), mb); err != nil {
+				t.Error(err)
+				return
+			}
+			inFlight.Add(mb)
+			time.Sleep(time.Millisecond)
+			inFlight.Add(-mb)
+			sem.Release(mb)
+		}()
+	}
+	wg.Wait()
+	if p := inFlight.Peak(); p > budget {
+		t.Fatalf("peak MB in flight = %d, want <= %d", p, budget)
+	}
+}
+
+func TestWeightedFIFO(t *testing.T) {
+	sem := NewWeighted(4)
+	sem.Acquire(context.Background(), 3)
+
+	big := make(chan struct{})
+	go func() {
+		sem.Acquire(context.Background(), 4)
+		close(big)
+	}()
+	waitQueued(t, sem, 1)
+
+	// One unit is free, but the queued 4-unit request is first in line.
+	if sem.TryAcquire(1) {
+		t.Fatal("TryAcquire jumped the queue")
+	}
+	sem.Release(3)
+	<-big
+}
+
+func TestWeightedCancelUnblocksQueue(t *testing.T) {
+	sem := NewWeighted(4)
+	sem.Acquire(context.Background(), 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() { errc <- sem.Acquire(ctx, 4) }()
+	waitQueued(t, sem, 1)
+
+	small := make(chan struct{})
+	go func() {
+		sem.Acquire(context.Background(), 2)
+		close(small)
+	}()
+	waitQueued(t, sem, 2)
+
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire() = %v, want Canceled", err)
+	}
+	select {
+	case <-small:
+	case <-time.After(time.Second):
+		t.Fatal("waiter behind cancelled head was not woken")
+	}
+}
+
+func TestWeightedOversized(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := NewWeighted(2).Acquire(ctx, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire() = %v, want DeadlineExceeded", err)
+	}
+}
+
+func waitQueued(t *testing.T, w *Weighted, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		w.mu.Lock()
+		got := w.waiters.Len()
+		w.mu.Unlock()
+		if got == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("waiters never reached %d", n)
+}
@@ -0,0 +1,127 @@
+// Package workerpool runs jobs on a fixed number of goroutines.
+//
+// The pool owns three pieces of state: a jobs channel feeding the workers,
+// a results channel they publish to, and a stopped flag guarding
+// Submit. Stop closes them in dependency order — no new submissions, then
+// no more jobs, then (once every worker has returned) no more results —
+// so a consumer can simply range over Results until it is closed.
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/stawuah/pounce-on-go/panics"
+)
+
+// ErrStopped is returned by Submit after Stop has been called.
+var ErrStopped = errors.New("workerpool: pool stopped")
+
+// Result is the outcome of one job. If the job panicked, Err is a
+// *panics.Error.
+type Result[J, R any] struct {
+	Job   J
+	Value R
+	Err   error
+}
+
+// Pool processes jobs of type J into results of type R.
+type Pool[J, R any] struct {
+	work    func(context.Context, J) (R, error)
+	ctx     context.Context
+	cancel  context.CancelFunc
+	jobs    chan J
+	results chan Result[J, R]
+
+	mu         sync.Mutex
+	stopped    bool
+	submitters sync.WaitGroup // Submit calls that passed the stopped check
+	workers    sync.WaitGroup
+	stopOnce   sync.Once
+}
+
+// New starts size workers running work. queue is the number of jobs that
+// can wait before Submit blocks. The context passed to work is cancelled by
+// Abort; Stop lets queued jobs finish first.
+func New[J, R any](size, queue int, work func(context.Context, J) (R, error)) *Pool[J, R] {
+	size = max(size, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool[J, R]{
+		work:    work,
+		ctx:     ctx,
+		cancel:  cancel,
+		jobs:    make(chan J, max(queue, 0)),
+		results: make(chan Result[J, R], size),
+	}
+	p.workers.Add(size)
+	for range size {
+		go p.worker()
+	}
+	return p
+}
+
+func (p *Pool[J, R]) worker() {
+	defer p.workers.Done()
+	for job := range p.jobs {
+		// A panicking job must not take the worker, and with it the
+		// process, down; it becomes that job's error instead.
+		var v R
+		err := panics.Try(func() (err error) {
+			v, err = p.work(p.ctx, job)
+			return err
+		})
+		p.results <- Result[J, R]{Job: job, Value: v, Err: err}
+	}
+}
+
+// Submit queues job, waiting for space if the queue is full. It returns
+// ErrStopped once Stop has been called, or ctx.Err() if ctx ends first.
+func (p *Pool[J, R]) Submit(ctx context.Context, job J) error {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return ErrStopped
+	}
+	p.submitters.Add(1)
+	p.mu.Unlock()
+	defer p.submitters.Done()
+
+	select {
+	case p.jobs <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Results delivers one Result per accepted job and is closed after Stop
+// once every job has finished. It must be drained: workers block when it
+// is full.
+func (p *Pool[J, R]) Results() <-chan Result[J, R] { return p.results }
+
+// Stop rejects further submissions, lets queued and running jobs finish,
+// and closes Results. It returns without waiting; drain Results to know
+// when the work is done. Calling Stop more than once is safe.
+func (p *Pool[J, R]) Stop() {
+	p.stopOnce.Do(func() {
+		p.mu.Lock()
+		p.stopped = true
+		p.mu.Unlock()
+
+		go func() {
+			p.submitters.Wait() // nobody can still be sending on jobs
+			close(p.jobs)
+			p.workers.Wait()
+			close(p.results)
+			p.cancel()
+		}()
+	})
+}
+
+// Abort is Stop plus cancellation of the context passed to running and
+// queued jobs, for shutting down without waiting on slow work.
+func (p *Pool[J, R]) Abort() {
+	p.cancel()
+	p.Stop()
+}
@@ -0,0 +1,166 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/concurrency/leaktest"
+	"github.com/stawuah/pounce-on-go/panics"
+)
+
+func TestAllJobsComplete(t *testing.T) {
+	leaktest.Check(t)
+
+	p := New(4, 8, func(_ context.Context, n int) (int, error) { return n * n, nil })
+	const jobs = 100
+	go func() {
+		for i := range jobs {
+			if err := p.Submit(context.Background(), i); err != nil {
+				t.Error(err)
+			}
+		}
+		p.Stop()
+	}()
+
+	seen := make(map[int]bool)
+	for r := range p.Results() {
+		if r.Err != nil || r.Value != r.Job*r.Job {
+			t.Fatalf("bad result %+v", r)
+		}
+		seen[r.Job] = true
+	}
+	if len(seen) != jobs {
+		t.Fatalf("got %d distinct results, want %d", len(seen), jobs)
+	}
+}
+
+func TestSubmitAfterStop(t *testing.T) {
+	p := New(1, 0, func(context.Context, int) (int, error) { return 0, nil })
+	p.Stop()
+	p.Stop() // idempotent
+	if err := p.Submit(context.Background(), 1); !errors.Is(err, ErrStopped) {
+		t.Fatalf("Submit after Stop = %v, want ErrStopped", err)
+	}
+	for range p.Results() {
+	}
+}
+
+func TestSubmitHonoursContext(t *testing.T) {
+	block := make(chan struct{})
+	p := New(1, 0, func(context.Context, int) (int, error) { <-block; return 0, nil })
+	defer func() {
+		close(block)
+		p.Stop()
+		for range p.Results() {
+		}
+	}()
+
+	p.Submit(context.Background(), 1) // occupies the only worker
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Submit(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Submit on a saturated pool = %v, want deadline exceeded", err)
+	}
+}
+
+func TestAbortCancelsWork(t *testing.T) {
+	leaktest.Check(t)
+	var cancelled atomic.Int32
+	p := New(2, 0, func(ctx context.Context, _ int) (int, error) {
+		<-ctx.Done()
+		cancelled.Add(1)
+		return 0, ctx.Err()
+	})
+	p.Submit(context.Background(), 1)
+	p.Submit(context.Background(), 2)
+	p.Abort()
+
+	for r := range p.Results() {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Fatalf("result error = %v", r.Err)
+		}
+	}
+	if cancelled.Load() != 2 {
+		t.Fatalf("cancelled = %d, want 2", cancelled.Load())
+	}
+}
+
+// TestBulkImport is the shape of a bulk import: parse CSV-ish lines in
+// parallel, collect failures per line rather than aborting the batch.
+func TestBulkImport(t *testing.T) {
+	leaktest.Check(t)
+	type row struct {
+		line int
+		text string
+	}
+	type product struct {
+		name  string
+		price float64
+	}
+	parse := func(_ context.Context, r row) (product, error) {
+		name, price, ok := strings.Cut(r.text, ",")
+		if !ok {
+			return product{}, fmt.Errorf("line %d: missing price", r.line)
+		}
+		v, err := strconv.ParseFloat(price, 64)
+		if err != nil {
+			return product{}, fmt.Errorf("line %d: %w", r.line, err)
+		}
+		return product{name, v}, nil
+	}
+
+	input := []string{"apple,0.5", "pear,0.75", "broken", "melon,x", "kiwi,1"}
+	p := New(3, len(input), parse)
+	for i, text := range input {
+		p.Submit(context.Background(), row{i + 1, text})
+	}
+	p.Stop()
+
+	var imported, failed int
+	for r := range p.Results() {
+		if r.Err != nil {
+			failed++
+			continue
+		}
+		imported++
+	}
+	if imported != 3 || failed != 2 {
+		t.Fatalf("imported %d, failed %d; want 3 and 2", imported, failed)
+	}
+}
+
+func TestPanicBecomesResultError(t *testing.T) {
+	leaktest.Check(t)
+	p := New(2, 4, func(_ context.Context, n int) (int, error) {
+		if n == 3 {
+			panic("bad job")
+		}
+		return n, nil
+	})
+	for i := range 5 {
+		p.Submit(context.Background(), i)
+	}
+	p.Stop()
+
+	var ok, panicked int
+	for r := range p.Results() {
+		var pe *panics.Error
+		switch {
+		case r.Err == nil:
+			ok++
+		case errors.As(r.Err, &pe) && r.Job == 3 && pe.Value == "bad job":
+			panicked++
+		default:
+			t.Fatalf("unexpected result %+v", r)
+		}
+	}
+	if ok != 4 || panicked != 1 {
+		t.Fatalf("ok = %d, panicked = %d; want 4 and 1", ok, panicked)
+	}
+}
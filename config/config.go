@@ -0,0 +1,365 @@
+// Package config fills a program's settings struct from layered sources:
+// the defaults already in the struct, then a JSON file, then environment
+// variables, then command-line flags, each overriding the one before.
+//
+// Fields take part through a config tag naming the setting:
+//
+//	type Config struct {
+//		Addr    string        `config:"addr" usage:"HTTP listen address"`
+//		Timeout time.Duration `config:"timeout"`
+//		Token   config.Secret `config:"token,required,noflag"`
+//	}
+//
+// The name is the file's JSON key and the flag's name. Upper-cased, with
+// dashes turned into underscores and a prefix in front, it is also the
+// environment variable: POUNCE_TOKEN. After the name, required rejects a
+// setting still zero once every source is applied, and noflag keeps a
+// setting off the command line. Secrets belong there, since flags show up
+// in process listings.
+//
+// A Secret prints and encodes as [redacted], so a settings struct can be
+// logged whole. If the struct has a Validate() error method, Load calls
+// it last.
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/reflectutil"
+)
+
+var (
+	// ErrInvalid is wrapped by errors for values that do not parse as
+	// their field's type, and for unknown keys in a file.
+	ErrInvalid = errors.New("config: invalid setting")
+	// ErrRequired is wrapped by errors for required settings no source
+	// supplied.
+	ErrRequired = errors.New("config: missing required setting")
+)
+
+// Secret is a string, such as a password or key, that must not be
+// printed. String, GoString and the marshal methods all give [redacted];
+// Value gives the secret itself.
+type Secret string
+
+const redacted = "[redacted]"
+
+// Value returns the secret.
+func (s Secret) Value() string { return string(s) }
+
+// String returns [redacted], or "" for an empty Secret, so a log still
+// shows whether one was set.
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString redacts %#v too.
+func (s Secret) GoString() string { return strconv.Quote(s.String()) }
+
+// MarshalText redacts Secret in JSON and other text encodings.
+func (s Secret) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// field is one tagged setting of the struct being loaded.
+type field struct {
+	name     string
+	usage    string
+	required bool
+	noflag   bool
+	v        reflect.Value
+}
+
+func fields(cfg any) ([]field, error) {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: want a pointer to a struct, got %T", cfg)
+	}
+	var out []field
+	for _, f := range reflectutil.FieldsWithTag(rv.Type(), "config") {
+		sf := rv.Elem().Type().FieldByIndex(f.Index)
+		out = append(out, field{
+			name:     f.Tag,
+			usage:    sf.Tag.Get("usage"),
+			required: slices.Contains(f.Opts, "required"),
+			noflag:   slices.Contains(f.Opts, "noflag"),
+			v:        rv.Elem().FieldByIndex(f.Index),
+		})
+	}
+	return out, nil
+}
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// set parses s into v according to v's type.
+func set(v reflect.Value, s string) error {
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// A Source supplies setting values as strings.
+type Source struct {
+	// values returns the values it has for the named settings.
+	values func(names []string) (map[string]string, error)
+	// where describes a setting's origin for error messages.
+	where func(name string) string
+}
+
+// File reads settings from a JSON object. Strings are parsed like any
+// other source's values, so durations are written "30s"; numbers and
+// booleans may be bare. An empty path is a source with nothing in it, so
+// a -config flag can default to "". Keys that name no setting are errors,
+// to catch typos.
+func File(path string) Source {
+	return Source{
+		values: func(names []string) (map[string]string, error) {
+			if path == "" {
+				return nil, nil
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("config: %w", err)
+			}
+			var raw map[string]json.RawMessage
+			if err := json.Unmarshal(b, &raw); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalid, path, err)
+			}
+			vals := make(map[string]string, len(raw))
+			for k, r := range raw {
+				if !slices.Contains(names, k) {
+					return nil, fmt.Errorf("%w: %s: unknown key %q", ErrInvalid, path, k)
+				}
+				var s string
+				if json.Unmarshal(r, &s) != nil {
+					s = string(r)
+				}
+				vals[k] = s
+			}
+			return vals, nil
+		},
+		where: func(name string) string { return path + ": " + name },
+	}
+}
+
+// Env reads each setting from the variable EnvName(prefix, name). Empty
+// variables count as unset. getenv is os.Getenv outside tests.
+func Env(prefix string, getenv func(string) string) Source {
+	return Source{
+		values: func(names []string) (map[string]string, error) {
+			vals := make(map[string]string)
+			for _, name := range names {
+				if v := getenv(EnvName(prefix, name)); v != "" {
+					vals[name] = v
+				}
+			}
+			return vals, nil
+		},
+		where: func(name string) string { return "$" + EnvName(prefix, name) },
+	}
+}
+
+// EnvName returns the variable Env reads a setting from.
+func EnvName(prefix, name string) string {
+	name = strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// Flags supplies the flags set on fs's command line. The flags must have
+// been defined by RegisterFlags and fs parsed; a flag left at its default
+// does not override the sources before it.
+func Flags(fs *flag.FlagSet) Source {
+	return Source{
+		values: func(names []string) (map[string]string, error) {
+			vals := make(map[string]string)
+			fs.Visit(func(f *flag.Flag) {
+				if slices.Contains(names, f.Name) {
+					vals[f.Name] = f.Value.String()
+				}
+			})
+			return vals, nil
+		},
+		where: func(name string) string { return "-" + name },
+	}
+}
+
+// RegisterFlags defines a flag on fs for every setting of cfg, a pointer
+// to a struct, except those tagged noflag. The flags show cfg's current
+// values as their defaults and their usage tags as help.
+//
+// The flags write to variables of their own, not to cfg: Flags copies
+// the ones given on the command line when Load runs, so they override the
+// file and environment whichever order things happen in.
+func RegisterFlags(fs *flag.FlagSet, cfg any) {
+	fields, err := fields(cfg)
+	if err != nil {
+		panic(err)
+	}
+	for _, f := range fields {
+		if f.noflag {
+			continue
+		}
+		switch v := f.v; {
+		case reflect.PointerTo(v.Type()).Implements(textUnmarshalerType):
+			tf := &textFlag{typ: v.Type()}
+			if !v.IsZero() {
+				tf.s = fmt.Sprint(v.Interface())
+			}
+			fs.Var(tf, f.name, f.usage)
+		case v.Type() == durationType:
+			fs.Duration(f.name, time.Duration(v.Int()), f.usage)
+		case v.Kind() == reflect.String:
+			fs.String(f.name, fmt.Sprint(v.Interface()), f.usage)
+		case v.Kind() == reflect.Bool:
+			fs.Bool(f.name, v.Bool(), f.usage)
+		case v.CanInt():
+			fs.Int64(f.name, v.Int(), f.usage)
+		case v.CanUint():
+			fs.Uint64(f.name, v.Uint(), f.usage)
+		case v.CanFloat():
+			fs.Float64(f.name, v.Float(), f.usage)
+		default:
+			fs.Var(&textFlag{typ: v.Type()}, f.name, f.usage)
+		}
+	}
+}
+
+// textFlag is the flag for settings of other types, such as
+// encoding.TextUnmarshalers. It checks its text parses and keeps it.
+type textFlag struct {
+	typ reflect.Type
+	s   string
+}
+
+func (f *textFlag) String() string { return f.s }
+
+func (f *textFlag) Set(s string) error {
+	if err := set(reflect.New(f.typ).Elem(), s); err != nil {
+		return err
+	}
+	f.s = s
+	return nil
+}
+
+// Load applies sources to cfg, a pointer to a struct holding the
+// defaults, in order, so later sources win. It then checks required
+// settings and calls cfg's Validate method, if it has one. Every problem
+// found is joined into the error.
+func Load(cfg any, sources ...Source) error {
+	fields, err := fields(cfg)
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+
+	var errs []error
+	for _, src := range sources {
+		vals, err := src.values(names)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, f := range fields {
+			s, ok := vals[f.name]
+			if !ok {
+				continue
+			}
+			if err := set(f.v, s); err != nil {
+				errs = append(errs, fmt.Errorf("%w: %s: %v", ErrInvalid, src.where(f.name), err))
+			}
+		}
+	}
+	for _, f := range fields {
+		if f.required && f.v.IsZero() {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrRequired, f.name))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if v, ok := cfg.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// String formats cfg's settings as name=value pairs in declaration order,
+// for logging the configuration a program started with. Secrets are
+// redacted.
+func String(cfg any) string {
+	fields, err := fields(cfg)
+	if err != nil {
+		return err.Error()
+	}
+	var b strings.Builder
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		v := fmt.Sprint(f.v.Interface())
+		if v == "" || strings.ContainsAny(v, " \t\"=") {
+			v = strconv.Quote(v)
+		}
+		fmt.Fprintf(&b, "%s=%s", f.name, v)
+	}
+	return b.String()
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type settings struct {
+	Addr    string        `config:"addr" usage:"listen address"`
+	Port    int           `config:"port"`
+	Timeout time.Duration `config:"timeout"`
+	Debug   bool          `config:"debug"`
+	Ratio   float64       `config:"ratio"`
+	Token   Secret        `config:"token,required,noflag"`
+	Ignored string
+}
+
+func (s settings) Validate() error {
+	if s.Port < 0 || s.Port > 65535 {
+		return fmt.Errorf("port %d out of range", s.Port)
+	}
+	return nil
+}
+
+func defaults() settings {
+	return settings{Addr: ":8080", Port: 80, Timeout: 10 * time.Second, Ratio: 0.5}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLayering(t *testing.T) {
+	file := writeFile(t, `{"addr": ":9000", "port": 9000, "timeout": "1m", "token": "from-file"}`)
+	env := map[string]string{"APP_PORT": "9100", "APP_DEBUG": "true", "APP_TOKEN": ""}
+
+	cfg := defaults()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	RegisterFlags(fs, &cfg)
+	if err := fs.Parse([]string{"-port", "9200", "-debug=false"}); err != nil {
+		t.Fatal(err)
+	}
+	err := Load(&cfg, File(file), Env("APP", func(k string) string { return env[k] }), Flags(fs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := settings{
+		Addr:    ":9000",     // file over default
+		Port:    9200,        // flag over env over file
+		Timeout: time.Minute, // file
+		Debug:   false,       // flag over env
+		Ratio:   0.5,         // default; nothing set it
+		Token:   "from-file", // an empty variable counts as unset
+	}
+	if cfg != want {
+		t.Fatalf("cfg = %+v\nwant  %+v", cfg, want)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	cfg := defaults()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	RegisterFlags(fs, &cfg)
+
+	if fs.Lookup("token") != nil {
+		t.Fatal("noflag setting registered as a flag")
+	}
+	if f := fs.Lookup("addr"); f == nil || f.DefValue != ":8080" || f.Usage != "listen address" {
+		t.Fatalf("addr flag = %+v", f)
+	}
+	if err := fs.Parse([]string{"-port", "eighty"}); err == nil {
+		t.Fatal("bad -port parsed")
+	}
+	// Parsing alone changes nothing; Load applies the flags.
+	if err := fs.Parse([]string{"-debug", "-timeout", "2s"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Debug {
+		t.Fatal("flag applied before Load")
+	}
+	cfg.Token = "t"
+	if err := Load(&cfg, Flags(fs)); err != nil || !cfg.Debug || cfg.Timeout != 2*time.Second {
+		t.Fatalf("Load = %v, cfg %+v", err, cfg)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	getenv := func(vars map[string]string) func(string) string {
+		return func(k string) string { return vars[k] }
+	}
+	tests := []struct {
+		name    string
+		sources []Source
+		want    []error
+		wantMsg []string
+	}{
+		{
+			name:    "missing required",
+			want:    []error{ErrRequired},
+			wantMsg: []string{"token"},
+		},
+		{
+			name:    "every bad value reported",
+			sources: []Source{Env("APP", getenv(map[string]string{"APP_TOKEN": "t", "APP_PORT": "x", "APP_TIMEOUT": "soon"}))},
+			want:    []error{ErrInvalid},
+			wantMsg: []string{"$APP_PORT", "$APP_TIMEOUT"},
+		},
+		{
+			name:    "unknown file key",
+			sources: []Source{File(writeFile(t, `{"token": "t", "prot": 1}`))},
+			want:    []error{ErrInvalid},
+			wantMsg: []string{`unknown key "prot"`},
+		},
+		{
+			name:    "malformed file",
+			sources: []Source{File(writeFile(t, `addr = ":80"`))},
+			want:    []error{ErrInvalid, ErrRequired},
+		},
+		{
+			name:    "missing file",
+			sources: []Source{File(filepath.Join(t.TempDir(), "nope.json")), Env("APP", getenv(map[string]string{"APP_TOKEN": "t"}))},
+			want:    []error{os.ErrNotExist},
+		},
+		{
+			name:    "Validate",
+			sources: []Source{Env("APP", getenv(map[string]string{"APP_TOKEN": "t", "APP_PORT": "70000"}))},
+			wantMsg: []string{"port 70000 out of range"},
+		},
+	}
+	for _, tt := range tests {
+		cfg := defaults()
+		err := Load(&cfg, tt.sources...)
+		if err == nil {
+			t.Errorf("%s: Load succeeded", tt.name)
+			continue
+		}
+		for _, want := range tt.want {
+			if !errors.Is(err, want) {
+				t.Errorf("%s: err = %v, want %v", tt.name, err, want)
+			}
+		}
+		for _, msg := range tt.wantMsg {
+			if !strings.Contains(err.Error(), msg) {
+				t.Errorf("%s: err = %v, want it to mention %s", tt.name, err, msg)
+			}
+		}
+	}
+
+	if err := Load(settings{}); err == nil {
+		t.Fatal("Load accepted a struct value")
+	}
+	if err := Load(&cfgWithMap{}, File(writeFile(t, `{"tags": "a"}`))); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("unsupported type: err = %v", err)
+	}
+}
+
+func TestTextUnmarshalerFlag(t *testing.T) {
+	var cfg struct {
+		Peer netip.Addr `config:"peer"`
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	RegisterFlags(fs, &cfg)
+	if err := fs.Parse([]string{"-peer", "10.0.0.300"}); err == nil {
+		t.Fatal("bad -peer parsed")
+	}
+	if err := fs.Parse([]string{"-peer", "10.0.0.3"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load(&cfg, Flags(fs)); err != nil || cfg.Peer != netip.MustParseAddr("10.0.0.3") {
+		t.Fatalf("Load = %v, peer %v", err, cfg.Peer)
+	}
+}
+
+type cfgWithMap struct {
+	Tags map[string]string `config:"tags"`
+}
+
+func TestEmptyFileSource(t *testing.T) {
+	cfg := defaults()
+	cfg.Token = "t"
+	if err := Load(&cfg, File("")); err != nil || cfg.Addr != ":8080" {
+		t.Fatalf("Load = %v, cfg %+v", err, cfg)
+	}
+}
+
+func TestSecretRedacted(t *testing.T) {
+	cfg := defaults()
+	cfg.Token = "hunter2"
+	js, _ := json.Marshal(cfg)
+	for _, s := range []string{
+		fmt.Sprint(cfg.Token),
+		fmt.Sprintf("%v %+v %#v %s %q", cfg, cfg, cfg, cfg.Token, cfg.Token),
+		string(js),
+		String(&cfg),
+	} {
+		if strings.Contains(s, "hunter2") {
+			t.Errorf("secret leaked: %s", s)
+		}
+		if !strings.Contains(s, "[redacted]") {
+			t.Errorf("no [redacted] marker: %s", s)
+		}
+	}
+	if cfg.Token.Value() != "hunter2" {
+		t.Fatalf("Value = %q", cfg.Token.Value())
+	}
+	if Secret("").String() != "" {
+		t.Fatal("empty secret should print empty")
+	}
+}
+
+func TestString(t *testing.T) {
+	cfg := defaults()
+	cfg.Token = "t"
+	want := `addr=:8080 port=80 timeout=10s debug=false ratio=0.5 token=[redacted]`
+	if got := String(&cfg); got != want {
+		t.Fatalf("String = %s\nwant     %s", got, want)
+	}
+	cfg.Addr = ""
+	if got := String(&cfg); !strings.HasPrefix(got, `addr="" `) {
+		t.Fatalf("String = %s, want an empty addr quoted", got)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	for _, tt := range []struct{ prefix, name, want string }{
+		{"POUNCE", "snapshot-key", "POUNCE_SNAPSHOT_KEY"},
+		{"", "addr", "ADDR"},
+	} {
+		if got := EnvName(tt.prefix, tt.name); got != tt.want {
+			t.Errorf("EnvName(%q, %q) = %q, want %q", tt.prefix, tt.name, got, tt.want)
+		}
+	}
+}
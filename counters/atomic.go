@@ -0,0 +1,56 @@
+package counters
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// AtomicCounter is a lock-free counter. The zero value is ready to use.
+type AtomicCounter struct {
+	n atomic.Int64
+}
+
+// Inc adds one.
+func (c *AtomicCounter) Inc() { c.n.Add(1) }
+
+// Dec subtracts one.
+func (c *AtomicCounter) Dec() { c.n.Add(-1) }
+
+// Add adds n, which may be negative.
+func (c *AtomicCounter) Add(n int64) { c.n.Add(n) }
+
+// Reset sets the count to zero.
+func (c *AtomicCounter) Reset() { c.n.Store(0) }
+
+// Value returns the current count.
+func (c *AtomicCounter) Value() int64 { return c.n.Load() }
+
+// Snapshot returns the current count and the time it was read.
+func (c *AtomicCounter) Snapshot() Snapshot {
+	return Snapshot{Value: c.n.Load(), At: time.Now()}
+}
+
+// Gauge is a float64 that goes up and down, such as a queue depth or a
+// temperature. It is lock-free: the value is stored as its IEEE 754 bits
+// in an atomic.Uint64. The zero value is ready to use and reads 0.
+type Gauge struct {
+	bits atomic.Uint64
+}
+
+// Set replaces the value.
+func (g *Gauge) Set(v float64) { g.bits.Store(math.Float64bits(v)) }
+
+// Add adds delta, which may be negative. There is no atomic float add, so
+// it retries a compare-and-swap until no other writer intervened.
+func (g *Gauge) Add(delta float64) {
+	for {
+		old := g.bits.Load()
+		if g.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+// Value returns the current value.
+func (g *Gauge) Value() float64 { return math.Float64frombits(g.bits.Load()) }
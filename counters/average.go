@@ -0,0 +1,93 @@
+package counters
+
+import "sync"
+
+// Averager smooths a series of samples. MovingAverage and EWMA implement
+// it; both are safe for concurrent use.
+type Averager interface {
+	Add(v float64)
+	Value() float64
+}
+
+// MovingAverage is the mean of the most recent samples, kept in a ring
+// buffer of fixed size. Every sample in the window counts equally and a
+// sample stops counting entirely once it falls out.
+type MovingAverage struct {
+	mu      sync.Mutex
+	samples []float64
+	next    int // slot the next sample goes in
+	full    bool
+}
+
+// NewMovingAverage averages over the last n samples (minimum one).
+func NewMovingAverage(n int) *MovingAverage {
+	return &MovingAverage{samples: make([]float64, max(n, 1))}
+}
+
+// Add records a sample, replacing the oldest once the window is full.
+func (m *MovingAverage) Add(v float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.samples[m.next] = v
+	m.next = (m.next + 1) % len(m.samples)
+	m.full = m.full || m.next == 0
+}
+
+// Value returns the mean of the samples in the window, or 0 before the
+// first. The sum is recomputed each time rather than kept running, so
+// floating-point error cannot build up over a long-lived process.
+func (m *MovingAverage) Value() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	window := m.samples[:m.next]
+	if m.full {
+		window = m.samples
+	}
+	if len(window) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range window {
+		sum += v
+	}
+	return sum / float64(len(window))
+}
+
+// EWMA is an exponentially weighted moving average: each sample moves the
+// value alpha of the way towards it. It needs constant space however long
+// the memory, and old samples fade rather than drop out. An alpha of
+// 2/(n+1) gives roughly the responsiveness of an n-sample MovingAverage.
+type EWMA struct {
+	mu     sync.Mutex
+	alpha  float64
+	value  float64
+	primed bool
+}
+
+// NewEWMA returns an EWMA with smoothing factor alpha, which must be in
+// (0, 1]. Larger values react faster.
+func NewEWMA(alpha float64) *EWMA {
+	if !(alpha > 0 && alpha <= 1) {
+		panic("counters: EWMA alpha must be in (0, 1]")
+	}
+	return &EWMA{alpha: alpha}
+}
+
+// Add folds v into the average. The first sample becomes the value as is,
+// so the average does not start by climbing up from zero.
+func (e *EWMA) Add(v float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.primed {
+		e.value, e.primed = v, true
+		return
+	}
+	e.value += e.alpha * (v - e.value)
+}
+
+// Value returns the current average, or 0 before the first sample.
+func (e *EWMA) Value() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.value
+}
@@ -0,0 +1,73 @@
+package counters
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMovingAverage(t *testing.T) {
+	m := NewMovingAverage(3)
+	if m.Value() != 0 {
+		t.Fatalf("empty Value = %v", m.Value())
+	}
+	tests := []struct {
+		add  float64
+		want float64
+	}{
+		{3, 3},
+		{6, 4.5},
+		{9, 6},
+		{12, 9}, // 3 has left the window
+		{0, 7},
+	}
+	for _, tt := range tests {
+		m.Add(tt.add)
+		if got := m.Value(); got != tt.want {
+			t.Fatalf("after Add(%v): Value = %v, want %v", tt.add, got, tt.want)
+		}
+	}
+	if got := NewMovingAverage(0); len(got.samples) != 1 {
+		t.Fatalf("window of 0 gave %d slots", len(got.samples))
+	}
+}
+
+func TestEWMA(t *testing.T) {
+	e := NewEWMA(0.5)
+	for _, tt := range []struct{ add, want float64 }{
+		{10, 10}, // the first sample is taken as is
+		{20, 15},
+		{20, 17.5},
+		{0, 8.75},
+	} {
+		e.Add(tt.add)
+		if got := e.Value(); got != tt.want {
+			t.Fatalf("after Add(%v): Value = %v, want %v", tt.add, got, tt.want)
+		}
+	}
+
+	// A step change: the EWMA approaches the new level without reaching
+	// it, the moving average gets there once the window has turned over.
+	ewma, ma := NewEWMA(2.0/11), NewMovingAverage(10)
+	for range 10 {
+		ewma.Add(0)
+		ma.Add(0)
+	}
+	for range 10 {
+		ewma.Add(100)
+		ma.Add(100)
+	}
+	if ma.Value() != 100 || ewma.Value() < 80 || ewma.Value() >= 100 {
+		t.Fatalf("after step: moving = %v, ewma = %v", ma.Value(), ewma.Value())
+	}
+
+	for _, alpha := range []float64{0, -1, 1.5, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewEWMA(%v) did not panic", alpha)
+				}
+			}()
+			NewEWMA(alpha)
+		}()
+	}
+}
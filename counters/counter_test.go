@@ -0,0 +1,94 @@
+package counters
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func implementations() map[string]func() Counter {
+	return map[string]func() Counter{
+		"safe":    func() Counter { return new(SafeCounter) },
+		"atomic":  func() Counter { return new(AtomicCounter) },
+		"sharded": func() Counter { return NewShardedCounter(0) },
+	}
+}
+
+func TestConcurrentInc(t *testing.T) {
+	const goroutines, perGoroutine = 16, 1000
+	for name, newCounter := range implementations() {
+		t.Run(name, func(t *testing.T) {
+			c := newCounter()
+			var wg sync.WaitGroup
+			for range goroutines {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for range perGoroutine {
+						c.Inc()
+					}
+				}()
+			}
+			wg.Wait()
+			if got, want := c.Value(), int64(goroutines*perGoroutine); got != want {
+				t.Fatalf("Value() = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestOperations(t *testing.T) {
+	for name, newCounter := range implementations() {
+		t.Run(name, func(t *testing.T) {
+			c := newCounter()
+			c.Add(10)
+			c.Inc()
+			c.Dec()
+			c.Dec()
+			if got := c.Value(); got != 9 {
+				t.Fatalf("Value() = %d, want 9", got)
+			}
+
+			before := time.Now()
+			snap := c.Snapshot()
+			if snap.Value != 9 || snap.At.Before(before) {
+				t.Fatalf("Snapshot() = %+v, want value 9 at or after %v", snap, before)
+			}
+
+			c.Reset()
+			if got := c.Value(); got != 0 {
+				t.Fatalf("Value() after Reset = %d, want 0", got)
+			}
+			if snap.Value != 9 {
+				t.Fatal("Reset changed an earlier snapshot")
+			}
+		})
+	}
+}
+
+func BenchmarkInc(b *testing.B) {
+	for name, newCounter := range implementations() {
+		b.Run(name, func(b *testing.B) {
+			c := newCounter()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					c.Inc()
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkValue(b *testing.B) {
+	for name, newCounter := range implementations() {
+		b.Run(name, func(b *testing.B) {
+			c := newCounter()
+			c.Inc()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_ = c.Value()
+				}
+			})
+		})
+	}
+}
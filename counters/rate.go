@@ -0,0 +1,95 @@
+package counters
+
+import (
+	"sync"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+type rateBucket struct {
+	sec int64 // Unix second this bucket currently holds
+	n   int64
+}
+
+// RateCounter tracks events per second over a sliding window. It keeps one
+// bucket per second in a ring and lazily clears a bucket when its slot is
+// reused for a newer second.
+type RateCounter struct {
+	mu      sync.Mutex
+	buckets []rateBucket
+	clock   clock.Clock
+}
+
+// Option configures a RateCounter or a Sampler.
+type Option func(*options)
+
+type options struct {
+	clock clock.Clock
+}
+
+// WithClock makes a RateCounter or Sampler read and wait on c instead of
+// the system clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+func buildOptions(opts []Option) options {
+	o := options{clock: clock.Real{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// NewRateCounter returns a RateCounter averaging over window, rounded up to
+// whole seconds (minimum one).
+func NewRateCounter(window time.Duration, opts ...Option) *RateCounter {
+	secs := int((window + time.Second - 1) / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	return &RateCounter{buckets: make([]rateBucket, secs), clock: buildOptions(opts).clock}
+}
+
+// Inc records one event.
+func (r *RateCounter) Inc() { r.Add(1) }
+
+// Add records n events.
+func (r *RateCounter) Add(n int64) {
+	sec := r.clock.Now().Unix()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := &r.buckets[r.slot(sec)]
+	if b.sec != sec {
+		*b = rateBucket{sec: sec}
+	}
+	b.n += n
+}
+
+// Total returns the number of events recorded inside the window.
+func (r *RateCounter) Total() int64 {
+	now := r.clock.Now().Unix()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var total int64
+	for _, b := range r.buckets {
+		if now-b.sec < int64(len(r.buckets)) {
+			total += b.n
+		}
+	}
+	return total
+}
+
+// Rate returns the average events per second over the window.
+func (r *RateCounter) Rate() float64 {
+	return float64(r.Total()) / float64(len(r.buckets))
+}
+
+func (r *RateCounter) slot(sec int64) int {
+	i := int(sec % int64(len(r.buckets)))
+	if i < 0 {
+		i += len(r.buckets)
+	}
+	return i
+}
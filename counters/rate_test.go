@@ -0,0 +1,49 @@
+package counters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+func TestRateCounterWindow(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1_000, 0))
+	r := NewRateCounter(3*time.Second, WithClock(clk))
+
+	r.Add(6) // t=1000
+	clk.Advance(time.Second)
+	r.Add(3) // t=1001
+	if got := r.Total(); got != 9 {
+		t.Fatalf("Total() = %d, want 9", got)
+	}
+	if got := r.Rate(); got != 3 {
+		t.Fatalf("Rate() = %v, want 3", got)
+	}
+
+	// At t=1003 the bucket from t=1000 has left the 3s window.
+	clk.Advance(2 * time.Second)
+	if got := r.Total(); got != 3 {
+		t.Fatalf("Total() after slide = %d, want 3", got)
+	}
+
+	// Reusing the t=1000 slot for t=1003 must clear the stale count.
+	r.Inc()
+	if got := r.Total(); got != 4 {
+		t.Fatalf("Total() after slot reuse = %d, want 4", got)
+	}
+
+	clk.Advance(10 * time.Second)
+	if got := r.Total(); got != 0 {
+		t.Fatalf("Total() long after = %d, want 0", got)
+	}
+}
+
+func TestRateCounterMinimumWindow(t *testing.T) {
+	if got := len(NewRateCounter(0).buckets); got != 1 {
+		t.Fatalf("buckets = %d, want 1", got)
+	}
+	if got := len(NewRateCounter(1500 * time.Millisecond).buckets); got != 2 {
+		t.Fatalf("buckets = %d, want 2", got)
+	}
+}
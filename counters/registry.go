@@ -0,0 +1,171 @@
+package counters
+
+import (
+	"expvar"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry hands out named AtomicCounters and Gauges. Lookups of existing
+// names take only a read lock, so hot paths that call GetOrCreate on every
+// request do not serialize.
+type Registry struct {
+	mu       sync.RWMutex
+	counters map[string]*AtomicCounter
+	gauges   map[string]*Gauge
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		counters: make(map[string]*AtomicCounter),
+		gauges:   make(map[string]*Gauge),
+	}
+}
+
+// GetOrCreate returns the counter registered under name, creating it on
+// first use. Callers may keep the returned pointer and skip the lookup on
+// later calls.
+func (r *Registry) GetOrCreate(name string) *AtomicCounter {
+	r.mu.RLock()
+	c, ok := r.counters[name]
+	r.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Another goroutine may have created it between the two locks.
+	if c, ok := r.counters[name]; ok {
+		return c
+	}
+	c = new(AtomicCounter)
+	r.counters[name] = c
+	return c
+}
+
+// GetOrCreateGauge returns the gauge registered under name, creating it on
+// first use. Gauges and counters have separate namespaces.
+func (r *Registry) GetOrCreateGauge(name string) *Gauge {
+	r.mu.RLock()
+	g, ok := r.gauges[name]
+	r.mu.RUnlock()
+	if ok {
+		return g
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if g, ok := r.gauges[name]; ok {
+		return g
+	}
+	g = new(Gauge)
+	r.gauges[name] = g
+	return g
+}
+
+// DumpGauges returns the current value of every gauge.
+func (r *Registry) DumpGauges() map[string]float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make(map[string]float64, len(r.gauges))
+	for name, g := range r.gauges {
+		out[name] = g.Value()
+	}
+	return out
+}
+
+// Dump returns the current value of every counter.
+func (r *Registry) Dump() map[string]int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make(map[string]int64, len(r.counters))
+	for name, c := range r.counters {
+		out[name] = c.Value()
+	}
+	return out
+}
+
+// publishMu makes checking for an expvar name and publishing it one step.
+// expvar names are process-global, so one lock serves every Registry.
+var publishMu sync.Mutex
+
+// Publish exposes Dump under name in expvar, and so on /debug/vars. expvar
+// names are process-global, so publishing a name twice is an error.
+func (r *Registry) Publish(name string) error {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+	if expvar.Get(name) != nil {
+		return fmt.Errorf("counters: expvar %q already published", name)
+	}
+	expvar.Publish(name, expvar.Func(func() any { return r.Dump() }))
+	return nil
+}
+
+// CountRequests increments the counter named after the matched ServeMux
+// pattern (for example "GET /products/{id}") for every request. Wrap the
+// handlers registered on the mux, not the mux itself: the pattern is only
+// known after routing. Requests without a pattern count as "unmatched".
+func (r *Registry) CountRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		name := req.Pattern
+		if name == "" {
+			name = "unmatched"
+		}
+		r.GetOrCreate(name).Inc()
+		next.ServeHTTP(w, req)
+	})
+}
+
+// Handler serves every counter and gauge as plain text, one "name value"
+// line each in name order, each preceded by a "# TYPE" comment in the
+// style of the Prometheus text format. Mount it at /metrics.
+//
+// Names are made valid metric names first: every character but ASCII
+// letters, digits, '_' and ':' becomes '_', so the counter CountRequests
+// keeps for "GET /products/{id}" is served as GET__products__id_.
+// Counters whose names become the same are summed; of gauges, the one
+// whose original name sorts last wins.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		counters := make(map[string]int64)
+		for name, v := range r.Dump() {
+			counters[metricName(name)] += v
+		}
+		gauges := make(map[string]float64)
+		dumped := r.DumpGauges()
+		for _, name := range slices.Sorted(maps.Keys(dumped)) {
+			gauges[metricName(name)] = dumped[name]
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, name := range slices.Sorted(maps.Keys(counters)) {
+			fmt.Fprintf(w, "# TYPE %s counter\n%s %d\n", name, name, counters[name])
+		}
+		for _, name := range slices.Sorted(maps.Keys(gauges)) {
+			fmt.Fprintf(w, "# TYPE %s gauge\n%s %s\n", name, name, strconv.FormatFloat(gauges[name], 'g', -1, 64))
+		}
+	})
+}
+
+// metricName maps name onto [a-zA-Z_:][a-zA-Z0-9_:]*.
+func metricName(name string) string {
+	valid := func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == ':'
+	}
+	out := strings.Map(func(r rune) rune {
+		if valid(r) {
+			return r
+		}
+		return '_'
+	}, name)
+	if out == "" || out[0] >= '0' && out[0] <= '9' {
+		out = "_" + out
+	}
+	return out
+}
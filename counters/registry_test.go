@@ -0,0 +1,159 @@
+package counters
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+var published atomic.Int64
+
+// expvarName returns a name for t to publish under that no other test, nor
+// an earlier run of t under -count, has taken: expvar names are global and
+// cannot be unpublished.
+func expvarName(t *testing.T) string {
+	return fmt.Sprintf("%s_%d", t.Name(), published.Add(1))
+}
+
+func TestRegistryGetOrCreate(t *testing.T) {
+	r := NewRegistry()
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.GetOrCreate("hits").Inc()
+		}()
+	}
+	wg.Wait()
+
+	if r.GetOrCreate("hits") != r.GetOrCreate("hits") {
+		t.Fatal("GetOrCreate returned different counters for the same name")
+	}
+	got := r.Dump()
+	if len(got) != 1 || got["hits"] != 50 {
+		t.Fatalf("Dump() = %v, want map[hits:50]", got)
+	}
+}
+
+func TestRegistryPublish(t *testing.T) {
+	r := NewRegistry()
+	r.GetOrCreate("a").Add(3)
+	name := expvarName(t)
+	if err := r.Publish(name); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Publish(name); err == nil {
+		t.Fatal("second Publish with the same name succeeded")
+	}
+
+	var got map[string]int64
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["a"] != 3 {
+		t.Fatalf("published value = %v, want a=3", got)
+	}
+}
+
+func TestRegistryPublishConcurrent(t *testing.T) {
+	r := NewRegistry()
+	name := expvarName(t)
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- r.Publish(name)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	ok := 0
+	for err := range errs {
+		if err == nil {
+			ok++
+		}
+	}
+	if ok != 1 {
+		t.Fatalf("%d of 8 concurrent Publish calls succeeded, want 1", ok)
+	}
+}
+
+func TestCountRequests(t *testing.T) {
+	r := NewRegistry()
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {})
+	mux := http.NewServeMux()
+	mux.Handle("GET /products/{id}", r.CountRequests(ok))
+	mux.Handle("GET /health", r.CountRequests(ok))
+
+	for _, path := range []string{"/products/1", "/products/2", "/health"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	got := r.Dump()
+	if got["GET /products/{id}"] != 2 || got["GET /health"] != 1 {
+		t.Fatalf("Dump() = %v", got)
+	}
+}
+
+func TestGaugeConcurrentAdd(t *testing.T) {
+	var g Gauge
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				g.Add(0.5)
+			}
+		}()
+	}
+	wg.Wait()
+	if g.Value() != 4000 {
+		t.Fatalf("Value() = %v, want 4000", g.Value())
+	}
+	g.Set(-1.25)
+	if g.Value() != -1.25 {
+		t.Fatalf("Value() after Set = %v", g.Value())
+	}
+}
+
+func TestRegistryHandler(t *testing.T) {
+	r := NewRegistry()
+	r.GetOrCreate("requests").Add(3)
+	r.GetOrCreate("errors").Inc()
+	r.GetOrCreateGauge("queue_depth").Set(2.5)
+	r.GetOrCreate("GET /products/{id}").Add(2)
+	r.GetOrCreate("stock.anvils").Add(5)
+	r.GetOrCreate("stock_anvils").Inc() // the same name once sanitized
+	r.GetOrCreateGauge("1m.rate").Set(4)
+	if r.GetOrCreateGauge("queue_depth") != r.GetOrCreateGauge("queue_depth") {
+		t.Fatal("GetOrCreateGauge returned different gauges for the same name")
+	}
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	want := `# TYPE GET__products__id_ counter
+GET__products__id_ 2
+# TYPE errors counter
+errors 1
+# TYPE requests counter
+requests 3
+# TYPE stock_anvils counter
+stock_anvils 6
+# TYPE _1m_rate gauge
+_1m_rate 4
+# TYPE queue_depth gauge
+queue_depth 2.5
+`
+	if got := rec.Body.String(); got != want {
+		t.Fatalf("body =\n%s\nwant\n%s", got, want)
+	}
+}
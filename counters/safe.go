@@ -0,0 +1,47 @@
+package counters
+
+import (
+	"sync"
+	"time"
+)
+
+// SafeCounter is a mutex-protected counter. The zero value is ready to use.
+type SafeCounter struct {
+	mu sync.Mutex
+	n  int64
+}
+
+// Inc adds one.
+func (c *SafeCounter) Inc() { c.Add(1) }
+
+// Dec subtracts one.
+func (c *SafeCounter) Dec() { c.Add(-1) }
+
+// Add adds n, which may be negative.
+func (c *SafeCounter) Add(n int64) {
+	c.mu.Lock()
+	c.n += n
+	c.mu.Unlock()
+}
+
+// Reset sets the count to zero.
+func (c *SafeCounter) Reset() {
+	c.mu.Lock()
+	c.n = 0
+	c.mu.Unlock()
+}
+
+// Value returns the current count.
+func (c *SafeCounter) Value() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+// Snapshot returns the current count and the time it was read, both taken
+// under the lock.
+func (c *SafeCounter) Snapshot() Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Snapshot{Value: c.n, At: time.Now()}
+}
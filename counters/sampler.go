@@ -0,0 +1,94 @@
+package counters
+
+import (
+	"sync"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+// Sampler reads a set of sources once per tick, feeds each reading to an
+// Averager and publishes the average as a gauge in a Registry, where the
+// /metrics handler picks it up. One goroutine does all the sampling.
+type Sampler struct {
+	reg      *Registry
+	interval time.Duration
+	clock    clock.Clock
+
+	mu     sync.Mutex
+	series []series
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+type series struct {
+	gauge  *Gauge
+	sample func() float64
+	avg    Averager
+}
+
+// NewSampler starts sampling every interval into reg. Call Stop to end
+// it.
+func NewSampler(reg *Registry, interval time.Duration, opts ...Option) *Sampler {
+	s := &Sampler{
+		reg:      reg,
+		interval: interval,
+		clock:    buildOptions(opts).clock,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Track samples src every tick and publishes avg's value as the gauge
+// name.
+func (s *Sampler) Track(name string, src func() float64, avg Averager) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.series = append(s.series, series{s.reg.GetOrCreateGauge(name), src, avg})
+}
+
+// TrackRate publishes the average per-second rate at which c grows, as
+// measured over each tick.
+func (s *Sampler) TrackRate(name string, c Counter, avg Averager) {
+	s.TrackRateFunc(name, c.Value, avg)
+}
+
+// TrackRateFunc is TrackRate for a count read by value, such as the sum
+// of several counters.
+func (s *Sampler) TrackRateFunc(name string, value func() int64, avg Averager) {
+	last := value()
+	s.Track(name, func() float64 {
+		v := value()
+		delta := v - last
+		last = v
+		return float64(delta) / s.interval.Seconds()
+	}, avg)
+}
+
+func (s *Sampler) run() {
+	defer close(s.done)
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-s.clock.After(s.interval):
+		}
+		s.mu.Lock()
+		for _, sr := range s.series {
+			sr.avg.Add(sr.sample())
+			sr.gauge.Set(sr.avg.Value())
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Stop ends sampling and waits for an in-progress tick to finish. The
+// gauges keep their last values. Calling Stop more than once is safe.
+func (s *Sampler) Stop() {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+}
@@ -0,0 +1,66 @@
+package counters
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+	"github.com/stawuah/pounce-on-go/concurrency/leaktest"
+)
+
+func TestSampler(t *testing.T) {
+	leaktest.Check(t)
+	clk := clock.NewFake(time.Unix(1_000, 0))
+	reg := NewRegistry()
+	s := NewSampler(reg, time.Second, WithClock(clk))
+	defer s.Stop()
+
+	requests := reg.GetOrCreate("requests")
+	depth := 4.0
+	s.TrackRate("requests.rps.avg3", requests, NewMovingAverage(3))
+	s.TrackRate("requests.rps.ewma", requests, NewEWMA(0.5))
+	s.Track("queue.depth.avg3", func() float64 { return depth }, NewMovingAverage(3))
+
+	// tick runs one sampling pass: wait for the sampler to be asleep on
+	// the clock, wake it, and wait for it to go back to sleep.
+	tick := func() {
+		clk.BlockUntil(1)
+		clk.Advance(time.Second)
+		clk.BlockUntil(1)
+	}
+
+	for _, n := range []int64{10, 20, 30, 0} {
+		requests.Add(n)
+		tick()
+	}
+	depth = 10
+	tick()
+
+	// The rate samples were 10, 20, 30, 0 and 0 per second.
+	gauges := reg.DumpGauges()
+	want := map[string]float64{
+		"requests.rps.avg3": (30 + 0 + 0) / 3.0,
+		"requests.rps.ewma": 5.625, // 10, 15, 22.5, 11.25, 5.625
+		"queue.depth.avg3":  (4 + 4 + 10) / 3.0,
+	}
+	for name, w := range want {
+		if got := gauges[name]; got != w {
+			t.Errorf("%s = %v, want %v", name, got, w)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), "# TYPE requests_rps_ewma gauge\nrequests_rps_ewma 5.625\n") {
+		t.Fatalf("/metrics lacks the EWMA gauge:\n%s", rec.Body)
+	}
+}
+
+func TestSamplerStopIsIdempotent(t *testing.T) {
+	leaktest.Check(t)
+	s := NewSampler(NewRegistry(), time.Hour)
+	s.Stop()
+	s.Stop()
+}
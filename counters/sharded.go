@@ -0,0 +1,67 @@
+package counters
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// cacheLine is the padding unit used to keep shards on separate cache
+// lines. 64 bytes covers amd64 and most arm64 parts.
+const cacheLine = 64
+
+type shard struct {
+	n atomic.Int64
+	_ [cacheLine - 8]byte
+}
+
+// ShardedCounter spreads increments across several padded atomics. Inc is
+// cheap under contention; Value walks every shard.
+type ShardedCounter struct {
+	shards []shard
+}
+
+// NewShardedCounter returns a counter with n shards. If n <= 0 it uses
+// runtime.GOMAXPROCS(0).
+func NewShardedCounter(n int) *ShardedCounter {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	return &ShardedCounter{shards: make([]shard, n)}
+}
+
+// Inc adds one to a randomly chosen shard.
+func (c *ShardedCounter) Inc() { c.Add(1) }
+
+// Dec subtracts one from a randomly chosen shard. Individual shards may go
+// negative; only the sum is meaningful.
+func (c *ShardedCounter) Dec() { c.Add(-1) }
+
+// Add adds n to a randomly chosen shard. The runtime's per-P random source
+// makes the choice itself contention-free.
+func (c *ShardedCounter) Add(n int64) {
+	c.shards[rand.N(len(c.shards))].n.Add(n)
+}
+
+// Reset zeroes every shard. Increments racing with Reset may survive it.
+func (c *ShardedCounter) Reset() {
+	for i := range c.shards {
+		c.shards[i].n.Store(0)
+	}
+}
+
+// Value returns the sum of all shards. It is not a point-in-time snapshot
+// while writers are active.
+func (c *ShardedCounter) Value() int64 {
+	var total int64
+	for i := range c.shards {
+		total += c.shards[i].n.Load()
+	}
+	return total
+}
+
+// Snapshot returns Value and the time the sum was taken.
+func (c *ShardedCounter) Snapshot() Snapshot {
+	return Snapshot{Value: c.Value(), At: time.Now()}
+}
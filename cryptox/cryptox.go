@@ -0,0 +1,161 @@
+// Package cryptox wraps the standard crypto packages for the three jobs
+// the product service has for them: signing webhook payloads with
+// HMAC-SHA256 and checking those signatures on the way in, and sealing
+// files at rest with AES-256-GCM under a key taken from the environment.
+// Content hashes are in the digest package.
+//
+// Nothing here invents a primitive. The helpers fix the choices a caller
+// would otherwise have to get right each time: constant-time comparison,
+// a timestamp inside the signature so a captured request cannot be
+// replayed later, and a fresh random nonce for every encryption.
+package cryptox
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/stawuah/pounce-on-go/fileio"
+)
+
+// KeySize is the length of an encryption key: AES-256.
+const KeySize = 32
+
+// sealVersion is the first byte of everything Encrypt produces, so the
+// format can change without misreading old files.
+const sealVersion = 1
+
+var (
+	// ErrNoKey is returned by KeyFromEnv when the variable is unset.
+	ErrNoKey = errors.New("cryptox: no key")
+	// ErrBadKey is returned for a key of the wrong length or encoding.
+	ErrBadKey = errors.New("cryptox: key must be 32 bytes, hex or base64 encoded")
+	// ErrDecrypt is returned when sealed data is truncated, was sealed
+	// under another key, or has been tampered with. GCM cannot tell these
+	// apart, and neither does Decrypt.
+	ErrDecrypt = errors.New("cryptox: decryption failed")
+)
+
+// ParseKey decodes a 32-byte key written as 64 hex digits or as base64.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if key, err := hex.DecodeString(s); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	return nil, ErrBadKey
+}
+
+// KeyFromEnv reads a key from the environment variable name. Keys belong
+// in the environment, or a secret store that fills it, rather than in
+// flags, which show up in process listings.
+func KeyFromEnv(name string) ([]byte, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil, fmt.Errorf("%w: $%s is not set", ErrNoKey, name)
+	}
+	key, err := ParseKey(v)
+	if err != nil {
+		return nil, fmt.Errorf("$%s: %w", name, err)
+	}
+	return key, nil
+}
+
+// NewKey returns a random key, hex encoded, for setting up a deployment.
+func NewKey() string {
+	return hex.EncodeToString(randomBytes(KeySize))
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("cryptox: crypto/rand failed: " + err.Error())
+	}
+	return b
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrBadKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt seals plaintext with AES-256-GCM. The result is a version byte,
+// a random nonce and the ciphertext with its authentication tag. ad is
+// authenticated but not encrypted; Decrypt must be given the same ad, so
+// binding, say, a file's purpose into it stops one sealed file being
+// passed off as another.
+func Encrypt(key, plaintext, ad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 1, 1+gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	out[0] = sealVersion
+	out = append(out, randomBytes(gcm.NonceSize())...)
+	return gcm.Seal(out, out[1:], plaintext, append([]byte{sealVersion}, ad...)), nil
+}
+
+// Decrypt opens what Encrypt sealed under the same key and ad.
+func Decrypt(key, sealed, ad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < 1+gcm.NonceSize()+gcm.Overhead() || sealed[0] != sealVersion {
+		return nil, ErrDecrypt
+	}
+	nonce, ciphertext := sealed[1:1+gcm.NonceSize()], sealed[1+gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, append([]byte{sealVersion}, ad...))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// WriteEncryptedFile replaces path, atomically, with what write produces
+// sealed under key. GCM seals a message as a whole, so the plaintext is
+// held in memory.
+func WriteEncryptedFile(path string, key []byte, perm os.FileMode, write func(io.Writer) error) error {
+	var plain bytes.Buffer
+	if err := write(&plain); err != nil {
+		return err
+	}
+	sealed, err := Encrypt(key, plain.Bytes(), nil)
+	if err != nil {
+		return err
+	}
+	return fileio.WriteAtomic(path, perm, func(w io.Writer) error {
+		_, err := w.Write(sealed)
+		return err
+	})
+}
+
+// ReadEncryptedFile returns the plaintext of a file written by
+// WriteEncryptedFile.
+func ReadEncryptedFile(path string, key []byte) ([]byte, error) {
+	sealed, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := Decrypt(key, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return plain, nil
+}
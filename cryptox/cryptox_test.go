@@ -0,0 +1,118 @@
+package cryptox
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testKey = bytes.Repeat([]byte{0x42}, KeySize)
+
+func TestParseKey(t *testing.T) {
+	hexKey := strings.Repeat("42", KeySize)
+	b64Key := base64.StdEncoding.EncodeToString(testKey)
+	for _, s := range []string{hexKey, b64Key, " " + hexKey + "\n"} {
+		key, err := ParseKey(s)
+		if err != nil || !bytes.Equal(key, testKey) {
+			t.Errorf("ParseKey(%q) = %x, %v", s, key, err)
+		}
+	}
+	for _, s := range []string{"", "42", strings.Repeat("42", KeySize+1), "not a key at all"} {
+		if _, err := ParseKey(s); !errors.Is(err, ErrBadKey) {
+			t.Errorf("ParseKey(%q) err = %v, want ErrBadKey", s, err)
+		}
+	}
+	if key, err := ParseKey(NewKey()); err != nil || len(key) != KeySize {
+		t.Fatalf("ParseKey(NewKey()) = %x, %v", key, err)
+	}
+	if NewKey() == NewKey() {
+		t.Fatal("NewKey returned the same key twice")
+	}
+}
+
+func TestKeyFromEnv(t *testing.T) {
+	t.Setenv("TEST_KEY", strings.Repeat("42", KeySize))
+	if key, err := KeyFromEnv("TEST_KEY"); err != nil || !bytes.Equal(key, testKey) {
+		t.Fatalf("KeyFromEnv = %x, %v", key, err)
+	}
+	t.Setenv("TEST_KEY", "")
+	if _, err := KeyFromEnv("TEST_KEY"); !errors.Is(err, ErrNoKey) {
+		t.Fatalf("unset: err = %v, want ErrNoKey", err)
+	}
+	t.Setenv("TEST_KEY", "short")
+	if _, err := KeyFromEnv("TEST_KEY"); !errors.Is(err, ErrBadKey) || !strings.Contains(err.Error(), "$TEST_KEY") {
+		t.Fatalf("bad: err = %v, want ErrBadKey naming the variable", err)
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	plain := []byte(`{"sku":"A1","name":"Anvil","price":9}`)
+	ad := []byte("snapshot")
+	sealed, err := Encrypt(testKey, plain, ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte("Anvil")) {
+		t.Fatal("plaintext visible in sealed output")
+	}
+	again, _ := Encrypt(testKey, plain, ad)
+	if bytes.Equal(sealed, again) {
+		t.Fatal("two encryptions of the same plaintext are identical; nonce reused")
+	}
+	got, err := Decrypt(testKey, sealed, ad)
+	if err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("Decrypt = %q, %v", got, err)
+	}
+
+	otherKey := bytes.Repeat([]byte{0x43}, KeySize)
+	flipped := bytes.Clone(sealed)
+	flipped[len(flipped)-1] ^= 1
+	tests := []struct {
+		name   string
+		key    []byte
+		sealed []byte
+		ad     []byte
+	}{
+		{"wrong key", otherKey, sealed, ad},
+		{"wrong ad", testKey, sealed, []byte("backup")},
+		{"tampered", testKey, flipped, ad},
+		{"truncated", testKey, sealed[:20], ad},
+		{"empty", testKey, nil, ad},
+		{"unknown version", testKey, append([]byte{9}, sealed[1:]...), ad},
+	}
+	for _, tt := range tests {
+		if _, err := Decrypt(tt.key, tt.sealed, tt.ad); !errors.Is(err, ErrDecrypt) {
+			t.Errorf("%s: err = %v, want ErrDecrypt", tt.name, err)
+		}
+	}
+	if _, err := Encrypt(testKey[:16], plain, nil); !errors.Is(err, ErrBadKey) {
+		t.Fatalf("AES-128 key: err = %v, want ErrBadKey", err)
+	}
+}
+
+func TestEncryptedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "products.snapshot")
+	write := func(w io.Writer) error {
+		_, err := io.WriteString(w, "line one\nline two\n")
+		return err
+	}
+	if err := WriteEncryptedFile(path, testKey, 0o600, write); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := os.ReadFile(path)
+	if bytes.Contains(raw, []byte("line")) {
+		t.Fatal("file holds plaintext")
+	}
+	got, err := ReadEncryptedFile(path, testKey)
+	if err != nil || string(got) != "line one\nline two\n" {
+		t.Fatalf("ReadEncryptedFile = %q, %v", got, err)
+	}
+	if _, err := ReadEncryptedFile(path, bytes.Repeat([]byte{1}, KeySize)); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("wrong key: err = %v, want ErrDecrypt", err)
+	}
+}
@@ -0,0 +1,153 @@
+package cryptox
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+// SignatureHeader carries a payload's signature, in the form
+//
+//	t=1741339800,v1=5257a869e7ecebeda32affa62cdca3fa51cad7e77a0e56ff536d0ce8e108d8bd
+//
+// where t is the Unix time of signing and v1 the hex HMAC-SHA256 of t, a
+// dot and the body.
+const SignatureHeader = "X-Pounce-Signature"
+
+var (
+	// ErrNoSignature is returned when the header is missing or unparsable.
+	ErrNoSignature = errors.New("cryptox: missing or malformed signature")
+	// ErrBadSignature is returned when no signature matches the body.
+	ErrBadSignature = errors.New("cryptox: signature mismatch")
+	// ErrStaleSignature is returned when the signing time is outside the
+	// verifier's tolerance.
+	ErrStaleSignature = errors.New("cryptox: signature too old or too new")
+)
+
+func mac(secret []byte, t int64, body []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	fmt.Fprintf(h, "%d.", t)
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// Sign returns the SignatureHeader value for body signed at t.
+func Sign(secret []byte, t time.Time, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", t.Unix(), hex.EncodeToString(mac(secret, t.Unix(), body)))
+}
+
+// SignRequest sets r's SignatureHeader for body, which must be the body r
+// will send.
+func SignRequest(r *http.Request, secret []byte, t time.Time, body []byte) {
+	r.Header.Set(SignatureHeader, Sign(secret, t, body))
+}
+
+// Verifier checks signed requests. Secrets lists every secret currently
+// accepted, so a secret can be rotated by adding the new one, moving
+// senders over, then dropping the old one.
+type Verifier struct {
+	Secrets [][]byte
+	// Tolerance is how far the signing time may be from now, either way.
+	// It defaults to five minutes.
+	Tolerance time.Duration
+	// MaxBody caps how much of a request Middleware reads. It defaults to
+	// 1 MiB.
+	MaxBody int64
+	// Clock defaults to the system clock.
+	Clock clock.Clock
+}
+
+// Verify checks header against body.
+func (v *Verifier) Verify(header string, body []byte) error {
+	t, sigs, err := parseSignature(header)
+	if err != nil {
+		return err
+	}
+	tolerance := v.Tolerance
+	if tolerance <= 0 {
+		tolerance = 5 * time.Minute
+	}
+	var c clock.Clock = clock.Real{}
+	if v.Clock != nil {
+		c = v.Clock
+	}
+	if d := c.Now().Sub(time.Unix(t, 0)); d > tolerance || d < -tolerance {
+		return ErrStaleSignature
+	}
+	for _, secret := range v.Secrets {
+		want := mac(secret, t, body)
+		for _, sig := range sigs {
+			if hmac.Equal(sig, want) {
+				return nil
+			}
+		}
+	}
+	return ErrBadSignature
+}
+
+// parseSignature splits a header into its time and v1 signatures. Unknown
+// schemes are ignored so a later version can be sent alongside v1.
+func parseSignature(header string) (t int64, sigs [][]byte, err error) {
+	haveT := false
+	for part := range strings.SplitSeq(header, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return 0, nil, ErrNoSignature
+		}
+		switch k {
+		case "t":
+			if t, err = strconv.ParseInt(v, 10, 64); err != nil {
+				return 0, nil, ErrNoSignature
+			}
+			haveT = true
+		case "v1":
+			sig, err := hex.DecodeString(v)
+			if err != nil {
+				return 0, nil, ErrNoSignature
+			}
+			sigs = append(sigs, sig)
+		}
+	}
+	if !haveT || len(sigs) == 0 {
+		return 0, nil, ErrNoSignature
+	}
+	return t, sigs, nil
+}
+
+// Middleware rejects requests whose body does not carry a valid
+// signature with 401 Unauthorized, and hands the rest to next with the
+// body intact.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := v.MaxBody
+		if limit <= 0 {
+			limit = 1 << 20
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		case err != nil:
+			http.Error(w, "reading request body failed", http.StatusBadRequest)
+			return
+		}
+		if err := v.Verify(r.Header.Get(SignatureHeader), body); err != nil {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,83 @@
+package cryptox
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1_741_339_800, 0)
+	secret, old := []byte("whsec-new"), []byte("whsec-old")
+	body := []byte(`{"type":"product.created"}`)
+	v := &Verifier{Secrets: [][]byte{secret, old}, Clock: clock.NewFake(now)}
+
+	tests := []struct {
+		name   string
+		header string
+		body   []byte
+		want   error
+	}{
+		{"valid", Sign(secret, now, body), body, nil},
+		{"old secret still accepted", Sign(old, now, body), body, nil},
+		{"within tolerance", Sign(secret, now.Add(-4*time.Minute), body), body, nil},
+		{"clock skew ahead", Sign(secret, now.Add(time.Minute), body), body, nil},
+		{"extra scheme ignored", Sign(secret, now, body) + ",v2=abcd", body, nil},
+		{"body changed", Sign(secret, now, body), []byte(`{"type":"product.deleted"}`), ErrBadSignature},
+		{"unknown secret", Sign([]byte("guess"), now, body), body, ErrBadSignature},
+		{"replayed later", Sign(secret, now.Add(-6*time.Minute), body), body, ErrStaleSignature},
+		{"timestamp edited", strings.Replace(Sign(secret, now, body), "t=1741339800", "t=1741339801", 1), body, ErrBadSignature},
+		{"missing", "", body, ErrNoSignature},
+		{"no v1", "t=1741339800", body, ErrNoSignature},
+		{"no t", "v1=abcd", body, ErrNoSignature},
+		{"bad hex", "t=1741339800,v1=zz", body, ErrNoSignature},
+	}
+	for _, tt := range tests {
+		if err := v.Verify(tt.header, tt.body); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	now := time.Now()
+	secret := []byte("whsec")
+	v := &Verifier{Secrets: [][]byte{secret}, MaxBody: 64}
+	h := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Write(b) // the handler still sees the whole body
+	}))
+
+	send := func(body, sig string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/hooks", strings.NewReader(body))
+		if sig != "" {
+			r.Header.Set(SignatureHeader, sig)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	body := `{"sku":"A1"}`
+	r := httptest.NewRequest("POST", "/hooks", nil)
+	SignRequest(r, secret, now, []byte(body))
+	if rec := send(body, r.Header.Get(SignatureHeader)); rec.Code != 200 || rec.Body.String() != body {
+		t.Fatalf("signed: %d %q", rec.Code, rec.Body)
+	}
+	if rec := send(body, ""); rec.Code != 401 {
+		t.Fatalf("unsigned: %d, want 401", rec.Code)
+	}
+	if rec := send(`{"sku":"B2"}`, Sign(secret, now, []byte(body))); rec.Code != 401 {
+		t.Fatalf("tampered: %d, want 401", rec.Code)
+	}
+	big := strings.Repeat("x", 65)
+	if rec := send(big, Sign(secret, now, []byte(big))); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized: %d, want 413", rec.Code)
+	}
+}
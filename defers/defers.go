@@ -0,0 +1,100 @@
+// Package defers pins down the defer rules that most often surprise
+// people, each as a small function whose behaviour the tests check:
+//
+//   - Arguments to a deferred call are evaluated when the defer statement
+//     runs, not when the deferred call does. A deferred closure reads
+//     variables when it finally runs.
+//   - Defers run when the function returns, not when a loop iteration
+//     ends, so deferring Close inside a loop holds every file open until
+//     the end.
+//   - A deferred closure can read and assign named results, which is how
+//     errors get wrapped or a Close error gets reported after the fact.
+package defers
+
+import (
+	"fmt"
+	"io"
+)
+
+// Evaluation records, in run order, what a deferred call with an argument
+// and a deferred closure each see of a variable that changes after both
+// defers are registered.
+func Evaluation() (log []string) {
+	record := func(s string) { log = append(log, s) }
+
+	x := 1
+	defer record(fmt.Sprint("argument saw ", x)) // argument evaluated now
+	defer func() { record(fmt.Sprint("closure reads ", x)) }()
+	x = 2
+	return nil
+}
+
+// Order returns the order in which three defers run: last in, first out.
+func Order() (order []int) {
+	for i := range 3 {
+		defer func() { order = append(order, i) }()
+	}
+	return nil
+}
+
+// Opener opens a resource by name.
+type Opener func(name string) (io.ReadCloser, error)
+
+// ReadAllLeaky reads every named resource, deferring each Close inside
+// the loop. All of them stay open until ReadAllLeaky returns.
+func ReadAllLeaky(open Opener, names []string) (int64, error) {
+	var total int64
+	for _, name := range names {
+		rc, err := open(name)
+		if err != nil {
+			return total, err
+		}
+		defer rc.Close() // runs at function exit, not per iteration
+		n, err := io.Copy(io.Discard, rc)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// ReadAll reads every named resource, closing each before opening the
+// next by giving each iteration its own function.
+func ReadAll(open Opener, names []string) (int64, error) {
+	var total int64
+	for _, name := range names {
+		n, err := readOne(open, name)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func readOne(open Opener, name string) (n int64, err error) {
+	rc, err := open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer closeInto(rc, &err)
+	return io.Copy(io.Discard, rc)
+}
+
+// closeInto closes c and, if the function had otherwise succeeded,
+// reports the Close error through *err. For writers the Close error is
+// often the only sign that buffered data never reached disk.
+func closeInto(c io.Closer, err *error) {
+	if cerr := c.Close(); cerr != nil && *err == nil {
+		*err = cerr
+	}
+}
+
+// wrap prefixes a non-nil *err with context. Deferred with a named
+// result, it annotates every return path at once.
+func wrap(err *error, format string, args ...any) {
+	if *err != nil {
+		*err = fmt.Errorf(format+": %w", append(args, *err)...)
+	}
+}
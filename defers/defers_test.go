@@ -0,0 +1,125 @@
+package defers
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEvaluation(t *testing.T) {
+	want := []string{"closure reads 2", "argument saw 1"}
+	if got := Evaluation(); !slices.Equal(got, want) {
+		t.Fatalf("Evaluation() = %q, want %q", got, want)
+	}
+}
+
+func TestOrder(t *testing.T) {
+	if got := Order(); !slices.Equal(got, []int{2, 1, 0}) {
+		t.Fatalf("Order() = %v, want [2 1 0]", got)
+	}
+}
+
+// tracker hands out readers and records how many are open at once.
+type tracker struct {
+	open, peak int
+	closeErr   error
+}
+
+type trackedReader struct {
+	io.Reader
+	t *tracker
+}
+
+func (r trackedReader) Close() error {
+	r.t.open--
+	return r.t.closeErr
+}
+
+func (t *tracker) Open(name string) (io.ReadCloser, error) {
+	t.open++
+	t.peak = max(t.peak, t.open)
+	return trackedReader{strings.NewReader(name), t}, nil
+}
+
+func TestLoopDefer(t *testing.T) {
+	names := []string{"a", "bb", "ccc", "dddd"}
+
+	leaky := &tracker{}
+	if n, _ := ReadAllLeaky(leaky.Open, names); n != 10 {
+		t.Fatalf("ReadAllLeaky read %d bytes", n)
+	}
+	if leaky.peak != len(names) {
+		t.Fatalf("leaky peak = %d, want %d", leaky.peak, len(names))
+	}
+
+	scoped := &tracker{}
+	if n, _ := ReadAll(scoped.Open, names); n != 10 {
+		t.Fatalf("ReadAll read %d bytes", n)
+	}
+	if scoped.peak != 1 || scoped.open != 0 {
+		t.Fatalf("scoped peak = %d, open = %d; want 1 and 0", scoped.peak, scoped.open)
+	}
+}
+
+func TestCloseErrorReported(t *testing.T) {
+	errClose := errors.New("flush failed")
+	tr := &tracker{closeErr: errClose}
+	if _, err := ReadAll(tr.Open, []string{"a"}); !errors.Is(err, errClose) {
+		t.Fatalf("ReadAll() = %v, want close error", err)
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	s := NewStore()
+	s.Set("sku:A1", "Anvil")
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewStore()
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := loaded.Get("sku:A1"); !ok || v != "Anvil" {
+		t.Fatalf("Get() = %q, %t", v, ok)
+	}
+}
+
+func TestStoreErrorsAreWrapped(t *testing.T) {
+	dir := t.TempDir()
+	err := NewStore().Load(filepath.Join(dir, "missing.json"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Load() = %v, want ErrNotExist", err)
+	}
+	if !strings.HasPrefix(err.Error(), "load ") {
+		t.Fatalf("Load() error not wrapped: %q", err)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte("{"), 0o644)
+	if err := NewStore().Load(bad); err == nil || !strings.Contains(err.Error(), "load "+bad) {
+		t.Fatalf("Load(bad) = %v", err)
+	}
+}
+
+func TestFailedSaveLeavesNoTempFile(t *testing.T) {
+	dir := t.TempDir()
+	// Renaming onto a directory fails after the temp file is written.
+	target := filepath.Join(dir, "target")
+	os.Mkdir(target, 0o755)
+	os.WriteFile(filepath.Join(target, "keep"), nil, 0o644)
+
+	if err := NewStore().Save(target); err == nil {
+		t.Fatal("Save onto a non-empty directory succeeded")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("dir has %d entries, want only the target; temp file leaked", len(entries))
+	}
+}
@@ -0,0 +1,84 @@
+package defers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store is a string map persisted to a JSON file. It uses defer for the
+// three cleanup jobs that must happen on every path: unlocking, closing,
+// and removing a temporary file when a save fails part-way.
+type Store struct {
+	mu   sync.RWMutex
+	data map[string]string
+}
+
+// NewStore returns an empty store.
+func NewStore() *Store { return &Store{data: make(map[string]string)} }
+
+// Get returns the value for key.
+func (s *Store) Get(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.data[key]
+	return v, ok
+}
+
+// Set stores value under key.
+func (s *Store) Set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+}
+
+// Save writes the store to path atomically: it writes a temporary file in
+// the same directory and renames it into place, so a crash leaves either
+// the old file or the new one, never half of one.
+func (s *Store) Save(path string) (err error) {
+	defer wrap(&err, "save %s", path)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".store-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+
+	if err := json.NewEncoder(f).Encode(s.data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Load replaces the store's contents with those saved at path.
+func (s *Store) Load(path string) (err error) {
+	defer wrap(&err, "load %s", path)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer closeInto(f, &err)
+
+	data := make(map[string]string)
+	if err := json.NewDecoder(f).Decode(&data); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = data
+	return nil
+}
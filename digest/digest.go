@@ -0,0 +1,95 @@
+// Package digest provides a SHA-256 content hash as a value type.
+//
+// Digest is a [32]byte, not a []byte: it is comparable, so it can be a map
+// key or compared with ==, and copying it copies the bytes rather than a
+// pointer to them.
+package digest
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// Size is the length of a Digest in bytes.
+const Size = sha256.Size
+
+// Digest is a SHA-256 hash.
+type Digest [Size]byte
+
+// Sum returns the SHA-256 digest of data.
+func Sum(data []byte) Digest { return sha256.Sum256(data) }
+
+// SumReader hashes everything read from r.
+func SumReader(r io.Reader) (Digest, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return Digest{}, err
+	}
+	var d Digest
+	h.Sum(d[:0])
+	return d, nil
+}
+
+// Equal compares d and o in constant time. Use it instead of == when one
+// side is attacker-supplied, such as a checksum received over the network.
+func (d Digest) Equal(o Digest) bool {
+	return subtle.ConstantTimeCompare(d[:], o[:]) == 1
+}
+
+// IsZero reports whether d is the zero value, which no real input hashes to
+// in practice and is used to mean "not computed".
+func (d Digest) IsZero() bool { return d == Digest{} }
+
+// Hex returns the lowercase hex encoding.
+func (d Digest) Hex() string { return hex.EncodeToString(d[:]) }
+
+// Base64 returns the standard padded base64 encoding.
+func (d Digest) Base64() string { return base64.StdEncoding.EncodeToString(d[:]) }
+
+// String returns the hex encoding.
+func (d Digest) String() string { return d.Hex() }
+
+// ParseHex decodes a 64-character hex string.
+func ParseHex(s string) (Digest, error) {
+	var d Digest
+	if hex.DecodedLen(len(s)) != Size {
+		return d, fmt.Errorf("digest: hex string has length %d, want %d", len(s), 2*Size)
+	}
+	if _, err := hex.Decode(d[:], []byte(s)); err != nil {
+		return d, fmt.Errorf("digest: %w", err)
+	}
+	return d, nil
+}
+
+// ParseBase64 decodes a standard base64 string.
+func ParseBase64(s string) (Digest, error) {
+	var d Digest
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return d, fmt.Errorf("digest: %w", err)
+	}
+	if len(b) != Size {
+		return d, fmt.Errorf("digest: decoded %d bytes, want %d", len(b), Size)
+	}
+	copy(d[:], b)
+	return d, nil
+}
+
+// MarshalText encodes d as hex, so it appears as a JSON string.
+func (d Digest) MarshalText() ([]byte, error) {
+	return []byte(d.Hex()), nil
+}
+
+// UnmarshalText decodes a hex string.
+func (d *Digest) UnmarshalText(text []byte) error {
+	parsed, err := ParseHex(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,92 @@
+package digest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// sha256("abc") from FIPS 180-2.
+const abcHex = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+
+func TestSum(t *testing.T) {
+	d := Sum([]byte("abc"))
+	if d.Hex() != abcHex || d.String() != abcHex {
+		t.Fatalf("Sum(abc) = %s", d)
+	}
+	r, err := SumReader(strings.NewReader("abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r != d {
+		t.Fatal("SumReader disagrees with Sum")
+	}
+}
+
+func TestEncodingsRoundTrip(t *testing.T) {
+	d := Sum([]byte("hello"))
+
+	fromHex, err := ParseHex(d.Hex())
+	if err != nil || fromHex != d {
+		t.Fatalf("ParseHex round trip: %v, %v", fromHex, err)
+	}
+	fromB64, err := ParseBase64(d.Base64())
+	if err != nil || fromB64 != d {
+		t.Fatalf("ParseBase64 round trip: %v, %v", fromB64, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, s := range []string{"", "abc", strings.Repeat("z", 64), abcHex + "00"} {
+		if _, err := ParseHex(s); err == nil {
+			t.Errorf("ParseHex(%q) succeeded", s)
+		}
+	}
+	for _, s := range []string{"!!", "YWJj"} {
+		if _, err := ParseBase64(s); err == nil {
+			t.Errorf("ParseBase64(%q) succeeded", s)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+		Hash Digest `json:"hash"`
+	}
+	in := item{Name: "abc", Hash: Sum([]byte("abc"))}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"abc","hash":"` + abcHex + `"}`; string(data) != want {
+		t.Fatalf("Marshal = %s", data)
+	}
+
+	var out item
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Fatalf("round trip = %+v", out)
+	}
+	if err := json.Unmarshal([]byte(`{"hash":"nope"}`), &out); err == nil {
+		t.Fatal("Unmarshal accepted an invalid digest")
+	}
+}
+
+func TestEqualAndZero(t *testing.T) {
+	a, b := Sum([]byte("a")), Sum([]byte("b"))
+	if !a.Equal(a) || a.Equal(b) {
+		t.Fatal("Equal gave the wrong answer")
+	}
+	if a.IsZero() || !(Digest{}).IsZero() {
+		t.Fatal("IsZero gave the wrong answer")
+	}
+
+	// Digest is comparable, so it works directly as a map key.
+	seen := map[Digest]string{a: "a"}
+	if seen[Sum([]byte("a"))] != "a" {
+		t.Fatal("map lookup by digest failed")
+	}
+}
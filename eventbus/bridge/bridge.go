@@ -0,0 +1,284 @@
+// Package bridge carries eventbus events out of the process: to browsers
+// as Server-Sent Events and to webhook endpoints as delivery jobs. Each
+// consumer can narrow what it receives with a Filter.
+package bridge
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/cryptox"
+	"github.com/stawuah/pounce-on-go/eventbus"
+	"github.com/stawuah/pounce-on-go/retry"
+	"github.com/stawuah/pounce-on-go/tracing"
+)
+
+// Filter reports whether an event should be forwarded. A nil Filter
+// forwards everything.
+type Filter[T any] func(T) bool
+
+func (f Filter[T]) match(v T) bool { return f == nil || f(v) }
+
+// And returns a filter matching events that match every one of fs.
+func And[T any](fs ...Filter[T]) Filter[T] {
+	return func(v T) bool {
+		for _, f := range fs {
+			if !f.match(v) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// WriteFrame writes one SSE frame. Multi-line data is split across
+// several data: lines as the spec requires; an empty event or id is
+// omitted.
+func WriteFrame(w io.Writer, event, id string, data []byte) error {
+	var b strings.Builder
+	if event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event)
+	}
+	if id != "" {
+		fmt.Fprintf(&b, "id: %s\n", id)
+	}
+	for line := range strings.SplitSeq(string(data), "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Frame is one SSE frame as read by ReadFrames.
+type Frame struct {
+	Event string
+	ID    string
+	Data  []byte
+}
+
+// ReadFrames reads an SSE stream, such as SSE serves, and calls fn with
+// each frame that carries data, until r ends or fn fails. Comments and
+// fields other than event, id and data are ignored, as the spec says; a
+// frame cut off by the end of the stream is dropped.
+func ReadFrames(r io.Reader, fn func(Frame) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 4096), 1<<20)
+	var (
+		f       Frame
+		data    []string
+		hasData bool
+	)
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if line == "" {
+			if hasData {
+				f.Data = []byte(strings.Join(data, "\n"))
+				if err := fn(f); err != nil {
+					return err
+				}
+			}
+			f, data, hasData = Frame{}, data[:0], false
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			f.Event = value
+		case "id":
+			f.ID = value
+		case "data":
+			data, hasData = append(data, value), true
+		}
+	}
+	return sc.Err()
+}
+
+// SSE streams a topic's events to HTTP clients as JSON-encoded SSE
+// frames. Every request gets its own subscription with the Drop policy,
+// so a slow browser misses events rather than stalling publishers.
+type SSE[T any] struct {
+	Topic *eventbus.Topic[T]
+	// Event names the frames; it defaults to the topic name.
+	Event string
+	// Buffer is each client's subscription buffer. It defaults to 16.
+	Buffer int
+	// FilterFor, if set, builds a per-client filter from the request,
+	// e.g. from query parameters. An error is answered with 400.
+	FilterFor func(*http.Request) (Filter[T], error)
+}
+
+func (s *SSE[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var filter Filter[T]
+	if s.FilterFor != nil {
+		var err error
+		if filter, err = s.FilterFor(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	event := s.Event
+	if event == "" {
+		event = s.Topic.Name()
+	}
+	buffer := s.Buffer
+	if buffer <= 0 {
+		buffer = 16
+	}
+
+	sub, err := s.Topic.Subscribe(buffer, eventbus.Drop)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer sub.Unsubscribe()
+
+	rc := http.NewResponseController(w)
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	for {
+		select {
+		case v, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if !filter.match(v) {
+				continue
+			}
+			data, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+			if WriteFrame(w, event, "", data) != nil || rc.Flush() != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// Webhook is a subscriber endpoint and the events it wants. Deliveries
+// to a webhook with a Secret are signed with it; see cryptox.Sign.
+type Webhook[T any] struct {
+	URL    string
+	Filter Filter[T]
+	Secret []byte
+}
+
+// EventHeader names the topic a webhook delivery came from.
+const EventHeader = "X-Pounce-Event"
+
+// Job is one webhook delivery waiting to be sent. It encodes as JSON
+// so a job queue can store it, except for Secret: a stored job must not
+// put the key on disk, so whoever delivers it sets Secret again.
+type Job struct {
+	URL    string          `json:"url"`
+	Event  string          `json:"event"`
+	Body   json.RawMessage `json:"body"`
+	Secret []byte          `json:"-"`
+	// TraceParent continues the trace of the request that caused the
+	// event. Forward copies it from events that have a TraceParent
+	// method.
+	TraceParent string `json:"traceparent,omitempty"`
+}
+
+// NewRequest builds the POST that delivers j, signed as of now if j has a
+// Secret. It sends j's traceparent, or failing that the one of the span
+// in ctx. A retried delivery should build a fresh request, so that its
+// signature is not rejected as stale.
+func (j Job) NewRequest(ctx context.Context, now time.Time) (*http.Request, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, j.URL, bytes.NewReader(j.Body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(EventHeader, j.Event)
+	if tp := cmp.Or(j.TraceParent, tracing.FromContext(ctx).Traceparent()); tp != "" {
+		r.Header.Set(tracing.TraceparentHeader, tp)
+	}
+	if j.Secret != nil {
+		cryptox.SignRequest(r, j.Secret, now, j.Body)
+	}
+	return r, nil
+}
+
+// Deliver sends j with client, signed as of now. Any 2xx response is
+// success. A transport error, 429 or 5xx is worth retrying; any other
+// status is marked retry.Permanent, since the same body would get the
+// same answer.
+func (j Job) Deliver(ctx context.Context, client *http.Client, now time.Time) error {
+	r, err := j.NewRequest(ctx, now)
+	if err != nil {
+		return retry.Permanent(err)
+	}
+	resp, err := client.Do(r)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16)) // lets the connection be reused
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode/100 == 2:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return fmt.Errorf("bridge: %s answered %s", j.URL, resp.Status)
+	}
+	return retry.Permanent(fmt.Errorf("bridge: %s answered %s", j.URL, resp.Status))
+}
+
+// Forward subscribes to topic and turns every event into one Job per
+// matching webhook, handing each to enqueue. It uses the Block policy so
+// no event is lost while enqueue keeps up. Forward returns nil when the
+// topic closes, ctx.Err() when ctx ends, and the first enqueue error.
+func Forward[T any](ctx context.Context, topic *eventbus.Topic[T], buffer int, hooks []Webhook[T], enqueue func(context.Context, Job) error) error {
+	sub, err := topic.Subscribe(buffer, eventbus.Block)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case v, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			var body []byte
+			for _, h := range hooks {
+				if !h.Filter.match(v) {
+					continue
+				}
+				if body == nil {
+					if body, err = json.Marshal(v); err != nil {
+						return fmt.Errorf("bridge: encoding %s event: %w", topic.Name(), err)
+					}
+				}
+				job := Job{URL: h.URL, Event: topic.Name(), Body: body, Secret: h.Secret}
+				if t, ok := any(v).(interface{ TraceParent() string }); ok {
+					job.TraceParent = t.TraceParent()
+				}
+				if err := enqueue(ctx, job); err != nil {
+					return err
+				}
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
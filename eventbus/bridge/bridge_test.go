@@ -0,0 +1,315 @@
+package bridge
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+	"github.com/stawuah/pounce-on-go/concurrency/leaktest"
+	"github.com/stawuah/pounce-on-go/cryptox"
+	"github.com/stawuah/pounce-on-go/eventbus"
+	"github.com/stawuah/pounce-on-go/retry"
+	"github.com/stawuah/pounce-on-go/tracing"
+)
+
+type priceChange struct {
+	SKU string  `json:"sku"`
+	Old float64 `json:"old"`
+	New float64 `json:"new"`
+}
+
+// changedBy matches price moves of at least pct percent either way.
+func changedBy(pct float64) Filter[priceChange] {
+	return func(c priceChange) bool {
+		return c.Old != 0 && math.Abs(c.New-c.Old)/c.Old*100 >= pct
+	}
+}
+
+func waitForSubscribers(t *testing.T, topic interface{ Len() int }, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for topic.Len() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("subscribers never reached %d", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWriteFrame(t *testing.T) {
+	var buf bytes.Buffer
+	WriteFrame(&buf, "price", "7", []byte("a\nb"))
+	want := "event: price\nid: 7\ndata: a\ndata: b\n\n"
+	if buf.String() != want {
+		t.Fatalf("frame = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	WriteFrame(&buf, "", "", []byte("{}"))
+	if buf.String() != "data: {}\n\n" {
+		t.Fatalf("frame = %q", buf.String())
+	}
+}
+
+func TestReadFrames(t *testing.T) {
+	var buf bytes.Buffer
+	WriteFrame(&buf, "price", "7", []byte("a\nb"))
+	buf.WriteString(": a comment\r\nretry: 10\r\n\r\n") // no data: no frame
+	WriteFrame(&buf, "", "", []byte("{}"))
+	buf.WriteString("data: cut off")
+
+	var got []Frame
+	err := ReadFrames(&buf, func(f Frame) error {
+		got = append(got, f)
+		return nil
+	})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("read %+v, %v", got, err)
+	}
+	if f := got[0]; f.Event != "price" || f.ID != "7" || string(f.Data) != "a\nb" {
+		t.Fatalf("first frame = %+v", f)
+	}
+	if f := got[1]; f.Event != "" || string(f.Data) != "{}" {
+		t.Fatalf("second frame = %+v", f)
+	}
+}
+
+func TestSSEStreamsFilteredEvents(t *testing.T) {
+	leaktest.Check(t)
+	topic := eventbus.NewTopic[priceChange]("price")
+	defer topic.Close()
+
+	srv := httptest.NewServer(&SSE[priceChange]{
+		Topic: topic,
+		FilterFor: func(r *http.Request) (Filter[priceChange], error) {
+			s := r.URL.Query().Get("min_pct")
+			if s == "" {
+				return nil, nil
+			}
+			pct, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, fmt.Errorf("bad min_pct: %w", err)
+			}
+			return changedBy(pct), nil
+		},
+	})
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"?min_pct=10", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	waitForSubscribers(t, topic, 1)
+	topic.Publish(ctx, priceChange{"A1", 100, 101}) // 1%: filtered out
+	topic.Publish(ctx, priceChange{"B2", 100, 80})  // 20%: forwarded
+
+	r := bufio.NewReader(resp.Body)
+	var frame []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line == "\n" {
+			break
+		}
+		frame = append(frame, strings.TrimSuffix(line, "\n"))
+	}
+	want := []string{"event: price", `data: {"sku":"B2","old":100,"new":80}`}
+	if strings.Join(frame, "|") != strings.Join(want, "|") {
+		t.Fatalf("frame = %q, want %q", frame, want)
+	}
+
+	cancel()
+	waitForSubscribers(t, topic, 0)
+}
+
+func TestSSEBadFilter(t *testing.T) {
+	topic := eventbus.NewTopic[priceChange]("price")
+	h := &SSE[priceChange]{
+		Topic:     topic,
+		FilterFor: func(*http.Request) (Filter[priceChange], error) { return nil, errors.New("nope") },
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+	if topic.Len() != 0 {
+		t.Fatal("subscription left behind")
+	}
+}
+
+func TestForward(t *testing.T) {
+	leaktest.Check(t)
+	topic := eventbus.NewTopic[priceChange]("price")
+	hooks := []Webhook[priceChange]{
+		{URL: "https://all.example"},
+		{URL: "https://big.example", Filter: changedBy(25)},
+		{URL: "https://cheap.example", Filter: And(changedBy(5), func(c priceChange) bool { return c.New < 10 })},
+	}
+
+	var jobs []Job
+	done := make(chan error)
+	go func() {
+		done <- Forward(context.Background(), topic, 4, hooks, func(_ context.Context, j Job) error {
+			jobs = append(jobs, j)
+			return nil
+		})
+	}()
+	waitForSubscribers(t, topic, 1)
+
+	topic.Publish(context.Background(), priceChange{"A1", 100, 110})
+	topic.Publish(context.Background(), priceChange{"B2", 100, 50})
+	topic.Publish(context.Background(), priceChange{"C3", 8, 9})
+	topic.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, j := range jobs {
+		got = append(got, j.URL)
+		if j.Event != "price" {
+			t.Fatalf("job event = %q", j.Event)
+		}
+	}
+	want := "https://all.example https://all.example https://big.example https://all.example https://cheap.example"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("jobs = %v", got)
+	}
+	if string(jobs[0].Body) != `{"sku":"A1","old":100,"new":110}` {
+		t.Fatalf("body = %s", jobs[0].Body)
+	}
+}
+
+func TestForwardStopsOnEnqueueError(t *testing.T) {
+	topic := eventbus.NewTopic[priceChange]("price")
+	defer topic.Close()
+	errFull := errors.New("queue full")
+
+	done := make(chan error)
+	go func() {
+		done <- Forward(context.Background(), topic, 1, []Webhook[priceChange]{{URL: "x"}},
+			func(context.Context, Job) error { return errFull })
+	}()
+	waitForSubscribers(t, topic, 1)
+	topic.Publish(context.Background(), priceChange{})
+	if err := <-done; !errors.Is(err, errFull) {
+		t.Fatalf("Forward() = %v, want errFull", err)
+	}
+}
+
+func TestJobNewRequest(t *testing.T) {
+	now := time.Unix(1_741_339_800, 0)
+	secret := []byte("whsec")
+	j := Job{URL: "https://hooks.example/price", Event: "price", Body: []byte(`{"sku":"A1"}`), Secret: secret}
+
+	r, err := j.NewRequest(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Method != "POST" || r.URL.String() != j.URL || r.Header.Get(EventHeader) != "price" {
+		t.Fatalf("request = %s %s, event %q", r.Method, r.URL, r.Header.Get(EventHeader))
+	}
+	body, _ := io.ReadAll(r.Body)
+	v := &cryptox.Verifier{Secrets: [][]byte{secret}, Clock: clock.NewFake(now)}
+	if err := v.Verify(r.Header.Get(cryptox.SignatureHeader), body); err != nil {
+		t.Fatalf("Verify = %v", err)
+	}
+
+	if r.Header.Get(tracing.TraceparentHeader) != "" {
+		t.Fatalf("untraced job sent traceparent %q", r.Header.Get(tracing.TraceparentHeader))
+	}
+
+	j.Secret = nil
+	if r, _ := j.NewRequest(context.Background(), now); r.Header.Get(cryptox.SignatureHeader) != "" {
+		t.Fatal("unsigned job sent a signature")
+	}
+
+	// The job's own trace wins over the context's.
+	ctx, span := tracing.NewTracer(1).StartTrace(context.Background(), "deliver", "")
+	if r, _ := j.NewRequest(ctx, now); r.Header.Get(tracing.TraceparentHeader) != span.Traceparent() {
+		t.Fatalf("traceparent = %q, want the context's span", r.Header.Get(tracing.TraceparentHeader))
+	}
+	j.TraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	if r, _ := j.NewRequest(ctx, now); r.Header.Get(tracing.TraceparentHeader) != j.TraceParent {
+		t.Fatalf("traceparent = %q, want the job's", r.Header.Get(tracing.TraceparentHeader))
+	}
+}
+
+type tracedChange struct {
+	priceChange
+	Trace string `json:"-"`
+}
+
+func (c tracedChange) TraceParent() string { return c.Trace }
+
+func TestForwardCarriesTrace(t *testing.T) {
+	topic := eventbus.NewTopic[tracedChange]("price")
+	jobs := make(chan Job, 1)
+	done := make(chan error)
+	go func() {
+		done <- Forward(context.Background(), topic, 1, []Webhook[tracedChange]{{URL: "x"}},
+			func(_ context.Context, j Job) error { jobs <- j; return nil })
+	}()
+	waitForSubscribers(t, topic, 1)
+	topic.Publish(context.Background(), tracedChange{priceChange{"A1", 1, 2}, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"})
+	topic.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if j := <-jobs; j.TraceParent != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Fatalf("job TraceParent = %q", j.TraceParent)
+	}
+}
+
+func TestJobDeliver(t *testing.T) {
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	j := Job{URL: srv.URL, Event: "price", Body: []byte(`{}`)}
+
+	for _, tc := range []struct {
+		status           int
+		fails, permanent bool
+	}{
+		{http.StatusNoContent, false, false},
+		{http.StatusServiceUnavailable, true, false},
+		{http.StatusTooManyRequests, true, false},
+		{http.StatusGone, true, true},
+	} {
+		status = tc.status
+		err := j.Deliver(context.Background(), srv.Client(), time.Now())
+		if (err != nil) != tc.fails || retry.IsPermanent(err) != tc.permanent {
+			t.Errorf("status %d: Deliver = %v", tc.status, err)
+		}
+	}
+
+	stored, _ := json.Marshal(Job{URL: "u", Event: "e", Body: []byte(`{}`), Secret: []byte("whsec")})
+	if strings.Contains(string(stored), "whsec") || strings.Contains(string(stored), "Secret") {
+		t.Fatalf("encoded job leaks its secret: %s", stored)
+	}
+}
@@ -0,0 +1,149 @@
+// Package eventbus delivers typed events from publishers to subscribers.
+//
+// A Topic[T] carries one payload type, so subscribers receive T values
+// without type assertions. Each subscriber has its own buffered channel
+// and chooses what happens when it falls behind: Drop discards events
+// for that subscriber alone, Block makes publishers wait for it.
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClosed is returned by Publish and Subscribe after Close.
+var ErrClosed = errors.New("eventbus: topic closed")
+
+// Policy decides what Publish does when a subscriber's buffer is full.
+type Policy int
+
+const (
+	// Drop skips the event for the full subscriber and counts it.
+	Drop Policy = iota
+	// Block waits until the subscriber has room, the publisher's context
+	// ends, or the subscriber goes away.
+	Block
+)
+
+// Topic fans events of type T out to its subscribers.
+type Topic[T any] struct {
+	name string
+
+	mu     sync.RWMutex
+	subs   map[*Subscription[T]]struct{}
+	closed bool
+}
+
+// NewTopic returns a topic with no subscribers.
+func NewTopic[T any](name string) *Topic[T] {
+	return &Topic[T]{name: name, subs: make(map[*Subscription[T]]struct{})}
+}
+
+// Name returns the name given to NewTopic.
+func (t *Topic[T]) Name() string { return t.name }
+
+// Subscription is one subscriber's view of a topic. Events arrive on C,
+// which is closed on Unsubscribe or when the topic closes.
+type Subscription[T any] struct {
+	C <-chan T
+
+	topic   *Topic[T]
+	ch      chan T
+	policy  Policy
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Int64
+}
+
+// Subscribe registers a subscriber with room for buffer undelivered
+// events.
+func (t *Topic[T]) Subscribe(buffer int, policy Policy) (*Subscription[T], error) {
+	ch := make(chan T, buffer)
+	s := &Subscription[T]{C: ch, topic: t, ch: ch, policy: policy, done: make(chan struct{})}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil, ErrClosed
+	}
+	t.subs[s] = struct{}{}
+	return s, nil
+}
+
+// Dropped returns how many events this subscriber missed under Drop.
+func (s *Subscription[T]) Dropped() int64 { return s.dropped.Load() }
+
+// Unsubscribe stops delivery and closes C. Events already buffered stay
+// readable. It is safe to call more than once.
+func (s *Subscription[T]) Unsubscribe() {
+	// Closing done first releases any publisher blocked on this
+	// subscriber, which may be holding the read lock we need.
+	s.once.Do(func() { close(s.done) })
+
+	t := s.topic
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.subs[s]; ok {
+		delete(t.subs, s)
+		close(s.ch)
+	}
+}
+
+// Len returns the number of subscribers.
+func (t *Topic[T]) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.subs)
+}
+
+// Publish delivers v to every subscriber according to its policy. It
+// returns ctx.Err() if ctx ends while blocked on a subscriber; subscribers
+// already served keep the event.
+func (t *Topic[T]) Publish(ctx context.Context, v T) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.closed {
+		return ErrClosed
+	}
+	for s := range t.subs {
+		switch s.policy {
+		case Drop:
+			select {
+			case s.ch <- v:
+			default:
+				s.dropped.Add(1)
+			}
+		case Block:
+			select {
+			case s.ch <- v:
+			case <-s.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// Close unsubscribes everyone, closing their channels, and makes later
+// Publish and Subscribe calls fail with ErrClosed.
+func (t *Topic[T]) Close() {
+	t.mu.RLock()
+	for s := range t.subs {
+		s.once.Do(func() { close(s.done) })
+	}
+	t.mu.RUnlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	for s := range t.subs {
+		close(s.ch)
+		delete(t.subs, s)
+	}
+}
@@ -0,0 +1,158 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type productChanged struct {
+	SKU   string
+	Price float64
+}
+
+func drain[T any](c <-chan T) []T {
+	var out []T
+	for v := range c {
+		out = append(out, v)
+	}
+	return out
+}
+
+func TestPublishReachesAllSubscribers(t *testing.T) {
+	topic := NewTopic[productChanged]("products")
+	sse, _ := topic.Subscribe(8, Drop)
+	audit, _ := topic.Subscribe(8, Block)
+
+	events := []productChanged{{"A1", 1.5}, {"B2", 3}}
+	for _, e := range events {
+		if err := topic.Publish(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	topic.Close()
+
+	for name, sub := range map[string]*Subscription[productChanged]{"sse": sse, "audit": audit} {
+		if got := drain(sub.C); !slices.Equal(got, events) {
+			t.Errorf("%s got %v, want %v", name, got, events)
+		}
+	}
+}
+
+func TestDropPolicy(t *testing.T) {
+	topic := NewTopic[int]("n")
+	slow, _ := topic.Subscribe(2, Drop)
+	for i := range 5 {
+		topic.Publish(context.Background(), i)
+	}
+	if d := slow.Dropped(); d != 3 {
+		t.Fatalf("Dropped() = %d, want 3", d)
+	}
+	slow.Unsubscribe()
+	if got := drain(slow.C); !slices.Equal(got, []int{0, 1}) {
+		t.Fatalf("buffered = %v, want [0 1]", got)
+	}
+}
+
+func TestBlockPolicyWaitsForConsumer(t *testing.T) {
+	topic := NewTopic[int]("n")
+	sub, _ := topic.Subscribe(0, Block)
+
+	published := make(chan error)
+	go func() { published <- topic.Publish(context.Background(), 42) }()
+
+	select {
+	case <-published:
+		t.Fatal("Publish returned before the subscriber received")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if v := <-sub.C; v != 42 {
+		t.Fatalf("received %d", v)
+	}
+	if err := <-published; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBlockPolicyHonoursContext(t *testing.T) {
+	topic := NewTopic[int]("n")
+	topic.Subscribe(0, Block)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := topic.Publish(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Publish() = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestUnsubscribeReleasesBlockedPublisher(t *testing.T) {
+	topic := NewTopic[int]("n")
+	sub, _ := topic.Subscribe(0, Block)
+
+	published := make(chan error)
+	go func() { published <- topic.Publish(context.Background(), 1) }()
+	time.Sleep(5 * time.Millisecond)
+
+	sub.Unsubscribe()
+	if err := <-published; err != nil {
+		t.Fatal(err)
+	}
+	if topic.Len() != 0 {
+		t.Fatalf("Len() = %d after Unsubscribe", topic.Len())
+	}
+	sub.Unsubscribe() // idempotent
+}
+
+func TestClose(t *testing.T) {
+	topic := NewTopic[string]("n")
+	sub, _ := topic.Subscribe(0, Block)
+
+	published := make(chan error)
+	go func() { published <- topic.Publish(context.Background(), "x") }()
+	time.Sleep(5 * time.Millisecond)
+
+	topic.Close()
+	if err := <-published; err != nil {
+		t.Fatalf("in-flight Publish() = %v", err)
+	}
+	if _, ok := <-sub.C; ok {
+		t.Fatal("C not closed")
+	}
+	if err := topic.Publish(context.Background(), "y"); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Publish after Close = %v", err)
+	}
+	if _, err := topic.Subscribe(1, Drop); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Subscribe after Close = %v", err)
+	}
+	sub.Unsubscribe()
+	topic.Close()
+}
+
+func TestConcurrentPublishSubscribe(t *testing.T) {
+	topic := NewTopic[int]("n")
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				topic.Publish(context.Background(), i)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				sub, err := topic.Subscribe(4, Drop)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				sub.Unsubscribe()
+			}
+		}()
+	}
+	wg.Wait()
+	topic.Close()
+}
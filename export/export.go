@@ -0,0 +1,57 @@
+// Package export writes slices of records as CSV or JSON. Columns are
+// described once with accessor functions, so any type can be exported
+// without struct tags or reflection.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// Column names one CSV column and how to read it from a record.
+type Column[T any] struct {
+	Name  string
+	Value func(T) string
+}
+
+// Float returns a column rendering a float with prec decimals.
+func Float[T any](name string, prec int, f func(T) float64) Column[T] {
+	return Column[T]{Name: name, Value: func(v T) string {
+		return strconv.FormatFloat(f(v), 'f', prec, 64)
+	}}
+}
+
+// CSV writes a header row of column names followed by one row per record.
+func CSV[T any](w io.Writer, records []T, cols []Column[T]) error {
+	cw := csv.NewWriter(w)
+	row := make([]string, len(cols))
+	for i, c := range cols {
+		row[i] = c.Name
+	}
+	if err := cw.Write(row); err != nil {
+		return err
+	}
+	for _, r := range records {
+		for i, c := range cols {
+			row[i] = c.Value(r)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// JSON writes records as an indented JSON array followed by a newline. A
+// nil slice is written as [] rather than null.
+func JSON[T any](w io.Writer, records []T) error {
+	if records == nil {
+		records = []T{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
@@ -0,0 +1,128 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stawuah/pounce-on-go/geometry"
+	"github.com/stawuah/pounce-on-go/golden"
+	"github.com/stawuah/pounce-on-go/sliceutil"
+)
+
+// shapes builds the fixture, failing the test if any shape is invalid.
+// t.Helper makes a failure point at the caller's line.
+func shapes(t *testing.T) []geometry.Shape {
+	t.Helper()
+	out := []geometry.Shape{
+		geometry.Rectangle{Width: 3, Height: 4},
+		geometry.Circle{Radius: 1},
+		geometry.Triangle{A: 3, B: 4, C: 5},
+	}
+	for _, s := range out {
+		if v, ok := s.(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				t.Fatalf("fixture %T: %v", s, err)
+			}
+		}
+	}
+	return out
+}
+
+// createFile opens a file in a per-test directory and registers its
+// Close with t.Cleanup, so the test body cannot forget it.
+func createFile(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Create(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+var shapeColumns = []Column[geometry.Shape]{
+	{Name: "kind", Value: func(s geometry.Shape) string { return fmt.Sprintf("%T", s) }},
+	Float("area", 3, geometry.Shape.Area),
+	Float("perimeter", 3, geometry.Shape.Perimeter),
+}
+
+func TestShapesCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := CSV(&buf, shapes(t), shapeColumns); err != nil {
+		t.Fatal(err)
+	}
+	golden.Assert(t, "shapes.csv", buf.Bytes())
+}
+
+type shapeSummary struct {
+	Kind      string  `json:"kind"`
+	Area      float64 `json:"area"`
+	Perimeter float64 `json:"perimeter"`
+}
+
+func TestShapesJSON(t *testing.T) {
+	summaries := sliceutil.Map(shapes(t), func(s geometry.Shape) shapeSummary {
+		return shapeSummary{fmt.Sprintf("%T", s), s.Area(), s.Perimeter()}
+	})
+	f := createFile(t, "shapes.json")
+	if err := JSON(f, summaries); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden.Assert(t, "shapes.json", got)
+}
+
+func TestBatchedCSV(t *testing.T) {
+	// Large exports go out in fixed-size files; each batch gets its own
+	// golden file.
+	nums := []int{1, 2, 3, 4, 5}
+	cols := []Column[int]{
+		{Name: "n", Value: func(n int) string { return fmt.Sprint(n) }},
+		{Name: "square", Value: func(n int) string { return fmt.Sprint(n * n) }},
+	}
+	for i, batch := range sliceutil.Chunk(nums, 2) {
+		t.Run(fmt.Sprintf("batch%d", i), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := CSV(&buf, batch, cols); err != nil {
+				t.Fatal(err)
+			}
+			golden.Assert(t, fmt.Sprintf("squares-%d.csv", i), buf.Bytes())
+		})
+	}
+}
+
+func TestCSVQuoting(t *testing.T) {
+	cols := []Column[string]{{Name: "value", Value: func(s string) string { return s }}}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "value\nplain\n"},
+		{"a,b", "value\n\"a,b\"\n"},
+		{`say "hi"`, "value\n\"say \"\"hi\"\"\"\n"},
+		{"two\nlines", "value\n\"two\nlines\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var buf bytes.Buffer
+			CSV(&buf, []string{tt.in}, cols)
+			if buf.String() != tt.want {
+				t.Fatalf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONNil(t *testing.T) {
+	var buf bytes.Buffer
+	JSON[int](&buf, nil)
+	if buf.String() != "[]\n" {
+		t.Fatalf("got %q", buf.String())
+	}
+}
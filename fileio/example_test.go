@@ -0,0 +1,45 @@
+package fileio_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/stawuah/pounce-on-go/fileio"
+)
+
+type product struct {
+	SKU   string  `json:"sku"`
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+// Totting up a product dump one line at a time: memory use depends on the
+// longest line, not on the size of the file.
+func ExampleDecodeLines() {
+	dump := strings.NewReader(`{"sku":"ANV-1","name":"Anvil","price":120}
+{"sku":"ROC-2","name":"Rocket skates","price":310.5}
+
+{"sku":"MAG-3","name":"Giant magnet","price":89.99}
+`)
+	var n int
+	var total float64
+	err := fileio.DecodeLines(dump, func(p product) error {
+		n++
+		total += p.Price
+		return nil
+	})
+	fmt.Println(n, total, err)
+	// Output: 3 520.49 <nil>
+}
+
+func ExampleCopyProgress() {
+	src := strings.NewReader(strings.Repeat("x", 80_000))
+	fileio.CopyProgress(io.Discard, struct{ io.Reader }{src}, 80_000, func(p fileio.Progress) {
+		fmt.Printf("%d/%d %.0f%%\n", p.Written, p.Total, p.Percent())
+	})
+	// Output:
+	// 32768/80000 41%
+	// 65536/80000 82%
+	// 80000/80000 100%
+}
@@ -0,0 +1,106 @@
+// Package fileio reads and writes product files too large to hold in
+// memory. Readers stream a line at a time through a bufio.Scanner,
+// writers go through a bufio.Writer, and a file that replaces an older
+// version is written beside it and renamed into place, so readers see
+// either the old file or the whole new one.
+//
+// The line format is NDJSON: one JSON value per line. Blank lines are
+// skipped, and errors carry the line number they came from.
+package fileio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+)
+
+// MaxLineBytes bounds a single line. A longer line fails with
+// bufio.ErrTooLong rather than growing the buffer without limit.
+const MaxLineBytes = 1 << 20
+
+// WriteAtomic replaces the file at path with whatever write produces. The
+// data goes to a temporary file in the same directory, which is synced
+// and renamed over path only once write and every flush have succeeded;
+// on failure the temporary file is removed and path is left untouched.
+func WriteAtomic(path string, perm os.FileMode, write func(io.Writer) error) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("fileio: write %s: %w", path, err)
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+			err = fmt.Errorf("fileio: write %s: %w", path, err)
+		}
+	}()
+
+	bw := bufio.NewWriter(f)
+	if err := write(bw); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// EachLine calls fn with every non-blank line of r, without its line
+// ending. The slice is only valid until fn returns. The first error from
+// fn or from reading stops the scan and is returned with its line number.
+func EachLine(r io.Reader, fn func(line []byte) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), MaxLineBytes)
+	n := 0
+	for sc.Scan() {
+		n++
+		line := sc.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("line %d: %w", n+1, err)
+	}
+	return nil
+}
+
+// DecodeLines decodes each line of r as a T and passes it to fn.
+func DecodeLines[T any](r io.Reader, fn func(T) error) error {
+	return EachLine(r, func(line []byte) error {
+		var v T
+		if err := json.Unmarshal(line, &v); err != nil {
+			return err
+		}
+		return fn(v)
+	})
+}
+
+// EncodeLines writes each value of seq to w as one line of JSON.
+func EncodeLines[T any](w io.Writer, seq iter.Seq[T]) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for v := range seq {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,174 @@
+package fileio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type product struct {
+	SKU   string  `json:"sku"`
+	Price float64 `json:"price"`
+}
+
+func TestWriteAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "products.ndjson")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	boom := errors.New("boom")
+	err := WriteAtomic(path, 0o600, func(w io.Writer) error {
+		io.WriteString(w, "half a file")
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("WriteAtomic() = %v, want %v", err, boom)
+	}
+	assertFile(t, path, "old\n")
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("temporary file left behind: %v", entries)
+	}
+
+	if err := WriteAtomic(path, 0o600, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new\n")
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	assertFile(t, path, "new\n")
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Fatalf("Stat() = %v, %v; want mode 0600", fi.Mode(), err)
+	}
+}
+
+func TestWriteAtomicMissingDir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "out")
+	if err := WriteAtomic(path, 0o644, func(io.Writer) error { return nil }); err == nil {
+		t.Fatal("WriteAtomic() into a missing directory succeeded")
+	}
+}
+
+func assertFile(t *testing.T, path, want string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Fatalf("%s = %q, want %q", path, got, want)
+	}
+}
+
+func TestLinesRoundTrip(t *testing.T) {
+	in := []product{{"A-1", 1.5}, {"B-2", 20}, {"C-3", 0}}
+	var buf bytes.Buffer
+	if err := EncodeLines(&buf, slices.Values(in)); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != len(in) {
+		t.Fatalf("wrote %d lines, want %d:\n%s", got, len(in), buf.String())
+	}
+
+	var out []product
+	if err := DecodeLines(&buf, func(p product) error {
+		out = append(out, p)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(out, in) {
+		t.Fatalf("decoded %v, want %v", out, in)
+	}
+}
+
+func TestDecodeLinesErrors(t *testing.T) {
+	stop := errors.New("stop")
+	tests := []struct {
+		name  string
+		input string
+		fn    func(product) error
+		want  string
+		count int
+	}{
+		{"blank lines skipped", "\n{\"sku\":\"A\"}\n  \r\n{\"sku\":\"B\"}\r\n", nil, "", 2},
+		{"bad json", "{\"sku\":\"A\"}\n{\"sku\":\n", nil, "line 2: unexpected end of JSON input", 1},
+		{"callback error", "{\"sku\":\"A\"}\n\n{\"sku\":\"B\"}\n", func(p product) error {
+			if p.SKU == "B" {
+				return stop
+			}
+			return nil
+		}, "line 3: stop", 2},
+		{"line too long", "{\"sku\":\"A\"}\n" + strings.Repeat("x", MaxLineBytes+1), nil, "line 2: " + bufio.ErrTooLong.Error(), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count := 0
+			err := DecodeLines(strings.NewReader(tt.input), func(p product) error {
+				count++
+				if tt.fn != nil {
+					return tt.fn(p)
+				}
+				return nil
+			})
+			if got := errString(err); got != tt.want {
+				t.Errorf("err = %q, want %q", got, tt.want)
+			}
+			if count != tt.count {
+				t.Errorf("fn called %d times, want %d", count, tt.count)
+			}
+		})
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestCopyProgress(t *testing.T) {
+	src := strings.Repeat("x", 100_000)
+	var reports []Progress
+	var dst bytes.Buffer
+	// Hide strings.Reader's WriteTo so the copy goes in 32 KiB chunks.
+	n, err := CopyProgress(&dst, struct{ io.Reader }{strings.NewReader(src)}, int64(len(src)), func(p Progress) {
+		reports = append(reports, p)
+	})
+	if err != nil || n != int64(len(src)) || dst.String() != src {
+		t.Fatalf("CopyProgress() = %d, %v", n, err)
+	}
+	if len(reports) < 3 {
+		t.Fatalf("got %d reports, want one per chunk", len(reports))
+	}
+	for i := 1; i < len(reports); i++ {
+		if reports[i].Written < reports[i-1].Written {
+			t.Fatalf("progress went backwards: %v", reports)
+		}
+	}
+	if last := reports[len(reports)-1]; last.Written != int64(len(src)) || last.Percent() != 100 {
+		t.Fatalf("last report = %+v (%.0f%%), want all of it", last, last.Percent())
+	}
+}
+
+func TestCopyProgressEmpty(t *testing.T) {
+	var reports []Progress
+	CopyProgress(io.Discard, strings.NewReader(""), 0, func(p Progress) { reports = append(reports, p) })
+	if len(reports) != 1 || reports[0] != (Progress{}) {
+		t.Fatalf("reports = %v, want one zero Progress", reports)
+	}
+}
+
+func TestPercentUnknownTotal(t *testing.T) {
+	if got := (Progress{Written: 10, Total: -1}).Percent(); got != 0 {
+		t.Fatalf("Percent() = %v, want 0", got)
+	}
+}
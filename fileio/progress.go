@@ -0,0 +1,47 @@
+package fileio
+
+import "io"
+
+// Progress is how far a copy has got. Total is -1 when the size is not
+// known in advance.
+type Progress struct {
+	Written int64
+	Total   int64
+}
+
+// Percent returns Written as a percentage of Total, or 0 if Total is not
+// known.
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return 100 * float64(p.Written) / float64(p.Total)
+}
+
+// CopyProgress copies src to dst like io.Copy, calling report after each
+// chunk is written, so the last call shows the final count. A copy that
+// writes nothing reports once. total is passed through to report as
+// Progress.Total; use -1 if it is unknown.
+func CopyProgress(dst io.Writer, src io.Reader, total int64, report func(Progress)) (int64, error) {
+	pw := &progressWriter{w: dst, p: Progress{Total: total}, report: report}
+	n, err := io.Copy(pw, src)
+	if n == 0 {
+		report(pw.p)
+	}
+	return n, err
+}
+
+type progressWriter struct {
+	w      io.Writer
+	p      Progress
+	report func(Progress)
+}
+
+func (pw *progressWriter) Write(b []byte) (int, error) {
+	n, err := pw.w.Write(b)
+	pw.p.Written += int64(n)
+	if n > 0 {
+		pw.report(pw.p)
+	}
+	return n, err
+}
@@ -0,0 +1,180 @@
+// Package flags switches features on and off while a program runs.
+//
+// A Flag is on for everyone, off for everyone, or on for a percentage of
+// subjects, such as users. The percentage rollout hashes the flag's name
+// with the subject, so a given user stays in or out as the percentage
+// only grows, and different flags pick different users.
+//
+// A Set holds the flags. Handler lets an operator read and change them
+// over HTTP, Watch reports every change, and Gate hides an endpoint while
+// its flag is off for the caller.
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/stawuah/pounce-on-go/eventbus"
+)
+
+// ErrInvalid is returned for a flag without a name or with a percentage
+// outside 0 to 100.
+var ErrInvalid = errors.New("flags: invalid flag")
+
+// ErrUnknown is returned for a flag the Set does not have.
+var ErrUnknown = errors.New("flags: unknown flag")
+
+// Flag is one feature's setting.
+type Flag struct {
+	Name string `json:"name"`
+	// On turns the feature on for every subject.
+	On bool `json:"on"`
+	// Percent, when On is false, turns the feature on for that share of
+	// subjects.
+	Percent int `json:"percent,omitempty"`
+}
+
+// Enabled reports whether the feature is on for subject.
+func (f Flag) Enabled(subject string) bool {
+	if f.On {
+		return true
+	}
+	return f.Percent > 0 && bucket(f.Name, subject) < f.Percent
+}
+
+// String renders f as Parse reads it.
+func (f Flag) String() string {
+	switch {
+	case f.On:
+		return f.Name
+	case f.Percent > 0:
+		return f.Name + "=" + strconv.Itoa(f.Percent) + "%"
+	}
+	return f.Name + "=off"
+}
+
+func (f Flag) validate() error {
+	if f.Name == "" || strings.ContainsAny(f.Name, ",= /") {
+		return fmt.Errorf("%w: name %q", ErrInvalid, f.Name)
+	}
+	if f.Percent < 0 || f.Percent > 100 {
+		return fmt.Errorf("%w: %s: percent %d is not between 0 and 100", ErrInvalid, f.Name, f.Percent)
+	}
+	return nil
+}
+
+// bucket places subject in one of 100 buckets for the flag name.
+func bucket(name, subject string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(subject))
+	return int(h.Sum32() % 100)
+}
+
+// Parse reads a comma-separated list of flags:
+//
+//	search,beta=25%,legacy=off
+//
+// A bare name is on, name=on and name=off are what they say, and name=N%
+// rolls the feature out to N percent of subjects.
+func Parse(s string) ([]Flag, error) {
+	var fs []Flag
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(item, "=")
+		f := Flag{Name: name}
+		switch value {
+		case "", "on":
+			f.On = true
+		case "off":
+		default:
+			n, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+			if err != nil || !strings.HasSuffix(value, "%") {
+				return nil, fmt.Errorf("%w: %q: want on, off or a percentage such as 25%%", ErrInvalid, item)
+			}
+			f.Percent = n
+		}
+		if err := f.validate(); err != nil {
+			return nil, err
+		}
+		fs = append(fs, f)
+	}
+	return fs, nil
+}
+
+// Set is a program's flags, safe for concurrent use. A feature with no
+// flag in the Set is off.
+type Set struct {
+	mu      sync.RWMutex
+	flags   map[string]Flag
+	changes *eventbus.Topic[Flag]
+}
+
+// New returns a Set holding fs. It panics if one is invalid; flags from
+// outside the program should go through Parse first.
+func New(fs ...Flag) *Set {
+	s := &Set{flags: make(map[string]Flag, len(fs)), changes: eventbus.NewTopic[Flag]("flags")}
+	for _, f := range fs {
+		if err := f.validate(); err != nil {
+			panic(err)
+		}
+		s.flags[f.Name] = f
+	}
+	return s
+}
+
+// Enabled reports whether the feature name is on for subject.
+func (s *Set) Enabled(name, subject string) bool {
+	f, ok := s.Get(name)
+	return ok && f.Enabled(subject)
+}
+
+// Get returns the flag called name.
+func (s *Set) Get(name string) (Flag, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, ok := s.flags[name]
+	return f, ok
+}
+
+// All returns every flag, by name.
+func (s *Set) All() []Flag {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.SortedFunc(maps.Values(s.flags), func(a, b Flag) int { return strings.Compare(a.Name, b.Name) })
+}
+
+// Update adds f or replaces the flag of the same name, and tells watchers
+// if that changed anything.
+func (s *Set) Update(f Flag) error {
+	if err := f.validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	old, ok := s.flags[f.Name]
+	s.flags[f.Name] = f
+	// Published under the lock so watchers see changes in order.
+	if !ok || old != f {
+		s.changes.Publish(context.Background(), f)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Watch subscribes to changes: each Update that changes a flag delivers
+// the new flag. A watcher that falls more than buffer changes behind
+// misses some; it can read All to catch up. Unsubscribe when done.
+func (s *Set) Watch(buffer int) (*eventbus.Subscription[Flag], error) {
+	return s.changes.Subscribe(buffer, eventbus.Drop)
+}
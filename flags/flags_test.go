@@ -0,0 +1,164 @@
+package flags
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	fs, err := Parse(" search, beta=25%,legacy=off,new=on ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range fs {
+		got = append(got, f.String())
+	}
+	if s := strings.Join(got, ","); s != "search,beta=25%,legacy=off,new" {
+		t.Fatalf("Parse = %s", s)
+	}
+	for _, bad := range []string{"beta=25", "beta=x%", "beta=101%", "beta=-1%", "=on", "a b"} {
+		if _, err := Parse(bad); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q) err = %v", bad, err)
+		}
+	}
+}
+
+func TestRollout(t *testing.T) {
+	const users = 10000
+	in := func(f Flag) map[string]bool {
+		m := make(map[string]bool)
+		for i := range users {
+			if u := strconv.Itoa(i); f.Enabled(u) {
+				m[u] = true
+			}
+		}
+		return m
+	}
+	for _, tc := range []struct {
+		flag     Flag
+		min, max int
+	}{
+		{Flag{Name: "x"}, 0, 0},
+		{Flag{Name: "x", On: true}, users, users},
+		{Flag{Name: "x", Percent: 100}, users, users},
+		{Flag{Name: "x", Percent: 25}, users * 23 / 100, users * 27 / 100},
+	} {
+		if n := len(in(tc.flag)); n < tc.min || n > tc.max {
+			t.Errorf("%v: on for %d of %d users", tc.flag, n, users)
+		}
+	}
+
+	// Raising the percentage only adds users, and another flag at the
+	// same percentage picks others.
+	ten, twenty := in(Flag{Name: "beta", Percent: 10}), in(Flag{Name: "beta", Percent: 20})
+	for u := range ten {
+		if !twenty[u] {
+			t.Fatalf("user %s dropped out going from 10%% to 20%%", u)
+		}
+	}
+	same := 0
+	for u := range in(Flag{Name: "gamma", Percent: 10}) {
+		if ten[u] {
+			same++
+		}
+	}
+	if same > len(ten)/2 {
+		t.Fatalf("beta and gamma share %d of %d users", same, len(ten))
+	}
+}
+
+func TestUpdateAndWatch(t *testing.T) {
+	s := New(Flag{Name: "search", On: true})
+	w, err := s.Watch(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Unsubscribe()
+
+	if err := s.Update(Flag{Name: "search", On: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(Flag{Name: "beta", Percent: 101}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Update invalid err = %v", err)
+	}
+	s.Update(Flag{Name: "search"})
+	s.Update(Flag{Name: "beta", Percent: 50})
+
+	for _, want := range []string{"search=off", "beta=50%"} {
+		if f := <-w.C; f.String() != want {
+			t.Fatalf("watched %s, want %s", f, want)
+		}
+	}
+	select {
+	case f := <-w.C:
+		t.Fatalf("unexpected change %s", f)
+	default:
+	}
+	if s.Enabled("search", "u1") || s.Enabled("missing", "u1") {
+		t.Fatal("off and missing flags are enabled")
+	}
+	if all := s.All(); len(all) != 2 || all[0].Name != "beta" {
+		t.Fatalf("All = %v", all)
+	}
+}
+
+func TestHandlerAndGate(t *testing.T) {
+	s := New(Flag{Name: "search", On: true})
+	admin := Handler(s)
+	app := s.Gate("search", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("results"))
+	}))
+	do := func(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := do(app, "GET", "/search", ""); rec.Code != 200 {
+		t.Fatalf("gated endpoint with flag on = %d", rec.Code)
+	}
+	for _, tc := range []struct {
+		method, path, body string
+		status             int
+		want               string
+	}{
+		{"GET", "/admin/flags", "", 200, `[{"name":"search","on":true}]`},
+		{"PUT", "/admin/flags/search", `{"on":false}`, 200, `{"name":"search","on":false}`},
+		{"GET", "/admin/flags/search", "", 200, `{"name":"search","on":false}`},
+		{"GET", "/admin/flags?on=true", "", 200, `[]`},
+		{"GET", "/admin/flags?on=maybe", "", 400, ""},
+		{"GET", "/admin/flags/nope", "", 404, `{"error":"flags: unknown flag: nope"}`},
+		{"PUT", "/admin/flags/beta", `{"percent":200}`, 422, ""},
+		{"PUT", "/admin/flags/beta", `{`, 400, ""},
+	} {
+		rec := do(admin, tc.method, tc.path, tc.body)
+		if rec.Code != tc.status || tc.want != "" && strings.TrimSpace(rec.Body.String()) != tc.want {
+			t.Errorf("%s %s = %d %s, want %d %s", tc.method, tc.path, rec.Code, rec.Body, tc.status, tc.want)
+		}
+	}
+	if rec := do(app, "GET", "/search", ""); rec.Code != 404 {
+		t.Fatalf("gated endpoint with flag off = %d", rec.Code)
+	}
+
+	// A rollout follows the subject header, falling back to the address.
+	s.Update(Flag{Name: "search", Percent: 50})
+	seen := map[int]int{}
+	for i := range 40 {
+		req := httptest.NewRequest("GET", "/search", nil)
+		req.Header.Set(SubjectHeader, "user"+strconv.Itoa(i))
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		seen[rec.Code]++
+	}
+	if seen[200] == 0 || seen[404] == 0 {
+		t.Fatalf("50%% rollout over 40 users: %v", seen)
+	}
+	if got := Subject(httptest.NewRequest("GET", "/", nil)); got != "192.0.2.1" {
+		t.Fatalf("Subject without header = %q", got)
+	}
+}
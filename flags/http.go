@@ -0,0 +1,108 @@
+package flags
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/stawuah/pounce-on-go/query"
+)
+
+// SubjectHeader names the subject, usually a user ID, whose percentage
+// rollouts a request falls under. Without it, the client's address is
+// the subject.
+const SubjectHeader = "X-User-Id"
+
+// Subject returns the subject a request is judged as.
+func Subject(r *http.Request) string {
+	if s := r.Header.Get(SubjectHeader); s != "" {
+		return s
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Gate serves next while the feature name is on for the request's
+// subject, and 404 Not Found otherwise, as if the endpoint did not
+// exist.
+func (s *Set) Gate(name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.Enabled(name, Subject(r)) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Query is how GET /admin/flags filters, sorts and pages flags (see
+// package query): on=true lists the features fully rolled out.
+var Query = &query.Schema[Flag]{
+	Fields: []query.Field[Flag]{
+		query.String("name", func(f Flag) string { return f.Name }),
+		query.Bool("on", func(f Flag) bool { return f.On }),
+		query.Int("percent", func(f Flag) int { return f.Percent }),
+	},
+}
+
+// Handler is the admin API for s:
+//
+//	GET /admin/flags         flags by name, taking Query's parameters
+//	GET /admin/flags/{name}  one flag
+//	PUT /admin/flags/{name}  set a flag: {"on": false, "percent": 25}
+//
+// It has no access control of its own; mount it behind whatever guards
+// admin endpoints.
+func Handler(s *Set) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/flags", func(w http.ResponseWriter, r *http.Request) {
+		q, err := Query.Parse(r.URL.Query())
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		fs, total, err := Query.Apply(s.All(), q)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		query.SetHeaders(w, r, q, total)
+		writeJSON(w, http.StatusOK, fs)
+	})
+	mux.HandleFunc("GET /admin/flags/{name}", func(w http.ResponseWriter, r *http.Request) {
+		f, ok := s.Get(r.PathValue("name"))
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": ErrUnknown.Error() + ": " + r.PathValue("name")})
+			return
+		}
+		writeJSON(w, http.StatusOK, f)
+	})
+	mux.HandleFunc("PUT /admin/flags/{name}", func(w http.ResponseWriter, r *http.Request) {
+		var f Flag
+		if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "malformed JSON"})
+			return
+		}
+		f.Name = r.PathValue("name")
+		if err := s.Update(f); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrInvalid) {
+				status = http.StatusUnprocessableEntity
+			}
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, f)
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
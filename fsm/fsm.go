@@ -0,0 +1,139 @@
+// Package fsm describes finite-state machines as data.
+//
+// A Machine is a table of transitions, each naming the states it may
+// start from, the event that triggers it and the state it leads to, plus
+// an optional guard that can veto it. Entry and exit hooks run as a state
+// is left and entered. The Machine holds no current state of its own: the
+// subject it drives (an order, a job, a connection) keeps that, and one
+// Machine serves every subject of its kind.
+package fsm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTransition is matched by errors.Is for any event that is not
+// allowed from the current state.
+var ErrInvalidTransition = errors.New("fsm: invalid transition")
+
+// TransitionError reports a refused event. Err is ErrInvalidTransition
+// when no transition matches, or the guard's error when one vetoed it.
+type TransitionError[S, E comparable] struct {
+	From  S
+	Event E
+	Err   error
+}
+
+func (e *TransitionError[S, E]) Error() string {
+	if e.Err == ErrInvalidTransition {
+		return fmt.Sprintf("fsm: event %v not allowed in state %v", e.Event, e.From)
+	}
+	return fmt.Sprintf("fsm: event %v in state %v: %v", e.Event, e.From, e.Err)
+}
+
+func (e *TransitionError[S, E]) Unwrap() error { return e.Err }
+
+// Transition moves a subject from any of From to To when Event fires and
+// Guard, if set, returns nil.
+type Transition[S, E comparable, T any] struct {
+	From  []S
+	Event E
+	To    S
+	Guard func(subject T) error
+}
+
+type key[S, E comparable] struct {
+	from  S
+	event E
+}
+
+// Hook runs when a subject leaves or enters a state.
+type Hook[S comparable, T any] func(subject T, from, to S)
+
+// Machine is a set of transitions over states S and events E for subjects
+// of type T. Build it once with Add, OnEnter and OnExit, then share it;
+// Fire is safe for concurrent use as long as nothing is added.
+type Machine[S, E comparable, T any] struct {
+	table   map[key[S, E]]Transition[S, E, T]
+	events  map[S][]E // per state, in the order transitions were added
+	onEnter map[S][]Hook[S, T]
+	onExit  map[S][]Hook[S, T]
+}
+
+// New returns a Machine with no transitions.
+func New[S, E comparable, T any]() *Machine[S, E, T] {
+	return &Machine[S, E, T]{
+		table:   make(map[key[S, E]]Transition[S, E, T]),
+		events:  make(map[S][]E),
+		onEnter: make(map[S][]Hook[S, T]),
+		onExit:  make(map[S][]Hook[S, T]),
+	}
+}
+
+// Add registers t and returns m for chaining. Two transitions for the
+// same state and event would make the machine ambiguous, so Add panics on
+// a duplicate.
+func (m *Machine[S, E, T]) Add(t Transition[S, E, T]) *Machine[S, E, T] {
+	for _, from := range t.From {
+		k := key[S, E]{from, t.Event}
+		if _, dup := m.table[k]; dup {
+			panic(fmt.Sprintf("fsm: duplicate transition for event %v from %v", t.Event, from))
+		}
+		m.table[k] = t
+		m.events[from] = append(m.events[from], t.Event)
+	}
+	return m
+}
+
+// OnEnter registers fn to run whenever a subject enters state s.
+func (m *Machine[S, E, T]) OnEnter(s S, fn Hook[S, T]) *Machine[S, E, T] {
+	m.onEnter[s] = append(m.onEnter[s], fn)
+	return m
+}
+
+// OnExit registers fn to run whenever a subject leaves state s.
+func (m *Machine[S, E, T]) OnExit(s S, fn Hook[S, T]) *Machine[S, E, T] {
+	m.onExit[s] = append(m.onExit[s], fn)
+	return m
+}
+
+// Fire applies event to a subject in state current. It returns the new
+// state after running the exit hooks of current and the entry hooks of
+// the new state, or current and a *TransitionError if the event is not
+// allowed or its guard refuses. A self-transition runs both sets of
+// hooks.
+func (m *Machine[S, E, T]) Fire(subject T, current S, event E) (S, error) {
+	t, ok := m.table[key[S, E]{current, event}]
+	if !ok {
+		return current, &TransitionError[S, E]{From: current, Event: event, Err: ErrInvalidTransition}
+	}
+	if t.Guard != nil {
+		if err := t.Guard(subject); err != nil {
+			return current, &TransitionError[S, E]{From: current, Event: event, Err: err}
+		}
+	}
+	for _, h := range m.onExit[current] {
+		h(subject, current, t.To)
+	}
+	for _, h := range m.onEnter[t.To] {
+		h(subject, current, t.To)
+	}
+	return t.To, nil
+}
+
+// Can reports whether event has a transition from current, without
+// evaluating its guard.
+func (m *Machine[S, E, T]) Can(current S, event E) bool {
+	_, ok := m.table[key[S, E]{current, event}]
+	return ok
+}
+
+// Events returns the events with a transition from current, in the order
+// they were added.
+func (m *Machine[S, E, T]) Events(current S) []E {
+	return append([]E(nil), m.events[current]...)
+}
+
+// Terminal reports whether no event leads out of s.
+func (m *Machine[S, E, T]) Terminal(s S) bool { return len(m.events[s]) == 0 }
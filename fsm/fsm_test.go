@@ -0,0 +1,96 @@
+package fsm
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+type door struct {
+	locked bool
+	log    []string
+}
+
+func doorMachine() *Machine[string, string, *door] {
+	m := New[string, string, *door]().
+		Add(Transition[string, string, *door]{From: []string{"closed"}, Event: "open", To: "open",
+			Guard: func(d *door) error {
+				if d.locked {
+					return errors.New("locked")
+				}
+				return nil
+			}}).
+		Add(Transition[string, string, *door]{From: []string{"open"}, Event: "close", To: "closed"}).
+		Add(Transition[string, string, *door]{From: []string{"open", "closed"}, Event: "knock", To: "closed"})
+	for _, s := range []string{"open", "closed"} {
+		m.OnExit(s, func(d *door, from, to string) { d.log = append(d.log, "exit "+from) })
+		m.OnEnter(s, func(d *door, from, to string) { d.log = append(d.log, "enter "+to) })
+	}
+	return m
+}
+
+func TestFire(t *testing.T) {
+	m := doorMachine()
+	d := &door{}
+
+	s, err := m.Fire(d, "closed", "open")
+	if err != nil || s != "open" {
+		t.Fatalf("open = %q, %v", s, err)
+	}
+	if want := []string{"exit closed", "enter open"}; !slices.Equal(d.log, want) {
+		t.Fatalf("hooks = %q, want %q", d.log, want)
+	}
+
+	d.log = nil
+	if s, _ := m.Fire(d, "closed", "knock"); s != "closed" || len(d.log) != 2 {
+		t.Fatalf("self-transition = %q with hooks %q", s, d.log)
+	}
+}
+
+func TestRefusals(t *testing.T) {
+	m := doorMachine()
+
+	d := &door{}
+	s, err := m.Fire(d, "open", "open")
+	var te *TransitionError[string, string]
+	if !errors.Is(err, ErrInvalidTransition) || !errors.As(err, &te) || te.From != "open" || s != "open" {
+		t.Fatalf("invalid event: state %q, err %v", s, err)
+	}
+	if err.Error() != "fsm: event open not allowed in state open" {
+		t.Fatalf("message = %q", err)
+	}
+	if len(d.log) != 0 {
+		t.Fatal("hooks ran for a refused event")
+	}
+
+	d.locked = true
+	s, err = m.Fire(d, "closed", "open")
+	if err == nil || errors.Is(err, ErrInvalidTransition) || s != "closed" {
+		t.Fatalf("guarded event: state %q, err %v", s, err)
+	}
+	if err.Error() != "fsm: event open in state closed: locked" {
+		t.Fatalf("message = %q", err)
+	}
+}
+
+func TestIntrospection(t *testing.T) {
+	m := doorMachine()
+	if !m.Can("closed", "open") || m.Can("open", "open") {
+		t.Fatal("Can disagrees with the table")
+	}
+	if got := m.Events("open"); !slices.Equal(got, []string{"close", "knock"}) {
+		t.Fatalf("Events(open) = %q", got)
+	}
+	if m.Terminal("open") || !m.Terminal("gone") {
+		t.Fatal("Terminal disagrees with the table")
+	}
+}
+
+func TestDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("duplicate transition did not panic")
+		}
+	}()
+	doorMachine().Add(Transition[string, string, *door]{From: []string{"open"}, Event: "close", To: "open"})
+}
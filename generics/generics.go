@@ -0,0 +1,103 @@
+// Package generics collects the type-parameter idioms used across this
+// repository in one place: constraint type sets, small generic helpers,
+// and a generic Result type.
+//
+// Two limits shape the API. Go has no method type parameters, so an
+// operation that changes a type's parameter (Result[T] to Result[U]) is a
+// function, MapResult, not a method. And since Go 1.21 min and max are
+// builtins for ordered types; Min and Max here exist for slices, where
+// the builtins do not reach.
+package generics
+
+import (
+	"errors"
+
+	"github.com/stawuah/pounce-on-go/result"
+)
+
+// Integer is the set of all integer types, including named ones such as
+// type Cents int64 thanks to the ~ approximation.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Float is the set of floating-point types.
+type Float interface {
+	~float32 | ~float64
+}
+
+// Number is every type that supports + and *.
+type Number interface {
+	Integer | Float
+}
+
+// Ordered is every type that supports < (the same set as cmp.Ordered).
+type Ordered interface {
+	Integer | Float | ~string
+}
+
+// ErrEmpty is returned by Min and Max for an empty slice.
+var ErrEmpty = errors.New("generics: empty slice")
+
+// Min returns the smallest element of s.
+func Min[T Ordered](s []T) (T, error) {
+	if len(s) == 0 {
+		var zero T
+		return zero, ErrEmpty
+	}
+	m := s[0]
+	for _, v := range s[1:] {
+		m = min(m, v)
+	}
+	return m, nil
+}
+
+// Max returns the largest element of s.
+func Max[T Ordered](s []T) (T, error) {
+	if len(s) == 0 {
+		var zero T
+		return zero, ErrEmpty
+	}
+	m := s[0]
+	for _, v := range s[1:] {
+		m = max(m, v)
+	}
+	return m, nil
+}
+
+// Clamp limits v to [lo, hi]. It panics if lo > hi.
+func Clamp[T Ordered](v, lo, hi T) T {
+	if lo > hi {
+		panic("generics: Clamp with lo > hi")
+	}
+	return min(max(v, lo), hi)
+}
+
+// Sum adds up s. The result keeps s's element type, so summing a slice
+// of a named type like Cents yields Cents.
+func Sum[S ~[]T, T Number](s S) T {
+	var total T
+	for _, v := range s {
+		total += v
+	}
+	return total
+}
+
+// Result is result.Result; the alias keeps this package's examples
+// self-contained while sharing one implementation.
+type Result[T any] = result.Result[T]
+
+// Ok wraps a value.
+func Ok[T any](v T) Result[T] { return result.Ok(v) }
+
+// Fail wraps an error.
+func Fail[T any](err error) Result[T] { return result.Err[T](err) }
+
+// Of converts a (value, error) pair into a Result.
+func Of[T any](v T, err error) Result[T] { return result.Of(v, err) }
+
+// MapResult applies f to a successful result's value and passes errors
+// through. It would be a method if methods could declare the new type
+// parameter U.
+func MapResult[T, U any](r Result[T], f func(T) U) Result[U] { return result.Map(r, f) }
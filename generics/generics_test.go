@@ -0,0 +1,87 @@
+package generics
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+type Cents int64
+
+func TestMinMax(t *testing.T) {
+	if m, _ := Min([]int{4, -2, 9}); m != -2 {
+		t.Errorf("Min = %d", m)
+	}
+	if m, _ := Max([]string{"pear", "apple", "plum"}); m != "plum" {
+		t.Errorf("Max = %q", m)
+	}
+	if m, _ := Max([]Cents{150, 99, 1200}); m != 1200 {
+		t.Errorf("Max over named type = %d", m)
+	}
+	if _, err := Min([]float64(nil)); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Min(nil) error = %v", err)
+	}
+}
+
+func TestClamp(t *testing.T) {
+	tests := []struct{ v, want int }{{-5, 0}, {5, 5}, {50, 10}}
+	for _, tt := range tests {
+		if got := Clamp(tt.v, 0, 10); got != tt.want {
+			t.Errorf("Clamp(%d, 0, 10) = %d, want %d", tt.v, got, tt.want)
+		}
+	}
+	if got := Clamp("m", "a", "k"); got != "k" {
+		t.Errorf("Clamp on strings = %q", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Clamp with lo > hi did not panic")
+		}
+	}()
+	Clamp(1, 10, 0)
+}
+
+func TestSumKeepsNamedType(t *testing.T) {
+	type Basket []Cents
+	total := Sum(Basket{199, 250, 1})
+	// total is statically a Cents; this assignment would not compile if
+	// Sum returned int64.
+	var c Cents = total
+	if c != 450 {
+		t.Fatalf("Sum = %d", c)
+	}
+	if Sum([]float64{0.5, 0.25}) != 0.75 {
+		t.Fatal("Sum over floats")
+	}
+}
+
+func TestResult(t *testing.T) {
+	r := Of(strconv.Atoi("42"))
+	doubled := MapResult(r, func(n int) int { return n * 2 })
+	if v, err := doubled.Get(); v != 84 || err != nil {
+		t.Fatalf("Get() = %d, %v", v, err)
+	}
+
+	bad := Of(strconv.Atoi("forty-two"))
+	asString := MapResult(bad, strconv.Itoa)
+	var numErr *strconv.NumError
+	if !errors.As(asString.Err(), &numErr) {
+		t.Fatalf("error not carried through MapResult: %v", asString.Err())
+	}
+}
+
+// A method cannot introduce a type parameter of its own:
+//
+//	func (r Result[T]) Map[U any](f func(T) U) Result[U] // syntax error
+//
+// A method may only use the receiver's parameters, so transforms that
+// stay within T are fine as methods and those changing T must be
+// functions. This test documents the pattern rather than the error.
+func TestMethodsCannotAddTypeParameters(t *testing.T) {
+	r := Ok(3)
+	s := MapResult(r, strconv.Itoa)
+	if v, _ := s.Get(); v != "3" {
+		t.Fatalf("got %q", v)
+	}
+}
@@ -0,0 +1,28 @@
+package geometry
+
+import "math"
+
+// Circle is a circle of the given radius.
+type Circle struct {
+	Radius float64
+}
+
+// NewCircle returns a validated Circle.
+func NewCircle(radius float64) (Circle, error) {
+	c := Circle{Radius: radius}
+	return c, c.Validate()
+}
+
+// Validate reports whether the radius is non-negative.
+func (c Circle) Validate() error { return checkDimension("radius", c.Radius) }
+
+// Area returns πr².
+func (c Circle) Area() float64 { return math.Pi * c.Radius * c.Radius }
+
+// Perimeter returns the circumference 2πr.
+func (c Circle) Perimeter() float64 { return 2 * math.Pi * c.Radius }
+
+// Scale returns c with the radius multiplied by factor.
+func (c Circle) Scale(factor float64) (Shape, error) {
+	return NewCircle(c.Radius * factor)
+}
@@ -0,0 +1,32 @@
+package geometry
+
+// Rectangle is an axis-aligned rectangle.
+type Rectangle struct {
+	Width  float64
+	Height float64
+}
+
+// NewRectangle returns a validated Rectangle.
+func NewRectangle(width, height float64) (Rectangle, error) {
+	r := Rectangle{Width: width, Height: height}
+	return r, r.Validate()
+}
+
+// Validate reports whether both sides are non-negative.
+func (r Rectangle) Validate() error {
+	if err := checkDimension("width", r.Width); err != nil {
+		return err
+	}
+	return checkDimension("height", r.Height)
+}
+
+// Area returns Width × Height.
+func (r Rectangle) Area() float64 { return r.Width * r.Height }
+
+// Perimeter returns 2 × (Width + Height).
+func (r Rectangle) Perimeter() float64 { return 2 * (r.Width + r.Height) }
+
+// Scale returns r with both sides multiplied by factor.
+func (r Rectangle) Scale(factor float64) (Shape, error) {
+	return NewRectangle(r.Width*factor, r.Height*factor)
+}
@@ -0,0 +1,33 @@
+// Package geometry provides two-dimensional shapes behind a common Shape
+// interface. All dimensions are float64 and validated on construction.
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrNegativeDimension is returned when a length is negative or not a
+// finite number.
+var ErrNegativeDimension = errors.New("geometry: dimension must be a non-negative finite number")
+
+// ErrInvalidTriangle is returned when three sides violate the triangle
+// inequality.
+var ErrInvalidTriangle = errors.New("geometry: sides do not form a triangle")
+
+// Shape is a closed two-dimensional figure.
+type Shape interface {
+	Area() float64
+	Perimeter() float64
+	// Scale returns a copy of the shape with every length multiplied by
+	// factor. The receiver is left unchanged.
+	Scale(factor float64) (Shape, error)
+}
+
+func checkDimension(name string, v float64) error {
+	if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Errorf("%w: %s = %v", ErrNegativeDimension, name, v)
+	}
+	return nil
+}
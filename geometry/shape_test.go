@@ -0,0 +1,86 @@
+package geometry
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+const epsilon = 1e-9
+
+func almostEqual(a, b float64) bool { return math.Abs(a-b) <= epsilon*math.Max(1, math.Abs(b)) }
+
+func TestAreaAndPerimeter(t *testing.T) {
+	tests := []struct {
+		name      string
+		shape     Shape
+		area      float64
+		perimeter float64
+	}{
+		{"rectangle", Rectangle{Width: 3, Height: 4}, 12, 14},
+		{"fractional rectangle", Rectangle{Width: 0.1, Height: 0.2}, 0.02, 0.6},
+		{"zero rectangle", Rectangle{}, 0, 0},
+		{"circle", Circle{Radius: 2}, 4 * math.Pi, 4 * math.Pi},
+		{"right triangle", Triangle{A: 3, B: 4, C: 5}, 6, 12},
+		{"degenerate triangle", Triangle{A: 1, B: 2, C: 3}, 0, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.shape.Area(); !almostEqual(got, tt.area) {
+				t.Errorf("Area() = %v, want %v", got, tt.area)
+			}
+			if got := tt.shape.Perimeter(); !almostEqual(got, tt.perimeter) {
+				t.Errorf("Perimeter() = %v, want %v", got, tt.perimeter)
+			}
+		})
+	}
+}
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		name   string
+		shape  Shape
+		factor float64
+	}{
+		{"rectangle doubled", Rectangle{Width: 3, Height: 4}, 2},
+		{"circle halved", Circle{Radius: 5}, 0.5},
+		{"triangle tripled", Triangle{A: 3, B: 4, C: 5}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scaled, err := tt.shape.Scale(tt.factor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := scaled.Perimeter(), tt.shape.Perimeter()*tt.factor; !almostEqual(got, want) {
+				t.Errorf("Perimeter() = %v, want %v", got, want)
+			}
+			if got, want := scaled.Area(), tt.shape.Area()*tt.factor*tt.factor; !almostEqual(got, want) {
+				t.Errorf("Area() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func() error
+		wantErr error
+	}{
+		{"negative width", func() error { _, err := NewRectangle(-1, 2); return err }, ErrNegativeDimension},
+		{"NaN height", func() error { _, err := NewRectangle(1, math.NaN()); return err }, ErrNegativeDimension},
+		{"negative radius", func() error { _, err := NewCircle(-0.5); return err }, ErrNegativeDimension},
+		{"infinite side", func() error { _, err := NewTriangle(math.Inf(1), 1, 1); return err }, ErrNegativeDimension},
+		{"impossible triangle", func() error { _, err := NewTriangle(1, 1, 5); return err }, ErrInvalidTriangle},
+		{"negative scale", func() error { _, err := Circle{Radius: 1}.Scale(-2); return err }, ErrNegativeDimension},
+		{"valid", func() error { _, err := NewTriangle(2, 2, 3); return err }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.build(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
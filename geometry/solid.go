@@ -0,0 +1,109 @@
+package geometry
+
+import "math"
+
+// Solid is a closed three-dimensional figure.
+type Solid interface {
+	Volume() float64
+	SurfaceArea() float64
+	// Scale returns a copy of the solid with every length multiplied by
+	// factor.
+	Scale(factor float64) (Solid, error)
+}
+
+// Box is a rectangular cuboid.
+type Box struct {
+	Width  float64
+	Height float64
+	Depth  float64
+}
+
+// NewBox returns a validated Box.
+func NewBox(width, height, depth float64) (Box, error) {
+	b := Box{Width: width, Height: height, Depth: depth}
+	return b, b.Validate()
+}
+
+// Validate reports whether every edge is non-negative.
+func (b Box) Validate() error {
+	if err := checkDimension("width", b.Width); err != nil {
+		return err
+	}
+	if err := checkDimension("height", b.Height); err != nil {
+		return err
+	}
+	return checkDimension("depth", b.Depth)
+}
+
+// Volume returns Width × Height × Depth.
+func (b Box) Volume() float64 { return b.Width * b.Height * b.Depth }
+
+// SurfaceArea returns the total area of the six faces.
+func (b Box) SurfaceArea() float64 {
+	return 2 * (b.Width*b.Height + b.Width*b.Depth + b.Height*b.Depth)
+}
+
+// Scale returns b with every edge multiplied by factor.
+func (b Box) Scale(factor float64) (Solid, error) {
+	return NewBox(b.Width*factor, b.Height*factor, b.Depth*factor)
+}
+
+// Sphere is a sphere of the given radius.
+type Sphere struct {
+	Radius float64
+}
+
+// NewSphere returns a validated Sphere.
+func NewSphere(radius float64) (Sphere, error) {
+	s := Sphere{Radius: radius}
+	return s, s.Validate()
+}
+
+// Validate reports whether the radius is non-negative.
+func (s Sphere) Validate() error { return checkDimension("radius", s.Radius) }
+
+// Volume returns 4/3 πr³.
+func (s Sphere) Volume() float64 { return 4.0 / 3.0 * math.Pi * s.Radius * s.Radius * s.Radius }
+
+// SurfaceArea returns 4πr².
+func (s Sphere) SurfaceArea() float64 { return 4 * math.Pi * s.Radius * s.Radius }
+
+// Scale returns s with the radius multiplied by factor.
+func (s Sphere) Scale(factor float64) (Solid, error) {
+	return NewSphere(s.Radius * factor)
+}
+
+// Cylinder is a right circular cylinder.
+type Cylinder struct {
+	Radius float64
+	Height float64
+}
+
+// NewCylinder returns a validated Cylinder.
+func NewCylinder(radius, height float64) (Cylinder, error) {
+	c := Cylinder{Radius: radius, Height: height}
+	return c, c.Validate()
+}
+
+// Validate reports whether the radius and height are non-negative.
+func (c Cylinder) Validate() error {
+	if err := checkDimension("radius", c.Radius); err != nil {
+		return err
+	}
+	return checkDimension("height", c.Height)
+}
+
+// Volume returns πr²h.
+func (c Cylinder) Volume() float64 { return c.base().Area() * c.Height }
+
+// SurfaceArea returns the two end caps plus the curved side.
+func (c Cylinder) SurfaceArea() float64 {
+	return 2*c.base().Area() + c.base().Perimeter()*c.Height
+}
+
+// Scale returns c with the radius and height multiplied by factor.
+func (c Cylinder) Scale(factor float64) (Solid, error) {
+	return NewCylinder(c.Radius*factor, c.Height*factor)
+}
+
+func (c Cylinder) base() Circle { return Circle{Radius: c.Radius} }
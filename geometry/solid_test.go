@@ -0,0 +1,59 @@
+package geometry
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestVolumeAndSurfaceArea(t *testing.T) {
+	tests := []struct {
+		name    string
+		solid   Solid
+		volume  float64
+		surface float64
+	}{
+		{"unit cube", Box{Width: 1, Height: 1, Depth: 1}, 1, 6},
+		{"box", Box{Width: 2, Height: 3, Depth: 4}, 24, 52},
+		{"sphere", Sphere{Radius: 3}, 36 * math.Pi, 36 * math.Pi},
+		{"cylinder", Cylinder{Radius: 2, Height: 5}, 20 * math.Pi, 28 * math.Pi},
+		{"flat cylinder", Cylinder{Radius: 1}, 0, 2 * math.Pi},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.solid.Volume(); !almostEqual(got, tt.volume) {
+				t.Errorf("Volume() = %v, want %v", got, tt.volume)
+			}
+			if got := tt.solid.SurfaceArea(); !almostEqual(got, tt.surface) {
+				t.Errorf("SurfaceArea() = %v, want %v", got, tt.surface)
+			}
+		})
+	}
+}
+
+func TestSolidScale(t *testing.T) {
+	for _, s := range []Solid{Box{Width: 1, Height: 2, Depth: 3}, Sphere{Radius: 2}, Cylinder{Radius: 1, Height: 4}} {
+		scaled, err := s.Scale(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := scaled.Volume(), s.Volume()*8; !almostEqual(got, want) {
+			t.Errorf("%T: Volume() = %v, want %v", s, got, want)
+		}
+		if got, want := scaled.SurfaceArea(), s.SurfaceArea()*4; !almostEqual(got, want) {
+			t.Errorf("%T: SurfaceArea() = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestSolidValidation(t *testing.T) {
+	if _, err := NewBox(1, -1, 1); !errors.Is(err, ErrNegativeDimension) {
+		t.Errorf("NewBox: err = %v", err)
+	}
+	if _, err := NewSphere(math.NaN()); !errors.Is(err, ErrNegativeDimension) {
+		t.Errorf("NewSphere: err = %v", err)
+	}
+	if _, err := NewCylinder(1, -3); !errors.Is(err, ErrNegativeDimension) {
+		t.Errorf("NewCylinder: err = %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package geometry
+
+import (
+	"fmt"
+	"math"
+)
+
+// Triangle is defined by the lengths of its three sides.
+type Triangle struct {
+	A, B, C float64
+}
+
+// NewTriangle returns a validated Triangle.
+func NewTriangle(a, b, c float64) (Triangle, error) {
+	t := Triangle{A: a, B: b, C: c}
+	return t, t.Validate()
+}
+
+// Validate checks each side and the triangle inequality. Degenerate
+// triangles (one side equal to the sum of the others) are allowed and have
+// zero area.
+func (t Triangle) Validate() error {
+	for _, side := range []struct {
+		name string
+		v    float64
+	}{{"a", t.A}, {"b", t.B}, {"c", t.C}} {
+		if err := checkDimension(side.name, side.v); err != nil {
+			return err
+		}
+	}
+	if t.A+t.B < t.C || t.A+t.C < t.B || t.B+t.C < t.A {
+		return fmt.Errorf("%w: %v, %v, %v", ErrInvalidTriangle, t.A, t.B, t.C)
+	}
+	return nil
+}
+
+// Area uses Heron's formula.
+func (t Triangle) Area() float64 {
+	s := t.Perimeter() / 2
+	// Rounding can push the product slightly below zero for degenerate
+	// triangles.
+	return math.Sqrt(math.Max(0, s*(s-t.A)*(s-t.B)*(s-t.C)))
+}
+
+// Perimeter returns A + B + C.
+func (t Triangle) Perimeter() float64 { return t.A + t.B + t.C }
+
+// Scale returns t with every side multiplied by factor.
+func (t Triangle) Scale(factor float64) (Shape, error) {
+	return NewTriangle(t.A*factor, t.B*factor, t.C*factor)
+}
@@ -0,0 +1,107 @@
+// Package units provides typed lengths, areas and volumes so metric and
+// imperial quantities cannot be mixed by accident. Like time.Duration, each
+// type is a float64 in a fixed base unit (metres, square metres, cubic
+// metres) and is built by multiplying a number by a unit constant:
+//
+//	d := 3 * units.Foot
+//	fmt.Println(d.Meters()) // 0.9144
+//
+// Adding a Length to an Area does not compile; converting between them
+// goes through Times and Div.
+package units
+
+import "fmt"
+
+// Length is a distance stored in metres.
+type Length float64
+
+// Length units.
+const (
+	Millimeter Length = 1e-3
+	Centimeter Length = 1e-2
+	Meter      Length = 1
+	Kilometer  Length = 1e3
+
+	Inch Length = 0.0254
+	Foot Length = 12 * Inch
+	Yard Length = 3 * Foot
+	Mile Length = 1760 * Yard
+)
+
+func (l Length) Millimeters() float64 { return float64(l / Millimeter) }
+func (l Length) Centimeters() float64 { return float64(l / Centimeter) }
+func (l Length) Meters() float64      { return float64(l) }
+func (l Length) Kilometers() float64  { return float64(l / Kilometer) }
+func (l Length) Inches() float64      { return float64(l / Inch) }
+func (l Length) Feet() float64        { return float64(l / Foot) }
+func (l Length) Yards() float64       { return float64(l / Yard) }
+func (l Length) Miles() float64       { return float64(l / Mile) }
+
+// Times returns the area of a rectangle with sides l and o.
+func (l Length) Times(o Length) Area { return Area(float64(l) * float64(o)) }
+
+// String formats the length in metres.
+func (l Length) String() string { return fmt.Sprintf("%gm", float64(l)) }
+
+// Area is a surface stored in square metres.
+type Area float64
+
+// Area units.
+const (
+	SquareCentimeter Area = 1e-4
+	SquareMeter      Area = 1
+	Hectare          Area = 1e4
+	SquareKilometer  Area = 1e6
+
+	SquareInch Area = Area(Inch * Inch)
+	SquareFoot Area = Area(Foot * Foot)
+	SquareYard Area = Area(Yard * Yard)
+	Acre       Area = 4840 * SquareYard
+	SquareMile Area = Area(Mile * Mile)
+)
+
+func (a Area) SquareCentimeters() float64 { return float64(a / SquareCentimeter) }
+func (a Area) SquareMeters() float64      { return float64(a) }
+func (a Area) Hectares() float64          { return float64(a / Hectare) }
+func (a Area) SquareKilometers() float64  { return float64(a / SquareKilometer) }
+func (a Area) SquareInches() float64      { return float64(a / SquareInch) }
+func (a Area) SquareFeet() float64        { return float64(a / SquareFoot) }
+func (a Area) Acres() float64             { return float64(a / Acre) }
+func (a Area) SquareMiles() float64       { return float64(a / SquareMile) }
+
+// Div returns the side that, multiplied by l, gives a.
+func (a Area) Div(l Length) Length { return Length(float64(a) / float64(l)) }
+
+// Times returns the volume of a prism with base a and height l.
+func (a Area) Times(l Length) Volume { return Volume(float64(a) * float64(l)) }
+
+// String formats the area in square metres.
+func (a Area) String() string { return fmt.Sprintf("%gm²", float64(a)) }
+
+// Volume is a capacity stored in cubic metres.
+type Volume float64
+
+// Volume units.
+const (
+	Milliliter     Volume = 1e-6
+	Liter          Volume = 1e-3
+	CubicMeter     Volume = 1
+	CubicInch      Volume = Volume(Inch * Inch * Inch)
+	CubicFoot      Volume = Volume(Foot * Foot * Foot)
+	USGallon       Volume = 231 * CubicInch
+	ImperialGallon Volume = 4.54609 * Liter
+)
+
+func (v Volume) Milliliters() float64     { return float64(v / Milliliter) }
+func (v Volume) Liters() float64          { return float64(v / Liter) }
+func (v Volume) CubicMeters() float64     { return float64(v) }
+func (v Volume) CubicInches() float64     { return float64(v / CubicInch) }
+func (v Volume) CubicFeet() float64       { return float64(v / CubicFoot) }
+func (v Volume) USGallons() float64       { return float64(v / USGallon) }
+func (v Volume) ImperialGallons() float64 { return float64(v / ImperialGallon) }
+
+// Div returns the height of a prism with base a and volume v.
+func (v Volume) Div(a Area) Length { return Length(float64(v) / float64(a)) }
+
+// String formats the volume in cubic metres.
+func (v Volume) String() string { return fmt.Sprintf("%gm³", float64(v)) }
@@ -0,0 +1,63 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool { return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b)) }
+
+func TestLengthConversions(t *testing.T) {
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"foot in metres", Foot.Meters(), 0.3048},
+		{"mile in kilometres", Mile.Kilometers(), 1.609344},
+		{"metre in inches", Meter.Inches(), 39.37007874015748},
+		{"yard in feet", Yard.Feet(), 3},
+		{"5 km in miles", (5 * Kilometer).Miles(), 3.1068559611866697},
+		{"250 mm in cm", (250 * Millimeter).Centimeters(), 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !near(tt.got, tt.want) {
+				t.Fatalf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAreaAndVolume(t *testing.T) {
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"10ft x 12ft in square feet", (10 * Foot).Times(12 * Foot).SquareFeet(), 120},
+		{"acre in square feet", Acre.SquareFeet(), 43560},
+		{"hectare in acres", Hectare.Acres(), 2.471053814671653},
+		{"area back to side", (6 * SquareMeter).Div(2 * Meter).Meters(), 3},
+		{"1m x 1m x 1m in litres", (1 * Meter).Times(Meter).Times(Meter).Liters(), 1000},
+		{"gallon in litres", USGallon.Liters(), 3.785411784},
+		{"cubic foot in gallons", CubicFoot.USGallons(), 7.480519480519481},
+		{"volume back to height", (12 * CubicMeter).Div(4 * SquareMeter).Meters(), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !near(tt.got, tt.want) {
+				t.Fatalf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	if got := (2 * Meter).String(); got != "2m" {
+		t.Errorf("Length.String() = %q", got)
+	}
+	if got := (3 * SquareMeter).String(); got != "3m²" {
+		t.Errorf("Area.String() = %q", got)
+	}
+}
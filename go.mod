@@ -0,0 +1,3 @@
+module github.com/stawuah/pounce-on-go
+
+go 1.24
@@ -0,0 +1,57 @@
+package gobstream
+
+import (
+	"fmt"
+	"iter"
+	"math/rand/v2"
+	"time"
+)
+
+// Generate yields n items built from seed, each with samples samples, a
+// tree of readings up to four levels deep and payload bytes of payload.
+// Every item after the first has a Parent, and each Primary points into
+// its item's Children. The same arguments always yield the same items.
+func Generate(seed uint64, n, samples, payload int) iter.Seq[*LargeData] {
+	return func(yield func(*LargeData) bool) {
+		rng := rand.New(rand.NewPCG(seed, 0))
+		sources := []string{"north", "south", "east", "west"}
+		start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := range n {
+			d := &LargeData{
+				ID:      uint64(i + 1),
+				Source:  sources[rng.IntN(len(sources))],
+				At:      start.Add(time.Duration(i) * time.Second),
+				Samples: make([]float64, samples),
+				Labels:  map[string]string{"batch": fmt.Sprint(i / 100), "seed": fmt.Sprint(seed)},
+				Payload: make([]byte, payload),
+			}
+			for j := range d.Samples {
+				d.Samples[j] = rng.NormFloat64()*10 + 50
+			}
+			for range 1 + rng.IntN(3) {
+				d.Children = append(d.Children, tree(rng, "r", 4))
+			}
+			d.Primary = d.Children[0]
+			if i > 0 {
+				d.Parent = &Ref{ID: uint64(i), Source: d.Source}
+			}
+			for j := range d.Payload {
+				d.Payload[j] = byte(rng.Uint32())
+			}
+			if !yield(d) {
+				return
+			}
+		}
+	}
+}
+
+// tree returns a random tree at most depth levels deep.
+func tree(rng *rand.Rand, name string, depth int) *Node {
+	n := &Node{Name: name, Value: rng.Float64()}
+	if depth > 1 {
+		for i := range rng.IntN(3) {
+			n.Children = append(n.Children, tree(rng, fmt.Sprintf("%s.%d", name, i), depth-1))
+		}
+	}
+	return n
+}
@@ -0,0 +1,144 @@
+// Package gobstream streams pointer-rich Go values between processes as
+// one gob stream over TCP. cmd/gobproducer generates LargeData items and
+// sends them; cmd/gobconsumer receives them and aggregates Metrics.
+//
+// The protocol is three steps on one connection:
+//
+//	producer → Hello{Version, Producer}
+//	producer → Frame{Item} ... Frame{End: true, Count: n}
+//	consumer → Metrics for the stream, then it closes
+//
+// One gob.Encoder carries the whole stream, so each type is described
+// once and every later value costs only its data. net/rpc would have been
+// a call per item, each waiting for its reply.
+//
+// Pointers do not cross a process boundary; what they point to does. Gob
+// sends the value behind each pointer and the receiver allocates a new
+// one, which has three consequences LargeData shows:
+//
+//   - A nil pointer is not sent, and arrives as nil.
+//   - Two pointers to the same value arrive as two separate copies:
+//     LargeData.Primary and the Node it points to among Children are
+//     equal after the trip, but no longer the same Node.
+//   - A value that reaches itself through pointers never finishes
+//     encoding. Trees are fine; cycles are not.
+package gobstream
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"time"
+)
+
+// Version is the protocol version a producer announces in its Hello.
+const Version = 1
+
+// ErrProtocol is returned for a stream that does not follow the protocol.
+var ErrProtocol = errors.New("gobstream: protocol error")
+
+// LargeData is one item of the stream: a measurement with its samples,
+// labels and a tree of readings.
+type LargeData struct {
+	ID      uint64
+	Source  string
+	At      time.Time
+	Samples []float64
+	Labels  map[string]string
+	// Parent, if not nil, is the item this one follows up.
+	Parent *Ref
+	// Children is a tree of readings, and Primary the one that matters
+	// most, pointing into it.
+	Children []*Node
+	Primary  *Node
+	Payload  []byte
+}
+
+// Ref points at another item by ID, across processes.
+type Ref struct {
+	ID     uint64
+	Source string
+}
+
+// Node is one reading in a LargeData tree.
+type Node struct {
+	Name     string
+	Value    float64
+	Children []*Node
+}
+
+// Hello opens a stream.
+type Hello struct {
+	Version  int
+	Producer string
+}
+
+// Frame carries one item, or ends the stream with how many were sent.
+type Frame struct {
+	Item  *LargeData
+	End   bool
+	Count int
+}
+
+// Send streams items to conn and returns the Metrics the consumer sends
+// back, which describe what it received.
+func Send(conn io.ReadWriter, producer string, items iter.Seq[*LargeData]) (Metrics, error) {
+	enc := gob.NewEncoder(conn)
+	if err := enc.Encode(Hello{Version: Version, Producer: producer}); err != nil {
+		return Metrics{}, fmt.Errorf("gobstream: sending hello: %w", err)
+	}
+	n := 0
+	for d := range items {
+		if err := enc.Encode(Frame{Item: d}); err != nil {
+			return Metrics{}, fmt.Errorf("gobstream: sending item %d: %w", d.ID, err)
+		}
+		n++
+	}
+	if err := enc.Encode(Frame{End: true, Count: n}); err != nil {
+		return Metrics{}, fmt.Errorf("gobstream: ending stream: %w", err)
+	}
+	var m Metrics
+	if err := gob.NewDecoder(conn).Decode(&m); err != nil {
+		return Metrics{}, fmt.Errorf("gobstream: reading metrics: %w", err)
+	}
+	return m, nil
+}
+
+// Receive reads one stream from conn, passing each item to fn if it is
+// not nil, and answers with the stream's Metrics, which it also returns
+// along with the producer's name.
+func Receive(conn io.ReadWriter, fn func(*LargeData)) (producer string, m Metrics, err error) {
+	dec := gob.NewDecoder(conn)
+	var h Hello
+	if err := dec.Decode(&h); err != nil {
+		return "", Metrics{}, fmt.Errorf("gobstream: reading hello: %w", err)
+	}
+	if h.Version != Version {
+		return h.Producer, Metrics{}, fmt.Errorf("%w: version %d, want %d", ErrProtocol, h.Version, Version)
+	}
+	for {
+		var f Frame
+		if err := dec.Decode(&f); err != nil {
+			return h.Producer, m, fmt.Errorf("gobstream: after %d items: %w", m.Items, err)
+		}
+		if f.End {
+			if f.Count != m.Items {
+				return h.Producer, m, fmt.Errorf("%w: producer sent %d items, %d arrived", ErrProtocol, f.Count, m.Items)
+			}
+			break
+		}
+		if f.Item == nil {
+			return h.Producer, m, fmt.Errorf("%w: empty frame", ErrProtocol)
+		}
+		m.Add(f.Item)
+		if fn != nil {
+			fn(f.Item)
+		}
+	}
+	if err := gob.NewEncoder(conn).Encode(m); err != nil {
+		return h.Producer, m, fmt.Errorf("gobstream: sending metrics: %w", err)
+	}
+	return h.Producer, m, nil
+}
@@ -0,0 +1,148 @@
+package gobstream
+
+import (
+	"encoding/gob"
+	"errors"
+	"net"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/stawuah/pounce-on-go/concurrency/leaktest"
+)
+
+// pair returns both ends of a loopback TCP connection, so the tests cross
+// a real socket as the two programs do.
+func pair(t *testing.T) (producer, consumer net.Conn) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, _ := l.Accept()
+		accepted <- c
+	}()
+	producer, err = net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	consumer = <-accepted
+	t.Cleanup(func() { producer.Close(); consumer.Close() })
+	return producer, consumer
+}
+
+func TestStream(t *testing.T) {
+	leaktest.Check(t)
+	p, c := pair(t)
+	items := slices.Collect(Generate(7, 200, 16, 256))
+	var want Metrics
+	for _, d := range items {
+		want.Add(d)
+	}
+
+	type result struct {
+		producer string
+		m        Metrics
+		got      []*LargeData
+		err      error
+	}
+	done := make(chan result)
+	go func() {
+		var r result
+		r.producer, r.m, r.err = Receive(c, func(d *LargeData) { r.got = append(r.got, d) })
+		done <- r
+	}()
+	sent, err := Send(p, "test", slices.Values(items))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := <-done
+	if r.err != nil || r.producer != "test" {
+		t.Fatalf("Receive: %q, %v", r.producer, r.err)
+	}
+	if !reflect.DeepEqual(sent, want) || !reflect.DeepEqual(r.m, want) {
+		t.Fatalf("metrics:\nsent back %+v\nreceived  %+v\nwant      %+v", sent, r.m, want)
+	}
+	if want.Items != 200 || want.Samples != 3200 || want.Bytes != 200*256 || want.MaxDepth < 2 {
+		t.Fatalf("implausible metrics %+v", want)
+	}
+
+	// Values survive the trip; pointer identity does not.
+	for i, d := range r.got {
+		if !reflect.DeepEqual(d, items[i]) {
+			t.Fatalf("item %d arrived as %+v", i, d)
+		}
+	}
+	first, second := r.got[0], r.got[1]
+	if first.Parent != nil || second.Parent == nil || second.Parent.ID != 1 {
+		t.Fatalf("parents: %v, %v", first.Parent, second.Parent)
+	}
+	if items[0].Primary != items[0].Children[0] || first.Primary == first.Children[0] {
+		t.Fatal("Primary should alias Children[0] before sending and be a copy after")
+	}
+}
+
+func TestReceiveErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(*gob.Encoder)
+	}{
+		{"version", func(enc *gob.Encoder) { enc.Encode(Hello{Version: 2}) }},
+		{"count", func(enc *gob.Encoder) {
+			enc.Encode(Hello{Version: Version})
+			enc.Encode(Frame{Item: &LargeData{ID: 1}})
+			enc.Encode(Frame{End: true, Count: 2})
+		}},
+		{"empty frame", func(enc *gob.Encoder) {
+			enc.Encode(Hello{Version: Version})
+			enc.Encode(Frame{})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, c := pair(t)
+			go tt.write(gob.NewEncoder(p))
+			if _, _, err := Receive(c, nil); !errors.Is(err, ErrProtocol) {
+				t.Fatalf("Receive = %v, want ErrProtocol", err)
+			}
+		})
+	}
+
+	// A producer that hangs up mid-stream is an error, not an empty stream.
+	p, c := pair(t)
+	go func() {
+		enc := gob.NewEncoder(p)
+		enc.Encode(Hello{Version: Version})
+		enc.Encode(Frame{Item: &LargeData{ID: 1}})
+		p.Close()
+	}()
+	if _, m, err := Receive(c, nil); err == nil || m.Items != 1 {
+		t.Fatalf("Receive after hang-up = %+v, %v", m, err)
+	}
+}
+
+func TestMetricsMerge(t *testing.T) {
+	var a, b, all Metrics
+	for d := range Generate(1, 50, 4, 8) {
+		all.Add(d)
+		if d.ID%2 == 0 {
+			a.Add(d)
+		} else {
+			b.Add(d)
+		}
+	}
+	var merged Metrics
+	merged.Merge(a)
+	merged.Merge(Metrics{})
+	merged.Merge(b)
+	if merged.Items != all.Items || merged.Min != all.Min || merged.Max != all.Max ||
+		merged.Nodes != all.Nodes || !reflect.DeepEqual(merged.BySource, all.BySource) {
+		t.Fatalf("merged %+v, want %+v", merged, all)
+	}
+	if d := merged.Mean() - all.Mean(); d > 1e-9 || d < -1e-9 {
+		t.Fatalf("mean %v, want %v", merged.Mean(), all.Mean())
+	}
+}
@@ -0,0 +1,92 @@
+package gobstream
+
+import (
+	"maps"
+	"math"
+)
+
+// Metrics aggregates a stream of items. The zero value is empty and ready
+// to use.
+type Metrics struct {
+	Items    int
+	Samples  int
+	Sum      float64 // of every sample
+	Min, Max float64 // of every sample; zero until there is one
+	Nodes    int     // in every item's tree
+	MaxDepth int     // of the deepest tree
+	Bytes    int64   // of payload
+	BySource map[string]int
+}
+
+// Add counts d.
+func (m *Metrics) Add(d *LargeData) {
+	m.Items++
+	for _, v := range d.Samples {
+		if m.Samples == 0 {
+			m.Min, m.Max = v, v
+		}
+		m.Samples++
+		m.Sum += v
+		m.Min, m.Max = math.Min(m.Min, v), math.Max(m.Max, v)
+	}
+	for _, n := range d.Children {
+		nodes, depth := walk(n)
+		m.Nodes += nodes
+		m.MaxDepth = max(m.MaxDepth, depth)
+	}
+	m.Bytes += int64(len(d.Payload))
+	if m.BySource == nil {
+		m.BySource = make(map[string]int)
+	}
+	m.BySource[d.Source]++
+}
+
+// walk returns how many nodes the tree at n has and how deep it goes.
+func walk(n *Node) (nodes, depth int) {
+	if n == nil {
+		return 0, 0
+	}
+	nodes = 1
+	for _, c := range n.Children {
+		cn, cd := walk(c)
+		nodes += cn
+		depth = max(depth, cd)
+	}
+	return nodes, depth + 1
+}
+
+// Merge adds the counts of o to m.
+func (m *Metrics) Merge(o Metrics) {
+	if o.Samples > 0 {
+		if m.Samples == 0 {
+			m.Min, m.Max = o.Min, o.Max
+		}
+		m.Min, m.Max = math.Min(m.Min, o.Min), math.Max(m.Max, o.Max)
+	}
+	m.Items += o.Items
+	m.Samples += o.Samples
+	m.Sum += o.Sum
+	m.Nodes += o.Nodes
+	m.MaxDepth = max(m.MaxDepth, o.MaxDepth)
+	m.Bytes += o.Bytes
+	if len(o.BySource) > 0 && m.BySource == nil {
+		m.BySource = make(map[string]int)
+	}
+	for src, n := range o.BySource {
+		m.BySource[src] += n
+	}
+}
+
+// Mean returns the mean sample, or 0 before the first.
+func (m Metrics) Mean() float64 {
+	if m.Samples == 0 {
+		return 0
+	}
+	return m.Sum / float64(m.Samples)
+}
+
+// Clone returns a copy of m that shares nothing with it.
+func (m Metrics) Clone() Metrics {
+	m.BySource = maps.Clone(m.BySource)
+	return m
+}
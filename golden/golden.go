@@ -0,0 +1,68 @@
+// Package golden compares test output with files checked in under
+// testdata. Run the tests with -update to rewrite the files after an
+// intended change, then review the diff like any other code change:
+//
+//	go test ./export -update
+//
+// Import it only from _test.go files; it registers the -update flag.
+package golden
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// Path returns the golden file for name: testdata/<name>.golden.
+func Path(name string) string {
+	return filepath.Join("testdata", name+".golden")
+}
+
+// Assert fails t if got differs from the golden file for name, or writes
+// got to that file when -update is set.
+func Assert(t testing.TB, name string, got []byte) {
+	t.Helper()
+	path := Path(name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run with -update to accept):\n%s", path, diff(want, got))
+	}
+}
+
+// diff reports the first differing line with a little context; enough to
+// see what changed without a diff library.
+func diff(want, got []byte) string {
+	w := strings.Split(string(want), "\n")
+	g := strings.Split(string(got), "\n")
+	for i := range max(len(w), len(g)) {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl || i >= len(w) || i >= len(g) {
+			return fmt.Sprintf("line %d:\n- %s\n+ %s", i+1, wl, gl)
+		}
+	}
+	return "(identical lines; differing line endings?)"
+}
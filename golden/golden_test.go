@@ -0,0 +1,22 @@
+package golden
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		want, got string
+		out       string
+	}{
+		{"changed line", "a\nb\nc", "a\nx\nc", "line 2:\n- b\n+ x"},
+		{"extra line", "a", "a\nb", "line 2:\n- \n+ b"},
+		{"missing line", "a\nb", "a", "line 2:\n- b\n+ "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diff([]byte(tt.want), []byte(tt.got)); got != tt.out {
+				t.Fatalf("diff() = %q, want %q", got, tt.out)
+			}
+		})
+	}
+}
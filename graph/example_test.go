@@ -0,0 +1,40 @@
+package graph_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stawuah/pounce-on-go/graph"
+)
+
+// A category hierarchy as edges from parent to child. BFS depth gives the
+// indentation for a tree view, and a topological sort gives an order in
+// which each category can be created after its parent.
+func Example_categories() {
+	var g graph.Graph[string]
+	g.AddEdge("Tools", "Hand tools")
+	g.AddEdge("Tools", "Power tools")
+	g.AddEdge("Hand tools", "Hammers")
+	g.AddEdge("Hand tools", "Anvils")
+	g.AddEdge("Power tools", "Drills")
+
+	for c, depth := range g.BFS("Tools") {
+		fmt.Println(strings.Repeat("  ", depth) + c)
+	}
+	order, _ := g.TopoSort()
+	fmt.Println(order)
+
+	// A category made its own ancestor is rejected.
+	g.AddEdge("Drills", "Tools")
+	_, err := g.TopoSort()
+	fmt.Println(err)
+	// Output:
+	// Tools
+	//   Hand tools
+	//   Power tools
+	//     Hammers
+	//     Anvils
+	//     Drills
+	// [Tools Hand tools Power tools Hammers Anvils Drills]
+	// graph: cycle: [Tools Power tools Drills Tools]
+}
@@ -0,0 +1,200 @@
+// Package graph implements a directed graph over comparable node values
+// as adjacency lists.
+//
+// Nodes and each node's edges keep their insertion order, so traversals
+// and topological sorts are deterministic: the same sequence of AddEdge
+// calls always produces the same output, which matters for tests and for
+// anything shown to a user.
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// ErrCycle is returned by TopoSort for a graph with a cycle.
+var ErrCycle = errors.New("graph: cycle")
+
+// Graph is a directed graph. The zero value is an empty graph ready to
+// use. A Graph is not safe for concurrent use.
+type Graph[N comparable] struct {
+	nodes []N
+	edges map[N][]N
+}
+
+// AddNode adds n and reports whether it was new.
+func (g *Graph[N]) AddNode(n N) bool {
+	if _, ok := g.edges[n]; ok {
+		return false
+	}
+	if g.edges == nil {
+		g.edges = make(map[N][]N)
+	}
+	g.edges[n] = nil
+	g.nodes = append(g.nodes, n)
+	return true
+}
+
+// AddEdge adds an edge from -> to, adding either node if needed, and
+// reports whether the edge was new.
+func (g *Graph[N]) AddEdge(from, to N) bool {
+	g.AddNode(from)
+	g.AddNode(to)
+	if slices.Contains(g.edges[from], to) {
+		return false
+	}
+	g.edges[from] = append(g.edges[from], to)
+	return true
+}
+
+// HasNode reports whether n is in the graph.
+func (g *Graph[N]) HasNode(n N) bool {
+	_, ok := g.edges[n]
+	return ok
+}
+
+// HasEdge reports whether there is an edge from -> to.
+func (g *Graph[N]) HasEdge(from, to N) bool {
+	return slices.Contains(g.edges[from], to)
+}
+
+// Nodes returns every node in insertion order.
+func (g *Graph[N]) Nodes() []N { return slices.Clone(g.nodes) }
+
+// Neighbors returns the targets of n's outgoing edges.
+func (g *Graph[N]) Neighbors(n N) []N { return slices.Clone(g.edges[n]) }
+
+// Len returns the number of nodes.
+func (g *Graph[N]) Len() int { return len(g.nodes) }
+
+// BFS yields the nodes reachable from start, start included, in
+// breadth-first order together with their distance in edges from start.
+func (g *Graph[N]) BFS(start N) iter.Seq2[N, int] {
+	return func(yield func(N, int) bool) {
+		if !g.HasNode(start) {
+			return
+		}
+		seen := map[N]bool{start: true}
+		type item struct {
+			n     N
+			depth int
+		}
+		queue := []item{{start, 0}}
+		for len(queue) > 0 {
+			it := queue[0]
+			queue = queue[1:]
+			if !yield(it.n, it.depth) {
+				return
+			}
+			for _, next := range g.edges[it.n] {
+				if !seen[next] {
+					seen[next] = true
+					queue = append(queue, item{next, it.depth + 1})
+				}
+			}
+		}
+	}
+}
+
+// DFS yields the nodes reachable from start in depth-first preorder.
+func (g *Graph[N]) DFS(start N) iter.Seq[N] {
+	return func(yield func(N) bool) {
+		if !g.HasNode(start) {
+			return
+		}
+		seen := map[N]bool{}
+		stack := []N{start}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+			if !yield(n) {
+				return
+			}
+			// Push in reverse so the first edge is explored first.
+			for _, next := range slices.Backward(g.edges[n]) {
+				if !seen[next] {
+					stack = append(stack, next)
+				}
+			}
+		}
+	}
+}
+
+// TopoSort returns the nodes ordered so that every edge points forward,
+// breaking ties by insertion order (Kahn's algorithm). If the graph has a
+// cycle it returns an error wrapping ErrCycle that names one.
+func (g *Graph[N]) TopoSort() ([]N, error) {
+	indegree := make(map[N]int, len(g.nodes))
+	for _, n := range g.nodes {
+		for _, to := range g.edges[n] {
+			indegree[to]++
+		}
+	}
+	var ready []N
+	for _, n := range g.nodes {
+		if indegree[n] == 0 {
+			ready = append(ready, n)
+		}
+	}
+	order := make([]N, 0, len(g.nodes))
+	for len(ready) > 0 {
+		n := ready[0]
+		ready = ready[1:]
+		order = append(order, n)
+		for _, to := range g.edges[n] {
+			if indegree[to]--; indegree[to] == 0 {
+				ready = append(ready, to)
+			}
+		}
+	}
+	if len(order) < len(g.nodes) {
+		return nil, fmt.Errorf("%w: %v", ErrCycle, g.FindCycle())
+	}
+	return order, nil
+}
+
+// FindCycle returns the nodes of one cycle, with the first node repeated
+// at the end, or nil if the graph is acyclic.
+func (g *Graph[N]) FindCycle() []N {
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := make(map[N]int, len(g.nodes))
+	var path []N
+
+	var visit func(n N) []N
+	visit = func(n N) []N {
+		state[n] = onPath
+		path = append(path, n)
+		for _, next := range g.edges[n] {
+			switch state[next] {
+			case onPath:
+				i := slices.Index(path, next)
+				return append(slices.Clone(path[i:]), next)
+			case unvisited:
+				if c := visit(next); c != nil {
+					return c
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[n] = done
+		return nil
+	}
+	for _, n := range g.nodes {
+		if state[n] == unvisited {
+			if c := visit(n); c != nil {
+				return c
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func build(edges ...[2]int) *Graph[int] {
+	var g Graph[int]
+	for _, e := range edges {
+		g.AddEdge(e[0], e[1])
+	}
+	return &g
+}
+
+func TestAddAndQuery(t *testing.T) {
+	var g Graph[string]
+	if !g.AddNode("a") || g.AddNode("a") {
+		t.Fatal("AddNode did not report novelty")
+	}
+	if !g.AddEdge("a", "b") || g.AddEdge("a", "b") {
+		t.Fatal("AddEdge did not report novelty")
+	}
+	if !g.HasNode("b") || !g.HasEdge("a", "b") || g.HasEdge("b", "a") {
+		t.Fatal("edge is not directed")
+	}
+	if g.Len() != 2 || !slices.Equal(g.Nodes(), []string{"a", "b"}) || !slices.Equal(g.Neighbors("a"), []string{"b"}) {
+		t.Fatalf("nodes %v, neighbors %v", g.Nodes(), g.Neighbors("a"))
+	}
+}
+
+func TestTraversals(t *testing.T) {
+	//   1 → 2 → 4
+	//   ↓   ↓
+	//   3 → 5     6 (unreachable)
+	g := build([2]int{1, 2}, [2]int{1, 3}, [2]int{2, 4}, [2]int{2, 5}, [2]int{3, 5}, [2]int{6, 1})
+
+	var bfs, depths []int
+	for n, d := range g.BFS(1) {
+		bfs = append(bfs, n)
+		depths = append(depths, d)
+	}
+	if !slices.Equal(bfs, []int{1, 2, 3, 4, 5}) || !slices.Equal(depths, []int{0, 1, 1, 2, 2}) {
+		t.Fatalf("BFS = %v at depths %v", bfs, depths)
+	}
+	if dfs := slices.Collect(g.DFS(1)); !slices.Equal(dfs, []int{1, 2, 4, 5, 3}) {
+		t.Fatalf("DFS = %v", dfs)
+	}
+	if got := slices.Collect(g.DFS(99)); got != nil {
+		t.Fatalf("DFS from a missing node = %v", got)
+	}
+
+	var first []int
+	for n := range g.DFS(1) {
+		first = append(first, n)
+		if len(first) == 2 {
+			break
+		}
+	}
+	if !slices.Equal(first, []int{1, 2}) {
+		t.Fatalf("DFS with break = %v", first)
+	}
+}
+
+func TestTopoSort(t *testing.T) {
+	g := build([2]int{3, 1}, [2]int{1, 2}, [2]int{3, 2}, [2]int{4, 2})
+	order, err := g.TopoSort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(order, []int{3, 4, 1, 2}) {
+		t.Fatalf("TopoSort = %v", order)
+	}
+	pos := map[int]int{}
+	for i, n := range order {
+		pos[n] = i
+	}
+	for _, n := range g.Nodes() {
+		for _, to := range g.Neighbors(n) {
+			if pos[n] > pos[to] {
+				t.Fatalf("edge %d → %d points backwards in %v", n, to, order)
+			}
+		}
+	}
+}
+
+func TestCycles(t *testing.T) {
+	tests := []struct {
+		name  string
+		g     *Graph[int]
+		cycle []int
+	}{
+		{"acyclic", build([2]int{1, 2}, [2]int{2, 3}, [2]int{1, 3}), nil},
+		{"self loop", build([2]int{1, 1}), []int{1, 1}},
+		{"triangle", build([2]int{0, 1}, [2]int{1, 2}, [2]int{2, 3}, [2]int{3, 1}), []int{1, 2, 3, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.g.FindCycle(); !slices.Equal(got, tt.cycle) {
+				t.Fatalf("FindCycle = %v, want %v", got, tt.cycle)
+			}
+			_, err := tt.g.TopoSort()
+			if (err != nil) != (tt.cycle != nil) || (err != nil && !errors.Is(err, ErrCycle)) {
+				t.Fatalf("TopoSort error = %v", err)
+			}
+		})
+	}
+}
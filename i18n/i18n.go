@@ -0,0 +1,218 @@
+// Package i18n localizes user-facing messages. A Bundle holds a Catalog
+// of messages per locale; Negotiate picks the locales a client asked for
+// in its Accept-Language header, and the Localizer it returns looks each
+// message up in those locales in turn, then in the bundle's fallback.
+//
+// Messages name their parameters in braces, and arguments are given as
+// alternating names and values, as with log/slog:
+//
+//	"validation.min": "{field} must be at least {min}"
+//
+//	l.T("validation.min", "field", "price", "min", 0)
+//
+// A message missing from every catalog renders as its key, so a gap in a
+// translation shows up in the output rather than as an empty string.
+package i18n
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Catalog maps message keys to messages for one locale.
+type Catalog map[string]string
+
+// Bundle holds the catalogs of every supported locale. Add catalogs
+// before serving; a Bundle is then safe for concurrent use.
+type Bundle struct {
+	fallback string
+	catalogs map[string]Catalog
+}
+
+// NewBundle returns a bundle that falls back to the fallback locale, whose
+// catalog should hold every key.
+func NewBundle(fallback string) *Bundle {
+	return &Bundle{fallback: canonical(fallback), catalogs: make(map[string]Catalog)}
+}
+
+// Add merges c into the catalog for locale, such as "fr" or "pt-BR".
+func (b *Bundle) Add(locale string, c Catalog) {
+	locale = canonical(locale)
+	dst := b.catalogs[locale]
+	if dst == nil {
+		dst = make(Catalog, len(c))
+		b.catalogs[locale] = dst
+	}
+	maps.Copy(dst, c)
+}
+
+// LoadFS adds every JSON file in fsys matching pattern as a catalog, for
+// the locale named by the file name without its extension: fr.json holds
+// the French messages.
+func (b *Bundle) LoadFS(fsys fs.FS, pattern string) error {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return fmt.Errorf("i18n: %w", err)
+	}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("i18n: %w", err)
+		}
+		var c Catalog
+		if err := json.Unmarshal(data, &c); err != nil {
+			return fmt.Errorf("i18n: %s: %w", name, err)
+		}
+		b.Add(strings.TrimSuffix(path.Base(name), path.Ext(name)), c)
+	}
+	return nil
+}
+
+// Locales returns the locales with a catalog, sorted.
+func (b *Bundle) Locales() []string {
+	out := make([]string, 0, len(b.catalogs))
+	for l := range b.catalogs {
+		out = append(out, l)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// Localizer returns a Localizer trying locales in order, then the
+// fallback. Locales without a catalog are skipped, and a regional locale
+// such as fr-CH also tries its language, fr.
+func (b *Bundle) Localizer(locales ...string) Localizer {
+	l := Localizer{b: b}
+	add := func(locale string) {
+		if _, ok := b.catalogs[locale]; ok && !slices.Contains(l.chain, locale) {
+			l.chain = append(l.chain, locale)
+		}
+	}
+	for _, locale := range locales {
+		locale = canonical(locale)
+		add(locale)
+		if lang, _, ok := strings.Cut(locale, "-"); ok {
+			add(lang)
+		}
+	}
+	add(b.fallback)
+	return l
+}
+
+// Negotiate returns a Localizer for the locales an Accept-Language header
+// asks for, best first. The fallback comes last whatever the header says,
+// since a message in an unwanted language beats none.
+func (b *Bundle) Negotiate(acceptLanguage string) Localizer {
+	return b.Localizer(ParseAcceptLanguage(acceptLanguage)...)
+}
+
+// ParseAcceptLanguage returns the language ranges in an Accept-Language
+// header, most preferred first, leaving out those with q=0 and the
+// wildcard. Ranges with equal weights keep their order. Malformed entries
+// are skipped: a bad header should cost the client its preferences, not
+// its request.
+func ParseAcceptLanguage(header string) []string {
+	type entry struct {
+		tag string
+		q   float64
+	}
+	var entries []entry
+	for part := range strings.SplitSeq(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		q := 1.0
+		if params = strings.TrimSpace(params); params != "" {
+			v, ok := strings.CutPrefix(params, "q=")
+			f, err := strconv.ParseFloat(v, 64)
+			if !ok || err != nil || f < 0 || f > 1 {
+				continue
+			}
+			q = f
+		}
+		if tag == "" || tag == "*" || q == 0 {
+			continue
+		}
+		entries = append(entries, entry{tag, q})
+	}
+	slices.SortStableFunc(entries, func(a, b entry) int { return cmp.Compare(b.q, a.q) })
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.tag
+	}
+	return out
+}
+
+// Localizer renders messages in the locales chosen for one request. The
+// zero Localizer renders every message as its key.
+type Localizer struct {
+	b     *Bundle
+	chain []string
+}
+
+// Locale returns the locale messages are looked up in first, the one to
+// report in a Content-Language header.
+func (l Localizer) Locale() string {
+	if len(l.chain) == 0 {
+		return ""
+	}
+	return l.chain[0]
+}
+
+// Lookup returns the message for key from the first locale that has it.
+func (l Localizer) Lookup(key string) (string, bool) {
+	for _, locale := range l.chain {
+		if msg, ok := l.b.catalogs[locale][key]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// Has reports whether any of l's locales has a message for key.
+func (l Localizer) Has(key string) bool {
+	_, ok := l.Lookup(key)
+	return ok
+}
+
+// T renders the message for key with args, alternating parameter names
+// and values. A missing message renders as key, and a parameter without
+// an argument is left in braces.
+func (l Localizer) T(key string, args ...any) string {
+	msg, ok := l.Lookup(key)
+	if !ok {
+		return key
+	}
+	return format(msg, args)
+}
+
+// format replaces each {name} in msg with its value in args.
+func format(msg string, args []any) string {
+	if !strings.Contains(msg, "{") {
+		return msg
+	}
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+fmt.Sprint(args[i])+"}", fmt.Sprint(args[i+1]))
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+// canonical normalizes a locale's case and separator: en_us and EN-us
+// both become en-US.
+func canonical(locale string) string {
+	lang, region, ok := strings.Cut(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	if !ok {
+		return strings.ToLower(lang)
+	}
+	if len(region) == 2 {
+		region = strings.ToUpper(region)
+	}
+	return strings.ToLower(lang) + "-" + region
+}
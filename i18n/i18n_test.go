@@ -0,0 +1,108 @@
+package i18n
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func bundle() *Bundle {
+	b := NewBundle("en")
+	b.Add("en", Catalog{
+		"hello":    "Hello, {name}",
+		"items":    "{n} items in {place}",
+		"only.en":  "English only",
+		"no.param": "Plain",
+	})
+	b.Add("fr", Catalog{"hello": "Bonjour, {name}", "items": "{n} articles dans {place}"})
+	b.Add("fr_ca", Catalog{"hello": "Allô, {name}"})
+	return b
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", nil},
+		{"fr", []string{"fr"}},
+		{"fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5", []string{"fr-CH", "fr", "en"}},
+		{"en;q=0.5, de, fr;q=0.5", []string{"de", "en", "fr"}},
+		{"de;q=0, fr", []string{"fr"}},
+		{"de;q=abc, es;q=2, it;level=1, pt", []string{"pt"}},
+	}
+	for _, tt := range tests {
+		if got := ParseAcceptLanguage(tt.header); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	b := bundle()
+	tests := []struct {
+		header, locale, hello string
+	}{
+		{"", "en", "Hello, Ann"},
+		{"fr", "fr", "Bonjour, Ann"},
+		{"FR-ca", "fr-CA", "Allô, Ann"},
+		{"fr-CH", "fr", "Bonjour, Ann"}, // no fr-CH catalog: its language
+		{"de, fr;q=0.5", "fr", "Bonjour, Ann"},
+		{"de", "en", "Hello, Ann"},
+	}
+	for _, tt := range tests {
+		l := b.Negotiate(tt.header)
+		if l.Locale() != tt.locale || l.T("hello", "name", "Ann") != tt.hello {
+			t.Errorf("Negotiate(%q): locale %q, hello %q; want %q, %q", tt.header, l.Locale(), l.T("hello", "name", "Ann"), tt.locale, tt.hello)
+		}
+	}
+	if got := b.Locales(); !slices.Equal(got, []string{"en", "fr", "fr-CA"}) {
+		t.Fatalf("Locales = %q", got)
+	}
+}
+
+func TestMissing(t *testing.T) {
+	l := bundle().Localizer("fr-CA")
+	tests := []struct {
+		key  string
+		args []any
+		want string
+	}{
+		{"items", []any{"n", 3, "place", "le panier"}, "3 articles dans le panier"}, // from fr
+		{"only.en", nil, "English only"},                                            // from the fallback
+		{"nowhere", nil, "nowhere"},                                                 // the key itself
+		{"items", []any{"n", 3}, "3 articles dans {place}"},                         // missing argument
+		{"items", []any{"n", 3, "place"}, "3 articles dans {place}"},                // odd argument
+		{"no.param", []any{"x", 1}, "Plain"},
+	}
+	for _, tt := range tests {
+		if got := l.T(tt.key, tt.args...); got != tt.want {
+			t.Errorf("T(%q, %v) = %q, want %q", tt.key, tt.args, got, tt.want)
+		}
+	}
+	if l.Has("nowhere") || !l.Has("only.en") {
+		t.Fatal("Has disagrees with Lookup")
+	}
+	var zero Localizer
+	if zero.T("hello") != "hello" || zero.Locale() != "" {
+		t.Fatal("zero Localizer should render keys")
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	b := NewBundle("en")
+	err := b.LoadFS(fstest.MapFS{
+		"msgs/en.json":    {Data: []byte(`{"hi": "Hi"}`)},
+		"msgs/pt_br.json": {Data: []byte(`{"hi": "Oi"}`)},
+	}, "msgs/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Negotiate("pt-BR").T("hi"); got != "Oi" {
+		t.Fatalf("pt-BR hi = %q", got)
+	}
+	err = b.LoadFS(fstest.MapFS{"bad.json": {Data: []byte(`{"hi": 1}`)}}, "*.json")
+	if err == nil {
+		t.Fatal("LoadFS accepted a catalog that is not strings")
+	}
+}
@@ -0,0 +1,82 @@
+// Package iox holds small io.Reader and io.Writer implementations that
+// compose with the standard library: a size-rotating log file, a reader
+// that counts bytes, and a reader throttled to a byte rate.
+package iox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+// CountingReader counts the bytes read through it.
+type CountingReader struct {
+	R io.Reader
+	N int64
+}
+
+func (c *CountingReader) Read(p []byte) (int, error) {
+	n, err := c.R.Read(p)
+	c.N += int64(n)
+	return n, err
+}
+
+// ErrTooLarge is returned by DecodeJSON when the body exceeds its limit.
+var ErrTooLarge = errors.New("request body too large")
+
+// DecodeJSON decodes the request body into dst, reading at most limit
+// bytes, and returns how many bytes were read. Bodies over the limit fail
+// with an error wrapping ErrTooLarge, and the connection is marked to be
+// closed; the caller should answer 413.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any, limit int64) (int64, error) {
+	body := &CountingReader{R: http.MaxBytesReader(w, r.Body, limit)}
+	err := json.NewDecoder(body).Decode(dst)
+	if mbe := (*http.MaxBytesError)(nil); errors.As(err, &mbe) {
+		return body.N, fmt.Errorf("%w: limit is %d bytes", ErrTooLarge, mbe.Limit)
+	}
+	return body.N, err
+}
+
+// RateLimitedReader reads from R no faster than a fixed number of bytes
+// per second, averaged since the first Read. It is not safe for
+// concurrent use, like most readers.
+type RateLimitedReader struct {
+	r     io.Reader
+	rate  int64 // bytes per second
+	read  int64
+	start time.Time
+
+	clock clock.Clock
+}
+
+// NewRateLimitedReader wraps r at bytesPerSec.
+func NewRateLimitedReader(r io.Reader, bytesPerSec int64) *RateLimitedReader {
+	if bytesPerSec < 1 {
+		panic("iox: rate must be positive")
+	}
+	return &RateLimitedReader{r: r, rate: bytesPerSec, clock: clock.Real{}}
+}
+
+func (l *RateLimitedReader) Read(p []byte) (int, error) {
+	if l.start.IsZero() {
+		l.start = l.clock.Now()
+	}
+	// Never read more than one second's worth at once, so a large p does
+	// not turn into one long burst followed by one long sleep.
+	if int64(len(p)) > l.rate {
+		p = p[:l.rate]
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+
+	due := l.start.Add(time.Duration(l.read * int64(time.Second) / l.rate))
+	if wait := due.Sub(l.clock.Now()); wait > 0 {
+		<-l.clock.After(wait)
+	}
+	return n, err
+}
@@ -0,0 +1,144 @@
+package iox
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+func TestCountingReader(t *testing.T) {
+	c := &CountingReader{R: strings.NewReader("hello, world")}
+	if _, err := io.Copy(io.Discard, c); err != nil {
+		t.Fatal(err)
+	}
+	if c.N != 12 {
+		t.Fatalf("N = %d, want 12", c.N)
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	type upload struct {
+		Name string `json:"name"`
+	}
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{"fits", `{"name":"anvil"}`, nil},
+		{"too large", `{"name":"` + strings.Repeat("x", 100) + `"}`, ErrTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			var u upload
+			n, err := DecodeJSON(httptest.NewRecorder(), req, &u, 64)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (u.Name != "anvil" || n != int64(len(tt.body))) {
+				t.Fatalf("decoded %+v from %d bytes", u, n)
+			}
+			if tt.wantErr != nil && n > 64 {
+				t.Fatalf("read %d bytes past a 64-byte limit", n)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONInHandler(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var v map[string]any
+		if _, err := DecodeJSON(w, r, &v, 16); errors.Is(err, ErrTooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(`{"k":"`+strings.Repeat("v", 32)+`"}`)))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413", rec.Code)
+	}
+}
+
+func TestRateLimitedReader(t *testing.T) {
+	clk := clock.NewAutoFake(time.Unix(0, 0))
+	l := NewRateLimitedReader(bytes.NewReader(make([]byte, 2500)), 1000)
+	l.clock = clk
+
+	got, err := io.ReadAll(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2500 {
+		t.Fatalf("read %d bytes", len(got))
+	}
+	if clk.Slept() != 2500*time.Millisecond {
+		t.Fatalf("slept %v, want 2.5s", clk.Slept())
+	}
+}
+
+func TestRotatingFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := NewRotatingFileWriter(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n", "eeee\n", "ffff\n", "gggg\n"} {
+		if _, err := io.WriteString(w, line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"app.log":   "gggg\n",
+		"app.log.1": "eeee\nffff\n",
+		"app.log.2": "cccc\ndddd\n",
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != len(want) {
+		t.Fatalf("files = %v, want %d", entries, len(want))
+	}
+	for name, content := range want {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != content {
+			t.Errorf("%s = %q, want %q", name, b, content)
+		}
+	}
+
+	if _, err := w.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("Write after Close = %v", err)
+	}
+}
+
+func TestRotatingFileWriterResumesSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(path, []byte("12345678"), 0o644)
+
+	w, err := NewRotatingFileWriter(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	io.WriteString(w, "abc")
+	if b, _ := os.ReadFile(path + ".1"); string(b) != "12345678" {
+		t.Fatalf("existing content not rotated: %q", b)
+	}
+}
@@ -0,0 +1,102 @@
+package iox
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFileWriter appends to a file and rotates it once it would grow
+// past a size limit: path becomes path.1, path.1 becomes path.2 and so on,
+// keeping at most a fixed number of old files.
+type RotatingFileWriter struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewRotatingFileWriter opens path for appending, creating it if needed.
+// A single Write larger than maxBytes is written whole to a fresh file.
+func NewRotatingFileWriter(path string, maxBytes int64, maxBackups int) (*RotatingFileWriter, error) {
+	w := &RotatingFileWriter{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *RotatingFileWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.f, w.size = f, info.Size()
+	return nil
+}
+
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f == nil {
+		return 0, os.ErrClosed
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups up by one and starts a new file. w.mu must be
+// held.
+func (w *RotatingFileWriter) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	w.f = nil
+
+	if w.maxBackups < 1 {
+		if err := os.Remove(w.path); err != nil {
+			return err
+		}
+		return w.open()
+	}
+	os.Remove(w.backup(w.maxBackups))
+	for i := w.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(w.backup(i), w.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(w.path, w.backup(1)); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+func (w *RotatingFileWriter) backup(i int) string {
+	return fmt.Sprintf("%s.%d", w.path, i)
+}
+
+// Close closes the current file.
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f == nil {
+		return os.ErrClosed
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/query"
+)
+
+// Query is how GET /admin/jobs filters, sorts and pages jobs (see
+// package query), so state=dead lists the dead letters and
+// attempts[gte]=3&sort=-run_at the jobs that keep failing.
+var Query = &query.Schema[Job]{
+	Fields: []query.Field[Job]{
+		query.String("id", func(j Job) string { return j.ID }),
+		query.String("kind", func(j Job) string { return j.Kind }),
+		query.String("state", func(j Job) string { return string(j.State) }).OneOf(string(Pending), string(Running), string(Dead)),
+		query.Int("attempts", func(j Job) int { return j.Attempts }),
+		query.Time("created", func(j Job) time.Time { return j.Created }),
+		query.Time("run_at", func(j Job) time.Time { return j.RunAt }),
+	},
+}
+
+// Handler is the admin API for q:
+//
+//	GET  /admin/jobs?state=dead   jobs, oldest first, taking Query's parameters
+//	POST /admin/jobs/{id}/retry   make a dead job pending again
+//
+// It has no access control of its own; mount it behind whatever guards
+// admin endpoints.
+func Handler(q *Queue) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/jobs", func(w http.ResponseWriter, r *http.Request) {
+		qy, err := Query.Parse(r.URL.Query())
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		jobs, total, err := Query.Apply(q.Jobs(""), qy)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		query.SetHeaders(w, r, qy, total)
+		writeJSON(w, http.StatusOK, jobs)
+	})
+	mux.HandleFunc("POST /admin/jobs/{id}/retry", func(w http.ResponseWriter, r *http.Request) {
+		err := q.Retry(r.PathValue("id"))
+		switch {
+		case err == nil:
+			w.WriteHeader(http.StatusNoContent)
+		case errors.Is(err, ErrNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, ErrNotDead):
+			writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		default:
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,386 @@
+// Package jobs runs background work that has to happen eventually even
+// if it fails for a while or the process restarts: webhook deliveries,
+// snapshots and the like.
+//
+// A Queue holds jobs of named kinds, each with a JSON payload. Register
+// gives a kind its handler and returns a Type for enqueueing payloads of
+// the right Go type. Run feeds due jobs to a workerpool. A job that fails
+// is tried again after a backoff; one that fails too often, or with an
+// error marked retry.Permanent, is dead-lettered: it stays in the queue,
+// marked dead, until an operator retries it. A handler that was turned
+// away before doing any work, by an open circuit breaker say, returns an
+// error from RetryAt: the job runs again then without using up an attempt.
+//
+// Every change goes to a Store before it takes effect, so with a
+// FileStore a restart picks up where the last run stopped. A job that was
+// running when the process died runs again: handlers must cope with
+// doing their work twice.
+package jobs
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+	"github.com/stawuah/pounce-on-go/concurrency/workerpool"
+	"github.com/stawuah/pounce-on-go/logging"
+	"github.com/stawuah/pounce-on-go/retry"
+)
+
+var (
+	// ErrUnknownKind fails a job whose kind has no handler.
+	ErrUnknownKind = errors.New("jobs: no handler for job kind")
+	// ErrNotFound is returned for a job ID the queue does not hold.
+	ErrNotFound = errors.New("jobs: no such job")
+	// ErrNotDead is returned by Retry for a job that has not failed.
+	ErrNotDead = errors.New("jobs: job is not dead")
+)
+
+// State is where a job is in its life.
+type State string
+
+const (
+	Pending State = "pending" // waiting for its RunAt
+	Running State = "running"
+	Dead    State = "dead" // failed for good; see LastError
+)
+
+// Job is one unit of work. A job that succeeds is removed from the queue.
+type Job struct {
+	ID        string          `json:"id"`
+	Kind      string          `json:"kind"`
+	Payload   json.RawMessage `json:"payload"`
+	State     State           `json:"state"`
+	Attempts  int             `json:"attempts"`
+	Created   time.Time       `json:"created"`
+	RunAt     time.Time       `json:"run_at"`
+	LastError string          `json:"last_error,omitempty"`
+}
+
+type retryAtError struct {
+	at  time.Time
+	err error
+}
+
+func (e retryAtError) Error() string { return e.err.Error() }
+func (e retryAtError) Unwrap() error { return e.err }
+
+// RetryAt wraps err, returned by a handler that did not get to do its
+// work, so that the job runs again at t without it counting as an
+// attempt.
+func RetryAt(t time.Time, err error) error {
+	if err == nil {
+		return nil
+	}
+	return retryAtError{at: t, err: err}
+}
+
+// Option configures a Queue.
+type Option func(*Queue)
+
+// WithMaxAttempts sets how many times a job is tried before it is
+// dead-lettered. The default is 5.
+func WithMaxAttempts(n int) Option {
+	return func(q *Queue) { q.attempts = max(n, 1) }
+}
+
+// WithBackoff sets how long a failed job waits before its next attempt.
+// The default is jittered exponential backoff from 1s capped at 5m.
+func WithBackoff(b retry.Backoff) Option {
+	return func(q *Queue) { q.backoff = b }
+}
+
+// WithClock sets the clock jobs are scheduled by. It defaults to the
+// system clock.
+func WithClock(c clock.Clock) Option {
+	return func(q *Queue) { q.clock = c }
+}
+
+// Queue holds jobs and runs them. Its methods are safe for concurrent
+// use.
+type Queue struct {
+	store    Store
+	clock    clock.Clock
+	attempts int
+	backoff  retry.Backoff
+	wake     chan struct{} // has a value when there may be new work
+
+	mu       sync.Mutex
+	jobs     map[string]Job
+	handlers map[string]func(context.Context, json.RawMessage) error
+}
+
+// New returns a Queue holding the jobs already in store. Jobs that were
+// running when their last process stopped are pending again.
+func New(store Store, opts ...Option) (*Queue, error) {
+	q := &Queue{
+		store:    store,
+		clock:    clock.Real{},
+		attempts: 5,
+		backoff:  retry.Jitter(retry.Exponential(time.Second, 5*time.Minute)),
+		wake:     make(chan struct{}, 1),
+		jobs:     make(map[string]Job),
+		handlers: make(map[string]func(context.Context, json.RawMessage) error),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	jobs, err := store.All()
+	if err != nil {
+		return nil, err
+	}
+	for _, j := range jobs {
+		if j.State == Running {
+			j.State = Pending
+			if err := store.Put(j); err != nil {
+				return nil, err
+			}
+		}
+		q.jobs[j.ID] = j
+	}
+	return q, nil
+}
+
+// Type enqueues jobs of one kind, whose payloads are T values.
+type Type[T any] struct {
+	q    *Queue
+	kind string
+}
+
+// Register makes fn the handler for jobs of kind, replacing any earlier
+// one, and returns the Type for enqueueing them. Register every kind
+// before Run, so that stored jobs find their handlers. A payload that no
+// longer decodes into a T kills its job.
+func Register[T any](q *Queue, kind string, fn func(context.Context, T) error) Type[T] {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[kind] = func(ctx context.Context, payload json.RawMessage) error {
+		var v T
+		if err := json.Unmarshal(payload, &v); err != nil {
+			return retry.Permanent(fmt.Errorf("jobs: decoding %s payload: %w", kind, err))
+		}
+		return fn(ctx, v)
+	}
+	return Type[T]{q: q, kind: kind}
+}
+
+// Kind returns the kind given to Register.
+func (t Type[T]) Kind() string { return t.kind }
+
+// Enqueue stores a job to run v as soon as a worker is free, and returns
+// its ID.
+func (t Type[T]) Enqueue(v T) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("jobs: encoding %s payload: %w", t.kind, err)
+	}
+	now := t.q.clock.Now()
+	j := Job{ID: newID(), Kind: t.kind, Payload: payload, State: Pending, Created: now, RunAt: now}
+	t.q.mu.Lock()
+	defer t.q.mu.Unlock()
+	if err := t.q.store.Put(j); err != nil {
+		return "", err
+	}
+	t.q.jobs[j.ID] = j
+	t.q.signal()
+	return j.ID, nil
+}
+
+// signal wakes Run to look for due work.
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run runs due jobs on workers goroutines until ctx ends. Jobs still
+// running then are cancelled and go back to pending without using up an
+// attempt. Failures are logged to ctx's logger. Run returns nil.
+func (q *Queue) Run(ctx context.Context, workers int) error {
+	pool := workerpool.New(workers, 0, q.run)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for r := range pool.Results() {
+			q.finish(ctx, r.Job, r.Err)
+		}
+	}()
+	q.dispatch(ctx, pool)
+	pool.Abort()
+	<-finished
+	return nil
+}
+
+// dispatch submits due jobs to pool until ctx ends, sleeping until the
+// next job falls due or a new one arrives.
+func (q *Queue) dispatch(ctx context.Context, pool *workerpool.Pool[Job, struct{}]) {
+	for {
+		j, wait, ok := q.next(ctx)
+		if ok {
+			if err := pool.Submit(ctx, j); err != nil {
+				q.requeue(ctx, j)
+				return
+			}
+			continue
+		}
+		var due <-chan time.Time
+		if wait > 0 {
+			due = q.clock.After(wait)
+		}
+		select {
+		case <-q.wake:
+		case <-due:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// next marks the pending job that fell due first as running and returns
+// it. With nothing due, it returns how long until something is, or 0 if
+// nothing is pending.
+func (q *Queue) next(ctx context.Context) (Job, time.Duration, bool) {
+	now := q.clock.Now()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var first Job
+	found := false
+	for _, j := range q.jobs {
+		if j.State != Pending {
+			continue
+		}
+		if !found || cmp.Or(j.RunAt.Compare(first.RunAt), j.Created.Compare(first.Created)) < 0 {
+			first, found = j, true
+		}
+	}
+	if !found {
+		return Job{}, 0, false
+	}
+	if first.RunAt.After(now) {
+		return Job{}, first.RunAt.Sub(now), false
+	}
+	first.State = Running
+	q.save(ctx, first)
+	return first, 0, true
+}
+
+// run is the workerpool's job function.
+func (q *Queue) run(ctx context.Context, j Job) (struct{}, error) {
+	q.mu.Lock()
+	h, ok := q.handlers[j.Kind]
+	q.mu.Unlock()
+	if !ok {
+		return struct{}{}, retry.Permanent(fmt.Errorf("%w %q", ErrUnknownKind, j.Kind))
+	}
+	return struct{}{}, h(ctx, j.Payload)
+}
+
+// finish records the outcome of running j.
+func (q *Queue) finish(ctx context.Context, j Job, err error) {
+	if err != nil && ctx.Err() != nil {
+		// Cancelled by shutdown, not a failure of the job.
+		q.requeue(ctx, j)
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err == nil {
+		delete(q.jobs, j.ID)
+		if err := q.store.Delete(j.ID); err != nil {
+			logging.FromContext(ctx).Error("jobs: storing job", "id", j.ID, "err", err)
+		}
+		return
+	}
+	log := logging.FromContext(ctx).With("id", j.ID, "kind", j.Kind)
+	j.LastError = err.Error()
+	var later retryAtError
+	if errors.As(err, &later) {
+		j.State, j.RunAt = Pending, later.at
+		log.Info("jobs: job deferred", "attempts", j.Attempts, "retry_at", j.RunAt, "err", err)
+		q.save(ctx, j)
+		q.signal()
+		return
+	}
+	j.Attempts++
+	if retry.IsPermanent(err) || j.Attempts >= q.attempts {
+		j.State = Dead
+		log.Error("jobs: job dead", "attempts", j.Attempts, "err", err)
+	} else {
+		j.State = Pending
+		j.RunAt = q.clock.Now().Add(q.backoff(j.Attempts))
+		log.Warn("jobs: job failed", "attempts", j.Attempts, "retry_at", j.RunAt, "err", err)
+	}
+	q.save(ctx, j)
+	q.signal()
+}
+
+// requeue makes a job that did not get to run, or was cut short, pending
+// again.
+func (q *Queue) requeue(ctx context.Context, j Job) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j.State = Pending
+	q.save(ctx, j)
+}
+
+// save updates j in memory and in the store. Losing a state change is
+// logged rather than returned: the job carries on either way, and at
+// worst runs again after a restart. q.mu must be held.
+func (q *Queue) save(ctx context.Context, j Job) {
+	q.jobs[j.ID] = j
+	if err := q.store.Put(j); err != nil {
+		logging.FromContext(ctx).Error("jobs: storing job", "id", j.ID, "err", err)
+	}
+}
+
+// Jobs returns the jobs in state, or every job if state is empty, oldest
+// first.
+func (q *Queue) Jobs(state State) []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var out []Job
+	for _, j := range q.jobs {
+		if state == "" || j.State == state {
+			out = append(out, j)
+		}
+	}
+	slices.SortFunc(out, func(a, b Job) int {
+		return cmp.Or(a.Created.Compare(b.Created), cmp.Compare(a.ID, b.ID))
+	})
+	return out
+}
+
+// Retry makes a dead job pending again, with a fresh set of attempts.
+func (q *Queue) Retry(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if j.State != Dead {
+		return fmt.Errorf("%w: %s is %s", ErrNotDead, id, j.State)
+	}
+	j.State, j.Attempts, j.RunAt = Pending, 0, q.clock.Now()
+	if err := q.store.Put(j); err != nil {
+		return err
+	}
+	q.jobs[id] = j
+	q.signal()
+	return nil
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
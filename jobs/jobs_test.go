@@ -0,0 +1,229 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+	"github.com/stawuah/pounce-on-go/concurrency/leaktest"
+	"github.com/stawuah/pounce-on-go/logging"
+	"github.com/stawuah/pounce-on-go/retry"
+)
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// start runs q until the test ends.
+func start(t *testing.T, q *Queue) {
+	ctx, cancel := context.WithCancel(logging.WithContext(context.Background(), logging.Discard))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.Run(ctx, 2)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+type resize struct {
+	SKU   string `json:"sku"`
+	Width int    `json:"width"`
+}
+
+func TestRetriesAndDeadLetters(t *testing.T) {
+	leaktest.Check(t)
+
+	q, err := New(NewMemoryStore(), WithMaxAttempts(3), WithBackoff(retry.Constant(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	tries := map[string]int{}
+	resizes := Register(q, "resize", func(_ context.Context, r resize) error {
+		mu.Lock()
+		defer mu.Unlock()
+		tries[r.SKU]++
+		switch {
+		case r.SKU == "flaky" && tries[r.SKU] < 3:
+			return errors.New("image server busy")
+		case r.SKU == "broken":
+			return errors.New("corrupt image")
+		case r.SKU == "missing":
+			return retry.Permanent(errors.New("no image"))
+		}
+		return nil
+	})
+	for _, sku := range []string{"ok", "flaky", "broken", "missing"} {
+		if _, err := resizes.Enqueue(resize{SKU: sku, Width: 200}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start(t, q)
+	waitFor(t, "jobs to settle", func() bool { return len(q.Jobs(Dead)) == 2 && len(q.Jobs("")) == 2 })
+
+	mu.Lock()
+	if tries["ok"] != 1 || tries["flaky"] != 3 || tries["broken"] != 3 || tries["missing"] != 1 {
+		t.Errorf("tries = %v", tries)
+	}
+	mu.Unlock()
+	for _, j := range q.Jobs(Dead) {
+		var r resize
+		json.Unmarshal(j.Payload, &r)
+		if want := map[string]string{"broken": "corrupt image", "missing": "no image"}[r.SKU]; j.LastError != want {
+			t.Errorf("dead job %s: LastError = %q, want %q", r.SKU, j.LastError, want)
+		}
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.ndjson")
+	fs, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := clock.NewFake(time.Date(2025, 3, 7, 9, 30, 0, 0, time.UTC))
+	q, err := New(fs, WithClock(clk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resizes := Register(q, "resize", func(context.Context, resize) error { return nil })
+	id, _ := resizes.Enqueue(resize{SKU: "A1", Width: 100})
+	clk.Advance(time.Second)
+	resizes.Enqueue(resize{SKU: "A2", Width: 100})
+	// As if the process died while A2 was running.
+	running := q.Jobs("")[1]
+	running.State = Running
+	fs.Put(running)
+
+	fs, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err = New(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := q.Jobs(Pending)
+	if len(got) != 2 || got[0].ID != id || string(got[0].Payload) != `{"sku":"A1","width":100}` {
+		t.Fatalf("reloaded jobs = %+v", got)
+	}
+
+	// Nothing handles resize in this process.
+	start(t, q)
+	waitFor(t, "jobs to die", func() bool { return len(q.Jobs(Dead)) == 2 })
+	if j := q.Jobs(Dead)[0]; !strings.Contains(j.LastError, `no handler for job kind "resize"`) {
+		t.Fatalf("LastError = %q", j.LastError)
+	}
+	if all, _ := fs.All(); len(all) != 2 || all[0].State != Dead {
+		t.Fatalf("stored jobs = %+v", all)
+	}
+}
+
+func TestShutdownRequeues(t *testing.T) {
+	leaktest.Check(t)
+
+	q, _ := New(NewMemoryStore())
+	started := make(chan struct{})
+	slow := Register(q, "slow", func(ctx context.Context, _ struct{}) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	slow.Enqueue(struct{}{})
+	ctx, cancel := context.WithCancel(logging.WithContext(context.Background(), logging.Discard))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.Run(ctx, 1)
+	}()
+	<-started
+	cancel()
+	<-done
+	if got := q.Jobs(""); len(got) != 1 || got[0].State != Pending || got[0].Attempts != 0 {
+		t.Fatalf("after shutdown: %+v", got)
+	}
+	if err := q.Retry(q.Jobs("")[0].ID); !errors.Is(err, ErrNotDead) {
+		t.Fatalf("Retry of a pending job err = %v", err)
+	}
+}
+
+func TestRetryAtKeepsAttempts(t *testing.T) {
+	leaktest.Check(t)
+
+	clk := clock.NewFake(time.Unix(1_000, 0))
+	q, _ := New(NewMemoryStore(), WithMaxAttempts(1), WithClock(clk))
+	later := clk.Now().Add(time.Minute)
+	var tries atomic.Int32
+	turnedAway := Register(q, "turned-away", func(context.Context, struct{}) error {
+		tries.Add(1)
+		return RetryAt(later, errors.New("circuit open"))
+	})
+	turnedAway.Enqueue(struct{}{})
+	start(t, q)
+	waitFor(t, "the job to be deferred", func() bool { return tries.Load() == 1 && len(q.Jobs(Pending)) == 1 })
+
+	j := q.Jobs("")[0]
+	if j.State != Pending || j.Attempts != 0 || !j.RunAt.Equal(later) || j.LastError != "circuit open" {
+		t.Fatalf("deferred job = %+v", j)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	q, _ := New(NewMemoryStore(), WithMaxAttempts(1))
+	fail := Register(q, "fail", func(context.Context, int) error { return errors.New("nope") })
+	dead, _ := fail.Enqueue(1)
+	start(t, q)
+	waitFor(t, "the job to die", func() bool { return len(q.Jobs(Dead)) == 1 })
+
+	h := Handler(q)
+	do := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+	var list []Job
+	rec := do("GET", "/admin/jobs?state=dead")
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list) != 1 || list[0].ID != dead || list[0].LastError != "nope" {
+		t.Fatalf("GET dead = %d %s", rec.Code, rec.Body)
+	}
+	if rec := do("GET", "/admin/jobs?state=pending"); strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Fatalf("GET pending = %s", rec.Body)
+	}
+	for _, tc := range []struct {
+		method, path string
+		status       int
+	}{
+		{"GET", "/admin/jobs?state=done", 400},
+		{"GET", "/admin/jobs?sort=-attempts&attempts[gte]=1", 200},
+		{"GET", "/admin/jobs?sort=payload", 400},
+		{"POST", "/admin/jobs/nope/retry", 404},
+		{"POST", "/admin/jobs/" + dead + "/retry", 204},
+	} {
+		if rec := do(tc.method, tc.path); rec.Code != tc.status {
+			t.Errorf("%s %s = %d, want %d", tc.method, tc.path, rec.Code, tc.status)
+		}
+	}
+	// The retried job fails again, once.
+	waitFor(t, "the retried job to die", func() bool {
+		j := q.Jobs(Dead)
+		return len(j) == 1 && j[0].Attempts == 1 && j[0].RunAt.After(j[0].Created)
+	})
+}
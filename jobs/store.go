@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"cmp"
+	"fmt"
+
+	"github.com/stawuah/pounce-on-go/repository"
+)
+
+// Store keeps a Queue's jobs so they outlive the process. The Queue calls
+// it on every change, from one goroutine at a time, and keeps its own
+// copy of the jobs; a Store only has to hold on to them.
+type Store interface {
+	Put(Job) error
+	Delete(id string) error
+	// All returns every job stored, in any order.
+	All() ([]Job, error)
+}
+
+// MemoryStore is a Store that forgets everything when the process exits,
+// for tests and for programs that do not need the jobs to survive.
+type MemoryStore struct {
+	*repository.Repository[string, Job]
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{repository.New(jobID, repository.WithOrder[string](oldestFirst))}
+}
+
+// All returns every job, oldest first.
+func (s *MemoryStore) All() ([]Job, error) { return s.Repository.All(), nil }
+
+// FileStore is a Store kept in an NDJSON file, one job per line, oldest
+// first so the file reads as a log. Every change rewrites the file
+// atomically (see repository.Open). That is fine for the tens or hundreds
+// of jobs a queue normally holds.
+type FileStore struct {
+	*repository.Repository[string, Job]
+}
+
+// OpenFileStore reads the jobs in path. A missing file is an empty store;
+// it is created on the first change.
+func OpenFileStore(path string) (*FileStore, error) {
+	r, err := repository.Open(path, jobID, repository.WithOrder[string](oldestFirst))
+	if err != nil {
+		return nil, fmt.Errorf("jobs: %w", err)
+	}
+	return &FileStore{r}, nil
+}
+
+// All returns every job, oldest first.
+func (s *FileStore) All() ([]Job, error) { return s.Repository.All(), nil }
+
+func jobID(j Job) string { return j.ID }
+
+func oldestFirst(a, b Job) int {
+	return cmp.Or(a.Created.Compare(b.Created), cmp.Compare(a.ID, b.ID))
+}
@@ -0,0 +1,128 @@
+package jsonx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Payload is one of the event kinds an Event can carry.
+type Payload interface {
+	// EventType is the tag written to the "type" field.
+	EventType() string
+}
+
+// ProductCreated reports a product stored under a new SKU.
+type ProductCreated struct {
+	SKU    string        `json:"sku"`
+	Name   string        `json:"name"`
+	Price  float64       `json:"price"`
+	Status ProductStatus `json:"status,omitempty"`
+}
+
+// PriceChanged reports an existing product stored with a new price.
+type PriceChanged struct {
+	SKU string  `json:"sku"`
+	Old float64 `json:"old"`
+	New float64 `json:"new"`
+}
+
+// StatusChanged reports an existing product stored with a new status.
+type StatusChanged struct {
+	SKU  string        `json:"sku"`
+	From ProductStatus `json:"from,omitempty"`
+	To   ProductStatus `json:"to,omitempty"`
+}
+
+// ProductUpdated reports an existing product stored with changes no
+// other event covers, such as a new name or category. It names only the
+// product: a subscriber that cares fetches it.
+type ProductUpdated struct {
+	SKU string `json:"sku"`
+}
+
+func (ProductCreated) EventType() string { return "product.created" }
+func (PriceChanged) EventType() string   { return "product.price_changed" }
+func (StatusChanged) EventType() string  { return "product.status_changed" }
+func (ProductUpdated) EventType() string { return "product.updated" }
+
+// payloads maps each tag to the decoder for its payload, which is how
+// UnmarshalJSON knows what to decode "data" into.
+var payloads = map[string]func(json.RawMessage) (Payload, error){
+	ProductCreated{}.EventType(): decodePayload[ProductCreated],
+	PriceChanged{}.EventType():   decodePayload[PriceChanged],
+	StatusChanged{}.EventType():  decodePayload[StatusChanged],
+	ProductUpdated{}.EventType(): decodePayload[ProductUpdated],
+}
+
+func decodePayload[P Payload](data json.RawMessage) (Payload, error) {
+	var p P
+	err := json.Unmarshal(data, &p)
+	return p, err
+}
+
+// Event is a tagged union. It encodes as
+//
+//	{"type":"product.price_changed","at":"...","data":{"sku":"A1","old":9,"new":12}}
+//
+// and decodes back into an Event whose Payload has the concrete type the
+// tag names, held by value.
+type Event struct {
+	At      time.Time
+	Payload Payload
+	// Trace is the traceparent of the request that caused the event, if
+	// it was traced, so consumers can continue its trace. It encodes as
+	// "traceparent" and is left out when empty.
+	Trace string
+}
+
+type eventJSON struct {
+	Type  string          `json:"type"`
+	At    time.Time       `json:"at"`
+	Data  json.RawMessage `json:"data"`
+	Trace string          `json:"traceparent,omitempty"`
+}
+
+// TraceParent returns e.Trace, so that code generic over event types,
+// such as a webhook bridge, can find it.
+func (e Event) TraceParent() string { return e.Trace }
+
+// Type returns the payload's tag, or "" if there is no payload.
+func (e Event) Type() string {
+	if e.Payload == nil {
+		return ""
+	}
+	return e.Payload.EventType()
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	if e.Payload == nil {
+		return nil, errors.New("jsonx: event has no payload")
+	}
+	data, err := json.Marshal(e.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(eventJSON{Type: e.Payload.EventType(), At: e.At, Data: data, Trace: e.Trace})
+}
+
+func (e *Event) UnmarshalJSON(b []byte) error {
+	var raw eventJSON
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	decode, ok := payloads[raw.Type]
+	if !ok {
+		return fmt.Errorf("%w: unknown event type %q", ErrInvalid, raw.Type)
+	}
+	if len(raw.Data) == 0 || string(raw.Data) == "null" {
+		return fmt.Errorf("%w: %s event has no data", ErrInvalid, raw.Type)
+	}
+	p, err := decode(raw.Data)
+	if err != nil {
+		return fmt.Errorf("%s event: %w", raw.Type, err)
+	}
+	*e = Event{At: raw.At, Payload: p, Trace: raw.Trace}
+	return nil
+}
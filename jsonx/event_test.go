@@ -0,0 +1,76 @@
+package jsonx
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEventRoundTrip(t *testing.T) {
+	at := time.Date(2025, 3, 7, 9, 30, 0, 0, time.UTC)
+	events := []Event{
+		{At: at, Payload: ProductCreated{SKU: "A1", Name: "Anvil", Price: 9, Status: StatusActive}},
+		{At: at, Payload: PriceChanged{SKU: "A1", Old: 9, New: 12.5}},
+		{At: at, Payload: StatusChanged{SKU: "A1", From: StatusActive, To: StatusDiscontinued}},
+		{At: at, Payload: ProductUpdated{SKU: "A1"}},
+		{At: at, Payload: PriceChanged{SKU: "B2", Old: 1, New: 2}, Trace: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	}
+	for _, e := range events {
+		b, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Event
+		if err := json.Unmarshal(b, &back); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", b, err)
+		}
+		if back != e {
+			t.Fatalf("round trip of %s = %+v, want %+v", b, back, e)
+		}
+	}
+
+	b, _ := json.Marshal(events[1])
+	want := `{"type":"product.price_changed","at":"2025-03-07T09:30:00Z","data":{"sku":"A1","old":9,"new":12.5}}`
+	if string(b) != want {
+		t.Fatalf("Marshal = %s, want %s", b, want)
+	}
+}
+
+func TestEventTypeSwitch(t *testing.T) {
+	var e Event
+	in := `{"type":"product.status_changed","at":"2025-03-07T09:30:00Z","data":{"sku":"A1","to":"draft"}}`
+	if err := json.Unmarshal([]byte(in), &e); err != nil {
+		t.Fatal(err)
+	}
+	switch p := e.Payload.(type) {
+	case StatusChanged:
+		if p.To != StatusDraft || p.From != 0 {
+			t.Fatalf("payload = %+v", p)
+		}
+	default:
+		t.Fatalf("payload is %T, want StatusChanged", p)
+	}
+	if e.Type() != "product.status_changed" {
+		t.Fatalf("Type() = %q", e.Type())
+	}
+}
+
+func TestEventErrors(t *testing.T) {
+	if _, err := json.Marshal(Event{}); err == nil {
+		t.Fatal("Marshal of an event with no payload succeeded")
+	}
+	tests := []string{
+		`{"type":"product.deleted","data":{"sku":"A1"}}`,
+		`{"data":{"sku":"A1"}}`,
+		`{"type":"product.created"}`,
+		`{"type":"product.created","data":null}`,
+		`{"type":"product.status_changed","data":{"sku":"A1","to":"gone"}}`,
+	}
+	for _, in := range tests {
+		var e Event
+		if err := json.Unmarshal([]byte(in), &e); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Unmarshal(%s) err = %v, want ErrInvalid", in, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Package jsonx holds the types whose JSON form is not what
+// encoding/json would produce on its own: a calendar Date written as
+// YYYY-MM-DD, the ProductStatus enum written as its name, Money written
+// as an exact decimal string and its currency, and Event, a tagged union
+// whose "type" field says how to decode its "data".
+//
+// Each type validates while decoding, so a bad value is rejected at the
+// edge with an error naming what was wrong rather than turning up later
+// as a zero value.
+package jsonx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalid is wrapped by every decoding error this package returns.
+var ErrInvalid = errors.New("jsonx: invalid value")
+
+const dateLayout = "2006-01-02"
+
+// Date is a calendar date with no time of day or zone. The zero Date is
+// "no date" and encodes as null.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date t falls on in its own location.
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{y, m, d}
+}
+
+// ParseDate parses a YYYY-MM-DD date, rejecting days that do not exist.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("%w: date %q: want YYYY-MM-DD", ErrInvalid, s)
+	}
+	return DateOf(t), nil
+}
+
+// String returns the date as YYYY-MM-DD.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// IsZero reports whether d is the zero Date, so a field tagged omitzero
+// is left out.
+func (d Date) IsZero() bool { return d == Date{} }
+
+// Time returns midnight at the start of d in loc.
+func (d Date) Time(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// Before reports whether d is earlier than e.
+func (d Date) Before(e Date) bool {
+	if d.Year != e.Year {
+		return d.Year < e.Year
+	}
+	if d.Month != e.Month {
+		return d.Month < e.Month
+	}
+	return d.Day < e.Day
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("%w: date must be a string", ErrInvalid)
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,160 @@
+package jsonx
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDateJSON(t *testing.T) {
+	type release struct {
+		On    Date `json:"on"`
+		Until Date `json:"until,omitzero"`
+	}
+	b, err := json.Marshal(release{On: Date{2025, time.March, 7}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"on":"2025-03-07"}`; got != want {
+		t.Fatalf("Marshal = %s, want %s", got, want)
+	}
+
+	tests := []struct {
+		in      string
+		want    Date
+		wantErr bool
+	}{
+		{`{"on":"2024-02-29"}`, Date{2024, time.February, 29}, false},
+		{`{"on":null}`, Date{}, false},
+		{`{}`, Date{}, false},
+		{`{"on":"2023-02-29"}`, Date{}, true},
+		{`{"on":"07/03/2025"}`, Date{}, true},
+		{`{"on":"2025-03-07T10:00:00Z"}`, Date{}, true},
+		{`{"on":20250307}`, Date{}, true},
+	}
+	for _, tt := range tests {
+		var r release
+		err := json.Unmarshal([]byte(tt.in), &r)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalid) {
+				t.Errorf("Unmarshal(%s) err = %v, want ErrInvalid", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || r.On != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, %v; want %v", tt.in, r.On, err, tt.want)
+		}
+	}
+}
+
+func TestDateHelpers(t *testing.T) {
+	tm := time.Date(2025, time.December, 31, 23, 30, 0, 0, time.UTC)
+	d := DateOf(tm)
+	if d.String() != "2025-12-31" {
+		t.Fatalf("DateOf = %v", d)
+	}
+	// The same instant is already the next day further east.
+	if got := DateOf(tm.In(time.FixedZone("UTC+2", 2*60*60))); got.String() != "2026-01-01" {
+		t.Fatalf("DateOf in UTC+2 = %v", got)
+	}
+	if !d.Time(time.UTC).Equal(time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Time = %v", d.Time(time.UTC))
+	}
+	for _, tt := range []struct {
+		a, b Date
+		want bool
+	}{
+		{Date{2024, 12, 31}, Date{2025, 1, 1}, true},
+		{Date{2025, 1, 31}, Date{2025, 2, 1}, true},
+		{Date{2025, 2, 1}, Date{2025, 2, 2}, true},
+		{Date{2025, 2, 2}, Date{2025, 2, 2}, false},
+		{Date{2025, 3, 1}, Date{2025, 2, 28}, false},
+	} {
+		if got := tt.a.Before(tt.b); got != tt.want {
+			t.Errorf("%v.Before(%v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestProductStatusJSON(t *testing.T) {
+	for _, s := range []ProductStatus{StatusDraft, StatusActive, StatusDiscontinued} {
+		b, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back ProductStatus
+		if err := json.Unmarshal(b, &back); err != nil || back != s {
+			t.Fatalf("round trip of %v via %s = %v, %v", s, b, back, err)
+		}
+	}
+	if b, _ := json.Marshal(StatusActive); string(b) != `"active"` {
+		t.Fatalf("Marshal(StatusActive) = %s", b)
+	}
+
+	type product struct {
+		Status ProductStatus `json:"status,omitempty"`
+	}
+	if b, _ := json.Marshal(product{}); string(b) != `{}` {
+		t.Fatalf("unset status = %s, want it omitted", b)
+	}
+	if _, err := json.Marshal(product{Status: 7}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Marshal(ProductStatus(7)) err = %v, want ErrInvalid", err)
+	}
+	for _, in := range []string{`{"status":"retired"}`, `{"status":""}`, `{"status":2}`} {
+		var p product
+		if err := json.Unmarshal([]byte(in), &p); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Unmarshal(%s) err = %v, want ErrInvalid", in, err)
+		}
+	}
+	if StatusDiscontinued.String() != "discontinued" || ProductStatus(0).String() != "ProductStatus(0)" {
+		t.Fatalf("String() = %q, %q", StatusDiscontinued, ProductStatus(0))
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	for _, m := range []Money{{1234, "USD"}, {-5, "EUR"}, {700, "GBP"}, {0, "USD"}} {
+		b, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Money
+		if err := json.Unmarshal(b, &back); err != nil || back != m {
+			t.Fatalf("round trip of %v via %s = %v, %v", m, b, back, err)
+		}
+	}
+	if b, _ := json.Marshal(Money{-5, "EUR"}); string(b) != `{"amount":"-0.05","currency":"EUR"}` {
+		t.Fatalf("Marshal = %s", b)
+	}
+
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{`{"amount":"19.9","currency":"USD"}`, Money{1990, "USD"}, false},
+		{`{"amount":"3","currency":"USD"}`, Money{300, "USD"}, false},
+		{`{"amount":"0.001","currency":"USD"}`, Money{}, true},
+		{`{"amount":"1e3","currency":"USD"}`, Money{}, true},
+		{`{"amount":".5","currency":"USD"}`, Money{}, true},
+		{`{"amount":"5","currency":"usd"}`, Money{}, true},
+		{`{"amount":5,"currency":"USD"}`, Money{}, true},
+		{`19.99`, Money{}, true},
+	}
+	for _, tt := range tests {
+		var m Money
+		err := json.Unmarshal([]byte(tt.in), &m)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalid) {
+				t.Errorf("Unmarshal(%s) err = %v, want ErrInvalid", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || m != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, %v; want %v", tt.in, m, err, tt.want)
+		}
+	}
+	if m := MoneyOf(0.1+0.2, "USD"); m.Cents != 30 || m.Float() != 0.3 || m.String() != "0.30 USD" {
+		t.Fatalf("MoneyOf(0.1+0.2) = %v (%v)", m, m.Float())
+	}
+}
@@ -0,0 +1,80 @@
+package jsonx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact amount in a currency's hundredths, such as cents. It
+// encodes as {"amount":"12.34","currency":"USD"}: the amount is a decimal
+// string, so no float rounding creeps in between writer and reader.
+type Money struct {
+	Cents    int64
+	Currency string // ISO 4217, e.g. "USD"
+}
+
+// MoneyOf rounds v to the nearest hundredth of currency.
+func MoneyOf(v float64, currency string) Money {
+	return Money{Cents: int64(math.Round(v * 100)), Currency: currency}
+}
+
+// ParseMoney parses an amount such as "12.34", "-3" or "0.5" with at most
+// two decimal places.
+func ParseMoney(amount, currency string) (Money, error) {
+	if len(currency) != 3 || strings.ToUpper(currency) != currency {
+		return Money{}, fmt.Errorf("%w: currency %q: want a three-letter code such as USD", ErrInvalid, currency)
+	}
+	whole, frac, _ := strings.Cut(amount, ".")
+	neg := strings.HasPrefix(whole, "-")
+	digits := strings.TrimPrefix(whole, "-")
+	if len(frac) > 2 || digits == "" || strings.Trim(digits+frac, "0123456789") != "" {
+		return Money{}, fmt.Errorf("%w: amount %q: want a decimal with at most two places", ErrInvalid, amount)
+	}
+	cents, err := strconv.ParseInt(digits+(frac + "00")[:2], 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: amount %q: %v", ErrInvalid, amount, err)
+	}
+	if neg {
+		cents = -cents
+	}
+	return Money{Cents: cents, Currency: currency}, nil
+}
+
+// Float returns the amount in whole units, e.g. 12.34 for 1234 cents.
+func (m Money) Float() float64 { return float64(m.Cents) / 100 }
+
+// Amount returns the amount as a decimal string, e.g. "12.34".
+func (m Money) Amount() string {
+	sign, c := "", m.Cents
+	if c < 0 {
+		sign, c = "-", -c
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, c/100, c%100)
+}
+
+func (m Money) String() string { return m.Amount() + " " + m.Currency }
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{m.Amount(), m.Currency})
+}
+
+func (m *Money) UnmarshalJSON(b []byte) error {
+	var v moneyJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return fmt.Errorf("%w: money must be an object with amount and currency", ErrInvalid)
+	}
+	parsed, err := ParseMoney(v.Amount, v.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
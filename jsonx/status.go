@@ -0,0 +1,65 @@
+package jsonx
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ProductStatus is where a product is in its life. It encodes as its name;
+// the zero value means "not set" and is left out by omitempty.
+type ProductStatus int
+
+const (
+	StatusDraft ProductStatus = iota + 1
+	StatusActive
+	StatusDiscontinued
+)
+
+var statusNames = []string{
+	StatusDraft:        "draft",
+	StatusActive:       "active",
+	StatusDiscontinued: "discontinued",
+}
+
+// ParseProductStatus returns the status with the given name.
+func ParseProductStatus(name string) (ProductStatus, error) {
+	for s, n := range statusNames {
+		if n != "" && n == name {
+			return ProductStatus(s), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: status %q: want one of %s", ErrInvalid, name, strings.Join(statusNames[1:], ", "))
+}
+
+// Valid reports whether s is one of the named statuses.
+func (s ProductStatus) Valid() bool {
+	return s > 0 && int(s) < len(statusNames)
+}
+
+func (s ProductStatus) String() string {
+	if !s.Valid() {
+		return fmt.Sprintf("ProductStatus(%d)", int(s))
+	}
+	return statusNames[s]
+}
+
+func (s ProductStatus) MarshalJSON() ([]byte, error) {
+	if !s.Valid() {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, s)
+	}
+	return json.Marshal(statusNames[s])
+}
+
+func (s *ProductStatus) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err != nil {
+		return fmt.Errorf("%w: status must be a string", ErrInvalid)
+	}
+	parsed, err := ParseProductStatus(name)
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
@@ -0,0 +1,149 @@
+package kvtcp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrProtocol is returned by Client methods for a reply the protocol does
+// not allow.
+var ErrProtocol = errors.New("kvtcp: protocol error")
+
+// Client is a connection to a kvtcp server. It is safe for concurrent use;
+// requests are serialized on the one connection.
+type Client struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	r       *bufio.Reader
+	Timeout time.Duration // per request; zero means none
+}
+
+// Dial connects to the server at addr.
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+// ErrInvalidKey is returned for an empty key or one containing
+// whitespace, which the line protocol cannot carry.
+var ErrInvalidKey = errors.New("kvtcp: invalid key")
+
+func checkKey(key string) error {
+	if !validKey(key) {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+// Get returns the value stored under key.
+func (c *Client) Get(key string) (string, bool, error) {
+	if err := checkKey(key); err != nil {
+		return "", false, err
+	}
+	var value string
+	var found bool
+	err := c.do("GET "+key, func(line string) error {
+		switch {
+		case line == "NIL":
+			return nil
+		case strings.HasPrefix(line, "VALUE "):
+			value, found = strings.TrimPrefix(line, "VALUE "), true
+			return nil
+		}
+		return unexpected(line)
+	})
+	return value, found, err
+}
+
+// Set stores value under key.
+func (c *Client) Set(key, value string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("kvtcp: value for %q contains a newline", key)
+	}
+	return c.do("SET "+key+" "+value, expectOK)
+}
+
+// Delete removes key.
+func (c *Client) Delete(key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	return c.do("DEL "+key, expectOK)
+}
+
+// Keys returns every key in sorted order.
+func (c *Client) Keys() ([]string, error) {
+	var keys []string
+	err := c.do("KEYS", func(line string) error {
+		n, err := strconv.Atoi(strings.TrimPrefix(line, "KEYS "))
+		if !strings.HasPrefix(line, "KEYS ") || err != nil || n < 0 {
+			return unexpected(line)
+		}
+		keys = make([]string, n)
+		for i := range keys {
+			if keys[i], err = c.readLine(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return keys, err
+}
+
+// Close says goodbye and closes the connection.
+func (c *Client) Close() error {
+	c.do("QUIT", expectOK)
+	return c.conn.Close()
+}
+
+// do sends one request and passes the first reply line to parse, turning
+// ERR replies into errors.
+func (c *Client) do(req string, parse func(line string) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Timeout > 0 {
+		c.conn.SetDeadline(time.Now().Add(c.Timeout))
+	}
+	if _, err := fmt.Fprintf(c.conn, "%s\n", req); err != nil {
+		return err
+	}
+	line, err := c.readLine()
+	if err != nil {
+		return err
+	}
+	if msg, ok := strings.CutPrefix(line, "ERR "); ok {
+		return fmt.Errorf("kvtcp: server: %s", msg)
+	}
+	return parse(line)
+}
+
+func (c *Client) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func expectOK(line string) error {
+	if line != "OK" {
+		return unexpected(line)
+	}
+	return nil
+}
+
+func unexpected(line string) error {
+	return fmt.Errorf("%w: unexpected reply %q", ErrProtocol, line)
+}
@@ -0,0 +1,175 @@
+package kvtcp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/concurrency/leaktest"
+	"github.com/stawuah/pounce-on-go/concurrency/ownership"
+	"github.com/stawuah/pounce-on-go/logging"
+)
+
+// start serves a fresh store on a loopback port and shuts it down when
+// the test ends.
+func start(t *testing.T, idle time.Duration) (*Server, string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &Server{Store: ownership.NewMutexStore(), IdleTimeout: idle, Logger: logging.Discard}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(l) }()
+	t.Cleanup(func() {
+		if err := srv.Shutdown(context.Background()); err != nil {
+			t.Error(err)
+		}
+		if err := <-served; !errors.Is(err, ErrServerClosed) {
+			t.Errorf("Serve = %v, want ErrServerClosed", err)
+		}
+	})
+	return srv, l.Addr().String()
+}
+
+func TestClientRoundTrip(t *testing.T) {
+	leaktest.Check(t)
+	_, addr := start(t, 0)
+	c, err := Dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, ok, err := c.Get("sku:1"); ok || err != nil {
+		t.Fatalf("Get on empty store = %v, %v", ok, err)
+	}
+	for k, v := range map[string]string{"sku:1": "Anvil, 50kg", "sku:2": "Hammer", "sku:0": ""} {
+		if err := c.Set(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v, ok, err := c.Get("sku:1"); v != "Anvil, 50kg" || !ok || err != nil {
+		t.Fatalf("Get = %q, %v, %v", v, ok, err)
+	}
+	if v, ok, _ := c.Get("sku:0"); v != "" || !ok {
+		t.Fatalf("empty value: %q, %v", v, ok)
+	}
+	if err := c.Delete("sku:2"); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := c.Keys()
+	if err != nil || !slices.Equal(keys, []string{"sku:0", "sku:1"}) {
+		t.Fatalf("Keys = %q, %v", keys, err)
+	}
+
+	if err := c.Set("bad key", "x"); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Set with a space in the key = %v", err)
+	}
+	if err := c.Set("k", "two\nlines"); err == nil {
+		t.Fatal("Set accepted a newline in the value")
+	}
+}
+
+func TestRawProtocol(t *testing.T) {
+	_, addr := start(t, 0)
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	tests := []struct{ req, resp string }{
+		{"set a 1", "OK"},
+		{"GET a", "VALUE 1"},
+		{"GET", "ERR usage: GET key"},
+		{"", "ERR empty request"},
+		{"FLY away", `ERR unknown command "FLY"`},
+		{"DEL a\r", "OK"},
+		{"KEYS", "KEYS 0"},
+		{"QUIT", "OK"},
+	}
+	for _, tt := range tests {
+		fmt.Fprintf(conn, "%s\n", tt.req)
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("%q: %v", tt.req, err)
+		}
+		if got := strings.TrimSuffix(line, "\n"); got != tt.resp {
+			t.Fatalf("%q -> %q, want %q", tt.req, got, tt.resp)
+		}
+	}
+	if _, err := r.ReadString('\n'); err != io.EOF {
+		t.Fatalf("after QUIT: %v, want EOF", err)
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	_, addr := start(t, 50*time.Millisecond)
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("idle connection: read = %v, want EOF from server close", err)
+	}
+}
+
+func TestConcurrentClients(t *testing.T) {
+	leaktest.Check(t)
+	srv, addr := start(t, 0)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := Dial(addr)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer c.Close()
+			for j := range 50 {
+				if err := c.Set(fmt.Sprintf("k%d-%d", i, j), "v"); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := srv.Store.Len(); n != 400 {
+		t.Fatalf("store has %d keys, want 400", n)
+	}
+}
+
+func TestShutdownClosesConnections(t *testing.T) {
+	srv, addr := start(t, 0)
+	c, err := Dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.conn.Close()
+	c.Set("a", "1") // the connection is now being served
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown with an open connection = %v", err)
+	}
+	if _, _, err := c.Get("a"); err == nil {
+		t.Fatal("request succeeded after Shutdown")
+	}
+}
@@ -0,0 +1,232 @@
+// Package kvtcp serves an ownership.Store over TCP with a line protocol.
+//
+// Each request is one line; keys may not contain spaces and values may not
+// contain newlines:
+//
+//	GET key          -> VALUE value | NIL
+//	SET key value    -> OK
+//	DEL key          -> OK
+//	KEYS             -> KEYS n, then n lines with one key each
+//	QUIT             -> OK, then the server closes the connection
+//
+// Malformed requests get "ERR message" and the connection stays open. The
+// store is the same pointer-shared value an HTTP handler would use; only
+// the transport differs.
+package kvtcp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/concurrency/ownership"
+)
+
+// ErrServerClosed is returned by Serve after Shutdown.
+var ErrServerClosed = errors.New("kvtcp: server closed")
+
+// MaxLineBytes bounds a request line, so a client cannot make the server
+// buffer without limit.
+const MaxLineBytes = 64 << 10
+
+// DefaultIdleTimeout is used when Server.IdleTimeout is zero.
+const DefaultIdleTimeout = 5 * time.Minute
+
+// Server serves one Store. Set the fields before calling Serve.
+type Server struct {
+	Store ownership.Store
+	// IdleTimeout closes a connection that sends nothing for this long.
+	IdleTimeout time.Duration
+	// Logger receives connection errors; nil means slog.Default().
+	Logger *slog.Logger
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// Serve accepts connections on l, handling each on its own goroutine,
+// until Shutdown is called or l fails.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.listener = l
+	if s.conns == nil {
+		s.conns = make(map[net.Conn]struct{})
+	}
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		if !s.track(conn) {
+			conn.Close()
+			return ErrServerClosed
+		}
+		go func() {
+			defer s.wg.Done()
+			defer s.untrack(conn)
+			if err := s.handle(conn); err != nil {
+				s.logger().Warn("kvtcp: connection failed", "remote", conn.RemoteAddr().String(), "err", err)
+			}
+		}()
+	}
+}
+
+func (s *Server) track(c net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[c] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+func (s *Server) untrack(c net.Conn) {
+	c.Close()
+	s.mu.Lock()
+	delete(s.conns, c)
+	s.mu.Unlock()
+}
+
+// Shutdown stops accepting, closes every open connection and waits for
+// their goroutines, or for ctx to end.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Server) logger() *slog.Logger {
+	if s.Logger != nil {
+		return s.Logger
+	}
+	return slog.Default()
+}
+
+// handle serves requests on c until the client quits or disconnects, the
+// idle timeout passes, or the server shuts down. Those endings return
+// nil; anything else is logged.
+func (s *Server) handle(c net.Conn) error {
+	idle := s.IdleTimeout
+	if idle <= 0 {
+		idle = DefaultIdleTimeout
+	}
+	sc := bufio.NewScanner(c)
+	sc.Buffer(make([]byte, 0, 4096), MaxLineBytes)
+	w := bufio.NewWriter(c)
+
+	for {
+		c.SetReadDeadline(time.Now().Add(idle))
+		if !sc.Scan() {
+			err := sc.Err()
+			var ne net.Error
+			if err == nil || errors.Is(err, net.ErrClosed) || (errors.As(err, &ne) && ne.Timeout()) {
+				return nil
+			}
+			if errors.Is(err, bufio.ErrTooLong) {
+				fmt.Fprintf(w, "ERR line longer than %d bytes\n", MaxLineBytes)
+				w.Flush()
+			}
+			return err
+		}
+		quit := s.exec(w, sc.Text())
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+// exec runs one request line and writes the response. It reports whether
+// the client asked to quit.
+func (s *Server) exec(w io.Writer, line string) (quit bool) {
+	cmd, rest, _ := strings.Cut(strings.TrimRight(line, "\r"), " ")
+	switch strings.ToUpper(cmd) {
+	case "GET":
+		if !validKey(rest) {
+			fmt.Fprintln(w, "ERR usage: GET key")
+			return false
+		}
+		if v, ok := s.Store.Get(rest); ok {
+			fmt.Fprintf(w, "VALUE %s\n", v)
+		} else {
+			fmt.Fprintln(w, "NIL")
+		}
+	case "SET":
+		key, value, ok := strings.Cut(rest, " ")
+		if !ok || !validKey(key) {
+			fmt.Fprintln(w, "ERR usage: SET key value")
+			return false
+		}
+		s.Store.Set(key, value)
+		fmt.Fprintln(w, "OK")
+	case "DEL":
+		if !validKey(rest) {
+			fmt.Fprintln(w, "ERR usage: DEL key")
+			return false
+		}
+		s.Store.Delete(rest)
+		fmt.Fprintln(w, "OK")
+	case "KEYS":
+		keys := s.Store.Keys()
+		fmt.Fprintf(w, "KEYS %d\n", len(keys))
+		for _, k := range keys {
+			fmt.Fprintln(w, k)
+		}
+	case "QUIT":
+		fmt.Fprintln(w, "OK")
+		return true
+	case "":
+		fmt.Fprintln(w, "ERR empty request")
+	default:
+		fmt.Fprintf(w, "ERR unknown command %q\n", cmd)
+	}
+	return false
+}
+
+func validKey(k string) bool {
+	return k != "" && !strings.ContainsAny(k, " \t\r\n")
+}
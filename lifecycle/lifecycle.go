@@ -0,0 +1,252 @@
+// Package lifecycle starts a program's components in order and stops them
+// in reverse when the program is told to quit.
+//
+// Each component registers a start and a stop function. Start must return
+// once the component is up; anything long-running belongs on its own
+// goroutine, or can be handed to Go. Run starts every component, waits for
+// SIGINT, SIGTERM, the end of its context or a call to Shutdown, and then
+// stops the started components last-first. Each stop gets its own
+// timeout, so one stuck component cannot hold up the rest, and every
+// failure is collected into the error Run returns.
+//
+// Once shutdown begins the signals are released, so a second Ctrl-C kills
+// the process the usual way.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrRunning is returned by a second call to Run.
+var ErrRunning = errors.New("lifecycle: already running")
+
+// DefaultStopTimeout bounds each component's stop function unless
+// WithStopTimeout or StopTimeout says otherwise.
+const DefaultStopTimeout = 10 * time.Second
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithSignals replaces the signals that begin shutdown, SIGINT and SIGTERM
+// by default. With none, only the context and Shutdown end Run.
+func WithSignals(sigs ...os.Signal) Option {
+	return func(m *Manager) { m.signals = sigs }
+}
+
+// WithStopTimeout sets the default time each component has to stop.
+func WithStopTimeout(d time.Duration) Option {
+	return func(m *Manager) { m.timeout = d }
+}
+
+// WithLogger reports each component starting and stopping to l. By
+// default nothing is logged.
+func WithLogger(l *slog.Logger) Option {
+	return func(m *Manager) { m.logger = l }
+}
+
+// ComponentOption configures one registered component.
+type ComponentOption func(*component)
+
+// StopTimeout gives a component d to stop instead of the Manager's
+// default.
+func StopTimeout(d time.Duration) ComponentOption {
+	return func(c *component) { c.timeout = d }
+}
+
+type component struct {
+	name        string
+	start, stop func(context.Context) error
+	timeout     time.Duration
+}
+
+// Manager owns an ordered list of components.
+type Manager struct {
+	signals []os.Signal
+	timeout time.Duration
+	logger  *slog.Logger
+
+	mu         sync.Mutex
+	components []*component
+	running    bool
+	cause      error
+	quit       chan struct{} // closed by the first Shutdown
+	quitOnce   sync.Once
+}
+
+// New returns a Manager with no components.
+func New(opts ...Option) *Manager {
+	m := &Manager{
+		signals: []os.Signal{os.Interrupt, syscall.SIGTERM},
+		timeout: DefaultStopTimeout,
+		quit:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Register adds a component to be started after those registered before
+// it and stopped before them. Either function may be nil. Register panics
+// once Run has been called.
+func (m *Manager) Register(name string, start, stop func(context.Context) error, opts ...ComponentOption) {
+	c := &component{name: name, start: start, stop: stop}
+	for _, opt := range opts {
+		opt(c)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running {
+		panic("lifecycle: Register called after Run")
+	}
+	m.components = append(m.components, c)
+}
+
+// Go registers a component that runs fn on its own goroutine. Stopping it
+// cancels fn's context and waits for fn to return. If fn fails before
+// then, the Manager shuts down with that error.
+func (m *Manager) Go(name string, fn func(context.Context) error, opts ...ComponentOption) {
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+	start := func(ctx context.Context) error {
+		var runCtx context.Context
+		runCtx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+		go func() {
+			defer close(done)
+			if err := fn(runCtx); err != nil && runCtx.Err() == nil {
+				m.Shutdown(fmt.Errorf("lifecycle: %s: %w", name, err))
+			}
+		}()
+		return nil
+	}
+	stop := func(ctx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	m.Register(name, start, stop, opts...)
+}
+
+// Shutdown makes Run stop every component and return. A non-nil cause is
+// included in Run's error; only the first call's cause is kept. It is
+// safe to call from any goroutine, before or during Run.
+func (m *Manager) Shutdown(cause error) {
+	m.quitOnce.Do(func() {
+		m.mu.Lock()
+		m.cause = cause
+		m.mu.Unlock()
+		close(m.quit)
+	})
+}
+
+// Run starts the components in order, waits for a signal, ctx to end or
+// Shutdown, and stops the started components in reverse. If a start
+// fails, the remaining components are not started and those already
+// running are stopped. The result joins the shutdown cause, if any, with
+// every start and stop error.
+func (m *Manager) Run(ctx context.Context) error {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return ErrRunning
+	}
+	m.running = true
+	components := m.components
+	m.mu.Unlock()
+
+	releaseSignals := func() {}
+	if len(m.signals) > 0 {
+		ctx, releaseSignals = signal.NotifyContext(ctx, m.signals...)
+	}
+	defer releaseSignals()
+
+	var errs []error
+	started := 0
+	for _, c := range components {
+		if ctx.Err() != nil || m.quitting() {
+			break
+		}
+		m.log("starting", c.name)
+		if c.start != nil {
+			if err := c.start(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("lifecycle: start %s: %w", c.name, err))
+				break
+			}
+		}
+		started++
+	}
+	if len(errs) == 0 {
+		select {
+		case <-ctx.Done():
+		case <-m.quit:
+		}
+	}
+	releaseSignals()
+
+	for i := started - 1; i >= 0; i-- {
+		if err := m.stop(components[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	m.mu.Lock()
+	if m.cause != nil {
+		errs = append([]error{m.cause}, errs...)
+	}
+	m.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+func (m *Manager) quitting() bool {
+	select {
+	case <-m.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop runs c's stop function under its timeout. A stop function that
+// ignores its context is abandoned when the timeout passes.
+func (m *Manager) stop(c *component) error {
+	m.log("stopping", c.name)
+	if c.stop == nil {
+		return nil
+	}
+	timeout := c.timeout
+	if timeout <= 0 {
+		timeout = m.timeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- c.stop(ctx) }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("lifecycle: stop %s: %w", c.name, err)
+	}
+	return nil
+}
+
+func (m *Manager) log(msg, component string) {
+	if m.logger != nil {
+		m.logger.Info("lifecycle: "+msg, "component", component)
+	}
+}
@@ -0,0 +1,209 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/concurrency/leaktest"
+)
+
+// trace records start and stop calls in order.
+type trace struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (tr *trace) fn(call string, err error) func(context.Context) error {
+	return func(context.Context) error {
+		tr.mu.Lock()
+		tr.calls = append(tr.calls, call)
+		tr.mu.Unlock()
+		return err
+	}
+}
+
+func (tr *trace) register(m *Manager, name string, startErr, stopErr error) {
+	m.Register(name, tr.fn("start "+name, startErr), tr.fn("stop "+name, stopErr))
+}
+
+func (tr *trace) all() []string {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return slices.Clone(tr.calls)
+}
+
+func TestOrder(t *testing.T) {
+	leaktest.Check(t)
+	var tr trace
+	m := New(WithSignals())
+	for _, name := range []string{"db", "scheduler", "http"} {
+		tr.register(m, name, nil, nil)
+	}
+	m.Register("started", func(context.Context) error { m.Shutdown(nil); return nil }, nil)
+
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	want := []string{"start db", "start scheduler", "start http", "stop http", "stop scheduler", "stop db"}
+	if got := tr.all(); !slices.Equal(got, want) {
+		t.Fatalf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestContextEndsRun(t *testing.T) {
+	leaktest.Check(t)
+	var tr trace
+	m := New(WithSignals())
+	tr.register(m, "a", nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	m.Register("cancel", func(context.Context) error { cancel(); return nil }, nil)
+
+	if err := m.Run(ctx); err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if got := tr.all(); !slices.Equal(got, []string{"start a", "stop a"}) {
+		t.Fatalf("calls = %q", got)
+	}
+}
+
+func TestStartFailure(t *testing.T) {
+	leaktest.Check(t)
+	var tr trace
+	boom := errors.New("boom")
+	m := New(WithSignals())
+	tr.register(m, "a", nil, nil)
+	tr.register(m, "b", boom, nil)
+	tr.register(m, "c", nil, nil)
+
+	err := m.Run(context.Background())
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "start b") {
+		t.Fatalf("Run() = %v, want start b: boom", err)
+	}
+	// b never came up, so only a is stopped; c is never touched.
+	if got := tr.all(); !slices.Equal(got, []string{"start a", "start b", "stop a"}) {
+		t.Fatalf("calls = %q", got)
+	}
+}
+
+func TestStopErrorsAggregated(t *testing.T) {
+	leaktest.Check(t)
+	var tr trace
+	errA, errB := errors.New("a failed"), errors.New("b failed")
+	cause := errors.New("disk full")
+	m := New(WithSignals())
+	tr.register(m, "a", nil, errA)
+	tr.register(m, "b", nil, errB)
+	tr.register(m, "c", nil, nil)
+	m.Register("fail", func(context.Context) error { m.Shutdown(cause); return nil }, nil)
+
+	err := m.Run(context.Background())
+	for _, want := range []error{cause, errA, errB} {
+		if !errors.Is(err, want) {
+			t.Errorf("Run() = %v, want it to include %v", err, want)
+		}
+	}
+	want := []string{"start a", "start b", "start c", "stop c", "stop b", "stop a"}
+	if got := tr.all(); !slices.Equal(got, want) {
+		t.Fatalf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestStopTimeout(t *testing.T) {
+	leaktest.Check(t)
+	var tr trace
+	release := make(chan struct{})
+	defer close(release)
+	m := New(WithSignals(), WithStopTimeout(time.Minute))
+	tr.register(m, "a", nil, nil)
+	m.Register("stuck", nil, func(context.Context) error {
+		<-release // ignores its context
+		return nil
+	}, StopTimeout(10*time.Millisecond))
+	m.Register("slow", nil, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, StopTimeout(10*time.Millisecond))
+	m.Register("shutdown", func(context.Context) error { m.Shutdown(nil); return nil }, nil)
+
+	start := time.Now()
+	err := m.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Run took %v; the stop timeouts were not applied", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run() = %v, want DeadlineExceeded", err)
+	}
+	for _, name := range []string{"stop stuck", "stop slow"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Run() = %v, want it to mention %s", err, name)
+		}
+	}
+	// a is still stopped after the two that timed out.
+	if got := tr.all(); !slices.Equal(got, []string{"start a", "stop a"}) {
+		t.Fatalf("calls = %q", got)
+	}
+}
+
+func TestGo(t *testing.T) {
+	leaktest.Check(t)
+	m := New(WithSignals())
+	running := make(chan struct{})
+	var stopped bool
+	m.Go("worker", func(ctx context.Context) error {
+		close(running)
+		<-ctx.Done()
+		stopped = true
+		return ctx.Err()
+	})
+	m.Register("shutdown", func(context.Context) error {
+		<-running
+		m.Shutdown(nil)
+		return nil
+	}, nil)
+
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if !stopped {
+		t.Fatal("worker still running after Run returned")
+	}
+}
+
+func TestGoFailureShutsDown(t *testing.T) {
+	leaktest.Check(t)
+	var tr trace
+	boom := errors.New("listener closed")
+	m := New(WithSignals())
+	tr.register(m, "a", nil, nil)
+	m.Go("statsd", func(context.Context) error { return boom })
+
+	err := m.Run(context.Background())
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "statsd") {
+		t.Fatalf("Run() = %v, want statsd: %v", err, boom)
+	}
+	if got := tr.all(); !slices.Equal(got, []string{"start a", "stop a"}) {
+		t.Fatalf("calls = %q", got)
+	}
+}
+
+func TestRunTwice(t *testing.T) {
+	var tr trace
+	m := New(WithSignals())
+	tr.register(m, "a", nil, nil)
+	m.Shutdown(nil)
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("first Run() = %v", err)
+	}
+	// Shutdown before Run means nothing starts.
+	if got := tr.all(); len(got) != 0 {
+		t.Fatalf("calls = %q, want none", got)
+	}
+	if err := m.Run(context.Background()); !errors.Is(err, ErrRunning) {
+		t.Fatalf("second Run() = %v, want ErrRunning", err)
+	}
+}
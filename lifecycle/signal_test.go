@@ -0,0 +1,26 @@
+//go:build unix
+
+package lifecycle
+
+import (
+	"context"
+	"slices"
+	"syscall"
+	"testing"
+)
+
+func TestSignal(t *testing.T) {
+	var tr trace
+	m := New(WithSignals(syscall.SIGUSR1))
+	tr.register(m, "a", nil, nil)
+	m.Register("signal", func(context.Context) error {
+		return syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	}, nil)
+
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if got := tr.all(); !slices.Equal(got, []string{"start a", "stop a"}) {
+		t.Fatalf("calls = %q", got)
+	}
+}
@@ -0,0 +1,196 @@
+package linkedlist
+
+import "iter"
+
+// Element is an element of a DList.
+type Element[T any] struct {
+	Value      T
+	prev, next *Element[T]
+	list       *DList[T]
+}
+
+// Next returns the following element, or nil at the back.
+func (e *Element[T]) Next() *Element[T] { return e.next }
+
+// Prev returns the preceding element, or nil at the front.
+func (e *Element[T]) Prev() *Element[T] { return e.prev }
+
+// DList is a doubly linked list. The zero value is an empty list ready to
+// use. A DList is not safe for concurrent use.
+type DList[T any] struct {
+	head, tail *Element[T]
+	len        int
+}
+
+// Len returns the number of elements.
+func (l *DList[T]) Len() int { return l.len }
+
+// Front returns the first element, or nil if the list is empty.
+func (l *DList[T]) Front() *Element[T] { return l.head }
+
+// Back returns the last element, or nil if the list is empty.
+func (l *DList[T]) Back() *Element[T] { return l.tail }
+
+// PushFront inserts v at the front and returns its element.
+func (l *DList[T]) PushFront(v T) *Element[T] {
+	return l.link(&Element[T]{Value: v}, nil, l.head)
+}
+
+// PushBack inserts v at the back and returns its element.
+func (l *DList[T]) PushBack(v T) *Element[T] {
+	return l.link(&Element[T]{Value: v}, l.tail, nil)
+}
+
+// InsertBefore inserts v before mark. It panics if mark does not belong
+// to l.
+func (l *DList[T]) InsertBefore(mark *Element[T], v T) *Element[T] {
+	l.own(mark)
+	return l.link(&Element[T]{Value: v}, mark.prev, mark)
+}
+
+// InsertAfter inserts v after mark. It panics if mark does not belong to
+// l.
+func (l *DList[T]) InsertAfter(mark *Element[T], v T) *Element[T] {
+	l.own(mark)
+	return l.link(&Element[T]{Value: v}, mark, mark.next)
+}
+
+// link places e between prev and next, either of which may be nil at an
+// end of the list.
+func (l *DList[T]) link(e, prev, next *Element[T]) *Element[T] {
+	e.prev, e.next, e.list = prev, next, l
+	if prev != nil {
+		prev.next = e
+	} else {
+		l.head = e
+	}
+	if next != nil {
+		next.prev = e
+	} else {
+		l.tail = e
+	}
+	l.len++
+	return e
+}
+
+func (l *DList[T]) unlink(e *Element[T]) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		l.head = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		l.tail = e.prev
+	}
+	e.prev, e.next, e.list = nil, nil, nil
+	l.len--
+}
+
+func (l *DList[T]) own(e *Element[T]) {
+	if e == nil || e.list != l {
+		panic("linkedlist: element does not belong to this list")
+	}
+}
+
+// Remove unlinks e and reports whether it was in l.
+func (l *DList[T]) Remove(e *Element[T]) bool {
+	if e == nil || e.list != l {
+		return false
+	}
+	l.unlink(e)
+	return true
+}
+
+// MoveToFront moves e to the front. It panics if e does not belong to l.
+func (l *DList[T]) MoveToFront(e *Element[T]) {
+	l.own(e)
+	if e == l.head {
+		return
+	}
+	l.unlink(e)
+	l.link(e, nil, l.head)
+}
+
+// MoveToBack moves e to the back. It panics if e does not belong to l.
+func (l *DList[T]) MoveToBack(e *Element[T]) {
+	l.own(e)
+	if e == l.tail {
+		return
+	}
+	l.unlink(e)
+	l.link(e, l.tail, nil)
+}
+
+// All yields the values from front to back. Removing the current element
+// during iteration is safe.
+func (l *DList[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := l.head; e != nil; {
+			next := e.next
+			if !yield(e.Value) {
+				return
+			}
+			e = next
+		}
+	}
+}
+
+// Backward yields the values from back to front.
+func (l *DList[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := l.tail; e != nil; {
+			prev := e.prev
+			if !yield(e.Value) {
+				return
+			}
+			e = prev
+		}
+	}
+}
+
+// Reverse reverses the list in place by swapping each element's prev and
+// next pointers.
+func (l *DList[T]) Reverse() {
+	for e := l.head; e != nil; e = e.prev {
+		e.prev, e.next = e.next, e.prev
+	}
+	l.head, l.tail = l.tail, l.head
+}
+
+// SpliceAfter moves every element of other into l after mark, or at the
+// front if mark is nil, leaving other empty. It panics if mark does not
+// belong to l or other is l.
+func (l *DList[T]) SpliceAfter(mark *Element[T], other *DList[T]) {
+	if other == l {
+		panic("linkedlist: splice of a list onto itself")
+	}
+	if mark != nil {
+		l.own(mark)
+	}
+	if other.head == nil {
+		return
+	}
+	for e := other.head; e != nil; e = e.next {
+		e.list = l
+	}
+
+	var next *Element[T]
+	if mark != nil {
+		next = mark.next
+		mark.next = other.head
+	} else {
+		next = l.head
+		l.head = other.head
+	}
+	other.head.prev = mark
+	other.tail.next = next
+	if next != nil {
+		next.prev = other.tail
+	} else {
+		l.tail = other.tail
+	}
+	l.len += other.len
+	*other = DList[T]{}
+}
@@ -0,0 +1,180 @@
+package linkedlist
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// checkDList verifies both directions of links, ownership, ends and
+// length of l against want.
+func checkDList[T comparable](t *testing.T, l *DList[T], want []T) {
+	t.Helper()
+	var got []T
+	var prev *Element[T]
+	for e := l.Front(); e != nil; e = e.Next() {
+		if e.Prev() != prev {
+			t.Fatalf("element %v: prev link broken", e.Value)
+		}
+		if e.list != l {
+			t.Fatalf("element %v owned by another list", e.Value)
+		}
+		got = append(got, e.Value)
+		prev = e
+	}
+	if l.Back() != prev {
+		t.Fatalf("Back() = %v, want the last element", l.Back())
+	}
+	if !slices.Equal(got, want) || l.Len() != len(want) {
+		t.Fatalf("list = %v (len %d), want %v", got, l.Len(), want)
+	}
+	backward := slices.Collect(l.Backward())
+	slices.Reverse(backward)
+	if !slices.Equal(backward, want) {
+		t.Fatalf("Backward() reversed = %v, want %v", backward, want)
+	}
+}
+
+func TestDListInsert(t *testing.T) {
+	var l DList[string]
+	c := l.PushBack("c")
+	l.PushFront("a")
+	l.InsertBefore(c, "b")
+	l.InsertAfter(c, "d")
+	checkDList(t, &l, []string{"a", "b", "c", "d"})
+}
+
+func TestDListRemoveAndMove(t *testing.T) {
+	var l DList[int]
+	es := []*Element[int]{l.PushBack(1), l.PushBack(2), l.PushBack(3), l.PushBack(4)}
+
+	l.MoveToFront(es[2])
+	checkDList(t, &l, []int{3, 1, 2, 4})
+	l.MoveToBack(es[2])
+	checkDList(t, &l, []int{1, 2, 4, 3})
+	l.MoveToFront(es[0]) // already there
+	l.MoveToBack(es[2])
+	checkDList(t, &l, []int{1, 2, 4, 3})
+
+	for _, e := range []*Element[int]{es[3], es[0], es[2], es[1]} {
+		if !l.Remove(e) {
+			t.Fatalf("Remove(%d) = false", e.Value)
+		}
+	}
+	checkDList(t, &l, nil)
+	if l.Remove(es[0]) {
+		t.Fatal("Remove of a removed element returned true")
+	}
+}
+
+func TestDListForeignElementPanics(t *testing.T) {
+	var a, b DList[int]
+	e := a.PushBack(1)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MoveToFront with a foreign element did not panic")
+		}
+	}()
+	b.MoveToFront(e)
+}
+
+func TestDListReverse(t *testing.T) {
+	for n := range 4 {
+		var l DList[int]
+		var want []int
+		for i := range n {
+			l.PushBack(i)
+			want = append([]int{i}, want...)
+		}
+		l.Reverse()
+		checkDList(t, &l, want)
+	}
+}
+
+func TestSpliceAfter(t *testing.T) {
+	build := func(vs ...int) *DList[int] {
+		l := new(DList[int])
+		for _, v := range vs {
+			l.PushBack(v)
+		}
+		return l
+	}
+	tests := []struct {
+		name string
+		dst  []int
+		mark int // index in dst, or -1 for the front
+		src  []int
+		want []int
+	}{
+		{"front", []int{3, 4}, -1, []int{1, 2}, []int{1, 2, 3, 4}},
+		{"middle", []int{1, 4}, 0, []int{2, 3}, []int{1, 2, 3, 4}},
+		{"back", []int{1, 2}, 1, []int{3, 4}, []int{1, 2, 3, 4}},
+		{"into empty", nil, -1, []int{1}, []int{1}},
+		{"empty source", []int{1}, 0, nil, []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst, src := build(tt.dst...), build(tt.src...)
+			var mark *Element[int]
+			if tt.mark >= 0 {
+				mark = dst.Front()
+				for range tt.mark {
+					mark = mark.Next()
+				}
+			}
+			dst.SpliceAfter(mark, src)
+			checkDList(t, dst, tt.want)
+			checkDList(t, src, nil)
+		})
+	}
+}
+
+// TestDListRandom applies random operations to a DList and a slice and
+// checks they agree after every step.
+func TestDListRandom(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	var l DList[int]
+	var model []int
+	elems := map[int]*Element[int]{} // value -> element; values are unique
+	next := 0
+
+	indexOf := func(v int) int { return slices.Index(model, v) }
+	for range 2000 {
+		switch op := r.IntN(6); {
+		case op == 0 || len(model) == 0:
+			elems[next] = l.PushBack(next)
+			model = append(model, next)
+			next++
+		case op == 1:
+			elems[next] = l.PushFront(next)
+			model = slices.Insert(model, 0, next)
+			next++
+		case op == 2:
+			v := model[r.IntN(len(model))]
+			elems[next] = l.InsertAfter(elems[v], next)
+			model = slices.Insert(model, indexOf(v)+1, next)
+			next++
+		case op == 3:
+			v := model[r.IntN(len(model))]
+			l.Remove(elems[v])
+			delete(elems, v)
+			model = slices.Delete(model, indexOf(v), indexOf(v)+1)
+		case op == 4:
+			v := model[r.IntN(len(model))]
+			l.MoveToFront(elems[v])
+			model = slices.Delete(model, indexOf(v), indexOf(v)+1)
+			model = slices.Insert(model, 0, v)
+		default:
+			l.Reverse()
+			slices.Reverse(model)
+		}
+		checkDList(t, &l, model)
+	}
+}
+
+func BenchmarkDListPushRemove(b *testing.B) {
+	var l DList[int]
+	for b.Loop() {
+		l.Remove(l.PushBack(1))
+	}
+}
@@ -0,0 +1,164 @@
+// Package linkedlist implements singly and doubly linked lists with raw
+// next and prev pointers.
+//
+// LinkedList keeps a tail pointer so appending and splicing are O(1), but
+// removing a node means finding its predecessor, which is O(n). DList
+// pays one more pointer per element to make removal, insertion before a
+// node and backward traversal O(1). Each node records the list that owns
+// it, so handing a node to the wrong list is caught instead of silently
+// corrupting both.
+package linkedlist
+
+import "iter"
+
+// Node is an element of a LinkedList.
+type Node[T any] struct {
+	Value T
+	next  *Node[T]
+	list  *LinkedList[T]
+}
+
+// Next returns the following node, or nil at the end of the list.
+func (n *Node[T]) Next() *Node[T] { return n.next }
+
+// LinkedList is a singly linked list. The zero value is an empty list
+// ready to use. A LinkedList is not safe for concurrent use.
+type LinkedList[T any] struct {
+	head, tail *Node[T]
+	len        int
+}
+
+// Len returns the number of elements.
+func (l *LinkedList[T]) Len() int { return l.len }
+
+// Front returns the first node, or nil if the list is empty.
+func (l *LinkedList[T]) Front() *Node[T] { return l.head }
+
+// PushFront inserts v at the front and returns its node.
+func (l *LinkedList[T]) PushFront(v T) *Node[T] {
+	n := &Node[T]{Value: v, next: l.head, list: l}
+	l.head = n
+	if l.tail == nil {
+		l.tail = n
+	}
+	l.len++
+	return n
+}
+
+// PushBack inserts v at the back and returns its node.
+func (l *LinkedList[T]) PushBack(v T) *Node[T] {
+	if l.tail == nil {
+		return l.PushFront(v)
+	}
+	return l.InsertAfter(l.tail, v)
+}
+
+// InsertAfter inserts v after mark and returns its node. It panics if
+// mark does not belong to l.
+func (l *LinkedList[T]) InsertAfter(mark *Node[T], v T) *Node[T] {
+	l.own(mark)
+	n := &Node[T]{Value: v, next: mark.next, list: l}
+	mark.next = n
+	if l.tail == mark {
+		l.tail = n
+	}
+	l.len++
+	return n
+}
+
+// PopFront removes and returns the first value.
+func (l *LinkedList[T]) PopFront() (T, bool) {
+	n := l.head
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+	l.head = n.next
+	if l.head == nil {
+		l.tail = nil
+	}
+	l.detach(n)
+	return n.Value, true
+}
+
+// Remove unlinks n and reports whether it was in l. It walks from the
+// front to find n's predecessor.
+func (l *LinkedList[T]) Remove(n *Node[T]) bool {
+	if n == nil || n.list != l {
+		return false
+	}
+	if n == l.head {
+		l.PopFront()
+		return true
+	}
+	prev := l.head
+	for prev.next != n {
+		prev = prev.next
+	}
+	prev.next = n.next
+	if l.tail == n {
+		l.tail = prev
+	}
+	l.detach(n)
+	return true
+}
+
+func (l *LinkedList[T]) detach(n *Node[T]) {
+	n.next, n.list = nil, nil
+	l.len--
+}
+
+func (l *LinkedList[T]) own(n *Node[T]) {
+	if n == nil || n.list != l {
+		panic("linkedlist: node does not belong to this list")
+	}
+}
+
+// All yields the values from front to back. Removing the current node
+// during iteration is safe.
+func (l *LinkedList[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for n := l.head; n != nil; {
+			next := n.next
+			if !yield(n.Value) {
+				return
+			}
+			n = next
+		}
+	}
+}
+
+// Reverse reverses the list in place by turning every next pointer round.
+func (l *LinkedList[T]) Reverse() {
+	var prev *Node[T]
+	l.tail = l.head
+	for n := l.head; n != nil; {
+		next := n.next
+		n.next = prev
+		prev, n = n, next
+	}
+	l.head = prev
+}
+
+// Splice moves every node of other to the end of l, leaving other empty.
+// The links are joined in O(1); re-parenting the moved nodes is
+// O(other.Len()). Splicing a list onto itself panics.
+func (l *LinkedList[T]) Splice(other *LinkedList[T]) {
+	if other == l {
+		panic("linkedlist: splice of a list onto itself")
+	}
+	if other.head == nil {
+		return
+	}
+	for n := other.head; n != nil; n = n.next {
+		n.list = l
+	}
+	if l.tail == nil {
+		l.head = other.head
+	} else {
+		l.tail.next = other.head
+	}
+	l.tail = other.tail
+	l.len += other.len
+	*other = LinkedList[T]{}
+}
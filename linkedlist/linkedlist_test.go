@@ -0,0 +1,148 @@
+package linkedlist
+
+import (
+	"slices"
+	"testing"
+)
+
+// checkList verifies the links, ownership, tail and length of l against
+// want.
+func checkList[T comparable](t *testing.T, l *LinkedList[T], want []T) {
+	t.Helper()
+	var got []T
+	var last *Node[T]
+	for n := l.Front(); n != nil; n = n.Next() {
+		if n.list != l {
+			t.Fatalf("node %v owned by another list", n.Value)
+		}
+		got = append(got, n.Value)
+		last = n
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("list = %v, want %v", got, want)
+	}
+	if l.tail != last || l.Len() != len(want) {
+		t.Fatalf("tail %v, len %d; want tail %v, len %d", l.tail, l.Len(), last, len(want))
+	}
+	if all := slices.Collect(l.All()); !slices.Equal(all, want) {
+		t.Fatalf("All() = %v, want %v", all, want)
+	}
+}
+
+func TestPushAndInsert(t *testing.T) {
+	var l LinkedList[int]
+	checkList(t, &l, nil)
+	two := l.PushBack(2)
+	l.PushFront(1)
+	l.PushBack(4)
+	l.InsertAfter(two, 3)
+	checkList(t, &l, []int{1, 2, 3, 4})
+	l.InsertAfter(l.tail, 5)
+	checkList(t, &l, []int{1, 2, 3, 4, 5})
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove int // index into the nodes
+		want   []int
+	}{
+		{"head", 0, []int{2, 3}},
+		{"middle", 1, []int{1, 3}},
+		{"tail", 2, []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l LinkedList[int]
+			nodes := []*Node[int]{l.PushBack(1), l.PushBack(2), l.PushBack(3)}
+			if !l.Remove(nodes[tt.remove]) {
+				t.Fatal("Remove returned false")
+			}
+			checkList(t, &l, tt.want)
+			if l.Remove(nodes[tt.remove]) {
+				t.Fatal("second Remove of the same node returned true")
+			}
+			l.PushBack(9) // the tail must still be right
+			checkList(t, &l, append(tt.want, 9))
+		})
+	}
+}
+
+func TestPopFront(t *testing.T) {
+	var l LinkedList[string]
+	l.PushBack("a")
+	l.PushBack("b")
+	for _, want := range []string{"a", "b"} {
+		if v, ok := l.PopFront(); !ok || v != want {
+			t.Fatalf("PopFront = %q, %v; want %q", v, ok, want)
+		}
+	}
+	if _, ok := l.PopFront(); ok {
+		t.Fatal("PopFront on an empty list reported ok")
+	}
+	checkList(t, &l, nil)
+}
+
+func TestForeignNode(t *testing.T) {
+	var a, b LinkedList[int]
+	n := a.PushBack(1)
+	if b.Remove(n) {
+		t.Fatal("Remove accepted a node from another list")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("InsertAfter with a foreign node did not panic")
+		}
+	}()
+	b.InsertAfter(n, 2)
+}
+
+func TestReverse(t *testing.T) {
+	for n := range 4 {
+		var l LinkedList[int]
+		var want []int
+		for i := range n {
+			l.PushBack(i)
+			want = append([]int{i}, want...)
+		}
+		l.Reverse()
+		checkList(t, &l, want)
+	}
+}
+
+func TestSplice(t *testing.T) {
+	var a, b, empty LinkedList[int]
+	a.PushBack(1)
+	b.PushBack(2)
+	b.PushBack(3)
+
+	a.Splice(&b)
+	checkList(t, &a, []int{1, 2, 3})
+	checkList(t, &b, nil)
+
+	a.Splice(&empty)
+	empty.Splice(&a)
+	checkList(t, &empty, []int{1, 2, 3})
+	checkList(t, &a, nil)
+
+	// Moved nodes now belong to their new list.
+	if !empty.Remove(empty.Front().Next()) {
+		t.Fatal("moved node not owned by the new list")
+	}
+	checkList(t, &empty, []int{1, 3})
+}
+
+func TestRemoveDuringAll(t *testing.T) {
+	var l LinkedList[int]
+	for i := range 6 {
+		l.PushBack(i)
+	}
+	for n := l.Front(); n != nil; {
+		next := n.Next()
+		if n.Value%2 == 0 {
+			l.Remove(n)
+		}
+		n = next
+	}
+	checkList(t, &l, []int{1, 3, 5})
+}
@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// RequestIDHeader carries a request's ID. Middleware keeps one a client
+// or proxy sent and makes one up otherwise, and echoes it in the
+// response so a caller can quote it when reporting a problem.
+const RequestIDHeader = "X-Request-Id"
+
+// maxRequestIDLen caps an incoming ID, which ends up in every log line.
+const maxRequestIDLen = 64
+
+// Middleware puts a logger in each request's context: l with the
+// request's method, path and ID, and any fields With added to the context
+// before it. When the request is done it logs one line at Info with the
+// status, bytes written and duration.
+func Middleware(l *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLen {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		rl := l.With("method", r.Method, "path", r.URL.Path, "request_id", id)
+		if args, _ := r.Context().Value(attrsKey{}).([]any); len(args) > 0 {
+			rl = rl.With(args...)
+		}
+
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r.WithContext(WithContext(r.Context(), rl)))
+		if sw.status == 0 {
+			sw.status = http.StatusOK
+		}
+		rl.LogAttrs(r.Context(), slog.LevelInfo, "request",
+			slog.Int("status", sw.status),
+			slog.Int64("bytes", sw.bytes),
+			slog.Duration("duration", time.Since(start)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// statusWriter records the status and size of a response. Unwrap lets
+// http.ResponseController reach the underlying writer to flush streams.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,110 @@
+// Package logging builds the programs' slog.Logger from their settings
+// and carries it through a context, so code deep in a request logs with
+// the request's fields without a logger being passed to every function.
+//
+// A program builds one logger at startup with New or Open and hands it to
+// what it runs. Middleware gives each HTTP request a logger that adds the
+// request's method, path and ID, and handlers fetch it with FromContext.
+// With adds further fields for everything below a point in the call tree.
+//
+// Packages that log take the logger from the context, or as an explicit
+// option, and fall back to slog.Default(); none of them write to stdout
+// or stderr themselves.
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+)
+
+// ErrFormat is returned for a Format other than text or json.
+var ErrFormat = errors.New("logging: format must be text or json")
+
+// Config is the logging settings, tagged for the config package so a
+// program can embed it in its own settings struct.
+type Config struct {
+	Level  slog.Level `config:"log-level" usage:"lowest level logged: debug, info, warn or error"`
+	Format string     `config:"log-format" usage:"log line format: text or json"`
+	Output string     `config:"log-output" usage:"where logs go: stderr, stdout or a file to append to"`
+}
+
+// New returns a logger writing cfg.Format lines at cfg.Level and above to
+// w. cfg.Output is ignored; see Open.
+func New(w io.Writer, cfg Config) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: cfg.Level}
+	switch cfg.Format {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("%w, got %q", ErrFormat, cfg.Format)
+}
+
+// Open is New writing to cfg.Output. An empty Output or "stderr" means
+// stderr, which tests can replace. Closing the returned io.Closer closes
+// a log file and does nothing otherwise.
+func Open(cfg Config, stderr io.Writer) (*slog.Logger, io.Closer, error) {
+	var w io.Writer
+	var c io.Closer = nopCloser{}
+	switch cfg.Output {
+	case "", "stderr":
+		w = stderr
+	case "stdout":
+		w = os.Stdout
+	default:
+		f, err := os.OpenFile(cfg.Output, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("logging: %w", err)
+		}
+		w, c = f, f
+	}
+	l, err := New(w, cfg)
+	if err != nil {
+		c.Close()
+		return nil, nil, err
+	}
+	return l, c, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// Discard is a logger that drops everything, for tests.
+var Discard = slog.New(slog.DiscardHandler)
+
+type (
+	ctxKey   struct{}
+	attrsKey struct{}
+)
+
+// WithContext returns a copy of ctx carrying l.
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the logger ctx carries, or slog.Default() if it
+// carries none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// With returns a copy of ctx whose logger adds args, as key-value pairs
+// or slog.Attrs, to every record. The fields are also kept for a
+// Middleware further in, which adds them to the request's logger, so
+// middleware that tags requests, such as tracing, works on either side of
+// it.
+func With(ctx context.Context, args ...any) context.Context {
+	prev, _ := ctx.Value(attrsKey{}).([]any)
+	ctx = context.WithValue(ctx, attrsKey{}, append(slices.Clip(prev), args...))
+	return WithContext(ctx, FromContext(ctx).With(args...))
+}
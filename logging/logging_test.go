@@ -0,0 +1,166 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stawuah/pounce-on-go/config"
+)
+
+var timeField = regexp.MustCompile(`time=\S+ |"time":"[^"]+",`)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		cfg  Config
+		want string // the output of one Debug and one Info record
+	}{
+		{Config{}, "level=INFO msg=b k=2\n"},
+		{Config{Level: slog.LevelDebug}, "level=DEBUG msg=a k=1\nlevel=INFO msg=b k=2\n"},
+		{Config{Format: "json"}, `{"level":"INFO","msg":"b","k":2}` + "\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		l, err := New(&buf, tt.cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.Debug("a", "k", 1)
+		l.Info("b", "k", 2)
+		if got := timeField.ReplaceAllString(buf.String(), ""); got != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.cfg, got, tt.want)
+		}
+	}
+	if _, err := New(io.Discard, Config{Format: "xml"}); !errors.Is(err, ErrFormat) {
+		t.Fatalf("xml: err = %v, want ErrFormat", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	var stderr bytes.Buffer
+	l, c, err := Open(Config{}, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("to stderr")
+	c.Close()
+	if !strings.Contains(stderr.String(), "to stderr") {
+		t.Fatalf("stderr = %q", stderr.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	for _, msg := range []string{"first", "second"} {
+		l, c, err := Open(Config{Output: path, Format: "json"}, &stderr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.Info(msg)
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, _ := os.ReadFile(path)
+	if lines := strings.Split(strings.TrimSpace(string(b)), "\n"); len(lines) != 2 || !strings.Contains(lines[1], `"msg":"second"`) {
+		t.Fatalf("log file = %q, want both runs appended", b)
+	}
+
+	if _, _, err := Open(Config{Output: filepath.Join(t.TempDir(), "no", "such", "dir")}, &stderr); err == nil {
+		t.Fatal("Open into a missing directory succeeded")
+	}
+}
+
+func TestConfigLoad(t *testing.T) {
+	var cfg struct {
+		Config
+		Addr string `config:"addr"`
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	config.RegisterFlags(fs, &cfg)
+	if err := fs.Parse([]string{"-log-level", "debug"}); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"APP_LOG_FORMAT": "json"}
+	if err := config.Load(&cfg, config.Env("APP", func(k string) string { return env[k] }), config.Flags(fs)); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != slog.LevelDebug || cfg.Format != "json" {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	if FromContext(ctx) != slog.Default() {
+		t.Fatal("empty context did not give slog.Default()")
+	}
+	var buf bytes.Buffer
+	l, _ := New(&buf, Config{})
+	ctx = With(WithContext(ctx, l), "user", "ada")
+	ctx = With(ctx, "order", 7)
+	FromContext(ctx).Info("paid")
+	if got := timeField.ReplaceAllString(buf.String(), ""); got != "level=INFO msg=paid user=ada order=7\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := New(&buf, Config{Format: "json"})
+	h := Middleware(l, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Warn("low stock", "sku", "A1")
+		w.WriteHeader(http.StatusTeapot)
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush through the middleware: %v", err)
+		}
+		w.Write([]byte("short and stout"))
+	}))
+
+	r := httptest.NewRequest("GET", "/products/A1?x=1", nil)
+	r.Header.Set(RequestIDHeader, "req-42")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	if rec.Header().Get(RequestIDHeader) != "req-42" {
+		t.Fatalf("response %s = %q", RequestIDHeader, rec.Header().Get(RequestIDHeader))
+	}
+
+	var lines []map[string]any
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, m)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("logged %d lines, want 2", len(lines))
+	}
+	for _, m := range lines {
+		if m["method"] != "GET" || m["path"] != "/products/A1" || m["request_id"] != "req-42" {
+			t.Errorf("line missing request fields: %v", m)
+		}
+	}
+	if lines[0]["msg"] != "low stock" || lines[0]["sku"] != "A1" {
+		t.Errorf("handler line = %v", lines[0])
+	}
+	if done := lines[1]; done["msg"] != "request" || done["status"] != 418.0 || done["bytes"] != 15.0 {
+		t.Errorf("request line = %v", done)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if id := rec.Header().Get(RequestIDHeader); len(id) != 16 {
+		t.Fatalf("generated request ID = %q", id)
+	}
+}
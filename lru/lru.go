@@ -0,0 +1,238 @@
+// Package lru is a size-bounded cache that evicts the least recently used
+// entry.
+//
+// A map finds an entry's element in a doubly linked list in O(1), and the
+// list keeps entries in recency order: Get and Put move an element to the
+// front, and eviction takes it from the back. Entries may also carry a
+// time to live, checked lazily when they are read or when space is
+// needed.
+package lru
+
+import (
+	"sync"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+	"github.com/stawuah/pounce-on-go/linkedlist"
+)
+
+// Reason says why an entry left the cache.
+type Reason int
+
+const (
+	// Evicted means the entry was the least recently used one when space
+	// was needed.
+	Evicted Reason = iota
+	// Expired means the entry's time to live had passed.
+	Expired
+	// Removed means Remove or Purge deleted it.
+	Removed
+)
+
+func (r Reason) String() string {
+	switch r {
+	case Evicted:
+		return "evicted"
+	case Expired:
+		return "expired"
+	case Removed:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// Option configures a Cache.
+type Option[K comparable, V any] func(*Cache[K, V])
+
+// WithTTL sets the time to live of entries stored with Put. The default,
+// zero, means they never expire.
+func WithTTL[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return func(c *Cache[K, V]) { c.ttl = ttl }
+}
+
+// WithClock makes the cache read time from c when setting and checking
+// expiry.
+func WithClock[K comparable, V any](c clock.Clock) Option[K, V] {
+	return func(cache *Cache[K, V]) { cache.clock = c }
+}
+
+// WithOnEvict registers fn to be called for every entry that leaves the
+// cache other than by being overwritten. It runs after the cache's lock
+// is released, so it may call back into the cache.
+func WithOnEvict[K comparable, V any](fn func(K, V, Reason)) Option[K, V] {
+	return func(c *Cache[K, V]) { c.onEvict = fn }
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time // zero for no expiry
+}
+
+type evicted[K comparable, V any] struct {
+	key    K
+	value  V
+	reason Reason
+}
+
+// Cache is an LRU cache. It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	onEvict  func(K, V, Reason)
+	items    map[K]*linkedlist.Element[entry[K, V]]
+	order    linkedlist.DList[entry[K, V]] // front is most recent
+	clock    clock.Clock
+}
+
+// New returns a Cache holding at most capacity entries (minimum one).
+func New[K comparable, V any](capacity int, opts ...Option[K, V]) *Cache[K, V] {
+	c := &Cache[K, V]{
+		capacity: max(capacity, 1),
+		items:    make(map[K]*linkedlist.Element[entry[K, V]]),
+		clock:    clock.Real{},
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Get returns the value for key and marks it most recently used. An
+// expired entry is removed and reported as missing.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	e, ok := c.items[key]
+	if ok && c.expired(e) {
+		c.remove(e)
+		c.mu.Unlock()
+		c.notify(evicted[K, V]{e.Value.key, e.Value.value, Expired})
+		var zero V
+		return zero, false
+	}
+	if !ok {
+		c.mu.Unlock()
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(e)
+	v := e.Value.value
+	c.mu.Unlock()
+	return v, true
+}
+
+// Put stores value under key with the cache's default time to live.
+func (c *Cache[K, V]) Put(key K, value V) {
+	c.PutTTL(key, value, c.ttl)
+}
+
+// PutTTL stores value under key, expiring after ttl (never if ttl is
+// zero). If the cache is full, expired entries are dropped first, then the
+// least recently used one.
+func (c *Cache[K, V]) PutTTL(key K, value V, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = c.clock.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	if e, ok := c.items[key]; ok {
+		e.Value.value, e.Value.expires = value, expires
+		c.order.MoveToFront(e)
+		c.mu.Unlock()
+		return
+	}
+	var out []evicted[K, V]
+	if len(c.items) >= c.capacity {
+		out = c.makeRoom()
+	}
+	c.items[key] = c.order.PushFront(entry[K, V]{key, value, expires})
+	c.mu.Unlock()
+	c.notify(out...)
+}
+
+// makeRoom removes every expired entry, or if there are none the least
+// recently used one.
+func (c *Cache[K, V]) makeRoom() []evicted[K, V] {
+	var out []evicted[K, V]
+	for e := c.order.Back(); e != nil; {
+		prev := e.Prev()
+		if c.expired(e) {
+			c.remove(e)
+			out = append(out, evicted[K, V]{e.Value.key, e.Value.value, Expired})
+		}
+		e = prev
+	}
+	if len(out) == 0 {
+		e := c.order.Back()
+		c.remove(e)
+		out = append(out, evicted[K, V]{e.Value.key, e.Value.value, Evicted})
+	}
+	return out
+}
+
+// Remove deletes key and reports whether it was present.
+func (c *Cache[K, V]) Remove(key K) bool {
+	c.mu.Lock()
+	e, ok := c.items[key]
+	if ok {
+		c.remove(e)
+	}
+	c.mu.Unlock()
+	if ok {
+		c.notify(evicted[K, V]{e.Value.key, e.Value.value, Removed})
+	}
+	return ok
+}
+
+// Purge removes every entry.
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	var out []evicted[K, V]
+	for e := c.order.Front(); e != nil; e = e.Next() {
+		out = append(out, evicted[K, V]{e.Value.key, e.Value.value, Removed})
+	}
+	c.items = make(map[K]*linkedlist.Element[entry[K, V]])
+	c.order = linkedlist.DList[entry[K, V]]{}
+	c.mu.Unlock()
+	c.notify(out...)
+}
+
+// Len returns the number of entries, including expired ones not yet
+// removed.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Keys returns the keys from most to least recently used.
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]K, 0, len(c.items))
+	for e := range c.order.All() {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+func (c *Cache[K, V]) expired(e *linkedlist.Element[entry[K, V]]) bool {
+	exp := e.Value.expires
+	return !exp.IsZero() && !c.clock.Now().Before(exp)
+}
+
+func (c *Cache[K, V]) remove(e *linkedlist.Element[entry[K, V]]) {
+	c.order.Remove(e)
+	delete(c.items, e.Value.key)
+}
+
+func (c *Cache[K, V]) notify(out ...evicted[K, V]) {
+	if c.onEvict == nil {
+		return
+	}
+	for _, ev := range out {
+		c.onEvict(ev.key, ev.value, ev.reason)
+	}
+}
@@ -0,0 +1,146 @@
+package lru
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+type record struct {
+	key    string
+	reason Reason
+}
+
+func newRecorded(capacity int, opts ...Option[string, int]) (*Cache[string, int], *[]record, *clock.Fake) {
+	var got []record
+	clk := clock.NewFake(time.Unix(1000, 0))
+	opts = append(opts,
+		WithClock[string, int](clk),
+		WithOnEvict(func(k string, _ int, r Reason) { got = append(got, record{k, r}) }),
+	)
+	return New(capacity, opts...), &got, clk
+}
+
+func TestEvictsLeastRecentlyUsed(t *testing.T) {
+	c, evicted, _ := newRecorded(2)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Get("a") // b is now the oldest
+	c.Put("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("b survived eviction")
+	}
+	if got := c.Keys(); !slices.Equal(got, []string{"c", "a"}) {
+		t.Fatalf("Keys = %v, want [c a]", got)
+	}
+	if want := []record{{"b", Evicted}}; !slices.Equal(*evicted, want) {
+		t.Fatalf("evicted = %v, want %v", *evicted, want)
+	}
+}
+
+func TestOverwriteRefreshesWithoutEvicting(t *testing.T) {
+	c, evicted, _ := newRecorded(2)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("a", 10)
+	c.Put("c", 3)
+
+	if v, ok := c.Get("a"); !ok || v != 10 {
+		t.Fatalf("Get(a) = %d, %v", v, ok)
+	}
+	if want := []record{{"b", Evicted}}; !slices.Equal(*evicted, want) {
+		t.Fatalf("evicted = %v, want %v", *evicted, want)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	c, evicted, clk := newRecorded(3, WithTTL[string, int](time.Minute))
+	c.Put("a", 1)
+	c.PutTTL("forever", 2, 0)
+	c.PutTTL("short", 3, time.Second)
+
+	clk.Advance(2 * time.Second)
+	if _, ok := c.Get("short"); ok {
+		t.Fatal("expired entry returned")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("entry with time left was dropped")
+	}
+
+	clk.Advance(time.Hour)
+	c.Put("b", 4)
+	c.Put("c", 5) // full: the expired a goes before anything is evicted
+	if _, ok := c.Get("forever"); !ok {
+		t.Fatal("entry without a TTL was evicted although an expired one was available")
+	}
+	want := []record{{"short", Expired}, {"a", Expired}}
+	if !slices.Equal(*evicted, want) {
+		t.Fatalf("evicted = %v, want %v", *evicted, want)
+	}
+}
+
+func TestRemoveAndPurge(t *testing.T) {
+	c, evicted, _ := newRecorded(4)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	if !c.Remove("a") || c.Remove("a") {
+		t.Fatal("Remove did not report presence correctly")
+	}
+	c.Put("c", 3)
+	c.Purge()
+	if c.Len() != 0 {
+		t.Fatalf("Len after Purge = %d", c.Len())
+	}
+	want := []record{{"a", Removed}, {"c", Removed}, {"b", Removed}}
+	if !slices.Equal(*evicted, want) {
+		t.Fatalf("evicted = %v, want %v", *evicted, want)
+	}
+}
+
+func TestCallbackMayUseCache(t *testing.T) {
+	var c *Cache[string, int]
+	c = New(1, WithOnEvict(func(k string, v int, _ Reason) {
+		c.Len() // would deadlock if called under the lock
+	}))
+	c.Put("a", 1)
+	c.Put("b", 2)
+}
+
+func TestConcurrentUse(t *testing.T) {
+	c := New[int, int](64)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				k := (g*1000 + i) % 100
+				c.Put(k, i)
+				c.Get(k)
+			}
+		}()
+	}
+	wg.Wait()
+	if c.Len() > 64 {
+		t.Fatalf("Len = %d, above capacity", c.Len())
+	}
+}
+
+func BenchmarkGetHit(b *testing.B) {
+	c := New[string, int](1024)
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = fmt.Sprint(i)
+		c.Put(keys[i], i)
+	}
+	i := 0
+	for b.Loop() {
+		c.Get(keys[i%len(keys)])
+		i++
+	}
+}
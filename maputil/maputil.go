@@ -0,0 +1,120 @@
+// Package maputil holds generic helpers for maps that the standard maps
+// package leaves out. Functions that return maps always allocate new ones;
+// their inputs are never modified.
+package maputil
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+)
+
+// Merge combines ms from left to right into a new map. When a key appears
+// in more than one map, resolve receives the value accumulated so far and
+// the incoming one and returns the value to keep. A nil resolve keeps the
+// incoming value (last wins).
+func Merge[M ~map[K]V, K comparable, V any](resolve func(key K, current, incoming V) V, ms ...M) M {
+	size := 0
+	for _, m := range ms {
+		size = max(size, len(m))
+	}
+	out := make(M, size)
+	for _, m := range ms {
+		for k, v := range m {
+			if cur, ok := out[k]; ok && resolve != nil {
+				v = resolve(k, cur, v)
+			}
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Changes lists the keys that differ between two maps, each sorted.
+type Changes[K any] struct {
+	Added   []K // in new but not old
+	Removed []K // in old but not new
+	Changed []K // in both with different values
+}
+
+// Empty reports whether the maps were equal.
+func (c Changes[K]) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// Diff compares old and new using ==.
+func Diff[M ~map[K]V, K cmp.Ordered, V comparable](old, new M) Changes[K] {
+	return DiffFunc(old, new, func(a, b V) bool { return a == b })
+}
+
+// DiffFunc compares old and new using eq, for values that are not
+// comparable or need a looser notion of equality.
+func DiffFunc[M ~map[K]V, K cmp.Ordered, V any](old, new M, eq func(a, b V) bool) Changes[K] {
+	var c Changes[K]
+	for k, nv := range new {
+		ov, ok := old[k]
+		switch {
+		case !ok:
+			c.Added = append(c.Added, k)
+		case !eq(ov, nv):
+			c.Changed = append(c.Changed, k)
+		}
+	}
+	for k := range old {
+		if _, ok := new[k]; !ok {
+			c.Removed = append(c.Removed, k)
+		}
+	}
+	slices.Sort(c.Added)
+	slices.Sort(c.Removed)
+	slices.Sort(c.Changed)
+	return c
+}
+
+// Invert swaps keys and values. If two keys share a value, only one of them
+// survives and ok is false.
+func Invert[M ~map[K]V, K, V comparable](m M) (inverted map[V]K, ok bool) {
+	inverted = make(map[V]K, len(m))
+	for k, v := range m {
+		inverted[v] = k
+	}
+	return inverted, len(inverted) == len(m)
+}
+
+// FilterKeys returns the entries whose key satisfies keep.
+func FilterKeys[M ~map[K]V, K comparable, V any](m M, keep func(K) bool) M {
+	out := make(M)
+	for k, v := range m {
+		if keep(k) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// FilterValues returns the entries whose value satisfies keep.
+func FilterValues[M ~map[K]V, K comparable, V any](m M, keep func(V) bool) M {
+	out := make(M)
+	for k, v := range m {
+		if keep(v) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Keys returns the keys of m in ascending order.
+func Keys[M ~map[K]V, K cmp.Ordered, V any](m M) []K {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// Values returns the values of m ordered by their keys, so the result is
+// stable across runs.
+func Values[M ~map[K]V, K cmp.Ordered, V any](m M) []V {
+	keys := Keys(m)
+	out := make([]V, len(keys))
+	for i, k := range keys {
+		out[i] = m[k]
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package maputil
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	a := map[string]int{"x": 1, "y": 2}
+	b := map[string]int{"y": 20, "z": 30}
+	c := map[string]int{"y": 200}
+
+	tests := []struct {
+		name    string
+		resolve func(string, int, int) int
+		want    map[string]int
+	}{
+		{"last wins", nil, map[string]int{"x": 1, "y": 200, "z": 30}},
+		{"sum", func(_ string, cur, in int) int { return cur + in }, map[string]int{"x": 1, "y": 222, "z": 30}},
+		{"keep first", func(_ string, cur, _ int) int { return cur }, map[string]int{"x": 1, "y": 2, "z": 30}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Merge(tt.resolve, a, b, c); !maps.Equal(got, tt.want) {
+				t.Fatalf("Merge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if a["y"] != 2 {
+		t.Fatal("Merge modified an input")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := map[string]float64{"apple": 1, "pear": 2, "plum": 3}
+	new := map[string]float64{"apple": 1, "pear": 2.5, "kiwi": 4}
+
+	c := Diff(old, new)
+	if !slices.Equal(c.Added, []string{"kiwi"}) ||
+		!slices.Equal(c.Removed, []string{"plum"}) ||
+		!slices.Equal(c.Changed, []string{"pear"}) {
+		t.Fatalf("Diff() = %+v", c)
+	}
+	if c.Empty() || !Diff(old, maps.Clone(old)).Empty() {
+		t.Fatal("Empty() gave the wrong answer")
+	}
+}
+
+func TestDiffFunc(t *testing.T) {
+	old := map[int][]string{1: {"a"}, 2: {"b"}}
+	new := map[int][]string{1: {"a"}, 2: {"b", "c"}}
+	c := DiffFunc(old, new, slices.Equal)
+	if !slices.Equal(c.Changed, []int{2}) || len(c.Added)+len(c.Removed) != 0 {
+		t.Fatalf("DiffFunc() = %+v", c)
+	}
+}
+
+func TestInvert(t *testing.T) {
+	inv, ok := Invert(map[string]int{"one": 1, "two": 2})
+	if !ok || !maps.Equal(inv, map[int]string{1: "one", 2: "two"}) {
+		t.Fatalf("Invert() = %v, %v", inv, ok)
+	}
+	inv, ok = Invert(map[string]int{"a": 1, "b": 1})
+	if ok || len(inv) != 1 {
+		t.Fatalf("Invert() with duplicate values = %v, %v", inv, ok)
+	}
+}
+
+func TestFilters(t *testing.T) {
+	m := map[string]int{"apple": 3, "avocado": 0, "banana": 5}
+
+	got := FilterKeys(m, func(k string) bool { return strings.HasPrefix(k, "a") })
+	if !maps.Equal(got, map[string]int{"apple": 3, "avocado": 0}) {
+		t.Fatalf("FilterKeys() = %v", got)
+	}
+	got = FilterValues(m, func(v int) bool { return v > 0 })
+	if !maps.Equal(got, map[string]int{"apple": 3, "banana": 5}) {
+		t.Fatalf("FilterValues() = %v", got)
+	}
+}
+
+func TestKeysValues(t *testing.T) {
+	m := map[string]int{"c": 3, "a": 1, "b": 2}
+	if got := Keys(m); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Fatalf("Keys() = %v", got)
+	}
+	if got := Values(m); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("Values() = %v", got)
+	}
+	if got := Keys(map[int]bool(nil)); len(got) != 0 {
+		t.Fatalf("Keys(nil) = %v", got)
+	}
+}
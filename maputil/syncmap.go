@@ -0,0 +1,96 @@
+package maputil
+
+import "sync"
+
+// SyncMap is a map guarded by a sync.RWMutex. Unlike sync.Map it is typed,
+// so callers never assert from any, and it performs well for the general
+// read-mostly case without sync.Map's specialised access patterns. The
+// zero value is ready to use.
+type SyncMap[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  map[K]V
+}
+
+// NewSyncMap returns an empty SyncMap.
+func NewSyncMap[K comparable, V any]() *SyncMap[K, V] {
+	return &SyncMap[K, V]{m: make(map[K]V)}
+}
+
+// Load returns the value stored under key.
+func (s *SyncMap[K, V]) Load(key K) (V, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.m[key]
+	return v, ok
+}
+
+// Store sets the value for key.
+func (s *SyncMap[K, V]) Store(key K, value V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.m == nil {
+		s.m = make(map[K]V)
+	}
+	s.m[key] = value
+}
+
+// LoadOrStore returns the existing value for key if present. Otherwise it
+// stores and returns value. loaded reports which happened.
+func (s *SyncMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.m[key]; ok {
+		return v, true
+	}
+	if s.m == nil {
+		s.m = make(map[K]V)
+	}
+	s.m[key] = value
+	return value, false
+}
+
+// LoadAndDelete removes key and returns its previous value, if any.
+func (s *SyncMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.m[key]
+	delete(s.m, key)
+	return v, ok
+}
+
+// Delete removes key.
+func (s *SyncMap[K, V]) Delete(key K) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m, key)
+}
+
+// Len returns the number of entries.
+func (s *SyncMap[K, V]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.m)
+}
+
+// Range calls f for each entry until f returns false. It iterates over a
+// copy taken under the read lock, so f may call any SyncMap method —
+// including Store and Delete — without deadlocking, but it will not see
+// changes made after Range started.
+func (s *SyncMap[K, V]) Range(f func(key K, value V) bool) {
+	type entry struct {
+		k K
+		v V
+	}
+	s.mu.RLock()
+	entries := make([]entry, 0, len(s.m))
+	for k, v := range s.m {
+		entries = append(entries, entry{k, v})
+	}
+	s.mu.RUnlock()
+
+	for _, e := range entries {
+		if !f(e.k, e.v) {
+			return
+		}
+	}
+}
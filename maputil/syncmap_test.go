@@ -0,0 +1,111 @@
+package maputil
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSyncMapBasics(t *testing.T) {
+	var m SyncMap[string, int]
+	if _, ok := m.Load("a"); ok {
+		t.Fatal("Load on zero value reported ok")
+	}
+	m.Store("a", 1)
+	if v, loaded := m.LoadOrStore("a", 2); !loaded || v != 1 {
+		t.Fatalf("LoadOrStore(existing) = %d, %v", v, loaded)
+	}
+	if v, loaded := m.LoadOrStore("b", 3); loaded || v != 3 {
+		t.Fatalf("LoadOrStore(new) = %d, %v", v, loaded)
+	}
+	if v, ok := m.LoadAndDelete("a"); !ok || v != 1 {
+		t.Fatalf("LoadAndDelete = %d, %v", v, ok)
+	}
+	m.Delete("missing")
+	if m.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", m.Len())
+	}
+}
+
+func TestSyncMapRangeMayMutate(t *testing.T) {
+	m := NewSyncMap[int, int]()
+	for i := range 10 {
+		m.Store(i, i)
+	}
+	visited := 0
+	m.Range(func(k, _ int) bool {
+		m.Delete(k) // would deadlock if Range held the lock
+		m.Store(k+100, k)
+		visited++
+		return true
+	})
+	if visited != 10 || m.Len() != 10 {
+		t.Fatalf("visited %d, Len() = %d", visited, m.Len())
+	}
+}
+
+func TestSyncMapConcurrent(t *testing.T) {
+	m := NewSyncMap[int, int]()
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				m.Store(g*1000+i, i)
+				m.Load(i)
+			}
+		}()
+	}
+	wg.Wait()
+	if m.Len() != 8000 {
+		t.Fatalf("Len() = %d, want 8000", m.Len())
+	}
+}
+
+// The benchmarks compare SyncMap with sync.Map on a read-heavy mix (one
+// write per ten operations) over a fixed key space.
+
+const benchKeys = 1024
+
+func BenchmarkSyncMap(b *testing.B) {
+	m := NewSyncMap[string, int]()
+	keys := make([]string, benchKeys)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		m.Store(keys[i], i)
+	}
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			k := keys[i%benchKeys]
+			if i%10 == 0 {
+				m.Store(k, i)
+			} else {
+				m.Load(k)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkStdSyncMap(b *testing.B) {
+	var m sync.Map
+	keys := make([]string, benchKeys)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		m.Store(keys[i], i)
+	}
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			k := keys[i%benchKeys]
+			if i%10 == 0 {
+				m.Store(k, i)
+			} else if v, ok := m.Load(k); ok {
+				_ = v.(int)
+			}
+			i++
+		}
+	})
+}
@@ -0,0 +1,200 @@
+// Package matrix implements a dense float64 matrix on top of a [][]float64.
+//
+// Operations come in two flavours. Add, Scale, Multiply and Transpose
+// return a new *Matrix and leave their operands untouched. AddInPlace and
+// ScaleInPlace write through the receiver pointer instead, so every other
+// holder of that pointer observes the change — which is the point of
+// offering both.
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrShape is returned when operand dimensions are incompatible or rows
+// have different lengths.
+var ErrShape = errors.New("matrix: incompatible dimensions")
+
+// Matrix is a rows × cols matrix. Each row is its own slice.
+type Matrix struct {
+	rows, cols int
+	data       [][]float64
+}
+
+// New returns a zero matrix. Both dimensions must be positive.
+func New(rows, cols int) (*Matrix, error) {
+	if rows <= 0 || cols <= 0 {
+		return nil, fmt.Errorf("%w: %dx%d", ErrShape, rows, cols)
+	}
+	data := make([][]float64, rows)
+	for i := range data {
+		data[i] = make([]float64, cols)
+	}
+	return &Matrix{rows: rows, cols: cols, data: data}, nil
+}
+
+// FromRows copies rows into a new matrix. Every row must have the same,
+// non-zero length.
+func FromRows(rows [][]float64) (*Matrix, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no rows", ErrShape)
+	}
+	m, err := New(len(rows), len(rows[0]))
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range rows {
+		if len(r) != m.cols {
+			return nil, fmt.Errorf("%w: row %d has %d columns, want %d", ErrShape, i, len(r), m.cols)
+		}
+		copy(m.data[i], r)
+	}
+	return m, nil
+}
+
+// Identity returns the n × n identity matrix.
+func Identity(n int) (*Matrix, error) {
+	m, err := New(n, n)
+	if err != nil {
+		return nil, err
+	}
+	for i := range n {
+		m.data[i][i] = 1
+	}
+	return m, nil
+}
+
+// Rows returns the number of rows.
+func (m *Matrix) Rows() int { return m.rows }
+
+// Cols returns the number of columns.
+func (m *Matrix) Cols() int { return m.cols }
+
+// At returns the element at row i, column j. It panics if out of range.
+func (m *Matrix) At(i, j int) float64 { return m.data[i][j] }
+
+// Set stores v at row i, column j. It panics if out of range.
+func (m *Matrix) Set(i, j int, v float64) { m.data[i][j] = v }
+
+// Clone returns a deep copy; no row slice is shared with m.
+func (m *Matrix) Clone() *Matrix {
+	c, _ := FromRows(m.data)
+	return c
+}
+
+// Equal reports whether m and o have the same shape and elements.
+func (m *Matrix) Equal(o *Matrix) bool {
+	if m.rows != o.rows || m.cols != o.cols {
+		return false
+	}
+	for i := range m.data {
+		for j := range m.data[i] {
+			if m.data[i][j] != o.data[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Add returns m + o.
+func (m *Matrix) Add(o *Matrix) (*Matrix, error) {
+	out := m.Clone()
+	if err := out.AddInPlace(o); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AddInPlace sets m to m + o.
+func (m *Matrix) AddInPlace(o *Matrix) error {
+	if m.rows != o.rows || m.cols != o.cols {
+		return fmt.Errorf("%w: add %dx%d and %dx%d", ErrShape, m.rows, m.cols, o.rows, o.cols)
+	}
+	for i := range m.data {
+		for j := range m.data[i] {
+			m.data[i][j] += o.data[i][j]
+		}
+	}
+	return nil
+}
+
+// Scale returns k·m.
+func (m *Matrix) Scale(k float64) *Matrix {
+	out := m.Clone()
+	out.ScaleInPlace(k)
+	return out
+}
+
+// ScaleInPlace multiplies every element of m by k.
+func (m *Matrix) ScaleInPlace(k float64) {
+	for _, row := range m.data {
+		for j := range row {
+			row[j] *= k
+		}
+	}
+}
+
+// Transpose returns mᵀ.
+func (m *Matrix) Transpose() *Matrix {
+	out, _ := New(m.cols, m.rows)
+	for i, row := range m.data {
+		for j, v := range row {
+			out.data[j][i] = v
+		}
+	}
+	return out
+}
+
+// Multiply returns m × o. It walks both operands row by row (i-k-j loop
+// order), so the inner loop reads contiguous memory in o and out.
+func (m *Matrix) Multiply(o *Matrix) (*Matrix, error) {
+	if m.cols != o.rows {
+		return nil, fmt.Errorf("%w: multiply %dx%d by %dx%d", ErrShape, m.rows, m.cols, o.rows, o.cols)
+	}
+	out, _ := New(m.rows, o.cols)
+	for i, row := range m.data {
+		dst := out.data[i]
+		for k, a := range row {
+			src := o.data[k]
+			for j, b := range src {
+				dst[j] += a * b
+			}
+		}
+	}
+	return out, nil
+}
+
+// multiplyNaive is the textbook i-j-k loop. Its inner loop strides down a
+// column of o, touching a different row slice on every step. It is kept for
+// the benchmarks and as a reference in tests.
+func (m *Matrix) multiplyNaive(o *Matrix) (*Matrix, error) {
+	if m.cols != o.rows {
+		return nil, fmt.Errorf("%w: multiply %dx%d by %dx%d", ErrShape, m.rows, m.cols, o.rows, o.cols)
+	}
+	out, _ := New(m.rows, o.cols)
+	for i := range m.rows {
+		for j := range o.cols {
+			var sum float64
+			for k := range m.cols {
+				sum += m.data[i][k] * o.data[k][j]
+			}
+			out.data[i][j] = sum
+		}
+	}
+	return out, nil
+}
+
+// String formats the matrix one row per line.
+func (m *Matrix) String() string {
+	var b strings.Builder
+	for i, row := range m.data {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprint(&b, row)
+	}
+	return b.String()
+}
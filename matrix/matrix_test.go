@@ -0,0 +1,137 @@
+package matrix
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+func mustRows(t testing.TB, rows [][]float64) *Matrix {
+	t.Helper()
+	m, err := FromRows(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestShapeValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  func() error
+	}{
+		{"zero rows", func() error { _, err := New(0, 3); return err }},
+		{"no rows", func() error { _, err := FromRows(nil); return err }},
+		{"ragged", func() error { _, err := FromRows([][]float64{{1, 2}, {3}}); return err }},
+		{"add mismatch", func() error {
+			_, err := mustRows(t, [][]float64{{1}}).Add(mustRows(t, [][]float64{{1, 2}}))
+			return err
+		}},
+		{"multiply mismatch", func() error {
+			_, err := mustRows(t, [][]float64{{1, 2}}).Multiply(mustRows(t, [][]float64{{1, 2}}))
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.err(); !errors.Is(err, ErrShape) {
+				t.Fatalf("err = %v, want ErrShape", err)
+			}
+		})
+	}
+}
+
+func TestOperations(t *testing.T) {
+	a := mustRows(t, [][]float64{{1, 2, 3}, {4, 5, 6}})
+	b := mustRows(t, [][]float64{{7, 8}, {9, 10}, {11, 12}})
+
+	product, err := a.Multiply(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mustRows(t, [][]float64{{58, 64}, {139, 154}}); !product.Equal(want) {
+		t.Errorf("Multiply =\n%v", product)
+	}
+
+	if want := mustRows(t, [][]float64{{1, 4}, {2, 5}, {3, 6}}); !a.Transpose().Equal(want) {
+		t.Errorf("Transpose =\n%v", a.Transpose())
+	}
+
+	sum, err := a.Add(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sum.Equal(a.Scale(2)) {
+		t.Errorf("a+a != 2a:\n%v", sum)
+	}
+
+	id, _ := Identity(3)
+	if p, _ := a.Multiply(id); !p.Equal(a) {
+		t.Errorf("a × I != a")
+	}
+}
+
+func TestCopyVersusInPlace(t *testing.T) {
+	a := mustRows(t, [][]float64{{1, 2}, {3, 4}})
+	alias := a // same pointer, same matrix
+
+	scaled := a.Scale(10)
+	if a.At(0, 0) != 1 {
+		t.Fatal("Scale modified its receiver")
+	}
+	scaled.Set(0, 0, -1)
+	if a.At(0, 0) != 1 {
+		t.Fatal("Scale result shares rows with its receiver")
+	}
+
+	a.ScaleInPlace(10)
+	if alias.At(1, 1) != 40 {
+		t.Fatal("ScaleInPlace was not visible through another pointer")
+	}
+
+	clone := a.Clone()
+	clone.Set(1, 1, 0)
+	if a.At(1, 1) != 40 {
+		t.Fatal("Clone shares rows with the original")
+	}
+}
+
+func randomMatrix(t testing.TB, n int) *Matrix {
+	m, err := New(n, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range n {
+		for j := range n {
+			m.Set(i, j, rand.Float64())
+		}
+	}
+	return m
+}
+
+func TestMultiplyMatchesNaive(t *testing.T) {
+	a, b := randomMatrix(t, 17), randomMatrix(t, 17)
+	fast, _ := a.Multiply(b)
+	slow, _ := a.multiplyNaive(b)
+	for i := range 17 {
+		for j := range 17 {
+			if d := fast.At(i, j) - slow.At(i, j); d > 1e-9 || d < -1e-9 {
+				t.Fatalf("mismatch at (%d,%d): %v vs %v", i, j, fast.At(i, j), slow.At(i, j))
+			}
+		}
+	}
+}
+
+func BenchmarkMultiply(b *testing.B) {
+	x, y := randomMatrix(b, 256), randomMatrix(b, 256)
+	b.Run("naive-ijk", func(b *testing.B) {
+		for b.Loop() {
+			x.multiplyNaive(y)
+		}
+	})
+	b.Run("cache-friendly-ikj", func(b *testing.B) {
+		for b.Loop() {
+			x.Multiply(y)
+		}
+	})
+}
@@ -0,0 +1,193 @@
+// Package memlayout shows how Go lays structs out in memory.
+//
+// Every field starts at an offset that is a multiple of its alignment,
+// and a struct's size is rounded up to a multiple of its largest field
+// alignment, so the compiler inserts padding between and after fields.
+// Of reports that layout for any struct type and Layout.Draw prints it
+// byte by byte; Reordered shows what sorting fields by alignment saves.
+//
+// The unsafe helpers at the end are the few patterns that are both
+// useful and valid under the unsafe.Pointer rules.
+package memlayout
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unsafe"
+)
+
+// Padded wastes space: each bool is followed by padding so the next
+// field, or the next array element, starts aligned.
+type Padded struct {
+	A bool
+	B int64
+	C bool
+}
+
+// Packed holds the same fields as Padded, largest alignment first.
+type Packed struct {
+	B int64
+	A bool
+	C bool
+}
+
+// Field is one field's place in a struct.
+type Field struct {
+	Name    string
+	Type    string
+	Offset  uintptr
+	Size    uintptr
+	Align   uintptr
+	Padding uintptr // bytes between the end of this field and the next one, or the end of the struct
+}
+
+// Layout is the memory layout of a struct type.
+type Layout struct {
+	Type   string
+	Size   uintptr
+	Align  uintptr
+	Fields []Field
+}
+
+// Of returns the layout of struct type T. The offsets reflect reports are
+// the ones unsafe.Offsetof would return for the same fields.
+func Of[T any]() Layout {
+	return layoutOf(reflect.TypeFor[T]())
+}
+
+func layoutOf(t reflect.Type) Layout {
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("memlayout: %s is not a struct", t))
+	}
+	l := Layout{Type: t.String(), Size: t.Size(), Align: uintptr(t.Align())}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		l.Fields = append(l.Fields, Field{
+			Name:   f.Name,
+			Type:   f.Type.String(),
+			Offset: f.Offset,
+			Size:   f.Type.Size(),
+			Align:  uintptr(f.Type.Align()),
+		})
+	}
+	fillPadding(&l)
+	return l
+}
+
+func fillPadding(l *Layout) {
+	for i := range l.Fields {
+		end := l.Size
+		if i+1 < len(l.Fields) {
+			end = l.Fields[i+1].Offset
+		}
+		f := &l.Fields[i]
+		f.Padding = end - f.Offset - f.Size
+	}
+}
+
+// Padding returns the total number of padding bytes in the struct.
+func (l Layout) Padding() uintptr {
+	var n uintptr
+	for _, f := range l.Fields {
+		n += f.Padding
+	}
+	return n
+}
+
+// Reordered returns the layout the same fields would have if declared in
+// order of decreasing alignment, which minimises padding. It computes the
+// offsets with the compiler's rules rather than building a new type.
+//
+// The one exception to the rule is a trailing zero-size field, which the
+// compiler pads so that its address stays inside the struct; Reordered
+// keeps such fields first instead.
+func (l Layout) Reordered() Layout {
+	fields := slices.Clone(l.Fields)
+	slices.SortStableFunc(fields, func(a, b Field) int {
+		if (a.Size == 0) != (b.Size == 0) {
+			if a.Size == 0 {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(b.Align, a.Align)
+	})
+	var off uintptr
+	for i := range fields {
+		off = alignUp(off, fields[i].Align)
+		fields[i].Offset = off
+		off += fields[i].Size
+	}
+	r := Layout{Type: l.Type, Align: l.Align, Size: alignUp(off, l.Align), Fields: fields}
+	fillPadding(&r)
+	return r
+}
+
+func alignUp(n, align uintptr) uintptr {
+	return (n + align - 1) &^ (align - 1)
+}
+
+// Draw renders l one byte per character: each field is shown by the first
+// letter of its name and padding by a dot, eight bytes per row.
+//
+//	memlayout.Padded (24 bytes, align 8, 14 padding)
+//	 0  A.......
+//	 8  BBBBBBBB
+//	16  C.......
+func (l Layout) Draw() string {
+	cells := []byte(strings.Repeat(".", int(l.Size)))
+	for _, f := range l.Fields {
+		mark := byte('?')
+		if f.Name != "" {
+			mark = f.Name[0]
+		}
+		for i := f.Offset; i < f.Offset+f.Size; i++ {
+			cells[i] = mark
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%d bytes, align %d, %d padding)\n", l.Type, l.Size, l.Align, l.Padding())
+	for off := 0; off < len(cells); off += 8 {
+		fmt.Fprintf(&b, "%3d  %s\n", off, cells[off:min(off+8, len(cells))])
+	}
+	return b.String()
+}
+
+// Plain is the set of types with no pointers inside, whose bytes can be
+// viewed and copied without hiding anything from the garbage collector.
+type Plain interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Bytes returns the memory of s as a byte slice, without copying. Writes
+// through the result change s. The byte order is the machine's, so the
+// result is for hashing or inspection, not for a wire format.
+func Bytes[T Plain](s []T) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	var zero T
+	return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(s))), len(s)*int(unsafe.Sizeof(zero)))
+}
+
+// String returns b as a string without copying. The caller must not
+// modify b afterwards: strings are immutable and the compiler relies on
+// it.
+func String(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+// FieldAt returns a pointer to the field of *p that starts at offset,
+// typically obtained from unsafe.Offsetof. It uses unsafe.Add so the
+// arithmetic stays in one expression: a uintptr held in a variable is
+// just a number, and the object it pointed to may be collected or moved
+// before it is converted back.
+func FieldAt[F, S any](p *S, offset uintptr) *F {
+	return (*F)(unsafe.Add(unsafe.Pointer(p), offset))
+}
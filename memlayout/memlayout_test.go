@@ -0,0 +1,142 @@
+package memlayout
+
+import (
+	"encoding/binary"
+	"testing"
+	"unsafe"
+
+	"github.com/stawuah/pounce-on-go/geometry"
+	"github.com/stawuah/pounce-on-go/nilsafe"
+)
+
+const is64bit = unsafe.Sizeof(uintptr(0)) == 8
+
+func TestOfMatchesUnsafe(t *testing.T) {
+	var p Padded
+	l := Of[Padded]()
+	if l.Size != unsafe.Sizeof(p) || l.Align != unsafe.Alignof(p) {
+		t.Fatalf("size/align = %d/%d, want %d/%d", l.Size, l.Align, unsafe.Sizeof(p), unsafe.Alignof(p))
+	}
+	want := []uintptr{unsafe.Offsetof(p.A), unsafe.Offsetof(p.B), unsafe.Offsetof(p.C)}
+	for i, f := range l.Fields {
+		if f.Offset != want[i] {
+			t.Errorf("%s offset = %d, want %d", f.Name, f.Offset, want[i])
+		}
+	}
+
+	var c nilsafe.Config
+	cl := Of[nilsafe.Config]()
+	if cl.Size != unsafe.Sizeof(c) || cl.Fields[2].Offset != unsafe.Offsetof(c.TLS) {
+		t.Errorf("nilsafe.Config layout %+v disagrees with unsafe", cl)
+	}
+	var r geometry.Rectangle
+	if rl := Of[geometry.Rectangle](); rl.Size != unsafe.Sizeof(r) || rl.Padding() != 0 {
+		t.Errorf("geometry.Rectangle layout %+v", rl)
+	}
+}
+
+func TestPadding(t *testing.T) {
+	if !is64bit {
+		t.Skip("sizes below assume a 64-bit platform")
+	}
+	tests := []struct {
+		name          string
+		layout        Layout
+		size, padding uintptr
+	}{
+		{"Padded", Of[Padded](), 24, 14},
+		{"Packed", Of[Packed](), 16, 6},
+		{"Padded reordered", Of[Padded]().Reordered(), 16, 6},
+		{"nilsafe.Server", Of[nilsafe.Server](), 32, 0},
+		{"trailing zero-size field", Of[struct {
+			N int64
+			Z struct{}
+		}](), 16, 8},
+		{"zero-size field reordered", Of[struct {
+			N int64
+			Z struct{}
+		}]().Reordered(), 8, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.layout.Size != tt.size || tt.layout.Padding() != tt.padding {
+				t.Fatalf("size %d, padding %d; want %d and %d",
+					tt.layout.Size, tt.layout.Padding(), tt.size, tt.padding)
+			}
+		})
+	}
+}
+
+func TestReorderedAgreesWithCompiler(t *testing.T) {
+	got, want := Of[Padded]().Reordered(), Of[Packed]()
+	if got.Size != want.Size {
+		t.Fatalf("Reordered size %d, compiler gives %d", got.Size, want.Size)
+	}
+	for i := range got.Fields {
+		if got.Fields[i].Name != want.Fields[i].Name || got.Fields[i].Offset != want.Fields[i].Offset {
+			t.Fatalf("field %d: %+v, compiler gives %+v", i, got.Fields[i], want.Fields[i])
+		}
+	}
+}
+
+func TestDraw(t *testing.T) {
+	if !is64bit {
+		t.Skip("drawing assumes a 64-bit platform")
+	}
+	want := `memlayout.Padded (24 bytes, align 8, 14 padding)
+  0  A.......
+  8  BBBBBBBB
+ 16  C.......
+`
+	if got := Of[Padded]().Draw(); got != want {
+		t.Fatalf("Draw =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestOfPanicsOnNonStruct(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Of[int] did not panic")
+		}
+	}()
+	Of[int]()
+}
+
+func TestBytes(t *testing.T) {
+	s := []uint32{1, 0x01020304}
+	b := Bytes(s)
+	if len(b) != 8 {
+		t.Fatalf("len = %d, want 8", len(b))
+	}
+	if got := binary.NativeEndian.Uint32(b[4:]); got != 0x01020304 {
+		t.Fatalf("second element read back as %#x", got)
+	}
+	b[0] = 9 // the view aliases s
+	if binary.NativeEndian.Uint32(b[:4]) != s[0] {
+		t.Fatal("write through Bytes did not reach the slice")
+	}
+	if Bytes([]float64(nil)) != nil {
+		t.Fatal("Bytes(nil) != nil")
+	}
+}
+
+func TestStringDoesNotCopy(t *testing.T) {
+	b := []byte("catalog")
+	var s string
+	allocs := testing.AllocsPerRun(100, func() { s = String(b) })
+	if allocs != 0 || s != "catalog" {
+		t.Fatalf("String = %q with %v allocs", s, allocs)
+	}
+	if unsafe.StringData(s) != &b[0] {
+		t.Fatal("String copied the bytes")
+	}
+}
+
+func TestFieldAt(t *testing.T) {
+	c := nilsafe.Config{Host: "a", Port: 80}
+	port := FieldAt[int](&c, unsafe.Offsetof(c.Port))
+	*port = 443
+	if c.Port != 443 {
+		t.Fatalf("Port = %d after writing through FieldAt", c.Port)
+	}
+}
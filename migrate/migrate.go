@@ -0,0 +1,155 @@
+// Package migrate keeps old data files readable as the types stored in
+// them change. A file is NDJSON led by a header naming its format and
+// version:
+//
+//	{"format":"pounce.products","version":3}
+//	{"sku":"MUG-1","name":"Mug","price":{"amount":"8.00","currency":"USD"}}
+//
+// A Registry holds a format's migrations, each turning a record of one
+// version into the next, and Read applies whichever a file needs as it
+// decodes, so a file written by any earlier release loads as the current
+// type. Write always writes the current version. A file with no header
+// predates versioning and counts as version 1.
+//
+// Migrations work on records as generic JSON objects rather than Go
+// types, so the types of old versions need not be kept around: a
+// migration only has to know which fields it moves. Numbers are decoded
+// as json.Number, so migrating a record does not round them.
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/stawuah/pounce-on-go/fileio"
+)
+
+// Errors returned by Read.
+var (
+	ErrFormat = errors.New("migrate: file is of another format")
+	// ErrTooNew means the file was written by a later release, which
+	// knows versions this one does not.
+	ErrTooNew = errors.New("migrate: file is newer than this program")
+)
+
+// Record is one stored entity as a JSON object.
+type Record map[string]any
+
+// Func migrates a record from one version to the next, in place.
+type Func func(Record) error
+
+// Registry holds one format's migrations.
+type Registry struct {
+	format string
+	steps  []Func // steps[i] migrates version i+1 to i+2
+}
+
+// Header is the first line of a versioned file.
+type Header struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+}
+
+// New returns a registry for format, at version 1.
+func New(format string) *Registry { return &Registry{format: format} }
+
+// Register adds the migration from version from to from+1, which becomes
+// the current version. Migrations must be registered in order; Register
+// panics if from is not the current version, as that is a programming
+// error rather than bad data.
+func (r *Registry) Register(from int, fn Func) *Registry {
+	if from != r.Version() {
+		panic(fmt.Sprintf("migrate: %s: registering a migration from version %d at version %d", r.format, from, r.Version()))
+	}
+	r.steps = append(r.steps, fn)
+	return r
+}
+
+// Format returns the format name files carry in their header.
+func (r *Registry) Format() string { return r.format }
+
+// Version returns the current version: the one Write writes and every
+// record is migrated to.
+func (r *Registry) Version() int { return len(r.steps) + 1 }
+
+// Migrate brings rec from version from up to the current version.
+func (r *Registry) Migrate(rec Record, from int) error {
+	for v := from; v < r.Version(); v++ {
+		if err := r.steps[v-1](rec); err != nil {
+			return fmt.Errorf("migrate: %s version %d to %d: %w", r.format, v, v+1, err)
+		}
+	}
+	return nil
+}
+
+// Write writes a header for the current version and then items, one per
+// line.
+func Write[T any](w io.Writer, r *Registry, items iter.Seq[T]) error {
+	h, err := json.Marshal(Header{Format: r.format, Version: r.Version()})
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(append(h, '\n')); err != nil {
+		return err
+	}
+	return fileio.EncodeLines(w, items)
+}
+
+// Read decodes each record of a file in r's format as a T, migrating it
+// first if the file is of an earlier version, and passes it to fn.
+func Read[T any](rd io.Reader, r *Registry, fn func(T) error) error {
+	version := 0 // not known until the first line
+	return fileio.EachLine(rd, func(line []byte) error {
+		if version == 0 {
+			var h struct {
+				Format  *string `json:"format"`
+				Version int     `json:"version"`
+			}
+			if err := json.Unmarshal(line, &h); err != nil {
+				return err
+			}
+			if h.Format != nil {
+				switch {
+				case *h.Format != r.format:
+					return fmt.Errorf("%w: want %s, got %q", ErrFormat, r.format, *h.Format)
+				case h.Version > r.Version():
+					return fmt.Errorf("%w: %s version %d, want at most %d", ErrTooNew, r.format, h.Version, r.Version())
+				case h.Version < 1:
+					return fmt.Errorf("migrate: %s: bad version %d", r.format, h.Version)
+				}
+				version = h.Version
+				return nil
+			}
+			version = 1 // from before headers; this line is a record
+		}
+		if version < r.Version() {
+			var err error
+			if line, err = r.upgrade(line, version); err != nil {
+				return err
+			}
+		}
+		var v T
+		if err := json.Unmarshal(line, &v); err != nil {
+			return err
+		}
+		return fn(v)
+	})
+}
+
+// upgrade migrates one encoded record from version to the current one.
+func (r *Registry) upgrade(line []byte, version int) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var rec Record
+	if err := dec.Decode(&rec); err != nil {
+		return nil, err
+	}
+	if err := r.Migrate(rec, version); err != nil {
+		return nil, err
+	}
+	return json.Marshal(rec)
+}
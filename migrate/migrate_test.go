@@ -0,0 +1,120 @@
+package migrate
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type item struct {
+	Name  string   `json:"name"`
+	Count int64    `json:"count"`
+	Tags  []string `json:"tags"`
+}
+
+// items: version 2 added tags, version 3 renamed qty to count.
+func items() *Registry {
+	return New("test.items").
+		Register(1, func(r Record) error {
+			r["tags"] = []string{}
+			return nil
+		}).
+		Register(2, func(r Record) error {
+			qty, ok := r["qty"]
+			if !ok {
+				return errors.New("no qty")
+			}
+			r["count"] = qty
+			delete(r, "qty")
+			return nil
+		})
+}
+
+func read(t *testing.T, file string) ([]item, error) {
+	t.Helper()
+	var got []item
+	err := Read(strings.NewReader(file), items(), func(it item) error {
+		got = append(got, it)
+		return nil
+	})
+	return got, err
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want []item
+	}{
+		{"no header", `{"name":"a","qty":9007199254740993}` + "\n" + `{"name":"b","qty":2}`,
+			[]item{{"a", 9007199254740993, []string{}}, {"b", 2, []string{}}}},
+		{"version 2", `{"format":"test.items","version":2}` + "\n" + `{"name":"a","qty":1,"tags":["x"]}`,
+			[]item{{"a", 1, []string{"x"}}}},
+		{"current", `{"format":"test.items","version":3}` + "\n\n" + `{"name":"a","count":1,"tags":null}`,
+			[]item{{"a", 1, nil}}},
+		{"header only", `{"format":"test.items","version":1}`, nil},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := read(t, tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.EqualFunc(got, tt.want, func(a, b item) bool {
+				return a.Name == b.Name && a.Count == b.Count && slices.Equal(a.Tags, b.Tags) && (a.Tags == nil) == (b.Tags == nil)
+			}) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want error
+		msg  string
+	}{
+		{"newer", `{"format":"test.items","version":4}`, ErrTooNew, "version 4, want at most 3"},
+		{"other format", `{"format":"test.orders","version":1}`, ErrFormat, `"test.orders"`},
+		{"bad version", `{"format":"test.items","version":0}`, nil, "bad version 0"},
+		{"migration fails", `{"name":"a","qty":1}` + "\n" + `{"name":"b"}`, nil, "line 2: migrate: test.items version 2 to 3: no qty"},
+		{"bad JSON", `{"name":`, nil, "line 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := read(t, tt.file)
+			if err == nil || tt.want != nil && !errors.Is(err, tt.want) || !strings.Contains(err.Error(), tt.msg) {
+				t.Fatalf("Read = %v, want %v containing %q", err, tt.want, tt.msg)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	in := []item{{"a", 1, []string{"x"}}, {"b", 2, nil}}
+	if err := Write(&buf, items(), slices.Values(in)); err != nil {
+		t.Fatal(err)
+	}
+	if first, _, _ := strings.Cut(buf.String(), "\n"); first != `{"format":"test.items","version":3}` {
+		t.Fatalf("header = %s", first)
+	}
+	got, err := read(t, buf.String())
+	if err != nil || len(got) != 2 || got[0].Tags[0] != "x" || got[1].Count != 2 {
+		t.Fatalf("read back %+v, %v", got, err)
+	}
+}
+
+func TestRegisterOutOfOrder(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Register skipped a version without panicking")
+		}
+	}()
+	New("test.items").Register(2, func(Record) error { return nil })
+}
@@ -0,0 +1,85 @@
+// Package multimap provides a map from each key to a list of values.
+package multimap
+
+import (
+	"iter"
+	"slices"
+)
+
+// Map associates each key with zero or more values, kept in insertion
+// order. Keys with no values are removed, so len(m) is the number of
+// distinct keys. A nil Map behaves as empty for reads; use New or make
+// before adding.
+type Map[K, V comparable] map[K][]V
+
+// New returns an empty Map.
+func New[K, V comparable]() Map[K, V] { return make(Map[K, V]) }
+
+// Add appends values under key.
+func (m Map[K, V]) Add(key K, values ...V) {
+	if len(values) == 0 {
+		return
+	}
+	m[key] = append(m[key], values...)
+}
+
+// Get returns a copy of the values under key, so callers can modify the
+// result without corrupting the map.
+func (m Map[K, V]) Get(key K) []V { return slices.Clone(m[key]) }
+
+// Has reports whether key has any values.
+func (m Map[K, V]) Has(key K) bool { return len(m[key]) > 0 }
+
+// Contains reports whether value is stored under key.
+func (m Map[K, V]) Contains(key K, value V) bool {
+	return slices.Contains(m[key], value)
+}
+
+// RemoveValue removes every occurrence of value under key and reports
+// whether any was found.
+func (m Map[K, V]) RemoveValue(key K, value V) bool {
+	vs, ok := m[key]
+	if !ok {
+		return false
+	}
+	kept := slices.DeleteFunc(vs, func(v V) bool { return v == value })
+	if len(kept) == len(vs) {
+		return false
+	}
+	if len(kept) == 0 {
+		delete(m, key)
+	} else {
+		m[key] = kept
+	}
+	return true
+}
+
+// RemoveKey deletes key and all its values, returning them.
+func (m Map[K, V]) RemoveKey(key K) []V {
+	vs := m[key]
+	delete(m, key)
+	return vs
+}
+
+// Len returns the total number of values across all keys.
+func (m Map[K, V]) Len() int {
+	n := 0
+	for _, vs := range m {
+		n += len(vs)
+	}
+	return n
+}
+
+// All iterates every key-value pair. Keys come in map order; the values of
+// one key come in insertion order.
+func (m Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, vs := range m {
+			for _, v := range vs {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
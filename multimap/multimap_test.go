@@ -0,0 +1,74 @@
+package multimap
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAddGet(t *testing.T) {
+	m := New[string, int]()
+	m.Add("fruit", 1, 2)
+	m.Add("fruit", 3)
+	m.Add("tools")
+	if got := m.Get("fruit"); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("Get(fruit) = %v", got)
+	}
+	if m.Has("tools") || len(m) != 1 {
+		t.Fatal("adding no values created a key")
+	}
+
+	got := m.Get("fruit")
+	got[0] = 100
+	if m.Get("fruit")[0] != 1 {
+		t.Fatal("Get returned the internal slice")
+	}
+	if m.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", m.Len())
+	}
+}
+
+func TestRemove(t *testing.T) {
+	m := New[string, string]()
+	m.Add("host-a", "r1", "r2", "r1")
+	m.Add("host-b", "r3")
+
+	if !m.RemoveValue("host-a", "r1") {
+		t.Fatal("RemoveValue found nothing")
+	}
+	if got := m.Get("host-a"); !slices.Equal(got, []string{"r2"}) {
+		t.Fatalf("after RemoveValue = %v", got)
+	}
+	if m.RemoveValue("host-a", "missing") || m.RemoveValue("nobody", "r1") {
+		t.Fatal("RemoveValue reported a removal that did not happen")
+	}
+
+	m.RemoveValue("host-a", "r2")
+	if m.Has("host-a") || len(m) != 1 {
+		t.Fatal("key with no values left was not deleted")
+	}
+	if got := m.RemoveKey("host-b"); !slices.Equal(got, []string{"r3"}) || len(m) != 0 {
+		t.Fatalf("RemoveKey = %v", got)
+	}
+}
+
+func TestAll(t *testing.T) {
+	m := New[int, string]()
+	m.Add(1, "a", "b")
+	m.Add(2, "c")
+
+	seen := map[int][]string{}
+	for k, v := range m.All() {
+		seen[k] = append(seen[k], v)
+	}
+	if !slices.Equal(seen[1], []string{"a", "b"}) || !slices.Equal(seen[2], []string{"c"}) {
+		t.Fatalf("All() = %v", seen)
+	}
+
+	var nilMap Map[int, string]
+	for range nilMap.All() {
+		t.Fatal("nil map yielded a value")
+	}
+	if nilMap.Contains(1, "a") {
+		t.Fatal("nil map contains a value")
+	}
+}
@@ -0,0 +1,38 @@
+// Package nilsafe shows generated accessors for a pointer-heavy struct.
+// Reading s.Config.TLS.CertFile panics as soon as any hop is nil;
+// s.GetConfigTLSCertFile() reports false instead.
+package nilsafe
+
+//go:generate go run ../cmd/accessorgen -type Server
+
+// Server is the root of a tree of optional sections.
+type Server struct {
+	Name    string
+	Config  *Config
+	Metrics *Metrics
+}
+
+// Config holds connection settings. TLS is nil when TLS is disabled.
+type Config struct {
+	Host string
+	Port int
+	TLS  *TLS
+}
+
+// TLS holds certificate locations.
+type TLS struct {
+	CertFile string
+	KeyFile  string
+}
+
+// Metrics holds the last sampled resource usage.
+type Metrics struct {
+	CPU    float64
+	Memory *Memory
+}
+
+// Memory holds memory usage in bytes.
+type Memory struct {
+	Used  uint64
+	Total uint64
+}
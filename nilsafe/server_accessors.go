@@ -0,0 +1,100 @@
+// Code generated by accessorgen; DO NOT EDIT.
+
+package nilsafe
+
+// GetName returns Server.Name and whether every pointer on the way was non-nil.
+func (s *Server) GetName() (v string, ok bool) {
+	if s == nil {
+		return v, false
+	}
+	return s.Name, true
+}
+
+// GetConfigHost returns Server.Config.Host and whether every pointer on the way was non-nil.
+func (s *Server) GetConfigHost() (v string, ok bool) {
+	if s == nil {
+		return v, false
+	}
+	if s.Config == nil {
+		return v, false
+	}
+	return s.Config.Host, true
+}
+
+// GetConfigPort returns Server.Config.Port and whether every pointer on the way was non-nil.
+func (s *Server) GetConfigPort() (v int, ok bool) {
+	if s == nil {
+		return v, false
+	}
+	if s.Config == nil {
+		return v, false
+	}
+	return s.Config.Port, true
+}
+
+// GetConfigTLSCertFile returns Server.Config.TLS.CertFile and whether every pointer on the way was non-nil.
+func (s *Server) GetConfigTLSCertFile() (v string, ok bool) {
+	if s == nil {
+		return v, false
+	}
+	if s.Config == nil {
+		return v, false
+	}
+	if s.Config.TLS == nil {
+		return v, false
+	}
+	return s.Config.TLS.CertFile, true
+}
+
+// GetConfigTLSKeyFile returns Server.Config.TLS.KeyFile and whether every pointer on the way was non-nil.
+func (s *Server) GetConfigTLSKeyFile() (v string, ok bool) {
+	if s == nil {
+		return v, false
+	}
+	if s.Config == nil {
+		return v, false
+	}
+	if s.Config.TLS == nil {
+		return v, false
+	}
+	return s.Config.TLS.KeyFile, true
+}
+
+// GetMetricsCPU returns Server.Metrics.CPU and whether every pointer on the way was non-nil.
+func (s *Server) GetMetricsCPU() (v float64, ok bool) {
+	if s == nil {
+		return v, false
+	}
+	if s.Metrics == nil {
+		return v, false
+	}
+	return s.Metrics.CPU, true
+}
+
+// GetMetricsMemoryUsed returns Server.Metrics.Memory.Used and whether every pointer on the way was non-nil.
+func (s *Server) GetMetricsMemoryUsed() (v uint64, ok bool) {
+	if s == nil {
+		return v, false
+	}
+	if s.Metrics == nil {
+		return v, false
+	}
+	if s.Metrics.Memory == nil {
+		return v, false
+	}
+	return s.Metrics.Memory.Used, true
+}
+
+// GetMetricsMemoryTotal returns Server.Metrics.Memory.Total and whether every pointer on the way was non-nil.
+func (s *Server) GetMetricsMemoryTotal() (v uint64, ok bool) {
+	if s == nil {
+		return v, false
+	}
+	if s.Metrics == nil {
+		return v, false
+	}
+	if s.Metrics.Memory == nil {
+		return v, false
+	}
+	return s.Metrics.Memory.Total, true
+}
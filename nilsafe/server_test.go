@@ -0,0 +1,43 @@
+package nilsafe
+
+import "testing"
+
+func TestAccessorsOnPartialTree(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   *Server
+		wantHost string
+		wantOK   bool
+	}{
+		{"nil server", nil, "", false},
+		{"nil config", &Server{Name: "edge"}, "", false},
+		{"populated", &Server{Config: &Config{Host: "localhost"}}, "localhost", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, ok := tt.server.GetConfigHost()
+			if host != tt.wantHost || ok != tt.wantOK {
+				t.Fatalf("GetConfigHost() = %q, %v; want %q, %v", host, ok, tt.wantHost, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAccessorsDeepChain(t *testing.T) {
+	s := &Server{Metrics: &Metrics{CPU: 0.75}}
+
+	if cpu, ok := s.GetMetricsCPU(); !ok || cpu != 0.75 {
+		t.Fatalf("GetMetricsCPU() = %v, %v; want 0.75, true", cpu, ok)
+	}
+	if _, ok := s.GetMetricsMemoryUsed(); ok {
+		t.Fatal("GetMetricsMemoryUsed() reported ok with nil Memory")
+	}
+	if _, ok := s.GetConfigTLSCertFile(); ok {
+		t.Fatal("GetConfigTLSCertFile() reported ok with nil Config")
+	}
+
+	s.Metrics.Memory = &Memory{Used: 512}
+	if used, ok := s.GetMetricsMemoryUsed(); !ok || used != 512 {
+		t.Fatalf("GetMetricsMemoryUsed() = %v, %v; want 512, true", used, ok)
+	}
+}
@@ -0,0 +1,149 @@
+// Package notify sends messages to people: order confirmations, shipping
+// notices and the like. A Notifier delivers a Message; SMTP sends it as
+// email, Log only writes it to a logger, and Memory keeps it for tests.
+//
+// Messages are built from Templates, text/template files whose first
+// line is the subject:
+//
+//	Subject: Order {{.ID}} is on its way
+//
+//	Hello, your order shipped with tracking number {{.Tracking}}.
+//
+// Sending is slow and fails now and then, so callers normally queue
+// messages as jobs (see package jobs) rather than send them inline.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Message is one message to one recipient. It encodes as JSON so it can
+// be a job's payload.
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// A Notifier delivers messages. Notify returns once the message is
+// handed on, or with an error; an error marked retry.Permanent means
+// sending the same message again would fail the same way.
+type Notifier interface {
+	Notify(ctx context.Context, m Message) error
+}
+
+// Log is a Notifier that only logs each message at Info, for development
+// and for deployments without a mail server.
+type Log struct {
+	// Logger receives the messages; nil means slog.Default().
+	Logger *slog.Logger
+}
+
+// Notify logs m.
+func (l Log) Notify(ctx context.Context, m Message) error {
+	logger := l.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.InfoContext(ctx, "notify: message", "to", m.To, "subject", m.Subject, "body", m.Body)
+	return nil
+}
+
+// Memory is a Notifier that keeps what it is sent, for tests. Its zero
+// value is ready to use.
+type Memory struct {
+	mu   sync.Mutex
+	sent []Message
+	// Err, if set, is returned by Notify instead of keeping the message.
+	Err error
+}
+
+// Notify keeps m.
+func (n *Memory) Notify(_ context.Context, m Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.Err != nil {
+		return n.Err
+	}
+	n.sent = append(n.sent, m)
+	return nil
+}
+
+// Sent returns the messages kept so far, oldest first.
+func (n *Memory) Sent() []Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]Message(nil), n.sent...)
+}
+
+// ErrTemplate is returned for a template without a subject line.
+var ErrTemplate = errors.New(`notify: template must start with "Subject: " and a blank line`)
+
+// Template builds messages from a subject and a body template.
+type Template struct {
+	subject, body *template.Template
+}
+
+// ParseTemplate parses text, a subject line and a body separated by a
+// blank line, with the functions in funcs available to both.
+func ParseTemplate(name, text string, funcs template.FuncMap) (*Template, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	head, body, ok := strings.Cut(text, "\n\n")
+	subject, found := strings.CutPrefix(head, "Subject: ")
+	if !ok || !found || strings.Contains(subject, "\n") {
+		return nil, fmt.Errorf("%w: %s", ErrTemplate, name)
+	}
+	t := &Template{}
+	var err error
+	if t.subject, err = template.New(name).Funcs(funcs).Parse(subject); err != nil {
+		return nil, fmt.Errorf("notify: %w", err)
+	}
+	if t.body, err = template.New(name).Funcs(funcs).Parse(body); err != nil {
+		return nil, fmt.Errorf("notify: %w", err)
+	}
+	return t, nil
+}
+
+// ParseTemplates parses every file in fsys matching pattern, keyed by
+// file name without its extension.
+func ParseTemplates(fsys fs.FS, pattern string, funcs template.FuncMap) (map[string]*Template, error) {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("notify: %w", err)
+	}
+	out := make(map[string]*Template, len(names))
+	for _, name := range names {
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("notify: %w", err)
+		}
+		key := strings.TrimSuffix(path.Base(name), path.Ext(name))
+		if out[key], err = ParseTemplate(name, string(b), funcs); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Render executes t with data and returns the message for to. Runs of
+// whitespace in the subject, which a template's actions tend to leave,
+// become single spaces.
+func (t *Template) Render(to string, data any) (Message, error) {
+	var subject, body bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return Message{}, fmt.Errorf("notify: %w", err)
+	}
+	if err := t.body.Execute(&body, data); err != nil {
+		return Message{}, fmt.Errorf("notify: %w", err)
+	}
+	return Message{To: to, Subject: strings.Join(strings.Fields(subject.String()), " "), Body: body.String()}, nil
+}
@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/retry"
+)
+
+func TestTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("shipped", "Subject: Order {{.ID}}\n  {{- if .Late}} (late){{end}}\n\nTracking: {{.Tracking}}\n", nil)
+	if err == nil {
+		t.Fatal("a subject split over two lines parsed")
+	}
+	tmpl, err = ParseTemplate("shipped", "Subject: Order {{.ID}} {{if .Late}}(late){{end}}\r\n\r\nTracking: {{.Tracking}}\r\n", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := tmpl.Render("ann@example.com", map[string]any{"ID": "o-1", "Late": false, "Tracking": "1Z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Message{To: "ann@example.com", Subject: "Order o-1", Body: "Tracking: 1Z\n"}); m != want {
+		t.Fatalf("Render = %+v, want %+v", m, want)
+	}
+	if _, err := tmpl.Render("x", 42); err == nil {
+		t.Fatal("Render with the wrong data succeeded")
+	}
+
+	for _, bad := range []string{"Hello", "Subject: hi\nbody", "Subj: hi\n\nbody"} {
+		if _, err := ParseTemplate("bad", bad, nil); !errors.Is(err, ErrTemplate) {
+			t.Errorf("ParseTemplate(%q) err = %v", bad, err)
+		}
+	}
+
+	fsys := fstest.MapFS{
+		"mail/paid.txt":    {Data: []byte("Subject: Paid\n\nThanks.\n")},
+		"mail/shipped.txt": {Data: []byte("Subject: Shipped\n\nSoon.\n")},
+	}
+	ts, err := ParseTemplates(fsys, "mail/*.txt", nil)
+	if err != nil || len(ts) != 2 || ts["paid"] == nil {
+		t.Fatalf("ParseTemplates = %v, %v", ts, err)
+	}
+}
+
+func TestMemoryAndLog(t *testing.T) {
+	var n Memory
+	n.Notify(context.Background(), Message{To: "a"})
+	n.Err = errors.New("down")
+	if err := n.Notify(context.Background(), Message{To: "b"}); err != n.Err {
+		t.Fatalf("Notify err = %v", err)
+	}
+	if sent := n.Sent(); len(sent) != 1 || sent[0].To != "a" {
+		t.Fatalf("Sent = %v", sent)
+	}
+
+	var buf bytes.Buffer
+	Log{Logger: slog.New(slog.NewTextHandler(&buf, nil))}.Notify(context.Background(), Message{To: "a@example.com", Subject: "Hi"})
+	if !strings.Contains(buf.String(), "to=a@example.com subject=Hi") {
+		t.Fatalf("log = %s", buf.String())
+	}
+}
+
+// fakeSMTP serves one SMTP session on l, rejecting recipients at
+// reject.example, and sends the DATA it received on got.
+func fakeSMTP(t *testing.T, l net.Listener, got chan<- string) {
+	conn, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	c := textproto.NewConn(conn)
+	c.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, _, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			c.PrintfLine("250 fake")
+		case "MAIL":
+			c.PrintfLine("250 ok")
+		case "RCPT":
+			if strings.Contains(line, "reject.example") {
+				c.PrintfLine("550 no such user")
+			} else {
+				c.PrintfLine("250 ok")
+			}
+		case "DATA":
+			c.PrintfLine("354 go ahead")
+			data, err := c.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			got <- string(data)
+			c.PrintfLine("250 queued")
+		case "QUIT":
+			c.PrintfLine("221 bye")
+			return
+		default:
+			c.PrintfLine("502 unknown")
+		}
+	}
+}
+
+func TestSMTP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	got := make(chan string, 1)
+	go func() {
+		for range 2 {
+			fakeSMTP(t, l, got)
+		}
+	}()
+
+	s := &SMTP{Addr: l.Addr().String(), From: "shop@example.com", Now: func() time.Time { return time.Date(2025, 3, 7, 9, 30, 0, 0, time.UTC) }}
+	m := Message{To: "ann@example.com", Subject: "Order o-1 confirmed ✓", Body: "Thanks.\nTotal: $5.00\n"}
+	if err := s.Notify(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+	data := <-got
+	r := textproto.NewReader(bufio.NewReader(strings.NewReader(data)))
+	h, err := r.ReadMIMEHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Get("To") != "ann@example.com" || h.Get("From") != "shop@example.com" || h.Get("Date") != "Fri, 07 Mar 2025 09:30:00 +0000" {
+		t.Fatalf("headers = %v", h)
+	}
+	if h.Get("Subject") != "=?utf-8?q?Order_o-1_confirmed_=E2=9C=93?=" {
+		t.Fatalf("Subject = %q", h.Get("Subject"))
+	}
+	if !strings.HasSuffix(data, "\r\n\r\nThanks.\r\nTotal: $5.00\r\n") && !strings.HasSuffix(data, "\n\nThanks.\nTotal: $5.00\n") {
+		t.Fatalf("body = %q", data)
+	}
+
+	err = s.Notify(context.Background(), Message{To: "bob@reject.example", Subject: "x"})
+	if !retry.IsPermanent(err) || !strings.Contains(err.Error(), "550") {
+		t.Fatalf("rejected recipient: err = %v", err)
+	}
+
+	l.Close()
+	if err := s.Notify(context.Background(), m); err == nil || retry.IsPermanent(err) {
+		t.Fatalf("server down: err = %v, want a retryable error", err)
+	}
+}
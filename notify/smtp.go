@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/retry"
+)
+
+// SMTP is a Notifier that sends each message as a plain-text email
+// through a mail server.
+type SMTP struct {
+	// Addr is the server's host:port.
+	Addr string
+	// From is the sender's address.
+	From string
+	// Auth, if set, authenticates after STARTTLS. net/smtp's PlainAuth
+	// refuses to send a password over a connection that is not
+	// encrypted, unless the server is on localhost.
+	Auth smtp.Auth
+	// TLS configures STARTTLS, which is used whenever the server offers
+	// it; nil means verify the server's certificate for Addr's host.
+	TLS *tls.Config
+	// Now stamps the Date header; nil means time.Now.
+	Now func() time.Time
+}
+
+// Notify sends m. It gives up when ctx ends. A rejection the server
+// marks as permanent (a 5xx reply, such as an unknown recipient) is
+// marked retry.Permanent.
+func (s *SMTP) Notify(ctx context.Context, m Message) error {
+	host, _, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("notify: smtp address: %w", err))
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("notify: %w", err)
+	}
+	// net/smtp takes no context; closing the connection unblocks it.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return s.wrap(ctx, err)
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		cfg := s.TLS
+		if cfg == nil {
+			cfg = &tls.Config{ServerName: host}
+		}
+		if err := c.StartTLS(cfg); err != nil {
+			return s.wrap(ctx, err)
+		}
+	}
+	if s.Auth != nil {
+		if err := c.Auth(s.Auth); err != nil {
+			return s.wrap(ctx, err)
+		}
+	}
+	if err := c.Mail(s.From); err != nil {
+		return s.wrap(ctx, err)
+	}
+	if err := c.Rcpt(m.To); err != nil {
+		return s.wrap(ctx, err)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return s.wrap(ctx, err)
+	}
+	if _, err := w.Write(s.format(m)); err != nil {
+		return s.wrap(ctx, err)
+	}
+	if err := w.Close(); err != nil {
+		return s.wrap(ctx, err)
+	}
+	return s.wrap(ctx, c.Quit())
+}
+
+// format renders m as an RFC 5322 message with CRLF line endings.
+func (s *SMTP) format(m Message) []byte {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	var b strings.Builder
+	header := func(k, v string) { b.WriteString(k + ": " + v + "\r\n") }
+	header("From", s.From)
+	header("To", m.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "8bit")
+	b.WriteString("\r\n")
+	body := strings.ReplaceAll(m.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// wrap classifies err: the context's error if ctx ended, permanent for
+// a 5xx reply, and otherwise worth retrying.
+func (s *SMTP) wrap(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("notify: %w", ctx.Err())
+	}
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return retry.Permanent(fmt.Errorf("notify: %s: %w", s.Addr, err))
+	}
+	return fmt.Errorf("notify: %s: %w", s.Addr, err)
+}
@@ -0,0 +1,115 @@
+// Package orderedmap provides a map that remembers insertion order.
+//
+// Each key maps to a node in a doubly linked list, so Set, Get and Delete
+// stay O(1) while iteration follows the order keys were first inserted —
+// unlike a plain Go map, whose range order is deliberately randomized.
+package orderedmap
+
+import "iter"
+
+type node[K comparable, V any] struct {
+	key        K
+	value      V
+	prev, next *node[K, V]
+}
+
+// Map is an insertion-ordered map. The zero value is ready to use. A Map
+// is not safe for concurrent use.
+type Map[K comparable, V any] struct {
+	index      map[K]*node[K, V]
+	head, tail *node[K, V] // oldest, newest
+}
+
+// New returns an empty Map.
+func New[K comparable, V any]() *Map[K, V] {
+	return &Map[K, V]{}
+}
+
+// Set stores value under key. Updating an existing key keeps its original
+// position.
+func (m *Map[K, V]) Set(key K, value V) {
+	if n, ok := m.index[key]; ok {
+		n.value = value
+		return
+	}
+	if m.index == nil {
+		m.index = make(map[K]*node[K, V])
+	}
+	n := &node[K, V]{key: key, value: value, prev: m.tail}
+	if m.tail != nil {
+		m.tail.next = n
+	} else {
+		m.head = n
+	}
+	m.tail = n
+	m.index[key] = n
+}
+
+// Get returns the value stored under key.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	if n, ok := m.index[key]; ok {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Delete removes key and reports whether it was present.
+func (m *Map[K, V]) Delete(key K) bool {
+	n, ok := m.index[key]
+	if !ok {
+		return false
+	}
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else {
+		m.head = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else {
+		m.tail = n.prev
+	}
+	n.prev, n.next = nil, nil
+	delete(m.index, key)
+	return true
+}
+
+// Len returns the number of entries.
+func (m *Map[K, V]) Len() int { return len(m.index) }
+
+// Oldest returns the earliest inserted entry still present.
+func (m *Map[K, V]) Oldest() (K, V, bool) { return m.head.unpack() }
+
+// Newest returns the most recently inserted entry.
+func (m *Map[K, V]) Newest() (K, V, bool) { return m.tail.unpack() }
+
+func (n *node[K, V]) unpack() (k K, v V, ok bool) {
+	if n == nil {
+		return k, v, false
+	}
+	return n.key, n.value, true
+}
+
+// All iterates entries from oldest to newest. Deleting the current key
+// during iteration is safe; other mutations have unspecified effects.
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for n := m.head; n != nil; {
+			next := n.next
+			if !yield(n.key, n.value) {
+				return
+			}
+			n = next
+		}
+	}
+}
+
+// Keys returns the keys from oldest to newest.
+func (m *Map[K, V]) Keys() []K {
+	keys := make([]K, 0, m.Len())
+	for n := m.head; n != nil; n = n.next {
+		keys = append(keys, n.key)
+	}
+	return keys
+}
@@ -0,0 +1,103 @@
+package orderedmap
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInsertionOrder(t *testing.T) {
+	var m Map[string, int]
+	for i, k := range []string{"c", "a", "b", "d"} {
+		m.Set(k, i)
+	}
+	m.Set("a", 100) // update keeps position
+
+	if got, want := m.Keys(), []string{"c", "a", "b", "d"}; !slices.Equal(got, want) {
+		t.Fatalf("Keys() = %v, want %v", got, want)
+	}
+	if v, ok := m.Get("a"); !ok || v != 100 {
+		t.Fatalf("Get(a) = %d, %v", v, ok)
+	}
+	if _, ok := m.Get("zz"); ok {
+		t.Fatal("Get of missing key reported ok")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name   string
+		delete string
+		want   []string
+	}{
+		{"head", "a", []string{"b", "c"}},
+		{"middle", "b", []string{"a", "c"}},
+		{"tail", "c", []string{"a", "b"}},
+		{"missing", "x", []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New[string, int]()
+			m.Set("a", 1)
+			m.Set("b", 2)
+			m.Set("c", 3)
+			m.Delete(tt.delete)
+			if got := m.Keys(); !slices.Equal(got, tt.want) {
+				t.Fatalf("Keys() = %v, want %v", got, tt.want)
+			}
+			if m.Len() != len(tt.want) {
+				t.Fatalf("Len() = %d, want %d", m.Len(), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestOldestNewest(t *testing.T) {
+	m := New[int, string]()
+	if _, _, ok := m.Oldest(); ok {
+		t.Fatal("Oldest() on empty map reported ok")
+	}
+	m.Set(1, "one")
+	m.Set(2, "two")
+	m.Set(3, "three")
+	m.Delete(1)
+
+	if k, v, _ := m.Oldest(); k != 2 || v != "two" {
+		t.Fatalf("Oldest() = %d, %q", k, v)
+	}
+	if k, v, _ := m.Newest(); k != 3 || v != "three" {
+		t.Fatalf("Newest() = %d, %q", k, v)
+	}
+
+	m.Delete(2)
+	m.Delete(3)
+	if _, _, ok := m.Newest(); ok {
+		t.Fatal("Newest() after deleting everything reported ok")
+	}
+	m.Set(4, "four")
+	if k, _, _ := m.Oldest(); k != 4 {
+		t.Fatalf("Oldest() after refill = %d", k)
+	}
+}
+
+func TestAllAllowsDeletingCurrent(t *testing.T) {
+	m := New[int, int]()
+	for i := range 5 {
+		m.Set(i, i*i)
+	}
+	var seen []int
+	for k, v := range m.All() {
+		if v != k*k {
+			t.Fatalf("value for %d = %d", k, v)
+		}
+		seen = append(seen, k)
+		if k%2 == 0 {
+			m.Delete(k)
+		}
+	}
+	if want := []int{0, 1, 2, 3, 4}; !slices.Equal(seen, want) {
+		t.Fatalf("visited %v, want %v", seen, want)
+	}
+	if got := m.Keys(); !slices.Equal(got, []int{1, 3}) {
+		t.Fatalf("Keys() = %v", got)
+	}
+}
@@ -0,0 +1,108 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stawuah/pounce-on-go/product"
+	"github.com/stawuah/pounce-on-go/repository"
+)
+
+// Errors returned by Checkout.Place.
+var (
+	ErrUnknownProduct = errors.New("orders: unknown product")
+	ErrOutOfStock     = errors.New("orders: not enough stock")
+	ErrNoLines        = errors.New("orders: order has no lines")
+)
+
+// Line is one product on an order.
+type Line struct {
+	SKU      string  `json:"sku"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"` // each, when ordered
+}
+
+// Stock is how many units of a product can still be sold.
+type Stock struct {
+	SKU       string `json:"sku"`
+	Available int    `json:"available"`
+}
+
+// StockRepository stores stock by SKU.
+type StockRepository = repository.Repository[string, Stock]
+
+// NewStockRepository returns an empty StockRepository kept in memory.
+func NewStockRepository() *StockRepository {
+	return repository.New(func(s Stock) string { return s.SKU },
+		repository.WithOrder[string](func(a, b Stock) int { return strings.Compare(a.SKU, b.SKU) }))
+}
+
+// Checkout turns a basket into a paid order. Taking the stock and
+// recording the order happen in one repository.Unit, so a checkout that
+// fails part-way, for want of stock or because saving failed, leaves no
+// trace: no stock is held for an order that does not exist and no order
+// exists whose stock was not taken.
+type Checkout struct {
+	Products *repository.Repository[string, product.Product]
+	Stock    *StockRepository
+	Orders   *Repository
+	// Notify, if set, becomes each new order's Notify. It first hears of
+	// the payment once the checkout has committed.
+	Notify func(*Order, Change)
+}
+
+// Place checks out lines as order id for email, at the products' current
+// prices. It returns ErrConflict from package repository if a product or
+// its stock changed while it ran; nothing is changed then, and Place can
+// simply be called again.
+func (c *Checkout) Place(id, email string, lines []Line) (*Order, error) {
+	if len(lines) == 0 {
+		return nil, ErrNoLines
+	}
+	var o *Order
+	err := repository.Do(func(u *repository.Unit) error {
+		products := repository.Stage(u, c.Products)
+		stock := repository.Stage(u, c.Stock)
+		orders := repository.Stage(u, c.Orders)
+		if _, exists := orders.Get(id); exists {
+			return fmt.Errorf("orders: order %q already exists", id)
+		}
+
+		var total float64
+		placed := make([]Line, len(lines))
+		for i, l := range lines {
+			p, ok := products.Get(l.SKU)
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrUnknownProduct, l.SKU)
+			}
+			if l.Quantity <= 0 {
+				return fmt.Errorf("orders: %s: quantity must be positive, got %d", l.SKU, l.Quantity)
+			}
+			s, _ := stock.Get(l.SKU)
+			if s.Available < l.Quantity {
+				return fmt.Errorf("%w: %s: want %d, have %d", ErrOutOfStock, l.SKU, l.Quantity, s.Available)
+			}
+			s.Available -= l.Quantity
+			stock.Put(s)
+			placed[i] = Line{SKU: l.SKU, Quantity: l.Quantity, Price: p.Price}
+			total += float64(l.Quantity) * p.Price
+		}
+
+		o = New(id, total)
+		o.Email, o.Lines = email, placed
+		if err := o.Pay(); err != nil {
+			return err
+		}
+		orders.Put(*o)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	o.Notify = c.Notify
+	if o.Notify != nil {
+		o.Notify(o, o.History[len(o.History)-1])
+	}
+	return o, nil
+}
@@ -0,0 +1,71 @@
+package orders
+
+import (
+	"embed"
+	"log/slog"
+
+	"github.com/stawuah/pounce-on-go/jobs"
+	"github.com/stawuah/pounce-on-go/notify"
+)
+
+//go:embed templates/*.txt
+var templateFiles embed.FS
+
+// templates holds a message per status the customer hears about, keyed
+// by the status.
+var templates = func() map[string]*notify.Template {
+	t, err := notify.ParseTemplates(templateFiles, "templates/*.txt", nil)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}()
+
+// MessageKind is the job kind Confirmations queues messages as.
+const MessageKind = "order-message"
+
+// Confirmations tells customers when their orders are paid, shipped or
+// cancelled. Messages go through a job queue, so a slow or failing mail
+// server holds up neither the order nor its caller, and a message that
+// cannot be sent yet is retried.
+type Confirmations struct {
+	messages jobs.Type[notify.Message]
+	// Logger receives failures to queue a message; nil means
+	// slog.Default().
+	Logger *slog.Logger
+}
+
+// NewConfirmations registers the MessageKind job on q, sending through n.
+func NewConfirmations(q *jobs.Queue, n notify.Notifier) *Confirmations {
+	return &Confirmations{messages: jobs.Register(q, MessageKind, n.Notify)}
+}
+
+// Message renders the message for o having entered its current status.
+// ok is false if that status has no message.
+func Message(o *Order) (m notify.Message, ok bool, err error) {
+	t, ok := templates[string(o.Status)]
+	if !ok {
+		return notify.Message{}, false, nil
+	}
+	m, err = t.Render(o.Email, o)
+	return m, err == nil, err
+}
+
+// Notify queues the message for the change o just went through, if o has
+// an Email and the new status has a message. Set it as an order's Notify.
+func (c *Confirmations) Notify(o *Order, ch Change) {
+	if o.Email == "" {
+		return
+	}
+	log := c.Logger
+	if log == nil {
+		log = slog.Default()
+	}
+	m, ok, err := Message(o)
+	if ok {
+		_, err = c.messages.Enqueue(m)
+	}
+	if err != nil {
+		log.Error("orders: queueing message", "order", o.ID, "status", ch.To, "err", err)
+	}
+}
@@ -0,0 +1,165 @@
+// Package orders models an order's lifecycle on top of package fsm:
+//
+//	pending ──pay──▶ paid ──ship──▶ shipped ──deliver──▶ delivered
+//	   │               │
+//	   └────cancel─────┴──▶ cancelled
+//
+// Every status change goes through one shared machine, so an order cannot
+// be shipped before it is paid or cancelled once it has left the
+// warehouse, and every change is recorded in the order's history by an
+// entry hook rather than by each method remembering to do it.
+package orders
+
+import (
+	"errors"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/fsm"
+)
+
+// Status is where an order is in its lifecycle.
+type Status string
+
+const (
+	Pending   Status = "pending"
+	Paid      Status = "paid"
+	Shipped   Status = "shipped"
+	Delivered Status = "delivered"
+	Cancelled Status = "cancelled"
+)
+
+// Event is something that happens to an order.
+type Event string
+
+const (
+	Pay     Event = "pay"
+	Ship    Event = "ship"
+	Deliver Event = "deliver"
+	Cancel  Event = "cancel"
+)
+
+// Errors returned by guards, wrapped in an *fsm.TransitionError.
+var (
+	ErrNothingToPay = errors.New("orders: order total is zero")
+	ErrNoTracking   = errors.New("orders: tracking number required")
+)
+
+// Change is one entry in an order's history.
+type Change struct {
+	From, To Status
+	At       time.Time
+}
+
+// Order is a customer order.
+type Order struct {
+	ID       string
+	Lines    []Line // what was ordered, if placed by Checkout
+	Total    float64
+	Status   Status
+	Tracking string
+	Reason   string // why it was cancelled
+	Refunded bool   // set when a paid order is cancelled
+	History  []Change
+	// Email is where messages about the order go, if anywhere.
+	Email string
+	// Notify, if set, is called after every status change, once the
+	// order reflects it. Confirmations.Notify fits. It is not stored.
+	Notify func(*Order, Change) `json:"-"`
+
+	now func() time.Time
+}
+
+// New returns a pending order.
+func New(id string, total float64) *Order {
+	return &Order{ID: id, Total: total, Status: Pending, now: time.Now}
+}
+
+// clock returns the time to stamp a change with. An order loaded from a
+// Repository has no now of its own.
+func (o *Order) clock() time.Time {
+	if o.now == nil {
+		return time.Now()
+	}
+	return o.now()
+}
+
+var lifecycle = fsm.New[Status, Event, *Order]().
+	Add(fsm.Transition[Status, Event, *Order]{
+		From: []Status{Pending}, Event: Pay, To: Paid,
+		Guard: func(o *Order) error {
+			if o.Total <= 0 {
+				return ErrNothingToPay
+			}
+			return nil
+		},
+	}).
+	Add(fsm.Transition[Status, Event, *Order]{
+		From: []Status{Paid}, Event: Ship, To: Shipped,
+		Guard: func(o *Order) error {
+			if o.Tracking == "" {
+				return ErrNoTracking
+			}
+			return nil
+		},
+	}).
+	Add(fsm.Transition[Status, Event, *Order]{From: []Status{Shipped}, Event: Deliver, To: Delivered}).
+	Add(fsm.Transition[Status, Event, *Order]{From: []Status{Pending, Paid}, Event: Cancel, To: Cancelled})
+
+func init() {
+	for _, s := range []Status{Paid, Shipped, Delivered, Cancelled} {
+		lifecycle.OnEnter(s, func(o *Order, from, to Status) {
+			o.History = append(o.History, Change{From: from, To: to, At: o.clock()})
+		})
+	}
+	// Money taken for an order that will not ship goes back.
+	lifecycle.OnExit(Paid, func(o *Order, _, to Status) {
+		if to == Cancelled {
+			o.Refunded = true
+		}
+	})
+}
+
+func (o *Order) fire(e Event) error {
+	next, err := lifecycle.Fire(o, o.Status, e)
+	o.Status = next
+	if err == nil && o.Notify != nil {
+		o.Notify(o, o.History[len(o.History)-1])
+	}
+	return err
+}
+
+// Pay marks the order paid.
+func (o *Order) Pay() error { return o.fire(Pay) }
+
+// Ship marks the order shipped with the given tracking number. On failure
+// the order keeps its previous tracking number.
+func (o *Order) Ship(tracking string) error {
+	prev := o.Tracking
+	o.Tracking = tracking
+	if err := o.fire(Ship); err != nil {
+		o.Tracking = prev
+		return err
+	}
+	return nil
+}
+
+// Deliver marks the order delivered.
+func (o *Order) Deliver() error { return o.fire(Deliver) }
+
+// Cancel cancels an order that has not shipped. On failure the order
+// keeps its previous reason.
+func (o *Order) Cancel(reason string) error {
+	prev := o.Reason
+	o.Reason = reason
+	if err := o.fire(Cancel); err != nil {
+		o.Reason = prev
+		return err
+	}
+	return nil
+}
+
+// Next returns the events the order's current status allows.
+func (o *Order) Next() []Event { return lifecycle.Events(o.Status) }
+
+// Done reports whether the order has reached a final status.
+func (o *Order) Done() bool { return lifecycle.Terminal(o.Status) }
@@ -0,0 +1,299 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/fsm"
+	"github.com/stawuah/pounce-on-go/jobs"
+	"github.com/stawuah/pounce-on-go/notify"
+	"github.com/stawuah/pounce-on-go/product"
+	"github.com/stawuah/pounce-on-go/repository"
+)
+
+func newOrder(total float64) *Order {
+	o := New("o-1", total)
+	at := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	o.now = func() time.Time { at = at.Add(time.Hour); return at }
+	return o
+}
+
+func TestHappyPath(t *testing.T) {
+	o := newOrder(25)
+	if !slices.Equal(o.Next(), []Event{Pay, Cancel}) {
+		t.Fatalf("Next() = %v", o.Next())
+	}
+	for _, step := range []func() error{o.Pay, func() error { return o.Ship("1Z999") }, o.Deliver} {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if o.Status != Delivered || !o.Done() || o.Tracking != "1Z999" {
+		t.Fatalf("order = %+v", o)
+	}
+	var trail []Status
+	for _, c := range o.History {
+		trail = append(trail, c.To)
+	}
+	if !slices.Equal(trail, []Status{Paid, Shipped, Delivered}) {
+		t.Fatalf("history = %v", trail)
+	}
+	if !o.History[0].At.Before(o.History[2].At) {
+		t.Fatal("history not timestamped in order")
+	}
+}
+
+func TestInvalidTransitions(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  []Event
+		action func(*Order) error
+		want   error
+		status Status
+	}{
+		{"ship before pay", nil, func(o *Order) error { return o.Ship("x") }, fsm.ErrInvalidTransition, Pending},
+		{"deliver before ship", []Event{Pay}, (*Order).Deliver, fsm.ErrInvalidTransition, Paid},
+		{"cancel after ship", []Event{Pay, Ship}, func(o *Order) error { return o.Cancel("late") }, fsm.ErrInvalidTransition, Shipped},
+		{"pay twice", []Event{Pay}, (*Order).Pay, fsm.ErrInvalidTransition, Paid},
+		{"anything after cancel", []Event{Cancel}, (*Order).Pay, fsm.ErrInvalidTransition, Cancelled},
+		{"ship without tracking", []Event{Pay}, func(o *Order) error { return o.Ship("") }, ErrNoTracking, Paid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOrder(10)
+			o.Tracking = "T-1"
+			for _, e := range tt.setup {
+				if err := o.fire(e); err != nil {
+					t.Fatalf("setup %s: %v", e, err)
+				}
+			}
+			err := tt.action(o)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if o.Status != tt.status {
+				t.Fatalf("status = %s after refused event, want %s", o.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestGuardsAndHooks(t *testing.T) {
+	free := newOrder(0)
+	if err := free.Pay(); !errors.Is(err, ErrNothingToPay) {
+		t.Fatalf("paying a zero order = %v", err)
+	}
+
+	o := newOrder(10)
+	o.Pay()
+	o.Ship("")
+	if o.Tracking != "" {
+		t.Fatal("failed Ship left a tracking number")
+	}
+	if err := o.Cancel("changed mind"); err != nil {
+		t.Fatal(err)
+	}
+	if !o.Refunded || o.Reason != "changed mind" {
+		t.Fatalf("cancelled paid order: refunded %v, reason %q", o.Refunded, o.Reason)
+	}
+
+	unpaid := newOrder(10)
+	unpaid.Cancel("")
+	if unpaid.Refunded {
+		t.Fatal("unpaid order refunded")
+	}
+}
+
+func TestConfirmations(t *testing.T) {
+	q, err := jobs.New(jobs.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent notify.Memory
+	c := NewConfirmations(q, &sent)
+
+	a := newOrder(25)
+	a.Email, a.Notify = "ann@example.com", c.Notify
+	b := New("o-2", 10)
+	b.Notify = c.Notify // no Email: nothing is sent
+	for _, step := range []func() error{a.Pay, func() error { return a.Ship("1Z999") }, a.Deliver, b.Pay} {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := New("o-3", 40)
+	d.Email, d.Notify = "dan@example.com", c.Notify
+	d.Pay()
+	if err := d.Ship(""); err == nil {
+		t.Fatal("Ship without tracking succeeded")
+	}
+	d.Cancel("out of stock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- q.Run(ctx, 2) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sent.Sent()) < 4 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	got := map[string]notify.Message{}
+	for _, m := range sent.Sent() {
+		got[m.To+" "+m.Subject] = m
+	}
+	if len(got) != 4 {
+		t.Fatalf("sent %d distinct messages: %v", len(got), sent.Sent())
+	}
+	for key, want := range map[string]string{
+		"ann@example.com Order o-1 confirmed":   "Total: $25.00",
+		"ann@example.com Order o-1 has shipped": "Tracking number: 1Z999",
+		"dan@example.com Order o-3 confirmed":   "Total: $40.00",
+		"dan@example.com Order o-3 cancelled":   "cancelled: out of stock.\n\nThe $40.00 you paid will be refunded",
+	} {
+		if m, ok := got[key]; !ok || !strings.Contains(m.Body, want) {
+			t.Errorf("%s: body %q, want it to contain %q", key, m.Body, want)
+		}
+	}
+}
+
+func TestCancelKeepsReasonOnFailure(t *testing.T) {
+	o := newOrder(25)
+	o.Cancel("changed my mind")
+	if err := o.Cancel("again"); err == nil || o.Reason != "changed my mind" {
+		t.Fatalf("second Cancel: err = %v, Reason = %q", err, o.Reason)
+	}
+}
+
+func TestRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.ndjson")
+	r, err := OpenRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := newOrder(25)
+	o.Email = "ann@example.com"
+	o.Notify = func(*Order, Change) {}
+	o.Pay()
+	if err := r.Put(*o); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = OpenRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := r.Get("o-1")
+	if !ok || got.Status != Paid || got.Email != "ann@example.com" || len(got.History) != 1 || got.Notify != nil {
+		t.Fatalf("loaded %+v, %v", got, ok)
+	}
+	// A loaded order carries on from where it was.
+	if err := got.Ship("1Z999"); err != nil || got.History[1].At.IsZero() {
+		t.Fatalf("Ship after load: %v, %+v", err, got.History)
+	}
+}
+
+func newCheckout() *Checkout {
+	products := repository.New(func(p product.Product) string { return p.SKU })
+	products.Put(product.Product{SKU: "MUG", Name: "Mug", Price: 8})
+	products.Put(product.Product{SKU: "TEE", Name: "T-shirt", Price: 20})
+	stock := NewStockRepository()
+	stock.Put(Stock{SKU: "MUG", Available: 5})
+	stock.Put(Stock{SKU: "TEE", Available: 1})
+	return &Checkout{Products: products, Stock: stock, Orders: NewRepository()}
+}
+
+func TestCheckout(t *testing.T) {
+	c := newCheckout()
+	var heard []Status
+	c.Notify = func(o *Order, ch Change) {
+		if _, ok := c.Orders.Get(o.ID); !ok {
+			t.Error("notified before the order was stored")
+		}
+		heard = append(heard, ch.To)
+	}
+
+	o, err := c.Place("o-1", "ann@example.com", []Line{{SKU: "MUG", Quantity: 2}, {SKU: "TEE", Quantity: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != Paid || o.Total != 36 || o.Lines[0].Price != 8 || !slices.Equal(heard, []Status{Paid}) {
+		t.Fatalf("placed %+v, heard %v", o, heard)
+	}
+	if s, _ := c.Stock.Get("MUG"); s.Available != 3 {
+		t.Fatalf("MUG stock = %d", s.Available)
+	}
+	if stored, ok := c.Orders.Get("o-1"); !ok || stored.Email != "ann@example.com" || len(stored.Lines) != 2 {
+		t.Fatalf("stored %+v, %v", stored, ok)
+	}
+
+	// Each failure leaves stock and orders as they were, including the
+	// stock of lines before the one that failed.
+	tests := []struct {
+		name  string
+		id    string
+		lines []Line
+		want  error
+	}{
+		{"out of stock", "o-2", []Line{{SKU: "MUG", Quantity: 1}, {SKU: "TEE", Quantity: 1}}, ErrOutOfStock},
+		{"unknown product", "o-2", []Line{{SKU: "MUG", Quantity: 1}, {SKU: "HAT", Quantity: 1}}, ErrUnknownProduct},
+		{"no lines", "o-2", nil, ErrNoLines},
+		{"bad quantity", "o-2", []Line{{SKU: "MUG", Quantity: 0}}, nil},
+		{"existing order", "o-1", []Line{{SKU: "MUG", Quantity: 1}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Place(tt.id, "", tt.lines)
+			if err == nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("Place = %v, want %v", err, tt.want)
+			}
+			if s, _ := c.Stock.Get("MUG"); s.Available != 3 {
+				t.Errorf("MUG stock = %d", s.Available)
+			}
+			if n := c.Orders.Len(); n != 1 {
+				t.Errorf("%d orders stored", n)
+			}
+		})
+	}
+	if len(heard) != 1 {
+		t.Fatalf("heard %v", heard)
+	}
+}
+
+// Checkouts racing for the last units never sell more than there is.
+func TestCheckoutConcurrent(t *testing.T) {
+	c := newCheckout()
+	var (
+		wg   sync.WaitGroup
+		sold atomic.Int32
+	)
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				_, err := c.Place(fmt.Sprint("o-", i), "", []Line{{SKU: "MUG", Quantity: 1}})
+				if errors.Is(err, repository.ErrConflict) {
+					continue
+				}
+				if err == nil {
+					sold.Add(1)
+				}
+				return
+			}
+		}()
+	}
+	wg.Wait()
+	if s, _ := c.Stock.Get("MUG"); sold.Load() != 5 || s.Available != 0 || c.Orders.Len() != 5 {
+		t.Fatalf("sold %d, %d left, %d orders", sold.Load(), s.Available, c.Orders.Len())
+	}
+}
@@ -0,0 +1,27 @@
+package orders
+
+import (
+	"strings"
+
+	"github.com/stawuah/pounce-on-go/repository"
+)
+
+// Repository stores orders by ID. Stored orders are copies: change one
+// and Put it back. Notify is not stored, so set it again on an order you
+// Get before changing its status.
+type Repository = repository.Repository[string, Order]
+
+// NewRepository returns an empty Repository kept in memory.
+func NewRepository() *Repository {
+	return repository.New(orderID, byID)
+}
+
+// OpenRepository returns a Repository kept in the file at path, one order
+// per line in JSON.
+func OpenRepository(path string) (*Repository, error) {
+	return repository.Open(path, orderID, byID)
+}
+
+func orderID(o Order) string { return o.ID }
+
+var byID = repository.WithOrder[string](func(a, b Order) int { return strings.Compare(a.ID, b.ID) })
@@ -0,0 +1,80 @@
+// Package pagination splits in-memory collections into numbered pages.
+//
+// Compute does the arithmetic on a total count, so it also serves callers
+// that stream their items instead of holding a slice; Paginate applies it
+// to a slice. Window is the same arithmetic for callers that page by
+// offset and limit instead, such as package query.
+package pagination
+
+// Defaults applied by Compute when the caller's values are out of range.
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+// PageInfo describes one page of a collection. Pages are numbered from 1.
+type PageInfo struct {
+	Page       int  `json:"page"`
+	PerPage    int  `json:"per_page"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasPrev    bool `json:"has_prev"`
+	HasNext    bool `json:"has_next"`
+
+	// Offset and End bound the page's items: [Offset, End).
+	Offset int `json:"-"`
+	End    int `json:"-"`
+}
+
+// Compute clamps the request into range and returns the page bounds for a
+// collection of total items:
+//
+//   - perPage < 1 becomes DefaultPerPage; perPage > MaxPerPage becomes
+//     MaxPerPage.
+//   - page < 1 becomes 1; page past the end becomes the last page.
+//
+// An empty collection has a single, empty page 1.
+func Compute(total, page, perPage int) PageInfo {
+	total = max(total, 0)
+	switch {
+	case perPage < 1:
+		perPage = DefaultPerPage
+	case perPage > MaxPerPage:
+		perPage = MaxPerPage
+	}
+	totalPages := max((total+perPage-1)/perPage, 1)
+	page = min(max(page, 1), totalPages)
+
+	offset := (page - 1) * perPage
+	return PageInfo{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+		HasPrev:    page > 1,
+		HasNext:    page < totalPages,
+		Offset:     offset,
+		End:        min(offset+perPage, total),
+	}
+}
+
+// Paginate returns the requested page of s and its metadata. The page is a
+// view into s, capped so that appending to it cannot overwrite the next
+// page.
+func Paginate[S ~[]E, E any](s S, page, perPage int) (S, PageInfo) {
+	info := Compute(len(s), page, perPage)
+	return s[info.Offset:info.End:info.End], info
+}
+
+// Window returns the bounds [lo, hi) of the items that offset/limit paging
+// selects from a collection of total items: up to limit items starting at
+// offset, or all the rest when limit is 0. Unlike Compute it does not clamp
+// an offset past the end back onto the last page; that window is empty.
+func Window(total, offset, limit int) (lo, hi int) {
+	total = max(total, 0)
+	lo = min(max(offset, 0), total)
+	if limit <= 0 {
+		return lo, total
+	}
+	return lo, min(lo+limit, total)
+}
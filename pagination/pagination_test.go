@@ -0,0 +1,84 @@
+package pagination
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name                 string
+		total, page, perPage int
+		want                 PageInfo
+	}{
+		{"first page", 45, 1, 20, PageInfo{Page: 1, PerPage: 20, Total: 45, TotalPages: 3, HasNext: true, Offset: 0, End: 20}},
+		{"middle page", 45, 2, 20, PageInfo{Page: 2, PerPage: 20, Total: 45, TotalPages: 3, HasPrev: true, HasNext: true, Offset: 20, End: 40}},
+		{"short last page", 45, 3, 20, PageInfo{Page: 3, PerPage: 20, Total: 45, TotalPages: 3, HasPrev: true, Offset: 40, End: 45}},
+		{"page past end clamps", 45, 9, 20, PageInfo{Page: 3, PerPage: 20, Total: 45, TotalPages: 3, HasPrev: true, Offset: 40, End: 45}},
+		{"page zero clamps", 45, 0, 20, PageInfo{Page: 1, PerPage: 20, Total: 45, TotalPages: 3, HasNext: true, Offset: 0, End: 20}},
+		{"default per page", 5, 1, 0, PageInfo{Page: 1, PerPage: DefaultPerPage, Total: 5, TotalPages: 1, Offset: 0, End: 5}},
+		{"max per page", 500, 1, 1000, PageInfo{Page: 1, PerPage: MaxPerPage, Total: 500, TotalPages: 5, HasNext: true, Offset: 0, End: 100}},
+		{"empty", 0, 3, 10, PageInfo{Page: 1, PerPage: 10, Total: 0, TotalPages: 1, Offset: 0, End: 0}},
+		{"exact multiple", 40, 2, 20, PageInfo{Page: 2, PerPage: 20, Total: 40, TotalPages: 2, HasPrev: true, Offset: 20, End: 40}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compute(tt.total, tt.page, tt.perPage); got != tt.want {
+				t.Fatalf("Compute() = %+v\nwant        %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+
+	page, info := Paginate(items, 2, 2)
+	if !slices.Equal(page, []string{"c", "d"}) || !info.HasNext || !info.HasPrev {
+		t.Fatalf("Paginate() = %v, %+v", page, info)
+	}
+
+	// The page is a capped view: appending must not overwrite "e".
+	_ = append(page, "x")
+	if items[4] != "e" {
+		t.Fatal("append to a page overwrote the next item")
+	}
+
+	if empty, _ := Paginate([]int(nil), 1, 10); len(empty) != 0 {
+		t.Fatalf("Paginate(nil) = %v", empty)
+	}
+}
+
+func TestPageInfoJSON(t *testing.T) {
+	data, err := json.Marshal(Compute(45, 2, 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"page":2,"per_page":20,"total":45,"total_pages":3,"has_prev":true,"has_next":true}`
+	if string(data) != want {
+		t.Fatalf("JSON = %s", data)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	tests := []struct {
+		name                 string
+		total, offset, limit int
+		lo, hi               int
+	}{
+		{"first", 45, 0, 20, 0, 20},
+		{"short last", 45, 40, 20, 40, 45},
+		{"no limit", 45, 10, 0, 10, 45},
+		{"offset past end", 45, 60, 20, 45, 45},
+		{"negative offset", 45, -5, 10, 0, 10},
+		{"empty", 0, 0, 10, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if lo, hi := Window(tt.total, tt.offset, tt.limit); lo != tt.lo || hi != tt.hi {
+				t.Fatalf("Window() = [%d, %d), want [%d, %d)", lo, hi, tt.lo, tt.hi)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Package panics turns panics into errors at goroutine and request
+// boundaries.
+//
+// A panic that escapes a goroutine kills the whole process, so every
+// goroutine that runs code it does not control (a job, a handler, a
+// plugin) should recover at its top. Try does that for a function call;
+// Middleware does it for HTTP handlers. Both keep the stack of the panic,
+// which is lost once the goroutine unwinds.
+//
+// The Must convention is the reverse: a MustX function panics on error.
+// It is for inputs fixed at compile time, such as package-level regexps
+// or cron expressions, where an error is a programming mistake and
+// failing at start-up is the right outcome. Never call MustX on user
+// input.
+package panics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// Error records a recovered panic.
+type Error struct {
+	Value any    // the value passed to panic
+	Stack []byte // the panicking goroutine's stack
+}
+
+func (e *Error) Error() string { return fmt.Sprintf("panic: %v", e.Value) }
+
+// Unwrap returns the panic value if it was an error, so errors.Is and
+// errors.As see through a panic(err).
+func (e *Error) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Try calls fn and returns its error, or an *Error if fn panics.
+func Try(fn func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &Error{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+// Must returns v, panicking if err is non-nil.
+func Must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Middleware recovers panics from next, reports them to onPanic (which
+// may be nil) and answers 500 if nothing has been written yet.
+// http.ErrAbortHandler is re-panicked: it is the sanctioned way for a
+// handler to abort a response and net/http handles it quietly.
+func Middleware(next http.Handler, onPanic func(*http.Request, *Error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tw := &trackingWriter{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(v)
+			}
+			if onPanic != nil {
+				onPanic(r, &Error{Value: v, Stack: debug.Stack()})
+			}
+			if !tw.wrote {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(tw, r)
+	})
+}
+
+// trackingWriter remembers whether the response has started, since a
+// status code cannot be sent after that.
+type trackingWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (w *trackingWriter) WriteHeader(code int) {
+	w.wrote = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *trackingWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *trackingWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
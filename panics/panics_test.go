@@ -0,0 +1,111 @@
+package panics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestTry(t *testing.T) {
+	if err := Try(func() error { return nil }); err != nil {
+		t.Fatalf("Try(ok) = %v", err)
+	}
+
+	errPlain := errors.New("plain")
+	if err := Try(func() error { return errPlain }); err != errPlain {
+		t.Fatalf("Try(error) = %v", err)
+	}
+
+	err := Try(func() error {
+		var m map[string]int
+		m["x"] = 1 // nil map write
+		return nil
+	})
+	var pe *Error
+	if !errors.As(err, &pe) {
+		t.Fatalf("Try(panic) = %v, want *Error", err)
+	}
+	if !strings.Contains(pe.Error(), "assignment to entry in nil map") {
+		t.Fatalf("message = %q", pe.Error())
+	}
+	if !strings.Contains(string(pe.Stack), "TestTry") {
+		t.Fatal("stack does not include the panicking function")
+	}
+}
+
+func TestTryUnwrapsPanickedError(t *testing.T) {
+	errBad := errors.New("bad state")
+	err := Try(func() error { panic(errBad) })
+	if !errors.Is(err, errBad) {
+		t.Fatalf("errors.Is through panic = false for %v", err)
+	}
+}
+
+func TestMust(t *testing.T) {
+	re := Must(regexp.Compile(`^[A-Z]\d+$`))
+	if !re.MatchString("A1") {
+		t.Fatal("Must returned a broken value")
+	}
+
+	err := Try(func() error {
+		Must(regexp.Compile(`(`))
+		return nil
+	})
+	var pe *Error
+	if !errors.As(err, &pe) {
+		t.Fatalf("Must on error did not panic: %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var reported *Error
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/boom" {
+			panic("handler exploded")
+		}
+		w.Write([]byte("fine"))
+	}), func(_ *http.Request, e *Error) { reported = e })
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/boom", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	if reported == nil || reported.Value != "handler exploded" {
+		t.Fatalf("reported = %v", reported)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/ok", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "fine" {
+		t.Fatalf("normal request: %d %q", rec.Code, rec.Body)
+	}
+}
+
+func TestMiddlewareAfterWrite(t *testing.T) {
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("late")
+	}), nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d; headers already sent must not be overwritten", rec.Code)
+	}
+}
+
+func TestMiddlewareRepanicsAbort(t *testing.T) {
+	h := Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}), func(*http.Request, *Error) { t.Error("ErrAbortHandler reported as a panic") })
+
+	defer func() {
+		if recover() != http.ErrAbortHandler {
+			t.Fatal("ErrAbortHandler was swallowed")
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
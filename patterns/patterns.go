@@ -0,0 +1,90 @@
+// Package patterns holds the regular expressions the product tools use to
+// check and clean up their input: SKU validation, search-term
+// sanitisation and parsing "name:price" quick-add strings.
+//
+// Every pattern is compiled once, into a package-level variable, when the
+// package is initialised. regexp.MustCompile inside a function would
+// recompile on every call, which the benchmarks show costs far more than
+// the match itself. A *regexp.Regexp is safe for concurrent use, so one
+// shared value is all any caller needs.
+package patterns
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaxSKULen is the longest SKU ValidSKU accepts.
+const MaxSKULen = 32
+
+// MaxSearchRunes is how much of a search term SanitizeSearch keeps.
+const MaxSearchRunes = 64
+
+// skuExpr is one or more runs of upper-case letters and digits joined by
+// single dashes: "A1", "ANV-1", "SKU-00042".
+const skuExpr = `^[A-Z0-9]+(?:-[A-Z0-9]+)*$`
+
+var (
+	sku      = regexp.MustCompile(skuExpr)
+	notSKU   = regexp.MustCompile(`[^A-Z0-9]+`)
+	control  = regexp.MustCompile(`\p{C}`)
+	spaces   = regexp.MustCompile(`\s+`)
+	wildcard = regexp.MustCompile(`^[*%]+|[*%]+$`)
+
+	// quickAdd matches "Steel Anvil: $1,249.50". The name is everything
+	// before the first colon; the price may have a dollar sign, thousands
+	// separators and up to two decimals.
+	quickAdd = regexp.MustCompile(`^\s*(?P<name>[^:]*?)\s*:\s*\$?(?P<price>\d{1,3}(?:,\d{3})+(?:\.\d{1,2})?|\d+(?:\.\d{1,2})?)\s*$`)
+	nameIdx  = quickAdd.SubexpIndex("name")
+	priceIdx = quickAdd.SubexpIndex("price")
+)
+
+// ValidSKU reports whether s is a well-formed SKU.
+func ValidSKU(s string) bool {
+	return len(s) <= MaxSKULen && sku.MatchString(s)
+}
+
+// DeriveSKU makes a SKU from a product name: upper-cased, with each run of
+// other characters turned into a dash. It returns "" if name has no
+// letters or digits.
+func DeriveSKU(name string) string {
+	s := strings.Trim(notSKU.ReplaceAllString(strings.ToUpper(name), "-"), "-")
+	if len(s) > MaxSKULen {
+		s = strings.TrimRight(s[:MaxSKULen], "-")
+	}
+	return s
+}
+
+// SanitizeSearch cleans a search term typed by a user: control characters
+// are dropped, runs of whitespace become one space, wildcards people add
+// out of habit ("ham*") are trimmed from either end, and the result is
+// cut to MaxSearchRunes. Other punctuation is kept, since product names
+// contain it.
+func SanitizeSearch(q string) string {
+	q = control.ReplaceAllString(q, " ")
+	q = spaces.ReplaceAllString(q, " ")
+	q = strings.TrimSpace(wildcard.ReplaceAllString(strings.TrimSpace(q), ""))
+	if r := []rune(q); len(r) > MaxSearchRunes {
+		q = strings.TrimSpace(string(r[:MaxSearchRunes]))
+	}
+	return q
+}
+
+// ErrQuickAdd is wrapped by ParseQuickAdd's errors.
+var ErrQuickAdd = errors.New(`quick-add: want "name:price"`)
+
+// ParseQuickAdd splits a "name:price" string such as "Steel Anvil: 12.50".
+func ParseQuickAdd(s string) (name string, price float64, err error) {
+	m := quickAdd.FindStringSubmatch(s)
+	if m == nil || m[nameIdx] == "" {
+		return "", 0, fmt.Errorf("%w, got %q", ErrQuickAdd, s)
+	}
+	price, err = strconv.ParseFloat(strings.ReplaceAll(m[priceIdx], ",", ""), 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w, got %q: %v", ErrQuickAdd, s, err)
+	}
+	return m[nameIdx], price, nil
+}
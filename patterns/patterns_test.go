@@ -0,0 +1,135 @@
+package patterns
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestValidSKU(t *testing.T) {
+	tests := []struct {
+		sku  string
+		want bool
+	}{
+		{"A1", true},
+		{"ANV-1", true},
+		{"SKU-00042", true},
+		{"A-B-C", true},
+		{strings.Repeat("A", MaxSKULen), true},
+		{strings.Repeat("A", MaxSKULen+1), false},
+		{"", false},
+		{"anv-1", false},
+		{"ANV--1", false},
+		{"-ANV", false},
+		{"ANV-", false},
+		{"ANV 1", false},
+		{"ANV-1\n", false},
+	}
+	for _, tt := range tests {
+		if got := ValidSKU(tt.sku); got != tt.want {
+			t.Errorf("ValidSKU(%q) = %v, want %v", tt.sku, got, tt.want)
+		}
+	}
+}
+
+func TestDeriveSKU(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"Steel Anvil", "STEEL-ANVIL"},
+		{"  nails <100> ", "NAILS-100"},
+		{"Crème brûlée torch", "CR-ME-BR-L-E-TORCH"},
+		{"***", ""},
+		{strings.Repeat("ab ", 20), "AB-AB-AB-AB-AB-AB-AB-AB-AB-AB-AB"},
+	}
+	for _, tt := range tests {
+		got := DeriveSKU(tt.name)
+		if got != tt.want {
+			t.Errorf("DeriveSKU(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if got != "" && !ValidSKU(got) {
+			t.Errorf("DeriveSKU(%q) = %q, which is not a valid SKU", tt.name, got)
+		}
+	}
+}
+
+func TestSanitizeSearch(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"hammer", "hammer"},
+		{"  steel\t\tanvil \n", "steel anvil"},
+		{"ham*", "ham"},
+		{"%nails%", "nails"},
+		{"a*b", "a*b"},
+		{"nails <100>", "nails <100>"},
+		{"drop\x00\x1b[2Jtable", "drop [2Jtable"},
+		{"​zero​width", "zero width"},
+		{strings.Repeat("é", MaxSearchRunes+10), strings.Repeat("é", MaxSearchRunes)},
+		{"", ""},
+		{" * ", ""},
+	}
+	for _, tt := range tests {
+		if got := SanitizeSearch(tt.in); got != tt.want {
+			t.Errorf("SanitizeSearch(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseQuickAdd(t *testing.T) {
+	tests := []struct {
+		in      string
+		name    string
+		price   float64
+		wantErr bool
+	}{
+		{"Steel Anvil:12.50", "Steel Anvil", 12.5, false},
+		{"  Steel Anvil :  $1,249.5 ", "Steel Anvil", 1249.5, false},
+		{"Nails <100>: 3", "Nails <100>", 3, false},
+		{"Gift card: 1000000", "Gift card", 1000000, false},
+		{"Steel Anvil", "", 0, true},
+		{": 12", "", 0, true},
+		{"Anvil: twelve", "", 0, true},
+		{"Anvil: 12.505", "", 0, true},
+		{"Anvil: -3", "", 0, true},
+		{"Anvil: 1,24", "", 0, true},
+		{"Anvil: 1: 2", "", 0, true},
+	}
+	for _, tt := range tests {
+		name, price, err := ParseQuickAdd(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrQuickAdd) {
+				t.Errorf("ParseQuickAdd(%q) err = %v, want ErrQuickAdd", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || name != tt.name || price != tt.price {
+			t.Errorf("ParseQuickAdd(%q) = %q, %v, %v; want %q, %v", tt.in, name, price, err, tt.name, tt.price)
+		}
+	}
+}
+
+// validSKUCompileEachCall is ValidSKU as it would be written with the
+// pattern compiled inside the function.
+func validSKUCompileEachCall(s string) bool {
+	return len(s) <= MaxSKULen && regexp.MustCompile(skuExpr).MatchString(s)
+}
+
+func BenchmarkValidSKU(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		fn   func(string) bool
+	}{
+		{"CompileOnce", ValidSKU},
+		{"CompileEachCall", validSKUCompileEachCall},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			for b.Loop() {
+				bm.fn("SKU-00042")
+			}
+		})
+	}
+}
+
+func BenchmarkParseQuickAdd(b *testing.B) {
+	for b.Loop() {
+		ParseQuickAdd("Steel Anvil: $1,249.50")
+	}
+}
@@ -0,0 +1,36 @@
+package pqueue_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/pqueue"
+)
+
+type reservation struct {
+	ID        string
+	ExpiresAt time.Time
+}
+
+// Reservations ordered by expiry: the janitor only ever needs to look at
+// the head of the queue to know whether anything has expired.
+func Example_reservations() {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	q := pqueue.New(func(a, b reservation) bool { return a.ExpiresAt.Before(b.ExpiresAt) })
+
+	q.Push(reservation{"r-1", now.Add(10 * time.Minute)})
+	r2 := q.Push(reservation{"r-2", now.Add(2 * time.Minute)})
+	q.Push(reservation{"r-3", now.Add(5 * time.Minute)})
+
+	// r-2 is extended, so r-3 now expires first.
+	q.Update(r2, reservation{"r-2", now.Add(30 * time.Minute)})
+
+	for q.Len() > 0 {
+		r, _ := q.Pop()
+		fmt.Println(r.ID, r.ExpiresAt.Sub(now))
+	}
+	// Output:
+	// r-3 5m0s
+	// r-1 10m0s
+	// r-2 30m0s
+}
@@ -0,0 +1,111 @@
+// Package pqueue provides a generic priority queue on top of
+// container/heap.
+package pqueue
+
+import "container/heap"
+
+// Item is a handle to a queued value. Keep it to change the value's
+// priority with Update or to drop it with Remove.
+type Item[T any] struct {
+	Value T
+	index int // position in the heap, -1 once popped or removed
+}
+
+// Queued reports whether the item is still in its queue.
+func (it *Item[T]) Queued() bool { return it.index >= 0 }
+
+// PriorityQueue pops the element that sorts first under less. It is not
+// safe for concurrent use.
+type PriorityQueue[T any] struct {
+	h items[T]
+}
+
+// New returns an empty queue ordered by less. With a less that compares
+// with <, the smallest value is popped first.
+func New[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{h: items[T]{less: less}}
+}
+
+// Push adds v and returns its handle.
+func (q *PriorityQueue[T]) Push(v T) *Item[T] {
+	it := &Item[T]{Value: v}
+	heap.Push(&q.h, it)
+	return it
+}
+
+// Pop removes and returns the first value.
+func (q *PriorityQueue[T]) Pop() (T, bool) {
+	if len(q.h.list) == 0 {
+		var zero T
+		return zero, false
+	}
+	return heap.Pop(&q.h).(*Item[T]).Value, true
+}
+
+// Peek returns the first value without removing it.
+func (q *PriorityQueue[T]) Peek() (T, bool) {
+	if len(q.h.list) == 0 {
+		var zero T
+		return zero, false
+	}
+	return q.h.list[0].Value, true
+}
+
+// Len returns the number of queued values.
+func (q *PriorityQueue[T]) Len() int { return len(q.h.list) }
+
+// Update replaces the item's value and restores heap order. It reports
+// false if the item is no longer queued.
+func (q *PriorityQueue[T]) Update(it *Item[T], v T) bool {
+	if !q.owns(it) {
+		return false
+	}
+	it.Value = v
+	heap.Fix(&q.h, it.index)
+	return true
+}
+
+// Remove deletes the item from the queue. It reports false if the item was
+// already popped or removed.
+func (q *PriorityQueue[T]) Remove(it *Item[T]) bool {
+	if !q.owns(it) {
+		return false
+	}
+	heap.Remove(&q.h, it.index)
+	return true
+}
+
+func (q *PriorityQueue[T]) owns(it *Item[T]) bool {
+	return it.index >= 0 && it.index < len(q.h.list) && q.h.list[it.index] == it
+}
+
+// items implements heap.Interface. It is kept unexported so callers cannot
+// break the invariants through the raw heap methods.
+type items[T any] struct {
+	list []*Item[T]
+	less func(a, b T) bool
+}
+
+func (h items[T]) Len() int           { return len(h.list) }
+func (h items[T]) Less(i, j int) bool { return h.less(h.list[i].Value, h.list[j].Value) }
+
+func (h items[T]) Swap(i, j int) {
+	h.list[i], h.list[j] = h.list[j], h.list[i]
+	h.list[i].index = i
+	h.list[j].index = j
+}
+
+func (h *items[T]) Push(x any) {
+	it := x.(*Item[T])
+	it.index = len(h.list)
+	h.list = append(h.list, it)
+}
+
+func (h *items[T]) Pop() any {
+	n := len(h.list)
+	it := h.list[n-1]
+	h.list[n-1] = nil
+	h.list = h.list[:n-1]
+	it.index = -1
+	return it
+}
@@ -0,0 +1,86 @@
+package pqueue
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func intLess(a, b int) bool { return a < b }
+
+func drain[T any](q *PriorityQueue[T]) []T {
+	var out []T
+	for q.Len() > 0 {
+		v, _ := q.Pop()
+		out = append(out, v)
+	}
+	return out
+}
+
+func TestPopsInOrder(t *testing.T) {
+	q := New(intLess)
+	in := rand.Perm(50)
+	for _, v := range in {
+		q.Push(v)
+	}
+	if v, _ := q.Peek(); v != 0 {
+		t.Fatalf("Peek() = %d, want 0", v)
+	}
+	got := drain(q)
+	if !slices.IsSorted(got) || len(got) != len(in) {
+		t.Fatalf("drained %v", got)
+	}
+	if _, ok := q.Pop(); ok {
+		t.Fatal("Pop() on empty queue reported ok")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	q := New(intLess)
+	q.Push(5)
+	late := q.Push(10)
+	q.Push(7)
+
+	if !q.Update(late, 1) {
+		t.Fatal("Update of queued item failed")
+	}
+	if v, _ := q.Peek(); v != 1 {
+		t.Fatalf("Peek() after raising priority = %d, want 1", v)
+	}
+	q.Update(late, 100)
+	if got := drain(q); !slices.Equal(got, []int{5, 7, 100}) {
+		t.Fatalf("drained %v", got)
+	}
+	if q.Update(late, 0) || late.Queued() {
+		t.Fatal("Update succeeded on a popped item")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	q := New(intLess)
+	a := q.Push(3)
+	b := q.Push(1)
+	q.Push(2)
+
+	if !q.Remove(b) || b.Queued() {
+		t.Fatal("Remove of queued item failed")
+	}
+	if q.Remove(b) {
+		t.Fatal("second Remove succeeded")
+	}
+	if !a.Queued() {
+		t.Fatal("unrelated item lost")
+	}
+	if got := drain(q); !slices.Equal(got, []int{2, 3}) {
+		t.Fatalf("drained %v", got)
+	}
+}
+
+func TestForeignItem(t *testing.T) {
+	q1, q2 := New(intLess), New(intLess)
+	it := q1.Push(1)
+	q2.Push(2)
+	if q2.Remove(it) || q2.Update(it, 0) {
+		t.Fatal("queue accepted an item it does not own")
+	}
+}
@@ -0,0 +1,165 @@
+package product
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/stawuah/pounce-on-go/apperr"
+	"github.com/stawuah/pounce-on-go/maputil"
+)
+
+// DefaultSnapshots is how many snapshots a Snapshotter keeps when told 0.
+const DefaultSnapshots = 16
+
+// Diff is the body of GET /admin/diff: how the catalog changed between
+// snapshot Since and snapshot Snapshot, which is now. Each list is sorted
+// by SKU.
+type Diff struct {
+	Since    string    `json:"since,omitempty"`
+	Snapshot string    `json:"snapshot"` // pass as since next time
+	Added    []Product `json:"added"`
+	Changed  []Product `json:"changed"` // as they are now
+	Removed  []string  `json:"removed"` // SKUs
+}
+
+// Empty reports whether nothing changed.
+func (d Diff) Empty() bool { return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0 }
+
+// Snapshotter keeps recent copies of the whole catalog in memory, each
+// named by an ID, so a client that remembers the ID of the last snapshot
+// it saw can ask for only what changed since. Only the most recent few
+// are kept; a client whose snapshot has gone must start again from
+// nothing.
+//
+// IDs carry a prefix random to each Snapshotter, so an ID from before a
+// restart is gone rather than mistaken for a new snapshot.
+type Snapshotter struct {
+	svc    *Service
+	keep   int
+	prefix string
+
+	mu    sync.Mutex
+	seq   int
+	ids   []string // oldest first
+	snaps map[string]map[string]Product
+}
+
+// NewSnapshotter returns a Snapshotter of svc's catalog that keeps the
+// keep most recent snapshots, or DefaultSnapshots if keep is 0.
+func NewSnapshotter(svc *Service, keep int) *Snapshotter {
+	if keep <= 0 {
+		keep = DefaultSnapshots
+	}
+	b := make([]byte, 4)
+	rand.Read(b)
+	return &Snapshotter{svc: svc, keep: keep, prefix: hex.EncodeToString(b), snaps: make(map[string]map[string]Product)}
+}
+
+// Take snapshots the catalog and returns the snapshot's ID. If nothing
+// has changed since the last snapshot, that one's ID is returned instead,
+// so clients polling a quiet catalog do not use up the snapshots kept.
+func (s *Snapshotter) Take(ctx context.Context) (string, error) {
+	cur, err := s.current(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.take(cur), nil
+}
+
+// Diff returns what changed between snapshot since and the catalog now,
+// which it snapshots as Take does: only if the catalog has changed since
+// the newest snapshot, so clients polling with different since values do
+// not push each other's snapshots out. An empty since stands for an empty
+// catalog, so every product comes back as added. A since that is no
+// longer kept, or never was, is apperr.ErrGone.
+func (s *Snapshotter) Diff(ctx context.Context, since string) (Diff, error) {
+	cur, err := s.current(ctx)
+	if err != nil {
+		return Diff{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var base map[string]Product
+	if since != "" {
+		var ok bool
+		if base, ok = s.snaps[since]; !ok {
+			return Diff{}, fmt.Errorf("%w: snapshot %q is not kept; diff from the start", apperr.ErrGone, since)
+		}
+	}
+	d := Diff{Since: since, Snapshot: s.take(cur), Added: []Product{}, Changed: []Product{}, Removed: []string{}}
+	c := maputil.Diff(base, cur)
+	for _, sku := range c.Added {
+		d.Added = append(d.Added, cur[sku])
+	}
+	for _, sku := range c.Changed {
+		d.Changed = append(d.Changed, cur[sku])
+	}
+	d.Removed = append(d.Removed, c.Removed...)
+	return d, nil
+}
+
+func (s *Snapshotter) current(ctx context.Context) (map[string]Product, error) {
+	ps, err := s.svc.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]Product, len(ps))
+	for _, p := range ps {
+		m[p.SKU] = p
+	}
+	return m, nil
+}
+
+// take stores cur as a snapshot, unless it matches the latest, and
+// returns its ID. s.mu must be held.
+func (s *Snapshotter) take(cur map[string]Product) string {
+	if n := len(s.ids); n > 0 {
+		latest := s.ids[n-1]
+		if maputil.Diff(s.snaps[latest], cur).Empty() {
+			return latest
+		}
+	}
+	s.seq++
+	id := fmt.Sprintf("%s-%d", s.prefix, s.seq)
+	s.snaps[id] = cur
+	s.ids = append(s.ids, id)
+	if len(s.ids) > s.keep {
+		delete(s.snaps, s.ids[0])
+		s.ids = s.ids[1:]
+	}
+	return id
+}
+
+// DiffHandler serves POST /admin/snapshots, which takes a snapshot and
+// answers {"snapshot": id}, and GET /admin/diff?since=id, which answers a
+// Diff, or 410 Gone if the snapshot is no longer kept.
+func DiffHandler(s *Snapshotter) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /admin/snapshots", func(w http.ResponseWriter, r *http.Request) {
+		id, err := s.Take(r.Context())
+		if err != nil {
+			apperr.WriteError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"snapshot": id})
+	})
+	mux.HandleFunc("GET /admin/diff", func(w http.ResponseWriter, r *http.Request) {
+		d, err := s.Diff(r.Context(), r.URL.Query().Get("since"))
+		if err != nil {
+			apperr.WriteError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(d)
+	})
+	return mux
+}
@@ -0,0 +1,124 @@
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func skus(ps []Product) []string {
+	out := make([]string, len(ps))
+	for i, p := range ps {
+		out[i] = p.SKU
+	}
+	return out
+}
+
+func TestSnapshotterDiff(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository()
+	svc := &Service{Repo: repo}
+	for _, p := range []Product{{SKU: "A", Name: "Anvil", Price: 40}, {SKU: "B", Name: "Bolt", Price: 1}, {SKU: "C", Name: "Chisel", Price: 18}} {
+		svc.Create(ctx, p)
+	}
+	s := NewSnapshotter(svc, 0)
+
+	first, err := s.Diff(ctx, "")
+	if err != nil || !slices.Equal(skus(first.Added), []string{"A", "B", "C"}) || first.Snapshot == "" {
+		t.Fatalf("full diff = %+v, %v", first, err)
+	}
+
+	svc.Create(ctx, Product{SKU: "C", Name: "Chisel", Price: 20})
+	svc.Create(ctx, Product{SKU: "D", Name: "Drill", Price: 120})
+	repo.items.Delete("B") // nothing removes products yet; the diff must still say so
+	d, err := s.Diff(ctx, first.Snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Since != first.Snapshot || d.Snapshot == first.Snapshot ||
+		!slices.Equal(skus(d.Added), []string{"D"}) || !slices.Equal(skus(d.Changed), []string{"C"}) ||
+		d.Changed[0].Price != 20 || !slices.Equal(d.Removed, []string{"B"}) {
+		t.Fatalf("diff = %+v", d)
+	}
+
+	// A quiet catalog neither changes nor uses up snapshots.
+	quiet, err := s.Diff(ctx, d.Snapshot)
+	if err != nil || !quiet.Empty() || quiet.Snapshot != d.Snapshot {
+		t.Fatalf("quiet diff = %+v, %v", quiet, err)
+	}
+	if id, _ := s.Take(ctx); id != d.Snapshot {
+		t.Fatalf("Take on a quiet catalog = %q, want %q", id, d.Snapshot)
+	}
+}
+
+// Two pollers with different since values take turns many more times
+// than there are snapshots kept. Only catalog changes take snapshots, so
+// neither loses its own.
+func TestSnapshotterAlternatingPollers(t *testing.T) {
+	ctx := context.Background()
+	svc := &Service{Repo: NewRepository()}
+	svc.Create(ctx, Product{SKU: "A", Name: "Anvil", Price: 40})
+	s := NewSnapshotter(svc, 0)
+
+	since := map[string]string{"a": "", "b": ""}
+	ids := map[string]bool{}
+	changes := 0
+	for round := range 3 * DefaultSnapshots {
+		if round%4 == 0 {
+			changes++
+			svc.Create(ctx, Product{SKU: "A", Name: "Anvil", Price: float64(40 + changes)})
+		}
+		for _, poller := range []string{"a", "b"} {
+			d, err := s.Diff(ctx, since[poller])
+			if err != nil {
+				t.Fatalf("round %d: poller %s: %v", round, poller, err)
+			}
+			since[poller] = d.Snapshot
+			ids[d.Snapshot] = true
+		}
+	}
+	if len(ids) != changes {
+		t.Fatalf("%d snapshots taken for %d changes", len(ids), changes)
+	}
+}
+
+func TestDiffHandler(t *testing.T) {
+	ctx := context.Background()
+	svc := &Service{Repo: NewRepository()}
+	s := NewSnapshotter(svc, 2)
+	h := DiffHandler(s)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/snapshots", nil))
+	var taken struct{ Snapshot string }
+	if json.Unmarshal(rec.Body.Bytes(), &taken); rec.Code != 201 || taken.Snapshot == "" {
+		t.Fatalf("POST /admin/snapshots = %d %s", rec.Code, rec.Body)
+	}
+
+	// Two more snapshots push the first out.
+	for _, sku := range []string{"A", "B"} {
+		svc.Create(ctx, Product{SKU: sku, Name: "Widget"})
+		s.Take(ctx)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/diff?since="+taken.Snapshot, nil))
+	if rec.Code != 410 {
+		t.Fatalf("diff since an evicted snapshot = %d %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/diff", nil))
+	var d Diff
+	if err := json.Unmarshal(rec.Body.Bytes(), &d); err != nil || rec.Code != 200 || len(d.Added) != 2 || d.Removed == nil {
+		t.Fatalf("GET /admin/diff = %d %s", rec.Code, rec.Body)
+	}
+
+	// A snapshot from another Snapshotter, as after a restart, is gone too.
+	rec = httptest.NewRecorder()
+	DiffHandler(NewSnapshotter(svc, 2)).ServeHTTP(rec, httptest.NewRequest("GET", "/admin/diff?since="+d.Snapshot, nil))
+	if rec.Code != 410 {
+		t.Fatalf("diff since another server's snapshot = %d", rec.Code)
+	}
+}
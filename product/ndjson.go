@@ -0,0 +1,135 @@
+package product
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/stawuah/pounce-on-go/apperr"
+	"github.com/stawuah/pounce-on-go/i18n"
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/lru"
+)
+
+// NDJSON is newline-delimited JSON, one product per line.
+const NDJSON = "application/x-ndjson"
+
+// flushEvery is how many exported lines are buffered between flushes, so
+// a client sees steady progress without a syscall per product.
+const flushEvery = 100
+
+// maxImportFailures caps the per-record failures an import reports; the
+// count in ImportResult.Failed covers all of them.
+const maxImportFailures = 100
+
+// ImportResult is the body of a POST /products/import response.
+type ImportResult struct {
+	Imported int             `json:"imported"`
+	Failed   int             `json:"failed"`
+	Failures []ImportFailure `json:"failures,omitempty"`
+	Error    string          `json:"error,omitempty"` // why the import stopped early
+}
+
+// ImportFailure is one record that could not be stored. Record counts
+// from 1 in input order.
+type ImportFailure struct {
+	Record   int               `json:"record"`
+	SKU      string            `json:"sku,omitempty"`
+	Error    string            `json:"error"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Messages map[string]string `json:"messages,omitempty"` // as in error responses
+}
+
+// exportNDJSON serves GET /products/export.ndjson, writing each product
+// as it is encoded rather than building the whole body first.
+func exportNDJSON(svc *Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ps, err := svc.List(r.Context())
+		if err != nil {
+			apperr.WriteError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", NDJSON)
+		rc := http.NewResponseController(w)
+		enc := json.NewEncoder(w)
+		for i, p := range ps {
+			if r.Context().Err() != nil {
+				return
+			}
+			if err := enc.Encode(p); err != nil {
+				return
+			}
+			if (i+1)%flushEvery == 0 {
+				rc.Flush()
+			}
+		}
+		rc.Flush()
+	}
+}
+
+// importNDJSON serves POST /products/import. The body is decoded one
+// product at a time, so its size is bounded only by the repository.
+// Invalid products are reported and skipped; malformed JSON stops the
+// import, since the stream cannot be resynchronised, and answers 400
+// with what was stored before it.
+func importNDJSON(svc *Service, cache *lru.Cache[string, []byte]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var res ImportResult
+		status := http.StatusOK
+		l := apperr.Messages.Negotiate(r.Header.Get("Accept-Language"))
+		dec := json.NewDecoder(r.Body)
+		for record := 1; ; record++ {
+			var p Product
+			err := dec.Decode(&p)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err == nil {
+				err = svc.Create(r.Context(), p)
+			} else if !errors.Is(err, jsonx.ErrInvalid) {
+				status, res.Error = http.StatusBadRequest, l.T("import.malformed_json", "record", record)
+				break
+			}
+			// A field that failed its own decoding, such as an unknown
+			// status, leaves the decoder in step; skip it like any other
+			// invalid product.
+			if err != nil {
+				if apperr.StatusOf(err) != http.StatusUnprocessableEntity {
+					apperr.WriteError(w, r, err)
+					return
+				}
+				res.Failed++
+				if len(res.Failures) < maxImportFailures {
+					res.Failures = append(res.Failures, importFailure(l, record, p.SKU, err))
+				}
+				continue
+			}
+			if cache != nil {
+				cache.Remove(p.SKU)
+			}
+			res.Imported++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Language", l.Locale())
+		w.Header().Add("Vary", "Accept-Language")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+func importFailure(l i18n.Localizer, record int, sku string, err error) ImportFailure {
+	f := ImportFailure{Record: record, SKU: sku, Error: l.T("import.invalid_product")}
+	if errors.Is(err, jsonx.ErrInvalid) {
+		f.Error = err.Error()
+	}
+	if fields := apperr.Fields(err); len(fields) > 0 {
+		f.Fields = make(map[string]string, len(fields))
+		f.Messages = make(map[string]string, len(fields))
+		for _, v := range fields {
+			f.Fields[v.Field] = v.Rule
+			f.Messages[v.Field] = v.Message(l)
+		}
+	}
+	return f
+}
@@ -0,0 +1,134 @@
+package product
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportNDJSON(t *testing.T) {
+	svc := &Service{Repo: NewRepository()}
+	ctx := context.Background()
+	for i := range 250 {
+		if err := svc.Create(ctx, Product{SKU: fmt.Sprintf("P%03d", i), Name: "Widget", Price: float64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	Handler(svc).ServeHTTP(rec, httptest.NewRequest("GET", "/products/export.ndjson", nil))
+	if rec.Code != 200 || rec.Header().Get("Content-Type") != NDJSON {
+		t.Fatalf("status = %d, Content-Type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !rec.Flushed {
+		t.Fatal("export was never flushed")
+	}
+	sc := bufio.NewScanner(rec.Body)
+	n := 0
+	for sc.Scan() {
+		var p Product
+		if err := json.Unmarshal(sc.Bytes(), &p); err != nil {
+			t.Fatalf("line %d: %v", n+1, err)
+		}
+		if want := fmt.Sprintf("P%03d", n); p.SKU != want {
+			t.Fatalf("line %d: SKU = %q, want %q", n+1, p.SKU, want)
+		}
+		n++
+	}
+	if n != 250 {
+		t.Fatalf("exported %d lines, want 250", n)
+	}
+}
+
+func TestImportNDJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		want       ImportResult
+	}{
+		{
+			"all valid",
+			`{"sku":"A1","name":"Anvil","price":9}` + "\n" + `{"sku":"B2","name":"Bolt","price":1}` + "\n",
+			200, ImportResult{Imported: 2},
+		},
+		{
+			"invalid skipped",
+			`{"sku":"A1","name":"Anvil","price":9}` + "\n" + `{"sku":"B2","price":-1}` + "\n" + `{"sku":"C3","name":"Clamp","price":4}`,
+			200, ImportResult{Imported: 2, Failed: 1, Failures: []ImportFailure{
+				{
+					Record: 2, SKU: "B2", Error: "invalid product",
+					Fields:   map[string]string{"name": "required", "price": "min=0"},
+					Messages: map[string]string{"name": "name is required", "price": "price must be at least 0"},
+				},
+			}},
+		},
+		{
+			"malformed stops",
+			`{"sku":"A1","name":"Anvil","price":9}` + "\n" + `{"sku":` + "\n" + `{"sku":"C3","name":"Clamp","price":4}`,
+			400, ImportResult{Imported: 1, Error: "record 2: malformed JSON"},
+		},
+		{
+			"undecodable field skipped",
+			`{"sku":"A1","name":"Anvil","price":9,"status":"retired"}` + "\n" + `{"sku":"B2","name":"Bolt","price":1,"status":"active"}`,
+			200, ImportResult{Imported: 1, Failed: 1, Failures: []ImportFailure{
+				{Record: 1, SKU: "A1", Error: `jsonx: invalid value: status "retired": want one of draft, active, discontinued`},
+			}},
+		},
+		{"empty", "", 200, ImportResult{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Handler(&Service{Repo: NewRepository()}).ServeHTTP(rec, httptest.NewRequest("POST", "/products/import", strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			var got ImportResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if g, w := fmt.Sprint(got), fmt.Sprint(tt.want); g != w {
+				t.Fatalf("result = %s, want %s", g, w)
+			}
+		})
+	}
+}
+
+// Each record is stored as soon as it is decoded, before the rest of the
+// body has even been written.
+func TestImportNDJSONIsIncremental(t *testing.T) {
+	repo := NewRepository()
+	pr, pw := io.Pipe()
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		rec := httptest.NewRecorder()
+		Handler(&Service{Repo: repo}).ServeHTTP(rec, httptest.NewRequest("POST", "/products/import", pr))
+		done <- rec
+	}()
+
+	io.WriteString(pw, `{"sku":"A1","name":"Anvil","price":9}`+"\n")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := repo.Get(context.Background(), "A1"); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("first record not stored while the body was still open")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	io.WriteString(pw, `{"sku":"B2","name":"Bolt","price":1}`+"\n")
+	pw.Close()
+
+	rec := <-done
+	if rec.Code != 200 || !strings.Contains(rec.Body.String(), `"imported":2`) {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+}
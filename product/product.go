@@ -0,0 +1,688 @@
+// Package product is the catalog behind pounce serve: the Product
+// entity, its Repository, the Service that holds the business rules, and
+// Handler, the HTTP API over them.
+//
+// The layers report failures as package apperr describes. The Repository
+// returns apperr's typed errors, the Service wraps them with what it was
+// doing, and the handlers turn them into responses with apperr.WriteError.
+//
+// Besides the JSON API, the package streams the catalog out and in as
+// NDJSON and out as protocol buffers (see Proto), saves it in versioned
+// snapshot files (see Snapshots), and serves incremental changes between
+// in-memory snapshots for clients that keep a copy (see Snapshotter).
+package product
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/stawuah/pounce-on-go/apperr"
+	"github.com/stawuah/pounce-on-go/clock"
+	"github.com/stawuah/pounce-on-go/digest"
+	"github.com/stawuah/pounce-on-go/eventbus"
+	"github.com/stawuah/pounce-on-go/flags"
+	"github.com/stawuah/pounce-on-go/graph"
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/logging"
+	"github.com/stawuah/pounce-on-go/lru"
+	"github.com/stawuah/pounce-on-go/patterns"
+	"github.com/stawuah/pounce-on-go/query"
+	"github.com/stawuah/pounce-on-go/repository"
+	"github.com/stawuah/pounce-on-go/set"
+	"github.com/stawuah/pounce-on-go/skiplist"
+	"github.com/stawuah/pounce-on-go/tracing"
+	"github.com/stawuah/pounce-on-go/tree"
+	"github.com/stawuah/pounce-on-go/trie"
+)
+
+// Product is an item in the catalog.
+type Product struct {
+	SKU      string              `json:"sku"`
+	Name     string              `json:"name"`
+	Category string              `json:"category,omitempty"`
+	Price    float64             `json:"price"`
+	Status   jsonx.ProductStatus `json:"status,omitempty"`
+	Released jsonx.Date          `json:"released,omitzero"`
+}
+
+// Validate checks every field and joins all failures, so a client sees
+// every problem at once rather than one per request.
+func (p Product) Validate() error {
+	var errs []error
+	if p.SKU == "" {
+		errs = append(errs, &apperr.ValidationError{Field: "sku", Value: p.SKU, Rule: "required"})
+	}
+	if strings.TrimSpace(p.Name) == "" {
+		errs = append(errs, &apperr.ValidationError{Field: "name", Value: p.Name, Rule: "required"})
+	}
+	if p.Price < 0 {
+		errs = append(errs, &apperr.ValidationError{Field: "price", Value: p.Price, Rule: "min=0"})
+	}
+	// Snapshots store prices as jsonx.Money, so a price finer than a cent
+	// would not survive a save and load.
+	if c := p.Price * 100; math.Abs(c-math.Round(c)) > 1e-6 {
+		errs = append(errs, &apperr.ValidationError{Field: "price", Value: p.Price, Rule: "cents"})
+	}
+	if p.Status != 0 && !p.Status.Valid() {
+		errs = append(errs, &apperr.ValidationError{Field: "status", Value: p.Status, Rule: "oneof=draft active discontinued"})
+	}
+	return errors.Join(errs...)
+}
+
+// Query is how GET /products and GET /products/{sku}/related filter,
+// sort and page products (see package query). Without a limit they list
+// everything, which existing clients rely on.
+var Query = &query.Schema[Product]{
+	Fields: []query.Field[Product]{
+		query.String("sku", func(p Product) string { return p.SKU }),
+		query.String("name", func(p Product) string { return p.Name }),
+		query.String("category", func(p Product) string { return p.Category }),
+		query.Float("price", func(p Product) float64 { return p.Price }),
+		query.String("status", func(p Product) string {
+			if p.Status == 0 {
+				return ""
+			}
+			return p.Status.String()
+		}).OneOf("draft", "active", "discontinued"),
+	},
+}
+
+// Repository is the storage layer. It returns typed errors and no
+// context of its own beyond what the type carries. It is safe for
+// concurrent use.
+//
+// The products themselves live in a repository.Repository, in memory or
+// in a file; the indexes and relations are kept in memory only and
+// rebuilt when the repository is opened.
+type Repository struct {
+	mu      sync.RWMutex
+	items   *repository.Repository[string, Product]
+	byPrice priceIndex                 // secondary index over items
+	byName  trie.Trie[set.Set[string]] // lower-cased name to SKUs
+	related graph.Graph[string]        // SKU -> SKUs shown alongside it
+	// Fail, if set, is returned by every call, standing in for an outage.
+	Fail error
+}
+
+// priceKey orders products by price, then SKU so equal prices stay
+// distinct and deterministic.
+type priceKey struct {
+	price float64
+	sku   string
+}
+
+func comparePriceKeys(a, b priceKey) int {
+	return cmp.Or(cmp.Compare(a.price, b.price), cmp.Compare(a.sku, b.sku))
+}
+
+// priceIndex is the ordered structure behind ByPrice. tree.AVL satisfies
+// it directly and skiplist.List through skipIndex.
+type priceIndex interface {
+	Insert(priceKey, struct{}) bool
+	Delete(priceKey) bool
+	Ascend(from priceKey) iter.Seq2[priceKey, struct{}]
+}
+
+type skipIndex struct {
+	*skiplist.List[priceKey, struct{}]
+}
+
+func (s skipIndex) Ascend(from priceKey) iter.Seq2[priceKey, struct{}] { return s.Seek(from) }
+
+// RepositoryOption configures a Repository.
+type RepositoryOption func(*Repository)
+
+// WithSkipListIndex backs the price index with a skip list instead of the
+// default AVL tree. Both give the same results; the benchmarks package
+// compares their cost.
+func WithSkipListIndex() RepositoryOption {
+	return func(r *Repository) {
+		r.byPrice = skipIndex{skiplist.NewFunc[priceKey, struct{}](comparePriceKeys)}
+	}
+}
+
+// NewRepository returns an empty repository.
+func NewRepository(opts ...RepositoryOption) *Repository {
+	r := &Repository{
+		items:   repository.New(productSKU, productOrder),
+		byPrice: tree.NewAVLFunc[priceKey, struct{}](comparePriceKeys),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// OpenRepository returns a repository kept in the file at path, one
+// product per line, loading the products it already holds. Relations are
+// not kept.
+func OpenRepository(path string, opts ...RepositoryOption) (*Repository, error) {
+	items, err := repository.Open(path, productSKU, productOrder)
+	if err != nil {
+		return nil, err
+	}
+	r := NewRepository(opts...)
+	r.items = items
+	for _, p := range items.All() {
+		r.byPrice.Insert(priceKey{p.Price, p.SKU}, struct{}{})
+		r.indexName(p)
+	}
+	return r, nil
+}
+
+func productSKU(p Product) string { return p.SKU }
+
+var productOrder = repository.WithOrder[string](func(a, b Product) int { return strings.Compare(a.SKU, b.SKU) })
+
+// Get returns the product with the given SKU.
+func (r *Repository) Get(ctx context.Context, sku string) (Product, error) {
+	_, span := tracing.Start(ctx, "repo.Get")
+	defer span.Finish(nil)
+	if r.Fail != nil {
+		return Product{}, r.Fail
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.items.Get(sku)
+	if !ok {
+		return Product{}, &apperr.NotFoundError{Kind: "product", Key: sku}
+	}
+	return p, nil
+}
+
+// Put stores p.
+func (r *Repository) Put(ctx context.Context, p Product) error {
+	_, span := tracing.Start(ctx, "repo.Put")
+	defer span.Finish(nil)
+	if r.Fail != nil {
+		return r.Fail
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, existed := r.items.Get(p.SKU)
+	if err := r.items.Put(p); err != nil {
+		return err
+	}
+	if existed {
+		r.byPrice.Delete(priceKey{old.Price, old.SKU})
+		r.unindexName(old)
+	}
+	r.byPrice.Insert(priceKey{p.Price, p.SKU}, struct{}{})
+	r.indexName(p)
+	return nil
+}
+
+// product returns the product with sku, which an index has just named.
+func (r *Repository) product(sku string) Product {
+	p, _ := r.items.Get(sku)
+	return p
+}
+
+func (r *Repository) indexName(p Product) {
+	key := strings.ToLower(p.Name)
+	skus, ok := r.byName.Get(key)
+	if !ok {
+		skus = set.New[string]()
+		r.byName.Insert(key, skus)
+	}
+	skus.Add(p.SKU)
+}
+
+func (r *Repository) unindexName(p Product) {
+	key := strings.ToLower(p.Name)
+	if skus, ok := r.byName.Get(key); ok {
+		skus.Remove(p.SKU)
+		if skus.Len() == 0 {
+			r.byName.Delete(key)
+		}
+	}
+}
+
+// List returns every product, ordered by SKU.
+func (r *Repository) List(ctx context.Context) ([]Product, error) {
+	_, span := tracing.Start(ctx, "repo.List")
+	defer span.Finish(nil)
+	if r.Fail != nil {
+		return nil, r.Fail
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.items.All(), nil
+}
+
+// Suggest returns up to limit products whose name starts with prefix,
+// ignoring case, ordered by name and then SKU.
+func (r *Repository) Suggest(ctx context.Context, prefix string, limit int) ([]Product, error) {
+	_, span := tracing.Start(ctx, "repo.Suggest")
+	defer span.Finish(nil)
+	if r.Fail != nil {
+		return nil, r.Fail
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []Product
+	for _, skus := range r.byName.PrefixSearch(strings.ToLower(prefix)) {
+		for _, sku := range slices.Sorted(skus.All()) {
+			if len(out) == limit {
+				return out, nil
+			}
+			out = append(out, r.product(sku))
+		}
+	}
+	return out, nil
+}
+
+// ByPrice returns the products priced in [lo, hi], cheapest first. It
+// walks only the matching range of the price index.
+func (r *Repository) ByPrice(ctx context.Context, lo, hi float64) ([]Product, error) {
+	_, span := tracing.Start(ctx, "repo.ByPrice")
+	defer span.Finish(nil)
+	if r.Fail != nil {
+		return nil, r.Fail
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []Product
+	for k := range r.byPrice.Ascend(priceKey{price: lo}) {
+		if k.price > hi {
+			break
+		}
+		out = append(out, r.product(k.sku))
+	}
+	return out, nil
+}
+
+// Query returns the page of products q selects and how many match in
+// all. A bound on price narrows the scan to that range of the price
+// index; q's other conditions filter what it yields.
+func (r *Repository) Query(ctx context.Context, q query.Query) ([]Product, int, error) {
+	ctx, span := tracing.Start(ctx, "repo.Query")
+	defer span.Finish(nil)
+	var ps []Product
+	var err error
+	if lo, hi, ok := q.FloatRange("price"); ok {
+		ps, err = r.ByPrice(ctx, lo, hi)
+		if len(q.Sort) == 0 {
+			q.Sort = []query.Order{{Field: "sku"}} // as List orders them
+		}
+	} else {
+		ps, err = r.List(ctx)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return Query.Apply(ps, q)
+}
+
+// Relate records that to should be suggested alongside from. The relation
+// is one-way; call it twice for a symmetric one.
+func (r *Repository) Relate(ctx context.Context, from, to string) error {
+	_, span := tracing.Start(ctx, "repo.Relate")
+	defer span.Finish(nil)
+	if r.Fail != nil {
+		return r.Fail
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sku := range []string{from, to} {
+		if _, ok := r.items.Get(sku); !ok {
+			return &apperr.NotFoundError{Kind: "product", Key: sku}
+		}
+	}
+	r.related.AddEdge(from, to)
+	return nil
+}
+
+// Related returns the products reachable from sku through at most depth
+// relations, nearest first. sku itself is not included.
+func (r *Repository) Related(ctx context.Context, sku string, depth int) ([]Product, error) {
+	_, span := tracing.Start(ctx, "repo.Related")
+	defer span.Finish(nil)
+	if r.Fail != nil {
+		return nil, r.Fail
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, ok := r.items.Get(sku); !ok {
+		return nil, &apperr.NotFoundError{Kind: "product", Key: sku}
+	}
+	var out []Product
+	for n, d := range r.related.BFS(sku) {
+		if d > depth {
+			break
+		}
+		if d > 0 {
+			out = append(out, r.product(n))
+		}
+	}
+	return out, nil
+}
+
+// Service holds business rules and wraps repository errors with what it
+// was trying to do.
+type Service struct {
+	Repo *Repository
+	// Events, if set, receives a jsonx.Event for each change Create
+	// makes. Publishing is best effort: a closed topic or a subscriber
+	// that cannot keep up never fails the write.
+	Events *eventbus.Topic[jsonx.Event]
+	// Clock stamps events; nil means the system clock.
+	Clock clock.Clock
+	// Flags, if set, switches features of the HTTP layer, named by the
+	// Flag constants, on and off. Without it every feature is on.
+	Flags *flags.Set
+}
+
+// Flags the HTTP layer consults.
+const (
+	// FlagSearch gates GET /products/suggest.
+	FlagSearch = "search"
+)
+
+// gate serves h only while the feature is on for the caller; see
+// flags.Set.Gate.
+func (s *Service) gate(feature string, h http.HandlerFunc) http.Handler {
+	if s.Flags == nil {
+		return h
+	}
+	return s.Flags.Gate(feature, h)
+}
+
+// Get fetches a product.
+func (s *Service) Get(ctx context.Context, sku string) (Product, error) {
+	ctx, span := tracing.Start(ctx, "service.Get")
+	defer span.Finish(nil)
+	span.SetAttr("sku", sku)
+	p, err := s.Repo.Get(ctx, sku)
+	if err != nil {
+		err = fmt.Errorf("service: get product: %w", err)
+		span.Finish(err)
+		return Product{}, err
+	}
+	return p, nil
+}
+
+// List returns the whole catalog, ordered by SKU.
+func (s *Service) List(ctx context.Context) ([]Product, error) {
+	ctx, span := tracing.Start(ctx, "service.List")
+	defer span.Finish(nil)
+	ps, err := s.Repo.List(ctx)
+	if err != nil {
+		err = fmt.Errorf("service: list products: %w", err)
+		span.Finish(err)
+		return nil, err
+	}
+	return ps, nil
+}
+
+// Query returns the page of products q selects, ordered by SKU unless q
+// sorts them, and how many match in all.
+func (s *Service) Query(ctx context.Context, q query.Query) ([]Product, int, error) {
+	ctx, span := tracing.Start(ctx, "service.Query")
+	defer span.Finish(nil)
+	ps, total, err := s.Repo.Query(ctx, q)
+	if err != nil {
+		err = fmt.Errorf("service: query products: %w", err)
+		span.Finish(err)
+		return nil, 0, err
+	}
+	return ps, total, nil
+}
+
+// Suggest returns up to limit products whose name starts with prefix, for
+// search-as-you-type. An empty prefix returns nothing rather than the
+// whole catalog.
+func (s *Service) Suggest(ctx context.Context, prefix string, limit int) ([]Product, error) {
+	if strings.TrimSpace(prefix) == "" {
+		return nil, nil
+	}
+	ctx, span := tracing.Start(ctx, "service.Suggest")
+	defer span.Finish(nil)
+	ps, err := s.Repo.Suggest(ctx, prefix, limit)
+	if err != nil {
+		err = fmt.Errorf("service: suggest %q: %w", prefix, err)
+		span.Finish(err)
+		return nil, err
+	}
+	return ps, nil
+}
+
+// relatedDepth is how many relations away a related product may be.
+const relatedDepth = 2
+
+// Related returns the products related to sku directly or through one
+// intermediate product.
+func (s *Service) Related(ctx context.Context, sku string) ([]Product, error) {
+	ctx, span := tracing.Start(ctx, "service.Related")
+	defer span.Finish(nil)
+	span.SetAttr("sku", sku)
+	ps, err := s.Repo.Related(ctx, sku, relatedDepth)
+	if err != nil {
+		err = fmt.Errorf("service: related to %q: %w", sku, err)
+		span.Finish(err)
+		return nil, err
+	}
+	return ps, nil
+}
+
+// Create validates and stores a product.
+func (s *Service) Create(ctx context.Context, p Product) error {
+	ctx, span := tracing.Start(ctx, "service.Create")
+	defer span.Finish(nil)
+	span.SetAttr("sku", p.SKU)
+	if err := p.Validate(); err != nil {
+		err = fmt.Errorf("service: create product: %w", err)
+		span.Finish(err)
+		return err
+	}
+	var old Product
+	existed := false
+	if s.Events != nil {
+		var err error
+		old, err = s.Repo.Get(ctx, p.SKU)
+		existed = err == nil
+	}
+	if err := s.Repo.Put(ctx, p); err != nil {
+		err = fmt.Errorf("service: create product %q: %w", p.SKU, err)
+		span.Finish(err)
+		return err
+	}
+	if s.Events != nil {
+		s.publish(ctx, old, existed, p)
+	}
+	return nil
+}
+
+// publish announces what storing p changed: a new product, or a new
+// price or status for an existing one, and a ProductUpdated for any other
+// change to it.
+func (s *Service) publish(ctx context.Context, old Product, existed bool, p Product) {
+	var c clock.Clock = clock.Real{}
+	if s.Clock != nil {
+		c = s.Clock
+	}
+	at := c.Now()
+	var payloads []jsonx.Payload
+	switch {
+	case !existed:
+		payloads = append(payloads, jsonx.ProductCreated{SKU: p.SKU, Name: p.Name, Price: p.Price, Status: p.Status})
+	default:
+		if old.Price != p.Price {
+			payloads = append(payloads, jsonx.PriceChanged{SKU: p.SKU, Old: old.Price, New: p.Price})
+		}
+		if old.Status != p.Status {
+			payloads = append(payloads, jsonx.StatusChanged{SKU: p.SKU, From: old.Status, To: p.Status})
+		}
+		// Anything else that differs gets the generic event.
+		rest := old
+		rest.Price, rest.Status = p.Price, p.Status
+		if rest != p {
+			payloads = append(payloads, jsonx.ProductUpdated{SKU: p.SKU})
+		}
+	}
+	ctx, span := tracing.Start(ctx, "events.publish")
+	defer span.Finish(nil)
+	span.SetAttr("count", strconv.Itoa(len(payloads)))
+	for _, pl := range payloads {
+		if err := s.Events.Publish(ctx, jsonx.Event{At: at, Payload: pl, Trace: span.Traceparent()}); err != nil {
+			logging.FromContext(ctx).Warn("event dropped", "type", pl.EventType(), "sku", p.SKU, "err", err)
+		}
+	}
+}
+
+// writeProduct sends an encoded product with a strong ETag, the SHA-256
+// of the body, and answers 304 Not Modified when the client already
+// holds that version.
+func writeProduct(w http.ResponseWriter, r *http.Request, body []byte) {
+	etag := `"` + digest.Sum(body).Hex() + `"`
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// etagMatches reports whether an If-None-Match list names etag. The
+// comparison is weak, as RFC 9110 asks for If-None-Match.
+func etagMatches(header, etag string) bool {
+	for tag := range strings.SplitSeq(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestLimit caps the results of GET /products/suggest.
+const suggestLimit = 10
+
+// Handler is the HTTP layer: GET /products, GET /products/{sku}, GET
+// /products/{sku}/related, GET /products/suggest?q=prefix and POST
+// /products, plus GET /products/export.ndjson and POST /products/import
+// for streaming the whole catalog out and in as NDJSON, and GET
+// /products/export.pb with its schema at GET /products/schema.proto for
+// streaming it out as protocol buffers. The two lists take Query's
+// parameters. Suggest answers 404 while svc.Flags has
+// FlagSearch off for the caller.
+func Handler(svc *Service) http.Handler {
+	return CachingHandler(svc, nil)
+}
+
+// CachingHandler is Handler with GET /products/{sku} responses cached in
+// cache, keyed by SKU. Only successful responses are cached, and a POST
+// for a SKU drops its entry. A nil cache disables caching.
+func CachingHandler(svc *Service, cache *lru.Cache[string, []byte]) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /products", func(w http.ResponseWriter, r *http.Request) {
+		q, err := Query.Parse(r.URL.Query())
+		if err != nil {
+			apperr.WriteError(w, r, err)
+			return
+		}
+		ps, total, err := svc.Query(r.Context(), q)
+		if err != nil {
+			apperr.WriteError(w, r, err)
+			return
+		}
+		query.SetHeaders(w, r, q, total)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ps)
+	})
+	mux.HandleFunc("GET /products/{sku}", func(w http.ResponseWriter, r *http.Request) {
+		sku := r.PathValue("sku")
+		if cache != nil {
+			if body, ok := cache.Get(sku); ok {
+				w.Header().Set("X-Cache", "hit")
+				writeProduct(w, r, body)
+				return
+			}
+		}
+		p, err := svc.Get(r.Context(), sku)
+		if err != nil {
+			apperr.WriteError(w, r, err)
+			return
+		}
+		body, err := json.Marshal(p)
+		if err != nil {
+			apperr.WriteError(w, r, err)
+			return
+		}
+		body = append(body, '\n')
+		if cache != nil {
+			cache.Put(sku, body)
+		}
+		writeProduct(w, r, body)
+	})
+	mux.Handle("GET /products/suggest", svc.gate(FlagSearch, func(w http.ResponseWriter, r *http.Request) {
+		ps, err := svc.Suggest(r.Context(), patterns.SanitizeSearch(r.URL.Query().Get("q")), suggestLimit)
+		if err != nil {
+			apperr.WriteError(w, r, err)
+			return
+		}
+		if ps == nil {
+			ps = []Product{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ps)
+	}))
+	mux.HandleFunc("GET /products/{sku}/related", func(w http.ResponseWriter, r *http.Request) {
+		q, err := Query.Parse(r.URL.Query())
+		if err != nil {
+			apperr.WriteError(w, r, err)
+			return
+		}
+		ps, err := svc.Related(r.Context(), r.PathValue("sku"))
+		if err != nil {
+			apperr.WriteError(w, r, err)
+			return
+		}
+		ps, total, err := Query.Apply(ps, q)
+		if err != nil {
+			apperr.WriteError(w, r, err)
+			return
+		}
+		query.SetHeaders(w, r, q, total)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ps)
+	})
+	mux.HandleFunc("GET /products/export.ndjson", exportNDJSON(svc))
+	mux.HandleFunc("GET /products/export.pb", exportProto(svc))
+	mux.HandleFunc("GET /products/schema.proto", serveProductProto)
+	mux.HandleFunc("POST /products/import", importNDJSON(svc, cache))
+	mux.HandleFunc("POST /products", func(w http.ResponseWriter, r *http.Request) {
+		var p Product
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			if errors.Is(err, jsonx.ErrInvalid) {
+				apperr.WriteError(w, r, err)
+				return
+			}
+			l := apperr.Messages.Negotiate(r.Header.Get("Accept-Language"))
+			w.Header().Set("Content-Language", l.Locale())
+			w.Header().Add("Vary", "Accept-Language")
+			http.Error(w, l.T("malformed_json"), http.StatusBadRequest)
+			return
+		}
+		if err := svc.Create(r.Context(), p); err != nil {
+			apperr.WriteError(w, r, err)
+			return
+		}
+		if cache != nil {
+			cache.Remove(p.SKU)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	return mux
+}
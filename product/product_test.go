@@ -0,0 +1,530 @@
+package product
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/apperr"
+	"github.com/stawuah/pounce-on-go/clock"
+	"github.com/stawuah/pounce-on-go/digest"
+	"github.com/stawuah/pounce-on-go/eventbus"
+	"github.com/stawuah/pounce-on-go/flags"
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/logging"
+	"github.com/stawuah/pounce-on-go/lru"
+	"github.com/stawuah/pounce-on-go/tracing"
+)
+
+func TestNotFoundThroughLayers(t *testing.T) {
+	svc := &Service{Repo: NewRepository()}
+	_, err := svc.Get(context.Background(), "Z9")
+
+	if !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("errors.Is(err, apperr.ErrNotFound) = false for %v", err)
+	}
+	var nf *apperr.NotFoundError
+	if !errors.As(err, &nf) || nf.Key != "Z9" || nf.Kind != "product" {
+		t.Fatalf("errors.As gave %+v", nf)
+	}
+	if got := err.Error(); got != `service: get product: product "Z9" not found` {
+		t.Fatalf("message = %q", got)
+	}
+}
+
+func TestValidationJoinsAllFields(t *testing.T) {
+	svc := &Service{Repo: NewRepository()}
+	err := svc.Create(context.Background(), Product{Price: -1})
+
+	if !errors.Is(err, apperr.ErrInvalid) {
+		t.Fatalf("errors.Is(err, apperr.ErrInvalid) = false for %v", err)
+	}
+	if got := apperr.FieldNames(err); got != "sku, name, price" {
+		t.Fatalf("FieldNames = %q", got)
+	}
+	// errors.As finds only the first match in a joined tree.
+	var ve *apperr.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "sku" {
+		t.Fatalf("errors.As gave %+v", ve)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	repo := NewRepository()
+	h := Handler(&Service{Repo: repo})
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		fail       error
+		wantStatus int
+		wantBody   string
+	}{
+		{"create", "POST", "/products", `{"sku":"A1","name":"Anvil","price":9}`, nil, 201, ""},
+		{"get", "GET", "/products/A1", "", nil, 200, `"name":"Anvil"`},
+		{"missing", "GET", "/products/Z9", "", nil, 404, `product \"Z9\" not found`},
+		{"invalid", "POST", "/products", `{"price":-1}`, nil, 422, `"price":"min=0"`},
+		{"sub-cent price", "POST", "/products", `{"sku":"A1","name":"Anvil","price":9.995}`, nil, 422, `"price":"cents"`},
+		{"unknown status", "POST", "/products", `{"sku":"A2","name":"Axe","price":5,"status":"retired"}`, nil, 422, `status \"retired\"`},
+		{"bad date", "POST", "/products", `{"sku":"A2","name":"Axe","price":5,"released":"2025-13-01"}`, nil, 422, `date \"2025-13-01\"`},
+		{"outage hides detail", "GET", "/products/A1", "", errors.New("dial tcp 10.0.0.7: refused"), 500, `"Internal Server Error"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.Fail = tt.fail
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Fatalf("body = %s, want it to contain %s", rec.Body, tt.wantBody)
+			}
+			if strings.Contains(rec.Body.String(), "10.0.0.7") {
+				t.Fatal("internal error detail leaked to client")
+			}
+		})
+	}
+}
+
+func TestErrorBodyShape(t *testing.T) {
+	h := Handler(&Service{Repo: NewRepository()})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/products", strings.NewReader(`{"sku":"A1"}`)))
+
+	var body apperr.ErrorBody
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Fields["name"] != "required" || len(body.Fields) != 1 {
+		t.Fatalf("fields = %v", body.Fields)
+	}
+}
+
+func TestRepositoryByPrice(t *testing.T) {
+	for name, opts := range map[string][]RepositoryOption{
+		"avl":      nil,
+		"skiplist": {WithSkipListIndex()},
+	} {
+		t.Run(name, func(t *testing.T) { testByPrice(t, NewRepository(opts...)) })
+	}
+}
+
+func testByPrice(t *testing.T, repo *Repository) {
+	ctx := context.Background()
+	for _, p := range []Product{
+		{SKU: "C", Name: "c", Price: 5},
+		{SKU: "A", Name: "a", Price: 2},
+		{SKU: "B", Name: "b", Price: 5},
+		{SKU: "D", Name: "d", Price: 9},
+	} {
+		repo.Put(ctx, p)
+	}
+	repo.Put(ctx, Product{SKU: "D", Name: "d", Price: 1}) // reprice moves it in the index
+
+	got, err := repo.ByPrice(ctx, 1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var skus []string
+	for _, p := range got {
+		skus = append(skus, p.SKU)
+	}
+	if strings.Join(skus, ",") != "D,A,B,C" {
+		t.Fatalf("ByPrice(1, 5) = %v, want D,A,B,C", skus)
+	}
+	if got, _ := repo.ByPrice(ctx, 6, 100); len(got) != 0 {
+		t.Fatalf("ByPrice(6, 100) = %v, want none after the reprice", got)
+	}
+}
+
+func TestCachingHandler(t *testing.T) {
+	repo := NewRepository()
+	h := CachingHandler(&Service{Repo: repo}, lru.New[string, []byte](16))
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	do("POST", "/products", `{"sku":"A1","name":"Anvil","price":9}`)
+	if rec := do("GET", "/products/A1", ""); rec.Header().Get("X-Cache") != "" {
+		t.Fatal("first GET was served from cache")
+	}
+
+	// While cached, an outage behind the handler is invisible.
+	repo.Fail = errors.New("down")
+	rec := do("GET", "/products/A1", "")
+	if rec.Code != 200 || rec.Header().Get("X-Cache") != "hit" || !strings.Contains(rec.Body.String(), "Anvil") {
+		t.Fatalf("cached GET = %d %q (X-Cache %q)", rec.Code, rec.Body, rec.Header().Get("X-Cache"))
+	}
+	if rec := do("GET", "/products/B2", ""); rec.Code != 500 {
+		t.Fatalf("uncached GET during outage = %d, want 500", rec.Code)
+	}
+	repo.Fail = nil
+
+	do("POST", "/products", `{"sku":"A1","name":"Anvil v2","price":10}`)
+	if rec := do("GET", "/products/A1", ""); !strings.Contains(rec.Body.String(), "Anvil v2") {
+		t.Fatalf("GET after update = %s, want the new name", rec.Body)
+	}
+}
+
+func TestInternalErrorsLogged(t *testing.T) {
+	repo := NewRepository()
+	repo.Fail = errors.New("disk on fire")
+	var buf bytes.Buffer
+	log, _ := logging.New(&buf, logging.Config{})
+	h := logging.Middleware(log, Handler(&Service{Repo: repo}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/products/A1", nil))
+	if rec.Code != 500 || strings.Contains(rec.Body.String(), "disk on fire") {
+		t.Fatalf("GET = %d %s", rec.Code, rec.Body)
+	}
+	if got := buf.String(); !strings.Contains(got, `msg="request failed"`) || !strings.Contains(got, "disk on fire") || !strings.Contains(got, "path=/products/A1") {
+		t.Fatalf("log = %s, want the cause with the request's fields", got)
+	}
+}
+
+func TestProductETag(t *testing.T) {
+	h := CachingHandler(&Service{Repo: NewRepository()}, nil)
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/products/A1", nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/products", strings.NewReader(`{"sku":"A1","name":"Anvil","price":9}`)))
+
+	rec := get("")
+	etag := rec.Header().Get("ETag")
+	if rec.Code != 200 || len(etag) != 66 || etag != `"`+digest.Sum(rec.Body.Bytes()).Hex()+`"` {
+		t.Fatalf("GET = %d, ETag %q, want the quoted SHA-256 of the body", rec.Code, etag)
+	}
+	for _, inm := range []string{etag, `"stale", ` + etag, "W/" + etag, "*"} {
+		if rec := get(inm); rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: %d %q, want an empty 304", inm, rec.Code, rec.Body)
+		}
+	}
+	if rec := get(`"stale"`); rec.Code != 200 {
+		t.Errorf("stale If-None-Match: %d, want 200", rec.Code)
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/products", strings.NewReader(`{"sku":"A1","name":"Anvil v2","price":10}`)))
+	if rec := get(etag); rec.Code != 200 || rec.Header().Get("ETag") == etag {
+		t.Fatalf("after update: %d, ETag %q; want 200 and a new ETag", rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	ctx := context.Background()
+	svc := &Service{Repo: NewRepository()}
+	for _, p := range []Product{
+		{SKU: "A2", Name: "Anvil", Price: 9},
+		{SKU: "A1", Name: "anvil", Price: 8},
+		{SKU: "AX", Name: "Axe", Price: 5},
+		{SKU: "H1", Name: "Hammer", Price: 4},
+	} {
+		svc.Create(ctx, p)
+	}
+	svc.Create(ctx, Product{SKU: "H1", Name: "Anchor", Price: 4}) // rename re-indexes
+
+	h := Handler(svc)
+	tests := []struct {
+		q    string
+		want string
+	}{
+		{"an", "H1,A1,A2"},
+		{"%20an*", "H1,A1,A2"},
+		{"A", "H1,A1,A2,AX"},
+		{"ham", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/products/suggest?q="+tt.q, nil))
+		var got []Product
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil || got == nil {
+			t.Fatalf("q=%q: body did not decode to an array: %v", tt.q, err)
+		}
+		var skus []string
+		for _, p := range got {
+			skus = append(skus, p.SKU)
+		}
+		if strings.Join(skus, ",") != tt.want {
+			t.Errorf("suggest %q = %v, want %s", tt.q, skus, tt.want)
+		}
+	}
+}
+
+func TestSearchFlag(t *testing.T) {
+	svc := &Service{Repo: NewRepository(), Flags: flags.New(flags.Flag{Name: FlagSearch})}
+	h := Handler(svc)
+	get := func() int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/products/suggest?q=a", nil))
+		return rec.Code
+	}
+	if code := get(); code != http.StatusNotFound {
+		t.Fatalf("suggest with search off = %d", code)
+	}
+	svc.Flags.Update(flags.Flag{Name: FlagSearch, On: true})
+	if code := get(); code != http.StatusOK {
+		t.Fatalf("suggest with search on = %d", code)
+	}
+}
+
+func TestRelated(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository()
+	svc := &Service{Repo: repo}
+	for _, sku := range []string{"hammer", "nails", "anvil", "gloves", "glue"} {
+		svc.Create(ctx, Product{SKU: sku, Name: sku, Price: 1})
+	}
+	for _, e := range [][2]string{{"hammer", "nails"}, {"hammer", "anvil"}, {"nails", "glue"}, {"glue", "gloves"}, {"glue", "hammer"}} {
+		if err := repo.Relate(ctx, e[0], e[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.Relate(ctx, "hammer", "saw"); !errors.Is(err, apperr.ErrNotFound) {
+		t.Fatalf("Relate to a missing product = %v", err)
+	}
+
+	h := Handler(svc)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/products/hammer/related", nil))
+	var got []Product
+	json.NewDecoder(rec.Body).Decode(&got)
+	var skus []string
+	for _, p := range got {
+		skus = append(skus, p.SKU)
+	}
+	// gloves is three hops away; hammer itself is reached again via glue.
+	if strings.Join(skus, ",") != "nails,anvil,glue" {
+		t.Fatalf("related = %v, want nails,anvil,glue", skus)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/products/saw/related", nil))
+	if rec.Code != 404 {
+		t.Fatalf("related for a missing product = %d, want 404", rec.Code)
+	}
+}
+
+func TestServiceEvents(t *testing.T) {
+	ctx := context.Background()
+	topic := eventbus.NewTopic[jsonx.Event]("products")
+	sub, err := topic.Subscribe(8, eventbus.Drop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2025, 3, 7, 9, 0, 0, 0, time.UTC)
+	svc := &Service{Repo: NewRepository(), Events: topic, Clock: clock.NewFake(at)}
+
+	steps := []Product{
+		{SKU: "A1", Name: "Anvil", Price: 9, Status: jsonx.StatusDraft},
+		{SKU: "A1", Name: "Anvil", Price: 9, Status: jsonx.StatusDraft}, // no change, no event
+		{SKU: "A1", Name: "Anvil", Price: 12, Status: jsonx.StatusActive},
+		{SKU: "A1", Name: "Big anvil", Category: "tools", Price: 12, Status: jsonx.StatusActive},
+	}
+	for _, p := range steps {
+		if err := svc.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := svc.Create(ctx, Product{SKU: "B2"}); err == nil {
+		t.Fatal("invalid product accepted")
+	}
+	topic.Close()
+
+	var got []jsonx.Payload
+	for e := range sub.C {
+		if !e.At.Equal(at) {
+			t.Fatalf("event At = %v, want %v", e.At, at)
+		}
+		got = append(got, e.Payload)
+	}
+	want := []jsonx.Payload{
+		jsonx.ProductCreated{SKU: "A1", Name: "Anvil", Price: 9, Status: jsonx.StatusDraft},
+		jsonx.PriceChanged{SKU: "A1", Old: 9, New: 12},
+		jsonx.StatusChanged{SKU: "A1", From: jsonx.StatusDraft, To: jsonx.StatusActive},
+		jsonx.ProductUpdated{SKU: "A1"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+}
+
+func TestTracing(t *testing.T) {
+	topic := eventbus.NewTopic[jsonx.Event]("products")
+	sub, err := topic.Subscribe(1, eventbus.Drop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer := tracing.NewTracer(10)
+	h := tracer.Middleware(Handler(&Service{Repo: NewRepository(), Events: topic}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/products", strings.NewReader(`{"sku":"A1","name":"Anvil","price":9}`)))
+	id, err := tracing.ParseTraceID(rec.Header().Get(tracing.TraceIDHeader))
+	if rec.Code != http.StatusCreated || err != nil {
+		t.Fatalf("POST = %d, trace ID %q", rec.Code, rec.Header().Get(tracing.TraceIDHeader))
+	}
+	tr, _ := tracer.Trace(id)
+	var names []string
+	for _, s := range tr.Spans {
+		names = append(names, s.Name)
+	}
+	slices.Sort(names)
+	want := []string{"POST /products", "events.publish", "repo.Get", "repo.Put", "service.Create"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("spans = %v, want %v", names, want)
+	}
+
+	e := <-sub.C
+	if tid, _, err := tracing.ParseTraceparent(e.Trace); err != nil || tid != id {
+		t.Fatalf("event traceparent = %q, want trace %s", e.Trace, id)
+	}
+	topic.Close()
+}
+
+func TestLocalizedErrors(t *testing.T) {
+	repo := NewRepository()
+	repo.Put(context.Background(), Product{SKU: "A1", Name: "Anvil", Price: 9})
+	h := Handler(&Service{Repo: repo})
+
+	tests := []struct {
+		name, lang, method, path, body string
+		wantLang                       string
+		wantError                      string
+		wantMessages                   map[string]string
+	}{
+		{"default", "", "GET", "/products/Z9", "", "en", `product "Z9" not found`, nil},
+		{"french", "fr-FR, en;q=0.5", "GET", "/products/Z9", "", "fr", "produit « Z9 » introuvable", nil},
+		{"unsupported", "de", "GET", "/products/Z9", "", "en", `product "Z9" not found`, nil},
+		{"french fields", "fr", "POST", "/products", `{"sku":"A2","price":-1,"status":"draft"}`, "fr", "Entité non traitable", map[string]string{
+			"name": "name est obligatoire", "price": "price doit valoir au moins 0",
+		}},
+		{"spanish fields", "es", "POST", "/products", `{"name":"Axe","price":1.005}`, "es", "Entidad no procesable", map[string]string{
+			"sku": "sku es obligatorio", "price": "price debe ser un número entero de céntimos",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.lang != "" {
+				req.Header.Set("Accept-Language", tt.lang)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if got := rec.Header().Get("Content-Language"); got != tt.wantLang {
+				t.Errorf("Content-Language = %q, want %q", got, tt.wantLang)
+			}
+			var body apperr.ErrorBody
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error != tt.wantError || !maps.Equal(body.Messages, tt.wantMessages) {
+				t.Fatalf("body = %+v, want %q %v", body, tt.wantError, tt.wantMessages)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	repo := NewRepository()
+	for _, p := range []Product{
+		{SKU: "A1", Name: "Anvil", Price: 40, Status: jsonx.StatusActive},
+		{SKU: "B2", Name: "Bolt", Price: 0.5, Status: jsonx.StatusActive},
+		{SKU: "C3", Name: "Chisel", Price: 18, Status: jsonx.StatusDraft},
+		{SKU: "D4", Name: "Drill", Price: 120},
+	} {
+		repo.Put(context.Background(), p)
+	}
+	repo.Relate(context.Background(), "A1", "C3")
+	repo.Relate(context.Background(), "A1", "D4")
+	h := Handler(&Service{Repo: repo})
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantSKUs   []string
+		wantTotal  string
+	}{
+		{"/products", 200, []string{"A1", "B2", "C3", "D4"}, "4"},
+		{"/products?sort=-price&limit=2", 200, []string{"D4", "A1"}, "4"},
+		{"/products?price[gte]=1&price[lte]=100", 200, []string{"A1", "C3"}, "2"},
+		{"/products?price[gt]=18&sort=price", 200, []string{"A1", "D4"}, "2"},
+		{"/products?status=active&offset=1", 200, []string{"B2"}, "2"},
+		{"/products?name[prefix]=Dr", 200, []string{"D4"}, "1"},
+		{"/products/A1/related?price[lt]=100", 200, []string{"C3"}, "1"},
+		{"/products?status=retired", 400, nil, ""},
+		{"/products?sort=weight", 400, nil, ""},
+		{"/products/A1/related?limit=x", 400, nil, ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d (%s)", tt.path, rec.Code, tt.wantStatus, rec.Body)
+			continue
+		}
+		if tt.wantStatus != 200 {
+			var body apperr.ErrorBody
+			json.NewDecoder(rec.Body).Decode(&body)
+			if !strings.HasPrefix(body.Error, "query: invalid: ") {
+				t.Errorf("%s: error = %q, want the reason", tt.path, body.Error)
+			}
+			continue
+		}
+		var ps []Product
+		if err := json.NewDecoder(rec.Body).Decode(&ps); err != nil {
+			t.Fatal(err)
+		}
+		var skus []string
+		for _, p := range ps {
+			skus = append(skus, p.SKU)
+		}
+		if !slices.Equal(skus, tt.wantSKUs) || rec.Header().Get("X-Total-Count") != tt.wantTotal {
+			t.Errorf("%s: %v of %s, want %v of %s", tt.path, skus, rec.Header().Get("X-Total-Count"), tt.wantSKUs, tt.wantTotal)
+		}
+	}
+}
+
+func TestOpenRepository(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "products.ndjson")
+	repo, err := OpenRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.Put(ctx, Product{SKU: "B2", Name: "Bolt", Price: 0.5})
+	repo.Put(ctx, Product{SKU: "A1", Name: "Anvil", Price: 40})
+	repo.Put(ctx, Product{SKU: "A1", Name: "Axe", Price: 30})
+
+	repo, err = OpenRepository(path, WithSkipListIndex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, _ := repo.List(ctx)
+	cheap, _ := repo.ByPrice(ctx, 0, 35)
+	axes, _ := repo.Suggest(ctx, "ax", 5)
+	anvils, _ := repo.Suggest(ctx, "an", 5)
+	if len(list) != 2 || list[0].Name != "Axe" || len(cheap) != 2 || len(axes) != 1 || len(anvils) != 0 {
+		t.Fatalf("after reopening: list %v, cheap %v, axes %v, anvils %v", list, cheap, axes, anvils)
+	}
+}
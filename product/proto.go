@@ -0,0 +1,159 @@
+package product
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/apperr"
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/protox"
+)
+
+// Protobuf is the content type of a stream of length-delimited protocol
+// buffer messages.
+const Protobuf = "application/x-protobuf"
+
+// Proto is the schema of the protobuf export, served at
+// GET /products/schema.proto so clients can generate code from it.
+//
+//go:embed product.proto
+var Proto string
+
+// Field numbers from product.proto.
+const (
+	protoSKU      = 1
+	protoName     = 2
+	protoCategory = 3
+	protoPrice    = 4
+	protoStatus   = 5
+	protoReleased = 6
+
+	protoYear  = 1
+	protoMonth = 2
+	protoDay   = 3
+)
+
+// AppendProto appends p encoded as a pounce.v1.Product message.
+func (p Product) AppendProto(b []byte) []byte {
+	b = protox.AppendString(b, protoSKU, p.SKU)
+	b = protox.AppendString(b, protoName, p.Name)
+	b = protox.AppendString(b, protoCategory, p.Category)
+	b = protox.AppendDouble(b, protoPrice, p.Price)
+	b = protox.AppendInt(b, protoStatus, int64(p.Status))
+	if !p.Released.IsZero() {
+		b = protox.AppendMessage(b, protoReleased, func(b []byte) []byte {
+			b = protox.AppendInt(b, protoYear, int64(p.Released.Year))
+			b = protox.AppendInt(b, protoMonth, int64(p.Released.Month))
+			return protox.AppendInt(b, protoDay, int64(p.Released.Day))
+		})
+	}
+	return b
+}
+
+// UnmarshalProto decodes a pounce.v1.Product message into p. Fields it
+// does not know are skipped, so a newer writer can add them.
+func (p *Product) UnmarshalProto(msg []byte) error {
+	*p = Product{}
+	return protox.Each(msg, func(f protox.Field) error {
+		var err error
+		switch f.Num {
+		case protoSKU:
+			p.SKU, err = f.Text()
+		case protoName:
+			p.Name, err = f.Text()
+		case protoCategory:
+			p.Category, err = f.Text()
+		case protoPrice:
+			p.Price, err = f.Double()
+		case protoStatus:
+			var n int64
+			n, err = f.Int()
+			p.Status = jsonx.ProductStatus(n)
+		case protoReleased:
+			var date []byte
+			if date, err = f.Message(); err == nil {
+				p.Released, err = unmarshalDate(date)
+			}
+		}
+		return err
+	})
+}
+
+func unmarshalDate(msg []byte) (jsonx.Date, error) {
+	var d jsonx.Date
+	err := protox.Each(msg, func(f protox.Field) error {
+		n, err := f.Int()
+		switch f.Num {
+		case protoYear:
+			d.Year = int(n)
+		case protoMonth:
+			d.Month = time.Month(n)
+		case protoDay:
+			d.Day = int(n)
+		default:
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return jsonx.Date{}, err
+	}
+	if d.IsZero() {
+		return d, nil
+	}
+	// time.Date normalises the 30th of February to March; a real date
+	// comes back unchanged.
+	if jsonx.DateOf(d.Time(time.UTC)) != d {
+		return jsonx.Date{}, fmt.Errorf("%w: date %s does not exist", jsonx.ErrInvalid, d)
+	}
+	return d, nil
+}
+
+// ReadProto decodes a stream of length-delimited Product messages, as
+// GET /products/export.pb writes, and passes each product to fn.
+func ReadProto(r io.Reader, fn func(Product) error) error {
+	return protox.EachDelimited(r, func(msg []byte) error {
+		var p Product
+		if err := p.UnmarshalProto(msg); err != nil {
+			return err
+		}
+		return fn(p)
+	})
+}
+
+// exportProto serves GET /products/export.pb, the protobuf counterpart of
+// GET /products/export.ndjson.
+func exportProto(svc *Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ps, err := svc.List(r.Context())
+		if err != nil {
+			apperr.WriteError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", Protobuf)
+		rc := http.NewResponseController(w)
+		var msg []byte
+		for i, p := range ps {
+			if r.Context().Err() != nil {
+				return
+			}
+			msg = p.AppendProto(msg[:0])
+			if err := protox.WriteDelimited(w, msg); err != nil {
+				return
+			}
+			if (i+1)%flushEvery == 0 {
+				rc.Flush()
+			}
+		}
+		rc.Flush()
+	}
+}
+
+// serveProductProto serves GET /products/schema.proto.
+func serveProductProto(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, Proto)
+}
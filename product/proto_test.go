@@ -0,0 +1,234 @@
+package product
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/protox"
+)
+
+func TestProtoRoundTrip(t *testing.T) {
+	for _, p := range []Product{
+		{},
+		{SKU: "MUG-1", Name: "Mug", Category: "kitchen", Price: 8.5, Status: jsonx.StatusActive,
+			Released: jsonx.Date{Year: 2025, Month: 3, Day: 1}},
+		{SKU: "Ünïcode", Name: "Déjà vu", Price: -1},
+	} {
+		var back Product
+		if err := back.UnmarshalProto(p.AppendProto(nil)); err != nil || back != p {
+			t.Fatalf("round trip of %+v = %+v, %v", p, back, err)
+		}
+	}
+}
+
+// The bytes any protobuf library would write for the same message.
+func TestProtoWire(t *testing.T) {
+	p := Product{SKU: "A", Price: 2, Status: jsonx.StatusDraft, Released: jsonx.Date{Year: 2025, Month: 1, Day: 2}}
+	want := "0a0141" + "210000000000000040" + "2801" + "3207" + "08e90f" + "1001" + "1802"
+	if got := hex.EncodeToString(p.AppendProto(nil)); got != want {
+		t.Fatalf("AppendProto = %s, want %s", got, want)
+	}
+}
+
+func TestUnmarshalProto(t *testing.T) {
+	// A newer writer's field 99 is skipped.
+	msg := protox.AppendString(Product{SKU: "MUG-1"}.AppendProto(nil), 99, "from the future")
+	var p Product
+	if err := p.UnmarshalProto(msg); err != nil || p.SKU != "MUG-1" {
+		t.Fatalf("with an unknown field: %+v, %v", p, err)
+	}
+
+	for name, msg := range map[string][]byte{
+		"price as a string": protox.AppendString(nil, protoPrice, "8.50"),
+		"truncated":         Product{SKU: "MUG-1"}.AppendProto(nil)[:4],
+		"impossible date": protox.AppendMessage(nil, protoReleased, func(b []byte) []byte {
+			return protox.AppendInt(protox.AppendInt(protox.AppendInt(b, protoYear, 2025), protoMonth, 2), protoDay, 30)
+		}),
+	} {
+		if err := p.UnmarshalProto(msg); err == nil {
+			t.Errorf("%s: decoded %+v", name, p)
+		}
+	}
+}
+
+// product.proto and the field numbers in proto.go must agree.
+func TestProtoSchema(t *testing.T) {
+	fields := map[string]int{}
+	for _, m := range regexp.MustCompile(`(?m)^\s+\w+ (\w+) = (\d+);`).FindAllStringSubmatch(Proto, -1) {
+		fields[m[1]], _ = strconv.Atoi(m[2])
+	}
+	for name, num := range map[string]int{
+		"sku": protoSKU, "name": protoName, "category": protoCategory, "price": protoPrice,
+		"status": protoStatus, "released": protoReleased,
+		"year": protoYear, "month": protoMonth, "day": protoDay,
+	} {
+		if fields[name] != num {
+			t.Errorf("%s: product.proto says %d, proto.go %d", name, fields[name], num)
+		}
+	}
+	for s := jsonx.StatusDraft; s <= jsonx.StatusDiscontinued; s++ {
+		want := fmt.Sprintf("PRODUCT_STATUS_%s = %d;", bytes.ToUpper([]byte(s.String())), s)
+		if !bytes.Contains([]byte(Proto), []byte(want)) {
+			t.Errorf("product.proto lacks %s", want)
+		}
+	}
+}
+
+func TestExportProto(t *testing.T) {
+	svc := &Service{Repo: NewRepository()}
+	ctx := context.Background()
+	for i := range 250 {
+		if err := svc.Create(ctx, Product{SKU: fmt.Sprintf("P%03d", i), Name: "Widget", Price: float64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, _ := svc.List(ctx)
+
+	rec := httptest.NewRecorder()
+	Handler(svc).ServeHTTP(rec, httptest.NewRequest("GET", "/products/export.pb", nil))
+	if rec.Code != 200 || rec.Header().Get("Content-Type") != Protobuf || !rec.Flushed {
+		t.Fatalf("status = %d, Content-Type = %q, flushed = %v", rec.Code, rec.Header().Get("Content-Type"), rec.Flushed)
+	}
+	var got []Product
+	if err := ReadProto(rec.Body, func(p Product) error { got = append(got, p); return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("exported %d products, want %d", len(got), len(want))
+	}
+
+	rec = httptest.NewRecorder()
+	Handler(svc).ServeHTTP(rec, httptest.NewRequest("GET", "/products/schema.proto", nil))
+	if rec.Code != 200 || rec.Body.String() != Proto {
+		t.Fatalf("schema: %d %q", rec.Code, rec.Body.String())
+	}
+
+	boom := errors.New("boom")
+	if err := ReadProto(bytes.NewReader(Product{SKU: "A"}.AppendProto([]byte{3})), func(Product) error { return boom }); !errors.Is(err, boom) {
+		t.Fatalf("ReadProto = %v", err)
+	}
+}
+
+// catalog is a sample of the products the serialization benchmarks
+// encode.
+func catalog() []Product {
+	ps := make([]Product, 1000)
+	for i := range ps {
+		ps[i] = Product{
+			SKU: fmt.Sprintf("SKU-%05d", i), Name: fmt.Sprintf("Widget model %d", i),
+			Category: "hardware", Price: float64(i%500) + 0.99, Status: jsonx.StatusActive,
+			Released: jsonx.Date{Year: 2020 + i%5, Month: time.Month(1 + i%12), Day: 1 + i%28},
+		}
+	}
+	return ps
+}
+
+// codecs encodes and decodes a whole catalog three ways, for
+// BenchmarkEncode and BenchmarkDecode.
+var codecs = []struct {
+	name   string
+	encode func([]Product) []byte
+	decode func([]byte) ([]Product, error)
+}{
+	{"proto", func(ps []Product) []byte {
+		var buf bytes.Buffer
+		var msg []byte
+		for _, p := range ps {
+			msg = p.AppendProto(msg[:0])
+			protox.WriteDelimited(&buf, msg)
+		}
+		return buf.Bytes()
+	}, func(b []byte) ([]Product, error) {
+		var ps []Product
+		err := ReadProto(bytes.NewReader(b), func(p Product) error { ps = append(ps, p); return nil })
+		return ps, err
+	}},
+	{"json", func(ps []Product) []byte {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		for _, p := range ps {
+			enc.Encode(p)
+		}
+		return buf.Bytes()
+	}, func(b []byte) ([]Product, error) {
+		var ps []Product
+		dec := json.NewDecoder(bytes.NewReader(b))
+		for dec.More() {
+			var p Product
+			if err := dec.Decode(&p); err != nil {
+				return nil, err
+			}
+			ps = append(ps, p)
+		}
+		return ps, nil
+	}},
+	{"gob", func(ps []Product) []byte {
+		var buf bytes.Buffer
+		enc := gob.NewEncoder(&buf)
+		for _, p := range ps {
+			enc.Encode(p)
+		}
+		return buf.Bytes()
+	}, func(b []byte) ([]Product, error) {
+		var ps []Product
+		dec := gob.NewDecoder(bytes.NewReader(b))
+		for {
+			var p Product
+			if err := dec.Decode(&p); errors.Is(err, io.EOF) {
+				return ps, nil
+			} else if err != nil {
+				return nil, err
+			}
+			ps = append(ps, p)
+		}
+	}},
+}
+
+func TestCodecsAgree(t *testing.T) {
+	ps := catalog()
+	for _, c := range codecs {
+		if got, err := c.decode(c.encode(ps)); err != nil || !slices.Equal(got, ps) {
+			t.Errorf("%s: round trip failed: %v", c.name, err)
+		}
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	ps := catalog()
+	for _, c := range codecs {
+		b.Run(c.name, func(b *testing.B) {
+			var n int
+			for b.Loop() {
+				n = len(c.encode(ps))
+			}
+			b.ReportMetric(float64(n)/float64(len(ps)), "bytes/product")
+		})
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	ps := catalog()
+	for _, c := range codecs {
+		data := c.encode(ps)
+		b.Run(c.name, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := c.decode(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package product
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/migrate"
+)
+
+// Currency is what product prices are in.
+const Currency = "USD"
+
+// Snapshots is the product snapshot format and how each older version
+// becomes the next:
+//
+//   - 1, plain NDJSON products with no header, as every release wrote
+//     before snapshots were versioned.
+//   - 2 added category. Older products have none.
+//   - 3 stores the price as jsonx.Money, an exact decimal amount and a
+//     currency, instead of a float. Older prices are in Currency.
+//
+// A change to how products are stored registers the next migration here.
+var Snapshots = migrate.New("pounce.products").
+	Register(1, func(r migrate.Record) error {
+		if _, ok := r["category"]; !ok {
+			r["category"] = ""
+		}
+		return nil
+	}).
+	Register(2, func(r migrate.Record) error {
+		n, ok := r["price"].(json.Number)
+		if !ok {
+			return errors.New("price is not a number")
+		}
+		v, err := strconv.ParseFloat(string(n), 64)
+		if err != nil {
+			return fmt.Errorf("price: %w", err)
+		}
+		r["price"] = jsonx.MoneyOf(v, Currency)
+		return nil
+	})
+
+// snapshotProduct is a product as the current snapshot version stores it.
+type snapshotProduct struct {
+	SKU      string              `json:"sku"`
+	Name     string              `json:"name"`
+	Category string              `json:"category,omitempty"`
+	Price    jsonx.Money         `json:"price"`
+	Status   jsonx.ProductStatus `json:"status,omitempty"`
+	Released jsonx.Date          `json:"released,omitzero"`
+}
+
+// WriteSnapshot writes ps to w in the current Snapshots version.
+func WriteSnapshot(w io.Writer, ps iter.Seq[Product]) error {
+	return migrate.Write(w, Snapshots, func(yield func(snapshotProduct) bool) {
+		for p := range ps {
+			s := snapshotProduct{
+				SKU: p.SKU, Name: p.Name, Category: p.Category,
+				Price:  jsonx.MoneyOf(p.Price, Currency),
+				Status: p.Status, Released: p.Released,
+			}
+			if !yield(s) {
+				return
+			}
+		}
+	})
+}
+
+// ReadSnapshot reads a snapshot of any version, migrating it as it goes,
+// and passes each product to fn.
+func ReadSnapshot(r io.Reader, fn func(Product) error) error {
+	return migrate.Read(r, Snapshots, func(s snapshotProduct) error {
+		if s.Price.Currency != Currency {
+			return fmt.Errorf("product: %s: price in %s, want %s", s.SKU, s.Price.Currency, Currency)
+		}
+		return fn(Product{
+			SKU: s.SKU, Name: s.Name, Category: s.Category,
+			Price:  s.Price.Float(),
+			Status: s.Status, Released: s.Released,
+		})
+	})
+}
@@ -0,0 +1,139 @@
+package product
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/migrate"
+)
+
+func readSnapshot(file string) ([]Product, error) {
+	var ps []Product
+	err := ReadSnapshot(strings.NewReader(file), func(p Product) error {
+		ps = append(ps, p)
+		return nil
+	})
+	return ps, err
+}
+
+// Snapshots written by every earlier release still load.
+func TestReadSnapshotVersions(t *testing.T) {
+	want := []Product{
+		{SKU: "MUG-1", Name: "Mug", Price: 19.99, Status: jsonx.StatusActive},
+		{SKU: "PEN-1", Name: "Pen", Price: 0.1},
+	}
+	tests := []struct {
+		name     string
+		file     string
+		category string
+	}{
+		{"version 1", `{"sku":"MUG-1","name":"Mug","price":19.99,"status":"active"}
+{"sku":"PEN-1","name":"Pen","price":0.1}`, ""},
+		{"version 2", `{"format":"pounce.products","version":2}
+{"sku":"MUG-1","name":"Mug","category":"kitchen","price":19.99,"status":"active"}
+{"sku":"PEN-1","name":"Pen","category":"kitchen","price":0.1}`, "kitchen"},
+		{"version 3", `{"format":"pounce.products","version":3}
+{"sku":"MUG-1","name":"Mug","category":"kitchen","price":{"amount":"19.99","currency":"USD"},"status":"active"}
+{"sku":"PEN-1","name":"Pen","category":"kitchen","price":{"amount":"0.10","currency":"USD"}}`, "kitchen"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readSnapshot(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := slices.Clone(want)
+			for i := range want {
+				want[i].Category = tt.category
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	ps := []Product{
+		{SKU: "MUG-1", Name: "Mug", Category: "kitchen", Price: 8, Released: jsonx.Date{Year: 2025, Month: 3, Day: 1}},
+		{SKU: "PEN-1", Name: "Pen", Price: 1.25},
+	}
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, slices.Values(ps)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), `{"format":"pounce.products","version":3}`) ||
+		!strings.Contains(buf.String(), `"price":{"amount":"1.25","currency":"USD"}`) {
+		t.Fatalf("wrote %s", buf.String())
+	}
+	got, err := readSnapshot(buf.String())
+	if err != nil || !slices.Equal(got, ps) {
+		t.Fatalf("read back %+v, %v", got, err)
+	}
+}
+
+// TestSnapshotRoundTripPrices checks that every price Validate accepts
+// survives a save and load, and that it rejects the ones that would be
+// rounded.
+func TestSnapshotRoundTripPrices(t *testing.T) {
+	tests := []struct {
+		price float64
+		valid bool
+	}{
+		{0, true},
+		{0.1, true},
+		{1.15, true},
+		{19.99, true},
+		{1234567.89, true},
+		{9.995, false},
+		{0.001, false},
+		{1.0 / 3, false},
+	}
+	for _, tt := range tests {
+		p := Product{SKU: "A1", Name: "Anvil", Price: tt.price}
+		if err := p.Validate(); (err == nil) != tt.valid {
+			t.Errorf("Validate(%v) = %v, want valid %t", tt.price, err, tt.valid)
+			continue
+		}
+		if !tt.valid {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := WriteSnapshot(&buf, slices.Values([]Product{p})); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readSnapshot(buf.String())
+		if err != nil || len(got) != 1 || got[0] != p {
+			t.Errorf("price %v read back as %+v, %v", tt.price, got, err)
+		}
+	}
+}
+
+func TestReadSnapshotErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want error
+	}{
+		{"newer release", `{"format":"pounce.products","version":4}`, migrate.ErrTooNew},
+		{"other file", `{"format":"pounce.orders","version":1}`, migrate.ErrFormat},
+		{"bad amount", `{"format":"pounce.products","version":3}
+{"sku":"MUG-1","name":"Mug","price":{"amount":"1.999","currency":"USD"}}`, jsonx.ErrInvalid},
+		{"other currency", `{"format":"pounce.products","version":3}
+{"sku":"MUG-1","name":"Mug","price":{"amount":"1.99","currency":"EUR"}}`, nil},
+		{"version 2 price not a number", `{"format":"pounce.products","version":2}
+{"sku":"MUG-1","name":"Mug","price":"1.99"}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readSnapshot(tt.file)
+			if err == nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("ReadSnapshot = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
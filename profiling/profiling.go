@@ -0,0 +1,76 @@
+// Package profiling exposes pprof over HTTP and captures CPU and heap
+// profiles to files.
+//
+// Importing net/http/pprof for its side effect registers handlers on
+// http.DefaultServeMux, where they are easy to expose by accident.
+// Register puts them on a mux of the caller's choosing instead, and
+// AddFlag wires that to a -pprof listen address so profiling is off
+// unless asked for.
+package profiling
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	"runtime"
+	rpprof "runtime/pprof"
+)
+
+// Register adds the pprof endpoints under /debug/pprof/ on mux.
+func Register(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+// AddFlag defines a -pprof flag on fs. After fs is parsed, call the
+// returned function: if the flag was set it serves pprof on that address
+// in the background, on a dedicated mux, and returns the server so the
+// caller can shut it down; otherwise it returns nil.
+func AddFlag(fs *flag.FlagSet) func() *http.Server {
+	addr := fs.String("pprof", "", "serve pprof on this address (e.g. localhost:6060); off when empty")
+	return func() *http.Server {
+		if *addr == "" {
+			return nil
+		}
+		mux := http.NewServeMux()
+		Register(mux)
+		srv := &http.Server{Addr: *addr, Handler: mux}
+		go srv.ListenAndServe()
+		return srv
+	}
+}
+
+// Capture starts a CPU profile written to dir/cpu.pprof. The returned stop
+// function ends it and writes a heap profile to dir/heap.pprof; call it
+// when the load being measured is done.
+func Capture(dir string) (stop func() error, err error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	cpu, err := os.Create(filepath.Join(dir, "cpu.pprof"))
+	if err != nil {
+		return nil, err
+	}
+	if err := rpprof.StartCPUProfile(cpu); err != nil {
+		cpu.Close()
+		return nil, err
+	}
+	return func() error {
+		rpprof.StopCPUProfile()
+		errCPU := cpu.Close()
+
+		heap, err := os.Create(filepath.Join(dir, "heap.pprof"))
+		if err != nil {
+			return errors.Join(errCPU, err)
+		}
+		runtime.GC() // report live objects, not garbage awaiting collection
+		errHeap := rpprof.WriteHeapProfile(heap)
+		return errors.Join(errCPU, errHeap, heap.Close())
+	}, nil
+}
@@ -0,0 +1,94 @@
+package profiling
+
+import (
+	"context"
+	"flag"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegister(t *testing.T) {
+	mux := http.NewServeMux()
+	Register(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/debug/pprof/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestAddFlagOffByDefault(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	start := AddFlag(fs)
+	fs.Parse(nil)
+	if srv := start(); srv != nil {
+		t.Fatal("pprof served without -pprof")
+	}
+}
+
+func TestAddFlag(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	start := AddFlag(fs)
+	fs.Parse([]string{"-pprof", addr})
+	srv := start()
+	if srv == nil {
+		t.Fatal("no server started")
+	}
+	defer srv.Shutdown(context.Background())
+
+	// The listener starts in the background; retry briefly.
+	var resp *http.Response
+	for range 100 {
+		if resp, err = http.Get("http://" + addr + "/debug/pprof/cmdline"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func TestCapture(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profiles")
+	stop, err := Capture(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	for i := range 10000 {
+		sb.WriteString(strings.Repeat("x", i%32))
+	}
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"cpu.pprof", "heap.pprof"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() == 0 {
+			t.Fatalf("%s is empty", name)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package propcheck
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stawuah/pounce-on-go/concurrency/ownership"
+	"github.com/stawuah/pounce-on-go/product"
+	"github.com/stawuah/pounce-on-go/sliceutil"
+)
+
+var skus = Map(IntRange(1, 9999), func(n int) string { return fmt.Sprintf("SKU-%04d", n) })
+
+var products = Build(func(r *rand.Rand) product.Product {
+	return product.Product{
+		SKU:   skus.Generate(r),
+		Name:  String("abcdefghij ", 12).Generate(r) + "x",
+		Price: float64(IntRange(0, 50000).Generate(r)) / 100,
+	}
+})
+
+type item struct {
+	SKU string
+	Qty int
+}
+
+var items = Build(func(r *rand.Rand) item {
+	return item{SKU: skus.Generate(r), Qty: IntRange(0, 100).Generate(r)}
+})
+
+func TestReverseTwiceIsIdentity(t *testing.T) {
+	Check(t, SliceOf(IntRange(-100, 100), 30), func(s []int) bool {
+		return slices.Equal(sliceutil.Reverse(sliceutil.Reverse(s)), s)
+	})
+}
+
+func TestGeneratedProductsAreValid(t *testing.T) {
+	Check(t, products, func(p product.Product) bool {
+		return p.Validate() == nil
+	})
+}
+
+func TestStoreSetThenGet(t *testing.T) {
+	stores := map[string]func() ownership.Store{
+		"Mutex": func() ownership.Store { return ownership.NewMutexStore() },
+		"Owned": func() ownership.Store { return ownership.NewOwnedStore() },
+	}
+	for name, mk := range stores {
+		t.Run(name, func(t *testing.T) {
+			s := mk()
+			if c, ok := s.(interface{ Close() }); ok {
+				defer c.Close()
+			}
+			Check(t, SliceOf(items, 20), func(batch []item) bool {
+				for _, it := range batch {
+					s.Set(it.SKU, fmt.Sprint(it.Qty))
+				}
+				// Later writes to a SKU win, so check against the last one.
+				last := make(map[string]int)
+				for _, it := range batch {
+					last[it.SKU] = it.Qty
+				}
+				for sku, qty := range last {
+					if v, ok := s.Get(sku); !ok || v != fmt.Sprint(qty) {
+						return false
+					}
+				}
+				return true
+			})
+		})
+	}
+}
+
+// fakeT captures Fatalf so failures can be asserted on.
+type fakeT struct {
+	testing.TB
+	msg string
+}
+
+func (f *fakeT) Helper() {}
+func (f *fakeT) Fatalf(format string, args ...any) {
+	f.msg = fmt.Sprintf(format, args...)
+}
+
+func TestShrinking(t *testing.T) {
+	ft := &fakeT{TB: t}
+	// False for any slice containing a value of 10 or more; the minimal
+	// counterexample is [10].
+	Check(ft, SliceOf(IntRange(0, 1000), 50), func(s []int) bool {
+		return !slices.ContainsFunc(s, func(v int) bool { return v >= 10 })
+	})
+	if !strings.Contains(ft.msg, "[]int{10}") {
+		t.Fatalf("did not shrink to []int{10}:\n%s", ft.msg)
+	}
+	if !strings.Contains(ft.msg, "-propcheck.seed=") {
+		t.Fatalf("failure does not report seed:\n%s", ft.msg)
+	}
+}
+
+func TestIntRangeStaysInRange(t *testing.T) {
+	g := IntRange(-3, 3)
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 1000 {
+		if v := g.Generate(r); v < -3 || v > 3 {
+			t.Fatalf("generated %d", v)
+		}
+	}
+	if got := IntRange(5, 9).Shrink(9); slices.Contains(got, 0) || got[0] != 5 {
+		t.Fatalf("shrink left the range: %v", got)
+	}
+}
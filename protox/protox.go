@@ -0,0 +1,207 @@
+// Package protox reads and writes the Protocol Buffers wire format with
+// nothing but the standard library, for the few messages this module
+// exports. A message type's Append and Unmarshal functions are written by
+// hand against its .proto file, field by field, using the helpers here;
+// any protobuf implementation can then read what they write, and they
+// can read what it writes.
+//
+// The helpers follow proto3: a field holding its zero value is not
+// written, and a reader skips fields it does not know, so a message can
+// gain fields without breaking older readers. Streams of messages are
+// each prefixed by their length as a varint, the framing the official
+// libraries call "delimited".
+package protox
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// ErrInvalid is wrapped by every error for malformed input.
+var ErrInvalid = errors.New("protox: malformed message")
+
+// MaxMessageBytes bounds a single message in a stream, so a corrupt
+// length cannot make a reader allocate without limit.
+const MaxMessageBytes = 4 << 20
+
+// Type is a field's wire type: how its value is laid out, which is all a
+// reader needs to skip a field it does not know.
+type Type uint8
+
+const (
+	Varint  Type = 0 // int32, int64, uint32, uint64, bool, enum
+	Fixed64 Type = 1 // double, fixed64
+	Bytes   Type = 2 // string, bytes, embedded messages
+	Fixed32 Type = 5 // float, fixed32
+)
+
+// AppendTag appends the key of field num with wire type t.
+func AppendTag(b []byte, num int, t Type) []byte {
+	return binary.AppendUvarint(b, uint64(num)<<3|uint64(t))
+}
+
+// AppendUint appends an unsigned varint field, unless v is zero.
+func AppendUint(b []byte, num int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.AppendUvarint(AppendTag(b, num, Varint), v)
+}
+
+// AppendInt appends an int32 or int64 field, unless v is zero. Negative
+// values take ten bytes, as the format defines; there is no zigzag.
+func AppendInt(b []byte, num int, v int64) []byte { return AppendUint(b, num, uint64(v)) }
+
+// AppendBool appends a bool field, unless v is false.
+func AppendBool(b []byte, num int, v bool) []byte {
+	if !v {
+		return b
+	}
+	return AppendUint(b, num, 1)
+}
+
+// AppendDouble appends a double field, unless v is zero.
+func AppendDouble(b []byte, num int, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.LittleEndian.AppendUint64(AppendTag(b, num, Fixed64), math.Float64bits(v))
+}
+
+// AppendString appends a string field, unless s is empty.
+func AppendString(b []byte, num int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = binary.AppendUvarint(AppendTag(b, num, Bytes), uint64(len(s)))
+	return append(b, s...)
+}
+
+// AppendMessage appends an embedded message field, encoded by appendMsg,
+// unless it is nil. An empty message is still written, so the field
+// reads back as set.
+func AppendMessage(b []byte, num int, appendMsg func([]byte) []byte) []byte {
+	if appendMsg == nil {
+		return b
+	}
+	msg := appendMsg(nil)
+	b = binary.AppendUvarint(AppendTag(b, num, Bytes), uint64(len(msg)))
+	return append(b, msg...)
+}
+
+// Field is one field read from a message.
+type Field struct {
+	Num  int
+	Type Type
+	n    uint64 // Varint, Fixed64 and Fixed32 values
+	data []byte // Bytes values
+}
+
+// Each calls fn with each field of msg in order. Repeated fields come once
+// per value. Byte values alias msg.
+func Each(msg []byte, fn func(Field) error) error {
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return fmt.Errorf("%w: bad field key", ErrInvalid)
+		}
+		msg = msg[n:]
+		f := Field{Num: int(key >> 3), Type: Type(key & 7)}
+		if f.Num == 0 {
+			return fmt.Errorf("%w: field number 0", ErrInvalid)
+		}
+		switch f.Type {
+		case Varint:
+			if f.n, n = binary.Uvarint(msg); n <= 0 {
+				return fmt.Errorf("%w: field %d: bad varint", ErrInvalid, f.Num)
+			}
+		case Fixed64:
+			if n = 8; len(msg) < n {
+				return fmt.Errorf("%w: field %d: truncated", ErrInvalid, f.Num)
+			}
+			f.n = binary.LittleEndian.Uint64(msg)
+		case Fixed32:
+			if n = 4; len(msg) < n {
+				return fmt.Errorf("%w: field %d: truncated", ErrInvalid, f.Num)
+			}
+			f.n = uint64(binary.LittleEndian.Uint32(msg))
+		case Bytes:
+			size, m := binary.Uvarint(msg)
+			if m <= 0 || size > uint64(len(msg)-m) {
+				return fmt.Errorf("%w: field %d: truncated", ErrInvalid, f.Num)
+			}
+			f.data, n = msg[m:m+int(size)], m+int(size)
+		default:
+			return fmt.Errorf("%w: field %d: unsupported wire type %d", ErrInvalid, f.Num, f.Type)
+		}
+		msg = msg[n:]
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f Field) want(t Type) error {
+	if f.Type != t {
+		return fmt.Errorf("%w: field %d: wire type %d, want %d", ErrInvalid, f.Num, f.Type, t)
+	}
+	return nil
+}
+
+// Uint returns a varint field's value.
+func (f Field) Uint() (uint64, error) { return f.n, f.want(Varint) }
+
+// Int returns an int32 or int64 field's value.
+func (f Field) Int() (int64, error) { return int64(f.n), f.want(Varint) }
+
+// Bool returns a bool field's value.
+func (f Field) Bool() (bool, error) { return f.n != 0, f.want(Varint) }
+
+// Double returns a double field's value.
+func (f Field) Double() (float64, error) { return math.Float64frombits(f.n), f.want(Fixed64) }
+
+// Text returns a string field's value.
+func (f Field) Text() (string, error) { return string(f.data), f.want(Bytes) }
+
+// Message returns an embedded message field's encoding, for Each.
+func (f Field) Message() ([]byte, error) { return f.data, f.want(Bytes) }
+
+// WriteDelimited writes msg to w preceded by its length.
+func WriteDelimited(w io.Writer, msg []byte) error {
+	b := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(msg)), uint64(len(msg)))
+	_, err := w.Write(append(b, msg...))
+	return err
+}
+
+// EachDelimited calls fn with each length-prefixed message in r until
+// EOF. The slice is only valid until fn returns. The first error from fn
+// or from reading stops it and is returned with the message's position,
+// counting from 1.
+func EachDelimited(r io.Reader, fn func(msg []byte) error) error {
+	br := bufio.NewReader(r)
+	var buf []byte
+	for n := 1; ; n++ {
+		size, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("message %d: %w: %v", n, ErrInvalid, err)
+		}
+		if size > MaxMessageBytes {
+			return fmt.Errorf("message %d: %w: %d bytes, more than %d", n, ErrInvalid, size, MaxMessageBytes)
+		}
+		buf = slices.Grow(buf[:0], int(size))[:size]
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return fmt.Errorf("message %d: %w: %v", n, ErrInvalid, err)
+		}
+		if err := fn(buf); err != nil {
+			return fmt.Errorf("message %d: %w", n, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package protox
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"testing"
+)
+
+// The expected bytes are worked out from the encoding spec at
+// protobuf.dev/programming-guides/encoding.
+func TestAppend(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []byte
+		want string
+	}{
+		{"uint 150", AppendUint(nil, 1, 150), "089601"},
+		{"string", AppendString(nil, 2, "testing"), "120774657374696e67"},
+		{"negative int", AppendInt(nil, 3, -2), "18feffffffffffffffff01"},
+		{"bool", AppendBool(nil, 4, true), "2001"},
+		{"double", AppendDouble(nil, 5, 1), "29000000000000f03f"},
+		{"big field number", AppendUint(nil, 16, 1), "800101"},
+		{"message", AppendMessage(nil, 3, func(b []byte) []byte { return AppendUint(b, 1, 150) }), "1a03089601"},
+		{"empty message", AppendMessage(nil, 3, func(b []byte) []byte { return b }), "1a00"},
+		{"zero values", AppendBool(AppendDouble(AppendString(AppendInt(nil, 1, 0), 2, ""), 3, 0), 4, false), ""},
+		{"nil message", AppendMessage(nil, 3, nil), ""},
+	}
+	for _, tt := range tests {
+		if got := hex.EncodeToString(tt.got); got != tt.want {
+			t.Errorf("%s: %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEach(t *testing.T) {
+	var msg []byte
+	msg = AppendString(msg, 1, "MUG")
+	msg = AppendInt(msg, 2, -7)
+	msg = AppendDouble(msg, 3, math.Pi)
+	msg = AppendMessage(msg, 4, func(b []byte) []byte { return AppendBool(b, 1, true) })
+	msg = append(msg, 0x2d, 1, 2, 3, 4) // field 5, fixed32, which nothing reads
+
+	var (
+		s   string
+		i   int64
+		f   float64
+		ok  bool
+		num []int
+	)
+	err := Each(msg, func(fld Field) error {
+		num = append(num, fld.Num)
+		var err error
+		switch fld.Num {
+		case 1:
+			s, err = fld.Text()
+		case 2:
+			i, err = fld.Int()
+		case 3:
+			f, err = fld.Double()
+		case 4:
+			var sub []byte
+			if sub, err = fld.Message(); err == nil {
+				err = Each(sub, func(fld Field) (err error) { ok, err = fld.Bool(); return err })
+			}
+		}
+		return err
+	})
+	if err != nil || s != "MUG" || i != -7 || f != math.Pi || !ok || len(num) != 5 {
+		t.Fatalf("read %q %d %v %v %v, %v", s, i, f, ok, num, err)
+	}
+
+	for name, bad := range map[string][]byte{
+		"truncated string": AppendString(nil, 1, "MUG")[:3],
+		"truncated double": AppendDouble(nil, 1, 1)[:5],
+		"bad varint":       {0x08, 0xff},
+		"group":            {0x0b},
+		"field zero":       {0x00, 0x01},
+	} {
+		if err := Each(bad, func(Field) error { return nil }); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: Each = %v, want ErrInvalid", name, err)
+		}
+	}
+	// A known field with the wrong wire type is an error, not a zero.
+	err = Each(AppendUint(nil, 1, 5), func(f Field) error { _, err := f.Text(); return err })
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Text of a varint = %v", err)
+	}
+}
+
+func TestDelimited(t *testing.T) {
+	var buf bytes.Buffer
+	for _, s := range []string{"a", "", "ccc"} {
+		if err := WriteDelimited(&buf, AppendString(nil, 1, s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var sizes []int
+	err := EachDelimited(bytes.NewReader(buf.Bytes()), func(msg []byte) error {
+		sizes = append(sizes, len(msg))
+		return nil
+	})
+	if err != nil || len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 0 || sizes[2] != 5 {
+		t.Fatalf("sizes %v, %v", sizes, err)
+	}
+
+	truncated := buf.Bytes()[:buf.Len()-1]
+	if err := EachDelimited(bytes.NewReader(truncated), func([]byte) error { return nil }); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("truncated stream: %v", err)
+	}
+	huge := []byte{0xff, 0xff, 0xff, 0xff, 0x0f}
+	if err := EachDelimited(bytes.NewReader(huge), func([]byte) error { return nil }); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("oversized message: %v", err)
+	}
+}
@@ -0,0 +1,380 @@
+// Package query parses the query parameters of list endpoints and applies
+// them, so every list in the API pages, filters and sorts the same way:
+//
+//	GET /products?limit=20&offset=40&sort=-price,sku&price[gte]=5&status=active
+//
+// limit and offset select a page of the matching items. sort is a
+// comma-separated list of fields, each descending if prefixed with '-'.
+// Any other parameter named after a field filters on it: field=value for
+// equality, or field[op]=value with op one of eq, ne, lt, lte, gt, gte and,
+// for text, prefix. Filters on the same or different fields all have to
+// hold. Parameters that name no field are left to the endpoint.
+//
+// A Schema lists the fields of one item type, each with the accessor used
+// to filter and sort by it, in the style of export.Column. Parse checks
+// a request against it and Apply runs the resulting Query over a slice;
+// a backend that can do better than a full scan, such as one with an
+// index, can read the Query itself and pass Apply what it narrowed down.
+package query
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/pagination"
+)
+
+// ErrInvalid is wrapped by every error Parse and Apply return for a
+// query that does not fit the schema.
+var ErrInvalid = errors.New("query: invalid")
+
+// Op is a filter's comparison.
+type Op string
+
+// The comparisons. Prefix applies only to text.
+const (
+	Eq     Op = "eq"
+	Ne     Op = "ne"
+	Lt     Op = "lt"
+	Lte    Op = "lte"
+	Gt     Op = "gt"
+	Gte    Op = "gte"
+	Prefix Op = "prefix"
+)
+
+// Order is one sort key.
+type Order struct {
+	Field string
+	Desc  bool
+}
+
+// Filter is one condition an item must meet. Value is as written in the
+// request; the field parses it.
+type Filter struct {
+	Field string
+	Op    Op
+	Value string
+}
+
+// Query is a parsed list request. Limit 0 means no limit.
+type Query struct {
+	Limit   int
+	Offset  int
+	Sort    []Order
+	Filters []Filter
+}
+
+// Field is something items can be filtered and sorted by.
+type Field[T any] struct {
+	Name    string
+	compare func(a, b T) int
+	// match returns the predicate for op and a value from a request.
+	match func(op Op, value string) (func(T) bool, error)
+	// oneOf, if set, lists the only values filters may compare with.
+	oneOf []string
+}
+
+// OneOf returns f restricted to filters on the given values, for fields
+// such as a status where anything else is a mistake.
+func (f Field[T]) OneOf(values ...string) Field[T] {
+	f.oneOf = values
+	return f
+}
+
+var comparisons = []Op{Eq, Ne, Lt, Lte, Gt, Gte}
+
+// field builds a Field over values of type V, compared by compareV and
+// parsed from requests by parse.
+func field[T, V any](name string, get func(T) V, compareV func(a, b V) int, parse func(string) (V, error), ops ...Op) Field[T] {
+	return Field[T]{
+		Name:    name,
+		compare: func(a, b T) int { return compareV(get(a), get(b)) },
+		match: func(op Op, value string) (func(T) bool, error) {
+			if !slices.Contains(ops, op) {
+				return nil, fmt.Errorf("%w: %s does not support %s", ErrInvalid, name, op)
+			}
+			arg, err := parse(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %q: %v", ErrInvalid, name, value, err)
+			}
+			return func(item T) bool {
+				c := compareV(get(item), arg)
+				switch op {
+				case Eq:
+					return c == 0
+				case Ne:
+					return c != 0
+				case Lt:
+					return c < 0
+				case Lte:
+					return c <= 0
+				case Gt:
+					return c > 0
+				case Gte:
+					return c >= 0
+				}
+				return false
+			}, nil
+		},
+	}
+}
+
+// String returns a text field. Besides the comparisons, which are by
+// byte, it supports prefix.
+func String[T any](name string, get func(T) string) Field[T] {
+	f := field(name, get, strings.Compare, func(s string) (string, error) { return s, nil }, comparisons...)
+	match := f.match
+	f.match = func(op Op, value string) (func(T) bool, error) {
+		if op == Prefix {
+			return func(item T) bool { return strings.HasPrefix(get(item), value) }, nil
+		}
+		return match(op, value)
+	}
+	return f
+}
+
+// Float returns a numeric field.
+func Float[T any](name string, get func(T) float64) Field[T] {
+	return field(name, get, cmp.Compare[float64], func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	}, comparisons...)
+}
+
+// Int returns an integer field.
+func Int[T any](name string, get func(T) int) Field[T] {
+	return field(name, get, cmp.Compare[int], strconv.Atoi, comparisons...)
+}
+
+// Bool returns a true/false field, which supports eq and ne and sorts
+// false first.
+func Bool[T any](name string, get func(T) bool) Field[T] {
+	compare := func(a, b bool) int {
+		switch {
+		case a == b:
+			return 0
+		case a:
+			return 1
+		}
+		return -1
+	}
+	return field(name, get, compare, strconv.ParseBool, Eq, Ne)
+}
+
+// Time returns a time field, written in requests as RFC 3339.
+func Time[T any](name string, get func(T) time.Time) Field[T] {
+	return field(name, get, time.Time.Compare, func(s string) (time.Time, error) {
+		return time.Parse(time.RFC3339, s)
+	}, comparisons...)
+}
+
+// Schema describes the fields of one kind of item and the limits on
+// listing them.
+type Schema[T any] struct {
+	Fields []Field[T]
+	// DefaultLimit applies when a request has no limit; 0 means none.
+	DefaultLimit int
+	// MaxLimit caps limit; 0 means no cap.
+	MaxLimit int
+}
+
+func (s *Schema[T]) field(name string) (Field[T], bool) {
+	i := slices.IndexFunc(s.Fields, func(f Field[T]) bool { return f.Name == name })
+	if i < 0 {
+		return Field[T]{}, false
+	}
+	return s.Fields[i], true
+}
+
+// Parse reads a Query from a request's parameters and checks it against
+// s. Filters come out sorted by parameter name so a Query is the same
+// whatever order the parameters were in.
+func (s *Schema[T]) Parse(v url.Values) (Query, error) {
+	q := Query{Limit: s.DefaultLimit}
+	var err error
+	if q.Limit, err = intParam(v, "limit", q.Limit); err != nil {
+		return Query{}, err
+	}
+	if s.MaxLimit > 0 && (q.Limit > s.MaxLimit || q.Limit == 0) {
+		if v.Has("limit") {
+			return Query{}, fmt.Errorf("%w: limit must be from 1 to %d", ErrInvalid, s.MaxLimit)
+		}
+		q.Limit = s.MaxLimit
+	}
+	if q.Offset, err = intParam(v, "offset", 0); err != nil {
+		return Query{}, err
+	}
+	if sort := v.Get("sort"); sort != "" {
+		for key := range strings.SplitSeq(sort, ",") {
+			o := Order{Field: strings.TrimPrefix(key, "+")}
+			if name, ok := strings.CutPrefix(key, "-"); ok {
+				o = Order{Field: name, Desc: true}
+			}
+			if _, ok := s.field(o.Field); !ok {
+				return Query{}, fmt.Errorf("%w: cannot sort by %q", ErrInvalid, o.Field)
+			}
+			q.Sort = append(q.Sort, o)
+		}
+	}
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		name, op := key, Eq
+		if i := strings.IndexByte(key, '['); i > 0 && strings.HasSuffix(key, "]") {
+			name, op = key[:i], Op(key[i+1:len(key)-1])
+		}
+		if _, ok := s.field(name); !ok {
+			if op != Eq || key != name {
+				return Query{}, fmt.Errorf("%w: cannot filter by %q", ErrInvalid, name)
+			}
+			continue // another parameter of the endpoint's
+		}
+		for _, value := range v[key] {
+			q.Filters = append(q.Filters, Filter{Field: name, Op: op, Value: value})
+		}
+	}
+	if _, err := s.predicate(q); err != nil {
+		return Query{}, err
+	}
+	return q, nil
+}
+
+func intParam(v url.Values, name string, def int) (int, error) {
+	if !v.Has(name) {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v.Get(name))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: %s must be a whole number, got %q", ErrInvalid, name, v.Get(name))
+	}
+	return n, nil
+}
+
+// predicate compiles q's filters into one.
+func (s *Schema[T]) predicate(q Query) (func(T) bool, error) {
+	var preds []func(T) bool
+	for _, f := range q.Filters {
+		fld, ok := s.field(f.Field)
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot filter by %q", ErrInvalid, f.Field)
+		}
+		if fld.oneOf != nil && !slices.Contains(fld.oneOf, f.Value) {
+			return nil, fmt.Errorf("%w: %s must be one of %s, got %q", ErrInvalid, f.Field, strings.Join(fld.oneOf, ", "), f.Value)
+		}
+		p, err := fld.match(f.Op, f.Value)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, p)
+	}
+	return func(item T) bool {
+		for _, p := range preds {
+			if !p(item) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// Apply filters, sorts and pages items by q. It returns the page and how
+// many items matched in all, and leaves items alone. Without a sort the
+// items keep their order.
+func (s *Schema[T]) Apply(items []T, q Query) (page []T, total int, err error) {
+	match, err := s.predicate(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]T, 0, len(items))
+	for _, item := range items {
+		if match(item) {
+			out = append(out, item)
+		}
+	}
+	if len(q.Sort) > 0 {
+		compares := make([]func(a, b T) int, len(q.Sort))
+		for i, o := range q.Sort {
+			f, ok := s.field(o.Field)
+			if !ok {
+				return nil, 0, fmt.Errorf("%w: cannot sort by %q", ErrInvalid, o.Field)
+			}
+			compares[i] = f.compare
+			if o.Desc {
+				compares[i] = func(a, b T) int { return f.compare(b, a) }
+			}
+		}
+		slices.SortStableFunc(out, func(a, b T) int {
+			for _, c := range compares {
+				if r := c(a, b); r != 0 {
+					return r
+				}
+			}
+			return 0
+		})
+	}
+	total = len(out)
+	lo, hi := pagination.Window(total, q.Offset, q.Limit)
+	return out[lo:hi:hi], total, nil
+}
+
+// FloatRange returns the narrowest [lo, hi] the filters on a numeric field
+// allow, for a backend with an index on it. ok is false if no filter
+// bounds the field. Strict bounds come out inclusive, so the caller must
+// still Apply q to what the index returns.
+func (q Query) FloatRange(field string) (lo, hi float64, ok bool) {
+	lo, hi = -1e308, 1e308
+	for _, f := range q.Filters {
+		if f.Field != field {
+			continue
+		}
+		v, err := strconv.ParseFloat(f.Value, 64)
+		if err != nil {
+			continue
+		}
+		switch f.Op {
+		case Eq:
+			lo, hi, ok = max(lo, v), min(hi, v), true
+		case Gt, Gte:
+			lo, ok = max(lo, v), true
+		case Lt, Lte:
+			hi, ok = min(hi, v), true
+		}
+	}
+	return lo, hi, ok
+}
+
+// SetHeaders describes a page of a list response: X-Total-Count holds how
+// many items matched, and a Link header (RFC 8288) points at the next and
+// previous pages of r's URL, if there are any.
+func SetHeaders(w http.ResponseWriter, r *http.Request, q Query, total int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if q.Limit == 0 {
+		return
+	}
+	link := func(offset int, rel string) string {
+		u := *r.URL
+		v := u.Query()
+		v.Set("offset", strconv.Itoa(offset))
+		u.RawQuery = v.Encode()
+		return fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel)
+	}
+	var links []string
+	if q.Offset+q.Limit < total {
+		links = append(links, link(q.Offset+q.Limit, "next"))
+	}
+	if q.Offset > 0 {
+		links = append(links, link(max(q.Offset-q.Limit, 0), "prev"))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
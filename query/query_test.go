@@ -0,0 +1,171 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+type item struct {
+	Name  string
+	Price float64
+	Stock int
+	Sale  bool
+	Added time.Time
+}
+
+var schema = &Schema[item]{
+	Fields: []Field[item]{
+		String("name", func(i item) string { return i.Name }),
+		Float("price", func(i item) float64 { return i.Price }),
+		Int("stock", func(i item) int { return i.Stock }),
+		Bool("sale", func(i item) bool { return i.Sale }),
+		Time("added", func(i item) time.Time { return i.Added }),
+		String("size", func(i item) string { return "m" }).OneOf("s", "m", "l"),
+	},
+	MaxLimit: 50,
+}
+
+func day(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+
+var items = []item{
+	{"anvil", 40, 3, false, day(1)},
+	{"apron", 12, 0, true, day(4)},
+	{"bolt", 0.5, 900, false, day(2)},
+	{"brush", 12, 14, true, day(3)},
+	{"chisel", 18, 7, false, day(5)},
+}
+
+func names(is []item) []string {
+	var out []string
+	for _, i := range is {
+		out = append(out, i.Name)
+	}
+	return out
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		params string
+		want   []string
+		total  int
+	}{
+		{"", []string{"anvil", "apron", "bolt", "brush", "chisel"}, 5},
+		{"limit=2", []string{"anvil", "apron"}, 5},
+		{"limit=2&offset=4", []string{"chisel"}, 5},
+		{"offset=9", nil, 5},
+		{"sort=-price,name", []string{"anvil", "chisel", "apron", "brush", "bolt"}, 5},
+		{"sort=price,-name", []string{"bolt", "brush", "apron", "chisel", "anvil"}, 5},
+		{"sort=sale,added", []string{"anvil", "bolt", "chisel", "brush", "apron"}, 5},
+		{"price[gte]=12&price[lt]=40", []string{"apron", "brush", "chisel"}, 3},
+		{"price=12&sort=-stock", []string{"brush", "apron"}, 2},
+		{"name[prefix]=b", []string{"bolt", "brush"}, 2},
+		{"name[ne]=bolt&stock[gt]=5", []string{"brush", "chisel"}, 2},
+		{"sale=true", []string{"apron", "brush"}, 2},
+		{"added[lte]=2025-03-02T00:00:00Z", []string{"anvil", "bolt"}, 2},
+		{"size=m&limit=1&sort=-added", []string{"chisel"}, 5},
+		{"q=anything", []string{"anvil", "apron", "bolt", "brush", "chisel"}, 5}, // not ours
+	}
+	for _, tt := range tests {
+		v, _ := url.ParseQuery(tt.params)
+		q, err := schema.Parse(v)
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tt.params, err)
+			continue
+		}
+		page, total, err := schema.Apply(items, q)
+		if err != nil || !slices.Equal(names(page), tt.want) || total != tt.total {
+			t.Errorf("%s: %v of %d, %v; want %v of %d", tt.params, names(page), total, err, tt.want, tt.total)
+		}
+	}
+	if items[0].Name != "anvil" || items[4].Name != "chisel" {
+		t.Fatal("Apply reordered its input")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, params := range []string{
+		"limit=-1",
+		"limit=x",
+		"limit=51",
+		"limit=0",
+		"offset=1.5",
+		"sort=color",
+		"sort=-",
+		"color[eq]=red",
+		"price[prefix]=1",
+		"price=cheap",
+		"sale[gt]=true",
+		"added=yesterday",
+		"size=xl",
+		"name[like]=a",
+	} {
+		v, _ := url.ParseQuery(params)
+		if _, err := schema.Parse(v); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%s) err = %v, want ErrInvalid", params, err)
+		}
+	}
+	// A hand-built query is checked too.
+	if _, _, err := schema.Apply(items, Query{Sort: []Order{{Field: "color"}}}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Apply with an unknown sort: err = %v", err)
+	}
+	// Without a limit, MaxLimit applies.
+	if q, _ := schema.Parse(url.Values{}); q.Limit != 50 {
+		t.Errorf("default limit = %d, want MaxLimit", q.Limit)
+	}
+}
+
+func TestFloatRange(t *testing.T) {
+	tests := []struct {
+		params string
+		lo, hi float64
+		ok     bool
+	}{
+		{"", 0, 0, false},
+		{"name=x", 0, 0, false},
+		{"price[gte]=5&price[lt]=20", 5, 20, true},
+		{"price[gt]=5&price[gt]=8", 8, 1e308, true},
+		{"price=12&price[lte]=40", 12, 12, true},
+	}
+	for _, tt := range tests {
+		v, _ := url.ParseQuery(tt.params)
+		q, err := schema.Parse(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lo, hi, ok := q.FloatRange("price")
+		if ok != tt.ok || ok && (lo != tt.lo || hi != tt.hi) {
+			t.Errorf("%s: FloatRange = %v, %v, %v", tt.params, lo, hi, ok)
+		}
+	}
+}
+
+func TestSetHeaders(t *testing.T) {
+	tests := []struct {
+		target, link string
+	}{
+		{"/items?limit=2", `</items?limit=2&offset=2>; rel="next"`},
+		{"/items?limit=2&offset=2&sort=-price", `</items?limit=2&offset=4&sort=-price>; rel="next", </items?limit=2&offset=0&sort=-price>; rel="prev"`},
+		{"/items?limit=2&offset=4", `</items?limit=2&offset=2>; rel="prev"`},
+		{"/items?limit=10", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.target, nil)
+		q, err := schema.Parse(r.URL.Query())
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		SetHeaders(w, r, q, len(items))
+		if got := w.Header().Get("Link"); got != tt.link {
+			t.Errorf("%s: Link = %s, want %s", tt.target, got, tt.link)
+		}
+		if got := w.Header().Get("X-Total-Count"); got != fmt.Sprint(len(items)) {
+			t.Errorf("%s: X-Total-Count = %s", tt.target, got)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package ratelimit provides two rate limiters behind one interface.
+//
+// TokenBucket allows bursts up to a fixed size and refills at a steady
+// rate; it is cheap and holds constant state. SlidingWindowLog remembers
+// the time of every allowed event in the window, which makes it exact at
+// window boundaries at the cost of memory proportional to the limit.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+// Limiter decides whether an event may happen now.
+type Limiter interface {
+	// Allow reports whether an event may happen now, consuming capacity
+	// if so.
+	Allow() bool
+	// Wait blocks until an event may happen or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// Option configures a limiter.
+type Option func(*options)
+
+type options struct {
+	clock clock.Clock
+}
+
+// WithClock makes the limiter read time from c instead of the system clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+func buildOptions(opts []Option) options {
+	o := options{clock: clock.Real{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// wait blocks for d on clock, or until ctx is done.
+func wait(ctx context.Context, clk clock.Clock, d time.Duration) error {
+	select {
+	case <-clk.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Middleware rejects requests with 429 Too Many Requests when l does not
+// allow them. Limiters with a Delay method, such as both in this package,
+// also get a Retry-After header.
+func Middleware(l Limiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !l.Allow() {
+			if d, ok := l.(interface{ Delay() time.Duration }); ok {
+				secs := int(math.Ceil(d.Delay().Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(max(secs, 1)))
+			}
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
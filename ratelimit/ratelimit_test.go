@@ -0,0 +1,166 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+// newFakeClock returns a clock that advances whenever something waits on
+// it, so Wait returns immediately and tests can check how long it
+// "slept".
+func newFakeClock() *clock.Fake { return clock.NewAutoFake(time.Unix(1_000, 0)) }
+
+func allowN(l Limiter, n int) int {
+	allowed := 0
+	for range n {
+		if l.Allow() {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestTokenBucketBurstAndRefill(t *testing.T) {
+	clk := newFakeClock()
+	b := NewTokenBucket(2, 5, WithClock(clk))
+
+	if got := allowN(b, 10); got != 5 {
+		t.Fatalf("initial burst allowed %d, want 5", got)
+	}
+	clk.Advance(time.Second)
+	if got := allowN(b, 10); got != 2 {
+		t.Fatalf("after 1s allowed %d, want 2", got)
+	}
+	clk.Advance(time.Hour)
+	if got := allowN(b, 10); got != 5 {
+		t.Fatalf("after idle allowed %d, want burst of 5", got)
+	}
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	clk := newFakeClock()
+	b := NewTokenBucket(4, 1, WithClock(clk))
+
+	for range 3 {
+		if err := b.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// One token up front, then two more at 250ms each.
+	if clk.Slept() != 500*time.Millisecond {
+		t.Fatalf("slept %v, want 500ms", clk.Slept())
+	}
+}
+
+func TestSlidingWindowLog(t *testing.T) {
+	clk := newFakeClock()
+	l := NewSlidingWindowLog(3, time.Minute, WithClock(clk))
+
+	if got := allowN(l, 5); got != 3 {
+		t.Fatalf("allowed %d, want 3", got)
+	}
+	clk.Advance(59 * time.Second)
+	if l.Allow() {
+		t.Fatal("allowed before the window slid")
+	}
+	clk.Advance(time.Second)
+	if got := allowN(l, 5); got != 3 {
+		t.Fatalf("after window allowed %d, want 3", got)
+	}
+}
+
+func TestSlidingWindowLogIsExactAtBoundaries(t *testing.T) {
+	clk := newFakeClock()
+	l := NewSlidingWindowLog(2, 10*time.Second, WithClock(clk))
+
+	l.Allow()
+	clk.Advance(6 * time.Second)
+	l.Allow()
+	clk.Advance(5 * time.Second)
+	// The first event has left the window; the second has not.
+	if got := allowN(l, 3); got != 1 {
+		t.Fatalf("allowed %d, want 1", got)
+	}
+}
+
+func TestSlidingWindowLogWait(t *testing.T) {
+	clk := newFakeClock()
+	l := NewSlidingWindowLog(2, time.Second, WithClock(clk))
+	for range 4 {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if clk.Slept() != time.Second {
+		t.Fatalf("slept %v, want 1s", clk.Slept())
+	}
+}
+
+func TestWaitHonoursContext(t *testing.T) {
+	limiters := map[string]Limiter{
+		"TokenBucket":      NewTokenBucket(0.001, 1),
+		"SlidingWindowLog": NewSlidingWindowLog(1, time.Hour),
+	}
+	for name, l := range limiters {
+		t.Run(name, func(t *testing.T) {
+			l.Allow()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+			defer cancel()
+			if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("Wait() = %v, want DeadlineExceeded", err)
+			}
+		})
+	}
+}
+
+func TestConcurrentAllowNeverExceedsLimit(t *testing.T) {
+	clk := newFakeClock()
+	l := NewSlidingWindowLog(50, time.Minute, WithClock(clk))
+	var mu sync.Mutex
+	allowed := 0
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := allowN(l, 20)
+			mu.Lock()
+			allowed += n
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if allowed != 50 {
+		t.Fatalf("allowed %d, want 50", allowed)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	clk := newFakeClock()
+	l := NewTokenBucket(0.5, 1, WithClock(clk))
+	h := Middleware(l, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("first request: status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: status %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Fatalf("Retry-After = %q, want 2", got)
+	}
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+// SlidingWindowLog allows at most limit events in any window-long span.
+// It records each allowed event's time and forgets it once it falls out
+// of the window.
+type SlidingWindowLog struct {
+	mu     sync.Mutex
+	clock  clock.Clock
+	limit  int
+	window time.Duration
+	log    []time.Time // oldest first
+}
+
+var _ Limiter = (*SlidingWindowLog)(nil)
+
+// NewSlidingWindowLog returns a limiter allowing limit events per window.
+func NewSlidingWindowLog(limit int, window time.Duration, opts ...Option) *SlidingWindowLog {
+	if limit < 1 || window <= 0 {
+		panic("ratelimit: limit and window must be positive")
+	}
+	o := buildOptions(opts)
+	return &SlidingWindowLog{
+		clock:  o.clock,
+		limit:  limit,
+		window: window,
+		log:    make([]time.Time, 0, limit),
+	}
+}
+
+// prune drops events that have left the window. l.mu must be held.
+func (l *SlidingWindowLog) prune(now time.Time) {
+	cutoff := now.Add(-l.window)
+	i := 0
+	for i < len(l.log) && !l.log[i].After(cutoff) {
+		i++
+	}
+	l.log = append(l.log[:0], l.log[i:]...)
+}
+
+// Allow records an event if fewer than limit happened in the last window.
+func (l *SlidingWindowLog) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	l.prune(now)
+	if len(l.log) >= l.limit {
+		return false
+	}
+	l.log = append(l.log, now)
+	return true
+}
+
+// Delay returns how long until the oldest event leaves the window.
+func (l *SlidingWindowLog) Delay() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	l.prune(now)
+	return l.delay(now)
+}
+
+func (l *SlidingWindowLog) delay(now time.Time) time.Duration {
+	if len(l.log) < l.limit {
+		return 0
+	}
+	return l.log[0].Add(l.window).Sub(now)
+}
+
+// Wait blocks until an event is allowed and records it.
+func (l *SlidingWindowLog) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := l.clock.Now()
+		l.prune(now)
+		if len(l.log) < l.limit {
+			l.log = append(l.log, now)
+			l.mu.Unlock()
+			return nil
+		}
+		d := l.delay(now)
+		l.mu.Unlock()
+
+		if err := wait(ctx, l.clock, d); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+// TokenBucket holds up to burst tokens and gains rate tokens per second.
+// Each event spends one token.
+type TokenBucket struct {
+	mu     sync.Mutex
+	clock  clock.Clock
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+var _ Limiter = (*TokenBucket)(nil)
+
+// NewTokenBucket returns a full bucket. rate must be positive and burst at
+// least one.
+func NewTokenBucket(rate float64, burst int, opts ...Option) *TokenBucket {
+	if rate <= 0 || burst < 1 {
+		panic("ratelimit: rate must be positive and burst at least 1")
+	}
+	o := buildOptions(opts)
+	return &TokenBucket{
+		clock:  o.clock,
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   o.clock.Now(),
+	}
+}
+
+// refill credits the tokens earned since the last call. b.mu must be held.
+func (b *TokenBucket) refill() {
+	now := b.clock.Now()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+	}
+	b.last = now
+}
+
+// Allow spends a token if one is available.
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Delay returns how long until a token will be available.
+func (b *TokenBucket) Delay() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	return b.delay()
+}
+
+func (b *TokenBucket) delay() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// Wait blocks until a token can be spent and spends it.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		b.refill()
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		d := b.delay()
+		b.mu.Unlock()
+
+		// Another waiter may take the token first, so loop and re-check.
+		if err := wait(ctx, b.clock, d); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Package reflectutil walks and prints arbitrary Go values with reflect.
+//
+// Walk visits every node of a value, following pointers, interfaces,
+// struct fields, slices, arrays and maps, and names each node with a Go
+// selector path such as Config.TLS.CertFile or Items[2] or
+// Labels["env"]. Map keys are visited in sorted order and pointer cycles
+// are visited once, so output built from Walk is stable. DeepPrint is
+// built that way.
+package reflectutil
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SkipChildren may be returned by a Visitor to skip the children of the
+// current node.
+var SkipChildren = errors.New("skip children")
+
+// Visitor is called for every node. depth is 0 for the root.
+type Visitor func(path string, depth int, v reflect.Value) error
+
+// Walk calls fn for x and every value reachable from it. Unexported
+// struct fields are visited; their values can be inspected but not
+// extracted with Interface.
+func Walk(x any, fn Visitor) error {
+	w := walker{fn: fn, seen: make(map[uintptr]bool)}
+	return w.walk("", 0, reflect.ValueOf(x))
+}
+
+type walker struct {
+	fn   Visitor
+	seen map[uintptr]bool // pointers already entered, to stop at cycles
+}
+
+func (w *walker) walk(path string, depth int, v reflect.Value) error {
+	if err := w.fn(path, depth, v); err != nil {
+		if err == SkipChildren {
+			return nil
+		}
+		return err
+	}
+	return w.children(path, depth, v)
+}
+
+// children walks what v contains. Pointers and interfaces are looked
+// through without another visit: *T and T are the same node to a reader.
+func (w *walker) children(path string, depth int, v reflect.Value) error {
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || w.seen[v.Pointer()] {
+			return nil
+		}
+		w.seen[v.Pointer()] = true
+		defer delete(w.seen, v.Pointer())
+		return w.children(path, depth, v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return w.children(path, depth, v.Elem())
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			if err := w.walk(join(path, t.Field(i).Name), depth+1, v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := w.walk(path+"["+strconv.Itoa(i)+"]", depth+1, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, k := range sortedKeys(v) {
+			if err := w.walk(path+"["+formatKey(k)+"]", depth+1, v.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func formatKey(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return strconv.Quote(k.String())
+	}
+	return scalar(k)
+}
+
+// sortedKeys orders map keys by kind-appropriate comparison, falling back
+// to their printed form.
+func sortedKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		switch a.Kind() {
+		case reflect.String:
+			return cmp.Compare(a.String(), b.String())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return cmp.Compare(a.Int(), b.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return cmp.Compare(a.Uint(), b.Uint())
+		case reflect.Float32, reflect.Float64:
+			return cmp.Compare(a.Float(), b.Float())
+		}
+		return cmp.Compare(scalar(a), scalar(b))
+	})
+	return keys
+}
+
+// scalar formats a leaf value without calling Interface, so it works on
+// unexported fields too.
+func scalar(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits())
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		if v.IsNil() {
+			return "nil"
+		}
+		return v.Type().String()
+	}
+	return v.Type().String()
+}
+
+// DeepPrint renders x as an indented tree with one node per line. Nil
+// pointers, interfaces, slices and maps print as nil; map keys are sorted
+// and a pointer back to an ancestor prints as <cycle>, so the output is
+// deterministic and safe to compare in tests.
+func DeepPrint(x any) string {
+	var b strings.Builder
+	p := printer{b: &b, seen: make(map[uintptr]bool)}
+	p.print(reflect.ValueOf(x), 0)
+	return b.String()
+}
+
+type printer struct {
+	b    *strings.Builder
+	seen map[uintptr]bool
+}
+
+func (p *printer) line(depth int, format string, args ...any) {
+	p.b.WriteString(strings.Repeat("  ", depth))
+	fmt.Fprintf(p.b, format, args...)
+	p.b.WriteByte('\n')
+}
+
+// print writes v's value; the caller has already written any label.
+func (p *printer) print(v reflect.Value, depth int) {
+	if !v.IsValid() {
+		p.b.WriteString("nil\n")
+		return
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			p.b.WriteString("nil\n")
+			return
+		}
+		if p.seen[v.Pointer()] {
+			p.b.WriteString("<cycle>\n")
+			return
+		}
+		p.seen[v.Pointer()] = true
+		defer delete(p.seen, v.Pointer())
+		p.b.WriteString("&")
+		p.print(v.Elem(), depth)
+	case reflect.Interface:
+		if v.IsNil() {
+			p.b.WriteString("nil\n")
+			return
+		}
+		p.print(v.Elem(), depth)
+	case reflect.Struct:
+		t := v.Type()
+		p.b.WriteString(t.String() + " {\n")
+		for i := range t.NumField() {
+			p.b.WriteString(strings.Repeat("  ", depth+1) + t.Field(i).Name + ": ")
+			p.print(v.Field(i), depth+1)
+		}
+		p.line(depth, "}")
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			p.b.WriteString("nil\n")
+			return
+		}
+		if v.Len() == 0 {
+			p.b.WriteString(v.Type().String() + " {}\n")
+			return
+		}
+		p.b.WriteString(v.Type().String() + " {\n")
+		for i := range v.Len() {
+			p.b.WriteString(strings.Repeat("  ", depth+1) + strconv.Itoa(i) + ": ")
+			p.print(v.Index(i), depth+1)
+		}
+		p.line(depth, "}")
+	case reflect.Map:
+		if v.IsNil() {
+			p.b.WriteString("nil\n")
+			return
+		}
+		if v.Len() == 0 {
+			p.b.WriteString(v.Type().String() + " {}\n")
+			return
+		}
+		p.b.WriteString(v.Type().String() + " {\n")
+		for _, k := range sortedKeys(v) {
+			p.b.WriteString(strings.Repeat("  ", depth+1) + formatKey(k) + ": ")
+			p.print(v.MapIndex(k), depth+1)
+		}
+		p.line(depth, "}")
+	default:
+		p.b.WriteString(scalar(v) + "\n")
+	}
+}
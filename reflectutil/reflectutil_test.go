@@ -0,0 +1,173 @@
+package reflectutil
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stawuah/pounce-on-go/nilsafe"
+)
+
+func TestWalkPaths(t *testing.T) {
+	s := &nilsafe.Server{
+		Name:   "api",
+		Config: &nilsafe.Config{Host: "localhost", Port: 8080},
+	}
+	var paths []string
+	err := Walk(s, func(path string, _ int, v reflect.Value) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"", "Name", "Config", "Config.Host", "Config.Port", "Config.TLS", "Metrics"}
+	if !slices.Equal(paths, want) {
+		t.Fatalf("paths = %q, want %q", paths, want)
+	}
+}
+
+func TestWalkCollectionsSorted(t *testing.T) {
+	v := map[string][]int{"b": {1, 2}, "a": {3}}
+	var paths []string
+	Walk(v, func(path string, _ int, _ reflect.Value) error {
+		paths = append(paths, path)
+		return nil
+	})
+	want := []string{"", `["a"]`, `["a"][0]`, `["b"]`, `["b"][0]`, `["b"][1]`}
+	if !slices.Equal(paths, want) {
+		t.Fatalf("paths = %q, want %q", paths, want)
+	}
+}
+
+func TestWalkSkipAndStop(t *testing.T) {
+	s := nilsafe.Server{Config: &nilsafe.Config{TLS: &nilsafe.TLS{}}}
+	var paths []string
+	Walk(s, func(path string, _ int, _ reflect.Value) error {
+		paths = append(paths, path)
+		if path == "Config" {
+			return SkipChildren
+		}
+		return nil
+	})
+	if slices.Contains(paths, "Config.Host") || !slices.Contains(paths, "Metrics") {
+		t.Fatalf("SkipChildren: paths = %q", paths)
+	}
+
+	stop := errors.New("stop")
+	err := Walk(s, func(path string, _ int, _ reflect.Value) error {
+		if path == "Config.Port" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("Walk = %v, want the visitor's error", err)
+	}
+}
+
+type node struct {
+	Name string
+	Next *node
+}
+
+func TestCycles(t *testing.T) {
+	a := &node{Name: "a"}
+	a.Next = &node{Name: "b", Next: a}
+
+	var n int
+	if err := Walk(a, func(string, int, reflect.Value) error { n++; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	// a, a.Name, a.Next, b.Name, b.Next (not entered again)
+	if n != 5 {
+		t.Fatalf("visited %d nodes, want 5", n)
+	}
+	if out := DeepPrint(a); !strings.Contains(out, "Next: <cycle>") {
+		t.Fatalf("DeepPrint did not mark the cycle:\n%s", out)
+	}
+}
+
+func TestDeepPrint(t *testing.T) {
+	type wrapper struct {
+		Server *nilsafe.Server
+		Tags   map[string]int
+		Empty  []string
+		List   []string
+		Any    any
+		secret string
+	}
+	w := wrapper{
+		Server: &nilsafe.Server{Name: "api", Config: &nilsafe.Config{Host: "h", Port: 1}},
+		Tags:   map[string]int{"z": 1, "a": 2},
+		List:   []string{},
+		secret: "s",
+	}
+	want := `reflectutil.wrapper {
+  Server: &nilsafe.Server {
+    Name: "api"
+    Config: &nilsafe.Config {
+      Host: "h"
+      Port: 1
+      TLS: nil
+    }
+    Metrics: nil
+  }
+  Tags: map[string]int {
+    "a": 2
+    "z": 1
+  }
+  Empty: nil
+  List: []string {}
+  Any: nil
+  secret: "s"
+}
+`
+	if got := DeepPrint(w); got != want {
+		t.Fatalf("DeepPrint =\n%s\nwant\n%s", got, want)
+	}
+	if got := DeepPrint(nil); got != "nil\n" {
+		t.Fatalf("DeepPrint(nil) = %q", got)
+	}
+}
+
+func TestFieldsWithTag(t *testing.T) {
+	type Base struct {
+		ID    string  `db:"id"`
+		Price float64 `db:"price"`
+	}
+	type Audit struct {
+		CreatedBy string `db:"created_by"`
+	}
+	type record struct {
+		Base
+		*Audit
+		Note   string `db:"note,omitempty"`
+		Hidden string `db:"-"`
+		Plain  string
+		secret string `db:"secret"`
+	}
+
+	fields := FieldsWithTag(reflect.TypeFor[*record](), "db")
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.Tag)
+	}
+	if want := []string{"id", "price", "created_by", "note"}; !slices.Equal(names, want) {
+		t.Fatalf("tags = %q, want %q", names, want)
+	}
+
+	note := fields[3]
+	if !slices.Equal(note.Opts, []string{"omitempty"}) {
+		t.Fatalf("note options = %q", note.Opts)
+	}
+	r := record{Base: Base{Price: 9.5}, secret: "unused"}
+	if got := reflect.ValueOf(r).FieldByIndex(fields[1].Index).Float(); got != 9.5 {
+		t.Fatalf("price via Index = %v", got)
+	}
+	if FieldsWithTag(reflect.TypeFor[int](), "db") != nil {
+		t.Fatal("non-struct type returned fields")
+	}
+}
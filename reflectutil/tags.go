@@ -0,0 +1,62 @@
+package reflectutil
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Field is a struct field carrying a given tag.
+type Field struct {
+	Name  string // Go field name; promoted fields use the inner name
+	Index []int  // for reflect.Value.FieldByIndex
+	Type  reflect.Type
+	Tag   string // the tag value's first comma-separated element
+	Opts  []string
+}
+
+// FieldsWithTag returns the exported fields of struct type t (or a
+// pointer to one) that have tag key, in declaration order. Fields of
+// embedded structs are promoted as encoding/json does. A tag value of "-"
+// excludes the field.
+//
+// A validator would call it with "validate" and an API schema generator
+// with "json"; both then read values through Index.
+func FieldsWithTag(t reflect.Type, key string) []Field {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var out []Field
+	collect(t, key, nil, &out)
+	return out
+}
+
+func collect(t reflect.Type, key string, index []int, out *[]Field) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		idx := append(append([]int(nil), index...), i)
+		tag, hasTag := f.Tag.Lookup(key)
+
+		if f.Anonymous && !hasTag {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collect(ft, key, idx, out)
+				continue
+			}
+		}
+		if !f.IsExported() || !hasTag || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		field := Field{Name: f.Name, Index: idx, Type: f.Type, Tag: name}
+		if opts != "" {
+			field.Opts = strings.Split(opts, ",")
+		}
+		*out = append(*out, field)
+	}
+}
@@ -0,0 +1,202 @@
+// Package reporting builds summaries of sales and of the product catalog
+// and renders them as plain text, for email and terminals, or as HTML.
+//
+// Building and rendering are separate: Daily and Products compute a
+// report value with the sliceutil helpers (GroupBy to total each product
+// or status, TopK for the best sellers), and its WriteText and WriteHTML
+// methods execute the embedded templates. Text uses text/template and HTML
+// uses html/template, so product names are escaped in the HTML report and
+// left alone in the text one. Templates are parsed once at startup.
+package reporting
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/catalog"
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/product"
+	"github.com/stawuah/pounce-on-go/set"
+	"github.com/stawuah/pounce-on-go/sliceutil"
+)
+
+//go:embed templates
+var files embed.FS
+
+var funcs = map[string]any{
+	"currency": catalog.Currency,
+	"date":     func(t time.Time) string { return t.Format("Monday 2 January 2006") },
+	"rule":     func(s string) string { return strings.Repeat("=", len([]rune(s))) },
+}
+
+// text holds the text templates, one per report, named after their files.
+var text = template.Must(template.New("").Funcs(funcs).ParseFS(files, "templates/*.txt"))
+
+// html holds one template set per report, each a clone of the layout, as
+// in package catalog.
+var html = func() map[string]*htmltemplate.Template {
+	layout := htmltemplate.Must(htmltemplate.New("").Funcs(funcs).ParseFS(files, "templates/layout.html"))
+	out := make(map[string]*htmltemplate.Template)
+	for _, name := range []string{"daily", "products"} {
+		t := htmltemplate.Must(layout.Clone())
+		out[name] = htmltemplate.Must(t.ParseFS(files, "templates/"+name+".html"))
+	}
+	return out
+}()
+
+// Sale is one line of an order: Quantity units of a product at Price
+// each.
+type Sale struct {
+	Order    string
+	SKU      string
+	Name     string
+	Quantity int
+	Price    float64
+	At       time.Time
+}
+
+// Amount is what the line was worth.
+func (s Sale) Amount() float64 { return float64(s.Quantity) * s.Price }
+
+// Line totals one product's sales.
+type Line struct {
+	SKU     string
+	Name    string
+	Orders  int
+	Units   int
+	Revenue float64
+}
+
+// DailySales summarises one day's sales.
+type DailySales struct {
+	Title   string
+	Day     time.Time // midnight at the start of the day
+	Orders  int
+	Units   int
+	Revenue float64
+	// Products is how many different products sold.
+	Products int
+	// Top holds the best sellers by revenue, highest first.
+	Top []Line
+}
+
+// Daily summarises the sales made on day, a calendar day in day's
+// location, listing at most top products.
+func Daily(sales []Sale, day time.Time, top int) *DailySales {
+	y, m, d := day.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1) // not 24h later: a day with a DST change is not 24h long
+	r := &DailySales{Title: "Daily sales", Day: start}
+	orders := set.New[string]()
+	var lines []Line
+	bySKU := sliceutil.GroupBy(sales, func(s Sale) string { return s.SKU })
+	for _, sku := range slices.Sorted(maps.Keys(bySKU)) {
+		l := Line{SKU: sku}
+		lineOrders := set.New[string]()
+		for _, s := range bySKU[sku] {
+			if s.At.Before(start) || !s.At.Before(end) {
+				continue
+			}
+			l.Name = s.Name
+			l.Units += s.Quantity
+			l.Revenue += s.Amount()
+			lineOrders.Add(s.Order)
+			orders.Add(s.Order)
+		}
+		if l.Units == 0 {
+			continue
+		}
+		l.Orders = len(lineOrders)
+		r.Units += l.Units
+		r.Revenue += l.Revenue
+		lines = append(lines, l)
+	}
+	r.Orders = len(orders)
+	r.Products = len(lines)
+	r.Top = sliceutil.TopK(lines, top, func(a, b Line) bool {
+		if a.Revenue != b.Revenue {
+			return a.Revenue < b.Revenue
+		}
+		return a.SKU > b.SKU // ties list in SKU order
+	})
+	return r
+}
+
+// WriteText writes the report as plain text.
+func (r *DailySales) WriteText(w io.Writer) error { return writeText(w, "daily.txt", r) }
+
+// WriteHTML writes the report as an HTML page.
+func (r *DailySales) WriteHTML(w io.Writer) error { return writeHTML(w, "daily", r) }
+
+// StatusCount is how many products have a status.
+type StatusCount struct {
+	Status string
+	Count  int
+}
+
+// ProductSummary summarises the catalog.
+type ProductSummary struct {
+	Title    string
+	Count    int
+	Value    float64 // the sum of the prices
+	ByStatus []StatusCount
+	// Priciest holds the most expensive products, highest first.
+	Priciest []product.Product
+}
+
+// Products summarises ps, listing at most top of the priciest.
+func Products(ps []product.Product, top int) *ProductSummary {
+	r := &ProductSummary{Title: "Product catalog", Count: len(ps)}
+	for _, p := range ps {
+		r.Value += p.Price
+	}
+	byStatus := sliceutil.GroupBy(ps, func(p product.Product) jsonx.ProductStatus { return p.Status })
+	for _, s := range slices.Sorted(maps.Keys(byStatus)) {
+		name := "unset"
+		if s != 0 {
+			name = s.String()
+		}
+		r.ByStatus = append(r.ByStatus, StatusCount{Status: name, Count: len(byStatus[s])})
+	}
+	r.Priciest = sliceutil.TopK(ps, top, func(a, b product.Product) bool {
+		if a.Price != b.Price {
+			return a.Price < b.Price
+		}
+		return a.SKU > b.SKU
+	})
+	return r
+}
+
+// WriteText writes the summary as plain text.
+func (r *ProductSummary) WriteText(w io.Writer) error { return writeText(w, "products.txt", r) }
+
+// WriteHTML writes the summary as an HTML page.
+func (r *ProductSummary) WriteHTML(w io.Writer) error { return writeHTML(w, "products", r) }
+
+// writeText and writeHTML render into a buffer first, so a template error
+// writes nothing rather than half a report.
+func writeText(w io.Writer, name string, data any) error {
+	var buf bytes.Buffer
+	if err := text.ExecuteTemplate(&buf, name, data); err != nil {
+		return fmt.Errorf("reporting: %w", err)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func writeHTML(w io.Writer, name string, data any) error {
+	var buf bytes.Buffer
+	if err := html[name].ExecuteTemplate(&buf, "layout", data); err != nil {
+		return fmt.Errorf("reporting: %w", err)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,83 @@
+package reporting
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/golden"
+	"github.com/stawuah/pounce-on-go/jsonx"
+	"github.com/stawuah/pounce-on-go/product"
+)
+
+var day = time.Date(2025, 3, 7, 15, 0, 0, 0, time.UTC)
+
+func at(hour int) time.Time { return time.Date(2025, 3, 7, hour, 30, 0, 0, time.UTC) }
+
+var sales = []Sale{
+	{Order: "o-1", SKU: "mug-1", Name: "Cat mug", Quantity: 2, Price: 12.5, At: at(9)},
+	{Order: "o-1", SKU: "tee-2", Name: "Tabby <tee>", Quantity: 1, Price: 37.5, At: at(9)},
+	{Order: "o-2", SKU: "mug-1", Name: "Cat mug", Quantity: 1, Price: 12.5, At: at(11)},
+	{Order: "o-3", SKU: "bed-9", Name: "Deluxe bed & blanket", Quantity: 1, Price: 1250, At: at(23)},
+	{Order: "o-4", SKU: "toy-3", Name: "Feather wand", Quantity: 3, Price: 4, At: at(14)},
+	{Order: "o-4", SKU: "box-5", Name: "Scratch box", Quantity: 2, Price: 6, At: at(14)},
+	// Outside the day.
+	{Order: "o-0", SKU: "mug-1", Name: "Cat mug", Quantity: 9, Price: 12.5, At: at(9).AddDate(0, 0, -1)},
+	{Order: "o-5", SKU: "pen-7", Name: "Pen", Quantity: 1, Price: 2, At: at(0).AddDate(0, 0, 1).Add(-30 * time.Minute)},
+}
+
+func TestDaily(t *testing.T) {
+	r := Daily(sales, day, 3)
+	if r.Orders != 4 || r.Units != 10 || r.Revenue != 1349 || r.Products != 5 {
+		t.Fatalf("totals = %d orders, %d units, %v revenue, %d products", r.Orders, r.Units, r.Revenue, r.Products)
+	}
+	var skus []string
+	for _, l := range r.Top {
+		skus = append(skus, l.SKU)
+	}
+	// mug-1 and tee-2 tie on revenue; ties list in SKU order.
+	if len(skus) != 3 || skus[0] != "bed-9" || skus[1] != "mug-1" || skus[2] != "tee-2" {
+		t.Fatalf("Top = %v", skus)
+	}
+	if r.Top[1].Orders != 2 || r.Top[1].Units != 3 {
+		t.Fatalf("mug-1 = %+v", r.Top[1])
+	}
+
+	// The day is a calendar day where it is asked for: 23:30 UTC on the
+	// 7th is the 8th in Tokyo.
+	tokyo := time.FixedZone("JST", 9*60*60)
+	if r := Daily(sales, day.In(tokyo), 3); r.Orders != 2 || r.Day.Day() != 8 {
+		t.Fatalf("Tokyo: %d orders on %v", r.Orders, r.Day)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	products := []product.Product{
+		{SKU: "bed-9", Name: "Deluxe bed & blanket", Price: 1250, Status: jsonx.StatusActive},
+		{SKU: "mug-1", Name: "Cat mug", Price: 12.5, Status: jsonx.StatusActive},
+		{SKU: "tee-2", Name: "Tabby <tee>", Price: 30, Status: jsonx.StatusDraft},
+		{SKU: "toy-3", Name: "Feather wand", Price: 4},
+		{SKU: "old-1", Name: "Laser pointer", Price: 30, Status: jsonx.StatusDiscontinued},
+	}
+	tests := []struct {
+		name       string
+		text, html func(io.Writer) error
+	}{
+		{"daily", Daily(sales, day, 3).WriteText, Daily(sales, day, 3).WriteHTML},
+		{"daily-empty", Daily(nil, day, 3).WriteText, Daily(nil, day, 3).WriteHTML},
+		{"products", Products(products, 3).WriteText, Products(products, 3).WriteHTML},
+		{"products-empty", Products(nil, 3).WriteText, Products(nil, 3).WriteHTML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for ext, write := range map[string]func(io.Writer) error{"txt": tt.text, "html": tt.html} {
+				var buf bytes.Buffer
+				if err := write(&buf); err != nil {
+					t.Fatal(err)
+				}
+				golden.Assert(t, tt.name+"."+ext, buf.Bytes())
+			}
+		})
+	}
+}
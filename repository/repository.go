@@ -0,0 +1,244 @@
+// Package repository stores entities by ID. A Repository keeps every
+// entity in memory and, if opened on a file, also writes them all to it
+// on each change, in the format of a pluggable Codec. It is the CRUD the
+// product, order and job stores have in common; each of them adds its
+// own indexes, errors and rules on top.
+//
+// Rewriting the whole file on every change suits the hundreds or
+// thousands of entities these stores hold, not millions.
+package repository
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/stawuah/pounce-on-go/fileio"
+)
+
+// A Codec writes and reads a file of entities.
+type Codec[T any] interface {
+	Encode(w io.Writer, items iter.Seq[T]) error
+	Decode(r io.Reader, fn func(T) error) error
+}
+
+// JSONLines is a Codec writing one JSON value per line, as package fileio
+// does. It is the default.
+type JSONLines[T any] struct{}
+
+// Encode writes items as NDJSON.
+func (JSONLines[T]) Encode(w io.Writer, items iter.Seq[T]) error { return fileio.EncodeLines(w, items) }
+
+// Decode reads NDJSON, passing each value to fn.
+func (JSONLines[T]) Decode(r io.Reader, fn func(T) error) error { return fileio.DecodeLines(r, fn) }
+
+// Gob is a Codec writing a gob stream of values: smaller and faster than
+// JSON, but only Go can read it.
+type Gob[T any] struct{}
+
+// Encode writes items as one gob stream.
+func (Gob[T]) Encode(w io.Writer, items iter.Seq[T]) error {
+	enc := gob.NewEncoder(w)
+	for v := range items {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode reads a gob stream, passing each value to fn.
+func (Gob[T]) Decode(r io.Reader, fn func(T) error) error {
+	dec := gob.NewDecoder(r)
+	for {
+		var v T
+		if err := dec.Decode(&v); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+}
+
+// Repository holds entities of type T keyed by an ID each carries. It is
+// safe for concurrent use.
+type Repository[ID comparable, T any] struct {
+	id      func(T) ID
+	compare func(a, b T) int
+	codec   Codec[T]
+	path    string // empty keeps the entities in memory only
+	seq     uint64 // orders locking when a Unit commits to several
+
+	mu    sync.RWMutex
+	items map[ID]T
+	// revs holds the revision of each ID's last change, so a Unit can
+	// tell whether what it read is still current.
+	revs map[ID]uint64
+	rev  uint64
+}
+
+// repositories numbers repositories for Unit's lock order.
+var repositories atomic.Uint64
+
+// Option configures a Repository.
+type Option[ID comparable, T any] func(*Repository[ID, T])
+
+// WithOrder sets the order All returns entities in and a file holds them
+// in. Without it the order is unspecified.
+func WithOrder[ID comparable, T any](compare func(a, b T) int) Option[ID, T] {
+	return func(r *Repository[ID, T]) { r.compare = compare }
+}
+
+// WithCodec sets the file format; the default is JSONLines.
+func WithCodec[ID comparable, T any](c Codec[T]) Option[ID, T] {
+	return func(r *Repository[ID, T]) { r.codec = c }
+}
+
+// New returns an empty repository kept in memory, which finds each
+// entity's ID with id.
+func New[ID comparable, T any](id func(T) ID, opts ...Option[ID, T]) *Repository[ID, T] {
+	r := &Repository[ID, T]{
+		id:    id,
+		codec: JSONLines[T]{},
+		seq:   repositories.Add(1),
+		items: make(map[ID]T),
+		revs:  make(map[ID]uint64),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Open returns a repository kept in the file at path, loading what it
+// holds. A missing file is an empty repository; it is created on the
+// first change. Every change rewrites the file with fileio.WriteAtomic,
+// so a crash leaves the state before or after the change, never part of
+// it.
+func Open[ID comparable, T any](path string, id func(T) ID, opts ...Option[ID, T]) (*Repository[ID, T], error) {
+	r := New(id, opts...)
+	r.path = path
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("repository: %w", err)
+	}
+	defer f.Close()
+	err = r.codec.Decode(f, func(v T) error {
+		r.items[r.id(v)] = v
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository: %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Get returns the entity with id.
+func (r *Repository[ID, T]) Get(id ID) (T, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v, ok := r.items[id]
+	return v, ok
+}
+
+// Put stores v, replacing the entity with its ID. If the file cannot be
+// written the repository is left as it was.
+func (r *Repository[ID, T]) Put(v T) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	undo := r.set(r.id(v), v, true)
+	if err := r.save(); err != nil {
+		undo()
+		return err
+	}
+	return nil
+}
+
+// Delete removes the entity with id, if there is one.
+func (r *Repository[ID, T]) Delete(id ID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, existed := r.items[id]; !existed {
+		return nil
+	}
+	undo := r.set(id, *new(T), false)
+	if err := r.save(); err != nil {
+		undo()
+		return err
+	}
+	return nil
+}
+
+// set stores v under id, or deletes id if !keep, and returns a function
+// that puts things back as they were. r.mu must be held.
+func (r *Repository[ID, T]) set(id ID, v T, keep bool) (undo func()) {
+	old, existed := r.items[id]
+	oldRev, hadRev := r.revs[id]
+	if keep {
+		r.items[id] = v
+	} else {
+		delete(r.items, id)
+	}
+	r.rev++
+	r.revs[id] = r.rev
+	return func() {
+		if existed {
+			r.items[id] = old
+		} else {
+			delete(r.items, id)
+		}
+		if hadRev {
+			r.revs[id] = oldRev
+		} else {
+			delete(r.revs, id)
+		}
+	}
+}
+
+// All returns every entity, in the WithOrder order if there is one.
+func (r *Repository[ID, T]) All() []T {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sorted()
+}
+
+// Len returns how many entities there are.
+func (r *Repository[ID, T]) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.items)
+}
+
+func (r *Repository[ID, T]) sorted() []T {
+	if r.compare == nil {
+		return slices.Collect(maps.Values(r.items))
+	}
+	return slices.SortedFunc(maps.Values(r.items), r.compare)
+}
+
+// save writes every entity to the file, if there is one.
+func (r *Repository[ID, T]) save() error {
+	if r.path == "" {
+		return nil
+	}
+	items := r.sorted()
+	err := fileio.WriteAtomic(r.path, 0o600, func(w io.Writer) error {
+		return r.codec.Encode(w, slices.Values(items))
+	})
+	if err != nil {
+		return fmt.Errorf("repository: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type user struct {
+	ID    int
+	Email string
+}
+
+func userID(u user) int { return u.ID }
+
+var byEmail = WithOrder[int](func(a, b user) int { return strings.Compare(a.Email, b.Email) })
+
+func TestMemory(t *testing.T) {
+	r := New(userID, byEmail)
+	for _, u := range []user{{1, "cy@example.com"}, {2, "al@example.com"}, {3, "bo@example.com"}} {
+		if err := r.Put(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.Put(user{1, "di@example.com"})
+	if err := r.Delete(3); err != nil || r.Delete(99) != nil {
+		t.Fatal("Delete failed")
+	}
+	if got, ok := r.Get(1); !ok || got.Email != "di@example.com" {
+		t.Fatalf("Get(1) = %v, %v", got, ok)
+	}
+	if _, ok := r.Get(3); ok {
+		t.Fatal("deleted user still there")
+	}
+	if got := r.All(); !slices.Equal(got, []user{{2, "al@example.com"}, {1, "di@example.com"}}) || r.Len() != 2 {
+		t.Fatalf("All = %v", got)
+	}
+}
+
+func TestFile(t *testing.T) {
+	for name, codec := range map[string]Codec[user]{"json": JSONLines[user]{}, "gob": Gob[user]{}} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "users")
+			r, err := Open(path, userID, byEmail, WithCodec[int](codec))
+			if err != nil || r.Len() != 0 {
+				t.Fatalf("Open missing file = %v, %v", r, err)
+			}
+			r.Put(user{1, "bo@example.com"})
+			r.Put(user{2, "al@example.com"})
+			r.Put(user{3, "cy@example.com"})
+			r.Delete(3)
+
+			again, err := Open(path, userID, byEmail, WithCodec[int](codec))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := again.All(); !slices.Equal(got, []user{{2, "al@example.com"}, {1, "bo@example.com"}}) {
+				t.Fatalf("reopened = %v", got)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "users.ndjson")
+	r, _ := Open(path, userID)
+	r.Put(user{1, "al@example.com"})
+	if b, _ := os.ReadFile(path); string(b) != `{"ID":1,"Email":"al@example.com"}`+"\n" {
+		t.Fatalf("file = %q", b)
+	}
+	os.WriteFile(path, []byte("{not json\n"), 0o600)
+	if _, err := Open(path, userID); err == nil {
+		t.Fatal("Open accepted a corrupt file")
+	}
+}
+
+// A change the file cannot take is undone in memory too.
+func TestSaveFailure(t *testing.T) {
+	dir := t.TempDir()
+	r, _ := Open(filepath.Join(dir, "users"), userID)
+	r.Put(user{1, "al@example.com"})
+	r.path = filepath.Join(dir, "missing", "users")
+
+	if err := r.Put(user{1, "changed@example.com"}); err == nil {
+		t.Fatal("Put into a missing directory succeeded")
+	}
+	if err := r.Put(user{2, "bo@example.com"}); err == nil {
+		t.Fatal("Put into a missing directory succeeded")
+	}
+	if err := r.Delete(1); err == nil {
+		t.Fatal("Delete in a missing directory succeeded")
+	}
+	if got := r.All(); !slices.Equal(got, []user{{1, "al@example.com"}}) {
+		t.Fatalf("after failed writes: %v", got)
+	}
+}
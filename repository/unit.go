@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Errors returned by Unit.Commit.
+var (
+	// ErrConflict means an entity the unit read was changed by someone
+	// else before the unit committed. Nothing was written; run the unit
+	// again from the start.
+	ErrConflict = errors.New("repository: conflicting change")
+	ErrDone     = errors.New("repository: unit already committed or discarded")
+)
+
+// A Unit batches changes to several repositories and commits them all
+// or none. Changes are staged through Stage and invisible to everyone
+// else until Commit, which locks every repository involved, checks that
+// nothing the unit read has changed since (optimistic concurrency), and
+// applies the lot. If saving any repository's file fails, every change
+// is undone.
+//
+// A Unit is for one goroutine; the repositories it writes to may be
+// shared.
+type Unit struct {
+	parts []part
+	done  bool
+}
+
+// part is one repository's share of a Unit.
+type part interface {
+	order() uint64
+	lock()
+	unlock()
+	check() error
+	apply() (undo func())
+	save() error
+}
+
+// NewUnit returns an empty Unit.
+func NewUnit() *Unit { return &Unit{} }
+
+// Do runs fn with a new Unit and commits it if fn returns nil. If fn
+// fails, its changes are discarded and its error returned.
+func Do(fn func(u *Unit) error) error {
+	u := NewUnit()
+	if err := fn(u); err != nil {
+		u.Discard()
+		return err
+	}
+	return u.Commit()
+}
+
+// Staged is a Unit's view of one repository: reads see the unit's own
+// changes, and writes are held until the unit commits.
+type Staged[ID comparable, T any] struct {
+	r       *Repository[ID, T]
+	reads   map[ID]uint64 // revision of each ID when first read
+	changes map[ID]change[T]
+	ids     []ID // changed IDs in the order first changed
+}
+
+type change[T any] struct {
+	v    T
+	keep bool // false deletes
+}
+
+// Stage returns u's view of r, the same one each time for the same r.
+func Stage[ID comparable, T any](u *Unit, r *Repository[ID, T]) *Staged[ID, T] {
+	for _, p := range u.parts {
+		if s, ok := p.(*Staged[ID, T]); ok && s.r == r {
+			return s
+		}
+	}
+	s := &Staged[ID, T]{r: r, reads: make(map[ID]uint64), changes: make(map[ID]change[T])}
+	u.parts = append(u.parts, s)
+	return s
+}
+
+// Get returns the entity with id as the unit sees it. Commit fails with
+// ErrConflict if the entity has changed in the repository since.
+func (s *Staged[ID, T]) Get(id ID) (T, bool) {
+	if c, ok := s.changes[id]; ok {
+		return c.v, c.keep
+	}
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+	if _, ok := s.reads[id]; !ok {
+		s.reads[id] = s.r.revs[id]
+	}
+	v, ok := s.r.items[id]
+	return v, ok
+}
+
+// Put stages storing v.
+func (s *Staged[ID, T]) Put(v T) { s.stage(s.r.id(v), change[T]{v: v, keep: true}) }
+
+// Delete stages removing the entity with id.
+func (s *Staged[ID, T]) Delete(id ID) { s.stage(id, change[T]{}) }
+
+func (s *Staged[ID, T]) stage(id ID, c change[T]) {
+	if _, ok := s.changes[id]; !ok {
+		s.ids = append(s.ids, id)
+	}
+	s.changes[id] = c
+}
+
+func (s *Staged[ID, T]) order() uint64 { return s.r.seq }
+func (s *Staged[ID, T]) lock()         { s.r.mu.Lock() }
+func (s *Staged[ID, T]) unlock()       { s.r.mu.Unlock() }
+
+func (s *Staged[ID, T]) check() error {
+	for id, rev := range s.reads {
+		if s.r.revs[id] != rev {
+			return fmt.Errorf("%w: %v", ErrConflict, id)
+		}
+	}
+	return nil
+}
+
+func (s *Staged[ID, T]) apply() (undo func()) {
+	undos := make([]func(), 0, len(s.ids))
+	for _, id := range s.ids {
+		c := s.changes[id]
+		undos = append(undos, s.r.set(id, c.v, c.keep))
+	}
+	return func() {
+		for _, u := range slices.Backward(undos) {
+			u()
+		}
+	}
+}
+
+func (s *Staged[ID, T]) save() error {
+	if len(s.ids) == 0 {
+		return nil
+	}
+	return s.r.save()
+}
+
+// Commit applies every staged change, or none of them. It returns
+// ErrConflict if something the unit read has changed, or the error
+// saving a file. Either way the unit is finished.
+func (u *Unit) Commit() error {
+	if u.done {
+		return ErrDone
+	}
+	u.done = true
+	// A fixed lock order keeps two units over the same repositories from
+	// deadlocking.
+	parts := slices.SortedFunc(slices.Values(u.parts), func(a, b part) int {
+		return cmp.Compare(a.order(), b.order())
+	})
+	for _, p := range parts {
+		p.lock()
+	}
+	defer func() {
+		for _, p := range parts {
+			p.unlock()
+		}
+	}()
+	for _, p := range parts {
+		if err := p.check(); err != nil {
+			return err
+		}
+	}
+	undos := make([]func(), len(parts))
+	for i, p := range parts {
+		undos[i] = p.apply()
+	}
+	for i, p := range parts {
+		if err := p.save(); err != nil {
+			for _, undo := range undos {
+				undo()
+			}
+			// Put back the files already written; if that fails too,
+			// memory is still right and the next change rewrites them.
+			for _, saved := range parts[:i] {
+				saved.save()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Discard drops every staged change. It is safe to call after Commit, so
+// it can be deferred.
+func (u *Unit) Discard() {
+	u.done = true
+	u.parts = nil
+}
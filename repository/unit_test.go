@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+type account struct {
+	ID      string
+	Balance int
+}
+
+func accountID(a account) string { return a.ID }
+
+func TestUnit(t *testing.T) {
+	accounts := New(accountID)
+	users := New(userID)
+	accounts.Put(account{"a", 10})
+
+	err := Do(func(u *Unit) error {
+		as, us := Stage(u, accounts), Stage(u, users)
+		a, _ := as.Get("a")
+		a.Balance -= 4
+		as.Put(a)
+		as.Put(account{"b", 4})
+		us.Put(user{1, "al@example.com"})
+		if got, _ := as.Get("a"); got.Balance != 6 {
+			t.Errorf("unit reads %v, want its own change", got)
+		}
+		if _, ok := accounts.Get("b"); ok {
+			t.Error("staged change visible before Commit")
+		}
+		if Stage(u, accounts) != as {
+			t.Error("Stage returned a second view of the same repository")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, _ := accounts.Get("a"); a.Balance != 6 || accounts.Len() != 2 || users.Len() != 1 {
+		t.Fatalf("after commit: %v, %d users", accounts.All(), users.Len())
+	}
+
+	// An error from fn discards everything.
+	boom := errors.New("boom")
+	err = Do(func(u *Unit) error {
+		Stage(u, accounts).Delete("a")
+		Stage(u, users).Put(user{2, "bo@example.com"})
+		return boom
+	})
+	if err != boom || accounts.Len() != 2 || users.Len() != 1 {
+		t.Fatalf("after failed unit: err %v, %d accounts, %d users", err, accounts.Len(), users.Len())
+	}
+
+	u := NewUnit()
+	u.Commit()
+	if err := u.Commit(); !errors.Is(err, ErrDone) {
+		t.Fatalf("second Commit = %v", err)
+	}
+}
+
+func TestUnitConflict(t *testing.T) {
+	accounts := New(accountID)
+	accounts.Put(account{"a", 10})
+
+	u := NewUnit()
+	as := Stage(u, accounts)
+	a, _ := as.Get("a")
+	as.Put(account{"a", a.Balance - 3})
+	as.Put(account{"c", 3})
+
+	accounts.Put(account{"a", 100}) // someone else gets in first
+	if err := u.Commit(); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Commit = %v, want ErrConflict", err)
+	}
+	if a, _ := accounts.Get("a"); a.Balance != 100 || accounts.Len() != 1 {
+		t.Fatalf("after conflict: %v", accounts.All())
+	}
+
+	// Reading something absent counts too: it must still be absent.
+	u = NewUnit()
+	if _, ok := Stage(u, accounts).Get("new"); ok {
+		t.Fatal("found an account that was never stored")
+	}
+	Stage(u, accounts).Put(account{"new", 1})
+	accounts.Put(account{"new", 50})
+	if err := u.Commit(); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Commit after a concurrent create = %v", err)
+	}
+}
+
+// If one repository's file cannot be written, the others are put back,
+// in memory and on disk.
+func TestUnitSaveFailure(t *testing.T) {
+	dir := t.TempDir()
+	accounts, _ := Open(filepath.Join(dir, "accounts"), accountID)
+	users, _ := Open(filepath.Join(dir, "users"), userID)
+	accounts.Put(account{"a", 10})
+	users.Put(user{1, "al@example.com"})
+	users.path = filepath.Join(dir, "missing", "users")
+
+	err := Do(func(u *Unit) error {
+		Stage(u, accounts).Put(account{"a", 0})
+		Stage(u, users).Put(user{1, "changed@example.com"})
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Commit succeeded with an unwritable file")
+	}
+	if a, _ := accounts.Get("a"); a.Balance != 10 {
+		t.Fatalf("account in memory = %v", a)
+	}
+	reopened, _ := Open(filepath.Join(dir, "accounts"), accountID)
+	if a, _ := reopened.Get("a"); a.Balance != 10 {
+		t.Fatalf("account on disk = %v", a)
+	}
+	if u, _ := users.Get(1); u.Email != "al@example.com" {
+		t.Fatalf("user = %v", u)
+	}
+}
+
+// Units moving money both ways between the same two repositories neither
+// deadlock nor lose an update.
+func TestUnitConcurrent(t *testing.T) {
+	left, right := New(accountID), New(accountID)
+	left.Put(account{"x", 1000})
+	right.Put(account{"x", 1000})
+	move := func(from, to *Repository[string, account]) {
+		for {
+			err := Do(func(u *Unit) error {
+				f, t := Stage(u, from), Stage(u, to)
+				a, _ := f.Get("x")
+				b, _ := t.Get("x")
+				f.Put(account{"x", a.Balance - 1})
+				t.Put(account{"x", b.Balance + 1})
+				return nil
+			})
+			if !errors.Is(err, ErrConflict) {
+				return
+			}
+		}
+	}
+	var wg sync.WaitGroup
+	for i := range 200 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				move(left, right)
+			} else {
+				move(right, left)
+			}
+		}()
+	}
+	wg.Wait()
+	l, _ := left.Get("x")
+	r, _ := right.Get("x")
+	if got := []int{l.Balance, r.Balance}; !slices.Equal(got, []int{1000, 1000}) {
+		t.Fatalf("balances = %v", got)
+	}
+}
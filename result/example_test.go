@@ -0,0 +1,76 @@
+package result_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/stawuah/pounce-on-go/result"
+)
+
+type Item struct {
+	SKU string
+	Qty int
+}
+
+var ErrNoItem = errors.New("no such item")
+
+var inventory = map[string]Item{"A1": {"A1", 3}}
+
+// GetItem is the idiomatic form.
+func GetItem(sku string) (Item, error) {
+	it, ok := inventory[sku]
+	if !ok {
+		return Item{}, fmt.Errorf("%w: %s", ErrNoItem, sku)
+	}
+	return it, nil
+}
+
+// GetItemResult returns the same outcome as one value.
+func GetItemResult(sku string) result.Result[Item] {
+	return result.Of(GetItem(sku))
+}
+
+// Backorder is the other legitimate answer to "do we have it?": not an
+// error, just not stock on hand.
+type Backorder struct {
+	SKU      string
+	Restocks string
+}
+
+// GetItemEither answers with stock or a backorder.
+func GetItemEither(sku string) result.Either[Backorder, Item] {
+	if it, ok := inventory[sku]; ok {
+		return result.Right[Backorder](it)
+	}
+	return result.Left[Backorder, Item](Backorder{SKU: sku, Restocks: "next week"})
+}
+
+func Example() {
+	// (value, error): the caller branches immediately.
+	if _, err := GetItem("Z9"); err != nil {
+		fmt.Println("error:", err)
+	}
+
+	// Result: outcomes can be collected and inspected later.
+	var outcomes []result.Result[Item]
+	for _, sku := range []string{"A1", "Z9"} {
+		outcomes = append(outcomes, GetItemResult(sku))
+	}
+	for _, r := range outcomes {
+		fmt.Println(r.UnwrapOr(Item{SKU: "?"}).SKU, r.IsOk())
+	}
+
+	// Either: both branches are normal answers.
+	for _, sku := range []string{"A1", "B2"} {
+		fmt.Println(result.Fold(GetItemEither(sku),
+			func(b Backorder) string { return b.SKU + " restocks " + b.Restocks },
+			func(it Item) string { return fmt.Sprintf("%s in stock: %d", it.SKU, it.Qty) },
+		))
+	}
+	// Output:
+	// error: no such item: Z9
+	// A1 true
+	// ? false
+	// A1 in stock: 3
+	// B2 restocks next week
+}
@@ -0,0 +1,117 @@
+// Package result provides Result and Either, two sum types for values
+// that come in one of two shapes.
+//
+// Idiomatic Go returns (value, error) and this package does not replace
+// that. Result is useful where a value and its error must travel
+// together as one thing: through a channel, in a slice of outcomes, or
+// as a map entry. Either is for two legitimate outcomes, neither of which
+// is a failure. Get converts a Result back to the usual pair at the
+// boundary.
+package result
+
+import "fmt"
+
+// Result holds either a value of type T or an error. The zero Result is
+// Ok with T's zero value.
+type Result[T any] struct {
+	val T
+	err error
+}
+
+// Ok returns a successful Result.
+func Ok[T any](v T) Result[T] { return Result[T]{val: v} }
+
+// Err returns a failed Result. err must not be nil.
+func Err[T any](err error) Result[T] {
+	if err == nil {
+		panic("result: Err(nil)")
+	}
+	return Result[T]{err: err}
+}
+
+// Of converts a (value, error) pair.
+func Of[T any](v T, err error) Result[T] {
+	if err != nil {
+		return Result[T]{err: err}
+	}
+	return Ok(v)
+}
+
+// IsOk reports whether r holds a value.
+func (r Result[T]) IsOk() bool { return r.err == nil }
+
+// Get returns the (value, error) pair.
+func (r Result[T]) Get() (T, error) { return r.val, r.err }
+
+// Err returns the error, or nil.
+func (r Result[T]) Err() error { return r.err }
+
+// Unwrap returns the value and panics if r holds an error.
+func (r Result[T]) Unwrap() T {
+	if r.err != nil {
+		panic(fmt.Sprintf("result: Unwrap on error: %v", r.err))
+	}
+	return r.val
+}
+
+// UnwrapOr returns the value, or def if r holds an error.
+func (r Result[T]) UnwrapOr(def T) T {
+	if r.err != nil {
+		return def
+	}
+	return r.val
+}
+
+func (r Result[T]) String() string {
+	if r.err != nil {
+		return fmt.Sprintf("Err(%v)", r.err)
+	}
+	return fmt.Sprintf("Ok(%v)", r.val)
+}
+
+// Map applies f to a successful value and passes errors through. It is a
+// function because a method cannot introduce the type parameter U.
+func Map[T, U any](r Result[T], f func(T) U) Result[U] {
+	if r.err != nil {
+		return Result[U]{err: r.err}
+	}
+	return Ok(f(r.val))
+}
+
+// Then chains a fallible step after a successful value.
+func Then[T, U any](r Result[T], f func(T) (U, error)) Result[U] {
+	if r.err != nil {
+		return Result[U]{err: r.err}
+	}
+	return Of(f(r.val))
+}
+
+// Either holds exactly one of an L or an R.
+type Either[L, R any] struct {
+	left    L
+	right   R
+	isRight bool
+}
+
+// Left returns an Either holding l.
+func Left[L, R any](l L) Either[L, R] { return Either[L, R]{left: l} }
+
+// Right returns an Either holding r.
+func Right[L, R any](r R) Either[L, R] { return Either[L, R]{right: r, isRight: true} }
+
+// IsRight reports whether e holds an R.
+func (e Either[L, R]) IsRight() bool { return e.isRight }
+
+// Left returns the L and whether e holds one.
+func (e Either[L, R]) Left() (L, bool) { return e.left, !e.isRight }
+
+// Right returns the R and whether e holds one.
+func (e Either[L, R]) Right() (R, bool) { return e.right, e.isRight }
+
+// Fold calls whichever of onLeft and onRight matches e's contents.
+func Fold[L, R, T any](e Either[L, R], onLeft func(L) T, onRight func(R) T) T {
+	if e.isRight {
+		return onRight(e.right)
+	}
+	return onLeft(e.left)
+}
@@ -0,0 +1,81 @@
+package result
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+var errBoom = errors.New("boom")
+
+func TestResult(t *testing.T) {
+	ok := Ok(2)
+	if !ok.IsOk() || ok.Unwrap() != 2 || ok.UnwrapOr(9) != 2 || ok.Err() != nil {
+		t.Fatalf("Ok(2) misbehaves: %v", ok)
+	}
+	bad := Err[int](errBoom)
+	if bad.IsOk() || bad.UnwrapOr(9) != 9 || !errors.Is(bad.Err(), errBoom) {
+		t.Fatalf("Err misbehaves: %v", bad)
+	}
+	if ok.String() != "Ok(2)" || bad.String() != "Err(boom)" {
+		t.Fatalf("String() = %s, %s", ok, bad)
+	}
+	var zero Result[string]
+	if !zero.IsOk() {
+		t.Fatal("zero Result is not Ok")
+	}
+}
+
+func TestUnwrapPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Unwrap on Err did not panic")
+		}
+	}()
+	Err[int](errBoom).Unwrap()
+}
+
+func TestErrNilPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Err(nil) did not panic")
+		}
+	}()
+	Err[int](nil)
+}
+
+func TestMapThen(t *testing.T) {
+	parsed := Then(Ok("21"), strconv.Atoi)
+	doubled := Map(parsed, func(n int) int { return n * 2 })
+	if doubled.Unwrap() != 42 {
+		t.Fatalf("got %v", doubled)
+	}
+
+	failed := Map(Then(Ok("x"), strconv.Atoi), func(n int) int { return n * 2 })
+	var numErr *strconv.NumError
+	if !errors.As(failed.Err(), &numErr) {
+		t.Fatalf("error lost: %v", failed)
+	}
+}
+
+func TestEither(t *testing.T) {
+	l := Left[string, int]("cached")
+	r := Right[string](7)
+
+	if v, ok := l.Left(); !ok || v != "cached" || l.IsRight() {
+		t.Fatalf("Left misbehaves")
+	}
+	if _, ok := l.Right(); ok {
+		t.Fatal("Left reported a Right value")
+	}
+	if v, ok := r.Right(); !ok || v != 7 {
+		t.Fatalf("Right misbehaves")
+	}
+
+	describe := func(e Either[string, int]) string {
+		return Fold(e, func(s string) string { return "L:" + s }, func(n int) string { return "R:" + strconv.Itoa(n) })
+	}
+	if describe(l) != "L:cached" || describe(r) != "R:7" {
+		t.Fatalf("Fold gave %q, %q", describe(l), describe(r))
+	}
+}
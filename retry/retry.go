@@ -0,0 +1,144 @@
+// Package retry calls a function until it succeeds, a retry budget runs
+// out, or the context ends, sleeping between attempts according to a
+// Backoff.
+//
+//	err := retry.Do(ctx, send,
+//		retry.WithMaxAttempts(5),
+//		retry.WithBackoff(retry.Jitter(retry.Exponential(100*time.Millisecond, 5*time.Second))),
+//		retry.WithRetryIf(isTemporary),
+//	)
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+)
+
+// Backoff returns how long to wait after the given failed attempt,
+// counting from 1.
+type Backoff func(attempt int) time.Duration
+
+// Constant waits d between every attempt.
+func Constant(d time.Duration) Backoff {
+	return func(int) time.Duration { return d }
+}
+
+// Exponential waits base, 2*base, 4*base, ... capped at limit.
+func Exponential(base, limit time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < limit; i++ {
+			d *= 2
+		}
+		return min(d, limit)
+	}
+}
+
+// Jitter spreads b's delays uniformly over [0, d) ("full jitter") so that
+// many clients failing together do not retry in lockstep.
+func Jitter(b Backoff) Backoff {
+	return func(attempt int) time.Duration {
+		d := b(attempt)
+		if d <= 0 {
+			return 0
+		}
+		return rand.N(d)
+	}
+}
+
+// Option configures Do.
+type Option func(*config)
+
+type config struct {
+	attempts int
+	backoff  Backoff
+	retryIf  func(error) bool
+	clock    clock.Clock
+}
+
+// WithMaxAttempts limits the total number of calls, including the first.
+// The default is 3.
+func WithMaxAttempts(n int) Option {
+	return func(c *config) { c.attempts = max(n, 1) }
+}
+
+// WithBackoff sets the delay schedule. The default is jittered exponential
+// backoff from 100ms capped at 10s.
+func WithBackoff(b Backoff) Option {
+	return func(c *config) { c.backoff = b }
+}
+
+// WithRetryIf retries only errors for which f returns true. By default
+// every error is retried except those marked Permanent.
+func WithRetryIf(f func(error) bool) Option {
+	return func(c *config) { c.retryIf = f }
+}
+
+// WithClock makes Do sleep on c instead of the system clock.
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) { cfg.clock = c }
+}
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying. Do returns it unwrapped.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// IsPermanent reports whether err, or an error it wraps, was marked by
+// Permanent, for callers that schedule their own retries.
+func IsPermanent(err error) bool {
+	var perm permanentError
+	return errors.As(err, &perm)
+}
+
+// Do calls fn until it returns nil, returns an error that should not be
+// retried, or the attempt budget is spent. It returns nil on success and
+// otherwise the last error from fn. If ctx ends while waiting, the
+// returned error matches both ctx.Err() and the last error.
+func Do(ctx context.Context, fn func(context.Context) error, opts ...Option) error {
+	cfg := config{
+		attempts: 3,
+		backoff:  Jitter(Exponential(100*time.Millisecond, 10*time.Second)),
+		retryIf:  func(error) bool { return true },
+		clock:    clock.Real{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		var perm permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if !cfg.retryIf(err) {
+			return err
+		}
+		if attempt >= cfg.attempts {
+			return fmt.Errorf("after %d attempts: %w", attempt, err)
+		}
+
+		select {
+		case <-cfg.clock.After(cfg.backoff(attempt)):
+		case <-ctx.Done():
+			return fmt.Errorf("%w; last error: %w", ctx.Err(), err)
+		}
+	}
+}
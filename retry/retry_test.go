@@ -0,0 +1,135 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeClock records every requested sleep and returns at once.
+type fakeClock struct {
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return time.Unix(0, 0) }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.sleeps = append(c.sleeps, d)
+	ch := make(chan time.Time, 1)
+	ch <- time.Unix(0, 0)
+	return ch
+}
+
+var errFlaky = errors.New("flaky")
+
+// failing returns fn that fails n times, then succeeds, and a pointer to
+// its call count.
+func failing(n int) (func(context.Context) error, *int) {
+	calls := 0
+	return func(context.Context) error {
+		calls++
+		if calls <= n {
+			return errFlaky
+		}
+		return nil
+	}, &calls
+}
+
+func TestSucceedsAfterRetries(t *testing.T) {
+	clk := &fakeClock{}
+	fn, calls := failing(2)
+	err := Do(context.Background(), fn,
+		WithMaxAttempts(5),
+		WithBackoff(Exponential(10*time.Millisecond, time.Second)),
+		WithClock(clk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 3 {
+		t.Fatalf("calls = %d, want 3", *calls)
+	}
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}
+	if len(clk.sleeps) != len(want) || clk.sleeps[0] != want[0] || clk.sleeps[1] != want[1] {
+		t.Fatalf("sleeps = %v, want %v", clk.sleeps, want)
+	}
+}
+
+func TestGivesUpAfterMaxAttempts(t *testing.T) {
+	fn, calls := failing(100)
+	err := Do(context.Background(), fn, WithMaxAttempts(4), WithClock(&fakeClock{}))
+	if !errors.Is(err, errFlaky) {
+		t.Fatalf("err = %v, want errFlaky", err)
+	}
+	if *calls != 4 {
+		t.Fatalf("calls = %d, want 4", *calls)
+	}
+}
+
+func TestRetryIf(t *testing.T) {
+	errFatal := errors.New("fatal")
+	calls := 0
+	err := Do(context.Background(), func(context.Context) error {
+		calls++
+		return errFatal
+	}, WithRetryIf(func(err error) bool { return errors.Is(err, errFlaky) }), WithClock(&fakeClock{}))
+	if err != errFatal || calls != 1 {
+		t.Fatalf("err = %v after %d calls, want errFatal after 1", err, calls)
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), func(context.Context) error {
+		calls++
+		return Permanent(errFlaky)
+	}, WithClock(&fakeClock{}))
+	if err != errFlaky || calls != 1 {
+		t.Fatalf("err = %v after %d calls, want errFlaky after 1", err, calls)
+	}
+	if !IsPermanent(fmt.Errorf("wrapped: %w", Permanent(errFlaky))) || IsPermanent(errFlaky) {
+		t.Fatal("IsPermanent does not follow the mark")
+	}
+	if Permanent(nil) != nil {
+		t.Fatal("Permanent(nil) != nil")
+	}
+}
+
+func TestContextCancelledWhileWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fn, calls := failing(100)
+	cancelAfterFirst := func(ctx context.Context) error {
+		defer cancel()
+		return fn(ctx)
+	}
+	err := Do(ctx, cancelAfterFirst, WithMaxAttempts(10), WithBackoff(Constant(time.Hour)))
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errFlaky) {
+		t.Fatalf("err = %v, want Canceled and errFlaky", err)
+	}
+	if *calls != 1 {
+		t.Fatalf("calls = %d, want 1", *calls)
+	}
+}
+
+func TestExponential(t *testing.T) {
+	b := Exponential(100*time.Millisecond, time.Second)
+	want := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, w := range want {
+		if got := b(i + 1); got != w*time.Millisecond {
+			t.Errorf("attempt %d: %v, want %v", i+1, got, w*time.Millisecond)
+		}
+	}
+}
+
+func TestJitterStaysInRange(t *testing.T) {
+	b := Jitter(Constant(50 * time.Millisecond))
+	for range 1000 {
+		if d := b(1); d < 0 || d >= 50*time.Millisecond {
+			t.Fatalf("jittered delay %v out of [0, 50ms)", d)
+		}
+	}
+	if d := Jitter(Constant(0))(1); d != 0 {
+		t.Fatalf("Jitter(0) = %v", d)
+	}
+}
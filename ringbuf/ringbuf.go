@@ -0,0 +1,146 @@
+// Package ringbuf provides a fixed-capacity ring buffer whose storage is an
+// array rather than a slice.
+//
+// Go has no way to make an array length a type parameter directly, so the
+// array type itself is the second type parameter:
+//
+//	var r ringbuf.Buffer[float64, [32]float64]
+//
+// The buffer's size is then part of its type, it is allocated inline with
+// the struct, and there is no slice header to grow or alias. The Array
+// constraint lists the supported lengths.
+package ringbuf
+
+import (
+	"errors"
+	"sync"
+)
+
+// Array is the set of array types a Buffer can be backed by.
+type Array[T any] interface {
+	~[2]T | ~[4]T | ~[8]T | ~[16]T | ~[32]T | ~[64]T | ~[128]T | ~[256]T | ~[512]T | ~[1024]T
+}
+
+// Mode selects what Push does when the buffer is full.
+type Mode int
+
+const (
+	// Overwrite drops the oldest element to make room.
+	Overwrite Mode = iota
+	// Block waits until a Pop frees a slot or the buffer is closed.
+	Block
+)
+
+// ErrClosed is returned by Push after Close.
+var ErrClosed = errors.New("ringbuf: buffer closed")
+
+// Buffer is a ring buffer holding up to len(A) elements. It is safe for
+// concurrent use. The zero value is an empty buffer in Overwrite mode.
+type Buffer[T any, A Array[T]] struct {
+	mu      sync.Mutex
+	notFull sync.Cond
+	buf     A
+	start   int // index of the oldest element
+	n       int
+	mode    Mode
+	closed  bool
+}
+
+// New returns an empty buffer using mode.
+func New[T any, A Array[T]](mode Mode) *Buffer[T, A] {
+	return &Buffer[T, A]{mode: mode}
+}
+
+func (b *Buffer[T, A]) cond() *sync.Cond {
+	if b.notFull.L == nil {
+		b.notFull.L = &b.mu
+	}
+	return &b.notFull
+}
+
+// Push appends v. In Overwrite mode it never blocks and reports whether the
+// oldest element was dropped. In Block mode it waits for space, so
+// overwrote is always false. After Close, Push returns ErrClosed.
+func (b *Buffer[T, A]) Push(v T) (overwrote bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.mode == Block {
+		for b.n == len(b.buf) && !b.closed {
+			b.cond().Wait()
+		}
+	}
+	if b.closed {
+		return false, ErrClosed
+	}
+	return b.push(v), nil
+}
+
+// TryPush appends v without waiting. In Block mode it reports false when
+// the buffer is full; in Overwrite mode it always succeeds.
+func (b *Buffer[T, A]) TryPush(v T) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed || (b.mode == Block && b.n == len(b.buf)) {
+		return false
+	}
+	b.push(v)
+	return true
+}
+
+func (b *Buffer[T, A]) push(v T) (overwrote bool) {
+	capacity := len(b.buf)
+	if b.n == capacity {
+		b.buf[b.start] = v
+		b.start = (b.start + 1) % capacity
+		return true
+	}
+	b.buf[(b.start+b.n)%capacity] = v
+	b.n++
+	return false
+}
+
+// Pop removes and returns the oldest element.
+func (b *Buffer[T, A]) Pop() (T, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var zero T
+	if b.n == 0 {
+		return zero, false
+	}
+	v := b.buf[b.start]
+	b.buf[b.start] = zero // release references held by the slot
+	b.start = (b.start + 1) % len(b.buf)
+	b.n--
+	b.cond().Signal()
+	return v, true
+}
+
+// Snapshot copies the contents from oldest to newest.
+func (b *Buffer[T, A]) Snapshot() []T {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]T, b.n)
+	for i := range out {
+		out[i] = b.buf[(b.start+i)%len(b.buf)]
+	}
+	return out
+}
+
+// Len returns the number of buffered elements.
+func (b *Buffer[T, A]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.n
+}
+
+// Cap returns the fixed capacity, len(A).
+func (b *Buffer[T, A]) Cap() int { return len(b.buf) }
+
+// Close makes further pushes fail and releases any blocked Push calls.
+// Buffered elements can still be popped.
+func (b *Buffer[T, A]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.cond().Broadcast()
+}
@@ -0,0 +1,100 @@
+package ringbuf
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestOverwrite(t *testing.T) {
+	var b Buffer[int, [4]int]
+	for i := 1; i <= 6; i++ {
+		overwrote, err := b.Push(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := i > 4; overwrote != want {
+			t.Fatalf("Push(%d) overwrote = %v, want %v", i, overwrote, want)
+		}
+	}
+	if got, want := b.Snapshot(), []int{3, 4, 5, 6}; !slices.Equal(got, want) {
+		t.Fatalf("Snapshot() = %v, want %v", got, want)
+	}
+	if v, ok := b.Pop(); !ok || v != 3 {
+		t.Fatalf("Pop() = %d, %v; want 3, true", v, ok)
+	}
+	if b.Len() != 3 || b.Cap() != 4 {
+		t.Fatalf("Len/Cap = %d/%d", b.Len(), b.Cap())
+	}
+}
+
+func TestPopEmpty(t *testing.T) {
+	b := New[string, [2]string](Overwrite)
+	if _, ok := b.Pop(); ok {
+		t.Fatal("Pop() on empty buffer reported ok")
+	}
+}
+
+func TestBlockWaitsForPop(t *testing.T) {
+	b := New[int, [2]int](Block)
+	b.Push(1)
+	b.Push(2)
+	if b.TryPush(3) {
+		t.Fatal("TryPush succeeded on a full blocking buffer")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := b.Push(3); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Push returned while the buffer was full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if v, _ := b.Pop(); v != 1 {
+		t.Fatalf("Pop() = %d, want 1", v)
+	}
+	<-done
+	if got := b.Snapshot(); !slices.Equal(got, []int{2, 3}) {
+		t.Fatalf("Snapshot() = %v", got)
+	}
+}
+
+func TestCloseReleasesBlockedPush(t *testing.T) {
+	b := New[int, [2]int](Block)
+	b.Push(1)
+	b.Push(2)
+
+	errc := make(chan error)
+	go func() {
+		_, err := b.Push(3)
+		errc <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	b.Close()
+
+	if err := <-errc; !errors.Is(err, ErrClosed) {
+		t.Fatalf("Push after Close = %v, want ErrClosed", err)
+	}
+	if v, ok := b.Pop(); !ok || v != 1 {
+		t.Fatal("buffered elements were lost on Close")
+	}
+}
+
+func TestStorageIsInline(t *testing.T) {
+	// The array lives inside the struct: a larger array type makes a larger
+	// Buffer, unlike a slice-backed buffer whose header size is fixed.
+	small := unsafe.Sizeof(Buffer[int64, [2]int64]{})
+	large := unsafe.Sizeof(Buffer[int64, [32]int64]{})
+	if large-small != 30*8 {
+		t.Fatalf("size difference = %d, want %d", large-small, 30*8)
+	}
+}
@@ -0,0 +1,181 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrBadCron is returned by ParseCron for malformed expressions.
+var ErrBadCron = errors.New("scheduler: invalid cron expression")
+
+// Schedule decides when a job runs next.
+type Schedule interface {
+	// Next returns the first activation strictly after t, or the zero
+	// Time if there is none.
+	Next(t time.Time) time.Time
+}
+
+type interval time.Duration
+
+// Every returns a schedule that fires every d, measured from the previous
+// activation.
+func Every(d time.Duration) Schedule {
+	if d <= 0 {
+		panic("scheduler: interval must be positive")
+	}
+	return interval(d)
+}
+
+func (i interval) Next(t time.Time) time.Time { return t.Add(time.Duration(i)) }
+
+// cron is a parsed five-field expression. Each field is a bitmask of
+// allowed values.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	// Classic cron semantics: if both day fields are restricted, a day
+	// matches when either does; otherwise both must.
+	domStar, dowStar bool
+}
+
+var macros = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// ParseCron parses a standard five-field cron expression:
+//
+//	minute hour day-of-month month day-of-week
+//
+// Each field accepts *, a number, a range a-b, a list a,b,c and a step
+// suffix /n on * or a range. Day-of-week runs 0-7 with both 0 and 7
+// meaning Sunday. The @yearly, @monthly, @weekly, @daily and @hourly
+// shorthands are also accepted. Times are evaluated in the location of
+// the time passed to Next.
+func ParseCron(expr string) (Schedule, error) {
+	if m, ok := macros[expr]; ok {
+		expr = m
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q: want 5 fields, got %d", ErrBadCron, expr, len(fields))
+	}
+	var c cron
+	var err error
+	parsers := []struct {
+		dst      *uint64
+		min, max int
+	}{
+		{&c.minute, 0, 59},
+		{&c.hour, 0, 23},
+		{&c.dom, 1, 31},
+		{&c.month, 1, 12},
+		{&c.dow, 0, 7},
+	}
+	for i, p := range parsers {
+		if *p.dst, err = parseField(fields[i], p.min, p.max); err != nil {
+			return nil, fmt.Errorf("%w: %q: field %d: %v", ErrBadCron, expr, i+1, err)
+		}
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 << 0
+	}
+	c.domStar = fields[2] == "*"
+	c.dowStar = fields[4] == "*"
+	return &c, nil
+}
+
+// MustParseCron is ParseCron for expressions known to be valid.
+func MustParseCron(expr string) Schedule {
+	s, err := ParseCron(expr)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func parseField(field string, lo, hi int) (uint64, error) {
+	var mask uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step < 1 {
+				return 0, fmt.Errorf("bad step %q", stepStr)
+			}
+		}
+
+		start, end := lo, hi
+		switch a, b, isRange := strings.Cut(rng, "-"); {
+		case rng == "*":
+		case isRange:
+			var err1, err2 error
+			start, err1 = strconv.Atoi(a)
+			end, err2 = strconv.Atoi(b)
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("bad range %q", rng)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("bad value %q", rng)
+			}
+			start, end = n, n
+			if hasStep {
+				end = hi
+			}
+		}
+		if start < lo || end > hi || start > end {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, lo, hi)
+		}
+		for v := start; v <= end; v += step {
+			mask |= 1 << v
+		}
+	}
+	return mask, nil
+}
+
+func has(mask uint64, v int) bool { return mask&(1<<v) != 0 }
+
+func (c *cron) dayMatches(t time.Time) bool {
+	dom, dow := has(c.dom, t.Day()), has(c.dow, int(t.Weekday()))
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next walks forward field by field, jumping to the start of the next
+// month, day or hour whenever a coarser field does not match.
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// No valid expression needs more than a few years to match again
+	// (29 February on a given weekday is the worst case).
+	limit := t.AddDate(30, 0, 0)
+	for t.Before(limit) {
+		if !has(c.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(c.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(c.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
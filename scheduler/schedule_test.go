@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEvery(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := Every(90 * time.Second).Next(start); !got.Equal(start.Add(90 * time.Second)) {
+		t.Fatalf("Next() = %v", got)
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// 2024-03-15 is a Friday.
+	from := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 3, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 3, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2024, 3, 15, 11, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2024, 3, 16, 2, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, 3, 15, 13, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 1", time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"15,45 8 * 6 *", time.Date(2024, 6, 1, 8, 15, 0, 0, time.UTC)},
+		// Both day fields restricted: the 20th OR any Monday.
+		{"0 0 20 * 1", time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		s, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: Next() = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestCronNeverMatches(t *testing.T) {
+	s := MustParseCron("0 0 31 2 *")
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Fatalf("Next() = %v, want zero", got)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+	} {
+		if _, err := ParseCron(expr); !errors.Is(err, ErrBadCron) {
+			t.Errorf("ParseCron(%q) error = %v, want ErrBadCron", expr, err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package scheduler runs named jobs on interval or cron schedules.
+//
+// Each job has its own timer goroutine. A run that is still going when
+// its next activation comes round is not started again; the activation is
+// skipped and reported as ErrSkipped, so a slow job never piles up
+// overlapping copies of itself. A panicking job is recovered and reported
+// as an error without affecting other jobs or later runs of the same job.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/stawuah/pounce-on-go/clock"
+	"github.com/stawuah/pounce-on-go/panics"
+)
+
+var (
+	// ErrSkipped is reported when an activation is dropped because the
+	// previous run of the same job had not finished.
+	ErrSkipped = errors.New("scheduler: previous run still in progress")
+	// ErrDuplicate is returned by Add for a name already in use.
+	ErrDuplicate = errors.New("scheduler: duplicate job name")
+	// ErrStopped is returned by Add after Stop.
+	ErrStopped = errors.New("scheduler: stopped")
+)
+
+// Option configures a Scheduler.
+type Option func(*Scheduler)
+
+// WithClock makes the scheduler read and wait on c.
+func WithClock(c clock.Clock) Option {
+	return func(s *Scheduler) { s.clock = c }
+}
+
+// WithErrorHandler sets the function that receives job failures, recovered
+// panics and skipped activations. The default discards them. It may be
+// called from several goroutines at once.
+func WithErrorHandler(f func(job string, err error)) Option {
+	return func(s *Scheduler) { s.onError = f }
+}
+
+// Scheduler runs jobs until Stop is called.
+type Scheduler struct {
+	clock   clock.Clock
+	onError func(job string, err error)
+
+	ctx    context.Context // cancelled when Stop gives up waiting
+	cancel context.CancelFunc
+	quit   chan struct{} // closed by Stop to end the timer loops
+
+	mu      sync.Mutex
+	names   map[string]bool
+	stopped bool
+	loops   sync.WaitGroup
+	runs    sync.WaitGroup
+}
+
+// New returns a running scheduler with no jobs.
+func New(opts ...Option) *Scheduler {
+	s := &Scheduler{
+		clock:   clock.Real{},
+		onError: func(string, error) {},
+		quit:    make(chan struct{}),
+		names:   make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	return s
+}
+
+// Add schedules fn under name. The first run is at sched.Next(now).
+func (s *Scheduler) Add(name string, sched Schedule, fn func(context.Context) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrStopped
+	}
+	if s.names[name] {
+		return fmt.Errorf("%w: %q", ErrDuplicate, name)
+	}
+	s.names[name] = true
+
+	j := &job{name: name, sched: sched, fn: fn}
+	s.loops.Add(1)
+	go s.loop(j)
+	return nil
+}
+
+type job struct {
+	name    string
+	sched   Schedule
+	fn      func(context.Context) error
+	running atomic.Bool
+}
+
+func (s *Scheduler) loop(j *job) {
+	defer s.loops.Done()
+	next := j.sched.Next(s.clock.Now())
+	for !next.IsZero() {
+		select {
+		case <-s.clock.After(next.Sub(s.clock.Now())):
+		case <-s.quit:
+			return
+		}
+		s.fire(j)
+		next = j.sched.Next(next)
+		// After a long stall (a suspended laptop, say) skip the missed
+		// activations rather than firing them back to back.
+		if now := s.clock.Now(); next.Before(now) {
+			next = j.sched.Next(now)
+		}
+	}
+}
+
+func (s *Scheduler) fire(j *job) {
+	if !j.running.CompareAndSwap(false, true) {
+		s.onError(j.name, ErrSkipped)
+		return
+	}
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		defer j.running.Store(false)
+		if err := s.run(j); err != nil {
+			s.onError(j.name, err)
+		}
+	}()
+}
+
+// run calls the job, turning a panic into a *panics.Error.
+func (s *Scheduler) run(j *job) error {
+	if err := panics.Try(func() error { return j.fn(s.ctx) }); err != nil {
+		return fmt.Errorf("scheduler: job %q: %w", j.name, err)
+	}
+	return nil
+}
+
+// Stop stops scheduling new runs and waits for those in progress. If ctx
+// ends first, Stop cancels the context passed to running jobs and returns
+// ctx.Err() without waiting further.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.quit)
+	}
+	s.mu.Unlock()
+	s.loops.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,232 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+	"github.com/stawuah/pounce-on-go/concurrency/leaktest"
+	"github.com/stawuah/pounce-on-go/panics"
+)
+
+func newFakeClock() *clock.Fake {
+	return clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// errorLog collects what the scheduler reports.
+type errorLog struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (l *errorLog) handle(_ string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errs = append(l.errs, err)
+}
+
+func (l *errorLog) all() []error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]error(nil), l.errs...)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestIntervalJob(t *testing.T) {
+	leaktest.Check(t)
+	clk := newFakeClock()
+	s := New(WithClock(clk))
+	defer s.Stop(context.Background())
+
+	var runs atomic.Int32
+	s.Add("snapshot", Every(time.Minute), func(context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	for i := range 3 {
+		clk.BlockUntil(1)
+		clk.Advance(time.Minute)
+		waitFor(t, func() bool { return runs.Load() == int32(i+1) })
+	}
+}
+
+func TestCronJob(t *testing.T) {
+	leaktest.Check(t)
+	clk := newFakeClock() // midnight
+	s := New(WithClock(clk))
+	defer s.Stop(context.Background())
+
+	ran := make(chan time.Time, 1)
+	s.Add("cleanup", MustParseCron("30 3 * * *"), func(context.Context) error {
+		ran <- clk.Now()
+		return nil
+	})
+
+	clk.BlockUntil(1)
+	clk.Advance(3 * time.Hour)
+	clk.BlockUntil(1)
+	clk.Advance(30 * time.Minute)
+	if got := <-ran; got.Hour() != 3 || got.Minute() != 30 {
+		t.Fatalf("ran at %v, want 03:30", got)
+	}
+}
+
+func TestNoOverlappingRuns(t *testing.T) {
+	leaktest.Check(t)
+	clk := newFakeClock()
+	var log errorLog
+	s := New(WithClock(clk), WithErrorHandler(log.handle))
+
+	release := make(chan struct{})
+	var runs, concurrent, peak atomic.Int32
+	s.Add("slow", Every(time.Second), func(context.Context) error {
+		runs.Add(1)
+		if n := concurrent.Add(1); n > peak.Load() {
+			peak.Store(n)
+		}
+		defer concurrent.Add(-1)
+		<-release
+		return nil
+	})
+
+	for range 3 {
+		clk.BlockUntil(1)
+		clk.Advance(time.Second)
+	}
+	waitFor(t, func() bool { return len(log.all()) == 2 })
+	close(release)
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if runs.Load() != 1 || peak.Load() != 1 {
+		t.Fatalf("runs = %d, peak = %d, want 1 and 1", runs.Load(), peak.Load())
+	}
+	for _, err := range log.all() {
+		if !errors.Is(err, ErrSkipped) {
+			t.Fatalf("reported %v, want ErrSkipped", err)
+		}
+	}
+}
+
+func TestPanicIsolation(t *testing.T) {
+	leaktest.Check(t)
+	clk := newFakeClock()
+	var log errorLog
+	s := New(WithClock(clk), WithErrorHandler(log.handle))
+	defer s.Stop(context.Background())
+
+	var healthy, panicky atomic.Int32
+	s.Add("panicky", Every(time.Second), func(context.Context) error {
+		panicky.Add(1)
+		panic("boom")
+	})
+	s.Add("healthy", Every(time.Second), func(context.Context) error {
+		healthy.Add(1)
+		return nil
+	})
+
+	for i := range 2 {
+		clk.BlockUntil(2)
+		clk.Advance(time.Second)
+		waitFor(t, func() bool { return healthy.Load() == int32(i+1) && panicky.Load() == int32(i+1) })
+	}
+	waitFor(t, func() bool { return len(log.all()) == 2 })
+	for _, err := range log.all() {
+		var pe *panics.Error
+		if !errors.As(err, &pe) || !strings.Contains(err.Error(), `job "panicky": panic: boom`) {
+			t.Fatalf("reported %v", err)
+		}
+	}
+}
+
+func TestJobErrorsReported(t *testing.T) {
+	clk := newFakeClock()
+	var log errorLog
+	s := New(WithClock(clk), WithErrorHandler(log.handle))
+	defer s.Stop(context.Background())
+
+	errDisk := errors.New("disk full")
+	s.Add("snapshot", Every(time.Second), func(context.Context) error { return errDisk })
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	waitFor(t, func() bool { return len(log.all()) == 1 })
+	if !errors.Is(log.all()[0], errDisk) {
+		t.Fatalf("reported %v", log.all()[0])
+	}
+}
+
+func TestAdd(t *testing.T) {
+	s := New(WithClock(newFakeClock()))
+	noop := func(context.Context) error { return nil }
+	if err := s.Add("a", Every(time.Second), noop); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("a", Every(time.Second), noop); !errors.Is(err, ErrDuplicate) {
+		t.Fatalf("duplicate Add: %v", err)
+	}
+	s.Stop(context.Background())
+	if err := s.Add("b", Every(time.Second), noop); !errors.Is(err, ErrStopped) {
+		t.Fatalf("Add after Stop: %v", err)
+	}
+}
+
+func TestStopTimeoutCancelsRunningJobs(t *testing.T) {
+	leaktest.Check(t)
+	clk := newFakeClock()
+	s := New(WithClock(clk))
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	s.Add("stuck", Every(time.Second), func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	})
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stop() = %v, want DeadlineExceeded", err)
+	}
+	<-cancelled
+}
+
+func TestSkipsMissedActivations(t *testing.T) {
+	clk := newFakeClock()
+	s := New(WithClock(clk))
+	defer s.Stop(context.Background())
+
+	var runs atomic.Int32
+	s.Add("tick", Every(time.Minute), func(context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	clk.BlockUntil(1)
+	clk.Advance(time.Hour)
+	clk.BlockUntil(1)
+	waitFor(t, func() bool { return runs.Load() == 1 })
+	if n := runs.Load(); n != 1 {
+		t.Fatalf("runs = %d after a long stall, want 1", n)
+	}
+}
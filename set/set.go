@@ -0,0 +1,139 @@
+// Package set provides a generic set backed by a map.
+package set
+
+import (
+	"bytes"
+	"encoding/json"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// Set is an unordered collection of distinct values. It is a map underneath,
+// so len(s) and for range work directly. A nil Set behaves as empty for
+// reads; use New or make before adding.
+type Set[T comparable] map[T]struct{}
+
+// New returns a set holding items.
+func New[T comparable](items ...T) Set[T] {
+	s := make(Set[T], len(items))
+	s.Add(items...)
+	return s
+}
+
+// Of returns a set holding the values produced by seq.
+func Of[T comparable](seq iter.Seq[T]) Set[T] {
+	s := make(Set[T])
+	for v := range seq {
+		s[v] = struct{}{}
+	}
+	return s
+}
+
+// Add inserts items, ignoring ones already present.
+func (s Set[T]) Add(items ...T) {
+	for _, v := range items {
+		s[v] = struct{}{}
+	}
+}
+
+// Remove deletes items, ignoring ones not present.
+func (s Set[T]) Remove(items ...T) {
+	for _, v := range items {
+		delete(s, v)
+	}
+}
+
+// Contains reports whether v is in the set.
+func (s Set[T]) Contains(v T) bool {
+	_, ok := s[v]
+	return ok
+}
+
+// Len returns the number of elements.
+func (s Set[T]) Len() int { return len(s) }
+
+// Union returns a new set with the elements of s and o.
+func (s Set[T]) Union(o Set[T]) Set[T] {
+	out := make(Set[T], max(len(s), len(o)))
+	maps.Copy(out, s)
+	maps.Copy(out, o)
+	return out
+}
+
+// Intersection returns a new set with the elements present in both s and o.
+func (s Set[T]) Intersection(o Set[T]) Set[T] {
+	small, large := s, o
+	if len(large) < len(small) {
+		small, large = large, small
+	}
+	out := make(Set[T])
+	for v := range small {
+		if large.Contains(v) {
+			out[v] = struct{}{}
+		}
+	}
+	return out
+}
+
+// Difference returns a new set with the elements of s that are not in o.
+func (s Set[T]) Difference(o Set[T]) Set[T] {
+	out := make(Set[T])
+	for v := range s {
+		if !o.Contains(v) {
+			out[v] = struct{}{}
+		}
+	}
+	return out
+}
+
+// Equal reports whether s and o hold the same elements.
+func (s Set[T]) Equal(o Set[T]) bool {
+	if len(s) != len(o) {
+		return false
+	}
+	for v := range s {
+		if !o.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// All returns an iterator over the elements in unspecified order.
+func (s Set[T]) All() iter.Seq[T] { return maps.Keys(s) }
+
+// ToSlice returns the elements in unspecified order. Use slices.Sorted(s.All())
+// when T is ordered and a stable order matters.
+func (s Set[T]) ToSlice() []T { return slices.Collect(maps.Keys(s)) }
+
+// MarshalJSON encodes the set as a JSON array. Elements are sorted by their
+// encoded form so the output is stable even though map order is not.
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	elems := make([][]byte, 0, len(s))
+	for v := range s {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, b)
+	}
+	slices.SortFunc(elems, bytes.Compare)
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	buf.Write(bytes.Join(elems, []byte{','}))
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON array, dropping duplicates. It replaces any
+// existing contents.
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	*s = New(items...)
+	return nil
+}
@@ -0,0 +1,82 @@
+package set
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestBasicOperations(t *testing.T) {
+	s := New("a", "b", "a")
+	if s.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", s.Len())
+	}
+	s.Add("c")
+	s.Remove("a", "missing")
+	if s.Contains("a") || !s.Contains("b") || !s.Contains("c") {
+		t.Fatalf("unexpected contents %v", s.ToSlice())
+	}
+
+	var empty Set[string]
+	if empty.Contains("a") || empty.Len() != 0 {
+		t.Fatal("nil set is not empty")
+	}
+}
+
+func TestAlgebra(t *testing.T) {
+	a := New(1, 2, 3)
+	b := New(2, 3, 4)
+
+	tests := []struct {
+		name string
+		got  Set[int]
+		want Set[int]
+	}{
+		{"union", a.Union(b), New(1, 2, 3, 4)},
+		{"intersection", a.Intersection(b), New(2, 3)},
+		{"difference", a.Difference(b), New(1)},
+		{"reverse difference", b.Difference(a), New(4)},
+		{"union with nil", a.Union(nil), a},
+		{"intersection with nil", a.Intersection(nil), New[int]()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.got.Equal(tt.want) {
+				t.Fatalf("got %v, want %v", slices.Sorted(tt.got.All()), slices.Sorted(tt.want.All()))
+			}
+		})
+	}
+	if !a.Equal(New(1, 2, 3)) {
+		t.Fatal("operations modified their receiver")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(New("b", "c", "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != `["a","b","c"]` {
+		t.Fatalf("Marshal = %s", got)
+	}
+
+	var s Set[int]
+	if err := json.Unmarshal([]byte(`[3, 1, 3]`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Equal(New(1, 3)) {
+		t.Fatalf("Unmarshal = %v", s.ToSlice())
+	}
+
+	// Inside a struct the set round-trips as a plain array field.
+	type resource struct {
+		IDs Set[int] `json:"ids"`
+	}
+	data, err = json.Marshal(resource{IDs: New(2, 10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != `{"ids":[10,2]}` {
+		t.Fatalf("Marshal struct = %s", got)
+	}
+}
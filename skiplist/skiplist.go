@@ -0,0 +1,151 @@
+// Package skiplist implements an ordered map as a skip list.
+//
+// A skip list is a sorted linked list with express lanes: every node is
+// on level 0, and each level above holds a random quarter of the nodes
+// below it. A search runs along the top lane until the next step would
+// overshoot, then drops down a level, for O(log n) expected steps with no
+// rebalancing. Once the first key of a range is found, iterating is a walk
+// along level 0.
+package skiplist
+
+import (
+	"cmp"
+	"iter"
+	"math/bits"
+	"math/rand/v2"
+)
+
+// maxLevel bounds the tower height; with p = 1/4 it covers 4^32 keys.
+const maxLevel = 32
+
+type node[K, V any] struct {
+	key   K
+	value V
+	next  []*node[K, V] // next[i] is the successor on level i
+}
+
+// List is a skip list. The zero value is not usable; create one with New
+// or NewFunc. A List is not safe for concurrent use.
+type List[K, V any] struct {
+	head    node[K, V] // sentinel; its key is never compared
+	level   int        // levels in use
+	len     int
+	compare func(K, K) int
+}
+
+// New returns an empty List ordered by cmp.Compare.
+func New[K cmp.Ordered, V any]() *List[K, V] {
+	return NewFunc[K, V](cmp.Compare[K])
+}
+
+// NewFunc returns an empty List ordered by compare.
+func NewFunc[K, V any](compare func(a, b K) int) *List[K, V] {
+	return &List[K, V]{
+		head:    node[K, V]{next: make([]*node[K, V], maxLevel)},
+		level:   1,
+		compare: compare,
+	}
+}
+
+// Len returns the number of entries.
+func (l *List[K, V]) Len() int { return l.len }
+
+// randomLevel returns 1 with probability 3/4, 2 with 3/16, and so on:
+// each pair of zero bits in a random word adds a level.
+func randomLevel() int {
+	return min(1+bits.TrailingZeros64(rand.Uint64())/2, maxLevel)
+}
+
+// findPrev fills prev[i] with the last node on level i whose key is less
+// than key, and returns the level-0 successor, the first node >= key.
+func (l *List[K, V]) findPrev(key K, prev []*node[K, V]) *node[K, V] {
+	x := &l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && l.compare(x.next[i].key, key) < 0 {
+			x = x.next[i]
+		}
+		if prev != nil {
+			prev[i] = x
+		}
+	}
+	return x.next[0]
+}
+
+// Search returns the value stored under key.
+func (l *List[K, V]) Search(key K) (V, bool) {
+	if n := l.findPrev(key, nil); n != nil && l.compare(n.key, key) == 0 {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Insert stores value under key and reports whether key was new.
+func (l *List[K, V]) Insert(key K, value V) bool {
+	var prev [maxLevel]*node[K, V]
+	if n := l.findPrev(key, prev[:]); n != nil && l.compare(n.key, key) == 0 {
+		n.value = value
+		return false
+	}
+	level := randomLevel()
+	for i := l.level; i < level; i++ {
+		prev[i] = &l.head
+	}
+	l.level = max(l.level, level)
+
+	n := &node[K, V]{key: key, value: value, next: make([]*node[K, V], level)}
+	for i := range level {
+		n.next[i] = prev[i].next[i]
+		prev[i].next[i] = n
+	}
+	l.len++
+	return true
+}
+
+// Delete removes key and reports whether it was present.
+func (l *List[K, V]) Delete(key K) bool {
+	var prev [maxLevel]*node[K, V]
+	n := l.findPrev(key, prev[:])
+	if n == nil || l.compare(n.key, key) != 0 {
+		return false
+	}
+	for i := range len(n.next) {
+		prev[i].next[i] = n.next[i]
+	}
+	for l.level > 1 && l.head.next[l.level-1] == nil {
+		l.level--
+	}
+	l.len--
+	return true
+}
+
+// Min returns the smallest key and its value.
+func (l *List[K, V]) Min() (K, V, bool) {
+	if n := l.head.next[0]; n != nil {
+		return n.key, n.value, true
+	}
+	var k K
+	var v V
+	return k, v, false
+}
+
+// All yields every entry in ascending key order.
+func (l *List[K, V]) All() iter.Seq2[K, V] {
+	return l.from(l.head.next[0])
+}
+
+// Seek yields the entries with key >= from in ascending order. Stop
+// ranging once past the end of the wanted range.
+func (l *List[K, V]) Seek(from K) iter.Seq2[K, V] {
+	return l.from(l.findPrev(from, nil))
+}
+
+func (l *List[K, V]) from(start *node[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for n := start; n != nil; n = n.next[0] {
+			if !yield(n.key, n.value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package skiplist
+
+import (
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func keys[K, V any](l *List[K, V]) []K {
+	var out []K
+	for k := range l.All() {
+		out = append(out, k)
+	}
+	return out
+}
+
+// checkLevels verifies that every level is sorted and a sublist of the
+// level below.
+func checkLevels(t *testing.T, l *List[int, int]) {
+	t.Helper()
+	below := map[*node[int, int]]bool{}
+	for i := range l.level {
+		on := map[*node[int, int]]bool{}
+		prev := (*node[int, int])(nil)
+		for n := l.head.next[i]; n != nil; n = n.next[i] {
+			if prev != nil && prev.key >= n.key {
+				t.Fatalf("level %d out of order at %d", i, n.key)
+			}
+			if i > 0 && !below[n] {
+				t.Fatalf("node %d on level %d but not level %d", n.key, i, i-1)
+			}
+			on[n] = true
+			prev = n
+		}
+		below = on
+	}
+	for i := l.level; i < maxLevel; i++ {
+		if l.head.next[i] != nil {
+			t.Fatalf("level %d in use above l.level %d", i, l.level)
+		}
+	}
+}
+
+func TestBasics(t *testing.T) {
+	l := New[string, int]()
+	if _, _, ok := l.Min(); ok {
+		t.Fatal("Min of an empty list reported ok")
+	}
+	for i, k := range []string{"m", "c", "x", "a"} {
+		if !l.Insert(k, i) {
+			t.Fatalf("Insert(%q) reported existing", k)
+		}
+	}
+	if l.Insert("c", 10) {
+		t.Fatal("re-insert reported new")
+	}
+	if v, ok := l.Search("c"); !ok || v != 10 {
+		t.Fatalf("Search(c) = %d, %v", v, ok)
+	}
+	if _, ok := l.Search("b"); ok {
+		t.Fatal("Search(b) found a missing key")
+	}
+	if got := keys(l); !slices.Equal(got, []string{"a", "c", "m", "x"}) {
+		t.Fatalf("All = %v", got)
+	}
+
+	var seek []string
+	for k := range l.Seek("d") {
+		seek = append(seek, k)
+	}
+	if !slices.Equal(seek, []string{"m", "x"}) {
+		t.Fatalf("Seek(d) = %v", seek)
+	}
+	if !l.Delete("m") || l.Delete("m") || l.Len() != 3 {
+		t.Fatalf("Delete(m) twice, Len = %d", l.Len())
+	}
+}
+
+func TestSeekReusable(t *testing.T) {
+	l := New[int, int]()
+	l.Insert(1, 0)
+	l.Insert(2, 0)
+	seq := l.Seek(0)
+	var n int
+	for range seq {
+		n++
+	}
+	for range seq {
+		n++
+	}
+	if n != 4 {
+		t.Fatalf("ranging twice over one Seek yielded %d entries, want 4", n)
+	}
+}
+
+func TestRandomOperations(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 5))
+	l := New[int, int]()
+	model := map[int]int{}
+	for i := range 5000 {
+		k := r.IntN(500)
+		if r.IntN(3) == 0 {
+			_, had := model[k]
+			if l.Delete(k) != had {
+				t.Fatalf("step %d: Delete(%d) disagrees with model", i, k)
+			}
+			delete(model, k)
+		} else {
+			_, had := model[k]
+			if l.Insert(k, i) == had {
+				t.Fatalf("step %d: Insert(%d) disagrees with model", i, k)
+			}
+			model[k] = i
+		}
+		if i%100 == 0 {
+			checkLevels(t, l)
+		}
+	}
+	checkLevels(t, l)
+	if got, want := keys(l), slices.Sorted(maps.Keys(model)); !slices.Equal(got, want) || l.Len() != len(want) {
+		t.Fatalf("keys differ from model (len %d vs %d)", l.Len(), len(want))
+	}
+	for k, v := range model {
+		if got, ok := l.Search(k); !ok || got != v {
+			t.Fatalf("Search(%d) = %d, %v; want %d", k, got, ok, v)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package slicegrowth records how append grows a slice: the length,
+// capacity and backing-array address after every append. The address is
+// what makes the table instructive — it changes exactly when append has
+// run out of capacity and copied everything to a new array, and from then
+// on older slice headers no longer see new writes.
+package slicegrowth
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"unsafe"
+)
+
+// Step is the state of the slice after one append.
+type Step struct {
+	Len   int
+	Cap   int
+	Addr  uintptr // address of element 0 of the backing array
+	Moved bool    // the append allocated a new backing array
+}
+
+// Record appends n zero values of T to a nil slice, one at a time, and
+// returns the state after each append.
+func Record[T any](n int) []Step {
+	var s []T
+	steps := make([]Step, 0, n)
+	var prev uintptr
+	for range n {
+		var zero T
+		s = append(s, zero)
+		addr := arrayAddr(s)
+		steps = append(steps, Step{Len: len(s), Cap: cap(s), Addr: addr, Moved: addr != prev})
+		prev = addr
+	}
+	return steps
+}
+
+// Growths filters steps down to the appends that reallocated.
+func Growths(steps []Step) []Step {
+	var out []Step
+	for _, st := range steps {
+		if st.Moved {
+			out = append(out, st)
+		}
+	}
+	return out
+}
+
+// WriteTable prints steps as an aligned table with the growth factor from
+// the previous capacity.
+func WriteTable(w io.Writer, steps []Step) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "len\tcap\tgrowth\tbacking array\tmoved\t")
+	prevCap := 0
+	for _, st := range steps {
+		growth := "-"
+		if prevCap > 0 && st.Cap != prevCap {
+			growth = fmt.Sprintf("%.2fx", float64(st.Cap)/float64(prevCap))
+		}
+		moved := ""
+		if st.Moved {
+			moved = "yes"
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%#x\t%s\t\n", st.Len, st.Cap, growth, st.Addr, moved)
+		prevCap = st.Cap
+	}
+	return tw.Flush()
+}
+
+// SharesArray reports whether a and b overlap in memory, that is whether a
+// write through one could be observed through the other.
+func SharesArray[T any](a, b []T) bool {
+	if cap(a) == 0 || cap(b) == 0 {
+		return false
+	}
+	size := unsafe.Sizeof(*new(T))
+	aStart, bStart := arrayAddr(a), arrayAddr(b)
+	aEnd := aStart + uintptr(cap(a))*size
+	bEnd := bStart + uintptr(cap(b))*size
+	return aStart < bEnd && bStart < aEnd
+}
+
+func arrayAddr[T any](s []T) uintptr {
+	return uintptr(unsafe.Pointer(unsafe.SliceData(s)))
+}
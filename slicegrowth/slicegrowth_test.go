@@ -0,0 +1,93 @@
+package slicegrowth
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	steps := Record[int](100)
+	if len(steps) != 100 {
+		t.Fatalf("got %d steps", len(steps))
+	}
+	for i, st := range steps {
+		if st.Len != i+1 || st.Cap < st.Len {
+			t.Fatalf("step %d = %+v", i, st)
+		}
+		if i > 0 {
+			prev := steps[i-1]
+			// The array moves exactly when capacity changes.
+			if st.Moved != (st.Cap != prev.Cap) {
+				t.Fatalf("step %d: moved = %v but cap %d -> %d", i, st.Moved, prev.Cap, st.Cap)
+			}
+		}
+	}
+	if !steps[0].Moved {
+		t.Fatal("first append from nil did not allocate")
+	}
+
+	growths := Growths(steps)
+	for i := 1; i < len(growths); i++ {
+		if growths[i].Cap <= growths[i-1].Cap {
+			t.Fatalf("capacity did not grow: %+v", growths)
+		}
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, Growths(Record[int64](10))); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "backing array") || !strings.Contains(out, "2.00x") {
+		t.Fatalf("unexpected table:\n%s", out)
+	}
+}
+
+// TestAliasingAcrossReallocation walks through what the growth table
+// predicts: while there is spare capacity, append writes into the shared
+// array; once capacity runs out, the result is detached from the original.
+func TestAliasingAcrossReallocation(t *testing.T) {
+	base := make([]int, 3, 4)
+
+	within := append(base, 1) // fits: same array
+	if !SharesArray(base, within) {
+		t.Fatal("append within capacity allocated")
+	}
+	within[0] = 42
+	if base[0] != 42 {
+		t.Fatal("write through the appended slice not visible in base")
+	}
+
+	beyond := append(within, 2) // cap 4 exceeded: new array
+	if SharesArray(base, beyond) {
+		t.Fatal("append beyond capacity still shares the array")
+	}
+	beyond[0] = 7
+	if base[0] != 42 {
+		t.Fatal("write after reallocation leaked into base")
+	}
+
+	// Two appends to the same base with spare room race for the same slot:
+	// the second silently overwrites the first.
+	x := append(base, 100)
+	y := append(base, 200)
+	if x[3] != 200 || y[3] != 200 {
+		t.Fatalf("x[3] = %d, y[3] = %d; both should see the second append", x[3], y[3])
+	}
+}
+
+func TestSharesArrayPartialOverlap(t *testing.T) {
+	s := make([]byte, 10)
+	if !SharesArray(s[:2], s[5:]) {
+		t.Fatal("s[:2] has capacity reaching into s[5:], so they share the array")
+	}
+	if SharesArray(s[:2:2], s[5:]) {
+		t.Fatal("a capped slice cannot reach s[5:]")
+	}
+	if SharesArray([]byte{}, s) {
+		t.Fatal("an empty slice shares nothing")
+	}
+}
@@ -0,0 +1,81 @@
+// Package slicepool recycles slices and byte buffers between uses to cut
+// allocations on hot paths such as encoding a response.
+//
+// Pools hand out *[]T rather than []T. Putting a bare slice header into a
+// sync.Pool boxes it in an interface, which itself allocates and undoes
+// part of the saving; a pointer fits in the interface directly.
+package slicepool
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Pool recycles []T slices. The zero value is not usable; call New.
+type Pool[T any] struct {
+	pool   sync.Pool
+	maxCap int
+}
+
+// New returns a Pool whose fresh slices start with capacity initialCap.
+// Slices that have grown past maxCap are dropped on Put instead of being
+// kept alive; maxCap <= 0 means no limit.
+func New[T any](initialCap, maxCap int) *Pool[T] {
+	p := &Pool[T]{maxCap: maxCap}
+	p.pool.New = func() any {
+		s := make([]T, 0, initialCap)
+		return &s
+	}
+	return p
+}
+
+// Get returns an empty slice, reused if possible. Append through the
+// pointer ((*s) = append((*s), v)) and hand the same pointer back to Put.
+func (p *Pool[T]) Get() *[]T {
+	s := p.pool.Get().(*[]T)
+	*s = (*s)[:0]
+	return s
+}
+
+// Put returns s to the pool. Elements are zeroed first so the pool does
+// not keep whatever they point to alive. The caller must not use s
+// afterwards.
+func (p *Pool[T]) Put(s *[]T) {
+	if s == nil || (p.maxCap > 0 && cap(*s) > p.maxCap) {
+		return
+	}
+	clear((*s)[:cap(*s)])
+	*s = (*s)[:0]
+	p.pool.Put(s)
+}
+
+// BufferPool recycles bytes.Buffers, for example as scratch space for
+// json.NewEncoder.
+type BufferPool struct {
+	pool   sync.Pool
+	maxCap int
+}
+
+// NewBufferPool returns a BufferPool that drops buffers grown past maxCap
+// bytes; maxCap <= 0 means no limit.
+func NewBufferPool(maxCap int) *BufferPool {
+	return &BufferPool{
+		pool:   sync.Pool{New: func() any { return new(bytes.Buffer) }},
+		maxCap: maxCap,
+	}
+}
+
+// Get returns an empty buffer.
+func (p *BufferPool) Get() *bytes.Buffer {
+	b := p.pool.Get().(*bytes.Buffer)
+	b.Reset()
+	return b
+}
+
+// Put returns b to the pool. The caller must not use b afterwards.
+func (p *BufferPool) Put(b *bytes.Buffer) {
+	if b == nil || (p.maxCap > 0 && b.Cap() > p.maxCap) {
+		return
+	}
+	p.pool.Put(b)
+}
@@ -0,0 +1,98 @@
+package slicepool
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestPoolReturnsEmptySlices(t *testing.T) {
+	p := New[int](4, 0)
+	s := p.Get()
+	*s = append(*s, 1, 2, 3)
+	p.Put(s)
+
+	again := p.Get()
+	if len(*again) != 0 {
+		t.Fatalf("reused slice has len %d", len(*again))
+	}
+	if cap(*again) < 4 {
+		t.Fatalf("cap = %d, want at least the initial 4", cap(*again))
+	}
+}
+
+func TestPoolZeroesElements(t *testing.T) {
+	p := New[*int](1, 0)
+	s := p.Get()
+	n := 7
+	*s = append(*s, &n)
+	backing := (*s)[:1]
+	p.Put(s)
+	if backing[0] != nil {
+		t.Fatal("Put left a pointer in the pooled array")
+	}
+}
+
+func TestPoolDropsOversized(t *testing.T) {
+	p := New[byte](8, 16)
+	s := p.Get()
+	*s = make([]byte, 0, 1024)
+	p.Put(s) // dropped
+	if got := p.Get(); cap(*got) == 1024 {
+		t.Fatal("oversized slice was pooled")
+	}
+	p.Put(nil) // must not panic
+}
+
+func TestBufferPool(t *testing.T) {
+	p := NewBufferPool(0)
+	b := p.Get()
+	b.WriteString("hello")
+	p.Put(b)
+	if got := p.Get(); got.Len() != 0 {
+		t.Fatalf("reused buffer holds %q", got.String())
+	}
+}
+
+type product struct {
+	ID    int     `json:"id"`
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+var catalog = func() []product {
+	ps := make([]product, 50)
+	for i := range ps {
+		ps[i] = product{ID: i, Name: "widget", Price: float64(i) * 1.5}
+	}
+	return ps
+}()
+
+// The encode benchmarks mirror a list handler: collect the rows, encode
+// them into a buffer, write the buffer out.
+
+func BenchmarkEncodeFresh(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		var rows []product
+		rows = append(rows, catalog...)
+		var buf []byte
+		buf, _ = json.Marshal(rows)
+		io.Discard.Write(buf)
+	}
+}
+
+func BenchmarkEncodePooled(b *testing.B) {
+	rowsPool := New[product](64, 0)
+	bufPool := NewBufferPool(1 << 20)
+	b.ReportAllocs()
+	for b.Loop() {
+		rows := rowsPool.Get()
+		*rows = append(*rows, catalog...)
+		buf := bufPool.Get()
+		json.NewEncoder(buf).Encode(*rows)
+		io.Discard.Write(buf.Bytes())
+		bufPool.Put(buf)
+		rowsPool.Put(rows)
+	}
+}
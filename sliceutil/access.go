@@ -0,0 +1,35 @@
+package sliceutil
+
+import "fmt"
+
+// At returns s[i] and true, or the zero value and false if i is out of
+// range. Negative indexes count from the end, so At(s, -1) is the last
+// element.
+func At[S ~[]E, E any](s S, i int) (E, bool) {
+	if i < 0 {
+		i += len(s)
+	}
+	if i < 0 || i >= len(s) {
+		var zero E
+		return zero, false
+	}
+	return s[i], true
+}
+
+// First returns the first element of s, or false if s is empty.
+func First[S ~[]E, E any](s S) (E, bool) { return At(s, 0) }
+
+// Last returns the last element of s, or false if s is empty. It is the
+// checked form of s[len(s)-1].
+func Last[S ~[]E, E any](s S) (E, bool) { return At(s, -1) }
+
+// MustAt is At for indexes the caller has already validated. It panics
+// with the index and length instead of the runtime's bare "index out of
+// range".
+func MustAt[S ~[]E, E any](s S, i int) E {
+	v, ok := At(s, i)
+	if !ok {
+		panic(fmt.Sprintf("sliceutil: index %d out of range for slice of length %d", i, len(s)))
+	}
+	return v
+}
@@ -0,0 +1,54 @@
+package sliceutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAt(t *testing.T) {
+	s := []string{"a", "b", "c"}
+	tests := []struct {
+		i      int
+		want   string
+		wantOK bool
+	}{
+		{0, "a", true},
+		{2, "c", true},
+		{-1, "c", true},
+		{-3, "a", true},
+		{3, "", false},
+		{-4, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := At(s, tt.i)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("At(%d) = %q, %v; want %q, %v", tt.i, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestFirstLastEmpty(t *testing.T) {
+	var empty []int
+	if _, ok := First(empty); ok {
+		t.Error("First(empty) reported ok")
+	}
+	if _, ok := Last(empty); ok {
+		t.Error("Last(empty) reported ok")
+	}
+	if v, ok := Last([]int{1, 2, 3}); !ok || v != 3 {
+		t.Errorf("Last() = %d, %v", v, ok)
+	}
+	if v, ok := First([]int{1, 2, 3}); !ok || v != 1 {
+		t.Errorf("First() = %d, %v", v, ok)
+	}
+}
+
+func TestMustAtPanicMessage(t *testing.T) {
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, "index 5") || !strings.Contains(msg, "length 2") {
+			t.Fatalf("panic = %q", msg)
+		}
+	}()
+	MustAt([]int{1, 2}, 5)
+}
@@ -0,0 +1,66 @@
+package sliceutil
+
+import (
+	"iter"
+	"slices"
+)
+
+// Chunk splits s into consecutive chunks of n elements; the last chunk may
+// be shorter. The chunks are carved out of a single copy of s, capped so
+// that appending to one chunk cannot overwrite the next. Chunk panics if
+// n < 1.
+func Chunk[S ~[]E, E any](s S, n int) []S {
+	if n < 1 {
+		panic("sliceutil: Chunk size must be positive")
+	}
+	if len(s) == 0 {
+		return nil
+	}
+	buf := slices.Clone(s)
+	out := make([]S, 0, (len(buf)+n-1)/n)
+	for len(buf) > 0 {
+		end := min(n, len(buf))
+		out = append(out, buf[:end:end])
+		buf = buf[end:]
+	}
+	return out
+}
+
+// Batch groups the values of seq into slices of up to n elements without
+// reading the whole sequence first, which suits sources like a CSV reader.
+// Each yielded batch is freshly allocated and may be retained. Batch
+// panics if n < 1.
+func Batch[E any](seq iter.Seq[E], n int) iter.Seq[[]E] {
+	if n < 1 {
+		panic("sliceutil: Batch size must be positive")
+	}
+	return func(yield func([]E) bool) {
+		batch := make([]E, 0, n)
+		for v := range seq {
+			batch = append(batch, v)
+			if len(batch) == n {
+				if !yield(batch) {
+					return
+				}
+				batch = make([]E, 0, n)
+			}
+		}
+		if len(batch) > 0 {
+			yield(batch)
+		}
+	}
+}
+
+// Flatten concatenates the slices in s into one newly allocated slice,
+// sized up front so it is filled without reallocating.
+func Flatten[S ~[]E, E any](s []S) S {
+	total := 0
+	for _, inner := range s {
+		total += len(inner)
+	}
+	out := make(S, 0, total)
+	for _, inner := range s {
+		out = append(out, inner...)
+	}
+	return out
+}
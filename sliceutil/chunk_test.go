@@ -0,0 +1,93 @@
+package sliceutil
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []int
+		n    int
+		want [][]int
+	}{
+		{"even", []int{1, 2, 3, 4}, 2, [][]int{{1, 2}, {3, 4}}},
+		{"remainder", []int{1, 2, 3, 4, 5}, 2, [][]int{{1, 2}, {3, 4}, {5}}},
+		{"size larger than input", []int{1, 2}, 5, [][]int{{1, 2}}},
+		{"empty", nil, 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Chunk(tt.in, tt.n)
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Fatalf("Chunk() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkIsolation(t *testing.T) {
+	in := []int{1, 2, 3, 4}
+	chunks := Chunk(in, 2)
+
+	chunks[0] = append(chunks[0], 99)
+	if chunks[1][0] != 3 {
+		t.Fatal("appending to one chunk overwrote the next")
+	}
+	chunks[1][0] = -1
+	if in[2] != 3 {
+		t.Fatal("writing to a chunk changed the input")
+	}
+}
+
+func TestChunkPanicsOnBadSize(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Chunk(s, 0) did not panic")
+		}
+	}()
+	Chunk([]int{1}, 0)
+}
+
+func TestBatch(t *testing.T) {
+	var got [][]int
+	for b := range Batch(slices.Values([]int{1, 2, 3, 4, 5}), 2) {
+		got = append(got, b)
+	}
+	want := [][]int{{1, 2}, {3, 4}, {5}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("Batch() = %v, want %v", got, want)
+	}
+}
+
+func TestBatchStopsEarly(t *testing.T) {
+	pulled := 0
+	src := func(yield func(int) bool) {
+		for i := range 100 {
+			pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	for range Batch(src, 3) {
+		break
+	}
+	if pulled != 3 {
+		t.Fatalf("source produced %d values, want 3", pulled)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	got := Flatten([][]int{{1, 2}, nil, {3}, {4, 5}})
+	if want := []int{1, 2, 3, 4, 5}; !slices.Equal(got, want) {
+		t.Fatalf("Flatten() = %v, want %v", got, want)
+	}
+	if cap(got) != len(got) {
+		t.Fatalf("cap = %d, want exactly %d", cap(got), len(got))
+	}
+	if got := Flatten[[]int](nil); len(got) != 0 {
+		t.Fatalf("Flatten(nil) = %v", got)
+	}
+}
@@ -0,0 +1,54 @@
+package sliceutil_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stawuah/pounce-on-go/sliceutil"
+)
+
+func ExampleMap() {
+	names := []string{"ada", "grace"}
+	fmt.Println(sliceutil.Map(names, strings.ToUpper))
+	// Output: [ADA GRACE]
+}
+
+func ExampleFilter() {
+	prices := []float64{4.5, 12, 30, 8.25}
+	cheap := sliceutil.Filter(prices, func(p float64) bool { return p < 10 })
+	fmt.Println(cheap)
+	// Output: [4.5 8.25]
+}
+
+func ExampleReduce() {
+	total := sliceutil.Reduce([]float64{4.5, 12, 8.25}, 0.0, func(sum, p float64) float64 { return sum + p })
+	fmt.Println(total)
+	// Output: 24.75
+}
+
+func ExampleFind() {
+	skus := []string{"A-100", "B-200", "B-300"}
+	sku, ok := sliceutil.Find(skus, func(s string) bool { return strings.HasPrefix(s, "B-") })
+	fmt.Println(sku, ok)
+	// Output: B-200 true
+}
+
+func ExampleAll() {
+	stock := []int{3, 0, 7}
+	inStock := sliceutil.All(stock, func(n int) bool { return n > 0 })
+	anyOut := sliceutil.Any(stock, func(n int) bool { return n == 0 })
+	fmt.Println(inStock, anyOut)
+	// Output: false true
+}
+
+func ExampleContains() {
+	fmt.Println(sliceutil.Contains([]string{"red", "green"}, "green"))
+	// Output: true
+}
+
+func ExampleGroupBy() {
+	words := []string{"go", "rust", "gleam", "ruby", "c"}
+	byInitial := sliceutil.GroupBy(words, func(w string) byte { return w[0] })
+	fmt.Println(byInitial['g'], byInitial['r'], byInitial['c'])
+	// Output: [go gleam] [rust ruby] [c]
+}
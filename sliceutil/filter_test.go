@@ -0,0 +1,77 @@
+package sliceutil
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFilterInPlace(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5, 6}
+	got := FilterInPlace(s, isEven)
+	if !slices.Equal(got, []int{2, 4, 6}) {
+		t.Fatalf("FilterInPlace() = %v", got)
+	}
+	if got := FilterInPlace([]int(nil), isEven); len(got) != 0 {
+		t.Fatalf("FilterInPlace(nil) = %v", got)
+	}
+}
+
+// TestFilterInPlaceAliasing shows what "in place" means for the caller's
+// original slice: same backing array, old length, rearranged contents.
+func TestFilterInPlaceAliasing(t *testing.T) {
+	original := []int{1, 2, 3, 4, 5, 6}
+	filtered := FilterInPlace(original, isEven)
+
+	if &filtered[0] != &original[0] {
+		t.Fatal("result does not share the original backing array")
+	}
+	if len(original) != 6 || cap(filtered) != cap(original) {
+		t.Fatalf("len(original) = %d, cap(filtered) = %d", len(original), cap(filtered))
+	}
+	if want := []int{2, 4, 6, 0, 0, 0}; !slices.Equal(original, want) {
+		t.Fatalf("original = %v, want %v", original, want)
+	}
+
+	// Writes through either header land in the same memory...
+	filtered[0] = 20
+	if original[0] != 20 {
+		t.Fatal("write through result not visible in original")
+	}
+	// ...and appending to the result overwrites the original's tail
+	// because there is spare capacity.
+	filtered = append(filtered, 8)
+	if original[3] != 8 {
+		t.Fatalf("append did not reuse the original array: %v", original)
+	}
+}
+
+func TestFilterAllocatesFresh(t *testing.T) {
+	original := []int{1, 2, 3, 4}
+	filtered := Filter(original, isEven)
+	filtered[0] = 20
+	if !slices.Equal(original, []int{1, 2, 3, 4}) {
+		t.Fatalf("Filter result aliases its input: %v", original)
+	}
+}
+
+func BenchmarkFilter(b *testing.B) {
+	src := make([]int, 4096)
+	for i := range src {
+		src[i] = i
+	}
+	work := make([]int, len(src))
+
+	b.Run("allocating", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			Filter(src, isEven)
+		}
+	})
+	b.Run("in-place", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			copy(work, src) // restore the input each round
+			FilterInPlace(work, isEven)
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package sliceutil
+
+// CountBy returns how many elements of s map to each key.
+func CountBy[S ~[]E, E any, K comparable](s S, key func(E) K) map[K]int {
+	counts := make(map[K]int)
+	for _, v := range s {
+		counts[key(v)]++
+	}
+	return counts
+}
+
+// GroupBy partitions s by key. Each group keeps the elements in their
+// original order; the groups are new slices.
+func GroupBy[S ~[]E, E any, K comparable](s S, key func(E) K) map[K]S {
+	groups := make(map[K]S)
+	for _, v := range s {
+		k := key(v)
+		groups[k] = append(groups[k], v)
+	}
+	return groups
+}
@@ -0,0 +1,61 @@
+package sliceutil
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type item struct {
+	name     string
+	category string
+	price    float64
+}
+
+var inventory = []item{
+	{"apple", "fruit", 0.5},
+	{"hammer", "tools", 12},
+	{"pear", "fruit", 0.75},
+	{"saw", "tools", 25},
+	{"melon", "fruit", 3},
+}
+
+func TestCountBy(t *testing.T) {
+	got := CountBy(inventory, func(i item) string { return i.category })
+	if want := map[string]int{"fruit": 3, "tools": 2}; !maps.Equal(got, want) {
+		t.Fatalf("CountBy() = %v, want %v", got, want)
+	}
+	if got := CountBy([]string(nil), strings.ToLower); len(got) != 0 {
+		t.Fatalf("CountBy(nil) = %v", got)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	band := func(i item) string {
+		switch {
+		case i.price < 1:
+			return "under-1"
+		case i.price < 20:
+			return "1-20"
+		default:
+			return "20+"
+		}
+	}
+	groups := GroupBy(inventory, band)
+
+	names := func(items []item) []string { return Map(items, func(i item) string { return i.name }) }
+	tests := map[string][]string{
+		"under-1": {"apple", "pear"},
+		"1-20":    {"hammer", "melon"},
+		"20+":     {"saw"},
+	}
+	if len(groups) != len(tests) {
+		t.Fatalf("got %d groups, want %d", len(groups), len(tests))
+	}
+	for key, want := range tests {
+		if got := names(groups[key]); !slices.Equal(got, want) {
+			t.Errorf("group %q = %v, want %v", key, got, want)
+		}
+	}
+}
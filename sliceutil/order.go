@@ -0,0 +1,93 @@
+package sliceutil
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// Dedup returns the distinct elements of s in order of first appearance.
+func Dedup[S ~[]E, E comparable](s S) S {
+	return DedupInPlace(slices.Clone(s))
+}
+
+// DedupInPlace moves the distinct elements of s, in order of first
+// appearance, to the front of s and returns that prefix. Elements past the
+// returned length are zeroed so the backing array does not keep stale
+// references alive.
+func DedupInPlace[S ~[]E, E comparable](s S) S {
+	seen := make(map[E]struct{}, len(s))
+	out := s[:0]
+	for _, v := range s {
+		if _, dup := seen[v]; dup {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	clear(s[len(out):])
+	return out
+}
+
+// Reverse returns a reversed copy of s.
+func Reverse[S ~[]E, E any](s S) S {
+	out := make(S, len(s))
+	for i, v := range s {
+		out[len(s)-1-i] = v
+	}
+	return out
+}
+
+// ReverseInPlace reverses s.
+func ReverseInPlace[S ~[]E, E any](s S) {
+	slices.Reverse(s)
+}
+
+// Shuffle returns a shuffled copy of s. A nil rng uses the global source;
+// pass a seeded *rand.Rand for reproducible order.
+func Shuffle[S ~[]E, E any](s S, rng *rand.Rand) S {
+	out := slices.Clone(s)
+	ShuffleInPlace(out, rng)
+	return out
+}
+
+// ShuffleInPlace shuffles s with a Fisher–Yates pass. A nil rng uses the
+// global source.
+func ShuffleInPlace[S ~[]E, E any](s S, rng *rand.Rand) {
+	swap := func(i, j int) { s[i], s[j] = s[j], s[i] }
+	if rng == nil {
+		rand.Shuffle(len(s), swap)
+		return
+	}
+	rng.Shuffle(len(s), swap)
+}
+
+// Rotate returns a copy of s rotated left by k positions; a negative k
+// rotates right. k may exceed len(s).
+func Rotate[S ~[]E, E any](s S, k int) S {
+	if len(s) == 0 {
+		return S{}
+	}
+	k = normalize(k, len(s))
+	out := make(S, 0, len(s))
+	return append(append(out, s[k:]...), s[:k]...)
+}
+
+// RotateInPlace rotates s left by k positions using three reversals, so it
+// needs no scratch space.
+func RotateInPlace[S ~[]E, E any](s S, k int) {
+	if len(s) == 0 {
+		return
+	}
+	k = normalize(k, len(s))
+	slices.Reverse(s[:k])
+	slices.Reverse(s[k:])
+	slices.Reverse(s)
+}
+
+func normalize(k, n int) int {
+	k %= n
+	if k < 0 {
+		k += n
+	}
+	return k
+}
@@ -0,0 +1,135 @@
+package sliceutil
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"testing/quick"
+)
+
+func TestDedup(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []int
+		want []int
+	}{
+		{"keeps first occurrence", []int{3, 1, 3, 2, 1}, []int{3, 1, 2}},
+		{"already unique", []int{1, 2}, []int{1, 2}},
+		{"empty", nil, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Dedup(tt.in); !slices.Equal(got, tt.want) {
+				t.Fatalf("Dedup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDedupInPlaceAliasing(t *testing.T) {
+	s := []int{1, 1, 2, 2, 3}
+	view := s[:] // another slice over the same backing array
+	out := DedupInPlace(s)
+
+	if !slices.Equal(out, []int{1, 2, 3}) {
+		t.Fatalf("DedupInPlace() = %v", out)
+	}
+	if &out[0] != &s[0] {
+		t.Fatal("DedupInPlace allocated a new backing array")
+	}
+	// The other view still has the old length and now sees the compacted
+	// prefix followed by zeroed slots.
+	if !slices.Equal(view, []int{1, 2, 3, 0, 0}) {
+		t.Fatalf("view = %v", view)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		k    int
+		want []int
+	}{
+		{0, []int{1, 2, 3, 4, 5}},
+		{2, []int{3, 4, 5, 1, 2}},
+		{-1, []int{5, 1, 2, 3, 4}},
+		{7, []int{3, 4, 5, 1, 2}},
+	}
+	for _, tt := range tests {
+		in := []int{1, 2, 3, 4, 5}
+		if got := Rotate(in, tt.k); !slices.Equal(got, tt.want) {
+			t.Errorf("Rotate(%d) = %v, want %v", tt.k, got, tt.want)
+		}
+		RotateInPlace(in, tt.k)
+		if !slices.Equal(in, tt.want) {
+			t.Errorf("RotateInPlace(%d) = %v, want %v", tt.k, in, tt.want)
+		}
+	}
+}
+
+func TestShuffleSeeded(t *testing.T) {
+	in := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	a := Shuffle(in, rand.New(rand.NewPCG(1, 2)))
+	b := Shuffle(in, rand.New(rand.NewPCG(1, 2)))
+	if !slices.Equal(a, b) {
+		t.Fatalf("same seed gave %v and %v", a, b)
+	}
+	if !slices.Equal(in, []int{1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Fatal("Shuffle modified its input")
+	}
+}
+
+// The properties below are checked against random inputs by testing/quick.
+
+func TestPropertyReverseTwiceIsIdentity(t *testing.T) {
+	prop := func(s []int) bool {
+		if !slices.Equal(Reverse(Reverse(s)), s) {
+			return false
+		}
+		c := slices.Clone(s)
+		ReverseInPlace(c)
+		ReverseInPlace(c)
+		return slices.Equal(c, s)
+	}
+	if err := quick.Check(prop, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPropertyRotateInverse(t *testing.T) {
+	prop := func(s []int, k int8) bool {
+		return slices.Equal(Rotate(Rotate(s, int(k)), -int(k)), s)
+	}
+	if err := quick.Check(prop, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPropertyRotateMatchesInPlace(t *testing.T) {
+	prop := func(s []int, k int8) bool {
+		c := slices.Clone(s)
+		RotateInPlace(c, int(k))
+		return slices.Equal(c, Rotate(s, int(k)))
+	}
+	if err := quick.Check(prop, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPropertyShuffleIsPermutation(t *testing.T) {
+	prop := func(s []int) bool {
+		return slices.Equal(slices.Sorted(slices.Values(Shuffle(s, nil))), slices.Sorted(slices.Values(s)))
+	}
+	if err := quick.Check(prop, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPropertyDedupIdempotent(t *testing.T) {
+	prop := func(s []uint8) bool {
+		once := Dedup(s)
+		return slices.Equal(Dedup(once), once) && All(s, func(v uint8) bool { return Contains(once, v) })
+	}
+	if err := quick.Check(prop, nil); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,92 @@
+// Package sliceutil holds generic helpers for working with slices. It
+// complements the standard slices package with the functional helpers it
+// leaves out.
+//
+// Functions never modify their input and return slices that do not share a
+// backing array with it — except those whose names end in InPlace. Those
+// rearrange the elements of s where they sit, so every other slice
+// sharing s's backing array sees the new order, and any result they return
+// is a reslice of s rather than a copy. Windows is the one read-only
+// exception: it yields views into s to avoid copying every window.
+package sliceutil
+
+import "slices"
+
+// Map returns a new slice holding f applied to each element of s.
+func Map[S ~[]E, E, R any](s S, f func(E) R) []R {
+	out := make([]R, len(s))
+	for i, v := range s {
+		out[i] = f(v)
+	}
+	return out
+}
+
+// Filter returns a new slice holding the elements of s for which keep
+// returns true, in their original order.
+func Filter[S ~[]E, E any](s S, keep func(E) bool) S {
+	var out S
+	for _, v := range s {
+		if keep(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// FilterInPlace keeps the elements of s for which keep returns true and
+// returns them as a prefix of s. It allocates nothing: the result is
+// s[:0] grown by append, which writes into s's own backing array. The
+// original slice header still has its old length, so reading past the
+// returned length through it shows the zeroed tail, not the removed
+// elements.
+func FilterInPlace[S ~[]E, E any](s S, keep func(E) bool) S {
+	out := s[:0]
+	for _, v := range s {
+		if keep(v) {
+			out = append(out, v)
+		}
+	}
+	clear(s[len(out):])
+	return out
+}
+
+// Reduce folds s from left to right, starting with init.
+func Reduce[S ~[]E, E, A any](s S, init A, f func(acc A, v E) A) A {
+	acc := init
+	for _, v := range s {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+// Find returns the first element of s matching pred. The second result
+// reports whether one was found.
+func Find[S ~[]E, E any](s S, pred func(E) bool) (E, bool) {
+	if i := slices.IndexFunc(s, pred); i >= 0 {
+		return s[i], true
+	}
+	var zero E
+	return zero, false
+}
+
+// Any reports whether pred holds for at least one element of s. It is
+// false for an empty slice.
+func Any[S ~[]E, E any](s S, pred func(E) bool) bool {
+	return slices.ContainsFunc(s, pred)
+}
+
+// All reports whether pred holds for every element of s. It is true for an
+// empty slice.
+func All[S ~[]E, E any](s S, pred func(E) bool) bool {
+	for _, v := range s {
+		if !pred(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains reports whether v is present in s.
+func Contains[S ~[]E, E comparable](s S, v E) bool {
+	return slices.Contains(s, v)
+}
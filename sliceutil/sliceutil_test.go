@@ -0,0 +1,104 @@
+package sliceutil
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func isEven(n int) bool { return n%2 == 0 }
+
+func TestMap(t *testing.T) {
+	in := []int{1, 2, 3}
+	got := Map(in, strconv.Itoa)
+	if want := []string{"1", "2", "3"}; !slices.Equal(got, want) {
+		t.Fatalf("Map() = %v, want %v", got, want)
+	}
+	if got := Map([]int(nil), strconv.Itoa); len(got) != 0 {
+		t.Fatalf("Map(nil) = %v, want empty", got)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []int
+		want []int
+	}{
+		{"mixed", []int{1, 2, 3, 4, 5, 6}, []int{2, 4, 6}},
+		{"none match", []int{1, 3}, nil},
+		{"empty", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Filter(tt.in, isEven); !slices.Equal(got, tt.want) {
+				t.Fatalf("Filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterDoesNotAlias(t *testing.T) {
+	in := []int{2, 4, 6}
+	out := Filter(in, isEven)
+	out[0] = 100
+	if in[0] != 2 {
+		t.Fatal("writing to Filter's result changed its input")
+	}
+}
+
+func TestReduce(t *testing.T) {
+	sum := Reduce([]int{1, 2, 3, 4}, 0, func(acc, v int) int { return acc + v })
+	if sum != 10 {
+		t.Fatalf("sum = %d, want 10", sum)
+	}
+	joined := Reduce([]int{1, 2, 3}, "", func(acc string, v int) string { return acc + strconv.Itoa(v) })
+	if joined != "123" {
+		t.Fatalf("joined = %q, want \"123\"", joined)
+	}
+	if got := Reduce([]int(nil), 7, func(acc, v int) int { return acc + v }); got != 7 {
+		t.Fatalf("Reduce(nil) = %d, want init", got)
+	}
+}
+
+func TestFind(t *testing.T) {
+	if v, ok := Find([]int{1, 3, 4, 6}, isEven); !ok || v != 4 {
+		t.Fatalf("Find() = %d, %v; want 4, true", v, ok)
+	}
+	if v, ok := Find([]int{1, 3}, isEven); ok || v != 0 {
+		t.Fatalf("Find() = %d, %v; want 0, false", v, ok)
+	}
+}
+
+func TestAnyAll(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []int
+		any  bool
+		all  bool
+	}{
+		{"empty", nil, false, true},
+		{"all even", []int{2, 4}, true, true},
+		{"some even", []int{1, 2}, true, false},
+		{"no even", []int{1, 3}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Any(tt.in, isEven); got != tt.any {
+				t.Errorf("Any() = %v, want %v", got, tt.any)
+			}
+			if got := All(tt.in, isEven); got != tt.all {
+				t.Errorf("All() = %v, want %v", got, tt.all)
+			}
+		})
+	}
+}
+
+func TestContains(t *testing.T) {
+	if !Contains([]string{"a", "b"}, "b") {
+		t.Error("Contains(b) = false")
+	}
+	if Contains([]string{"a", "b"}, "c") {
+		t.Error("Contains(c) = true")
+	}
+}
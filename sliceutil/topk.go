@@ -0,0 +1,105 @@
+package sliceutil
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// TopK returns the k elements of s that sort last under less, largest
+// first. It keeps a min-heap of the best k seen so far, so it runs in
+// O(n log k) and allocates only the k-element result. If k >= len(s) the
+// whole slice is returned sorted descending.
+func TopK[S ~[]E, E any](s S, k int, less func(a, b E) bool) S {
+	if k <= 0 {
+		return S{}
+	}
+	k = min(k, len(s))
+	h := make(S, 0, k)
+	for _, v := range s {
+		if len(h) < k {
+			h = append(h, v)
+			siftUp(h, len(h)-1, less)
+			continue
+		}
+		if less(h[0], v) { // v beats the weakest of the current top k
+			h[0] = v
+			siftDown(h, 0, less)
+		}
+	}
+	slices.SortFunc(h, func(a, b E) int {
+		switch {
+		case less(b, a):
+			return -1
+		case less(a, b):
+			return 1
+		}
+		return 0
+	})
+	return h
+}
+
+func siftUp[E any](h []E, i int, less func(a, b E) bool) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !less(h[i], h[parent]) {
+			return
+		}
+		h[i], h[parent] = h[parent], h[i]
+		i = parent
+	}
+}
+
+func siftDown[E any](h []E, i int, less func(a, b E) bool) {
+	for {
+		smallest := i
+		for _, c := range []int{2*i + 1, 2*i + 2} {
+			if c < len(h) && less(h[c], h[smallest]) {
+				smallest = c
+			}
+		}
+		if smallest == i {
+			return
+		}
+		h[i], h[smallest] = h[smallest], h[i]
+		i = smallest
+	}
+}
+
+// Kth returns the element that would sit at index k if s were sorted by
+// less, using quickselect on a copy of s: O(n) on average, with random
+// pivots so sorted input is not a worst case. It panics if k is out of
+// range.
+func Kth[S ~[]E, E any](s S, k int, less func(a, b E) bool) E {
+	if k < 0 || k >= len(s) {
+		panic("sliceutil: Kth index out of range")
+	}
+	work := slices.Clone(s)
+	lo, hi := 0, len(work)-1
+	for lo < hi {
+		p := partition(work, lo, hi, lo+rand.N(hi-lo+1), less)
+		switch {
+		case k < p:
+			hi = p - 1
+		case k > p:
+			lo = p + 1
+		default:
+			return work[k]
+		}
+	}
+	return work[k]
+}
+
+// partition moves s[pivot] to its sorted position within s[lo:hi+1] and
+// returns that position (Lomuto scheme).
+func partition[E any](s []E, lo, hi, pivot int, less func(a, b E) bool) int {
+	s[pivot], s[hi] = s[hi], s[pivot]
+	store := lo
+	for i := lo; i < hi; i++ {
+		if less(s[i], s[hi]) {
+			s[i], s[store] = s[store], s[i]
+			store++
+		}
+	}
+	s[store], s[hi] = s[hi], s[store]
+	return store
+}
@@ -0,0 +1,94 @@
+package sliceutil
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func intLess(a, b int) bool { return a < b }
+
+func TestTopK(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []int
+		k    int
+		want []int
+	}{
+		{"top three", []int{5, 1, 9, 3, 7, 2}, 3, []int{9, 7, 5}},
+		{"k larger than input", []int{2, 1}, 5, []int{2, 1}},
+		{"duplicates", []int{4, 4, 1, 4}, 2, []int{4, 4}},
+		{"zero k", []int{1, 2}, 0, []int{}},
+		{"empty", nil, 3, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TopK(tt.in, tt.k, intLess); !slices.Equal(got, tt.want) {
+				t.Fatalf("TopK() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopKByField(t *testing.T) {
+	byPrice := func(a, b item) bool { return a.price < b.price }
+	got := Map(TopK(inventory, 2, byPrice), func(i item) string { return i.name })
+	if !slices.Equal(got, []string{"saw", "hammer"}) {
+		t.Fatalf("most expensive = %v", got)
+	}
+}
+
+func TestTopKMatchesSort(t *testing.T) {
+	for range 50 {
+		in := rand.Perm(200)
+		k := rand.N(50) + 1
+		sorted := slices.Sorted(slices.Values(in))
+		slices.Reverse(sorted)
+		if got := TopK(in, k, intLess); !slices.Equal(got, sorted[:k]) {
+			t.Fatalf("TopK(k=%d) = %v, want %v", k, got, sorted[:k])
+		}
+	}
+}
+
+func TestKth(t *testing.T) {
+	for range 50 {
+		in := make([]int, rand.N(100)+1)
+		for i := range in {
+			in[i] = rand.N(20) // plenty of duplicates
+		}
+		sorted := slices.Sorted(slices.Values(in))
+		k := rand.N(len(in))
+		orig := slices.Clone(in)
+		if got := Kth(in, k, intLess); got != sorted[k] {
+			t.Fatalf("Kth(%v, %d) = %d, want %d", in, k, got, sorted[k])
+		}
+		if !slices.Equal(in, orig) {
+			t.Fatal("Kth modified its input")
+		}
+	}
+}
+
+func TestKthPanicsOutOfRange(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Kth did not panic")
+		}
+	}()
+	Kth([]int{1}, 1, intLess)
+}
+
+func BenchmarkTopKVersusSort(b *testing.B) {
+	in := rand.Perm(100_000)
+	b.Run("TopK", func(b *testing.B) {
+		for b.Loop() {
+			TopK(in, 10, intLess)
+		}
+	})
+	b.Run("sort-then-slice", func(b *testing.B) {
+		for b.Loop() {
+			s := slices.Clone(in)
+			slices.Sort(s)
+			_ = s[len(s)-10:]
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package sliceutil
+
+import "iter"
+
+// Windows yields every run of n consecutive elements of s, sliding one
+// element at a time: len(s)-n+1 windows in total, none if len(s) < n.
+// Each window is a view into s, capped so appending to it cannot clobber
+// s; copy it to keep it past the next iteration if s may change. Windows
+// panics if n < 1.
+func Windows[S ~[]E, E any](s S, n int) iter.Seq[S] {
+	if n < 1 {
+		panic("sliceutil: Windows size must be positive")
+	}
+	return func(yield func(S) bool) {
+		for i := 0; i+n <= len(s); i++ {
+			if !yield(s[i : i+n : i+n]) {
+				return
+			}
+		}
+	}
+}
+
+// Pair holds one element from each of two zipped slices.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Zip pairs up a and b element by element. The result is as long as the
+// shorter input.
+func Zip[A, B any](a []A, b []B) []Pair[A, B] {
+	n := min(len(a), len(b))
+	out := make([]Pair[A, B], n)
+	for i := range n {
+		out[i] = Pair[A, B]{a[i], b[i]}
+	}
+	return out
+}
+
+// Unzip splits pairs back into two slices. Unzip(Zip(a, b)) returns a and b
+// truncated to the same length.
+func Unzip[A, B any](pairs []Pair[A, B]) ([]A, []B) {
+	as := make([]A, len(pairs))
+	bs := make([]B, len(pairs))
+	for i, p := range pairs {
+		as[i], bs[i] = p.First, p.Second
+	}
+	return as, bs
+}
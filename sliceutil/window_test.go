@@ -0,0 +1,80 @@
+package sliceutil
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWindows(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []int
+		n    int
+		want [][]int
+	}{
+		{"sliding", []int{1, 2, 3, 4}, 2, [][]int{{1, 2}, {2, 3}, {3, 4}}},
+		{"exact", []int{1, 2, 3}, 3, [][]int{{1, 2, 3}}},
+		{"too short", []int{1, 2}, 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]int
+			for w := range Windows(tt.in, tt.n) {
+				got = append(got, w)
+			}
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Fatalf("Windows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowsAreCappedViews(t *testing.T) {
+	s := []int{1, 2, 3, 4}
+	for w := range Windows(s, 2) {
+		if &w[0] != &s[0] {
+			t.Fatal("window is not a view into s")
+		}
+		if cap(w) != 2 {
+			t.Fatalf("cap(window) = %d, want 2", cap(w))
+		}
+		_ = append(w, 99) // must reallocate rather than overwrite s[2]
+		break
+	}
+	if s[2] != 3 {
+		t.Fatal("append to a window overwrote the source slice")
+	}
+}
+
+// movingAverage is the kind of computation Windows is for: the mean of each
+// run of n CPU samples.
+func movingAverage(samples []float64, n int) []float64 {
+	var out []float64
+	for w := range Windows(samples, n) {
+		out = append(out, Reduce(w, 0.0, func(sum, v float64) float64 { return sum + v })/float64(n))
+	}
+	return out
+}
+
+func TestWindowsMovingAverage(t *testing.T) {
+	cpu := []float64{10, 20, 30, 40, 50}
+	if got := movingAverage(cpu, 3); !slices.Equal(got, []float64{20, 30, 40}) {
+		t.Fatalf("moving average = %v", got)
+	}
+}
+
+func TestZipUnzip(t *testing.T) {
+	names := []string{"cpu", "mem", "disk"}
+	values := []float64{0.5, 0.25}
+
+	pairs := Zip(names, values)
+	want := []Pair[string, float64]{{"cpu", 0.5}, {"mem", 0.25}}
+	if !slices.Equal(pairs, want) {
+		t.Fatalf("Zip() = %v, want %v", pairs, want)
+	}
+
+	gotNames, gotValues := Unzip(pairs)
+	if !slices.Equal(gotNames, names[:2]) || !slices.Equal(gotValues, values) {
+		t.Fatalf("Unzip() = %v, %v", gotNames, gotValues)
+	}
+}
@@ -0,0 +1,116 @@
+// Package sparse provides a slot allocator with stable handles and stable
+// element addresses.
+//
+// A plain []T has two problems as long-lived storage. Deleting from the
+// middle either shifts later elements (changing their indexes) or leaves a
+// hole that nothing reuses. And append may move the whole backing array,
+// so a *T taken earlier keeps pointing at the old copy and silently stops
+// seeing updates. Slice fixes both: freed slots go on a free list and are
+// reused, and elements live in fixed-size pages that never move once
+// allocated, so the pointer returned by Ptr stays valid for the lifetime
+// of the element.
+package sparse
+
+import "iter"
+
+const pageSize = 64
+
+// Handle identifies an element. Handles are not reused: once an element is
+// removed, its handle stays invalid even after the slot is recycled,
+// because every reuse bumps the slot's generation. The zero Handle is
+// never valid.
+type Handle struct {
+	index uint32
+	gen   uint32
+}
+
+type slot[T any] struct {
+	value T
+	gen   uint32 // generation of the current (or last) occupant
+	used  bool
+}
+
+// Slice is a sparse collection addressed by Handle. The zero value is ready
+// to use. It is not safe for concurrent use.
+type Slice[T any] struct {
+	pages []*[pageSize]slot[T]
+	free  []uint32 // indexes of vacated slots, reused LIFO
+	next  uint32   // first never-used index
+	n     int
+}
+
+// Insert stores v and returns its handle, reusing a freed slot if there is
+// one.
+func (s *Slice[T]) Insert(v T) Handle {
+	var idx uint32
+	if n := len(s.free); n > 0 {
+		idx = s.free[n-1]
+		s.free = s.free[:n-1]
+	} else {
+		idx = s.next
+		s.next++
+		if int(idx/pageSize) == len(s.pages) {
+			s.pages = append(s.pages, new([pageSize]slot[T]))
+		}
+	}
+	sl := s.slot(idx)
+	sl.gen++
+	sl.value, sl.used = v, true
+	s.n++
+	return Handle{index: idx, gen: sl.gen}
+}
+
+// Get returns the element for h.
+func (s *Slice[T]) Get(h Handle) (T, bool) {
+	if p := s.Ptr(h); p != nil {
+		return *p, true
+	}
+	var zero T
+	return zero, false
+}
+
+// Ptr returns a pointer to the element for h, or nil if h is invalid. The
+// pointer stays valid until the element is removed, no matter how many
+// elements are inserted afterwards.
+func (s *Slice[T]) Ptr(h Handle) *T {
+	if h.gen == 0 || h.index >= s.next {
+		return nil
+	}
+	sl := s.slot(h.index)
+	if !sl.used || sl.gen != h.gen {
+		return nil
+	}
+	return &sl.value
+}
+
+// Remove deletes the element for h and reports whether it existed.
+func (s *Slice[T]) Remove(h Handle) bool {
+	if s.Ptr(h) == nil {
+		return false
+	}
+	sl := s.slot(h.index)
+	var zero T
+	sl.value, sl.used = zero, false
+	s.free = append(s.free, h.index)
+	s.n--
+	return true
+}
+
+// Len returns the number of live elements.
+func (s *Slice[T]) Len() int { return s.n }
+
+// All iterates live elements in slot order.
+func (s *Slice[T]) All() iter.Seq2[Handle, T] {
+	return func(yield func(Handle, T) bool) {
+		for i := range s.next {
+			sl := s.slot(i)
+			if sl.used && !yield(Handle{index: i, gen: sl.gen}, sl.value) {
+				return
+			}
+		}
+	}
+}
+
+func (s *Slice[T]) slot(idx uint32) *slot[T] {
+	return &s.pages[idx/pageSize][idx%pageSize]
+}
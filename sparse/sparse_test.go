@@ -0,0 +1,95 @@
+package sparse
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInsertGetRemove(t *testing.T) {
+	var s Slice[string]
+	a := s.Insert("a")
+	b := s.Insert("b")
+
+	if v, ok := s.Get(a); !ok || v != "a" {
+		t.Fatalf("Get(a) = %q, %v", v, ok)
+	}
+	if !s.Remove(a) || s.Remove(a) {
+		t.Fatal("Remove should succeed once")
+	}
+	if _, ok := s.Get(a); ok {
+		t.Fatal("Get of removed handle reported ok")
+	}
+	if s.Len() != 1 {
+		t.Fatalf("Len() = %d", s.Len())
+	}
+	if v, _ := s.Get(b); v != "b" {
+		t.Fatal("removing a disturbed b")
+	}
+	if _, ok := s.Get(Handle{}); ok {
+		t.Fatal("zero Handle reported ok")
+	}
+}
+
+func TestSlotReuseInvalidatesOldHandle(t *testing.T) {
+	var s Slice[int]
+	old := s.Insert(1)
+	s.Remove(old)
+	fresh := s.Insert(2)
+
+	if fresh.index != old.index {
+		t.Fatal("freed slot was not reused")
+	}
+	if _, ok := s.Get(old); ok {
+		t.Fatal("stale handle resolved to the slot's new occupant")
+	}
+	if v, _ := s.Get(fresh); v != 2 {
+		t.Fatalf("Get(fresh) = %d", v)
+	}
+}
+
+// TestPointerStability contrasts Slice with a plain slice: after enough
+// appends, a pointer into a []T refers to a stale copy, while a pointer
+// from Slice.Ptr keeps tracking the live element.
+func TestPointerStability(t *testing.T) {
+	plain := make([]int, 1, 1)
+	plainPtr := &plain[0]
+	for i := range 1000 {
+		plain = append(plain, i)
+	}
+	plain[0] = 42
+	if *plainPtr == 42 {
+		t.Fatal("expected the plain slice to have moved away from plainPtr")
+	}
+
+	var s Slice[int]
+	h := s.Insert(0)
+	ptr := s.Ptr(h)
+	for i := range 1000 {
+		s.Insert(i)
+	}
+	*s.Ptr(h) = 42
+	if *ptr != 42 {
+		t.Fatal("pointer from Ptr went stale after growth")
+	}
+}
+
+func TestAll(t *testing.T) {
+	var s Slice[int]
+	var handles []Handle
+	for i := range 5 {
+		handles = append(handles, s.Insert(i))
+	}
+	s.Remove(handles[1])
+	s.Remove(handles[3])
+
+	var got []int
+	for h, v := range s.All() {
+		if p := s.Ptr(h); p == nil || *p != v {
+			t.Fatalf("All yielded an invalid handle for %d", v)
+		}
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{0, 2, 4}) {
+		t.Fatalf("All() = %v", got)
+	}
+}
@@ -0,0 +1,161 @@
+// Package statsd receives metrics over UDP in the statsd line format and
+// applies them to a counters.Registry.
+//
+// A packet holds one or more newline-separated lines:
+//
+//	name:value|c[|@rate]   counter: add value/rate (the client sampled)
+//	name:value|g           gauge: set to value
+//	name:+value|g          gauge: add (or subtract with -) value
+//
+// UDP can drop, truncate or reorder packets and nobody is told, so the
+// parser takes what it can: a malformed line is counted and skipped and
+// the rest of its packet still applies. Counters are sums, so a lost
+// packet undercounts but never corrupts; gauges keep the last value seen.
+// Updates go straight to the registry's atomic counters and gauges, so
+// its /metrics handler always shows the current aggregate.
+package statsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/stawuah/pounce-on-go/counters"
+)
+
+// Kind is the type of a metric.
+type Kind byte
+
+const (
+	Counter Kind = 'c'
+	Gauge   Kind = 'g'
+)
+
+// Metric is one parsed line.
+type Metric struct {
+	Name  string
+	Kind  Kind
+	Value float64
+	Delta bool    // a gauge line with an explicit sign
+	Rate  float64 // sample rate in (0, 1]; 1 if absent
+}
+
+// ErrMalformed is wrapped by Parse errors.
+var ErrMalformed = errors.New("statsd: malformed line")
+
+// Parse parses one line.
+func Parse(line string) (Metric, error) {
+	bad := func(why string) (Metric, error) {
+		return Metric{}, fmt.Errorf("%w: %s: %q", ErrMalformed, why, line)
+	}
+	name, rest, ok := strings.Cut(line, ":")
+	if !ok || name == "" || strings.ContainsAny(name, " |@") {
+		return bad("name")
+	}
+	value, rest, ok := strings.Cut(rest, "|")
+	if !ok {
+		return bad("missing type")
+	}
+	kind, rate, hasRate := strings.Cut(rest, "|")
+
+	m := Metric{Name: name, Rate: 1}
+	switch kind {
+	case "c":
+		m.Kind = Counter
+	case "g":
+		m.Kind = Gauge
+		m.Delta = strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-")
+	default:
+		return bad("type")
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return bad("value")
+	}
+	m.Value = v
+
+	if hasRate {
+		r, err := strconv.ParseFloat(strings.TrimPrefix(rate, "@"), 64)
+		if !strings.HasPrefix(rate, "@") || err != nil || r <= 0 || r > 1 {
+			return bad("sample rate")
+		}
+		m.Rate = r
+	}
+	return m, nil
+}
+
+// ParsePacket parses every line of a packet, returning the metrics that
+// parsed and an error for each line that did not. Blank lines are
+// ignored.
+func ParsePacket(packet []byte) ([]Metric, []error) {
+	var ms []Metric
+	var errs []error
+	for line := range strings.SplitSeq(string(packet), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		m, err := Parse(line)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ms = append(ms, m)
+	}
+	return ms, errs
+}
+
+// Apply adds m to reg.
+func Apply(reg *counters.Registry, m Metric) {
+	switch m.Kind {
+	case Counter:
+		reg.GetOrCreate(m.Name).Add(int64(math.Round(m.Value / m.Rate)))
+	case Gauge:
+		g := reg.GetOrCreateGauge(m.Name)
+		if m.Delta {
+			g.Add(m.Value)
+		} else {
+			g.Set(m.Value)
+		}
+	}
+}
+
+// Names of the counters a Listener keeps about itself.
+const (
+	PacketsReceived = "statsd.packets"
+	LinesMalformed  = "statsd.malformed_lines"
+)
+
+// MaxPacketBytes is the largest datagram read; longer ones are truncated
+// by the kernel and their cut-off last line fails to parse.
+const MaxPacketBytes = 8192
+
+// Listen reads packets from conn and applies them to reg until ctx ends
+// or conn fails. It closes conn when ctx ends.
+func Listen(ctx context.Context, conn net.PacketConn, reg *counters.Registry) error {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	packets := reg.GetOrCreate(PacketsReceived)
+	malformed := reg.GetOrCreate(LinesMalformed)
+	buf := make([]byte, MaxPacketBytes)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		packets.Inc()
+		ms, errs := ParsePacket(buf[:n])
+		malformed.Add(int64(len(errs)))
+		for _, m := range ms {
+			Apply(reg, m)
+		}
+	}
+}
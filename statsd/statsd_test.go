@@ -0,0 +1,118 @@
+package statsd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/concurrency/leaktest"
+	"github.com/stawuah/pounce-on-go/counters"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line string
+		want Metric
+	}{
+		{"hits:1|c", Metric{Name: "hits", Kind: Counter, Value: 1, Rate: 1}},
+		{"hits:3|c|@0.1", Metric{Name: "hits", Kind: Counter, Value: 3, Rate: 0.1}},
+		{"queue.depth:12.5|g", Metric{Name: "queue.depth", Kind: Gauge, Value: 12.5, Rate: 1}},
+		{"queue.depth:-2|g", Metric{Name: "queue.depth", Kind: Gauge, Value: -2, Delta: true, Rate: 1}},
+		{"queue.depth:+2|g", Metric{Name: "queue.depth", Kind: Gauge, Value: 2, Delta: true, Rate: 1}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.line)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %+v, %v; want %+v", tt.line, got, err, tt.want)
+		}
+	}
+
+	for _, line := range []string{
+		"", "hits", ":1|c", "hits:1", "hits:x|c", "hits:1|ms", "hits:1|c|0.5",
+		"hits:1|c|@0", "hits:1|c|@2", "a b:1|c", "hits:NaN|g", "hits:1|",
+	} {
+		if _, err := Parse(line); !errors.Is(err, ErrMalformed) {
+			t.Errorf("Parse(%q) error = %v, want ErrMalformed", line, err)
+		}
+	}
+}
+
+func TestParsePacketSkipsBadLines(t *testing.T) {
+	// The last line was cut off in transit.
+	packet := []byte("a:1|c\n\nbogus\nb:2|g\r\nc:4|")
+	ms, errs := ParsePacket(packet)
+	if len(ms) != 2 || ms[0].Name != "a" || ms[1].Name != "b" {
+		t.Fatalf("metrics = %+v", ms)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("errors = %v, want 2", errs)
+	}
+}
+
+func TestApply(t *testing.T) {
+	reg := counters.NewRegistry()
+	for _, line := range []string{"hits:1|c", "hits:2|c|@0.5", "temp:20|g", "temp:+1.5|g", "temp:-0.5|g"} {
+		m, _ := Parse(line)
+		Apply(reg, m)
+	}
+	if got := reg.Dump()["hits"]; got != 5 {
+		t.Fatalf("hits = %d, want 1 + 2/0.5 = 5", got)
+	}
+	if got := reg.DumpGauges()["temp"]; got != 21 {
+		t.Fatalf("temp = %v, want 21", got)
+	}
+}
+
+func TestListen(t *testing.T) {
+	leaktest.Check(t)
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := counters.NewRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Listen(ctx, conn, reg) }()
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for _, p := range []string{"orders:1|c\norders:1|c", "stock.anvils:7|g", "garbage", "orders:1|c"} {
+		client.Write([]byte(p))
+	}
+
+	// Loopback UDP does not lose packets in practice, but delivery is
+	// still asynchronous.
+	deadline := time.Now().Add(2 * time.Second)
+	for reg.Dump()[PacketsReceived] < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("received %d packets, want 4", reg.Dump()[PacketsReceived])
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	rec := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{"orders 3\n", "stock_anvils 7\n", "statsd_malformed_lines 1\n"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics missing %q:\n%s", want, body)
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Listen = %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Listen did not return after cancel")
+	}
+}
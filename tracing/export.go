@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// An Exporter receives every span a Tracer records, as it finishes, for
+// sending elsewhere. ExportSpan is called on the finishing goroutine and
+// must be safe for concurrent use; it should not block for long.
+type Exporter interface {
+	ExportSpan(Span)
+}
+
+// WithExporter sends each finished span to e as well as keeping it. A
+// Tracer made to keep no traces, NewTracer(0, WithExporter(e)), only
+// exports.
+func WithExporter(e Exporter) Option {
+	return func(t *Tracer) { t.exporters = append(t.exporters, e) }
+}
+
+// JSONExporter writes spans to a writer, one JSON object per line, in the
+// shape OpenTelemetry's OTLP/JSON encoding gives a span: hex IDs,
+// nanosecond Unix times, typed attribute values and a status. Pointed at
+// stdout, it shows what a collector would receive without running one.
+type JSONExporter struct {
+	// Service names the program, as the service.name resource attribute
+	// OpenTelemetry puts on every span.
+	Service string
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONExporter returns a JSONExporter writing to w for service.
+func NewJSONExporter(w io.Writer, service string) *JSONExporter {
+	return &JSONExporter{Service: service, w: w}
+}
+
+// otelSpan is a span as OTLP/JSON spells it. The 64-bit times are
+// strings, as protobuf's JSON mapping requires.
+type otelSpan struct {
+	TraceID           string      `json:"traceId"`
+	SpanID            string      `json:"spanId"`
+	ParentSpanID      string      `json:"parentSpanId,omitempty"`
+	Name              string      `json:"name"`
+	Kind              int         `json:"kind"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+	Attributes        []otelAttr  `json:"attributes,omitempty"`
+	Status            otelStatus  `json:"status"`
+	Resource          otelAttrSet `json:"resource"`
+}
+
+type otelAttr struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otelAttrSet struct {
+	Attributes []otelAttr `json:"attributes"`
+}
+
+type otelStatus struct {
+	Code    int    `json:"code"` // 1 OK, 2 error
+	Message string `json:"message,omitempty"`
+}
+
+// Span kinds, from OpenTelemetry's SpanKind.
+const (
+	kindInternal = 1
+	kindServer   = 2
+)
+
+// ExportSpan writes s as one line. Write errors are dropped: losing a
+// span must not fail the work it timed.
+func (e *JSONExporter) ExportSpan(s Span) {
+	o := otelSpan{
+		TraceID:           s.TraceID.String(),
+		SpanID:            s.ID.String(),
+		Name:              s.Name,
+		Kind:              kindInternal,
+		StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+		Status:            otelStatus{Code: 1},
+		Resource:          otelAttrSet{Attributes: []otelAttr{attr("service.name", e.Service)}},
+	}
+	if !s.Parent.IsZero() {
+		o.ParentSpanID = s.Parent.String()
+	}
+	if s.server {
+		o.Kind = kindServer
+	}
+	for _, k := range slices.Sorted(maps.Keys(s.Attrs)) {
+		o.Attributes = append(o.Attributes, attr(k, s.Attrs[k]))
+	}
+	if s.Err != "" {
+		o.Status = otelStatus{Code: 2, Message: s.Err}
+	}
+	b, err := json.Marshal(o)
+	if err != nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.w.Write(append(b, '\n'))
+}
+
+func attr(k, v string) otelAttr {
+	a := otelAttr{Key: k}
+	a.Value.StringValue = v
+	return a
+}
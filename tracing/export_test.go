@@ -0,0 +1,141 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+	"github.com/stawuah/pounce-on-go/concurrency/leaktest"
+	"github.com/stawuah/pounce-on-go/concurrency/ownership"
+	"github.com/stawuah/pounce-on-go/concurrency/workerpool"
+)
+
+// spanLog collects exported spans.
+type spanLog struct {
+	mu    sync.Mutex
+	spans []Span
+}
+
+func (l *spanLog) ExportSpan(s Span) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.spans = append(l.spans, s)
+}
+
+func (l *spanLog) names() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var names []string
+	for _, s := range l.spans {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestJSONExporter(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1700000000, 0))
+	var out bytes.Buffer
+	tr := NewTracer(0, WithClock(clk), WithExporter(NewJSONExporter(&out, "pounce")))
+	h := tr.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, s := Start(r.Context(), "repo.Get")
+		s.SetAttr("sku", "A1")
+		clk.Advance(time.Millisecond)
+		s.Finish(errors.New("not found"))
+	}))
+	req := httptest.NewRequest("GET", "/products/A1", nil)
+	req.Header.Set(TraceparentHeader, parent)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(tr.order) != 0 {
+		t.Fatal("a tracer keeping no traces kept one")
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("exported:\n%s", out.String())
+	}
+	var child, root map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &child); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &root); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"attributes":[{"key":"sku","value":{"stringValue":"A1"}}],` +
+		`"endTimeUnixNano":"1700000000001000000","kind":1,"name":"repo.Get",` +
+		`"parentSpanId":"` + root["spanId"].(string) + `",` +
+		`"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"pounce"}}]},` +
+		`"spanId":"` + child["spanId"].(string) + `",` +
+		`"startTimeUnixNano":"1700000000000000000","status":{"code":2,"message":"not found"},` +
+		`"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"}`
+	if got, _ := json.Marshal(child); string(got) != want {
+		t.Fatalf("child span\n got %s\nwant %s", got, want)
+	}
+	if root["kind"] != float64(kindServer) || root["parentSpanId"] != "00f067aa0ba902b7" || root["status"].(map[string]any)["code"] != float64(1) {
+		t.Fatalf("root span = %v", root)
+	}
+}
+
+func TestStoreHook(t *testing.T) {
+	var log spanLog
+	s := Store(NewTracer(10, WithExporter(&log)), ownership.NewMutexStore())
+	s.Set("sku:1", "Anvil")
+	if v, ok := s.Get("sku:1"); !ok || v != "Anvil" {
+		t.Fatalf("Get = %q, %v", v, ok)
+	}
+	s.Delete("sku:1")
+	if s.Len() != 0 || len(s.Keys()) != 0 {
+		t.Fatal("Delete did not reach the store")
+	}
+	if got := strings.Join(log.names(), " "); got != "store.Set store.Get store.Delete store.Len store.Keys" {
+		t.Fatalf("spans = %s", got)
+	}
+	if get := log.spans[1]; get.Attrs["key"] != "sku:1" || get.Attrs["found"] != "true" || get.TraceID == log.spans[0].TraceID {
+		t.Fatalf("store.Get span = %+v", get)
+	}
+}
+
+func TestWorkHook(t *testing.T) {
+	leaktest.Check(t)
+
+	var log spanLog
+	tr := NewTracer(10, WithExporter(&log))
+	p := workerpool.New(2, 4, Work(tr, "resize", func(ctx context.Context, n int) (int, error) {
+		if FromContext(ctx) == nil {
+			t.Error("job ran without a span")
+		}
+		switch n {
+		case 1:
+			return 0, errors.New("bad image")
+		case 2:
+			panic("boom")
+		}
+		return n, nil
+	}))
+	for n := range 3 {
+		if err := p.Submit(context.Background(), n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Stop()
+	for range p.Results() {
+	}
+
+	errs := map[string]int{}
+	for _, s := range log.spans {
+		if s.Name != "resize" || !s.Parent.IsZero() {
+			t.Fatalf("span = %+v", s)
+		}
+		errs[s.Err]++
+	}
+	if len(log.spans) != 3 || errs[""] != 1 || errs["bad image"] != 1 || errs["job panicked"] != 1 {
+		t.Fatalf("span errors = %v", errs)
+	}
+}
@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/stawuah/pounce-on-go/concurrency/ownership"
+)
+
+// Store wraps s so that each operation is a trace of its own, named
+// store.Get, store.Set and so on, with the key as an attribute. The
+// ownership.Store methods take no context, so there is no caller's trace
+// to join.
+func Store(t *Tracer, s ownership.Store) ownership.Store {
+	return tracedStore{t: t, s: s}
+}
+
+type tracedStore struct {
+	t *Tracer
+	s ownership.Store
+}
+
+func (ts tracedStore) span(name, key string) *Span {
+	_, span := ts.t.StartTrace(context.Background(), name, "")
+	if key != "" {
+		span.SetAttr("key", key)
+	}
+	return span
+}
+
+func (ts tracedStore) Get(key string) (string, bool) {
+	span := ts.span("store.Get", key)
+	v, ok := ts.s.Get(key)
+	span.SetAttr("found", strconv.FormatBool(ok))
+	span.Finish(nil)
+	return v, ok
+}
+
+func (ts tracedStore) Set(key, value string) {
+	span := ts.span("store.Set", key)
+	ts.s.Set(key, value)
+	span.Finish(nil)
+}
+
+func (ts tracedStore) Delete(key string) {
+	span := ts.span("store.Delete", key)
+	ts.s.Delete(key)
+	span.Finish(nil)
+}
+
+func (ts tracedStore) Len() int {
+	span := ts.span("store.Len", "")
+	defer span.Finish(nil)
+	return ts.s.Len()
+}
+
+func (ts tracedStore) Keys() []string {
+	span := ts.span("store.Keys", "")
+	defer span.Finish(nil)
+	return ts.s.Keys()
+}
+
+// Work wraps a workerpool job function so that each job is a span named
+// name, recording the job's error. A job runs under the pool's context,
+// not its submitter's, so each one starts a trace unless that context
+// already carries a span.
+func Work[J, R any](t *Tracer, name string, work func(context.Context, J) (R, error)) func(context.Context, J) (R, error) {
+	return func(ctx context.Context, job J) (v R, err error) {
+		var span *Span
+		if FromContext(ctx) != nil {
+			ctx, span = Start(ctx, name)
+		} else {
+			ctx, span = t.StartTrace(ctx, name, "")
+		}
+		// A panic is recovered by the pool, above this function; the span
+		// still has to end, as a failure.
+		returned := false
+		defer func() {
+			if returned {
+				span.Finish(err)
+			} else {
+				span.Finish(errPanicked)
+			}
+		}()
+		v, err = work(ctx, job)
+		returned = true
+		return v, err
+	}
+}
+
+var errPanicked = errors.New("job panicked")
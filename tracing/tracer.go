@@ -0,0 +1,241 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+	"github.com/stawuah/pounce-on-go/logging"
+)
+
+// Headers a Tracer reads and writes.
+const (
+	// TraceparentHeader carries a caller's span into the trace, and the
+	// current span out to webhooks.
+	TraceparentHeader = "traceparent"
+	// TraceIDHeader tells a client which trace its request was, so it can
+	// be looked up.
+	TraceIDHeader = "X-Trace-Id"
+)
+
+// MaxSpans caps how many spans a Tracer keeps for one trace; later ones
+// are counted but dropped.
+const MaxSpans = 256
+
+// Option configures a Tracer.
+type Option func(*Tracer)
+
+// WithClock sets the clock spans are timed by. It defaults to the system
+// clock.
+func WithClock(c clock.Clock) Option {
+	return func(t *Tracer) { t.clock = c }
+}
+
+// Tracer starts traces for incoming requests and keeps the spans of the
+// most recent ones.
+type Tracer struct {
+	clock     clock.Clock
+	max       int
+	exporters []Exporter
+
+	mu     sync.Mutex
+	traces map[TraceID]*Trace
+	order  []TraceID // oldest first
+}
+
+// Trace is the recorded spans of one trace, in the order they finished.
+type Trace struct {
+	ID      TraceID `json:"trace_id"`
+	Spans   []Span  `json:"spans"`
+	Dropped int     `json:"dropped,omitempty"`
+}
+
+// NewTracer returns a Tracer keeping the last max traces, or none if max
+// is 0.
+func NewTracer(max int, opts ...Option) *Tracer {
+	t := &Tracer{clock: clock.Real{}, max: max, traces: make(map[TraceID]*Trace)}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// StartTrace begins a root span for a new trace, or, if traceparent is a
+// valid header, for the trace it names, under the caller's span.
+func (t *Tracer) StartTrace(ctx context.Context, name, traceparent string) (context.Context, *Span) {
+	s := &Span{Name: name, tracer: t}
+	if id, parent, err := ParseTraceparent(traceparent); err == nil {
+		s.TraceID, s.Parent = id, parent
+	} else {
+		s.TraceID = newTraceID()
+	}
+	s.ID = newSpanID()
+	s.Start = t.clock.Now()
+	return context.WithValue(ctx, ctxKey{}, s), s
+}
+
+func (t *Tracer) record(s Span) {
+	s.tracer = nil
+	for _, e := range t.exporters {
+		e.ExportSpan(s)
+	}
+	if t.max <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tr, ok := t.traces[s.TraceID]
+	if !ok {
+		if len(t.order) >= t.max {
+			delete(t.traces, t.order[0])
+			t.order = t.order[1:]
+		}
+		tr = &Trace{ID: s.TraceID}
+		t.traces[s.TraceID] = tr
+		t.order = append(t.order, s.TraceID)
+	}
+	if len(tr.Spans) >= MaxSpans {
+		tr.Dropped++
+		return
+	}
+	tr.Spans = append(tr.Spans, s)
+}
+
+// Trace returns a copy of the trace with the given ID, if it is still
+// kept.
+func (t *Tracer) Trace(id TraceID) (Trace, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tr, ok := t.traces[id]
+	if !ok {
+		return Trace{}, false
+	}
+	return Trace{ID: tr.ID, Spans: slices.Clone(tr.Spans), Dropped: tr.Dropped}, true
+}
+
+// Middleware starts a trace for each request, continuing the caller's if
+// it sent a traceparent header. It names the trace in the response's
+// X-Trace-Id header and adds trace_id to the request's log lines. The
+// request ID logging.Middleware assigns becomes an attribute of the root
+// span. Put Middleware outside logging.Middleware so that the line
+// logging writes at the end of each request has the trace ID too.
+func (t *Tracer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := t.StartTrace(r.Context(), r.Method+" "+r.URL.Path, r.Header.Get(TraceparentHeader))
+		span.server = true
+		w.Header().Set(TraceIDHeader, span.TraceID.String())
+		ctx = logging.With(ctx, slog.String("trace_id", span.TraceID.String()))
+		next.ServeHTTP(w, r.WithContext(ctx))
+		if id := w.Header().Get(logging.RequestIDHeader); id != "" {
+			span.SetAttr("request_id", id)
+		}
+		span.Finish(nil)
+	})
+}
+
+// summary is one line of the trace list.
+type summary struct {
+	ID       TraceID       `json:"trace_id"`
+	Root     string        `json:"root"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration_ns"`
+	Spans    int           `json:"spans"`
+	Errors   int           `json:"errors,omitempty"`
+}
+
+// viewSpan is a span as the viewer shows it: in tree order, with its
+// depth below the root.
+type viewSpan struct {
+	Span
+	Depth    int           `json:"depth"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Handler serves the kept traces as JSON:
+//
+//	GET /debug/traces       the traces, newest first, one summary each
+//	GET /debug/traces/{id}  one trace's spans, depth first
+//
+// Mount it at /debug/traces/ behind whatever guards debug endpoints.
+func (t *Tracer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /debug/traces", func(w http.ResponseWriter, r *http.Request) {
+		t.mu.Lock()
+		list := make([]summary, 0, len(t.order))
+		for _, id := range slices.Backward(t.order) {
+			list = append(list, summarize(t.traces[id]))
+		}
+		t.mu.Unlock()
+		writeJSON(w, http.StatusOK, list)
+	})
+	mux.HandleFunc("GET /debug/traces/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := ParseTraceID(r.PathValue("id"))
+		tr, ok := t.Trace(id)
+		if err != nil || !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "no such trace: " + r.PathValue("id")})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"trace_id": tr.ID, "dropped": tr.Dropped, "spans": tree(tr.Spans)})
+	})
+	return mux
+}
+
+func summarize(tr *Trace) summary {
+	s := summary{ID: tr.ID, Spans: len(tr.Spans)}
+	var end time.Time
+	for i, sp := range tr.Spans {
+		if i == 0 || sp.Start.Before(s.Start) {
+			s.Start, s.Root = sp.Start, sp.Name
+		}
+		if sp.End.After(end) {
+			end = sp.End
+		}
+		if sp.Err != "" {
+			s.Errors++
+		}
+	}
+	s.Duration = end.Sub(s.Start)
+	return s
+}
+
+// tree orders spans depth first, children by start time. Spans whose
+// parent was not recorded, such as the caller's span of a continued
+// trace, are roots.
+func tree(spans []Span) []viewSpan {
+	ids := make(map[SpanID]bool, len(spans))
+	children := make(map[SpanID][]Span)
+	for _, s := range spans {
+		ids[s.ID] = true
+	}
+	var roots []Span
+	for _, s := range spans {
+		if ids[s.Parent] {
+			children[s.Parent] = append(children[s.Parent], s)
+		} else {
+			roots = append(roots, s)
+		}
+	}
+	byStart := func(a, b Span) int { return a.Start.Compare(b.Start) }
+	var out []viewSpan
+	var walk func(ss []Span, depth int)
+	walk = func(ss []Span, depth int) {
+		slices.SortStableFunc(ss, byStart)
+		for _, s := range ss {
+			out = append(out, viewSpan{Span: s, Depth: depth, Duration: s.End.Sub(s.Start)})
+			walk(children[s.ID], depth+1)
+		}
+	}
+	walk(roots, 0)
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,215 @@
+// Package tracing follows a request through the subsystems it touches.
+// Each request gets a trace ID; each step that wants to be seen in it,
+// such as a service call, a repository query or publishing an event,
+// starts a span under the span its context carries. A Tracer keeps the
+// most recent traces in memory and serves them for reading.
+//
+// Trace and span IDs use the W3C traceparent format,
+//
+//	00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+//
+// so a trace can continue from a caller that sends the header and on
+// into webhook deliveries, which send it too.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TraceID identifies one request's trace.
+type TraceID [16]byte
+
+// String returns the ID as 32 hex digits.
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// IsZero reports whether t is the invalid all-zero ID.
+func (t TraceID) IsZero() bool { return t == TraceID{} }
+
+// MarshalText encodes t as hex.
+func (t TraceID) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+
+// UnmarshalText decodes what MarshalText wrote.
+func (t *TraceID) UnmarshalText(b []byte) (err error) {
+	*t, err = ParseTraceID(string(b))
+	return err
+}
+
+// SpanID identifies one span within a trace.
+type SpanID [8]byte
+
+// String returns the ID as 16 hex digits.
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// IsZero reports whether s is the all-zero ID, which a root span has as
+// its parent.
+func (s SpanID) IsZero() bool { return s == SpanID{} }
+
+// MarshalText encodes s as hex.
+func (s SpanID) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// UnmarshalText decodes what MarshalText wrote.
+func (s *SpanID) UnmarshalText(b []byte) error {
+	if len(b) != 2*len(s) {
+		return fmt.Errorf("tracing: span ID %q is not 16 hex digits", b)
+	}
+	if _, err := hex.Decode(s[:], b); err != nil {
+		return fmt.Errorf("tracing: span ID %q is not 16 hex digits", b)
+	}
+	return nil
+}
+
+// ParseTraceID reads a trace ID written as 32 hex digits.
+func ParseTraceID(s string) (TraceID, error) {
+	var t TraceID
+	if len(s) != 2*len(t) {
+		return t, fmt.Errorf("tracing: trace ID %q is not 32 hex digits", s)
+	}
+	if _, err := hex.Decode(t[:], []byte(s)); err != nil {
+		return t, fmt.Errorf("tracing: trace ID %q is not 32 hex digits", s)
+	}
+	return t, nil
+}
+
+// ErrTraceparent is returned for a malformed traceparent header.
+var ErrTraceparent = errors.New("tracing: malformed traceparent")
+
+// ParseTraceparent reads a version 00 traceparent header.
+func ParseTraceparent(s string) (TraceID, SpanID, error) {
+	var p SpanID
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return TraceID{}, p, fmt.Errorf("%w: %q", ErrTraceparent, s)
+	}
+	t, err := ParseTraceID(parts[1])
+	if err != nil || t.IsZero() {
+		return t, p, fmt.Errorf("%w: %q", ErrTraceparent, s)
+	}
+	if _, err := hex.Decode(p[:], []byte(parts[2])); err != nil || p.IsZero() {
+		return t, p, fmt.Errorf("%w: %q", ErrTraceparent, s)
+	}
+	return t, p, nil
+}
+
+// Span is one timed step of a trace. A span belongs to the goroutine
+// that started it until Finish.
+type Span struct {
+	TraceID TraceID           `json:"trace_id"`
+	ID      SpanID            `json:"span_id"`
+	Parent  SpanID            `json:"parent_id,omitzero"`
+	Name    string            `json:"name"`
+	Start   time.Time         `json:"start"`
+	End     time.Time         `json:"end"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+	Err     string            `json:"error,omitempty"`
+
+	tracer *Tracer
+	ended  bool
+	server bool // the root span of an incoming request
+}
+
+// Duration is how long the span took, or has taken so far.
+func (s *Span) Duration() time.Duration {
+	if s.End.IsZero() {
+		return s.now().Sub(s.Start)
+	}
+	return s.End.Sub(s.Start)
+}
+
+// SetAttr records a key and value on the span.
+func (s *Span) SetAttr(key, value string) {
+	if s == nil {
+		return
+	}
+	if s.Attrs == nil {
+		s.Attrs = make(map[string]string)
+	}
+	s.Attrs[key] = value
+}
+
+// Finish ends the span, recording err if it is not nil, and hands it to
+// its Tracer. Only the first call counts, so
+//
+//	ctx, span := tracing.Start(ctx, "repo.Get")
+//	defer span.Finish(nil)
+//
+// can sit alongside an earlier span.Finish(err) on the error path.
+func (s *Span) Finish(err error) {
+	if s == nil || s.ended {
+		return
+	}
+	s.ended = true
+	s.End = s.now()
+	if err != nil {
+		s.Err = err.Error()
+	}
+	if s.tracer != nil {
+		s.tracer.record(*s)
+	}
+}
+
+// Traceparent returns the span as a traceparent header value.
+func (s *Span) Traceparent() string {
+	if s == nil {
+		return ""
+	}
+	return "00-" + s.TraceID.String() + "-" + s.ID.String() + "-01"
+}
+
+func (s *Span) now() time.Time {
+	if s.tracer != nil {
+		return s.tracer.clock.Now()
+	}
+	return time.Now()
+}
+
+type ctxKey struct{}
+
+// FromContext returns the span ctx carries, or nil.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(ctxKey{}).(*Span)
+	return s
+}
+
+// TraceIDFrom returns the ID of the trace ctx is part of, if any.
+func TraceIDFrom(ctx context.Context) (TraceID, bool) {
+	if s := FromContext(ctx); s != nil {
+		return s.TraceID, true
+	}
+	return TraceID{}, false
+}
+
+// Start begins a span named name as a child of the span in ctx, and
+// returns a context carrying the new span. With no span in ctx, nothing
+// is being traced: Start returns ctx and a nil span, whose methods do
+// nothing, so code can be instrumented whether or not its caller traces.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent := FromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	s := &Span{TraceID: parent.TraceID, ID: newSpanID(), Parent: parent.ID, Name: name, tracer: parent.tracer}
+	s.Start = s.now()
+	return context.WithValue(ctx, ctxKey{}, s), s
+}
+
+func newTraceID() TraceID {
+	var t TraceID
+	for t.IsZero() {
+		rand.Read(t[:])
+	}
+	return t
+}
+
+func newSpanID() SpanID {
+	var s SpanID
+	for s.IsZero() {
+		rand.Read(s[:])
+	}
+	return s
+}
@@ -0,0 +1,173 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/clock"
+	"github.com/stawuah/pounce-on-go/logging"
+)
+
+const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	id, span, err := ParseTraceparent(parent)
+	if err != nil || id.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || span.String() != "00f067aa0ba902b7" {
+		t.Fatalf("ParseTraceparent = %s, %s, %v", id, span, err)
+	}
+	for _, s := range []string{
+		"",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902zz-01",
+	} {
+		if _, _, err := ParseTraceparent(s); !errors.Is(err, ErrTraceparent) {
+			t.Errorf("ParseTraceparent(%q) err = %v", s, err)
+		}
+	}
+}
+
+func TestSpans(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 3, 7, 9, 30, 0, 0, time.UTC))
+	tr := NewTracer(10, WithClock(clk))
+
+	if ctx, s := Start(context.Background(), "untraced"); s != nil || ctx != context.Background() {
+		t.Fatal("Start without a trace made a span")
+	}
+	var none *Span
+	none.SetAttr("k", "v")
+	none.Finish(errors.New("ignored"))
+
+	ctx, root := tr.StartTrace(context.Background(), "GET /x", "")
+	ctx2, child := Start(ctx, "service.Get")
+	if child.TraceID != root.TraceID || child.Parent != root.ID || FromContext(ctx2) != child {
+		t.Fatalf("child = %+v, root = %+v", child, root)
+	}
+	child.SetAttr("sku", "A1")
+	clk.Advance(3 * time.Millisecond)
+	child.Finish(errors.New("not found"))
+	child.Finish(nil) // the deferred call does not undo the error
+	clk.Advance(time.Millisecond)
+	root.Finish(nil)
+
+	got, ok := tr.Trace(root.TraceID)
+	if !ok || len(got.Spans) != 2 {
+		t.Fatalf("Trace = %+v, %v", got, ok)
+	}
+	if s := got.Spans[0]; s.Name != "service.Get" || s.Err != "not found" || s.Attrs["sku"] != "A1" || s.Duration() != 3*time.Millisecond {
+		t.Fatalf("child span = %+v", s)
+	}
+	if id, ok := TraceIDFrom(ctx2); !ok || id != root.TraceID {
+		t.Fatal("TraceIDFrom lost the trace")
+	}
+}
+
+func TestContinueTrace(t *testing.T) {
+	tr := NewTracer(10)
+	_, root := tr.StartTrace(context.Background(), "GET /x", parent)
+	if root.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || root.Parent.String() != "00f067aa0ba902b7" {
+		t.Fatalf("continued root = %+v", root)
+	}
+	_, fresh := tr.StartTrace(context.Background(), "GET /x", "garbage")
+	if fresh.TraceID.IsZero() || fresh.TraceID == root.TraceID || !fresh.Parent.IsZero() {
+		t.Fatalf("fresh root = %+v", fresh)
+	}
+}
+
+func TestTracerKeepsRecent(t *testing.T) {
+	tr := NewTracer(2)
+	var ids []TraceID
+	for range 3 {
+		ctx, root := tr.StartTrace(context.Background(), "req", "")
+		for range MaxSpans + 5 {
+			_, s := Start(ctx, "step")
+			s.Finish(nil)
+		}
+		root.Finish(nil)
+		ids = append(ids, root.TraceID)
+	}
+	if _, ok := tr.Trace(ids[0]); ok {
+		t.Fatal("oldest trace kept past the limit")
+	}
+	got, ok := tr.Trace(ids[2])
+	if !ok || len(got.Spans) != MaxSpans || got.Dropped != 6 {
+		t.Fatalf("newest trace: %d spans, %d dropped, %v", len(got.Spans), got.Dropped, ok)
+	}
+}
+
+func TestMiddlewareAndViewer(t *testing.T) {
+	tr := NewTracer(10)
+	var logs bytes.Buffer
+	log, _ := logging.New(&logs, logging.Config{})
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, s := Start(r.Context(), "service.Get")
+		_, inner := Start(ctx, "repo.Get")
+		inner.Finish(nil)
+		s.Finish(nil)
+		logging.FromContext(r.Context()).Info("handled")
+	})
+	h := tr.Middleware(logging.Middleware(log, app))
+
+	req := httptest.NewRequest("GET", "/products/A1", nil)
+	req.Header.Set(TraceparentHeader, parent)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	id := rec.Header().Get(TraceIDHeader)
+	if id != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("%s = %q, want the caller's trace", TraceIDHeader, id)
+	}
+	if lines := strings.Split(strings.TrimSpace(logs.String()), "\n"); len(lines) != 2 || strings.Count(logs.String(), "trace_id="+id) != 2 {
+		t.Fatalf("log lines lack the trace ID: %s", logs.String())
+	}
+
+	viewer := tr.Handler()
+	rec = httptest.NewRecorder()
+	viewer.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/traces", nil))
+	var list []summary
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Root != "GET /products/A1" || list[0].Spans != 3 {
+		t.Fatalf("list = %+v", list)
+	}
+
+	rec = httptest.NewRecorder()
+	viewer.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/traces/"+id, nil))
+	var view struct {
+		Spans []struct {
+			Name  string            `json:"name"`
+			Depth int               `json:"depth"`
+			Attrs map[string]string `json:"attrs"`
+		} `json:"spans"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &view); err != nil {
+		t.Fatal(err)
+	}
+	var tree []string
+	for _, s := range view.Spans {
+		tree = append(tree, strings.Repeat("  ", s.Depth)+s.Name)
+	}
+	if got := strings.Join(tree, "\n"); got != "GET /products/A1\n  service.Get\n    repo.Get" {
+		t.Fatalf("tree:\n%s", got)
+	}
+	if view.Spans[0].Attrs["request_id"] == "" {
+		t.Fatal("root span lacks the request ID")
+	}
+
+	for _, path := range []string{"/debug/traces/00000000000000000000000000000001", "/debug/traces/nope"} {
+		rec = httptest.NewRecorder()
+		viewer.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != 404 {
+			t.Errorf("GET %s = %d, want 404", path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package tree
+
+import (
+	"cmp"
+	"iter"
+)
+
+// AVL is a self-balancing binary search tree. The zero value is not
+// usable; create one with NewAVL or NewAVLFunc. An AVL is not safe for
+// concurrent use.
+type AVL[K, V any] struct {
+	root    *node[K, V]
+	compare func(K, K) int
+	len     int
+}
+
+// NewAVL returns an empty AVL ordered by cmp.Compare.
+func NewAVL[K cmp.Ordered, V any]() *AVL[K, V] {
+	return NewAVLFunc[K, V](cmp.Compare[K])
+}
+
+// NewAVLFunc returns an empty AVL ordered by compare.
+func NewAVLFunc[K, V any](compare func(a, b K) int) *AVL[K, V] {
+	return &AVL[K, V]{compare: compare}
+}
+
+// Len returns the number of entries.
+func (t *AVL[K, V]) Len() int { return t.len }
+
+// Height returns the number of nodes on the longest root-to-leaf path.
+func (t *AVL[K, V]) Height() int { return h(t.root) }
+
+// Search returns the value stored under key.
+func (t *AVL[K, V]) Search(key K) (V, bool) {
+	if n := search(t.root, key, t.compare); n != nil {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Insert stores value under key and reports whether key was new.
+func (t *AVL[K, V]) Insert(key K, value V) bool {
+	var added bool
+	t.root = t.insert(t.root, key, value, &added)
+	if added {
+		t.len++
+	}
+	return added
+}
+
+func (t *AVL[K, V]) insert(n *node[K, V], key K, value V, added *bool) *node[K, V] {
+	if n == nil {
+		*added = true
+		return &node[K, V]{key: key, value: value, height: 1}
+	}
+	switch c := t.compare(key, n.key); {
+	case c < 0:
+		n.left = t.insert(n.left, key, value, added)
+	case c > 0:
+		n.right = t.insert(n.right, key, value, added)
+	default:
+		n.value = value
+		return n
+	}
+	return rebalance(n)
+}
+
+// Delete removes key and reports whether it was present.
+func (t *AVL[K, V]) Delete(key K) bool {
+	var removed bool
+	t.root = t.delete(t.root, key, &removed)
+	if removed {
+		t.len--
+	}
+	return removed
+}
+
+func (t *AVL[K, V]) delete(n *node[K, V], key K, removed *bool) *node[K, V] {
+	if n == nil {
+		return nil
+	}
+	switch c := t.compare(key, n.key); {
+	case c < 0:
+		n.left = t.delete(n.left, key, removed)
+	case c > 0:
+		n.right = t.delete(n.right, key, removed)
+	default:
+		*removed = true
+		if n.left == nil {
+			return n.right
+		}
+		if n.right == nil {
+			return n.left
+		}
+		succ := minNode(n.right)
+		succ.right = deleteMin(n.right)
+		succ.left = n.left
+		n = succ
+	}
+	return rebalance(n)
+}
+
+// deleteMin removes the leftmost node of n and returns the new subtree.
+func deleteMin[K, V any](n *node[K, V]) *node[K, V] {
+	if n.left == nil {
+		return n.right
+	}
+	n.left = deleteMin(n.left)
+	return rebalance(n)
+}
+
+// All yields every entry in ascending key order.
+func (t *AVL[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) { ascend(t.root, nil, t.compare, yield) }
+}
+
+// Ascend yields the entries with key >= from in ascending order.
+func (t *AVL[K, V]) Ascend(from K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) { ascend(t.root, &from, t.compare, yield) }
+}
+
+// Min returns the smallest key and its value.
+func (t *AVL[K, V]) Min() (K, V, bool) {
+	if t.root == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	n := minNode(t.root)
+	return n.key, n.value, true
+}
+
+func h[K, V any](n *node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func fix[K, V any](n *node[K, V]) {
+	n.height = 1 + max(h(n.left), h(n.right))
+}
+
+// rebalance restores the AVL property at n, whose subtrees are balanced
+// but may differ in height by two, and returns the subtree's new root.
+func rebalance[K, V any](n *node[K, V]) *node[K, V] {
+	fix(n)
+	switch balance := h(n.left) - h(n.right); {
+	case balance > 1:
+		if h(n.left.left) < h(n.left.right) {
+			n.left = rotateLeft(n.left) // left-right case
+		}
+		return rotateRight(n)
+	case balance < -1:
+		if h(n.right.right) < h(n.right.left) {
+			n.right = rotateRight(n.right) // right-left case
+		}
+		return rotateLeft(n)
+	}
+	return n
+}
+
+// rotateRight lifts n's left child into n's place, keeping the order
+// a < l < b < n < c:
+//
+//	    n          l
+//	   / \        / \
+//	  l   c  ->  a   n
+//	 / \            / \
+//	a   b          b   c
+func rotateRight[K, V any](n *node[K, V]) *node[K, V] {
+	l := n.left
+	n.left, l.right = l.right, n
+	fix(n)
+	fix(l)
+	return l
+}
+
+// rotateLeft is the mirror image of rotateRight.
+func rotateLeft[K, V any](n *node[K, V]) *node[K, V] {
+	r := n.right
+	n.right, r.left = r.left, n
+	fix(n)
+	fix(r)
+	return r
+}
@@ -0,0 +1,186 @@
+// Package tree implements ordered maps as binary search trees.
+//
+// BST is the plain algorithm: inserts and deletes follow one path from the
+// root, so operations cost O(height), and inserting keys in sorted order
+// degrades it into a linked list. AVL runs the same search but rebalances
+// with rotations on the way back up, keeping every node's subtrees within
+// one level of each other and the height under 1.44·log₂(n).
+//
+// Both iterate in key order, and both take a comparison function so that
+// composite keys, such as a price with a SKU as tie-breaker, need no
+// wrapper type.
+package tree
+
+import (
+	"cmp"
+	"iter"
+)
+
+type node[K, V any] struct {
+	key         K
+	value       V
+	left, right *node[K, V]
+	height      int // maintained by AVL only
+}
+
+// search returns the node holding key, or nil.
+func search[K, V any](n *node[K, V], key K, compare func(K, K) int) *node[K, V] {
+	for n != nil {
+		switch c := compare(key, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+// ascend yields, in order, the entries of n whose key is >= from (all of
+// them if from is nil). It reports false once yield has asked to stop.
+func ascend[K, V any](n *node[K, V], from *K, compare func(K, K) int, yield func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	inRange := from == nil || compare(n.key, *from) >= 0
+	// Everything on the left is smaller than n, so it can only be in
+	// range if n is.
+	if inRange && !ascend(n.left, from, compare, yield) {
+		return false
+	}
+	if inRange && !yield(n.key, n.value) {
+		return false
+	}
+	return ascend(n.right, from, compare, yield)
+}
+
+func minNode[K, V any](n *node[K, V]) *node[K, V] {
+	for n.left != nil {
+		n = n.left
+	}
+	return n
+}
+
+func heightOf[K, V any](n *node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return 1 + max(heightOf(n.left), heightOf(n.right))
+}
+
+// BST is an unbalanced binary search tree. The zero value is not usable;
+// create one with New or NewFunc. A BST is not safe for concurrent use.
+type BST[K, V any] struct {
+	root    *node[K, V]
+	compare func(K, K) int
+	len     int
+}
+
+// New returns an empty BST ordered by cmp.Compare.
+func New[K cmp.Ordered, V any]() *BST[K, V] {
+	return NewFunc[K, V](cmp.Compare[K])
+}
+
+// NewFunc returns an empty BST ordered by compare, which returns a
+// negative, zero or positive number as a is less than, equal to or
+// greater than b.
+func NewFunc[K, V any](compare func(a, b K) int) *BST[K, V] {
+	return &BST[K, V]{compare: compare}
+}
+
+// Len returns the number of entries.
+func (t *BST[K, V]) Len() int { return t.len }
+
+// Height returns the number of nodes on the longest root-to-leaf path.
+func (t *BST[K, V]) Height() int { return heightOf(t.root) }
+
+// Search returns the value stored under key.
+func (t *BST[K, V]) Search(key K) (V, bool) {
+	if n := search(t.root, key, t.compare); n != nil {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Insert stores value under key and reports whether key was new; an
+// existing key has its value replaced.
+func (t *BST[K, V]) Insert(key K, value V) bool {
+	link := &t.root
+	for *link != nil {
+		switch c := t.compare(key, (*link).key); {
+		case c < 0:
+			link = &(*link).left
+		case c > 0:
+			link = &(*link).right
+		default:
+			(*link).value = value
+			return false
+		}
+	}
+	*link = &node[K, V]{key: key, value: value}
+	t.len++
+	return true
+}
+
+// Delete removes key and reports whether it was present.
+func (t *BST[K, V]) Delete(key K) bool {
+	link := &t.root
+	for *link != nil {
+		n := *link
+		switch c := t.compare(key, n.key); {
+		case c < 0:
+			link = &n.left
+			continue
+		case c > 0:
+			link = &n.right
+			continue
+		}
+
+		switch {
+		case n.left == nil:
+			*link = n.right
+		case n.right == nil:
+			*link = n.left
+		default:
+			// Two children: unlink the in-order successor (the
+			// leftmost node on the right, which has no left child)
+			// and put it in n's place.
+			succLink := &n.right
+			for (*succLink).left != nil {
+				succLink = &(*succLink).left
+			}
+			succ := *succLink
+			*succLink = succ.right
+			succ.left, succ.right = n.left, n.right
+			*link = succ
+		}
+		t.len--
+		return true
+	}
+	return false
+}
+
+// All yields every entry in ascending key order.
+func (t *BST[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) { ascend(t.root, nil, t.compare, yield) }
+}
+
+// Ascend yields the entries with key >= from in ascending order. Stop
+// ranging once past the end of the wanted range.
+func (t *BST[K, V]) Ascend(from K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) { ascend(t.root, &from, t.compare, yield) }
+}
+
+// Min returns the smallest key and its value.
+func (t *BST[K, V]) Min() (K, V, bool) {
+	if t.root == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	n := minNode(t.root)
+	return n.key, n.value, true
+}
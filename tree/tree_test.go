@@ -0,0 +1,229 @@
+package tree
+
+import (
+	"cmp"
+	"iter"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// ordered is the API shared by BST and AVL.
+type ordered[K, V any] interface {
+	Len() int
+	Height() int
+	Search(K) (V, bool)
+	Insert(K, V) bool
+	Delete(K) bool
+	All() iter.Seq2[K, V]
+	Ascend(K) iter.Seq2[K, V]
+	Min() (K, V, bool)
+}
+
+var impls = []struct {
+	name string
+	new  func() ordered[int, string]
+}{
+	{"BST", func() ordered[int, string] { return New[int, string]() }},
+	{"AVL", func() ordered[int, string] { return NewAVL[int, string]() }},
+}
+
+func root(t ordered[int, string]) *node[int, string] {
+	switch t := t.(type) {
+	case *BST[int, string]:
+		return t.root
+	case *AVL[int, string]:
+		return t.root
+	}
+	panic("unknown tree")
+}
+
+// checkOrder verifies the search-tree property of every node.
+func checkOrder(t *testing.T, n *node[int, string], lo, hi int) {
+	t.Helper()
+	if n == nil {
+		return
+	}
+	if n.key <= lo || n.key >= hi {
+		t.Fatalf("key %d outside (%d, %d)", n.key, lo, hi)
+	}
+	checkOrder(t, n.left, lo, n.key)
+	checkOrder(t, n.right, n.key, hi)
+}
+
+// checkAVL verifies stored heights and the balance factor of every node
+// and returns the subtree height.
+func checkAVL(t *testing.T, n *node[int, string]) int {
+	t.Helper()
+	if n == nil {
+		return 0
+	}
+	l, r := checkAVL(t, n.left), checkAVL(t, n.right)
+	if n.height != 1+max(l, r) {
+		t.Fatalf("node %d: stored height %d, actual %d", n.key, n.height, 1+max(l, r))
+	}
+	if l-r > 1 || r-l > 1 {
+		t.Fatalf("node %d unbalanced: %d vs %d", n.key, l, r)
+	}
+	return n.height
+}
+
+func TestBasics(t *testing.T) {
+	for _, impl := range impls {
+		t.Run(impl.name, func(t *testing.T) {
+			tr := impl.new()
+			if _, _, ok := tr.Min(); ok {
+				t.Fatal("Min of an empty tree reported ok")
+			}
+			for _, k := range []int{5, 3, 8, 1, 4} {
+				if !tr.Insert(k, "v") {
+					t.Fatalf("Insert(%d) reported existing", k)
+				}
+			}
+			if tr.Insert(3, "three") {
+				t.Fatal("Insert of an existing key reported new")
+			}
+			if v, ok := tr.Search(3); !ok || v != "three" {
+				t.Fatalf("Search(3) = %q, %v", v, ok)
+			}
+			if _, ok := tr.Search(7); ok {
+				t.Fatal("Search(7) found a missing key")
+			}
+			if k, _, _ := tr.Min(); k != 1 {
+				t.Fatalf("Min = %d", k)
+			}
+			if got := slices.Collect(keys(tr.Ascend(4))); !slices.Equal(got, []int{4, 5, 8}) {
+				t.Fatalf("Ascend(4) = %v", got)
+			}
+			if got := slices.Collect(keys(tr.Ascend(6))); !slices.Equal(got, []int{8}) {
+				t.Fatalf("Ascend(6) = %v", got)
+			}
+			if !tr.Delete(5) || tr.Delete(5) || tr.Len() != 4 {
+				t.Fatalf("Delete(5) twice, Len = %d", tr.Len())
+			}
+			if got := slices.Collect(keys(tr.All())); !slices.Equal(got, []int{1, 3, 4, 8}) {
+				t.Fatalf("All = %v", got)
+			}
+		})
+	}
+}
+
+func keys[K, V any](seq iter.Seq2[K, V]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range seq {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+func TestEarlyBreak(t *testing.T) {
+	for _, impl := range impls {
+		tr := impl.new()
+		for i := range 100 {
+			tr.Insert(i, "")
+		}
+		var got []int
+		for k := range tr.Ascend(10) {
+			if k >= 13 {
+				break
+			}
+			got = append(got, k)
+		}
+		if !slices.Equal(got, []int{10, 11, 12}) {
+			t.Fatalf("%s: range = %v", impl.name, got)
+		}
+	}
+}
+
+// TestRandomOperations applies random inserts and deletes to each tree
+// and to a map, checking the invariants after every step.
+func TestRandomOperations(t *testing.T) {
+	for _, impl := range impls {
+		t.Run(impl.name, func(t *testing.T) {
+			r := rand.New(rand.NewPCG(7, 11))
+			tr := impl.new()
+			model := map[int]string{}
+			for i := range 3000 {
+				k := r.IntN(200)
+				if r.IntN(3) == 0 {
+					_, had := model[k]
+					if tr.Delete(k) != had {
+						t.Fatalf("step %d: Delete(%d) disagrees with model", i, k)
+					}
+					delete(model, k)
+				} else {
+					_, had := model[k]
+					if tr.Insert(k, "v") == had {
+						t.Fatalf("step %d: Insert(%d) disagrees with model", i, k)
+					}
+					model[k] = "v"
+				}
+
+				checkOrder(t, root(tr), math.MinInt, math.MaxInt)
+				if a, ok := tr.(*AVL[int, string]); ok {
+					checkAVL(t, a.root)
+				}
+				if tr.Len() != len(model) {
+					t.Fatalf("step %d: Len = %d, want %d", i, tr.Len(), len(model))
+				}
+			}
+			want := slices.Sorted(maps.Keys(model))
+			if got := slices.Collect(keys(tr.All())); !slices.Equal(got, want) {
+				t.Fatalf("All = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSortedInsertHeight(t *testing.T) {
+	const n = 1024
+	bst, avl := New[int, string](), NewAVL[int, string]()
+	for i := range n {
+		bst.Insert(i, "")
+		avl.Insert(i, "")
+	}
+	if bst.Height() != n {
+		t.Fatalf("BST height after sorted inserts = %d, want %d (a list)", bst.Height(), n)
+	}
+	if limit := int(1.44 * math.Log2(n+2)); avl.Height() > limit {
+		t.Fatalf("AVL height = %d, above the %d bound", avl.Height(), limit)
+	}
+}
+
+func TestCompositeKey(t *testing.T) {
+	type key struct {
+		price float64
+		sku   string
+	}
+	tr := NewAVLFunc[key, struct{}](func(a, b key) int {
+		return cmp.Or(cmp.Compare(a.price, b.price), cmp.Compare(a.sku, b.sku))
+	})
+	tr.Insert(key{2, "b"}, struct{}{})
+	tr.Insert(key{1, "z"}, struct{}{})
+	tr.Insert(key{2, "a"}, struct{}{})
+
+	var got []string
+	for k := range tr.All() {
+		got = append(got, k.sku)
+	}
+	if !slices.Equal(got, []string{"z", "a", "b"}) {
+		t.Fatalf("order = %v", got)
+	}
+}
+
+func BenchmarkInsertSorted(b *testing.B) {
+	for _, impl := range impls {
+		b.Run(impl.name, func(b *testing.B) {
+			for b.Loop() {
+				tr := impl.new()
+				for i := range 1000 {
+					tr.Insert(i, "")
+				}
+			}
+		})
+	}
+}
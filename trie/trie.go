@@ -0,0 +1,142 @@
+// Package trie implements a prefix tree keyed by strings.
+//
+// Each node holds one rune, so a key is the path from the root to a node
+// and every key sharing a prefix shares that path. Finding all keys that
+// start with a prefix costs the length of the prefix plus the size of the
+// answer, independent of how many keys the trie holds. Children are
+// walked in rune order, so results come out sorted.
+package trie
+
+import (
+	"iter"
+	"slices"
+)
+
+type node[V any] struct {
+	children map[rune]*node[V]
+	value    V
+	terminal bool // a key ends here
+}
+
+// Trie maps string keys to values of type V. The zero value is an empty
+// trie ready to use. A Trie is not safe for concurrent use.
+type Trie[V any] struct {
+	root node[V]
+	len  int
+}
+
+// Len returns the number of keys.
+func (t *Trie[V]) Len() int { return t.len }
+
+// Insert stores value under key and reports whether key was new.
+func (t *Trie[V]) Insert(key string, value V) bool {
+	n := &t.root
+	for _, r := range key {
+		child, ok := n.children[r]
+		if !ok {
+			if n.children == nil {
+				n.children = make(map[rune]*node[V])
+			}
+			child = new(node[V])
+			n.children[r] = child
+		}
+		n = child
+	}
+	added := !n.terminal
+	n.value, n.terminal = value, true
+	if added {
+		t.len++
+	}
+	return added
+}
+
+// Get returns the value stored under key.
+func (t *Trie[V]) Get(key string) (V, bool) {
+	n := t.find(key)
+	if n == nil || !n.terminal {
+		var zero V
+		return zero, false
+	}
+	return n.value, true
+}
+
+func (t *Trie[V]) find(prefix string) *node[V] {
+	n := &t.root
+	for _, r := range prefix {
+		if n = n.children[r]; n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// Delete removes key and reports whether it was present. Nodes left with
+// no key below them are pruned.
+func (t *Trie[V]) Delete(key string) bool {
+	path := []*node[V]{&t.root}
+	runes := []rune(key)
+	for _, r := range runes {
+		n := path[len(path)-1].children[r]
+		if n == nil {
+			return false
+		}
+		path = append(path, n)
+	}
+	last := path[len(path)-1]
+	if !last.terminal {
+		return false
+	}
+	var zero V
+	last.value, last.terminal = zero, false
+	t.len--
+
+	for i := len(runes) - 1; i >= 0; i-- {
+		n := path[i+1]
+		if n.terminal || len(n.children) > 0 {
+			break
+		}
+		delete(path[i].children, runes[i])
+	}
+	return true
+}
+
+// PrefixSearch yields every key starting with prefix, and its value, in
+// sorted order.
+func (t *Trie[V]) PrefixSearch(prefix string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		if n := t.find(prefix); n != nil {
+			walk(n, []rune(prefix), yield)
+		}
+	}
+}
+
+// walk yields n's subtree depth first; key holds the runes leading to n.
+func walk[V any](n *node[V], key []rune, yield func(string, V) bool) bool {
+	if n.terminal && !yield(string(key), n.value) {
+		return false
+	}
+	runes := make([]rune, 0, len(n.children))
+	for r := range n.children {
+		runes = append(runes, r)
+	}
+	slices.Sort(runes)
+	for _, r := range runes {
+		if !walk(n.children[r], append(key, r), yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// Autocomplete returns up to limit keys starting with prefix, in sorted
+// order. A limit of zero or less means no limit.
+func (t *Trie[V]) Autocomplete(prefix string, limit int) []string {
+	var out []string
+	for k := range t.PrefixSearch(prefix) {
+		out = append(out, k)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out
+}
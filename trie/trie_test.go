@@ -0,0 +1,105 @@
+package trie
+
+import (
+	"slices"
+	"testing"
+)
+
+func build(keys ...string) *Trie[int] {
+	var t Trie[int]
+	for i, k := range keys {
+		t.Insert(k, i)
+	}
+	return &t
+}
+
+func TestInsertGet(t *testing.T) {
+	tr := build("car", "cart", "cat", "dog", "")
+	if tr.Len() != 5 {
+		t.Fatalf("Len = %d", tr.Len())
+	}
+	if tr.Insert("car", 9) {
+		t.Fatal("re-inserting car reported new")
+	}
+	tests := []struct {
+		key  string
+		want int
+		ok   bool
+	}{
+		{"car", 9, true},
+		{"cart", 1, true},
+		{"ca", 0, false}, // a prefix, not a key
+		{"", 4, true},
+		{"cars", 0, false},
+	}
+	for _, tt := range tests {
+		if v, ok := tr.Get(tt.key); v != tt.want || ok != tt.ok {
+			t.Errorf("Get(%q) = %d, %v; want %d, %v", tt.key, v, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPrefixSearch(t *testing.T) {
+	tr := build("apple", "apricot", "app", "banana", "ápice", "apps")
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"ap", []string{"app", "apple", "apps", "apricot"}},
+		{"app", []string{"app", "apple", "apps"}},
+		{"á", []string{"ápice"}},
+		{"c", nil},
+		{"", []string{"app", "apple", "apps", "apricot", "banana", "ápice"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for k := range tr.PrefixSearch(tt.prefix) {
+			got = append(got, k)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("PrefixSearch(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+	if got := tr.Autocomplete("ap", 2); !slices.Equal(got, []string{"app", "apple"}) {
+		t.Errorf("Autocomplete(ap, 2) = %q", got)
+	}
+}
+
+func TestDeletePrunes(t *testing.T) {
+	tr := build("car", "cart", "dog")
+	if tr.Delete("ca") || tr.Delete("cars") {
+		t.Fatal("Delete of a non-key reported true")
+	}
+	if !tr.Delete("cart") {
+		t.Fatal("Delete(cart) = false")
+	}
+	if n := tr.find("car"); n == nil || len(n.children) != 0 {
+		t.Fatal("node for t not pruned under car")
+	}
+	if !tr.Delete("car") {
+		t.Fatal("Delete(car) = false")
+	}
+	if _, ok := tr.root.children['c']; ok {
+		t.Fatal("empty c branch not pruned")
+	}
+	if got := tr.Autocomplete("", 0); !slices.Equal(got, []string{"dog"}) || tr.Len() != 1 {
+		t.Fatalf("remaining keys = %q, Len %d", got, tr.Len())
+	}
+
+	// Deleting a key that is a prefix of another keeps the longer one.
+	tr = build("car", "cart")
+	tr.Delete("car")
+	if _, ok := tr.Get("cart"); !ok {
+		t.Fatal("deleting car removed cart")
+	}
+}
+
+func BenchmarkAutocomplete(b *testing.B) {
+	var tr Trie[struct{}]
+	for i := range 10_000 {
+		tr.Insert(string(rune('a'+i%26))+string(rune('a'+i/26%26))+string(rune('a'+i/676%26)), struct{}{})
+	}
+	for b.Loop() {
+		tr.Autocomplete("ab", 10)
+	}
+}
@@ -0,0 +1,43 @@
+package weakcache
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// Handle owns an external resource that must be released with Close.
+// If a Handle is dropped without Close, a cleanup releases the resource
+// when the Handle is collected and reports the leak, so the bug is
+// visible rather than silent.
+type Handle struct {
+	name    string
+	release func(name string)
+	closed  *atomic.Bool // shared with the cleanup, which cannot see h
+	cleanup runtime.Cleanup
+}
+
+// Open returns a Handle for name. release is called exactly once: by
+// Close, or by the cleanup with leaked set to true.
+func Open(name string, release func(name string, leaked bool)) *Handle {
+	closed := new(atomic.Bool)
+	h := &Handle{name: name, closed: closed}
+	h.release = func(n string) { release(n, false) }
+	h.cleanup = runtime.AddCleanup(h, func(n string) {
+		if closed.CompareAndSwap(false, true) {
+			release(n, true)
+		}
+	}, name)
+	return h
+}
+
+// Name returns the resource name.
+func (h *Handle) Name() string { return h.name }
+
+// Close releases the resource. Calling it more than once is safe.
+func (h *Handle) Close() error {
+	if h.closed.CompareAndSwap(false, true) {
+		h.cleanup.Stop()
+		h.release(h.name)
+	}
+	return nil
+}
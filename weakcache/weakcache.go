@@ -0,0 +1,100 @@
+// Package weakcache is a cache that does not keep its values alive.
+//
+// It holds each value through a weak.Pointer, so once no one else refers
+// to a value the garbage collector may reclaim it, and a cleanup
+// registered with runtime.AddCleanup then removes the dead entry. Memory
+// pressure, through the collector, decides what stays cached.
+//
+// runtime.AddCleanup replaces runtime.SetFinalizer for this job. A
+// finalizer receives the object itself and so can resurrect it, delays
+// its collection by a cycle, and at most one can be set per object; a
+// cleanup gets a separate argument that must not reference the object,
+// which rules all of that out.
+package weakcache
+
+import (
+	"runtime"
+	"sync"
+	"weak"
+)
+
+// Cache maps keys to weakly held *V values. It is safe for concurrent
+// use.
+type Cache[K comparable, V any] struct {
+	mu      sync.Mutex
+	entries map[K]weak.Pointer[V]
+	onEvict func(K)
+}
+
+// New returns an empty Cache. If onEvict is non-nil it is called, on the
+// runtime's cleanup goroutine, with the key of each entry removed because
+// its value was collected.
+func New[K comparable, V any](onEvict func(K)) *Cache[K, V] {
+	return &Cache[K, V]{entries: make(map[K]weak.Pointer[V]), onEvict: onEvict}
+}
+
+type entry[K comparable, V any] struct {
+	key K
+	ptr weak.Pointer[V]
+}
+
+// Put caches v under key, replacing any previous value.
+func (c *Cache[K, V]) Put(key K, v *V) {
+	wp := weak.Make(v)
+	c.mu.Lock()
+	c.entries[key] = wp
+	c.mu.Unlock()
+	// The argument holds only the weak pointer, never v, or v could
+	// never become unreachable.
+	runtime.AddCleanup(v, c.evict, entry[K, V]{key, wp})
+}
+
+// evict removes e if the key still maps to the collected value; the key
+// may have been overwritten since.
+func (c *Cache[K, V]) evict(e entry[K, V]) {
+	c.mu.Lock()
+	current, ok := c.entries[e.key]
+	if ok && current == e.ptr {
+		delete(c.entries, e.key)
+	}
+	c.mu.Unlock()
+	if ok && current == e.ptr && c.onEvict != nil {
+		c.onEvict(e.key)
+	}
+}
+
+// Get returns the value cached under key, or false if there is none or it
+// has been collected. A non-nil result is a strong reference again and
+// keeps the value alive for as long as the caller holds it.
+func (c *Cache[K, V]) Get(key K) (*V, bool) {
+	c.mu.Lock()
+	wp, ok := c.entries[key]
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	v := wp.Value()
+	return v, v != nil
+}
+
+// GetOrLoad returns the cached value for key, calling load and caching its
+// result if there is none.
+func (c *Cache[K, V]) GetOrLoad(key K, load func() (*V, error)) (*V, error) {
+	if v, ok := c.Get(key); ok {
+		return v, nil
+	}
+	v, err := load()
+	if err != nil {
+		return nil, err
+	}
+	c.Put(key, v)
+	return v, nil
+}
+
+// Len returns the number of entries, including any whose value has been
+// collected but whose cleanup has not run yet.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
@@ -0,0 +1,162 @@
+package weakcache
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stawuah/pounce-on-go/product"
+)
+
+// eventually runs the collector until cond holds. Cleanups run on their
+// own goroutine after a cycle, so one runtime.GC is not always enough.
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not reached after repeated GC")
+		}
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// product.Product contains strings, so it is never placed by the tiny
+// allocator, which could keep an unreachable value alive alongside a
+// live neighbour.
+func newProduct(sku string) *product.Product {
+	return &product.Product{SKU: sku, Name: "Widget " + sku, Price: 9.99}
+}
+
+func TestEntryLivesWhileReferenced(t *testing.T) {
+	c := New[string, product.Product](nil)
+	p := newProduct("A")
+	c.Put("A", p)
+
+	runtime.GC()
+	runtime.GC()
+	got, ok := c.Get("A")
+	if !ok || got != p {
+		t.Fatalf("Get = %v, %v while the value is still referenced", got, ok)
+	}
+	runtime.KeepAlive(p)
+}
+
+func TestEntryDroppedAfterCollection(t *testing.T) {
+	var mu sync.Mutex
+	var evicted []string
+	c := New[string, product.Product](func(k string) {
+		mu.Lock()
+		evicted = append(evicted, k)
+		mu.Unlock()
+	})
+	c.Put("A", newProduct("A"))
+	keep := newProduct("B")
+	c.Put("B", keep)
+
+	eventually(t, func() bool { return c.Len() == 1 })
+	if _, ok := c.Get("A"); ok {
+		t.Fatal("collected entry still returned")
+	}
+	if v, ok := c.Get("B"); !ok || v != keep {
+		t.Fatal("referenced entry was dropped")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(evicted) != 1 || evicted[0] != "A" {
+		t.Fatalf("evicted = %q, want [A]", evicted)
+	}
+	runtime.KeepAlive(keep)
+}
+
+func TestOverwrittenKeyNotEvicted(t *testing.T) {
+	c := New[string, product.Product](nil)
+	c.Put("A", newProduct("old"))
+	current := newProduct("new")
+	c.Put("A", current)
+
+	// The old value's cleanup must not delete the new entry.
+	for range 5 {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	if v, ok := c.Get("A"); !ok || v.SKU != "new" {
+		t.Fatalf("Get = %v, %v; the old value's cleanup removed the new entry", v, ok)
+	}
+	runtime.KeepAlive(current)
+}
+
+func TestGetOrLoad(t *testing.T) {
+	c := New[string, product.Product](nil)
+	loads := 0
+	load := func() (*product.Product, error) { loads++; return newProduct("A"), nil }
+
+	a, _ := c.GetOrLoad("A", load)
+	b, _ := c.GetOrLoad("A", load)
+	if a != b || loads != 1 {
+		t.Fatalf("second GetOrLoad reloaded (loads = %d)", loads)
+	}
+	runtime.KeepAlive(a)
+
+	boom := errors.New("boom")
+	if _, err := c.GetOrLoad("X", func() (*product.Product, error) { return nil, boom }); err != boom {
+		t.Fatalf("err = %v", err)
+	}
+	if _, ok := c.Get("X"); ok {
+		t.Fatal("failed load was cached")
+	}
+}
+
+type releases struct {
+	mu     sync.Mutex
+	leaked map[string]bool
+}
+
+func (r *releases) release(name string, leaked bool) {
+	r.mu.Lock()
+	r.leaked[name] = leaked
+	r.mu.Unlock()
+}
+
+func (r *releases) get(name string) (leaked, released bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	leaked, released = r.leaked[name]
+	return leaked, released
+}
+
+func TestHandleClose(t *testing.T) {
+	r := &releases{leaked: make(map[string]bool)}
+	h := Open("db", r.release)
+	h.Close()
+	h.Close()
+	if leaked, ok := r.get("db"); !ok || leaked {
+		t.Fatalf("after Close: released %v, leaked %v", ok, leaked)
+	}
+
+	// A closed handle's cleanup was stopped; collecting it must not
+	// report a leak.
+	h = nil
+	for range 3 {
+		runtime.GC()
+	}
+	if leaked, _ := r.get("db"); leaked {
+		t.Fatal("closed handle reported as leaked")
+	}
+}
+
+func TestHandleLeakReleasedByCleanup(t *testing.T) {
+	r := &releases{leaked: make(map[string]bool)}
+	Open("file", r.release) // dropped without Close
+
+	eventually(t, func() bool {
+		_, ok := r.get("file")
+		return ok
+	})
+	if leaked, _ := r.get("file"); !leaked {
+		t.Fatal("leaked handle released without the leak flag")
+	}
+}